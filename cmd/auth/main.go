@@ -25,9 +25,11 @@ import (
 	zoneInfra "github.com/sapliy/fintech-ecosystem/internal/zone/infrastructure"
 	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
 	"github.com/sapliy/fintech-ecosystem/pkg/database"
+	"github.com/sapliy/fintech-ecosystem/pkg/idempotency"
 	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
 	"github.com/sapliy/fintech-ecosystem/pkg/monitoring"
 	"github.com/sapliy/fintech-ecosystem/pkg/observability"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
 	pb "github.com/sapliy/fintech-ecosystem/proto/auth"
 	ledgerPb "github.com/sapliy/fintech-ecosystem/proto/ledger"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -95,21 +97,23 @@ func main() {
 		log.Printf("Warning: Redis connection failed in Auth: %v", err)
 	}
 
-	// Initialize Kafka Publisher
+	// Relay events from the outbox to Kafka. The flow service shares this
+	// database and its outbox, and relays them as well.
 	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
 	if kafkaBrokers == "" {
 		kafkaBrokers = "localhost:9092"
 	}
 	kafkaTopic := os.Getenv("KAFKA_TOPIC")
 	if kafkaTopic == "" {
-		kafkaTopic = "payment_events" // Default topic used by notification service
+		kafkaTopic = infrastructure.EventsTopic // Default topic used by notification service
 	}
-	publisher := infrastructure.NewKafkaPublisher(strings.Split(kafkaBrokers, ","), kafkaTopic)
-	defer publisher.Close()
+	eventPublisher := outbox.NewKafkaPublisher(strings.Split(kafkaBrokers, ","))
+	defer eventPublisher.Close()
+	go outbox.NewRelay(outbox.NewSQLStore(db), eventPublisher, 2*time.Second).Start(context.Background())
 
-	sqlRepo := infrastructure.NewSQLRepository(db)
+	sqlRepo := infrastructure.NewSQLRepository(db).WithEventsTopic(kafkaTopic)
 	cachedRepo := infrastructure.NewCachedRepository(sqlRepo, rdb)
-	authService := domain.NewAuthService(cachedRepo, publisher)
+	authService := domain.NewAuthService(cachedRepo)
 
 	// Zone Service Setup
 	zoneSQLRepo := zoneInfra.NewSQLRepository(db)
@@ -118,19 +122,23 @@ func main() {
 
 	flowRepo := flowInfra.NewSQLRepository(db)
 
+	dialLedger := func() (*grpc.ClientConn, error) {
+		ledgerAddr := os.Getenv("LEDGER_GRPC_ADDR")
+		if ledgerAddr == "" {
+			ledgerAddr = "localhost:50052"
+		}
+		return grpc.NewClient(ledgerAddr,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(
+				monitoring.UnaryClientInterceptor("auth"),
+				authutil.UnaryInternalTokenClientInterceptor(),
+			),
+		)
+	}
+
 	providers := zoneDomain.TemplateProviders{
 		CreateLedgerAccount: func(ctx context.Context, name, accType, currency string, zoneID, mode string) error {
-			ledgerAddr := os.Getenv("LEDGER_GRPC_ADDR")
-			if ledgerAddr == "" {
-				ledgerAddr = "localhost:50052"
-			}
-			conn, err := grpc.NewClient(ledgerAddr,
-				grpc.WithTransportCredentials(insecure.NewCredentials()),
-				grpc.WithChainUnaryInterceptor(
-					monitoring.UnaryClientInterceptor("auth"),
-					authutil.UnaryInternalTokenClientInterceptor(),
-				),
-			)
+			conn, err := dialLedger()
 			if err != nil {
 				return err
 			}
@@ -145,6 +153,20 @@ func main() {
 			})
 			return err
 		},
+		SeedChartOfAccounts: func(ctx context.Context, zoneID, mode, chart string) error {
+			conn, err := dialLedger()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			client := ledgerPb.NewLedgerServiceClient(conn)
+			_, err = client.SeedChartOfAccounts(ctx, &ledgerPb.SeedChartOfAccountsRequest{
+				ZoneId:   zoneID,
+				Mode:     mode,
+				Template: chart,
+			})
+			return err
+		},
 		CreateFlow: func(ctx context.Context, zoneID string, name string, nodes interface{}, edges interface{}) error {
 			// Basic template flow
 			return flowRepo.CreateFlow(ctx, &flowDomain.Flow{
@@ -226,11 +248,20 @@ func main() {
 		mux.HandleFunc("/debug/tokens", handler.DebugGetTokens)
 	}
 
-	// Zone Management
+	// Zone Management. Retried zone creations with an idempotency key
+	// return the first zone instead of creating another.
+	idempotencyStore := idempotency.NewSQLStore(db)
+	idempotencyOpts, err := idempotency.OptionsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid idempotency configuration: %v", err)
+	}
+	createZone := idempotency.New(idempotencyStore, idempotencyOpts...).Middleware(zoneHandler.CreateZone)
+	go idempotency.NewPurger(idempotencyStore, time.Hour).Start(context.Background())
+
 	mux.HandleFunc("/zones", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			zoneHandler.CreateZone(w, r)
+			createZone(w, r)
 		case http.MethodGet:
 			if r.URL.Query().Get("id") != "" {
 				zoneHandler.GetZone(w, r)
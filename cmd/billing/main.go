@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,8 +16,14 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/sapliy/fintech-ecosystem/internal/billing/api"
+	"github.com/sapliy/fintech-ecosystem/internal/billing/domain"
 	"github.com/sapliy/fintech-ecosystem/internal/billing/infrastructure"
 	"github.com/sapliy/fintech-ecosystem/internal/billing/service"
+	"github.com/sapliy/fintech-ecosystem/pkg/database"
+	"github.com/sapliy/fintech-ecosystem/pkg/idempotency"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
+	pb "github.com/sapliy/fintech-ecosystem/proto/billing"
 )
 
 func main() {
@@ -30,10 +38,22 @@ func main() {
 	}
 	defer func() { _ = db.Close() }()
 
+	migrationPath := os.Getenv("MIGRATIONS_PATH")
+	if migrationPath == "" {
+		migrationPath = "migrations/billing"
+	}
+	if err := database.Migrate(db, "billing", migrationPath); err != nil {
+		log.Fatalf("failed to run migrations: %v", err)
+	}
+
 	repo := infrastructure.NewSQLRepository(db)
 
-	// Mock payment client for now - in reality this would be a gRPC client to Payment service
-	paymentClient := &mockPaymentClient{}
+	// Renewals are charged off-session through the payments service
+	paymentsURL := os.Getenv("PAYMENT_SERVICE_URL")
+	if paymentsURL == "" {
+		paymentsURL = "http://localhost:8082"
+	}
+	paymentClient := infrastructure.NewPaymentsClient(paymentsURL, &http.Client{Timeout: 30 * time.Second})
 
 	worker := service.NewSubscriptionWorker(repo, paymentClient, 1*time.Minute)
 
@@ -42,13 +62,34 @@ func main() {
 
 	go worker.Start(ctx)
 
+	// Relay subscription events from the outbox to Kafka
+	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
+	if kafkaBrokers == "" {
+		kafkaBrokers = "localhost:9092"
+	}
+	eventPublisher := outbox.NewKafkaPublisher(strings.Split(kafkaBrokers, ","))
+	defer func() { _ = eventPublisher.Close() }()
+	go outbox.NewRelay(outbox.NewSQLStore(db), eventPublisher, 2*time.Second).Start(ctx)
+
 	lis, err := net.Listen("tcp", ":50054")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
-	// pb.RegisterBillingServiceServer(s, billingService) // Not yet implemented gRPC handlers fully in domain.BillingService
+	// Retried CreateSubscription calls with an idempotency key return the
+	// first subscription instead of creating another
+	idempotencyStore := idempotency.NewSQLStore(db)
+	idempotencyOpts, err := idempotency.OptionsFromEnv()
+	if err != nil {
+		log.Fatalf("invalid idempotency configuration: %v", err)
+	}
+	guard := idempotency.New(idempotencyStore, idempotencyOpts...)
+	go idempotency.NewPurger(idempotencyStore, time.Hour).Start(ctx)
+
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(guard.UnaryServerInterceptor(pb.BillingService_CreateSubscription_FullMethodName)),
+	)
+	pb.RegisterBillingServiceServer(s, api.NewBillingGRPCServer(domain.NewBillingService(repo)))
 	reflection.Register(s)
 
 	go func() {
@@ -65,10 +106,3 @@ func main() {
 	log.Println("Shutting down billing service...")
 	s.GracefulStop()
 }
-
-type mockPaymentClient struct{}
-
-func (m *mockPaymentClient) CreatePayment(ctx context.Context, userID, orgID string, amount int64, currency string) (string, error) {
-	log.Printf("Mock Payment: user=%s, org=%s, amount=%d %s", userID, orgID, amount, currency)
-	return "pmt_mock_" + userID, nil
-}
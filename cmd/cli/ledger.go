@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(ledgerCmd)
+	ledgerCmd.AddCommand(ledgerExportCmd)
+}
+
+var ledgerCmd = &cobra.Command{
+	Use:   "ledger",
+	Short: "Work with the ledger",
+}
+
+var ledgerExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export account statements as camt.053 or CSV",
+	Long: `Download account statements from the ledger in ISO 20022 camt.053 XML
+or flat CSV. The export is streamed, so large ranges are written to the
+output as they arrive.
+
+Example:
+  micro ledger export --from 2026-01-01 --to 2026-02-01 -o january.xml
+  micro ledger export --account acc_1 --account acc_2 --format csv -o statements.csv`,
+	Args: cobra.NoArgs,
+	Run:  runLedgerExport,
+}
+
+var (
+	exportAccounts []string
+	exportFrom     string
+	exportTo       string
+	exportFormat   string
+	exportOutput   string
+)
+
+func init() {
+	ledgerExportCmd.Flags().StringSliceVarP(&exportAccounts, "account", "a", nil, "Account ID to export (repeatable; default all accounts)")
+	ledgerExportCmd.Flags().StringVar(&exportFrom, "from", "", "Start date, inclusive (YYYY-MM-DD or RFC 3339; default start of month)")
+	ledgerExportCmd.Flags().StringVar(&exportTo, "to", "", "End date, exclusive (YYYY-MM-DD or RFC 3339; default now)")
+	ledgerExportCmd.Flags().StringVarP(&exportFormat, "format", "f", "camt053", "Export format: camt053 or csv")
+	ledgerExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default stdout)")
+}
+
+func runLedgerExport(cmd *cobra.Command, args []string) {
+	apiKey := viper.GetString("api_key")
+	if apiKey == "" {
+		apiKey = os.Getenv("SAPLIY_API_KEY")
+	}
+	if apiKey == "" {
+		fmt.Fprintln(os.Stderr, "Error: API key not configured. Run 'micro login' or set SAPLIY_API_KEY")
+		os.Exit(1)
+	}
+
+	gatewayURL := viper.GetString("gateway_url")
+	if gatewayURL == "" {
+		gatewayURL = os.Getenv("SAPLIY_GATEWAY_URL")
+	}
+	if gatewayURL == "" {
+		gatewayURL = "http://localhost:8080"
+	}
+
+	q := url.Values{}
+	q.Set("format", exportFormat)
+	if exportFrom != "" {
+		q.Set("from", exportFrom)
+	}
+	if exportTo != "" {
+		q.Set("to", exportTo)
+	}
+	if len(exportAccounts) > 0 {
+		q.Set("account_id", strings.Join(exportAccounts, ","))
+	}
+
+	req, err := http.NewRequest("GET", gatewayURL+"/v1/ledger/exports/statements?"+q.Encode(), nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating request: %v\n", err)
+		os.Exit(1)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending request: %v\n", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		fmt.Fprintf(os.Stderr, "✗ Export failed (HTTP %d)\n  Response: %s\n", resp.StatusCode, string(body))
+		os.Exit(1)
+	}
+
+	out := io.Writer(os.Stdout)
+	if exportOutput != "" {
+		f, err := os.Create(exportOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", exportOutput, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	n, err := io.Copy(out, resp.Body)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing export: %v\n", err)
+		os.Exit(1)
+	}
+	if exportOutput != "" {
+		fmt.Printf("✓ Exported %d bytes to %s\n", n, exportOutput)
+	}
+}
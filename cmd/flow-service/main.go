@@ -19,8 +19,8 @@ import (
 	"github.com/sapliy/fintech-ecosystem/internal/flow/domain"
 	"github.com/sapliy/fintech-ecosystem/internal/flow/infrastructure"
 	"github.com/sapliy/fintech-ecosystem/pkg/database"
-	"github.com/sapliy/fintech-ecosystem/pkg/messaging"
 	"github.com/sapliy/fintech-ecosystem/pkg/observability"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
 )
 
 type FlowServer struct {
@@ -641,17 +641,19 @@ func main() {
 	repo := infrastructure.NewSQLRepository(db)
 	debugService := flow.NewDebugService(repo)
 
-	// Setup Kafka Producer for retriggering
+	// Relay replayed events from the outbox to Kafka. The auth service
+	// shares this database and its outbox, and relays them as well.
 	brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
 	if len(brokers) == 0 || brokers[0] == "" {
 		brokers = []string{"localhost:9092"}
 	}
-	kafkaProducer := messaging.NewKafkaProducer(brokers, "payments")
-	defer kafkaProducer.Close()
+	eventPublisher := outbox.NewKafkaPublisher(brokers)
+	defer eventPublisher.Close()
+	go outbox.NewRelay(outbox.NewSQLStore(db), eventPublisher, 2*time.Second).Start(context.Background())
 
 	// Initialize real event store and retriggerer
 	eventStore := repo // SQLRepository implements EventStore methods
-	retriggerer := infrastructure.NewKafkaEventRetriggerer(kafkaProducer)
+	retriggerer := infrastructure.NewOutboxEventRetriggerer(db)
 
 	server := NewFlowServer(debugService, repo)
 	replayer := NewWebhookReplayer(eventStore, retriggerer, debugService)
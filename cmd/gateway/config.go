@@ -18,6 +18,7 @@ type Config struct {
 	EventsServiceURL       string
 	FlowServiceURL         string
 	BillingServiceURL      string
+	ReconcilerServiceURL   string
 	AuthGRPCAddr           string
 	WalletGRPCAddr         string
 	HMACSecret             string
@@ -37,6 +38,7 @@ func LoadConfig(logger *observability.Logger) *Config {
 	eventsURL := getEnv("EVENTS_SERVICE_URL", "http://127.0.0.1:8089")
 	flowURL := getEnv("FLOW_SERVICE_URL", "http://127.0.0.1:8088")
 	billingURL := getEnv("BILLING_SERVICE_URL", "http://127.0.0.1:8090")
+	reconcilerURL := getEnv("RECONCILER_SERVICE_URL", "http://127.0.0.1:8091")
 
 	authGRPCAddr := getEnv("AUTH_GRPC_ADDR", "localhost:50051")
 	walletGRPCAddr := getEnv("WALLET_GRPC_ADDR", "localhost:50053")
@@ -75,6 +77,7 @@ func LoadConfig(logger *observability.Logger) *Config {
 		EventsServiceURL:       eventsURL,
 		FlowServiceURL:         flowURL,
 		BillingServiceURL:      billingURL,
+		ReconcilerServiceURL:   reconcilerURL,
 		AuthGRPCAddr:           authGRPCAddr,
 		WalletGRPCAddr:         walletGRPCAddr,
 		HMACSecret:             hmacSecret,
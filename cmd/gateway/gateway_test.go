@@ -250,6 +250,44 @@ func TestServeHTTP_PublicRoutes(t *testing.T) {
 	}
 }
 
+func TestServeHTTP_HostedCheckout(t *testing.T) {
+	authCalled := false
+	handler, h, _ := newTestGateway(t, &mockAuthClient{
+		ValidateKeyFunc: func(_ context.Context, _ *pb.ValidateKeyRequest, _ ...grpc.CallOption) (*pb.ValidateKeyResponse, error) {
+			authCalled = true
+			return &pb.ValidateKeyResponse{Valid: false}, nil
+		},
+	})
+
+	var gotPath, gotUser, gotZone string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotUser, gotZone = r.URL.Path, r.Header.Get("X-User-ID"), r.Header.Get("X-Zone-ID")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+	h.paymentServiceURL = upstream.URL
+
+	// A customer's client cannot pose as a merchant on the public routes.
+	req := httptest.NewRequest(http.MethodPost, "/v1/checkout/sessions/cs_1/confirm", strings.NewReader(`{}`))
+	req.Header.Set("X-User-ID", "user_spoofed")
+	req.Header.Set("X-Zone-ID", "zone_spoofed")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if authCalled {
+		t.Error("auth gRPC client should not be called for hosted checkout")
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if gotPath != "/checkout/sessions/cs_1/confirm" {
+		t.Errorf("upstream path: got %q, want %q", gotPath, "/checkout/sessions/cs_1/confirm")
+	}
+	if gotUser != "" || gotZone != "" {
+		t.Errorf("identity headers reached payments: X-User-ID %q, X-Zone-ID %q", gotUser, gotZone)
+	}
+}
+
 // ─── Tests: Scope Enforcement ─────────────────────────────────────────────────
 
 func TestServeHTTP_ScopeEnforcement(t *testing.T) {
@@ -20,6 +20,7 @@ type GatewayHandler struct {
 	ledgerServiceURL       string
 	walletServiceURL       string
 	billingServiceURL      string
+	reconcilerServiceURL   string
 	eventsServiceURL       string
 	flowServiceURL         string
 	notificationServiceURL string
@@ -39,6 +40,7 @@ func NewGatewayHandler(cfg *Config, rdb *redis.Client, authClient pb.AuthService
 		ledgerServiceURL:       cfg.LedgerServiceURL,
 		walletServiceURL:       cfg.WalletServiceURL,
 		billingServiceURL:      cfg.BillingServiceURL,
+		reconcilerServiceURL:   cfg.ReconcilerServiceURL,
 		eventsServiceURL:       cfg.EventsServiceURL,
 		flowServiceURL:         cfg.FlowServiceURL,
 		notificationServiceURL: cfg.NotificationServiceURL,
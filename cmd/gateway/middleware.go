@@ -47,7 +47,7 @@ func (h *GatewayHandler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 
-		if strings.HasPrefix(path, "/auth") || path == "/health" {
+		if strings.HasPrefix(path, "/auth") || path == "/health" || isHostedCheckout(path) {
 			next.ServeHTTP(w, r)
 			return
 		}
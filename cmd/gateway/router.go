@@ -25,11 +25,26 @@ func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.proxyRequest(h.paymentServiceURL, w, r)
 		})).ServeHTTP(w, r)
 
+	case isHostedCheckout(path):
+		// Customers of the hosted checkout carry no API key, so nothing
+		// they send may pass for a merchant's identity.
+		for _, header := range identityHeaders {
+			r.Header.Del(header)
+		}
+		http.StripPrefix(path[:len(path)-len(p)], http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.proxyRequest(h.paymentServiceURL, w, r)
+		})).ServeHTTP(w, r)
+
 	case strings.HasPrefix(p, "/ledger"):
 		http.StripPrefix(path[:len(path)-len(p)]+"/ledger", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			h.proxyRequest(h.ledgerServiceURL, w, r)
 		})).ServeHTTP(w, r)
 
+	case strings.HasPrefix(p, "/reconciliation"):
+		http.StripPrefix(path[:len(path)-len(p)]+"/reconciliation", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.proxyRequest(h.reconcilerServiceURL, w, r)
+		})).ServeHTTP(w, r)
+
 	case strings.HasPrefix(p, "/wallets"):
 		h.proxyRequest(h.walletServiceURL, w, r)
 
@@ -93,6 +108,15 @@ func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// identityHeaders are set by AuthMiddleware from the caller's API key.
+var identityHeaders = []string{"X-User-ID", "X-Environment", "X-Org-ID", "X-Role", "X-Zone-ID", "X-Zone-Mode"}
+
+// isHostedCheckout reports whether path is a hosted checkout page or
+// payment link, which customers open without an API key.
+func isHostedCheckout(path string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(path, "/v1"), "/checkout/")
+}
+
 func (h *GatewayHandler) routePublic(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	if strings.HasPrefix(path, "/auth") {
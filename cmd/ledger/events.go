@@ -3,21 +3,31 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
 	"github.com/sapliy/fintech-ecosystem/pkg/messaging"
 )
 
+// PaymentEvent is an event of the payments topic. Data is the payment
+// intent, or the dispute for dispute events, which carries the same fields.
 type PaymentEvent struct {
 	Type string `json:"type"`
 	Data struct {
-		ID       string `json:"id"`
-		Amount   int64  `json:"amount"`
-		Currency string `json:"currency"`
-		UserID   string `json:"user_id"`
-		ZoneID   string `json:"zone_id"`
-		Mode     string `json:"mode"`
+		ID     string `json:"id"`
+		Amount int64  `json:"amount"`
+		// AmountReceived is what was captured, which a partial capture
+		// leaves below Amount. Events from before manual capture lack it.
+		AmountReceived int64  `json:"amount_received"`
+		Currency       string `json:"currency"`
+		UserID         string `json:"user_id"`
+		ZoneID         string `json:"zone_id"`
+		Mode           string `json:"mode"`
+		// OnBehalfOf is the connected account a marketplace payment is made
+		// for, which receives it less ApplicationFeeAmount.
+		ApplicationFeeAmount int64  `json:"application_fee_amount"`
+		OnBehalfOf           string `json:"on_behalf_of"`
 	} `json:"data"`
 }
 
@@ -34,50 +44,23 @@ func StartKafkaConsumer(brokers []string, service *domain.LedgerService) {
 
 		log.Printf("Ledger: Received Kafka event type %s for ID %s", event.Type, event.Data.ID)
 
-		var txReq domain.TransactionRequest
-
+		// Refunds are not handled here: the payments service posts their
+		// reversals through gRPC before announcing them.
+		var txReq *domain.TransactionRequest
+		var err error
 		switch event.Type {
 		case "payment.succeeded":
-			txReq = domain.TransactionRequest{
-				ReferenceID: event.Data.ID,
-				Description: "Kafka Event: Payment Success",
-				Entries: []domain.EntryRequest{
-					{
-						AccountID: "user_" + event.Data.UserID,
-						Amount:    event.Data.Amount,
-						Direction: "credit",
-					},
-					{
-						AccountID: "system_balancing",
-						Amount:    -event.Data.Amount,
-						Direction: "debit",
-					},
-				},
-			}
-		case "payment.refunded":
-			// Reversing entries
-			txReq = domain.TransactionRequest{
-				ReferenceID: "refund_" + event.Data.ID,
-				Description: "Kafka Event: Payment Refunded",
-				Entries: []domain.EntryRequest{
-					{
-						AccountID: "user_" + event.Data.UserID,
-						Amount:    -event.Data.Amount, // Negative credit is a debit
-						Direction: "debit",            // Explicitly set direction
-					},
-					{
-						AccountID: "system_balancing",
-						Amount:    event.Data.Amount,
-						Direction: "credit",
-					},
-				},
-			}
+			txReq, err = paymentTransaction(service, event)
+		case "dispute.created", "dispute.won", "dispute.lost":
+			txReq, err = disputeTransaction(service, event)
 		default:
 			return nil // Ignore other events
 		}
+		if err != nil {
+			return err
+		}
 
-		ctx := context.Background()
-		if err := service.RecordTransaction(ctx, txReq, event.Data.ZoneID, event.Data.Mode); err != nil {
+		if err := service.RecordTransaction(context.Background(), *txReq, event.Data.ZoneID, event.Data.Mode); err != nil {
 			log.Printf("Failed to record transaction for event %s (ID: %s): %v", event.Type, event.Data.ID, err)
 			return err
 		}
@@ -86,3 +69,150 @@ func StartKafkaConsumer(brokers []string, service *domain.LedgerService) {
 		return nil
 	})
 }
+
+// paymentTransaction moves a succeeded payment's funds from the zone's
+// settlement clearing account to the user's wallet, both resolved from the
+// chart of accounts. A marketplace payment made on behalf of a connected
+// account is split instead: the platform's fee goes to fee revenue and the
+// rest to the connected account. Refunds reverse the split in proportion.
+func paymentTransaction(service *domain.LedgerService, event PaymentEvent) (*domain.TransactionRequest, error) {
+	ctx := context.Background()
+	data := event.Data
+	if data.Currency == "" {
+		data.Currency = "USD"
+	}
+	clearing, err := service.SystemAccount(ctx, data.ZoneID, data.Mode, domain.CodeSettlementClearing, data.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve settlement clearing account: %w", err)
+	}
+
+	amount := data.Amount
+	if data.AmountReceived > 0 {
+		amount = data.AmountReceived
+	}
+	entries := []domain.EntryRequest{{AccountID: clearing.ID, Amount: -amount, Direction: "debit"}}
+	if data.OnBehalfOf == "" {
+		wallet, err := service.UserAccount(ctx, data.ZoneID, data.Mode, data.UserID, data.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve wallet of user %s: %w", data.UserID, err)
+		}
+		entries = append(entries, domain.EntryRequest{AccountID: wallet.ID, Amount: amount, Direction: "credit"})
+		return &domain.TransactionRequest{
+			ReferenceID: data.ID,
+			Description: "Kafka Event: Payment Success",
+			Entries:     entries,
+		}, nil
+	}
+
+	connected, err := service.ConnectedAccount(ctx, data.ZoneID, data.Mode, data.OnBehalfOf, data.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve connected account %s: %w", data.OnBehalfOf, err)
+	}
+	// A partial capture cannot pay the platform more than was captured
+	fee := min(data.ApplicationFeeAmount, amount)
+	if fee > 0 {
+		revenue, err := service.SystemAccount(ctx, data.ZoneID, data.Mode, domain.CodeFeeRevenue, data.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve fee revenue account: %w", err)
+		}
+		entries = append(entries, domain.EntryRequest{AccountID: revenue.ID, Amount: fee, Direction: "credit"})
+	}
+	if net := amount - fee; net > 0 {
+		entries = append(entries, domain.EntryRequest{AccountID: connected.ID, Amount: net, Direction: "credit"})
+	}
+	return &domain.TransactionRequest{
+		ReferenceID: data.ID,
+		Description: "Kafka Event: Marketplace Payment Success",
+		Entries:     entries,
+	}, nil
+}
+
+// disputeTransaction books a dispute step. Opening a dispute moves the
+// disputed amount to the disputes reserve from the merchant's balance: the
+// connected account of a marketplace payment, or else the user's wallet.
+// What the balance cannot cover is booked as a merchant receivable rather
+// than failing the posting. A won dispute reverses the opening, returning
+// each part where it came from, and a lost one pays the reserve out through
+// settlement clearing like a refund. Each step has its own reference, so
+// redelivered events are recorded once.
+func disputeTransaction(service *domain.LedgerService, event PaymentEvent) (*domain.TransactionRequest, error) {
+	ctx := context.Background()
+	data := event.Data
+	if data.Currency == "" {
+		data.Currency = "USD"
+	}
+	opening := "dispute_" + data.ID
+
+	switch event.Type {
+	case "dispute.won":
+		orig, err := service.GetTransactionByReference(ctx, opening)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get opening of dispute %s: %w", data.ID, err)
+		}
+		if orig == nil {
+			return nil, fmt.Errorf("dispute %s was won but its opening is not recorded", data.ID)
+		}
+		req := &domain.TransactionRequest{ReferenceID: opening + "_won", Description: "Dispute won"}
+		for _, e := range orig.Entries {
+			req.Entries = append(req.Entries, domain.EntryRequest{AccountID: e.AccountID, Amount: -e.Amount, Direction: directionOf(-e.Amount)})
+		}
+		return req, nil
+	case "dispute.lost":
+		reserve, err := service.SystemAccount(ctx, data.ZoneID, data.Mode, domain.CodeDisputesReserve, data.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve disputes reserve account: %w", err)
+		}
+		clearing, err := service.SystemAccount(ctx, data.ZoneID, data.Mode, domain.CodeSettlementClearing, data.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve settlement clearing account: %w", err)
+		}
+		return &domain.TransactionRequest{
+			ReferenceID: opening + "_lost",
+			Description: "Dispute lost",
+			Entries: []domain.EntryRequest{
+				{AccountID: clearing.ID, Amount: data.Amount, Direction: "credit"},
+				{AccountID: reserve.ID, Amount: -data.Amount, Direction: "debit"},
+			},
+		}, nil
+	}
+
+	var merchant *domain.Account
+	var err error
+	if data.OnBehalfOf != "" {
+		merchant, err = service.ConnectedAccount(ctx, data.ZoneID, data.Mode, data.OnBehalfOf, data.Currency)
+	} else {
+		merchant, err = service.UserAccount(ctx, data.ZoneID, data.Mode, data.UserID, data.Currency)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve balance of the disputed payment's merchant: %w", err)
+	}
+	reserve, err := service.SystemAccount(ctx, data.ZoneID, data.Mode, domain.CodeDisputesReserve, data.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve disputes reserve account: %w", err)
+	}
+
+	withheld := min(data.Amount, max(merchant.AvailableBalance, 0))
+	req := &domain.TransactionRequest{
+		ReferenceID: opening,
+		Description: "Dispute opened",
+		Entries:     []domain.EntryRequest{{AccountID: reserve.ID, Amount: data.Amount, Direction: "credit"}},
+	}
+	if withheld > 0 {
+		req.Entries = append(req.Entries, domain.EntryRequest{AccountID: merchant.ID, Amount: -withheld, Direction: "debit"})
+	}
+	if shortfall := data.Amount - withheld; shortfall > 0 {
+		receivable, err := service.SystemAccount(ctx, data.ZoneID, data.Mode, domain.CodeMerchantReceivables, data.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve merchant receivables account: %w", err)
+		}
+		req.Entries = append(req.Entries, domain.EntryRequest{AccountID: receivable.ID, Amount: -shortfall, Direction: "debit"})
+	}
+	return req, nil
+}
+
+func directionOf(amount int64) string {
+	if amount < 0 {
+		return "debit"
+	}
+	return "credit"
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+)
+
+func TestPaymentTransaction(t *testing.T) {
+	repo := &domain.MockRepository{
+		GetAccountByCodeFunc: func(ctx context.Context, zoneID, mode, code, currency string) (*domain.Account, error) {
+			return &domain.Account{ID: "code_" + code, Currency: currency}, nil
+		},
+		GetUserAccountFunc: func(ctx context.Context, zoneID, mode, userID, currency string) (*domain.Account, error) {
+			return &domain.Account{ID: "owned_" + userID, Currency: currency}, nil
+		},
+	}
+	service := domain.NewLedgerService(repo, nil)
+	clearing := "code_" + domain.CodeSettlementClearing
+	revenue := "code_" + domain.CodeFeeRevenue
+
+	tests := []struct {
+		name           string
+		amountReceived int64
+		fee            int64
+		onBehalfOf     string
+		want           map[string]int64
+	}{
+		{
+			name: "Merchant Payment",
+			want: map[string]int64{clearing: -10000, "owned_user_1": 10000},
+		},
+		{
+			name:       "Marketplace Payment",
+			fee:        1200,
+			onBehalfOf: "acct_1",
+			want:       map[string]int64{clearing: -10000, revenue: 1200, "owned_acct_1": 8800},
+		},
+		{
+			name:       "Marketplace Payment Without Fee",
+			onBehalfOf: "acct_1",
+			want:       map[string]int64{clearing: -10000, "owned_acct_1": 10000},
+		},
+		{
+			name:           "Partial Capture Below Fee",
+			amountReceived: 500,
+			fee:            1200,
+			onBehalfOf:     "acct_1",
+			want:           map[string]int64{clearing: -500, revenue: 500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event PaymentEvent
+			event.Type = "payment.succeeded"
+			event.Data.ID = "pi_1"
+			event.Data.Amount = 10000
+			event.Data.AmountReceived = tt.amountReceived
+			event.Data.Currency = "USD"
+			event.Data.UserID = "user_1"
+			event.Data.ZoneID = "zone_1"
+			event.Data.Mode = "live"
+			event.Data.ApplicationFeeAmount = tt.fee
+			event.Data.OnBehalfOf = tt.onBehalfOf
+
+			req, err := paymentTransaction(service, event)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if req.ReferenceID != "pi_1" {
+				t.Errorf("Expected reference pi_1, got %s", req.ReferenceID)
+			}
+			got := make(map[string]int64)
+			for _, e := range req.Entries {
+				got[e.AccountID] += e.Amount
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("Expected entries %v, got %v", tt.want, got)
+			}
+			for acc, amt := range tt.want {
+				if got[acc] != amt {
+					t.Errorf("Expected %d on %s, got %d", amt, acc, got[acc])
+				}
+			}
+		})
+	}
+}
+
+func TestDisputeTransaction(t *testing.T) {
+	opening := &domain.TransactionWithEntries{Entries: []domain.Entry{
+		{AccountID: "code_" + domain.CodeDisputesReserve, Amount: 10000},
+		{AccountID: "owned_acct_1", Amount: -4000},
+		{AccountID: "code_" + domain.CodeMerchantReceivables, Amount: -6000},
+	}}
+	repo := &domain.MockRepository{
+		GetAccountByCodeFunc: func(ctx context.Context, zoneID, mode, code, currency string) (*domain.Account, error) {
+			return &domain.Account{ID: "code_" + code, Currency: currency}, nil
+		},
+		GetUserAccountFunc: func(ctx context.Context, zoneID, mode, userID, currency string) (*domain.Account, error) {
+			return &domain.Account{ID: "owned_" + userID, Currency: currency, Balance: 4000}, nil
+		},
+		GetTransactionByReferenceFunc: func(ctx context.Context, referenceID string) (*domain.TransactionWithEntries, error) {
+			if referenceID == "dispute_dp_1" {
+				return opening, nil
+			}
+			return nil, nil
+		},
+	}
+	service := domain.NewLedgerService(repo, nil)
+	reserve := "code_" + domain.CodeDisputesReserve
+	receivable := "code_" + domain.CodeMerchantReceivables
+
+	tests := []struct {
+		name       string
+		eventType  string
+		id         string
+		amount     int64
+		onBehalfOf string
+		wantRef    string
+		want       map[string]int64
+		wantErr    bool
+	}{
+		{
+			name:      "Opened Within Balance",
+			eventType: "dispute.created",
+			id:        "dp_1",
+			amount:    3000,
+			wantRef:   "dispute_dp_1",
+			want:      map[string]int64{reserve: 3000, "owned_user_1": -3000},
+		},
+		{
+			name:       "Opened Beyond Connected Account Balance",
+			eventType:  "dispute.created",
+			id:         "dp_1",
+			amount:     10000,
+			onBehalfOf: "acct_1",
+			wantRef:    "dispute_dp_1",
+			want:       map[string]int64{reserve: 10000, "owned_acct_1": -4000, receivable: -6000},
+		},
+		{
+			name:      "Won",
+			eventType: "dispute.won",
+			id:        "dp_1",
+			amount:    10000,
+			wantRef:   "dispute_dp_1_won",
+			want:      map[string]int64{reserve: -10000, "owned_acct_1": 4000, receivable: 6000},
+		},
+		{
+			name:      "Won Without Opening",
+			eventType: "dispute.won",
+			id:        "dp_2",
+			amount:    10000,
+			wantErr:   true,
+		},
+		{
+			name:      "Lost",
+			eventType: "dispute.lost",
+			id:        "dp_1",
+			amount:    10000,
+			wantRef:   "dispute_dp_1_lost",
+			want:      map[string]int64{reserve: -10000, "code_" + domain.CodeSettlementClearing: 10000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event PaymentEvent
+			event.Type = tt.eventType
+			event.Data.ID = tt.id
+			event.Data.Amount = tt.amount
+			event.Data.Currency = "USD"
+			event.Data.UserID = "user_1"
+			event.Data.ZoneID = "zone_1"
+			event.Data.Mode = "live"
+			event.Data.OnBehalfOf = tt.onBehalfOf
+
+			req, err := disputeTransaction(service, event)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if req.ReferenceID != tt.wantRef {
+				t.Errorf("Expected reference %s, got %s", tt.wantRef, req.ReferenceID)
+			}
+			got := make(map[string]int64)
+			var sum int64
+			for _, e := range req.Entries {
+				got[e.AccountID] += e.Amount
+				sum += e.Amount
+			}
+			if sum != 0 {
+				t.Errorf("Expected balanced entries, got %v", got)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("Expected entries %v, got %v", tt.want, got)
+			}
+			for acc, amt := range tt.want {
+				if got[acc] != amt {
+					t.Errorf("Expected %d on %s, got %d", amt, acc, got[acc])
+				}
+			}
+		})
+	}
+}
@@ -19,9 +19,10 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
 	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
-	"github.com/sapliy/fintech-ecosystem/pkg/messaging"
 	"github.com/sapliy/fintech-ecosystem/pkg/monitoring"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
 	"google.golang.org/grpc"
 )
 
@@ -74,6 +75,26 @@ func main() {
 	repo := infrastructure.NewCachedRepository(sqlRepo, rdb)
 	metrics := &infrastructure.PrometheusMetrics{}
 	service := domain.NewLedgerService(repo, metrics)
+	if signingKey := os.Getenv("LEDGER_SIGNING_KEY"); signingKey != "" {
+		service.SetSigningKey([]byte(signingKey))
+	} else {
+		logger.Warn("LEDGER_SIGNING_KEY not set, accounting periods cannot be closed")
+	}
+
+	// Seed the FX rate table from a file when configured
+	if fxRatesFile := os.Getenv("FX_RATES_FILE"); fxRatesFile != "" {
+		if f, err := os.Open(fxRatesFile); err != nil {
+			logger.Warn("Failed to open FX rates file", "path", fxRatesFile, "error", err)
+		} else {
+			n, err := service.LoadFXRates(context.Background(), f)
+			_ = f.Close()
+			if err != nil {
+				logger.Warn("Failed to load FX rates", "path", fxRatesFile, "error", err)
+			} else {
+				logger.Info("Loaded FX rates", "path", fxRatesFile, "count", n)
+			}
+		}
+	}
 
 	// Initialize Tracer
 	shutdown, err := observability.InitTracer(context.Background(), observability.Config{
@@ -100,12 +121,16 @@ func main() {
 	brokers := strings.Split(kafkaBrokers, ",")
 	go StartKafkaConsumer(brokers, service)
 
-	// Start Outbox Publisher for Reliable Event Delivery
-	ledgerProducer := messaging.NewKafkaProducer(brokers, "ledger-events")
-	publisher := infrastructure.NewOutboxPublisher(repo, ledgerProducer, 2*time.Second)
-	go publisher.Start(context.Background())
+	// Start Outbox Relay for Reliable Event Delivery
+	eventPublisher := outbox.NewKafkaPublisher(brokers)
+	defer func() { _ = eventPublisher.Close() }()
+	go outbox.NewRelay(outbox.NewSQLStore(db), eventPublisher, 2*time.Second).Start(context.Background())
+
+	// Release holds that were neither captured nor voided in time
+	go infrastructure.NewHoldExpirer(service, time.Minute).Start(context.Background())
 
 	handler := api.NewLedgerHandler(service)
+	reportHandler := api.NewReportHandler(domain.NewReportingService(repo))
 
 	mux := http.NewServeMux()
 
@@ -120,15 +145,29 @@ func main() {
 
 	mux.HandleFunc("/accounts", handler.CreateAccount)
 
-	// Simple routing for /accounts/{id}
+	// Simple routing for /accounts/{id} and its balance/statement/rollup sub-resources
 	mux.HandleFunc("/accounts/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/balance"):
+			handler.GetAccountBalance(w, r)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/rollup"):
+			handler.GetAccountRollup(w, r)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/statement"):
+			handler.GetAccountStatement(w, r)
+		case r.Method == http.MethodGet:
 			handler.GetAccount(w, r)
-			return
+		case r.Method == http.MethodPatch:
+			handler.UpdateAccount(w, r)
+		default:
+			jsonutil.WriteErrorJSON(w, "Not Found")
 		}
-		jsonutil.WriteErrorJSON(w, "Not Found")
 	})
 
+	mux.HandleFunc("/exports/statements", handler.ExportStatements)
+
+	mux.HandleFunc("/chart", handler.GetChartOfAccounts)
+	mux.HandleFunc("/chart/seed", handler.SeedChartOfAccounts)
+
 	mux.HandleFunc("/transactions", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			handler.ListTransactions(w, r)
@@ -137,17 +176,82 @@ func main() {
 		handler.RecordTransaction(w, r)
 	})
 
-	// Simple routing for /transactions/{id}
+	// Simple routing for /transactions/{id} and /transactions/{id}/reverse
 	mux.HandleFunc("/transactions/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/reverse"):
+			handler.ReverseTransaction(w, r)
+		case r.Method == http.MethodGet:
 			handler.GetTransaction(w, r)
-			return
+		default:
+			jsonutil.WriteErrorJSON(w, "Not Found")
 		}
-		jsonutil.WriteErrorJSON(w, "Not Found")
 	})
 
 	mux.HandleFunc("/bulk-transactions", handler.BulkRecordTransactions)
 
+	mux.HandleFunc("/fx-rates", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListFXRates(w, r)
+		case http.MethodPost, http.MethodPut:
+			handler.SetFXRate(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST, PUT")
+			apierror.MethodNotAllowed("Method not allowed").Write(w)
+		}
+	})
+
+	mux.HandleFunc("/periods", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			handler.ListPeriods(w, r)
+			return
+		}
+		handler.CreatePeriod(w, r)
+	})
+
+	// Simple routing for /periods/{id}/close, /reopen and /snapshot
+	mux.HandleFunc("/periods/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/close"):
+			handler.ClosePeriod(w, r)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/reopen"):
+			handler.ReopenPeriod(w, r)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/snapshot"):
+			handler.GetPeriodSnapshot(w, r)
+		default:
+			jsonutil.WriteErrorJSON(w, "Not Found")
+		}
+	})
+
+	mux.HandleFunc("/holds", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			apierror.MethodNotAllowed("Method not allowed").Write(w)
+			return
+		}
+		handler.CreateHold(w, r)
+	})
+
+	// Simple routing for /holds/{id}, /holds/{id}/capture and /holds/{id}/void
+	mux.HandleFunc("/holds/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/capture"):
+			handler.CaptureHold(w, r)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/void"):
+			handler.VoidHold(w, r)
+		case r.Method == http.MethodGet:
+			handler.GetHold(w, r)
+		default:
+			jsonutil.WriteErrorJSON(w, "Not Found")
+		}
+	})
+
+	// Financial statements
+	mux.HandleFunc("/reports/trial-balance", reportHandler.TrialBalance)
+	mux.HandleFunc("/reports/balance-sheet", reportHandler.BalanceSheet)
+	mux.HandleFunc("/reports/income-statement", reportHandler.IncomeStatement)
+
 	port := ":8083"
 	logger.Info("Ledger service HTTP starting", "port", port)
 
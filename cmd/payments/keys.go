@@ -0,0 +1,36 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/tenant"
+)
+
+// devMasterKey encrypts zone keys when PAYMENT_METHODS_MASTER_KEY is not
+// set and PAYMENT_METHODS_DEV_KEY=true opts into it. It is only fit for
+// local development.
+const devMasterKey = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// newKeyManager returns the key manager encrypting saved card numbers. Zone
+// keys are kept in the service's database, encrypted with the master key
+// given hex-encoded in PAYMENT_METHODS_MASTER_KEY. Without it the service
+// refuses to start unless the development key is explicitly allowed.
+func newKeyManager(db *sql.DB) (*tenant.KeyManager, error) {
+	encoded := os.Getenv("PAYMENT_METHODS_MASTER_KEY")
+	if encoded == "" {
+		if os.Getenv("PAYMENT_METHODS_DEV_KEY") != "true" {
+			return nil, fmt.Errorf("PAYMENT_METHODS_MASTER_KEY is not set")
+		}
+		log.Println("PAYMENT_METHODS_MASTER_KEY is not set; saved cards are encrypted with the development key")
+		encoded = devMasterKey
+	}
+	masterKey, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAYMENT_METHODS_MASTER_KEY: %w", err)
+	}
+	return tenant.NewKeyManager(masterKey, tenant.NewSQLKeyStore(db))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
+)
+
+// newProcessors registers the payment processor adapters and assigns zones
+// to them. Only the simulator exists so far; PAYMENT_PROCESSOR picks the
+// fallback for unassigned zones and PAYMENT_ZONE_PROCESSORS assigns zones,
+// as in "zone_a=simulator,zone_b=acme". The simulator is returned too so
+// its challenge pages can be served.
+func newProcessors() (*bank.Registry, *bank.Simulator, error) {
+	cfg, err := simulatorConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fallback := os.Getenv("PAYMENT_PROCESSOR")
+	if fallback == "" {
+		fallback = "simulator"
+	}
+	processors := bank.NewRegistry(fallback)
+	simulator := bank.NewSimulator(cfg)
+	processors.Register("simulator", simulator, cfg.WebhookSecret)
+	if _, err := processors.Get(fallback); err != nil {
+		return nil, nil, err
+	}
+
+	zones, err := bank.ParseZoneAssignments(os.Getenv("PAYMENT_ZONE_PROCESSORS"))
+	if err != nil {
+		return nil, nil, err
+	}
+	for zoneID, name := range zones {
+		if err := processors.AssignZone(zoneID, name); err != nil {
+			return nil, nil, err
+		}
+	}
+	return processors, simulator, nil
+}
+
+// devWebhookSecret signs simulator webhooks when SIMULATOR_WEBHOOK_SECRET is
+// not set and SIMULATOR_DEV_SECRET=true opts into it. It is only fit for
+// local development: anyone knowing it can forge dispute and settlement
+// webhooks.
+const devWebhookSecret = "whsec_simulator"
+
+// simulatorConfig reads the simulator's settings from SIMULATOR_*
+// variables. Settlement webhooks and challenge pages are served by this
+// service.
+func simulatorConfig() (bank.SimulatorConfig, error) {
+	cfg := bank.SimulatorConfig{
+		Latency:         50 * time.Millisecond,
+		SettlementDelay: 30 * time.Second,
+		WebhookURL:      os.Getenv("SIMULATOR_WEBHOOK_URL"),
+		WebhookSecret:   os.Getenv("SIMULATOR_WEBHOOK_SECRET"),
+		ChallengeURL:    os.Getenv("SIMULATOR_CHALLENGE_URL"),
+	}
+	if cfg.WebhookURL == "" {
+		cfg.WebhookURL = "http://localhost:8082/processors/simulator/webhooks"
+	}
+	if cfg.ChallengeURL == "" {
+		cfg.ChallengeURL = "http://localhost:8082/simulator/challenges/"
+	}
+	if cfg.WebhookSecret == "" {
+		if os.Getenv("SIMULATOR_DEV_SECRET") != "true" {
+			return cfg, fmt.Errorf("SIMULATOR_WEBHOOK_SECRET is not set")
+		}
+		log.Println("SIMULATOR_WEBHOOK_SECRET is not set; simulator webhooks are signed with the development secret")
+		cfg.WebhookSecret = devWebhookSecret
+	}
+
+	var err error
+	durations := map[string]*time.Duration{
+		"SIMULATOR_LATENCY":          &cfg.Latency,
+		"SIMULATOR_SETTLEMENT_DELAY": &cfg.SettlementDelay,
+	}
+	for name, d := range durations {
+		if v := os.Getenv(name); v != "" {
+			if *d, err = time.ParseDuration(v); err != nil {
+				return cfg, fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	rates := map[string]*float64{
+		"SIMULATOR_DECLINE_RATE":       &cfg.DeclineRate,
+		"SIMULATOR_NETWORK_ERROR_RATE": &cfg.NetworkErrorRate,
+	}
+	for name, rate := range rates {
+		if v := os.Getenv(name); v != "" {
+			if *rate, err = strconv.ParseFloat(v, 64); err != nil {
+				return cfg, fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	if v := os.Getenv("SIMULATOR_SEED"); v != "" {
+		if cfg.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return cfg, fmt.Errorf("invalid SIMULATOR_SEED: %w", err)
+		}
+	}
+	return cfg, nil
+}
@@ -2,14 +2,25 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/api"
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/infrastructure"
+	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
 	"github.com/sapliy/fintech-ecosystem/pkg/database"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
 	"github.com/sapliy/fintech-ecosystem/pkg/monitoring"
+	ledgerpb "github.com/sapliy/fintech-ecosystem/proto/ledger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -44,64 +55,125 @@ func main() {
 	// Start Metrics Server
 	monitoring.StartMetricsServer(":8081")
 
+	// Bank statement reconciliation. Write-off adjustments are posted
+	// through the ledger service rather than written to its tables directly.
+	ledgerGRPCAddr := os.Getenv("LEDGER_GRPC_ADDR")
+	if ledgerGRPCAddr == "" {
+		ledgerGRPCAddr = "localhost:50052"
+	}
+	conn, err := grpc.NewClient(ledgerGRPCAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			monitoring.UnaryClientInterceptor("reconciler"),
+			authutil.UnaryInternalTokenClientInterceptor(),
+		),
+	)
+	if err != nil {
+		log.Fatalf("did not connect to ledger gRPC: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	service := domain.NewReconciliationService(
+		infrastructure.NewSQLRepository(ledgerDB),
+		infrastructure.NewLedgerClient(ledgerpb.NewLedgerServiceClient(conn)),
+	)
+
+	// Payment audit runs. Discrepancies are announced on the zone event
+	// streams so flows can react to them.
+	redisAddr := os.Getenv("REDIS_ADDR")
+	if redisAddr == "" {
+		redisAddr = "localhost:6379"
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		log.Printf("Redis connection failed in Reconciler: %v", err)
+	}
+	defer func() { _ = rdb.Close() }()
+
+	runs := domain.NewRunService(
+		infrastructure.NewSQLRunRepository(ledgerDB),
+		infrastructure.NewSQLPaymentSource(payDB),
+		infrastructure.NewSQLLedgerSource(ledgerDB),
+		infrastructure.NewRedisEventPublisher(rdb),
+		domain.DefaultRunConfig,
+	)
+
+	go serveHTTP(api.NewReconciliationHandler(service), api.NewRunHandler(runs))
+
 	log.Println("Reconciliation Checker started. Auditing every 1 minute...")
 
 	ticker := time.NewTicker(1 * time.Minute)
 	for range ticker.C {
-		reconcile(payDB, ledgerDB)
+		run, err := runs.Run(context.Background(), "schedule")
+		if errors.Is(err, domain.ErrRunInProgress) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Reconciler: audit run failed: %v", err)
+			continue
+		}
+		log.Printf("Reconciler audit complete. Run: %s, Payments: %d, Transactions: %d, Discrepancies: %d, Resolved: %d",
+			run.ID, run.PaymentsChecked, run.TransactionsChecked, run.DiscrepancyCount, run.ResolvedCount)
 	}
 }
 
-func reconcile(payDB, ledgerDB *sql.DB) {
-	ctx := context.Background()
-
-	// 1. Get all successful/refunded payments from last hour (to keep it small for now)
-	rows, err := payDB.QueryContext(ctx, "SELECT id, status, amount FROM payment_intents WHERE status IN ('succeeded', 'refunded') AND created_at > NOW() - INTERVAL '1 hour'")
-	if err != nil {
-		log.Printf("Reconciler: Error fetching payments: %v", err)
-		return
-	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			log.Printf("Reconciler: Failed to close rows: %v", err)
-		}
-	}()
+func serveHTTP(handler *api.ReconciliationHandler, runs *api.RunHandler) {
+	mux := http.NewServeMux()
 
-	discrepancies := 0
-	totalChecked := 0
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		jsonutil.WriteJSON(w, http.StatusOK, map[string]string{
+			"status":  "active",
+			"service": "reconciler",
+		})
+	})
 
-	for rows.Next() {
-		var id, status string
-		var amount int64
-		if err := rows.Scan(&id, &status, &amount); err != nil {
-			log.Printf("Reconciler: Error scanning payment: %v", err)
-			continue
+	mux.HandleFunc("/statements", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			handler.ImportStatement(w, r)
+			return
 		}
-
-		totalChecked++
-
-		// 2. Check Ledger for corresponding transaction
-		refID := id
-		if status == "refunded" {
-			// For simplicity, let's just check if the refund transaction exists
-			// This is a basic check.
-			refID = "refund_" + id
+		handler.ListStatements(w, r)
+	})
+	mux.HandleFunc("/statements/", handler.GetStatement)
+	mux.HandleFunc("/reconcile", handler.Reconcile)
+	mux.HandleFunc("/review", handler.ReviewQueue)
+	mux.HandleFunc("/matches", handler.ManualMatch)
+	mux.HandleFunc("/lines/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/write-off") {
+			handler.WriteOffLine(w, r)
+			return
 		}
-
-		var existingID string
-		err := ledgerDB.QueryRowContext(ctx, "SELECT id FROM transactions WHERE reference_id = $1", refID).Scan(&existingID)
-		if err == sql.ErrNoRows {
-			log.Printf("❌ RECONCILIATION FAILURE: Missing transaction in Ledger for Payment %s (Status: %s)", id, status)
-			discrepancies++
-			continue
-		} else if err != nil {
-			log.Printf("Reconciler: Error checking Ledger: %v", err)
-			continue
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/entries/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/write-off") {
+			handler.WriteOffEntry(w, r)
+			return
 		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/rules", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			handler.SetRules(w, r)
+			return
+		}
+		handler.GetRules(w, r)
+	})
 
-		// Optional: Check entry sum for that transaction
-		// ...
+	mux.HandleFunc("/runs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			runs.StartRun(w, r)
+			return
+		}
+		runs.ListRuns(w, r)
+	})
+	mux.HandleFunc("/runs/", runs.GetRun)
+	mux.HandleFunc("/discrepancies", runs.ListDiscrepancies)
+	mux.HandleFunc("/discrepancies/", runs.GetDiscrepancy)
+
+	port := ":8091"
+	log.Printf("Reconciliation API starting on %s", port)
+	if err := http.ListenAndServe(port, monitoring.PrometheusMiddleware(mux)); err != nil {
+		log.Fatalf("Reconciliation API failed: %v", err)
 	}
-
-	log.Printf("Reconciler audit complete. Checked: %d, Discrepancies: %d", totalChecked, discrepancies)
 }
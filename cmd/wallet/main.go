@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/sapliy/fintech-ecosystem/internal/wallet/api"
 	"github.com/sapliy/fintech-ecosystem/internal/wallet/domain"
 	"github.com/sapliy/fintech-ecosystem/internal/wallet/infrastructure"
 	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
+	"github.com/sapliy/fintech-ecosystem/pkg/idempotency"
 	"github.com/sapliy/fintech-ecosystem/pkg/monitoring"
 	"github.com/sapliy/fintech-ecosystem/pkg/observability"
 	ledgerpb "github.com/sapliy/fintech-ecosystem/proto/ledger"
@@ -65,8 +67,24 @@ func main() {
 
 	handler := api.NewWalletHandler(walletService)
 
+	// Retried top-ups with an idempotency key, over HTTP or gRPC, return the
+	// first top-up's result instead of crediting the wallet again
+	redisAddr := os.Getenv("REDIS_ADDR")
+	if redisAddr == "" {
+		redisAddr = "localhost:6379"
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		log.Printf("Warning: Redis connection failed in Wallet: %v", err)
+	}
+	idempotencyOpts, err := idempotency.OptionsFromEnv()
+	if err != nil {
+		log.Fatalf("invalid idempotency configuration: %v", err)
+	}
+	guard := idempotency.New(idempotency.NewRedisStore(rdb, "sapliy:idempotency:wallet:"), idempotencyOpts...)
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("/v1/wallets/top-up", handler.TopUp)
+	mux.HandleFunc("/v1/wallets/top-up", guard.Middleware(handler.TopUp))
 	mux.HandleFunc("/v1/wallets/transfer", handler.Transfer)
 	mux.HandleFunc("/v1/wallets/", handler.GetWallet)
 
@@ -89,6 +107,7 @@ func main() {
 	chain := authutil.ChainUnaryServer(
 		monitoring.UnaryServerInterceptor("wallet"),
 		authutil.UnaryInternalTokenServerInterceptor(),
+		guard.UnaryServerInterceptor(walletpb.WalletService_TopUp_FullMethodName),
 	)
 	s := grpc.NewServer(
 		grpc.UnaryInterceptor(chain),
@@ -43,7 +43,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mRepo := &domain.MockRepository{}
 			tt.mockSetup(mRepo)
-			service := domain.NewAuthService(mRepo, nil)
+			service := domain.NewAuthService(mRepo)
 			h := &AuthHandler{service: service}
 
 			req := httptest.NewRequest("POST", "/login", strings.NewReader(tt.reqBody))
@@ -79,7 +79,7 @@ func TestAuthHandler_Register(t *testing.T) {
 						Email: "new@example.com",
 					}, nil
 				}
-				m.CreateEmailVerificationTokenFunc = func(ctx context.Context, token *domain.EmailVerificationToken) error {
+				m.CreateEmailVerificationTokenFunc = func(ctx context.Context, token *domain.EmailVerificationToken, event *domain.Event) error {
 					return nil
 				}
 			},
@@ -92,7 +92,7 @@ func TestAuthHandler_Register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mRepo := &domain.MockRepository{}
 			tt.mockSetup(mRepo)
-			service := domain.NewAuthService(mRepo, nil)
+			service := domain.NewAuthService(mRepo)
 			h := &AuthHandler{service: service}
 
 			req := httptest.NewRequest("POST", "/register", strings.NewReader(tt.reqBody))
@@ -144,7 +144,7 @@ func TestAuthHandler_VerifyEmail(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mRepo := &domain.MockRepository{}
 			tt.mockSetup(mRepo)
-			service := domain.NewAuthService(mRepo, nil)
+			service := domain.NewAuthService(mRepo)
 			h := &AuthHandler{service: service}
 
 			req := httptest.NewRequest("POST", "/verify-email", strings.NewReader(tt.reqBody))
@@ -177,7 +177,7 @@ func TestAuthHandler_ResendVerification(t *testing.T) {
 						EmailVerified: false,
 					}, nil
 				}
-				m.CreateEmailVerificationTokenFunc = func(ctx context.Context, token *domain.EmailVerificationToken) error {
+				m.CreateEmailVerificationTokenFunc = func(ctx context.Context, token *domain.EmailVerificationToken, event *domain.Event) error {
 					return nil
 				}
 				m.GetUserByIDFunc = func(ctx context.Context, id string) (*domain.User, error) {
@@ -192,7 +192,7 @@ func TestAuthHandler_ResendVerification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mRepo := &domain.MockRepository{}
 			tt.mockSetup(mRepo)
-			service := domain.NewAuthService(mRepo, nil)
+			service := domain.NewAuthService(mRepo)
 			h := &AuthHandler{service: service}
 
 			req := httptest.NewRequest("POST", "/resend-verification", strings.NewReader(tt.reqBody))
@@ -12,10 +12,10 @@ type MockRepository struct {
 	LinkExternalIdentityFunc           func(ctx context.Context, userID, provider, providerUserID string) error
 	UpdateUserPasswordFunc             func(ctx context.Context, userID, passwordHash string) error
 	SetEmailVerifiedFunc               func(ctx context.Context, userID string) error
-	CreatePasswordResetTokenFunc       func(ctx context.Context, token *PasswordResetToken) error
+	CreatePasswordResetTokenFunc       func(ctx context.Context, token *PasswordResetToken, event *Event) error
 	GetPasswordResetTokenFunc          func(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
 	MarkPasswordResetTokenUsedFunc     func(ctx context.Context, tokenHash string) error
-	CreateEmailVerificationTokenFunc   func(ctx context.Context, token *EmailVerificationToken) error
+	CreateEmailVerificationTokenFunc   func(ctx context.Context, token *EmailVerificationToken, event *Event) error
 	GetEmailVerificationTokenFunc      func(ctx context.Context, tokenHash string) (*EmailVerificationToken, error)
 	MarkEmailVerificationTokenUsedFunc func(ctx context.Context, tokenHash string) error
 	CreateOrganizationFunc             func(ctx context.Context, name, domain string) (*Organization, error)
@@ -180,9 +180,9 @@ func (m *MockRepository) SetEmailVerified(ctx context.Context, userID string) er
 	return nil
 }
 
-func (m *MockRepository) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+func (m *MockRepository) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken, event *Event) error {
 	if m.CreatePasswordResetTokenFunc != nil {
-		return m.CreatePasswordResetTokenFunc(ctx, token)
+		return m.CreatePasswordResetTokenFunc(ctx, token, event)
 	}
 	return nil
 }
@@ -203,9 +203,9 @@ func (m *MockRepository) MarkPasswordResetTokenUsed(ctx context.Context, tokenHa
 
 // Email Verification Token methods
 
-func (m *MockRepository) CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken) error {
+func (m *MockRepository) CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken, event *Event) error {
 	if m.CreateEmailVerificationTokenFunc != nil {
-		return m.CreateEmailVerificationTokenFunc(ctx, token)
+		return m.CreateEmailVerificationTokenFunc(ctx, token, event)
 	}
 	return nil
 }
@@ -246,14 +246,3 @@ func (m *MockRepository) RevokeRefreshToken(ctx context.Context, id string) erro
 	}
 	return nil
 }
-
-type MockPublisher struct {
-	PublishFunc func(ctx context.Context, topic string, event interface{}) error
-}
-
-func (m *MockPublisher) Publish(ctx context.Context, topic string, event interface{}) error {
-	if m.PublishFunc != nil {
-		return m.PublishFunc(ctx, topic, event)
-	}
-	return nil
-}
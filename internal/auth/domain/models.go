@@ -26,6 +26,21 @@ type PasswordResetToken struct {
 	CreatedAt time.Time  `json:"created_at"`
 }
 
+// Events for the notification service.
+const (
+	EventUserRegistered = "user.registered"
+	EventPasswordReset  = "password.reset"
+)
+
+// Event is a notification for other services, such as the email carrying a
+// verification link. It is stored in the outbox with the token it carries.
+type Event struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Timestamp time.Time         `json:"timestamp"`
+	Data      map[string]string `json:"data"`
+}
+
 // EmailVerificationToken represents a token for email verification.
 type EmailVerificationToken struct {
 	ID        string     `json:"id"`
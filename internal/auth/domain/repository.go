@@ -15,12 +15,16 @@ type Repository interface {
 	SetEmailVerified(ctx context.Context, userID string) error
 
 	// Password Reset Token methods
-	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error
+	// CreatePasswordResetToken stores token and, if event is not nil, queues
+	// it in the same transaction.
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken, event *Event) error
 	GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
 	MarkPasswordResetTokenUsed(ctx context.Context, tokenHash string) error
 
 	// Email Verification Token methods
-	CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken) error
+	// CreateEmailVerificationToken stores token and, if event is not nil,
+	// queues it in the same transaction.
+	CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken, event *Event) error
 	GetEmailVerificationToken(ctx context.Context, tokenHash string) (*EmailVerificationToken, error)
 	MarkEmailVerificationTokenUsed(ctx context.Context, tokenHash string) error
 
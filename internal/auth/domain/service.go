@@ -14,19 +14,13 @@ import (
 	"github.com/sapliy/fintech-ecosystem/pkg/validation"
 )
 
-type Publisher interface {
-	Publish(ctx context.Context, topic string, event interface{}) error
-}
-
 type AuthService struct {
-	repo      Repository
-	publisher Publisher
+	repo Repository
 }
 
-func NewAuthService(repo Repository, publisher Publisher) *AuthService {
+func NewAuthService(repo Repository) *AuthService {
 	return &AuthService{
-		repo:      repo,
-		publisher: publisher,
+		repo: repo,
 	}
 }
 
@@ -45,35 +39,10 @@ func (s *AuthService) CreateUser(ctx context.Context, email, passwordHash string
 		return nil, err
 	}
 
-	// Generate verification token
-	token, err := s.CreateEmailVerificationToken(ctx, user.ID)
-	if err != nil {
-		// Log error but don't fail user creation? Or fail?
-		// For now, let's log and proceed or return error.
-		// Better to fail if critical.
-		return user, nil // Proceed without token for now or implement retry
-	}
-
-	// Publish UserRegistered event
-	if s.publisher != nil {
-		baseURL := os.Getenv("APP_BASE_URL")
-		if baseURL == "" {
-			baseURL = "https://sapliy.io"
-		}
-		event := map[string]interface{}{
-			"id":        uuid.New().String(),
-			"type":      "user.registered",
-			"timestamp": time.Now().UTC(),
-			"data": map[string]string{
-				"user_id": user.ID,
-				"email":   user.Email,
-				"token":   token,
-				"link":    fmt.Sprintf("%s/verify-email?token=%s", baseURL, token),
-			},
-		}
-		if err := s.publisher.Publish(ctx, "", event); err != nil {
-			log.Printf("Failed to publish UserRegistered event: %v", err)
-		}
+	// The user.registered event carries the token and is stored with it,
+	// so the verification email goes out if and only if the token exists.
+	if _, err := s.issueEmailVerification(ctx, user); err != nil {
+		log.Printf("Failed to issue email verification for user %s: %v", user.ID, err)
 	}
 
 	return user, nil
@@ -271,33 +240,20 @@ func (s *AuthService) CreatePasswordResetToken(ctx context.Context, userID strin
 		ExpiresAt: time.Now().Add(1 * time.Hour), // 1 hour expiry
 	}
 
-	if err := s.repo.CreatePasswordResetToken(ctx, token); err != nil {
-		return "", err
+	// The password.reset event is stored with the token it carries
+	var event *Event
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err == nil && user != nil {
+		event = newEvent(EventPasswordReset, map[string]string{
+			"user_id": userID,
+			"email":   user.Email,
+			"token":   rawToken,
+			"link":    appLink("reset-password", rawToken),
+		})
 	}
 
-	// Publish PasswordResetRequested event
-	// Publish PasswordResetRequested event
-	if s.publisher != nil {
-		// Get user email
-		user, err := s.repo.GetUserByID(ctx, userID)
-		if err == nil && user != nil {
-			baseURL := os.Getenv("APP_BASE_URL")
-			if baseURL == "" {
-				baseURL = "https://sapliy.io"
-			}
-			event := map[string]interface{}{
-				"id":        uuid.New().String(),
-				"type":      "password.reset", // Was user.password_reset_requested, but events.go defines EventPasswordReset = "password.reset"
-				"timestamp": time.Now().UTC(),
-				"data": map[string]string{
-					"user_id": userID,
-					"email":   user.Email,
-					"token":   rawToken,
-					"link":    fmt.Sprintf("%s/reset-password?token=%s", baseURL, rawToken),
-				},
-			}
-			_ = s.publisher.Publish(ctx, "", event)
-		}
+	if err := s.repo.CreatePasswordResetToken(ctx, token, event); err != nil {
+		return "", err
 	}
 
 	return rawToken, nil
@@ -337,59 +293,82 @@ func (s *AuthService) ResetPassword(ctx context.Context, rawToken, newPasswordHa
 // Email Verification methods
 
 func (s *AuthService) CreateEmailVerificationToken(ctx context.Context, userID string) (string, error) {
-	rawToken, err := s.GenerateRandomString(32)
+	rawToken, token, err := s.newEmailVerificationToken(userID)
 	if err != nil {
 		return "", err
 	}
 
-	tokenHash := s.HashString(rawToken)
+	if err := s.repo.CreateEmailVerificationToken(ctx, token, nil); err != nil {
+		return "", err
+	}
 
-	token := &EmailVerificationToken{
-		ID:        uuid.New().String(),
-		UserID:    userID,
-		Token:     tokenHash,
-		ExpiresAt: time.Now().Add(15 * time.Minute), // 15 minute expiry
+	return rawToken, nil
+}
+
+// ResendEmailVerification issues a new token and sends it the same way
+// registration does.
+func (s *AuthService) ResendEmailVerification(ctx context.Context, userID string) (string, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
 	}
+	if user == nil {
+		return "", fmt.Errorf("user not found")
+	}
+	return s.issueEmailVerification(ctx, user)
+}
 
-	if err := s.repo.CreateEmailVerificationToken(ctx, token); err != nil {
+// issueEmailVerification stores a verification token for user together with
+// the user.registered event that emails it.
+func (s *AuthService) issueEmailVerification(ctx context.Context, user *User) (string, error) {
+	rawToken, token, err := s.newEmailVerificationToken(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	event := newEvent(EventUserRegistered, map[string]string{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"token":   rawToken,
+		"link":    appLink("verify-email", rawToken),
+	})
+	if err := s.repo.CreateEmailVerificationToken(ctx, token, event); err != nil {
 		return "", err
 	}
 
 	return rawToken, nil
 }
 
-func (s *AuthService) ResendEmailVerification(ctx context.Context, userID string) (string, error) {
-	token, err := s.CreateEmailVerificationToken(ctx, userID)
+func (s *AuthService) newEmailVerificationToken(userID string) (string, *EmailVerificationToken, error) {
+	rawToken, err := s.GenerateRandomString(32)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	// Publish UserRegistered event (or we could use a specific resend event, but user.registered triggers the verification email)
-	if s.publisher != nil {
-		user, err := s.repo.GetUserByID(ctx, userID)
-		if err == nil && user != nil {
-			baseURL := os.Getenv("APP_BASE_URL")
-			if baseURL == "" {
-				baseURL = "https://sapliy.io"
-			}
-			event := map[string]interface{}{
-				"id":        uuid.New().String(),
-				"type":      "user.registered", // Consistent with CreateUser
-				"timestamp": time.Now().UTC(),
-				"data": map[string]string{
-					"user_id": user.ID,
-					"email":   user.Email,
-					"token":   token,
-					"link":    fmt.Sprintf("%s/verify-email?token=%s", baseURL, token),
-				},
-			}
-			if err := s.publisher.Publish(ctx, "", event); err != nil {
-				log.Printf("Failed to publish UserRegistered event for resend: %v", err)
-			}
-		}
+	return rawToken, &EmailVerificationToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Token:     s.HashString(rawToken),
+		ExpiresAt: time.Now().Add(15 * time.Minute), // 15 minute expiry
+	}, nil
+}
+
+func newEvent(eventType string, data map[string]string) *Event {
+	return &Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
 	}
+}
 
-	return token, nil
+// appLink builds a link into the dashboard carrying token.
+func appLink(path, token string) string {
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://sapliy.io"
+	}
+	return fmt.Sprintf("%s/%s?token=%s", baseURL, path, token)
 }
 
 func (s *AuthService) VerifyEmail(ctx context.Context, rawToken string) error {
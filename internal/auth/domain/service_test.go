@@ -21,22 +21,14 @@ func TestAuthService_CreateUser(t *testing.T) {
 			}
 			return &User{ID: userID, Email: e}, nil
 		},
-		CreateEmailVerificationTokenFunc: func(ctx context.Context, t *EmailVerificationToken) error {
-			if t.UserID != userID {
+		CreateEmailVerificationTokenFunc: func(ctx context.Context, tok *EmailVerificationToken, event *Event) error {
+			if tok.UserID != userID {
 				return errors.New("unexpected userID in verification token")
 			}
-			t.Token = "hashed-token" // Simulate hashing in repo if needed, but service returns raw
-			return nil
-		},
-	}
-
-	publisher := &MockPublisher{
-		PublishFunc: func(ctx context.Context, topic string, event interface{}) error {
-			evt := event.(map[string]interface{})
-			if evt["type"] != "user.registered" {
-				t.Errorf("expected event type user.registered, got %v", evt["type"])
+			if event == nil || event.Type != EventUserRegistered {
+				t.Fatalf("expected a user.registered event with the token, got %v", event)
 			}
-			data := evt["data"].(map[string]string)
+			data := event.Data
 			if data["user_id"] != userID || data["email"] != email {
 				t.Errorf("unexpected event data: %v", data)
 			}
@@ -47,7 +39,7 @@ func TestAuthService_CreateUser(t *testing.T) {
 		},
 	}
 
-	service := NewAuthService(repo, publisher)
+	service := NewAuthService(repo)
 	user, err := service.CreateUser(ctx, email, passwordHash)
 
 	if err != nil {
@@ -70,29 +62,21 @@ func TestAuthService_CreatePasswordResetToken(t *testing.T) {
 			}
 			return &User{ID: userID, Email: email}, nil
 		},
-		CreatePasswordResetTokenFunc: func(ctx context.Context, token *PasswordResetToken) error {
+		CreatePasswordResetTokenFunc: func(ctx context.Context, token *PasswordResetToken, event *Event) error {
 			if token.UserID != userID {
 				return errors.New("unexpected userID in reset token")
 			}
-			return nil
-		},
-	}
-
-	publisher := &MockPublisher{
-		PublishFunc: func(ctx context.Context, topic string, event interface{}) error {
-			evt := event.(map[string]interface{})
-			if evt["type"] != "password.reset" {
-				t.Errorf("expected event type password.reset, got %v", evt["type"])
+			if event == nil || event.Type != EventPasswordReset {
+				t.Fatalf("expected a password.reset event with the token, got %v", event)
 			}
-			data := evt["data"].(map[string]string)
-			if data["email"] != email || data["user_id"] != userID {
-				t.Errorf("unexpected event data: %v", data)
+			if event.Data["email"] != email || event.Data["user_id"] != userID {
+				t.Errorf("unexpected event data: %v", event.Data)
 			}
 			return nil
 		},
 	}
 
-	service := NewAuthService(repo, publisher)
+	service := NewAuthService(repo)
 	token, err := service.CreatePasswordResetToken(ctx, userID)
 
 	if err != nil {
@@ -126,7 +110,7 @@ func TestAuthService_VerifyEmail(t *testing.T) {
 		},
 	}
 
-	service := NewAuthService(repo, nil)
+	service := NewAuthService(repo)
 	err := service.VerifyEmail(ctx, rawToken)
 
 	if err != nil {
@@ -143,7 +127,7 @@ func TestAuthService_VerifyEmail_Expired(t *testing.T) {
 			}, nil
 		},
 	}
-	service := NewAuthService(repo, nil)
+	service := NewAuthService(repo)
 	err := service.VerifyEmail(ctx, "token")
 	if err == nil || !strings.Contains(err.Error(), "expired") {
 		t.Errorf("expected expired error, got %v", err)
@@ -161,7 +145,7 @@ func TestAuthService_VerifyEmail_AlreadyUsed(t *testing.T) {
 			}, nil
 		},
 	}
-	service := NewAuthService(repo, nil)
+	service := NewAuthService(repo)
 	err := service.VerifyEmail(ctx, "token")
 	if err == nil || !strings.Contains(err.Error(), "already used") {
 		t.Errorf("expected already used error, got %v", err)
@@ -194,7 +178,7 @@ func TestAuthService_HasPermission(t *testing.T) {
 					return &Membership{Role: tt.userRole}, nil
 				},
 			}
-			service := NewAuthService(repo, nil)
+			service := NewAuthService(repo)
 			got, err := service.HasPermission(ctx, userID, orgID, tt.requiredRole)
 			if err != nil {
 				t.Fatalf("HasPermission failed: %v", err)
@@ -215,7 +199,7 @@ func TestAuthService_ValidateOAuthToken_Expired(t *testing.T) {
 			}, nil
 		},
 	}
-	service := NewAuthService(repo, nil)
+	service := NewAuthService(repo)
 	_, err := service.ValidateOAuthToken(ctx, "expired-token")
 	if err == nil || !strings.Contains(err.Error(), "expired") {
 		t.Errorf("expected expired error, got %v", err)
@@ -232,7 +216,7 @@ func TestAuthService_ValidateRefreshToken_Revoked(t *testing.T) {
 			}, nil
 		},
 	}
-	service := NewAuthService(repo, nil)
+	service := NewAuthService(repo)
 	_, err := service.ValidateRefreshToken(ctx, "token")
 	if err == nil || !strings.Contains(err.Error(), "revoked") {
 		t.Errorf("expected revoked error, got %v", err)
@@ -249,7 +233,7 @@ func TestAuthService_ValidateRefreshToken_Expired(t *testing.T) {
 			}, nil
 		},
 	}
-	service := NewAuthService(repo, nil)
+	service := NewAuthService(repo)
 	_, err := service.ValidateRefreshToken(ctx, "token")
 	if err == nil || !strings.Contains(err.Error(), "expired") {
 		t.Errorf("expected expired error, got %v", err)
@@ -262,11 +246,11 @@ func TestAuthService_CreateUser_VerificationTokenFail(t *testing.T) {
 		CreateUserFunc: func(ctx context.Context, email, pwd string) (*User, error) {
 			return &User{ID: "u1", Email: email}, nil
 		},
-		CreateEmailVerificationTokenFunc: func(ctx context.Context, token *EmailVerificationToken) error {
+		CreateEmailVerificationTokenFunc: func(ctx context.Context, token *EmailVerificationToken, event *Event) error {
 			return errors.New("db error")
 		},
 	}
-	service := NewAuthService(repo, nil)
+	service := NewAuthService(repo)
 	user, err := service.CreateUser(ctx, "test@example.com", "long-password")
 	if err != nil {
 		t.Fatalf("CreateUser should not fail if verification token fails currently: %v", err)
@@ -3,14 +3,21 @@ package infrastructure
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/sapliy/fintech-ecosystem/internal/auth/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
 )
 
+// EventsTopic is the Kafka topic auth events are published to; the
+// notification service consumes it.
+const EventsTopic = "payment_events"
+
 type SQLRepository struct {
-	db *sql.DB
+	db          *sql.DB
+	eventsTopic string
 }
 
 func toNullString(s string) sql.NullString {
@@ -21,7 +28,36 @@ func toNullString(s string) sql.NullString {
 }
 
 func NewSQLRepository(db *sql.DB) *SQLRepository {
-	return &SQLRepository{db: db}
+	return &SQLRepository{db: db, eventsTopic: EventsTopic}
+}
+
+// WithEventsTopic returns a repository that queues events for topic.
+func (r *SQLRepository) WithEventsTopic(topic string) *SQLRepository {
+	return &SQLRepository{db: r.db, eventsTopic: topic}
+}
+
+// insertWithEvent runs an insert and, if event is not nil, queues the event
+// in the same transaction.
+func (r *SQLRepository) insertWithEvent(ctx context.Context, event *domain.Event, query string, args ...any) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	if event != nil {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s event: %w", event.Type, err)
+		}
+		if err := outbox.Write(ctx, tx, outbox.Event{Topic: r.eventsTopic, Type: event.Type, Key: event.Data["user_id"], Payload: payload}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 // User methods
@@ -126,8 +162,8 @@ func (r *SQLRepository) SetEmailVerified(ctx context.Context, userID string) err
 
 // Password Reset Token methods
 
-func (r *SQLRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
-	_, err := r.db.ExecContext(ctx,
+func (r *SQLRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken, event *domain.Event) error {
+	err := r.insertWithEvent(ctx, event,
 		`INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)`,
 		token.ID, token.UserID, token.Token, token.ExpiresAt)
 	if err != nil {
@@ -166,8 +202,8 @@ func (r *SQLRepository) MarkPasswordResetTokenUsed(ctx context.Context, tokenHas
 
 // Email Verification Token methods
 
-func (r *SQLRepository) CreateEmailVerificationToken(ctx context.Context, token *domain.EmailVerificationToken) error {
-	_, err := r.db.ExecContext(ctx,
+func (r *SQLRepository) CreateEmailVerificationToken(ctx context.Context, token *domain.EmailVerificationToken, event *domain.Event) error {
+	err := r.insertWithEvent(ctx, event,
 		`INSERT INTO email_verification_tokens (id, user_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)`,
 		token.ID, token.UserID, token.Token, token.ExpiresAt)
 	if err != nil {
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sapliy/fintech-ecosystem/internal/billing/domain"
+	pb "github.com/sapliy/fintech-ecosystem/proto/billing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type BillingGRPCServer struct {
+	pb.UnimplementedBillingServiceServer
+	service *domain.BillingService
+}
+
+func NewBillingGRPCServer(service *domain.BillingService) *BillingGRPCServer {
+	return &BillingGRPCServer{service: service}
+}
+
+func (s *BillingGRPCServer) CreateSubscription(ctx context.Context, req *pb.CreateSubscriptionRequest) (*pb.Subscription, error) {
+	if req.UserId == "" || req.PlanId == "" || req.CustomerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id, plan_id and customer_id are required")
+	}
+	sub, err := s.service.CreateSubscription(ctx, req.UserId, req.OrgId, req.PlanId, req.CustomerId, req.PaymentMethodId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(sub), nil
+}
+
+func (s *BillingGRPCServer) CancelSubscription(ctx context.Context, req *pb.CancelSubscriptionRequest) (*pb.Subscription, error) {
+	sub, err := s.service.CancelSubscription(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(sub), nil
+}
+
+func (s *BillingGRPCServer) GetSubscription(ctx context.Context, req *pb.GetSubscriptionRequest) (*pb.Subscription, error) {
+	sub, err := s.service.GetSubscription(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(sub), nil
+}
+
+func toStatus(err error) error {
+	if errors.Is(err, domain.ErrPlanNotFound) || errors.Is(err, domain.ErrSubscriptionNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+func toProto(sub *domain.Subscription) *pb.Subscription {
+	out := &pb.Subscription{
+		Id:                 sub.ID,
+		UserId:             sub.UserID,
+		OrgId:              sub.OrgID,
+		PlanId:             sub.PlanID,
+		CustomerId:         sub.CustomerID,
+		PaymentMethodId:    sub.PaymentMethodID,
+		Status:             string(sub.Status),
+		CurrentPeriodStart: timestamppb.New(sub.CurrentPeriodStart),
+		CurrentPeriodEnd:   timestamppb.New(sub.CurrentPeriodEnd),
+		CreatedAt:          timestamppb.New(sub.CreatedAt),
+	}
+	if sub.CanceledAt != nil {
+		out.CanceledAt = timestamppb.New(*sub.CanceledAt)
+	}
+	return out
+}
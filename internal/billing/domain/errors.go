@@ -5,4 +5,9 @@ import "errors"
 var (
 	ErrPlanNotFound         = errors.New("plan not found")
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrNoCustomer is returned when renewing a subscription that has no
+	// customer to charge.
+	ErrNoCustomer = errors.New("subscription has no customer to charge")
+	// ErrPaymentFailed is returned when a renewal's payment was declined.
+	ErrPaymentFailed = errors.New("payment failed")
 )
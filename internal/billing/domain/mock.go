@@ -7,7 +7,7 @@ import (
 type MockRepository struct {
 	CreateSubscriptionFunc   func(ctx context.Context, sub *Subscription) error
 	GetSubscriptionFunc      func(ctx context.Context, id string) (*Subscription, error)
-	UpdateSubscriptionFunc   func(ctx context.Context, sub *Subscription) error
+	UpdateSubscriptionFunc   func(ctx context.Context, sub *Subscription, eventType string) error
 	ListDueSubscriptionsFunc func(ctx context.Context) ([]*Subscription, error)
 	GetPlanFunc              func(ctx context.Context, id string) (*Plan, error)
 }
@@ -20,8 +20,8 @@ func (m *MockRepository) GetSubscription(ctx context.Context, id string) (*Subsc
 	return m.GetSubscriptionFunc(ctx, id)
 }
 
-func (m *MockRepository) UpdateSubscription(ctx context.Context, sub *Subscription) error {
-	return m.UpdateSubscriptionFunc(ctx, sub)
+func (m *MockRepository) UpdateSubscription(ctx context.Context, sub *Subscription, eventType string) error {
+	return m.UpdateSubscriptionFunc(ctx, sub, eventType)
 }
 
 func (m *MockRepository) ListDueSubscriptions(ctx context.Context) ([]*Subscription, error) {
@@ -13,6 +13,14 @@ const (
 	SubscriptionStatusIncomplete SubscriptionStatus = "incomplete"
 )
 
+// Subscription events, published to other services through the outbox.
+const (
+	EventSubscriptionCreated  = "subscription.created"
+	EventSubscriptionRenewed  = "subscription.renewed"
+	EventSubscriptionPastDue  = "subscription.past_due"
+	EventSubscriptionCanceled = "subscription.canceled"
+)
+
 type Plan struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
@@ -33,6 +41,11 @@ type Subscription struct {
 	CanceledAt         *time.Time         `json:"canceled_at,omitempty"`
 	CreatedAt          time.Time          `json:"created_at"`
 	UpdatedAt          time.Time          `json:"updated_at"`
+	// CustomerID is the customer in the payments service whose saved
+	// payment method pays the renewals: PaymentMethodID, or else the
+	// customer's default one.
+	CustomerID      string `json:"customer_id"`
+	PaymentMethodID string `json:"payment_method_id,omitempty"`
 }
 
 type Invoice struct {
@@ -46,3 +59,19 @@ type Invoice struct {
 	PaymentIntentID string    `json:"payment_intent_id,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 }
+
+// Charge is an off-session payment of a subscription renewal, made without
+// the customer present.
+type Charge struct {
+	// IdempotencyKey identifies the renewal, so that retrying it charges
+	// the customer once.
+	IdempotencyKey string
+	UserID         string
+	OrgID          string
+	CustomerID     string
+	// PaymentMethodID defaults to the customer's default payment method.
+	PaymentMethodID string
+	Amount          int64
+	Currency        string
+	Description     string
+}
@@ -8,9 +8,13 @@ import (
 )
 
 type Repository interface {
+	// CreateSubscription stores sub and queues EventSubscriptionCreated in
+	// the same transaction.
 	CreateSubscription(ctx context.Context, sub *Subscription) error
 	GetSubscription(ctx context.Context, id string) (*Subscription, error)
-	UpdateSubscription(ctx context.Context, sub *Subscription) error
+	// UpdateSubscription stores sub and, unless eventType is empty, queues
+	// that event in the same transaction.
+	UpdateSubscription(ctx context.Context, sub *Subscription, eventType string) error
 	ListDueSubscriptions(ctx context.Context) ([]*Subscription, error)
 	GetPlan(ctx context.Context, id string) (*Plan, error)
 }
@@ -23,7 +27,10 @@ func NewBillingService(repo Repository) *BillingService {
 	return &BillingService{repo: repo}
 }
 
-func (s *BillingService) CreateSubscription(ctx context.Context, userID, orgID, planID string) (*Subscription, error) {
+// CreateSubscription subscribes a customer to a plan. Renewals are charged
+// to paymentMethodID, or to the customer's default payment method when it
+// is empty.
+func (s *BillingService) CreateSubscription(ctx context.Context, userID, orgID, planID, customerID, paymentMethodID string) (*Subscription, error) {
 	plan, err := s.repo.GetPlan(ctx, planID)
 	if err != nil {
 		return nil, err
@@ -37,6 +44,8 @@ func (s *BillingService) CreateSubscription(ctx context.Context, userID, orgID,
 		UserID:             userID,
 		OrgID:              orgID,
 		PlanID:             planID,
+		CustomerID:         customerID,
+		PaymentMethodID:    paymentMethodID,
 		Status:             SubscriptionStatusActive,
 		CurrentPeriodStart: time.Now(),
 		CurrentPeriodEnd:   CalculateNextPeriod(time.Now(), plan.Interval),
@@ -51,6 +60,17 @@ func (s *BillingService) CreateSubscription(ctx context.Context, userID, orgID,
 	return sub, nil
 }
 
+func (s *BillingService) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
 func (s *BillingService) CancelSubscription(ctx context.Context, id string) (*Subscription, error) {
 	sub, err := s.repo.GetSubscription(ctx, id)
 	if err != nil {
@@ -64,7 +84,7 @@ func (s *BillingService) CancelSubscription(ctx context.Context, id string) (*Su
 	sub.Status = SubscriptionStatusCanceled
 	sub.CanceledAt = &now
 
-	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+	if err := s.repo.UpdateSubscription(ctx, sub, EventSubscriptionCanceled); err != nil {
 		return nil, err
 	}
 
@@ -20,7 +20,7 @@ func TestBillingService_CreateSubscription(t *testing.T) {
 			return &Plan{ID: id, Interval: "month"}, nil
 		},
 		CreateSubscriptionFunc: func(ctx context.Context, sub *Subscription) error {
-			if sub.UserID != userID || sub.PlanID != planID {
+			if sub.UserID != userID || sub.PlanID != planID || sub.CustomerID != "cus-123" {
 				return errors.New("unexpected subscription data")
 			}
 			return nil
@@ -28,7 +28,7 @@ func TestBillingService_CreateSubscription(t *testing.T) {
 	}
 
 	service := NewBillingService(repo)
-	sub, err := service.CreateSubscription(ctx, userID, orgID, planID)
+	sub, err := service.CreateSubscription(ctx, userID, orgID, planID, "cus-123", "")
 
 	if err != nil {
 		t.Fatalf("CreateSubscription failed: %v", err)
@@ -49,10 +49,13 @@ func TestBillingService_CancelSubscription(t *testing.T) {
 		GetSubscriptionFunc: func(ctx context.Context, id string) (*Subscription, error) {
 			return &Subscription{ID: id, Status: SubscriptionStatusActive}, nil
 		},
-		UpdateSubscriptionFunc: func(ctx context.Context, sub *Subscription) error {
+		UpdateSubscriptionFunc: func(ctx context.Context, sub *Subscription, eventType string) error {
 			if sub.Status != SubscriptionStatusCanceled {
 				return errors.New("expected status to be canceled")
 			}
+			if eventType != EventSubscriptionCanceled {
+				return errors.New("expected a subscription.canceled event")
+			}
 			return nil
 		},
 	}
@@ -0,0 +1,31 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapliy/fintech-ecosystem/internal/billing/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
+)
+
+// EventsTopic is the Kafka topic billing events are published to.
+const EventsTopic = "billing-events"
+
+// writeEvent queues a subscription event in tx, keyed by subscription so its
+// events are consumed in order.
+func writeEvent(ctx context.Context, tx *sql.Tx, eventType string, sub *domain.Subscription) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":         uuid.New().String(),
+		"type":       eventType,
+		"created_at": time.Now().UTC(),
+		"data":       sub,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return outbox.Write(ctx, tx, outbox.Event{Topic: EventsTopic, Type: eventType, Key: sub.ID, Payload: payload})
+}
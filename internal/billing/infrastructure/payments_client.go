@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sapliy/fintech-ecosystem/internal/billing/domain"
+)
+
+// PaymentsClient charges renewals through the payments service's HTTP API:
+// it creates an intent for the customer and confirms it off-session with
+// the saved payment method.
+type PaymentsClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewPaymentsClient(baseURL string, client *http.Client) *PaymentsClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &PaymentsClient{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+type paymentIntent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// Charge returns the ID of the succeeded intent, or ErrPaymentFailed if the
+// payment was declined. Both requests carry idempotency keys derived from
+// the charge's, so a retried renewal reuses the intent of the first
+// attempt.
+func (c *PaymentsClient) Charge(ctx context.Context, charge domain.Charge) (string, error) {
+	var intent paymentIntent
+	err := c.post(ctx, "/intents", charge, charge.IdempotencyKey+":create", http.StatusCreated, map[string]any{
+		"amount":      charge.Amount,
+		"currency":    charge.Currency,
+		"description": charge.Description,
+		"customer_id": charge.CustomerID,
+	}, &intent)
+	if err != nil {
+		return "", fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	err = c.post(ctx, "/intents/"+intent.ID+"/confirm", charge, charge.IdempotencyKey+":confirm", http.StatusOK, map[string]any{
+		"payment_method_id": charge.PaymentMethodID,
+		"off_session":       true,
+	}, &intent)
+	if err != nil {
+		return "", fmt.Errorf("failed to confirm payment intent %s: %w", intent.ID, err)
+	}
+	if intent.Status != "succeeded" {
+		return "", fmt.Errorf("%w: payment intent %s is %s", domain.ErrPaymentFailed, intent.ID, intent.Status)
+	}
+	return intent.ID, nil
+}
+
+func (c *PaymentsClient) post(ctx context.Context, path string, charge domain.Charge, idempotencyKey string, wantStatus int, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", charge.UserID)
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != wantStatus {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("payments service answered %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -16,34 +16,66 @@ func NewSQLRepository(db *sql.DB) *SQLRepository {
 	return &SQLRepository{db: db}
 }
 
+// nullString stores an empty string as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 func (r *SQLRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
-		INSERT INTO subscriptions (id, user_id, org_id, plan_id, status, current_period_start, current_period_end, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.db.ExecContext(ctx, query, sub.ID, sub.UserID, sub.OrgID, sub.PlanID, sub.Status, sub.CurrentPeriodStart, sub.CurrentPeriodEnd, sub.CreatedAt, sub.UpdatedAt)
-	return err
+		INSERT INTO subscriptions (id, user_id, org_id, plan_id, status, current_period_start, current_period_end, created_at, updated_at, customer_id, payment_method_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	if _, err := tx.ExecContext(ctx, query, sub.ID, sub.UserID, sub.OrgID, sub.PlanID, sub.Status, sub.CurrentPeriodStart, sub.CurrentPeriodEnd, sub.CreatedAt, sub.UpdatedAt,
+		nullString(sub.CustomerID), nullString(sub.PaymentMethodID)); err != nil {
+		return err
+	}
+	if err := writeEvent(ctx, tx, domain.EventSubscriptionCreated, sub); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *SQLRepository) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
-	query := `SELECT id, user_id, org_id, plan_id, status, current_period_start, current_period_end, canceled_at, created_at, updated_at FROM subscriptions WHERE id = $1`
+	query := `SELECT id, user_id, org_id, plan_id, status, current_period_start, current_period_end, canceled_at, created_at, updated_at, customer_id, payment_method_id FROM subscriptions WHERE id = $1`
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var sub domain.Subscription
-	err := row.Scan(&sub.ID, &sub.UserID, &sub.OrgID, &sub.PlanID, &sub.Status, &sub.CurrentPeriodStart, &sub.CurrentPeriodEnd, &sub.CanceledAt, &sub.CreatedAt, &sub.UpdatedAt)
+	var customerID, paymentMethodID sql.NullString
+	err := row.Scan(&sub.ID, &sub.UserID, &sub.OrgID, &sub.PlanID, &sub.Status, &sub.CurrentPeriodStart, &sub.CurrentPeriodEnd, &sub.CanceledAt, &sub.CreatedAt, &sub.UpdatedAt, &customerID, &paymentMethodID)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
+	sub.CustomerID, sub.PaymentMethodID = customerID.String, paymentMethodID.String
 	return &sub, err
 }
 
-func (r *SQLRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+func (r *SQLRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription, eventType string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `UPDATE subscriptions SET status = $1, current_period_start = $2, current_period_end = $3, canceled_at = $4, updated_at = $5 WHERE id = $6`
-	_, err := r.db.ExecContext(ctx, query, sub.Status, sub.CurrentPeriodStart, sub.CurrentPeriodEnd, sub.CanceledAt, time.Now(), sub.ID)
-	return err
+	if _, err := tx.ExecContext(ctx, query, sub.Status, sub.CurrentPeriodStart, sub.CurrentPeriodEnd, sub.CanceledAt, time.Now(), sub.ID); err != nil {
+		return err
+	}
+	if eventType != "" {
+		if err := writeEvent(ctx, tx, eventType, sub); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (r *SQLRepository) ListDueSubscriptions(ctx context.Context) ([]*domain.Subscription, error) {
-	query := `SELECT id, user_id, org_id, plan_id, status, current_period_start, current_period_end, created_at, updated_at FROM subscriptions WHERE status = 'active' AND current_period_end <= $1`
+	query := `SELECT id, user_id, org_id, plan_id, status, current_period_start, current_period_end, created_at, updated_at, customer_id, payment_method_id FROM subscriptions WHERE status = 'active' AND current_period_end <= $1`
 	rows, err := r.db.QueryContext(ctx, query, time.Now())
 	if err != nil {
 		return nil, err
@@ -53,9 +85,11 @@ func (r *SQLRepository) ListDueSubscriptions(ctx context.Context) ([]*domain.Sub
 	var subs []*domain.Subscription
 	for rows.Next() {
 		var sub domain.Subscription
-		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.OrgID, &sub.PlanID, &sub.Status, &sub.CurrentPeriodStart, &sub.CurrentPeriodEnd, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		var customerID, paymentMethodID sql.NullString
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.OrgID, &sub.PlanID, &sub.Status, &sub.CurrentPeriodStart, &sub.CurrentPeriodEnd, &sub.CreatedAt, &sub.UpdatedAt, &customerID, &paymentMethodID); err != nil {
 			return nil, err
 		}
+		sub.CustomerID, sub.PaymentMethodID = customerID.String, paymentMethodID.String
 		subs = append(subs, &sub)
 	}
 	return subs, nil
@@ -2,14 +2,17 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/sapliy/fintech-ecosystem/internal/billing/domain"
 )
 
+// PaymentClient charges a customer's saved payment method off-session and
+// returns the ID of the payment.
 type PaymentClient interface {
-	CreatePayment(ctx context.Context, userID, orgID string, amount int64, currency string) (string, error)
+	Charge(ctx context.Context, charge domain.Charge) (string, error)
 }
 
 type SubscriptionWorker struct {
@@ -59,14 +62,21 @@ func (w *SubscriptionWorker) processSubscription(ctx context.Context, sub *domai
 	if err != nil {
 		return err
 	}
+	if plan == nil {
+		return domain.ErrPlanNotFound
+	}
 
-	// Create Payment
+	// Charge the customer's saved payment method. The key is the same for
+	// every attempt at this period, so a retry after a timeout cannot charge
+	// twice.
 	log.Printf("Worker: Processing renewal for sub %s, user %s", sub.ID, sub.UserID)
-	paymentID, err := w.paymentClient.CreatePayment(ctx, sub.UserID, sub.OrgID, plan.Amount, plan.Currency)
+	paymentID, err := w.charge(ctx, sub, plan)
 	if err != nil {
-		// In a real scenario, we'd mark the sub as past_due after retries
 		sub.Status = domain.SubscriptionStatusPastDue
-		_ = w.repo.UpdateSubscription(ctx, sub)
+		sub.UpdatedAt = time.Now()
+		if updateErr := w.repo.UpdateSubscription(ctx, sub, domain.EventSubscriptionPastDue); updateErr != nil {
+			log.Printf("Worker: failed to mark sub %s past due: %v", sub.ID, updateErr)
+		}
 		return err
 	}
 
@@ -77,5 +87,21 @@ func (w *SubscriptionWorker) processSubscription(ctx context.Context, sub *domai
 	sub.UpdatedAt = time.Now()
 
 	log.Printf("Worker: Sub %s renewed successfully, payment ID: %s", sub.ID, paymentID)
-	return w.repo.UpdateSubscription(ctx, sub)
+	return w.repo.UpdateSubscription(ctx, sub, domain.EventSubscriptionRenewed)
+}
+
+func (w *SubscriptionWorker) charge(ctx context.Context, sub *domain.Subscription, plan *domain.Plan) (string, error) {
+	if sub.CustomerID == "" {
+		return "", domain.ErrNoCustomer
+	}
+	return w.paymentClient.Charge(ctx, domain.Charge{
+		IdempotencyKey:  fmt.Sprintf("subscription-%s-%d", sub.ID, sub.CurrentPeriodEnd.Unix()),
+		UserID:          sub.UserID,
+		OrgID:           sub.OrgID,
+		CustomerID:      sub.CustomerID,
+		PaymentMethodID: sub.PaymentMethodID,
+		Amount:          plan.Amount,
+		Currency:        plan.Currency,
+		Description:     fmt.Sprintf("Subscription %s renewal", sub.ID),
+	})
 }
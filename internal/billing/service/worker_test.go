@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/billing/domain"
+)
+
+type stubPaymentClient struct {
+	charges []domain.Charge
+	err     error
+}
+
+func (c *stubPaymentClient) Charge(ctx context.Context, charge domain.Charge) (string, error) {
+	c.charges = append(c.charges, charge)
+	if c.err != nil {
+		return "", c.err
+	}
+	return "pi_1", nil
+}
+
+func TestSubscriptionWorker_ProcessSubscription(t *testing.T) {
+	periodEnd := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		customerID  string
+		chargeErr   error
+		wantStatus  domain.SubscriptionStatus
+		wantEvent   string
+		wantCharges int
+	}{
+		{name: "charged", customerID: "cus_1", wantStatus: domain.SubscriptionStatusActive, wantEvent: domain.EventSubscriptionRenewed, wantCharges: 1},
+		{name: "declined", customerID: "cus_1", chargeErr: domain.ErrPaymentFailed, wantStatus: domain.SubscriptionStatusPastDue, wantEvent: domain.EventSubscriptionPastDue, wantCharges: 1},
+		{name: "no customer", wantStatus: domain.SubscriptionStatusPastDue, wantEvent: domain.EventSubscriptionPastDue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event string
+			repo := &domain.MockRepository{
+				GetPlanFunc: func(ctx context.Context, id string) (*domain.Plan, error) {
+					return &domain.Plan{ID: id, Amount: 990, Currency: "USD", Interval: "month"}, nil
+				},
+				UpdateSubscriptionFunc: func(ctx context.Context, sub *domain.Subscription, eventType string) error {
+					event = eventType
+					return nil
+				},
+			}
+			payments := &stubPaymentClient{err: tt.chargeErr}
+			w := NewSubscriptionWorker(repo, payments, time.Minute)
+
+			sub := &domain.Subscription{
+				ID:                 "sub_1",
+				UserID:             "user_1",
+				PlanID:             "plan_1",
+				CustomerID:         tt.customerID,
+				PaymentMethodID:    "pm_1",
+				Status:             domain.SubscriptionStatusActive,
+				CurrentPeriodStart: periodEnd.AddDate(0, -1, 0),
+				CurrentPeriodEnd:   periodEnd,
+			}
+			err := w.processSubscription(context.Background(), sub)
+			if tt.wantStatus == domain.SubscriptionStatusActive && err != nil {
+				t.Fatalf("processSubscription() error = %v", err)
+			}
+			if tt.wantStatus == domain.SubscriptionStatusPastDue && err == nil {
+				t.Fatal("processSubscription() succeeded, want an error")
+			}
+
+			if sub.Status != tt.wantStatus || event != tt.wantEvent {
+				t.Errorf("status %s with event %q, want %s with %q", sub.Status, event, tt.wantStatus, tt.wantEvent)
+			}
+			if len(payments.charges) != tt.wantCharges {
+				t.Fatalf("charged %d times, want %d", len(payments.charges), tt.wantCharges)
+			}
+			if tt.wantCharges == 0 {
+				return
+			}
+			charge := payments.charges[0]
+			if charge.CustomerID != "cus_1" || charge.PaymentMethodID != "pm_1" || charge.Amount != 990 ||
+				charge.IdempotencyKey != "subscription-sub_1-1772323200" {
+				t.Errorf("charge = %+v, want 990 USD to pm_1 keyed by the period", charge)
+			}
+			if tt.wantStatus == domain.SubscriptionStatusActive && !sub.CurrentPeriodStart.Equal(periodEnd) {
+				t.Errorf("period starts %v, want %v", sub.CurrentPeriodStart, periodEnd)
+			}
+		})
+	}
+}
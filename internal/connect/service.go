@@ -4,6 +4,8 @@ import (
 	"context"
 
 	pb "github.com/sapliy/fintech-ecosystem/proto/connect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -43,7 +45,7 @@ func (s *Service) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*p
 		return nil, err
 	}
 	if acc == nil {
-		return nil, nil // Or return Not Found error
+		return nil, status.Errorf(codes.NotFound, "account %s not found", req.Id)
 	}
 
 	return mapAccountToProto(acc), nil
@@ -55,7 +57,7 @@ func (s *Service) UpdateAccount(ctx context.Context, req *pb.UpdateAccountReques
 		return nil, err
 	}
 	if acc == nil {
-		return nil, nil
+		return nil, status.Errorf(codes.NotFound, "account %s not found", req.Id)
 	}
 
 	if req.Email != "" {
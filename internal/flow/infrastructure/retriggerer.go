@@ -2,22 +2,38 @@ package infrastructure
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/sapliy/fintech-ecosystem/internal/flow/domain"
-	"github.com/sapliy/fintech-ecosystem/pkg/messaging"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
 )
 
-type KafkaEventRetriggerer struct {
-	producer *messaging.KafkaProducer
+// EventsTopic is the Kafka topic the flow runner takes triggering events
+// from.
+const EventsTopic = "payments"
+
+// OutboxEventRetriggerer queues replayed events for the flow runner. Replays
+// are not recorded as events of their own, so past events list only what
+// actually happened.
+type OutboxEventRetriggerer struct {
+	db *sql.DB
 }
 
-func NewKafkaEventRetriggerer(producer *messaging.KafkaProducer) *KafkaEventRetriggerer {
-	return &KafkaEventRetriggerer{
-		producer: producer,
-	}
+func NewOutboxEventRetriggerer(db *sql.DB) *OutboxEventRetriggerer {
+	return &OutboxEventRetriggerer{db: db}
 }
 
-func (r *KafkaEventRetriggerer) RetriggerEvent(ctx context.Context, event *domain.Event) error {
-	// Re-publish the event to Kafka to trigger standard flow processing
-	return r.producer.Publish(ctx, event.ID, event.Data)
+func (r *OutboxEventRetriggerer) RetriggerEvent(ctx context.Context, event *domain.Event) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Keyed by zone so a zone's replays run in the order they were made. The
+	// message carries the original event data, as first published.
+	if err := outbox.Write(ctx, tx, outbox.Event{Topic: EventsTopic, Type: event.Type, Key: event.ZoneID, Payload: event.Data}); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
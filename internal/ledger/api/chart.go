@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+// GetChartOfAccounts returns the zone's accounts as a tree with roll-up
+// balances.
+func (h *LedgerHandler) GetChartOfAccounts(w http.ResponseWriter, r *http.Request) {
+	chart, err := h.service.GetChartOfAccounts(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"))
+	if err != nil {
+		apierror.Internal("Error retrieving chart of accounts").Write(w)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, map[string]interface{}{"accounts": chart})
+}
+
+// SeedChartOfAccounts creates the accounts of a chart template in the zone.
+func (h *LedgerHandler) SeedChartOfAccounts(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Template string `json:"template"`
+		Currency string `json:"currency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+	if req.Template == "" {
+		req.Template = domain.ChartFintechBasic
+	}
+
+	accounts, err := h.service.SeedChartOfAccounts(r.Context(), req.Template, r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), req.Currency)
+	if err != nil {
+		if domain.IsValidationError(err) {
+			apierror.BadRequest(err.Error()).Write(w)
+			return
+		}
+		apierror.Internal("Failed to seed chart of accounts").Write(w)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, map[string]interface{}{"accounts": accounts})
+}
+
+// GetAccountRollup returns an account with its subtree's balances rolled up.
+func (h *LedgerHandler) GetAccountRollup(w http.ResponseWriter, r *http.Request) {
+	node, err := h.service.GetRollupBalance(r.Context(), jsonutil.GetIDAfter(r, "accounts"))
+	if err != nil {
+		apierror.Internal("Error retrieving roll-up balance").Write(w)
+		return
+	}
+	if node == nil {
+		apierror.NotFound("Account not found").Write(w)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, node)
+}
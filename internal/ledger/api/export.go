@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+)
+
+// ExportStatements streams account statements as camt.053 XML or flat CSV.
+// account_id may be repeated or comma separated; without it every account of
+// the zone is exported.
+func (h *LedgerHandler) ExportStatements(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	to, err := parseTimeParam(r, "to", time.Now().UTC())
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+	from, err := parseTimeParam(r, "from", time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+
+	req := domain.ExportRequest{
+		Format: domain.ExportFormat(q.Get("format")),
+		From:   from,
+		To:     to,
+	}
+	if req.Format == "" {
+		req.Format = domain.ExportCAMT053
+	}
+	for _, v := range q["account_id"] {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				req.AccountIDs = append(req.AccountIDs, id)
+			}
+		}
+	}
+
+	ext, contentType := "xml", "application/xml"
+	if req.Format == domain.ExportCSV {
+		ext, contentType = "csv", "text/csv"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statements_%s_%s.%s"`, from.Format("20060102"), to.Format("20060102"), ext))
+
+	sw := &startedWriter{w: w}
+	err = h.service.ExportStatements(r.Context(), sw, r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), req)
+	if err == nil {
+		return
+	}
+	if sw.started {
+		// The status line is gone; all we can do is cut the stream short.
+		log.Printf("Ledger: statement export aborted: %v", err)
+		return
+	}
+	w.Header().Del("Content-Disposition")
+	if domain.IsValidationError(err) {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+	apierror.Internal("Failed to export statements").Write(w)
+}
+
+// startedWriter records whether any of the response body has been written.
+type startedWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (s *startedWriter) Write(p []byte) (int, error) {
+	s.started = true
+	return s.w.Write(p)
+}
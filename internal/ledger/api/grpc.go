@@ -1,11 +1,16 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
 	pb "github.com/sapliy/fintech-ecosystem/proto/ledger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -22,12 +27,70 @@ func (s *LedgerGRPCServer) RecordTransaction(ctx context.Context, req *pb.Record
 	txReq := domain.TransactionRequest{
 		ReferenceID: req.ReferenceId,
 		Description: req.Description,
-		Entries: []domain.EntryRequest{
-			{AccountID: req.AccountId, Amount: req.Amount, Direction: "credit"},
-			{AccountID: "system_balancing", Amount: -req.Amount, Direction: "debit"},
-		},
 	}
 
+	if len(req.Entries) > 0 {
+		// Multi-leg transaction: all legs are posted atomically as supplied.
+		// Legs given by user are posted to the user's wallet account.
+		for _, e := range req.Entries {
+			accountID := e.AccountId
+			if accountID == "" && e.UserId != "" {
+				if req.ZoneId == "" {
+					return nil, fmt.Errorf("zone_id is required to post to a user's wallet")
+				}
+				acc, err := s.service.UserAccount(ctx, req.ZoneId, req.Mode, e.UserId, req.Currency)
+				if err != nil {
+					return nil, err
+				}
+				accountID = acc.ID
+			}
+			txReq.Entries = append(txReq.Entries, domain.EntryRequest{
+				AccountID: accountID,
+				Amount:    e.Amount,
+				Direction: e.Direction,
+			})
+		}
+	} else {
+		// Single-account form: the counterpart is the zone's settlement
+		// clearing account in the transaction currency.
+		if req.ZoneId == "" {
+			return nil, fmt.Errorf("zone_id is required without explicit entries")
+		}
+		accountID, currency := req.AccountId, req.Currency
+		switch {
+		case req.UserId != "":
+			acc, err := s.service.UserAccount(ctx, req.ZoneId, req.Mode, req.UserId, currency)
+			if err != nil {
+				return nil, err
+			}
+			accountID, currency = acc.ID, acc.Currency
+		case currency == "":
+			acc, err := s.service.GetAccount(ctx, req.AccountId)
+			if err != nil {
+				return nil, err
+			}
+			if acc == nil {
+				return nil, fmt.Errorf("account %s not found", req.AccountId)
+			}
+			currency = acc.Currency
+		}
+		clearing, err := s.service.SystemAccount(ctx, req.ZoneId, req.Mode, domain.CodeSettlementClearing, currency)
+		if err != nil {
+			return nil, err
+		}
+		txReq.Entries = []domain.EntryRequest{
+			{AccountID: accountID, Amount: req.Amount, Direction: "credit"},
+			{AccountID: clearing.ID, Amount: -req.Amount, Direction: "debit"},
+		}
+	}
+
+	if req.EffectiveAt != nil {
+		effectiveAt := req.EffectiveAt.AsTime()
+		txReq.EffectiveAt = &effectiveAt
+	}
+
+	txReq.Conversions = toDomainConversions(req.FxConversions)
+
 	if err := s.service.RecordTransaction(ctx, txReq, req.ZoneId, req.Mode); err != nil {
 		return nil, err
 	}
@@ -35,16 +98,338 @@ func (s *LedgerGRPCServer) RecordTransaction(ctx context.Context, req *pb.Record
 	return &pb.RecordTransactionResponse{Status: "recorded"}, nil
 }
 
+func (s *LedgerGRPCServer) ReverseTransaction(ctx context.Context, req *pb.ReverseTransactionRequest) (*pb.ReverseTransactionResponse, error) {
+	originalID := req.TransactionId
+	if originalID == "" && req.OriginalReferenceId != "" {
+		orig, err := s.service.GetTransactionByReference(ctx, req.OriginalReferenceId)
+		if err != nil {
+			return nil, err
+		}
+		if orig == nil {
+			return nil, status.Errorf(codes.NotFound, "no transaction recorded for reference %s", req.OriginalReferenceId)
+		}
+		originalID = orig.ID
+	}
+
+	var (
+		reversalID string
+		err        error
+	)
+	switch {
+	case req.Amount != 0:
+		reversalID, err = s.service.ReverseTransactionAmount(ctx, req.ZoneId, req.Mode, originalID, domain.ReversalRequest{
+			ReferenceID: req.ReferenceId,
+			Reason:      req.Reason,
+		}, req.Amount)
+	case len(req.Entries) > 0:
+		revReq := domain.ReversalRequest{
+			ReferenceID: req.ReferenceId,
+			Reason:      req.Reason,
+			Conversions: toDomainConversions(req.FxConversions),
+		}
+		for _, e := range req.Entries {
+			revReq.Entries = append(revReq.Entries, domain.EntryRequest{
+				AccountID: e.AccountId,
+				Amount:    e.Amount,
+				Direction: e.Direction,
+			})
+		}
+		reversalID, err = s.service.ReverseTransactionPartial(ctx, req.ZoneId, req.Mode, originalID, revReq)
+	default:
+		reversalID, err = s.service.ReverseTransaction(ctx, req.ZoneId, req.Mode, originalID, req.Reason)
+	}
+	if err != nil {
+		return nil, reversalError(err)
+	}
+
+	return &pb.ReverseTransactionResponse{
+		TransactionId:         reversalID,
+		ReversesTransactionId: originalID,
+		Status:                "reversed",
+	}, nil
+}
+
+// reversalError gives the codes callers rely on to tell a rejected reversal,
+// which posted nothing, from one whose outcome is unknown.
+func reversalError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTransactionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrAlreadyReversed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case domain.IsValidationError(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+func toDomainConversions(convs []*pb.FXConversion) []domain.FXConversion {
+	var out []domain.FXConversion
+	for _, c := range convs {
+		conv := domain.FXConversion{
+			FromCurrency: c.FromCurrency,
+			ToCurrency:   c.ToCurrency,
+			FromAmount:   c.FromAmount,
+			ToAmount:     c.ToAmount,
+			Rate:         c.Rate,
+			RateSource:   c.RateSource,
+		}
+		if c.RateTimestamp != nil {
+			conv.RateTimestamp = c.RateTimestamp.AsTime()
+		}
+		out = append(out, conv)
+	}
+	return out
+}
+
+func (s *LedgerGRPCServer) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	accReq := domain.AccountRequest{
+		Name:     req.Name,
+		Type:     domain.AccountType(req.Type),
+		Currency: req.Currency,
+		Code:     req.Code,
+	}
+	if accReq.Currency == "" {
+		accReq.Currency = "USD"
+	}
+	if req.ParentId != "" {
+		accReq.ParentID = &req.ParentId
+	}
+	if req.UserId != "" {
+		accReq.UserID = &req.UserId
+	}
+
+	acc, err := s.service.CreateAccount(ctx, accReq, req.ZoneId, req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateAccountResponse{AccountId: acc.ID, Status: "created"}, nil
+}
+
+func (s *LedgerGRPCServer) SeedChartOfAccounts(ctx context.Context, req *pb.SeedChartOfAccountsRequest) (*pb.SeedChartOfAccountsResponse, error) {
+	template := req.Template
+	if template == "" {
+		template = domain.ChartFintechBasic
+	}
+	accounts, err := s.service.SeedChartOfAccounts(ctx, template, req.ZoneId, req.Mode, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.SeedChartOfAccountsResponse{}
+	for _, acc := range accounts {
+		ca := &pb.ChartAccount{
+			AccountId: acc.ID,
+			Code:      acc.Code,
+			Name:      acc.Name,
+			Type:      string(acc.Type),
+		}
+		if acc.ParentID != nil {
+			ca.ParentId = *acc.ParentID
+		}
+		resp.Accounts = append(resp.Accounts, ca)
+	}
+	return resp, nil
+}
+
 func (s *LedgerGRPCServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
-	acc, err := s.service.GetAccount(ctx, req.AccountId)
+	var (
+		acc *domain.Account
+		err error
+	)
+	if req.AccountId == "" && req.UserId != "" {
+		if req.ZoneId == "" {
+			return nil, fmt.Errorf("zone_id is required to look up a user's wallet")
+		}
+		acc, err = s.service.UserAccount(ctx, req.ZoneId, req.Mode, req.UserId, req.Currency)
+	} else {
+		acc, err = s.service.GetAccount(ctx, req.AccountId)
+	}
 	if err != nil || acc == nil {
 		return nil, fmt.Errorf("account not found")
 	}
 
-	return &pb.GetAccountResponse{
-		AccountId: acc.ID,
-		Balance:   acc.Balance,
-		Currency:  acc.Currency,
-		CreatedAt: timestamppb.New(acc.CreatedAt),
+	resp := &pb.GetAccountResponse{
+		AccountId:        acc.ID,
+		Balance:          acc.Balance,
+		Currency:         acc.Currency,
+		CreatedAt:        timestamppb.New(acc.CreatedAt),
+		PostedBalance:    acc.PostedBalance,
+		AvailableBalance: acc.AvailableBalance,
+		Code:             acc.Code,
+	}
+	if acc.ParentID != nil {
+		resp.ParentId = *acc.ParentID
+	}
+	return resp, nil
+}
+
+func (s *LedgerGRPCServer) CreateHold(ctx context.Context, req *pb.CreateHoldRequest) (*pb.Hold, error) {
+	holdReq := domain.HoldRequest{
+		AccountID:   req.AccountId,
+		Amount:      req.Amount,
+		ReferenceID: req.ReferenceId,
+		Description: req.Description,
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.AsTime()
+		holdReq.ExpiresAt = &expiresAt
+	}
+
+	hold, err := s.service.CreateHold(ctx, holdReq, req.ZoneId, req.Mode)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	return toPBHold(hold), nil
+}
+
+func (s *LedgerGRPCServer) CaptureHold(ctx context.Context, req *pb.CaptureHoldRequest) (*pb.Hold, error) {
+	hold, err := s.service.CaptureHold(ctx, req.ZoneId, req.Mode, req.HoldId, domain.CaptureRequest{
+		Amount:      req.Amount,
+		ToAccountID: req.ToAccountId,
+		ReferenceID: req.ReferenceId,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, holdError(err)
+	}
+	return toPBHold(hold), nil
+}
+
+func (s *LedgerGRPCServer) VoidHold(ctx context.Context, req *pb.VoidHoldRequest) (*pb.Hold, error) {
+	hold, err := s.service.VoidHold(ctx, req.ZoneId, req.Mode, req.HoldId)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	return toPBHold(hold), nil
+}
+
+// holdError reports holds and accounts of other zones as missing.
+func holdError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrHoldNotFound), errors.Is(err, domain.ErrAccountNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case domain.IsValidationError(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+func toPBHold(h *domain.Hold) *pb.Hold {
+	out := &pb.Hold{
+		Id:             h.ID,
+		AccountId:      h.AccountID,
+		Amount:         h.Amount,
+		CapturedAmount: h.CapturedAmount,
+		ReferenceId:    h.ReferenceID,
+		Status:         string(h.Status),
+		ExpiresAt:      timestamppb.New(h.ExpiresAt),
+	}
+	if h.TransactionID != nil {
+		out.TransactionId = *h.TransactionID
+	}
+	return out
+}
+
+// SetFXRate stores a rate for the request's zone. Platform-wide rates are
+// only loaded from file at startup.
+func (s *LedgerGRPCServer) SetFXRate(ctx context.Context, req *pb.FXRate) (*pb.FXRate, error) {
+	if req.ZoneId == "" {
+		return nil, status.Error(codes.InvalidArgument, "zone_id is required to set an fx rate")
+	}
+	rate := domain.FXRate{
+		ZoneID:        req.ZoneId,
+		Mode:          req.Mode,
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+		Source:        req.Source,
+	}
+	if req.AsOf != nil {
+		rate.AsOf = req.AsOf.AsTime()
+	}
+
+	stored, err := s.service.SetFXRate(ctx, rate)
+	if err != nil {
+		if domain.IsValidationError(err) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	return &pb.FXRate{
+		ZoneId:        stored.ZoneID,
+		Mode:          stored.Mode,
+		BaseCurrency:  stored.BaseCurrency,
+		QuoteCurrency: stored.QuoteCurrency,
+		Rate:          stored.Rate,
+		Source:        stored.Source,
+		AsOf:          timestamppb.New(stored.AsOf),
 	}, nil
 }
+
+func (s *LedgerGRPCServer) GetBalanceAt(ctx context.Context, req *pb.GetBalanceAtRequest) (*pb.GetBalanceAtResponse, error) {
+	asOf := time.Now().UTC()
+	if req.AsOf != nil {
+		asOf = req.AsOf.AsTime()
+	}
+
+	balance, err := s.service.GetBalanceAt(ctx, req.AccountId, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetBalanceAtResponse{
+		AccountId: balance.AccountID,
+		Balance:   balance.Balance,
+		Currency:  balance.Currency,
+		AsOf:      timestamppb.New(balance.AsOf),
+	}, nil
+}
+
+func (s *LedgerGRPCServer) GetStatement(ctx context.Context, req *pb.GetStatementRequest) (*pb.GetStatementResponse, error) {
+	if req.From == nil || req.To == nil {
+		return nil, fmt.Errorf("from and to are required")
+	}
+
+	st, err := s.service.GetStatement(ctx, req.AccountId, req.From.AsTime(), req.To.AsTime())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.GetStatementResponse{
+		AccountId:      st.AccountID,
+		Currency:       st.Currency,
+		From:           timestamppb.New(st.From),
+		To:             timestamppb.New(st.To),
+		OpeningBalance: st.OpeningBalance,
+		ClosingBalance: st.ClosingBalance,
+	}
+	for _, l := range st.Lines {
+		res.Lines = append(res.Lines, &pb.StatementLine{
+			EntryId:        l.EntryID,
+			TransactionId:  l.TransactionID,
+			ReferenceId:    l.ReferenceID,
+			Description:    l.Description,
+			Amount:         l.Amount,
+			Direction:      string(l.Direction),
+			RunningBalance: l.RunningBalance,
+			CreatedAt:      timestamppb.New(l.CreatedAt),
+			EffectiveAt:    timestamppb.New(l.EffectiveAt),
+		})
+	}
+
+	switch req.Format {
+	case "", "json":
+	case "csv":
+		var buf bytes.Buffer
+		if err := domain.WriteStatementCSV(&buf, st); err != nil {
+			return nil, err
+		}
+		res.Csv = buf.Bytes()
+	default:
+		return nil, fmt.Errorf("format must be json or csv")
+	}
+
+	return res, nil
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
 	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
 	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
 )
 
@@ -20,12 +22,7 @@ func NewLedgerHandler(service *domain.LedgerService) *LedgerHandler {
 }
 
 func (h *LedgerHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Name     string             `json:"name"`
-		Type     domain.AccountType `json:"type"`
-		Currency string             `json:"currency"`
-		UserID   *string            `json:"user_id"`
-	}
+	var req domain.AccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		apierror.BadRequest("Invalid request body").Write(w)
 		return
@@ -40,8 +37,12 @@ func (h *LedgerHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		req.Currency = "USD"
 	}
 
-	acc, err := h.service.CreateAccount(r.Context(), req.Name, req.Type, req.Currency, req.UserID, r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"))
+	acc, err := h.service.CreateAccount(r.Context(), req, r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"))
 	if err != nil {
+		if domain.IsValidationError(err) {
+			apierror.BadRequest(err.Error()).Write(w)
+			return
+		}
 		apierror.Internal("Failed to create account").Write(w)
 		return
 	}
@@ -69,6 +70,49 @@ func (h *LedgerHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
 	jsonutil.WriteJSON(w, http.StatusOK, acc)
 }
 
+// UpdateAccount changes an account's balance rules. A null overdraft_limit
+// removes the floor; 0 forbids negative balances. Only owners and admins of
+// the account's zone may change them.
+func (h *LedgerHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+	if !privilegedRoles[r.Header.Get("X-Role")] {
+		apierror.Forbidden("Changing overdraft limits requires the owner or admin role").Write(w)
+		return
+	}
+	id := jsonutil.GetIDAfter(r, "accounts")
+	if id == "" {
+		apierror.BadRequest("Missing Account ID").Write(w)
+		return
+	}
+
+	var req struct {
+		OverdraftLimit *int64 `json:"overdraft_limit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	acc, err := h.service.SetOverdraftLimit(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), id, req.OverdraftLimit)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAccountNotFound):
+			apierror.NotFound("Account not found").Write(w)
+		case domain.IsValidationError(err):
+			apierror.BadRequest(err.Error()).Write(w)
+		default:
+			apierror.Internal("Failed to update account").Write(w)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, acc)
+}
+
 func (h *LedgerHandler) RecordTransaction(w http.ResponseWriter, r *http.Request) {
 	var req domain.TransactionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -82,7 +126,7 @@ func (h *LedgerHandler) RecordTransaction(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := h.service.RecordTransaction(r.Context(), req, r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode")); err != nil {
-		if strings.Contains(err.Error(), "balanced") {
+		if domain.IsValidationError(err) {
 			apierror.BadRequest(err.Error()).Write(w)
 		} else {
 			apierror.Internal("Failed to record transaction").Write(w)
@@ -103,6 +147,51 @@ func (h *LedgerHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 	jsonutil.WriteJSON(w, http.StatusOK, tx)
 }
 
+// ReverseTransaction posts a reversal of a transaction. Without entries it
+// reverses whatever is left unreversed; with entries it is a partial reversal
+// and reference_id is required.
+func (h *LedgerHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "transactions")
+	if id == "" {
+		apierror.BadRequest("Missing Transaction ID").Write(w)
+		return
+	}
+
+	var req domain.ReversalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	zoneID, mode := r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode")
+	var reversalID string
+	var err error
+	if len(req.Entries) > 0 {
+		reversalID, err = h.service.ReverseTransactionPartial(r.Context(), zoneID, mode, id, req)
+	} else {
+		reversalID, err = h.service.ReverseTransaction(r.Context(), zoneID, mode, id, req.Reason)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTransactionNotFound):
+			apierror.NotFound("Transaction not found").Write(w)
+		case errors.Is(err, domain.ErrAlreadyReversed):
+			apierror.Conflict(err.Error()).Write(w)
+		case domain.IsValidationError(err):
+			apierror.BadRequest(err.Error()).Write(w)
+		default:
+			apierror.Internal("Failed to reverse transaction").Write(w)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, map[string]string{
+		"status":                  "reversed",
+		"transaction_id":          reversalID,
+		"reverses_transaction_id": id,
+	})
+}
+
 func (h *LedgerHandler) BulkRecordTransactions(w http.ResponseWriter, r *http.Request) {
 	var reqs []domain.TransactionRequest
 	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
@@ -147,3 +236,129 @@ func (h *LedgerHandler) ListTransactions(w http.ResponseWriter, r *http.Request)
 
 	jsonutil.WriteJSON(w, http.StatusOK, txs)
 }
+
+// SetFXRate stores a rate for the caller's zone, which its conversions are
+// checked against in place of the platform-wide rate for the pair.
+func (h *LedgerHandler) SetFXRate(w http.ResponseWriter, r *http.Request) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+	if !privilegedRoles[r.Header.Get("X-Role")] {
+		apierror.Forbidden("Setting fx rates requires the owner or admin role").Write(w)
+		return
+	}
+	zoneID := r.Header.Get("X-Zone-ID")
+	if zoneID == "" {
+		apierror.BadRequest("X-Zone-ID is required").Write(w)
+		return
+	}
+
+	var req domain.FXRate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+	req.ZoneID, req.Mode = zoneID, r.Header.Get("X-Zone-Mode")
+
+	rate, err := h.service.SetFXRate(r.Context(), req)
+	if err != nil {
+		if domain.IsValidationError(err) {
+			apierror.BadRequest(err.Error()).Write(w)
+		} else {
+			apierror.Internal("Failed to store fx rate").Write(w)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, rate)
+}
+
+func (h *LedgerHandler) ListFXRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListFXRates(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"))
+	if err != nil {
+		apierror.Internal("Failed to list fx rates").Write(w)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, rates)
+}
+
+// GetAccountBalance returns an account's balance as of the `as_of` query
+// parameter, defaulting to now.
+func (h *LedgerHandler) GetAccountBalance(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "accounts")
+	asOf, err := parseTimeParam(r, "as_of", time.Now().UTC())
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+
+	balance, err := h.service.GetBalanceAt(r.Context(), id, asOf)
+	if err != nil {
+		if domain.IsValidationError(err) {
+			apierror.NotFound(err.Error()).Write(w)
+		} else {
+			apierror.Internal("Failed to compute balance").Write(w)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, balance)
+}
+
+// GetAccountStatement returns the statement of an account between the `from`
+// and `to` query parameters as JSON, or as CSV when format=csv. The range
+// defaults to the current month up to now.
+func (h *LedgerHandler) GetAccountStatement(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "accounts")
+	to, err := parseTimeParam(r, "to", time.Now().UTC())
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+	from, err := parseTimeParam(r, "from", time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+
+	st, err := h.service.GetStatement(r.Context(), id, from, to)
+	if err != nil {
+		if domain.IsValidationError(err) {
+			apierror.BadRequest(err.Error()).Write(w)
+		} else {
+			apierror.Internal("Failed to build statement").Write(w)
+		}
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		jsonutil.WriteJSON(w, http.StatusOK, st)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statement_%s_%s.csv"`, st.AccountID, from.Format("20060102")))
+		w.WriteHeader(http.StatusOK)
+		_ = domain.WriteStatementCSV(w, st)
+	default:
+		apierror.BadRequest("format must be json or csv").Write(w)
+	}
+}
+
+// parseTimeParam reads an RFC 3339 timestamp or a YYYY-MM-DD date from the
+// query string, returning def when the parameter is absent.
+func parseTimeParam(r *http.Request, name string, def time.Time) (time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s: expected RFC 3339 timestamp or YYYY-MM-DD date", name)
+}
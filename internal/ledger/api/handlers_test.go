@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
 )
@@ -95,3 +96,175 @@ func TestLedgerHandler_RecordTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestLedgerHandler_GetAccountStatement(t *testing.T) {
+	mRepo := &domain.MockRepository{
+		GetAccountFunc: func(ctx context.Context, id string) (*domain.Account, error) {
+			return &domain.Account{ID: id, Currency: "USD"}, nil
+		},
+		GetBalanceAtFunc: func(ctx context.Context, accountID string, at time.Time) (int64, error) {
+			return 100, nil
+		},
+		ListAccountEntriesFunc: func(ctx context.Context, accountID string, from, to time.Time) ([]domain.StatementLine, error) {
+			return []domain.StatementLine{{TransactionID: "tx_1", ReferenceID: "ref_1", Amount: 250, Direction: domain.Credit}}, nil
+		},
+	}
+	h := &LedgerHandler{service: domain.NewLedgerService(mRepo, nil)}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"JSON", "from=2026-01-01&to=2026-02-01", http.StatusOK, `"closing_balance":350`},
+		{"CSV", "from=2026-01-01&to=2026-02-01&format=csv", http.StatusOK, "tx_1,ref_1,,credit,250,350,USD"},
+		{"Invalid Date", "from=yesterday", http.StatusBadRequest, "invalid from"},
+		{"Invalid Format", "format=xml", http.StatusBadRequest, "format must be json or csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/accounts/acc_1/statement?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			h.GetAccountStatement(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain '%s', got '%s'", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLedgerHandler_ReopenPeriod_RequiresPrivilegedRole(t *testing.T) {
+	h := &LedgerHandler{service: domain.NewLedgerService(&domain.MockRepository{}, nil)}
+
+	tests := []struct {
+		name           string
+		userID         string
+		role           string
+		expectedStatus int
+	}{
+		{"Unauthenticated", "", "admin", http.StatusUnauthorized},
+		{"Developer Role", "user_1", "developer", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/periods/p_1/reopen", strings.NewReader(`{"reason":"correction"}`))
+			req.Header.Set("X-User-ID", tt.userID)
+			req.Header.Set("X-Role", tt.role)
+			w := httptest.NewRecorder()
+
+			h.ReopenPeriod(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestLedgerHandler_SetFXRate_ScopedToZone(t *testing.T) {
+	var stored *domain.FXRate
+	repo := &domain.MockRepository{
+		UpsertFXRateFunc: func(ctx context.Context, rate *domain.FXRate) error {
+			stored = rate
+			return nil
+		},
+	}
+	h := &LedgerHandler{service: domain.NewLedgerService(repo, nil)}
+
+	tests := []struct {
+		name           string
+		userID         string
+		role           string
+		zoneID         string
+		expectedStatus int
+	}{
+		{"Unauthenticated", "", "admin", "zone_1", http.StatusUnauthorized},
+		{"Developer Role", "user_1", "developer", "zone_1", http.StatusForbidden},
+		{"Missing Zone", "user_1", "admin", "", http.StatusBadRequest},
+		{"Admin", "user_1", "admin", "zone_1", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored = nil
+			body := `{"base_currency":"usd","quote_currency":"eur","rate":0.9,"source":"manual","zone_id":"zone_2"}`
+			req := httptest.NewRequest("POST", "/fx-rates", strings.NewReader(body))
+			req.Header.Set("X-User-ID", tt.userID)
+			req.Header.Set("X-Role", tt.role)
+			req.Header.Set("X-Zone-ID", tt.zoneID)
+			req.Header.Set("X-Zone-Mode", "live")
+			w := httptest.NewRecorder()
+
+			h.SetFXRate(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if stored != nil {
+					t.Errorf("Expected no rate to be stored, got %+v", stored)
+				}
+				return
+			}
+			if stored == nil || stored.ZoneID != "zone_1" || stored.Mode != "live" {
+				t.Errorf("Expected the rate to be stored for zone_1/live, got %+v", stored)
+			}
+		})
+	}
+}
+
+func TestLedgerHandler_UpdateAccount_ScopedToZone(t *testing.T) {
+	var updated bool
+	repo := &domain.MockRepository{
+		GetAccountFunc: func(ctx context.Context, id string) (*domain.Account, error) {
+			return &domain.Account{ID: id, ZoneID: "zone_1", Mode: "live", Type: domain.Liability}, nil
+		},
+		UpdateOverdraftLimitFunc: func(ctx context.Context, id string, limit *int64) error {
+			updated = true
+			return nil
+		},
+	}
+	h := &LedgerHandler{service: domain.NewLedgerService(repo, nil)}
+
+	tests := []struct {
+		name           string
+		userID         string
+		role           string
+		zoneID         string
+		expectedStatus int
+	}{
+		{"Unauthenticated", "", "admin", "zone_1", http.StatusUnauthorized},
+		{"Developer Role", "user_1", "developer", "zone_1", http.StatusForbidden},
+		{"Other Zone", "user_1", "admin", "zone_2", http.StatusNotFound},
+		{"Admin", "user_1", "admin", "zone_1", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated = false
+			req := httptest.NewRequest("PATCH", "/accounts/acc_1", strings.NewReader(`{"overdraft_limit":null}`))
+			req.Header.Set("X-User-ID", tt.userID)
+			req.Header.Set("X-Role", tt.role)
+			req.Header.Set("X-Zone-ID", tt.zoneID)
+			req.Header.Set("X-Zone-Mode", "live")
+			w := httptest.NewRecorder()
+
+			h.UpdateAccount(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if updated != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Expected update %v, got %v", tt.expectedStatus == http.StatusOK, updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+func (h *LedgerHandler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	var req domain.HoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	hold, err := h.service.CreateHold(r.Context(), req, r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"))
+	if err != nil {
+		writeHoldError(w, err, "Failed to create hold")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, hold)
+}
+
+func (h *LedgerHandler) GetHold(w http.ResponseWriter, r *http.Request) {
+	hold, err := h.service.GetHold(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), jsonutil.GetIDAfter(r, "holds"))
+	if err != nil {
+		apierror.Internal("Error retrieving hold").Write(w)
+		return
+	}
+	if hold == nil {
+		apierror.NotFound("Hold not found").Write(w)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, hold)
+}
+
+func (h *LedgerHandler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	var req domain.CaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	hold, err := h.service.CaptureHold(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), jsonutil.GetIDAfter(r, "holds"), req)
+	if err != nil {
+		writeHoldError(w, err, "Failed to capture hold")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, hold)
+}
+
+func (h *LedgerHandler) VoidHold(w http.ResponseWriter, r *http.Request) {
+	hold, err := h.service.VoidHold(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), jsonutil.GetIDAfter(r, "holds"))
+	if err != nil {
+		writeHoldError(w, err, "Failed to void hold")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, hold)
+}
+
+func writeHoldError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrHoldNotFound):
+		apierror.NotFound("Hold not found").Write(w)
+	case errors.Is(err, domain.ErrAccountNotFound):
+		apierror.NotFound("Account not found").Write(w)
+	case domain.IsValidationError(err):
+		apierror.BadRequest(err.Error()).Write(w)
+	default:
+		apierror.Internal(msg).Write(w)
+	}
+}
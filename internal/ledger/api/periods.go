@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+// privilegedRoles may reopen closed accounting periods and set their zone's
+// fx rates and overdraft limits.
+var privilegedRoles = map[string]bool{
+	"owner": true,
+	"admin": true,
+}
+
+func (h *LedgerHandler) CreatePeriod(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string    `json:"name"`
+		StartsAt time.Time `json:"starts_at"`
+		EndsAt   time.Time `json:"ends_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	period, err := h.service.CreatePeriod(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), req.Name, req.StartsAt, req.EndsAt)
+	if err != nil {
+		if domain.IsValidationError(err) {
+			apierror.BadRequest(err.Error()).Write(w)
+		} else {
+			apierror.Internal("Failed to create period").Write(w)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, period)
+}
+
+func (h *LedgerHandler) ListPeriods(w http.ResponseWriter, r *http.Request) {
+	periods, err := h.service.ListPeriods(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"))
+	if err != nil {
+		apierror.Internal("Failed to list periods").Write(w)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, periods)
+}
+
+func (h *LedgerHandler) ClosePeriod(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "periods")
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+
+	snapshot, err := h.service.ClosePeriod(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPeriodNotFound):
+			apierror.NotFound("Period not found").Write(w)
+		case domain.IsValidationError(err):
+			apierror.BadRequest(err.Error()).Write(w)
+		default:
+			apierror.Internal("Failed to close period").Write(w)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, snapshot)
+}
+
+// ReopenPeriod is restricted to owners and admins and requires a reason,
+// which is written to the audit log.
+func (h *LedgerHandler) ReopenPeriod(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "periods")
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+	if !privilegedRoles[r.Header.Get("X-Role")] {
+		apierror.Forbidden("Reopening a period requires the owner or admin role").Write(w)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	period, err := h.service.ReopenPeriod(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), id, userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPeriodNotFound):
+			apierror.NotFound("Period not found").Write(w)
+		case domain.IsValidationError(err):
+			apierror.BadRequest(err.Error()).Write(w)
+		default:
+			apierror.Internal("Failed to reopen period").Write(w)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, period)
+}
+
+// GetPeriodSnapshot returns the latest close snapshot of a period along with
+// whether its signature still verifies.
+func (h *LedgerHandler) GetPeriodSnapshot(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "periods")
+	snapshot, err := h.service.GetPeriodSnapshot(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), id)
+	if err != nil {
+		apierror.Internal("Failed to retrieve snapshot").Write(w)
+		return
+	}
+	if snapshot == nil {
+		apierror.NotFound("Snapshot not found").Write(w)
+		return
+	}
+
+	valid, err := h.service.VerifyPeriodSnapshot(snapshot)
+	if err != nil {
+		apierror.Internal("Failed to verify snapshot").Write(w)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"snapshot":        snapshot,
+		"signature_valid": valid,
+	})
+}
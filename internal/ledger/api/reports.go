@@ -0,0 +1,127 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+type ReportHandler struct {
+	service *domain.ReportingService
+}
+
+func NewReportHandler(service *domain.ReportingService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// TrialBalance returns the trial balance of the caller's zone over the
+// `from`/`to` range or the accounting period given by `period_id`.
+func (h *ReportHandler) TrialBalance(w http.ResponseWriter, r *http.Request) {
+	zoneID, mode := r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode")
+	from, to, ok := h.reportRange(w, r, zoneID, mode)
+	if !ok {
+		return
+	}
+
+	tb, err := h.service.TrialBalance(r.Context(), zoneID, mode, from, to)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+	writeReport(w, r, "trial_balance", from, tb, func() error { return domain.WriteTrialBalanceCSV(w, tb) })
+}
+
+// BalanceSheet returns the balance sheet of the caller's zone as of `as_of`,
+// or as of the end of the accounting period given by `period_id`.
+func (h *ReportHandler) BalanceSheet(w http.ResponseWriter, r *http.Request) {
+	zoneID, mode := r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode")
+	asOf, err := parseTimeParam(r, "as_of", time.Now().UTC())
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+	if periodID := r.URL.Query().Get("period_id"); periodID != "" {
+		period, err := h.service.GetPeriod(r.Context(), zoneID, mode, periodID)
+		if err != nil {
+			writeReportError(w, err)
+			return
+		}
+		asOf = period.EndsAt
+	}
+
+	bs, err := h.service.BalanceSheet(r.Context(), zoneID, mode, asOf)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+	writeReport(w, r, "balance_sheet", asOf, bs, func() error { return domain.WriteBalanceSheetCSV(w, bs) })
+}
+
+// IncomeStatement returns the income statement of the caller's zone over the
+// `from`/`to` range or the accounting period given by `period_id`.
+func (h *ReportHandler) IncomeStatement(w http.ResponseWriter, r *http.Request) {
+	zoneID, mode := r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode")
+	from, to, ok := h.reportRange(w, r, zoneID, mode)
+	if !ok {
+		return
+	}
+
+	is, err := h.service.IncomeStatement(r.Context(), zoneID, mode, from, to)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+	writeReport(w, r, "income_statement", from, is, func() error { return domain.WriteIncomeStatementCSV(w, is) })
+}
+
+// reportRange resolves the range of a report from `period_id`, or from the
+// `from` and `to` query parameters defaulting to the current month up to now.
+func (h *ReportHandler) reportRange(w http.ResponseWriter, r *http.Request, zoneID, mode string) (time.Time, time.Time, bool) {
+	if periodID := r.URL.Query().Get("period_id"); periodID != "" {
+		period, err := h.service.GetPeriod(r.Context(), zoneID, mode, periodID)
+		if err != nil {
+			writeReportError(w, err)
+			return time.Time{}, time.Time{}, false
+		}
+		return period.StartsAt, period.EndsAt, true
+	}
+
+	to, err := parseTimeParam(r, "to", time.Now().UTC())
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return time.Time{}, time.Time{}, false
+	}
+	from, err := parseTimeParam(r, "from", time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func writeReportError(w http.ResponseWriter, err error) {
+	if domain.IsValidationError(err) {
+		apierror.BadRequest(err.Error()).Write(w)
+	} else {
+		apierror.Internal("Failed to build report").Write(w)
+	}
+}
+
+// writeReport writes a report as JSON, or as CSV when format=csv.
+func writeReport(w http.ResponseWriter, r *http.Request, name string, date time.Time, report interface{}, writeCSV func() error) {
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		jsonutil.WriteJSON(w, http.StatusOK, report)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_%s.csv"`, name, date.Format("20060102")))
+		w.WriteHeader(http.StatusOK)
+		_ = writeCSV()
+	default:
+		apierror.BadRequest("format must be json or csv").Write(w)
+	}
+}
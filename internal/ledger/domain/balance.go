@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// maxBalanceRetries bounds how often a transaction is retried after losing an
+// optimistic-locking race on an account balance.
+const maxBalanceRetries = 3
+
+// ErrVersionConflict is returned by TransactionContext.UpdateAccountBalance
+// when the account was modified since it was read.
+var ErrVersionConflict = errors.New("account balance was modified concurrently")
+
+// ErrAccountNotFound is returned for an account that does not exist or
+// belongs to another zone or mode than the caller's.
+var ErrAccountNotFound = errors.New("account not found")
+
+// BalanceFloor returns the lowest balance the account may reach and whether a
+// floor applies at all. Accounts without an overdraft limit are unrestricted.
+func (a *Account) BalanceFloor() (int64, bool) {
+	if a.OverdraftLimit == nil {
+		return 0, false
+	}
+	return -*a.OverdraftLimit, true
+}
+
+// defaultOverdraftLimit returns the overdraft limit a new account of the given
+// type starts with. Liability accounts hold customer funds and cannot go below
+// zero; the other types are unrestricted until configured.
+func defaultOverdraftLimit(accType AccountType) *int64 {
+	if accType == Liability {
+		limit := int64(0)
+		return &limit
+	}
+	return nil
+}
+
+// SetOverdraftLimit configures how far below zero an account of the zone
+// may go. A nil limit removes the floor entirely.
+func (s *LedgerService) SetOverdraftLimit(ctx context.Context, zoneID, mode, accountID string, limit *int64) (*Account, error) {
+	if limit != nil && *limit < 0 {
+		return nil, invalidf("overdraft limit must not be negative")
+	}
+
+	acc, err := s.zoneAccount(ctx, zoneID, mode, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateOverdraftLimit(ctx, accountID, limit); err != nil {
+		return nil, err
+	}
+	acc.OverdraftLimit = limit
+	return acc, nil
+}
+
+// applyBalanceChanges updates the materialized balance of every account
+// touched by the entries, enforcing each account's overdraft limit against
+// its available balance. releases lowers the held amount of accounts whose
+// hold is being captured. Accounts are updated in a stable order to keep lock
+// acquisition consistent.
+func (s *LedgerService) applyBalanceChanges(ctx context.Context, txCtx TransactionContext, entries []EntryRequest, releases map[string]int64) error {
+	deltas := make(map[string]int64)
+	var ids []string
+	for _, e := range entries {
+		if _, ok := deltas[e.AccountID]; !ok {
+			ids = append(ids, e.AccountID)
+		}
+		deltas[e.AccountID] += e.Amount
+	}
+	for id := range releases {
+		if _, ok := deltas[id]; !ok {
+			deltas[id] = 0
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		delta, release := deltas[id], releases[id]
+		if delta == 0 && release == 0 {
+			continue
+		}
+
+		acc, err := txCtx.GetAccountBalance(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to read balance for account %s: %w", id, err)
+		}
+		if acc == nil {
+			return invalidf("account %s not found", id)
+		}
+
+		newBalance := acc.Balance + delta
+		newHeld := acc.HeldAmount - release
+		if floor, ok := acc.BalanceFloor(); ok && delta < 0 && newBalance-newHeld < floor {
+			return invalidf("insufficient funds: account %s available balance %d cannot be debited by %d (limit %d)", id, acc.Balance-newHeld, -delta, floor)
+		}
+
+		if err := txCtx.UpdateAccountBalance(ctx, id, newBalance, newHeld, acc.Version); err != nil {
+			if errors.Is(err, ErrVersionConflict) {
+				return err
+			}
+			return fmt.Errorf("failed to update balance for account %s: %w", id, err)
+		}
+	}
+	return nil
+}
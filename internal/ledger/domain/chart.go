@@ -0,0 +1,301 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ChartFintechBasic is the chart of accounts seeded for zones created from
+// the fintech-basic template. It is also the fallback chart for zones that
+// post to well-known accounts before any chart was seeded.
+const ChartFintechBasic = "fintech-basic"
+
+// Well-known account codes. Services resolve accounts by code instead of
+// relying on account naming conventions.
+const (
+	CodeCash                = "1000"
+	CodeSettlementClearing  = "1100" // Counterpart of processor-settled payments and refunds
+	CodeMerchantReceivables = "1300" // Disputed amounts merchants owed beyond their balance
+	CodeFXClearing          = "1400" // Clearing legs of fx conversions, one account per currency
+	CodeCustomerFunds       = "2000"
+	CodeCustomerWallets     = "2100" // Parent of per-user wallet accounts
+	CodeMerchantPayables    = "2200" // Parent of connected account balances
+	CodeDisputesReserve     = "2300" // Disputed amounts withheld from merchants until decided
+	CodeFeeRevenue          = "4000" // Platform fees kept from marketplace payments
+)
+
+// ChartAccount is one account in a chart template. Parents are listed
+// before their children.
+type ChartAccount struct {
+	Code       string      `json:"code"`
+	Name       string      `json:"name"`
+	Type       AccountType `json:"type"`
+	ParentCode string      `json:"parent_code,omitempty"`
+}
+
+var chartTemplates = map[string][]ChartAccount{
+	ChartFintechBasic: {
+		{Code: CodeCash, Name: "Cash", Type: Asset},
+		{Code: "1010", Name: "Operating Bank", Type: Asset, ParentCode: CodeCash},
+		{Code: "1020", Name: "Reserve Bank", Type: Asset, ParentCode: CodeCash},
+		{Code: CodeSettlementClearing, Name: "Settlement Clearing", Type: Asset},
+		{Code: "1200", Name: "Processor Receivables", Type: Asset},
+		{Code: CodeMerchantReceivables, Name: "Merchant Receivables", Type: Asset},
+		{Code: CodeFXClearing, Name: "FX Clearing", Type: Asset},
+		{Code: CodeCustomerFunds, Name: "Customer Funds", Type: Liability},
+		{Code: CodeCustomerWallets, Name: "Customer Wallets", Type: Liability, ParentCode: CodeCustomerFunds},
+		{Code: CodeMerchantPayables, Name: "Merchant Payables", Type: Liability, ParentCode: CodeCustomerFunds},
+		{Code: CodeDisputesReserve, Name: "Disputes Reserve", Type: Liability},
+		{Code: "3000", Name: "Owner Equity", Type: Equity},
+		{Code: CodeFeeRevenue, Name: "Fee Revenue", Type: Revenue},
+		{Code: "5000", Name: "Processing Costs", Type: Expense},
+	},
+}
+
+// ChartNode is an account in the chart of accounts tree. The roll-up
+// balances include the account itself and all of its descendants.
+type ChartNode struct {
+	Account
+	Label          string       `json:"label"` // e.g. "1000-Cash"
+	RollupBalance  int64        `json:"rollup_balance"`
+	RollupHeld     int64        `json:"rollup_held_amount"`
+	RollupAccounts int          `json:"rollup_accounts"`
+	Children       []*ChartNode `json:"children,omitempty"`
+}
+
+// SeedChartOfAccounts creates the accounts of a chart template in a zone.
+// Accounts whose code already exists are left alone, so seeding twice is
+// harmless. It returns the chart's accounts in template order.
+func (s *LedgerService) SeedChartOfAccounts(ctx context.Context, template, zoneID, mode, currency string) ([]Account, error) {
+	chart, ok := chartTemplates[template]
+	if !ok {
+		return nil, invalidf("unknown chart of accounts template %q", template)
+	}
+	if currency == "" {
+		currency = "USD"
+	}
+
+	ids := make(map[string]string, len(chart))
+	accounts := make([]Account, 0, len(chart))
+	for _, ca := range chart {
+		acc, err := s.repo.GetAccountByCode(ctx, zoneID, mode, ca.Code, currency)
+		if err != nil {
+			return nil, err
+		}
+		if acc == nil {
+			req := AccountRequest{Name: ca.Name, Type: ca.Type, Currency: currency, Code: ca.Code}
+			if ca.ParentCode != "" {
+				parentID := ids[ca.ParentCode]
+				req.ParentID = &parentID
+			}
+			if acc, err = s.CreateAccount(ctx, req, zoneID, mode); err != nil {
+				// Seeded concurrently; use the winner's account.
+				if acc, _ = s.repo.GetAccountByCode(ctx, zoneID, mode, ca.Code, currency); acc == nil {
+					return nil, fmt.Errorf("failed to create account %s-%s: %w", ca.Code, ca.Name, err)
+				}
+			}
+		}
+		ids[ca.Code] = acc.ID
+		accounts = append(accounts, *acc)
+	}
+	return accounts, nil
+}
+
+func (s *LedgerService) GetAccountByCode(ctx context.Context, zoneID, mode, code, currency string) (*Account, error) {
+	acc, err := s.repo.GetAccountByCode(ctx, zoneID, mode, code, currency)
+	if err != nil || acc == nil {
+		return acc, err
+	}
+	acc.setBalances()
+	return acc, nil
+}
+
+// SystemAccount resolves a well-known account by code. Zones that have no
+// chart yet get the fintech-basic chart seeded in that currency.
+func (s *LedgerService) SystemAccount(ctx context.Context, zoneID, mode, code, currency string) (*Account, error) {
+	acc, err := s.GetAccountByCode(ctx, zoneID, mode, code, currency)
+	if err != nil || acc != nil {
+		return acc, err
+	}
+	if _, err := s.SeedChartOfAccounts(ctx, ChartFintechBasic, zoneID, mode, currency); err != nil {
+		return nil, err
+	}
+	acc, err = s.GetAccountByCode(ctx, zoneID, mode, code, currency)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, invalidf("no account with code %s in zone %s", code, zoneID)
+	}
+	return acc, nil
+}
+
+// UserAccount returns the user's wallet account in the currency, opening one
+// under Customer Wallets if the user has none.
+func (s *LedgerService) UserAccount(ctx context.Context, zoneID, mode, userID, currency string) (*Account, error) {
+	if userID == "" {
+		return nil, invalidf("user_id is required")
+	}
+	return s.ownedAccount(ctx, zoneID, mode, userID, currency, CodeCustomerWallets, "Wallet "+userID)
+}
+
+// ConnectedAccount returns the balance account of a connected account of
+// the connect service in the currency, opening one under Merchant Payables
+// if it has none. Marketplace payments made on behalf of the connected
+// account are credited there, less the platform's fee.
+func (s *LedgerService) ConnectedAccount(ctx context.Context, zoneID, mode, connectedAccountID, currency string) (*Account, error) {
+	if connectedAccountID == "" {
+		return nil, invalidf("connected account ID is required")
+	}
+	return s.ownedAccount(ctx, zoneID, mode, connectedAccountID, currency, CodeMerchantPayables, "Connected account "+connectedAccountID)
+}
+
+// ownedAccount returns the account owned by ownerID in the currency,
+// opening one named name under the account with parentCode if the owner
+// has none. Owners are users or connected accounts, whose IDs do not
+// collide, and both are kept in the account's user ID. An owner has one
+// account per currency, so concurrent callers opening it share the first.
+func (s *LedgerService) ownedAccount(ctx context.Context, zoneID, mode, ownerID, currency, parentCode, name string) (*Account, error) {
+	if currency == "" {
+		currency = "USD"
+	}
+	acc, err := s.repo.GetUserAccount(ctx, zoneID, mode, ownerID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if acc != nil {
+		acc.setBalances()
+		return acc, nil
+	}
+
+	parent, err := s.SystemAccount(ctx, zoneID, mode, parentCode, currency)
+	if err != nil {
+		return nil, err
+	}
+	acc, err = s.CreateAccount(ctx, AccountRequest{
+		Name:     name,
+		Type:     parent.Type,
+		Currency: currency,
+		UserID:   &ownerID,
+		ParentID: &parent.ID,
+	}, zoneID, mode)
+	if err != nil {
+		// Opened concurrently; use the winner's account.
+		if acc, _ = s.repo.GetUserAccount(ctx, zoneID, mode, ownerID, currency); acc == nil {
+			return nil, err
+		}
+		acc.setBalances()
+	}
+	return acc, nil
+}
+
+// GetChartOfAccounts returns the zone's accounts as a tree with roll-up
+// balances. Accounts without a parent are roots.
+func (s *LedgerService) GetChartOfAccounts(ctx context.Context, zoneID, mode string) ([]*ChartNode, error) {
+	accounts, err := s.repo.ListAccounts(ctx, zoneID, mode)
+	if err != nil {
+		return nil, err
+	}
+	roots, _ := buildChart(accounts)
+	return roots, nil
+}
+
+// GetRollupBalance returns an account with the balances of its whole
+// subtree rolled up.
+func (s *LedgerService) GetRollupBalance(ctx context.Context, accountID string) (*ChartNode, error) {
+	acc, err := s.repo.GetAccount(ctx, accountID)
+	if err != nil || acc == nil {
+		return nil, err
+	}
+	accounts, err := s.repo.ListAccounts(ctx, acc.ZoneID, acc.Mode)
+	if err != nil {
+		return nil, err
+	}
+	_, nodes := buildChart(accounts)
+	node, ok := nodes[accountID]
+	if !ok {
+		return nil, fmt.Errorf("account %s missing from the chart of zone %s", accountID, acc.ZoneID)
+	}
+	return node, nil
+}
+
+func checkParent(parent *Account, parentID string, req AccountRequest, zoneID, mode string) error {
+	switch {
+	case parent == nil:
+		return invalidf("parent account %s not found", parentID)
+	case parent.ZoneID != zoneID || parent.Mode != mode:
+		return invalidf("parent account %s belongs to another zone", parentID)
+	case parent.Type != req.Type:
+		return invalidf("account type %s does not match parent account type %s", req.Type, parent.Type)
+	case parent.Currency != req.Currency:
+		return invalidf("account currency %s does not match parent account currency %s", req.Currency, parent.Currency)
+	}
+	return nil
+}
+
+// buildChart links accounts into trees and computes roll-up balances. It
+// returns the roots and every node by account ID.
+func buildChart(accounts []Account) ([]*ChartNode, map[string]*ChartNode) {
+	nodes := make(map[string]*ChartNode, len(accounts))
+	for _, acc := range accounts {
+		acc.setBalances()
+		label := acc.Name
+		if acc.Code != "" {
+			label = acc.Code + "-" + acc.Name
+		}
+		nodes[acc.ID] = &ChartNode{Account: acc, Label: label}
+	}
+
+	var roots []*ChartNode
+	for _, acc := range accounts {
+		node := nodes[acc.ID]
+		if parent, ok := nodes[deref(acc.ParentID)]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortChart(roots)
+	for _, root := range roots {
+		rollup(root)
+	}
+	return roots, nodes
+}
+
+func rollup(node *ChartNode) {
+	node.RollupBalance = node.Balance
+	node.RollupHeld = node.HeldAmount
+	node.RollupAccounts = 1
+	for _, child := range node.Children {
+		rollup(child)
+		node.RollupBalance += child.RollupBalance
+		node.RollupHeld += child.RollupHeld
+		node.RollupAccounts += child.RollupAccounts
+	}
+}
+
+// sortChart orders siblings by code, with uncoded accounts last by name.
+func sortChart(nodes []*ChartNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if (a.Code == "") != (b.Code == "") {
+			return a.Code != ""
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Name < b.Name
+	})
+	for _, n := range nodes {
+		sortChart(n.Children)
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
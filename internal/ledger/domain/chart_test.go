@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// chartTestRepo keeps accounts in memory and answers code and user lookups.
+func chartTestRepo() (*MockRepository, map[string]*Account) {
+	accounts := make(map[string]*Account)
+	var order []string
+	repo := &MockRepository{
+		CreateAccountFunc: func(ctx context.Context, acc *Account) error {
+			acc.ID = fmt.Sprintf("acc_%d", len(accounts)+1)
+			stored := *acc
+			accounts[acc.ID] = &stored
+			order = append(order, acc.ID)
+			return nil
+		},
+		GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+			if acc, ok := accounts[id]; ok {
+				cp := *acc
+				return &cp, nil
+			}
+			return nil, nil
+		},
+		GetAccountByCodeFunc: func(ctx context.Context, zoneID, mode, code, currency string) (*Account, error) {
+			for _, acc := range accounts {
+				if acc.ZoneID == zoneID && acc.Mode == mode && acc.Code == code && acc.Currency == currency {
+					cp := *acc
+					return &cp, nil
+				}
+			}
+			return nil, nil
+		},
+		GetUserAccountFunc: func(ctx context.Context, zoneID, mode, userID, currency string) (*Account, error) {
+			for _, id := range order {
+				acc := accounts[id]
+				if acc.ZoneID == zoneID && acc.Mode == mode && acc.UserID != nil && *acc.UserID == userID && acc.Currency == currency {
+					cp := *acc
+					return &cp, nil
+				}
+			}
+			return nil, nil
+		},
+		ListAccountsFunc: func(ctx context.Context, zoneID, mode string) ([]Account, error) {
+			var out []Account
+			for _, id := range order {
+				if acc := accounts[id]; acc.ZoneID == zoneID && acc.Mode == mode {
+					out = append(out, *acc)
+				}
+			}
+			return out, nil
+		},
+	}
+	return repo, accounts
+}
+
+func TestChartOfAccounts(t *testing.T) {
+	ctx := context.Background()
+	repo, accounts := chartTestRepo()
+	service := NewLedgerService(repo, nil)
+
+	seeded, err := service.SeedChartOfAccounts(ctx, ChartFintechBasic, "zone_1", "test", "USD")
+	if err != nil {
+		t.Fatalf("SeedChartOfAccounts: %v", err)
+	}
+	if len(seeded) != len(chartTemplates[ChartFintechBasic]) {
+		t.Fatalf("expected %d accounts, got %d", len(chartTemplates[ChartFintechBasic]), len(seeded))
+	}
+
+	// Seeding again reuses the existing accounts
+	if _, err := service.SeedChartOfAccounts(ctx, ChartFintechBasic, "zone_1", "test", "USD"); err != nil {
+		t.Fatalf("second SeedChartOfAccounts: %v", err)
+	}
+	if len(accounts) != len(seeded) {
+		t.Errorf("expected reseeding to create nothing, have %d accounts", len(accounts))
+	}
+
+	wallets, err := service.SystemAccount(ctx, "zone_1", "test", CodeCustomerWallets, "USD")
+	if err != nil {
+		t.Fatalf("SystemAccount: %v", err)
+	}
+	funds, _ := service.GetAccountByCode(ctx, "zone_1", "test", CodeCustomerFunds, "USD")
+	if wallets.ParentID == nil || *wallets.ParentID != funds.ID {
+		t.Errorf("expected %s under %s, got parent %v", CodeCustomerWallets, CodeCustomerFunds, wallets.ParentID)
+	}
+
+	t.Run("UserAccount opens a wallet once", func(t *testing.T) {
+		first, err := service.UserAccount(ctx, "zone_1", "test", "u1", "USD")
+		if err != nil {
+			t.Fatalf("UserAccount: %v", err)
+		}
+		if first.ParentID == nil || *first.ParentID != wallets.ID || first.Type != Liability {
+			t.Errorf("expected a liability wallet under %s, got %+v", wallets.ID, first)
+		}
+		second, _ := service.UserAccount(ctx, "zone_1", "test", "u1", "USD")
+		if second.ID != first.ID {
+			t.Errorf("expected the same wallet, got %s and %s", first.ID, second.ID)
+		}
+	})
+
+	t.Run("UserAccount uses a wallet opened concurrently", func(t *testing.T) {
+		winner, _ := service.UserAccount(ctx, "zone_2", "test", "u3", "USD")
+		lookup, create := repo.GetUserAccountFunc, repo.CreateAccountFunc
+		defer func() { repo.GetUserAccountFunc, repo.CreateAccountFunc = lookup, create }()
+		// The first lookup misses the winner's wallet and the insert then
+		// hits the unique index
+		missed := false
+		repo.GetUserAccountFunc = func(ctx context.Context, zoneID, mode, userID, currency string) (*Account, error) {
+			if !missed {
+				missed = true
+				return nil, nil
+			}
+			return lookup(ctx, zoneID, mode, userID, currency)
+		}
+		repo.CreateAccountFunc = func(ctx context.Context, acc *Account) error {
+			return fmt.Errorf("duplicate key value violates unique constraint \"idx_accounts_zone_user\"")
+		}
+
+		got, err := service.UserAccount(ctx, "zone_2", "test", "u3", "USD")
+		if err != nil {
+			t.Fatalf("UserAccount: %v", err)
+		}
+		if got.ID != winner.ID {
+			t.Errorf("expected the winner's wallet %s, got %s", winner.ID, got.ID)
+		}
+	})
+
+	t.Run("Roll-up balances", func(t *testing.T) {
+		u1, _ := service.UserAccount(ctx, "zone_1", "test", "u1", "USD")
+		u2, _ := service.UserAccount(ctx, "zone_1", "test", "u2", "USD")
+		accounts[u1.ID].Balance = 700
+		accounts[u2.ID].Balance = 300
+		accounts[u2.ID].HeldAmount = 50
+		accounts[wallets.ID].Balance = 5
+
+		node, err := service.GetRollupBalance(ctx, funds.ID)
+		if err != nil {
+			t.Fatalf("GetRollupBalance: %v", err)
+		}
+		if node.RollupBalance != 1005 || node.RollupHeld != 50 {
+			t.Errorf("expected roll-up 1005 with 50 held, got %d with %d held", node.RollupBalance, node.RollupHeld)
+		}
+		// Customer Funds, Customer Wallets, two wallets and Merchant Payables
+		if node.RollupAccounts != 5 {
+			t.Errorf("expected 5 accounts in the subtree, got %d", node.RollupAccounts)
+		}
+
+		chart, err := service.GetChartOfAccounts(ctx, "zone_1", "test")
+		if err != nil {
+			t.Fatalf("GetChartOfAccounts: %v", err)
+		}
+		if chart[0].Label != "1000-Cash" || len(chart[0].Children) != 2 {
+			t.Errorf("expected 1000-Cash with 2 children first, got %s with %d", chart[0].Label, len(chart[0].Children))
+		}
+	})
+
+	t.Run("Parent validation", func(t *testing.T) {
+		cash, _ := service.GetAccountByCode(ctx, "zone_1", "test", CodeCash, "USD")
+		missing := "acc_missing"
+		tests := []struct {
+			name string
+			req  AccountRequest
+			zone string
+		}{
+			{"type mismatch", AccountRequest{Name: "X", Type: Liability, Currency: "USD", ParentID: &cash.ID}, "zone_1"},
+			{"currency mismatch", AccountRequest{Name: "X", Type: Asset, Currency: "EUR", ParentID: &cash.ID}, "zone_1"},
+			{"other zone", AccountRequest{Name: "X", Type: Asset, Currency: "USD", ParentID: &cash.ID}, "zone_2"},
+			{"unknown parent", AccountRequest{Name: "X", Type: Asset, Currency: "USD", ParentID: &missing}, "zone_1"},
+			{"duplicate code", AccountRequest{Name: "X", Type: Asset, Currency: "USD", Code: CodeCash}, "zone_1"},
+		}
+		for _, tt := range tests {
+			if _, err := service.CreateAccount(ctx, tt.req, tt.zone, "test"); !IsValidationError(err) {
+				t.Errorf("%s: expected a validation error, got %v", tt.name, err)
+			}
+		}
+
+		if _, err := service.CreateAccount(ctx, AccountRequest{Name: "Petty Cash", Type: Asset, Currency: "USD", Code: "1030", ParentID: &cash.ID}, "zone_1", "test"); err != nil {
+			t.Errorf("expected child account to be created, got %v", err)
+		}
+	})
+
+	t.Run("Unknown template", func(t *testing.T) {
+		if _, err := service.SeedChartOfAccounts(ctx, "nope", "zone_1", "test", "USD"); !IsValidationError(err) {
+			t.Errorf("expected a validation error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationError is returned when a request is rejected by ledger rules
+// rather than failing in storage. API layers map it to a client error.
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+func invalidf(format string, args ...any) error {
+	return &ValidationError{msg: fmt.Sprintf(format, args...)}
+}
+
+// IsValidationError reports whether err, or any error it wraps, is a
+// ValidationError.
+func IsValidationError(err error) bool {
+	var verr *ValidationError
+	return errors.As(err, &verr)
+}
@@ -0,0 +1,364 @@
+package domain
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/currency"
+)
+
+type ExportFormat string
+
+const (
+	ExportCAMT053 ExportFormat = "camt053" // ISO 20022 BankToCustomerStatement
+	ExportCSV     ExportFormat = "csv"
+)
+
+// camt053Namespace is the camt.053 version our accounting partners import.
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// ExportRequest selects the statements to export. With no account IDs every
+// account of the zone is exported, one statement per account.
+type ExportRequest struct {
+	Format     ExportFormat
+	AccountIDs []string
+	From       time.Time
+	To         time.Time
+}
+
+// statementEncoder writes statements in one export format. Entries are
+// passed one at a time so exports never hold a whole range in memory.
+type statementEncoder interface {
+	begin(createdAt time.Time) error
+	startStatement(st *Statement) error
+	entry(st *Statement, l StatementLine) error
+	endStatement(st *Statement) error
+	end() error
+}
+
+// ExportStatements streams account statements for [From, To) to w. Accounts
+// are checked before anything is written, so a bad request never produces
+// a partial file.
+func (s *LedgerService) ExportStatements(ctx context.Context, w io.Writer, zoneID, mode string, req ExportRequest) error {
+	if !req.From.Before(req.To) {
+		return invalidf("export range is empty: from must be before to")
+	}
+
+	var enc statementEncoder
+	switch req.Format {
+	case ExportCAMT053:
+		enc = newCAMT053Encoder(w, zoneID)
+	case ExportCSV:
+		enc = newCSVStatementEncoder(w)
+	default:
+		return invalidf("unsupported export format %q: use camt053 or csv", req.Format)
+	}
+
+	accounts, err := s.exportAccounts(ctx, zoneID, mode, req.AccountIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := enc.begin(time.Now().UTC()); err != nil {
+		return err
+	}
+	for _, acc := range accounts {
+		if err := s.exportStatement(ctx, enc, acc, req.From, req.To); err != nil {
+			return fmt.Errorf("failed to export statement of account %s: %w", acc.ID, err)
+		}
+	}
+	return enc.end()
+}
+
+func (s *LedgerService) exportAccounts(ctx context.Context, zoneID, mode string, ids []string) ([]Account, error) {
+	if len(ids) == 0 {
+		return s.repo.ListAccounts(ctx, zoneID, mode)
+	}
+
+	accounts := make([]Account, 0, len(ids))
+	for _, id := range ids {
+		acc, err := s.repo.GetAccount(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if acc == nil || acc.ZoneID != zoneID || acc.Mode != mode {
+			return nil, invalidf("account %s not found", id)
+		}
+		accounts = append(accounts, *acc)
+	}
+	return accounts, nil
+}
+
+func (s *LedgerService) exportStatement(ctx context.Context, enc statementEncoder, acc Account, from, to time.Time) error {
+	opening, err := s.repo.GetBalanceAt(ctx, acc.ID, from)
+	if err != nil {
+		return err
+	}
+	// Balances come before entries in camt.053, so the closing balance is
+	// read up front rather than summed while streaming.
+	closing, err := s.repo.GetBalanceAt(ctx, acc.ID, to)
+	if err != nil {
+		return err
+	}
+
+	st := &Statement{
+		AccountID:      acc.ID,
+		AccountName:    acc.Name,
+		Currency:       acc.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: closing,
+	}
+	if err := enc.startStatement(st); err != nil {
+		return err
+	}
+
+	running := opening
+	err = s.repo.StreamAccountEntries(ctx, acc.ID, from, to, func(l StatementLine) error {
+		running += l.Amount
+		l.RunningBalance = running
+		return enc.entry(st, l)
+	})
+	if err != nil {
+		return err
+	}
+	return enc.endStatement(st)
+}
+
+// creditDebit returns the ISO 20022 indicator for a signed ledger amount;
+// positive amounts are credits.
+func creditDebit(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// csvStatementEncoder writes one flat CSV for all accounts. Each statement
+// has an opening row, one row per entry and a closing row.
+type csvStatementEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVStatementEncoder(w io.Writer) *csvStatementEncoder {
+	return &csvStatementEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvStatementEncoder) begin(time.Time) error {
+	return e.w.Write([]string{"record_type", "account_id", "account_name", "currency", "booking_date", "value_date",
+		"entry_id", "transaction_id", "reference_id", "description", "credit_debit", "amount", "balance"})
+}
+
+func (e *csvStatementEncoder) startStatement(st *Statement) error {
+	return e.balanceRow("opening", st, st.From, st.OpeningBalance)
+}
+
+func (e *csvStatementEncoder) entry(st *Statement, l StatementLine) error {
+	return e.w.Write([]string{
+		"entry",
+		st.AccountID,
+		st.AccountName,
+		st.Currency,
+		l.CreatedAt.UTC().Format(time.RFC3339),
+		l.EffectiveAt.UTC().Format(time.RFC3339),
+		l.EntryID,
+		l.TransactionID,
+		l.ReferenceID,
+		l.Description,
+		creditDebit(l.Amount),
+		currency.FormatMinor(l.Amount, st.Currency),
+		currency.FormatMinor(l.RunningBalance, st.Currency),
+	})
+}
+
+func (e *csvStatementEncoder) endStatement(st *Statement) error {
+	if err := e.balanceRow("closing", st, st.To, st.ClosingBalance); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvStatementEncoder) balanceRow(kind string, st *Statement, at time.Time, balance int64) error {
+	date := at.UTC().Format(time.RFC3339)
+	return e.w.Write([]string{kind, st.AccountID, st.AccountName, st.Currency, date, date,
+		"", "", "", "", creditDebit(balance), "", currency.FormatMinor(balance, st.Currency)})
+}
+
+func (e *csvStatementEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// camt053Encoder writes a BkToCstmrStmt document with one Stmt per account.
+type camt053Encoder struct {
+	w         io.Writer
+	enc       *xml.Encoder
+	zoneID    string
+	createdAt time.Time
+	entries   int
+}
+
+func newCAMT053Encoder(w io.Writer, zoneID string) *camt053Encoder {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &camt053Encoder{w: w, enc: enc, zoneID: zoneID}
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtGroupHeader struct {
+	XMLName   xml.Name `xml:"GrpHdr"`
+	MessageID string   `xml:"MsgId"`
+	CreatedAt string   `xml:"CreDtTm"`
+}
+
+type camtAccount struct {
+	XMLName  xml.Name `xml:"Acct"`
+	ID       string   `xml:"Id>Othr>Id"`
+	Currency string   `xml:"Ccy"`
+	Name     string   `xml:"Nm,omitempty"`
+}
+
+type camtBalance struct {
+	XMLName     xml.Name   `xml:"Bal"`
+	Code        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Date        string     `xml:"Dt>DtTm"`
+}
+
+type camtEntry struct {
+	XMLName        xml.Name   `xml:"Ntry"`
+	Reference      string     `xml:"NtryRef"`
+	Amount         camtAmount `xml:"Amt"`
+	CreditDebit    string     `xml:"CdtDbtInd"`
+	Status         string     `xml:"Sts"`
+	BookingDate    string     `xml:"BookgDt>DtTm"`
+	ValueDate      string     `xml:"ValDt>DtTm"`
+	ServicerRef    string     `xml:"AcctSvcrRef"`
+	BankTxCode     string     `xml:"BkTxCd>Prtry>Cd"`
+	EndToEndID     string     `xml:"NtryDtls>TxDtls>Refs>EndToEndId,omitempty"`
+	RemittanceInfo string     `xml:"NtryDtls>TxDtls>RmtInf>Ustrd,omitempty"`
+}
+
+func (e *camt053Encoder) begin(createdAt time.Time) error {
+	e.createdAt = createdAt
+	// Written directly: the encoder puts no line break after a ProcInst
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	doc := xml.StartElement{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}}}
+	if err := e.enc.EncodeToken(doc); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "BkToCstmrStmt"}}); err != nil {
+		return err
+	}
+	return e.enc.Encode(camtGroupHeader{
+		MessageID: maxText("STMT-"+createdAt.Format("20060102150405")+"-"+camtID(e.zoneID), 35),
+		CreatedAt: camtDateTime(createdAt),
+	})
+}
+
+func (e *camt053Encoder) startStatement(st *Statement) error {
+	if err := e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "Stmt"}}); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement(maxText(camtID(st.AccountID)+"-"+st.From.UTC().Format("20060102"), 35), xml.StartElement{Name: xml.Name{Local: "Id"}}); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement(camtDateTime(e.createdAt), xml.StartElement{Name: xml.Name{Local: "CreDtTm"}}); err != nil {
+		return err
+	}
+	fromTo := struct {
+		XMLName xml.Name `xml:"FrToDt"`
+		From    string   `xml:"FrDtTm"`
+		To      string   `xml:"ToDtTm"`
+	}{From: camtDateTime(st.From), To: camtDateTime(st.To)}
+	if err := e.enc.Encode(fromTo); err != nil {
+		return err
+	}
+	if err := e.enc.Encode(camtAccount{ID: maxText(camtID(st.AccountID), 34), Currency: st.Currency, Name: maxText(st.AccountName, 70)}); err != nil {
+		return err
+	}
+	if err := e.enc.Encode(camtBalanceOf("OPBD", st.OpeningBalance, st.Currency, st.From)); err != nil {
+		return err
+	}
+	return e.enc.Encode(camtBalanceOf("CLBD", st.ClosingBalance, st.Currency, st.To))
+}
+
+func (e *camt053Encoder) entry(st *Statement, l StatementLine) error {
+	err := e.enc.Encode(camtEntry{
+		Reference:      maxText(camtID(l.EntryID), 35),
+		Amount:         camtAmount{Currency: st.Currency, Value: currency.FormatMinor(abs(l.Amount), st.Currency)},
+		CreditDebit:    creditDebit(l.Amount),
+		Status:         "BOOK",
+		BookingDate:    camtDateTime(l.CreatedAt),
+		ValueDate:      camtDateTime(l.EffectiveAt),
+		ServicerRef:    maxText(camtID(l.TransactionID), 35),
+		BankTxCode:     "LEDGER",
+		EndToEndID:     maxText(l.ReferenceID, 35),
+		RemittanceInfo: maxText(l.Description, 140),
+	})
+	if err != nil {
+		return err
+	}
+	// Push output regularly so large exports start arriving right away
+	if e.entries++; e.entries%100 == 0 {
+		return e.enc.Flush()
+	}
+	return nil
+}
+
+func (e *camt053Encoder) endStatement(*Statement) error {
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "Stmt"}}); err != nil {
+		return err
+	}
+	return e.enc.Flush()
+}
+
+func (e *camt053Encoder) end() error {
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "BkToCstmrStmt"}}); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "Document"}}); err != nil {
+		return err
+	}
+	return e.enc.Flush()
+}
+
+func camtBalanceOf(code string, balance int64, ccy string, at time.Time) camtBalance {
+	return camtBalance{
+		Code:        code,
+		Amount:      camtAmount{Currency: ccy, Value: currency.FormatMinor(abs(balance), ccy)},
+		CreditDebit: creditDebit(balance),
+		Date:        camtDateTime(at),
+	}
+}
+
+func camtDateTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// camtID drops the dashes from UUIDs so they fit ISO 20022 Max35Text fields.
+func camtID(id string) string {
+	return strings.ReplaceAll(id, "-", "")
+}
+
+// maxText truncates s to n characters, the length limit of the field.
+func maxText(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
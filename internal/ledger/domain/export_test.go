@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportStatements(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	accounts := map[string]*Account{
+		"acc_wallet": {ID: "acc_wallet", Name: "Wallet", Currency: "USD", ZoneID: "zone_1", Mode: "live"},
+		"acc_other":  {ID: "acc_other", Name: "Other", Currency: "USD", ZoneID: "zone_2", Mode: "live"},
+	}
+	lines := []StatementLine{
+		{EntryID: "e-1", TransactionID: "t-1", ReferenceID: "pay_1", Description: "Top up", Amount: 2500, Direction: Credit, EffectiveAt: from.Add(time.Hour), CreatedAt: from.Add(time.Hour)},
+		{EntryID: "e-2", TransactionID: "t-2", ReferenceID: "pay_2", Description: "Card purchase", Amount: -1000, Direction: Debit, EffectiveAt: from.Add(48 * time.Hour), CreatedAt: from.Add(48 * time.Hour)},
+	}
+
+	mockRepo := &MockRepository{
+		GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+			return accounts[id], nil
+		},
+		GetBalanceAtFunc: func(ctx context.Context, accountID string, at time.Time) (int64, error) {
+			if at.Equal(from) {
+				return 10000, nil
+			}
+			return 11500, nil
+		},
+		StreamAccountEntriesFunc: func(ctx context.Context, accountID string, from, to time.Time, fn func(StatementLine) error) error {
+			for _, l := range lines {
+				if err := fn(l); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	service := NewLedgerService(mockRepo, nil)
+
+	t.Run("camt.053", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := service.ExportStatements(context.Background(), &buf, "zone_1", "live", ExportRequest{
+			Format: ExportCAMT053, AccountIDs: []string{"acc_wallet"}, From: from, To: to,
+		})
+		if err != nil {
+			t.Fatalf("ExportStatements: %v", err)
+		}
+
+		var doc struct {
+			XMLName xml.Name `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02 Document"`
+			Stmts   []struct {
+				Acct string `xml:"Acct>Id>Othr>Id"`
+				Bal  []struct {
+					Code string `xml:"Tp>CdOrPrtry>Cd"`
+					Amt  string `xml:"Amt"`
+					Ind  string `xml:"CdtDbtInd"`
+				} `xml:"Bal"`
+				Ntry []struct {
+					Amt   string `xml:"Amt"`
+					Ind   string `xml:"CdtDbtInd"`
+					Ref   string `xml:"AcctSvcrRef"`
+					E2E   string `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+					Ustrd string `xml:"NtryDtls>TxDtls>RmtInf>Ustrd"`
+				} `xml:"Ntry"`
+			} `xml:"BkToCstmrStmt>Stmt"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("export is not valid camt.053 XML: %v\n%s", err, buf.String())
+		}
+		if len(doc.Stmts) != 1 {
+			t.Fatalf("expected 1 statement, got %d", len(doc.Stmts))
+		}
+		st := doc.Stmts[0]
+		if st.Acct != "acc_wallet" {
+			t.Errorf("expected account acc_wallet, got %s", st.Acct)
+		}
+		if len(st.Bal) != 2 || st.Bal[0].Code != "OPBD" || st.Bal[0].Amt != "100.00" || st.Bal[1].Code != "CLBD" || st.Bal[1].Amt != "115.00" {
+			t.Errorf("unexpected balances: %+v", st.Bal)
+		}
+		if len(st.Ntry) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(st.Ntry))
+		}
+		if st.Ntry[0].Amt != "25.00" || st.Ntry[0].Ind != "CRDT" || st.Ntry[0].E2E != "pay_1" || st.Ntry[0].Ustrd != "Top up" {
+			t.Errorf("unexpected first entry: %+v", st.Ntry[0])
+		}
+		if st.Ntry[1].Amt != "10.00" || st.Ntry[1].Ind != "DBIT" || st.Ntry[1].Ref != "t2" {
+			t.Errorf("unexpected second entry: %+v", st.Ntry[1])
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := service.ExportStatements(context.Background(), &buf, "zone_1", "live", ExportRequest{
+			Format: ExportCSV, AccountIDs: []string{"acc_wallet"}, From: from, To: to,
+		})
+		if err != nil {
+			t.Fatalf("ExportStatements: %v", err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		// Header, opening, two entries, closing
+		if len(rows) != 5 {
+			t.Fatalf("expected 5 rows, got %d", len(rows))
+		}
+		if got := strings.Join(rows[3], ","); !strings.Contains(got, "entry,acc_wallet") || !strings.HasSuffix(got, "DBIT,-10.00,115.00") {
+			t.Errorf("unexpected entry row: %s", got)
+		}
+		if rows[4][0] != "closing" || rows[4][12] != "115.00" {
+			t.Errorf("unexpected closing row: %v", rows[4])
+		}
+	})
+
+	t.Run("Rejected before writing", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  ExportRequest
+		}{
+			{"other zone", ExportRequest{Format: ExportCSV, AccountIDs: []string{"acc_other"}, From: from, To: to}},
+			{"unknown format", ExportRequest{Format: "mt940", AccountIDs: []string{"acc_wallet"}, From: from, To: to}},
+			{"empty range", ExportRequest{Format: ExportCSV, AccountIDs: []string{"acc_wallet"}, From: to, To: from}},
+		}
+		for _, tt := range tests {
+			var buf bytes.Buffer
+			err := service.ExportStatements(context.Background(), &buf, "zone_1", "live", tt.req)
+			if !IsValidationError(err) {
+				t.Errorf("%s: expected a validation error, got %v", tt.name, err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("%s: expected no output, got %q", tt.name, buf.String())
+			}
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// fxRateTolerance is the rounding slack, in minor units, allowed between a
+// conversion's ToAmount and FromAmount * Rate.
+const fxRateTolerance = 1
+
+// SetFXRate stores or replaces the rate for a currency pair, in the rate's
+// zone or platform-wide when it has none.
+func (s *LedgerService) SetFXRate(ctx context.Context, rate FXRate) (*FXRate, error) {
+	rate.BaseCurrency = strings.ToUpper(rate.BaseCurrency)
+	rate.QuoteCurrency = strings.ToUpper(rate.QuoteCurrency)
+	if rate.BaseCurrency == "" || rate.QuoteCurrency == "" {
+		return nil, invalidf("invalid fx rate: base and quote currency are required")
+	}
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return nil, invalidf("invalid fx rate: base and quote currency must differ")
+	}
+	if rate.Rate <= 0 {
+		return nil, invalidf("invalid fx rate for %s/%s: rate must be positive", rate.BaseCurrency, rate.QuoteCurrency)
+	}
+	if rate.Source == "" {
+		rate.Source = "api"
+	}
+	if rate.AsOf.IsZero() {
+		rate.AsOf = time.Now().UTC()
+	}
+	if err := s.repo.UpsertFXRate(ctx, &rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// ListFXRates returns the rates that apply to a zone: its own and the
+// platform-wide ones.
+func (s *LedgerService) ListFXRates(ctx context.Context, zoneID, mode string) ([]FXRate, error) {
+	return s.repo.ListFXRates(ctx, zoneID, mode)
+}
+
+// LoadFXRates reads a JSON array of FXRate objects and stores each of them.
+// It is used to seed the platform-wide rates from a file at startup.
+func (s *LedgerService) LoadFXRates(ctx context.Context, r io.Reader) (int, error) {
+	var rates []FXRate
+	if err := json.NewDecoder(r).Decode(&rates); err != nil {
+		return 0, fmt.Errorf("failed to decode fx rates: %w", err)
+	}
+	for i, rate := range rates {
+		if rate.Source == "" {
+			rate.Source = "file"
+		}
+		if _, err := s.SetFXRate(ctx, rate); err != nil {
+			return i, err
+		}
+	}
+	return len(rates), nil
+}
+
+// resolveConversions validates the conversions of a transaction against the
+// currencies it touches, filling in rates from the zone's rate table where
+// the caller did not supply one. clearing holds the net amount the
+// transaction posts to FX Clearing accounts per currency: each conversion
+// debits its FromAmount there and credits its ToAmount, so the conversions
+// must add up to exactly those legs.
+func (s *LedgerService) resolveConversions(ctx context.Context, zoneID, mode string, convs []FXConversion, currencies []string, clearing map[string]int64) error {
+	linked := make(map[string]bool)
+	converted := make(map[string]int64)
+	for i := range convs {
+		c := &convs[i]
+		c.FromCurrency = strings.ToUpper(c.FromCurrency)
+		c.ToCurrency = strings.ToUpper(c.ToCurrency)
+		if c.FromCurrency == "" || c.ToCurrency == "" || c.FromCurrency == c.ToCurrency {
+			return invalidf("invalid fx conversion %d: from and to currency must be set and differ", i)
+		}
+		if c.FromAmount <= 0 || c.ToAmount <= 0 {
+			return invalidf("invalid fx conversion %s->%s: amounts must be positive", c.FromCurrency, c.ToCurrency)
+		}
+
+		if c.Rate == 0 {
+			rate, err := s.lookupFXRate(ctx, zoneID, mode, c.FromCurrency, c.ToCurrency)
+			if err != nil {
+				return err
+			}
+			c.Rate = rate.Rate
+			c.RateSource = rate.Source
+			c.RateTimestamp = rate.AsOf
+		}
+		if c.Rate < 0 {
+			return invalidf("invalid fx conversion %s->%s: rate must be positive", c.FromCurrency, c.ToCurrency)
+		}
+		if c.RateSource == "" {
+			c.RateSource = "client"
+		}
+		if c.RateTimestamp.IsZero() {
+			c.RateTimestamp = time.Now().UTC()
+		}
+
+		expected := int64(math.Round(float64(c.FromAmount) * c.Rate))
+		if diff := expected - c.ToAmount; diff > fxRateTolerance || diff < -fxRateTolerance {
+			return invalidf("fx conversion %s->%s does not match rate %g: expected %d, got %d", c.FromCurrency, c.ToCurrency, c.Rate, expected, c.ToAmount)
+		}
+
+		linked[c.FromCurrency] = true
+		linked[c.ToCurrency] = true
+		converted[c.FromCurrency] -= c.FromAmount
+		converted[c.ToCurrency] += c.ToAmount
+	}
+
+	if len(convs) > 0 {
+		posted := make(map[string]bool, len(currencies))
+		for _, cur := range currencies {
+			if !linked[cur] {
+				return invalidf("currency %s is not covered by any fx conversion", cur)
+			}
+			if clearing[cur] != converted[cur] {
+				return invalidf("fx conversions net %d %s on fx clearing, but the transaction posts %d", converted[cur], cur, clearing[cur])
+			}
+			posted[cur] = true
+		}
+		for _, c := range convs {
+			for _, cur := range []string{c.FromCurrency, c.ToCurrency} {
+				if !posted[cur] {
+					return invalidf("fx conversion currency %s is not posted by the transaction", cur)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lookupFXRate finds the rate for base/quote, falling back to the inverse of
+// a stored quote/base rate.
+func (s *LedgerService) lookupFXRate(ctx context.Context, zoneID, mode, base, quote string) (*FXRate, error) {
+	rate, err := s.repo.GetFXRate(ctx, zoneID, mode, base, quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fx rate %s/%s: %w", base, quote, err)
+	}
+	if rate != nil {
+		return rate, nil
+	}
+
+	inverse, err := s.repo.GetFXRate(ctx, zoneID, mode, quote, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fx rate %s/%s: %w", quote, base, err)
+	}
+	if inverse == nil {
+		return nil, invalidf("no fx rate for %s/%s", base, quote)
+	}
+	return &FXRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          1 / inverse.Rate,
+		Source:        inverse.Source,
+		AsOf:          inverse.AsOf,
+	}, nil
+}
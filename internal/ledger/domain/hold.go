@@ -0,0 +1,333 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultHoldTTL is how long a hold stays active when no expiry is given.
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+// ErrHoldNotFound is returned for a hold that does not exist or belongs to
+// another zone or mode than the caller's.
+var ErrHoldNotFound = errors.New("hold not found")
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// Hold reserves funds on an account without posting them. While active it
+// lowers the account's available balance but not its posted balance.
+type Hold struct {
+	ID             string     `json:"id"`
+	AccountID      string     `json:"account_id"`
+	ZoneID         string     `json:"zone_id"`
+	Mode           string     `json:"mode"`
+	Amount         int64      `json:"amount"`
+	CapturedAmount int64      `json:"captured_amount"`
+	ReferenceID    string     `json:"reference_id"`
+	Description    string     `json:"description"`
+	Status         HoldStatus `json:"status"`
+	TransactionID  *string    `json:"transaction_id,omitempty"` // Capture transaction
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type HoldRequest struct {
+	AccountID   string     `json:"account_id"`
+	Amount      int64      `json:"amount"`
+	ReferenceID string     `json:"reference_id"`
+	Description string     `json:"description"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Defaults to 7 days from now
+}
+
+// CaptureRequest turns a hold into a posted transaction that debits the held
+// account and credits ToAccountID. Amount defaults to the full hold; any
+// remainder is released.
+type CaptureRequest struct {
+	Amount      int64  `json:"amount"`
+	ToAccountID string `json:"to_account_id"`
+	ReferenceID string `json:"reference_id"` // Defaults to "capture_<hold id>"
+	Description string `json:"description"`
+}
+
+// holdCapture releases a hold as part of posting its capture transaction.
+type holdCapture struct {
+	holdID string
+	amount int64
+}
+
+// setBalances fills in the derived posted and available balances.
+func (a *Account) setBalances() {
+	a.PostedBalance = a.Balance
+	a.AvailableBalance = a.Balance - a.HeldAmount
+}
+
+// CreateHold reserves funds on an account of the zone. The account's
+// overdraft limit is enforced against the available balance. Creating a hold
+// with a reference ID that was already used returns the existing hold.
+func (s *LedgerService) CreateHold(ctx context.Context, req HoldRequest, zoneID, mode string) (*Hold, error) {
+	if req.Amount <= 0 {
+		return nil, invalidf("hold amount must be positive")
+	}
+	if req.ReferenceID == "" {
+		return nil, invalidf("hold reference_id is required")
+	}
+	expiresAt := time.Now().UTC().Add(defaultHoldTTL)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return nil, invalidf("hold expires_at must be in the future")
+		}
+		expiresAt = req.ExpiresAt.UTC()
+	}
+	if _, err := s.zoneAccount(ctx, zoneID, mode, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		hold, err := s.postHold(ctx, req, zoneID, mode, expiresAt)
+		if !errors.Is(err, ErrVersionConflict) || attempt >= maxBalanceRetries {
+			return hold, err
+		}
+	}
+}
+
+func (s *LedgerService) postHold(ctx context.Context, req HoldRequest, zoneID, mode string, expiresAt time.Time) (*Hold, error) {
+	txCtx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = txCtx.Rollback() }()
+
+	existing, err := txCtx.GetHoldByReference(ctx, req.ReferenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check hold reference: %w", err)
+	}
+	if existing != nil {
+		if existing.ZoneID != zoneID || existing.Mode != mode {
+			return nil, invalidf("reference %s is already used by hold %s", req.ReferenceID, existing.ID)
+		}
+		return existing, nil
+	}
+
+	acc, err := txCtx.GetAccountBalance(ctx, req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance for account %s: %w", req.AccountID, err)
+	}
+	if acc == nil {
+		return nil, invalidf("account %s not found", req.AccountID)
+	}
+	available := acc.Balance - acc.HeldAmount
+	if floor, ok := acc.BalanceFloor(); ok && available-req.Amount < floor {
+		return nil, invalidf("insufficient funds: account %s available balance %d cannot be held by %d (limit %d)", req.AccountID, available, req.Amount, floor)
+	}
+	if err := txCtx.UpdateAccountBalance(ctx, req.AccountID, acc.Balance, acc.HeldAmount+req.Amount, acc.Version); err != nil {
+		return nil, err
+	}
+
+	hold := &Hold{
+		AccountID:   req.AccountID,
+		ZoneID:      zoneID,
+		Mode:        mode,
+		Amount:      req.Amount,
+		ReferenceID: req.ReferenceID,
+		Description: req.Description,
+		Status:      HoldActive,
+		ExpiresAt:   expiresAt,
+	}
+	if err := txCtx.CreateHold(ctx, hold); err != nil {
+		return nil, fmt.Errorf("failed to create hold: %w", err)
+	}
+
+	if err := createHoldEvent(ctx, txCtx, "hold.created", hold); err != nil {
+		return nil, err
+	}
+	return hold, txCtx.Commit()
+}
+
+// GetHold returns a hold of the zone, or nil if it has none with the ID.
+func (s *LedgerService) GetHold(ctx context.Context, zoneID, mode, id string) (*Hold, error) {
+	hold, err := s.repo.GetHold(ctx, id)
+	if err != nil || hold == nil {
+		return nil, err
+	}
+	if hold.ZoneID != zoneID || hold.Mode != mode {
+		return nil, nil
+	}
+	return hold, nil
+}
+
+// CaptureHold posts a hold of the zone as a real transaction and releases
+// the hold in the same database transaction.
+func (s *LedgerService) CaptureHold(ctx context.Context, zoneID, mode, holdID string, req CaptureRequest) (*Hold, error) {
+	hold, err := s.GetHold(ctx, zoneID, mode, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold == nil {
+		return nil, ErrHoldNotFound
+	}
+	if err := checkHoldCapturable(hold, time.Now()); err != nil {
+		return nil, err
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount < 0 || amount > hold.Amount {
+		return nil, invalidf("capture amount %d must be between 1 and the held amount %d", amount, hold.Amount)
+	}
+	if req.ToAccountID == "" {
+		return nil, invalidf("to_account_id is required")
+	}
+	if _, err := s.zoneAccount(ctx, zoneID, mode, req.ToAccountID); err != nil {
+		return nil, err
+	}
+	if req.ReferenceID == "" {
+		req.ReferenceID = "capture_" + holdID
+	}
+	if req.Description == "" {
+		req.Description = hold.Description
+	}
+
+	_, err = s.recordTransaction(ctx, TransactionRequest{
+		ReferenceID: req.ReferenceID,
+		Description: req.Description,
+		Entries: []EntryRequest{
+			{AccountID: hold.AccountID, Amount: -amount, Direction: string(Debit)},
+			{AccountID: req.ToAccountID, Amount: amount, Direction: string(Credit)},
+		},
+		capture: &holdCapture{holdID: holdID, amount: amount},
+	}, hold.ZoneID, hold.Mode)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetHold(ctx, holdID)
+}
+
+// VoidHold releases an active hold of the zone without posting anything.
+func (s *LedgerService) VoidHold(ctx context.Context, zoneID, mode, holdID string) (*Hold, error) {
+	return s.releaseHold(ctx, zoneID, mode, holdID, HoldVoided)
+}
+
+// ExpireHolds releases active holds whose expiry has passed and returns how
+// many were expired.
+func (s *LedgerService) ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error) {
+	holds, err := s.repo.ListExpiredHolds(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, h := range holds {
+		if _, err := s.releaseHold(ctx, h.ZoneID, h.Mode, h.ID, HoldExpired); err != nil {
+			// Captured or voided since it was listed
+			if IsValidationError(err) {
+				continue
+			}
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *LedgerService) releaseHold(ctx context.Context, zoneID, mode, holdID string, status HoldStatus) (*Hold, error) {
+	for attempt := 1; ; attempt++ {
+		hold, err := s.postHoldRelease(ctx, zoneID, mode, holdID, status)
+		if !errors.Is(err, ErrVersionConflict) || attempt >= maxBalanceRetries {
+			return hold, err
+		}
+	}
+}
+
+func (s *LedgerService) postHoldRelease(ctx context.Context, zoneID, mode, holdID string, status HoldStatus) (*Hold, error) {
+	txCtx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = txCtx.Rollback() }()
+
+	hold, err := txCtx.LockHold(ctx, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock hold %s: %w", holdID, err)
+	}
+	if hold == nil || hold.ZoneID != zoneID || hold.Mode != mode {
+		return nil, ErrHoldNotFound
+	}
+	if hold.Status != HoldActive {
+		return nil, invalidf("hold %s is %s", holdID, hold.Status)
+	}
+
+	acc, err := txCtx.GetAccountBalance(ctx, hold.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance for account %s: %w", hold.AccountID, err)
+	}
+	if acc == nil {
+		return nil, invalidf("account %s not found", hold.AccountID)
+	}
+	if err := txCtx.UpdateAccountBalance(ctx, hold.AccountID, acc.Balance, acc.HeldAmount-hold.Amount, acc.Version); err != nil {
+		return nil, err
+	}
+
+	hold.Status = status
+	if err := txCtx.UpdateHold(ctx, hold); err != nil {
+		return nil, fmt.Errorf("failed to update hold %s: %w", holdID, err)
+	}
+
+	if err := createHoldEvent(ctx, txCtx, "hold."+string(status), hold); err != nil {
+		return nil, err
+	}
+	return hold, txCtx.Commit()
+}
+
+// captureHold marks a hold captured by transactionID and returns the held
+// amount to release from its account.
+func captureHold(ctx context.Context, txCtx TransactionContext, c *holdCapture, transactionID string) (*Hold, error) {
+	hold, err := txCtx.LockHold(ctx, c.holdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock hold %s: %w", c.holdID, err)
+	}
+	if hold == nil {
+		return nil, invalidf("hold %s not found", c.holdID)
+	}
+	if err := checkHoldCapturable(hold, time.Now()); err != nil {
+		return nil, err
+	}
+
+	hold.Status = HoldCaptured
+	hold.CapturedAmount = c.amount
+	hold.TransactionID = &transactionID
+	if err := txCtx.UpdateHold(ctx, hold); err != nil {
+		return nil, fmt.Errorf("failed to update hold %s: %w", c.holdID, err)
+	}
+	return hold, createHoldEvent(ctx, txCtx, "hold.captured", hold)
+}
+
+func checkHoldCapturable(hold *Hold, now time.Time) error {
+	if hold.Status != HoldActive {
+		return invalidf("hold %s is %s", hold.ID, hold.Status)
+	}
+	if !now.Before(hold.ExpiresAt) {
+		return invalidf("hold %s expired at %s", hold.ID, hold.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func createHoldEvent(ctx context.Context, txCtx TransactionContext, eventType string, hold *Hold) error {
+	payload, _ := json.Marshal(hold)
+	if err := txCtx.CreateOutboxEvent(ctx, eventType, outboxKey(hold.ZoneID, hold.AccountID), payload); err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+	return nil
+}
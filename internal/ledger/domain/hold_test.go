@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// holdTestRepo wires a MockRepository to in-memory accounts and holds so a
+// hold's lifecycle can be followed across calls.
+func holdTestRepo(accounts map[string]*Account, holds map[string]*Hold, events *[]string) *MockRepository {
+	txCtx := &MockTransactionContext{
+		CheckIdempotencyFunc: func(ctx context.Context, referenceID string) (string, error) { return "", nil },
+		GetPeriodForDateFunc: func(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error) {
+			return nil, nil
+		},
+		CreateTransactionFunc: func(ctx context.Context, tx *Transaction) (string, error) { return "tx_capture", nil },
+		CreateEntryFunc:       func(ctx context.Context, entry *Entry) error { return nil },
+		GetAccountBalanceFunc: func(ctx context.Context, id string) (*Account, error) {
+			acc := *accounts[id]
+			return &acc, nil
+		},
+		UpdateAccountBalanceFunc: func(ctx context.Context, id string, balance, held, expectedVersion int64) error {
+			accounts[id].Balance = balance
+			accounts[id].HeldAmount = held
+			return nil
+		},
+		GetHoldByReferenceFunc: func(ctx context.Context, referenceID string) (*Hold, error) { return nil, nil },
+		CreateHoldFunc: func(ctx context.Context, hold *Hold) error {
+			hold.ID = "hold_1"
+			holds[hold.ID] = hold
+			return nil
+		},
+		LockHoldFunc: func(ctx context.Context, id string) (*Hold, error) {
+			h := *holds[id]
+			return &h, nil
+		},
+		UpdateHoldFunc: func(ctx context.Context, hold *Hold) error {
+			holds[hold.ID] = hold
+			return nil
+		},
+		CreateOutboxEventFunc: func(ctx context.Context, eventType, key string, payload []byte) error {
+			*events = append(*events, eventType)
+			return nil
+		},
+		CommitFunc:   func() error { return nil },
+		RollbackFunc: func() error { return nil },
+	}
+
+	return &MockRepository{
+		GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+			acc := *accounts[id]
+			return &acc, nil
+		},
+		GetHoldFunc: func(ctx context.Context, id string) (*Hold, error) {
+			h := *holds[id]
+			return &h, nil
+		},
+		BeginTxFunc: func(ctx context.Context) (TransactionContext, error) { return txCtx, nil },
+	}
+}
+
+func TestHolds_Lifecycle(t *testing.T) {
+	zero := int64(0)
+	tests := []struct {
+		name         string
+		holdAmount   int64
+		action       func(s *LedgerService) (*Hold, error)
+		expectedErr  string
+		wantStatus   HoldStatus
+		wantPosted   int64
+		wantHeld     int64
+		wantMerchant int64
+		wantEvent    string
+	}{
+		{
+			name:       "Hold Reduces Available Balance Only",
+			holdAmount: 300,
+			wantStatus: HoldActive,
+			wantPosted: 1000,
+			wantHeld:   300,
+			wantEvent:  "hold.created",
+		},
+		{
+			name:        "Hold Exceeds Available Balance",
+			holdAmount:  1500,
+			expectedErr: "insufficient funds: account wallet available balance 1000 cannot be held by 1500 (limit 0)",
+		},
+		{
+			name:       "Partial Capture Releases Remainder",
+			holdAmount: 300,
+			action: func(s *LedgerService) (*Hold, error) {
+				return s.CaptureHold(context.Background(), "zone_123", "live", "hold_1", CaptureRequest{Amount: 200, ToAccountID: "merchant"})
+			},
+			wantStatus:   HoldCaptured,
+			wantPosted:   800,
+			wantHeld:     0,
+			wantMerchant: 200,
+			wantEvent:    "transaction.recorded",
+		},
+		{
+			name:       "Void Releases Hold",
+			holdAmount: 300,
+			action: func(s *LedgerService) (*Hold, error) {
+				return s.VoidHold(context.Background(), "zone_123", "live", "hold_1")
+			},
+			wantStatus: HoldVoided,
+			wantPosted: 1000,
+			wantHeld:   0,
+			wantEvent:  "hold.voided",
+		},
+		{
+			name:       "Capture After Void Rejected",
+			holdAmount: 300,
+			action: func(s *LedgerService) (*Hold, error) {
+				if _, err := s.VoidHold(context.Background(), "zone_123", "live", "hold_1"); err != nil {
+					return nil, err
+				}
+				return s.CaptureHold(context.Background(), "zone_123", "live", "hold_1", CaptureRequest{ToAccountID: "merchant"})
+			},
+			expectedErr: "hold hold_1 is voided",
+		},
+		{
+			name:       "Capture More Than Held Rejected",
+			holdAmount: 300,
+			action: func(s *LedgerService) (*Hold, error) {
+				return s.CaptureHold(context.Background(), "zone_123", "live", "hold_1", CaptureRequest{Amount: 301, ToAccountID: "merchant"})
+			},
+			expectedErr: "capture amount 301 must be between 1 and the held amount 300",
+		},
+		{
+			name:       "Hold On Other Zone's Account Rejected",
+			holdAmount: 300,
+			action: func(s *LedgerService) (*Hold, error) {
+				return s.CreateHold(context.Background(), HoldRequest{AccountID: "wallet", Amount: 100, ReferenceID: "auth_2"}, "zone_456", "live")
+			},
+			expectedErr: "account not found",
+		},
+		{
+			name:       "Void From Other Zone Rejected",
+			holdAmount: 300,
+			action: func(s *LedgerService) (*Hold, error) {
+				return s.VoidHold(context.Background(), "zone_456", "live", "hold_1")
+			},
+			expectedErr: "hold not found",
+		},
+		{
+			name:       "Capture To Other Zone's Account Rejected",
+			holdAmount: 300,
+			action: func(s *LedgerService) (*Hold, error) {
+				return s.CaptureHold(context.Background(), "zone_123", "live", "hold_1", CaptureRequest{ToAccountID: "other"})
+			},
+			expectedErr: "account not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]*Account{
+				"wallet":   {ID: "wallet", ZoneID: "zone_123", Mode: "live", Currency: "USD", Balance: 1000, OverdraftLimit: &zero},
+				"merchant": {ID: "merchant", ZoneID: "zone_123", Mode: "live", Currency: "USD"},
+				"other":    {ID: "other", ZoneID: "zone_456", Mode: "live", Currency: "USD"},
+			}
+			holds := make(map[string]*Hold)
+			var events []string
+			service := NewLedgerService(holdTestRepo(accounts, holds, &events), nil)
+
+			hold, err := service.CreateHold(context.Background(), HoldRequest{AccountID: "wallet", Amount: tt.holdAmount, ReferenceID: "auth_1"}, "zone_123", "live")
+			if err == nil && tt.action != nil {
+				hold, err = tt.action(service)
+			}
+
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("Expected error '%s', got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if hold.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, hold.Status)
+			}
+			acc, _ := service.GetAccount(context.Background(), "wallet")
+			if acc.PostedBalance != tt.wantPosted || acc.HeldAmount != tt.wantHeld || acc.AvailableBalance != tt.wantPosted-tt.wantHeld {
+				t.Errorf("Expected posted %d held %d, got %+v", tt.wantPosted, tt.wantHeld, acc)
+			}
+			if accounts["merchant"].Balance != tt.wantMerchant {
+				t.Errorf("Expected merchant balance %d, got %d", tt.wantMerchant, accounts["merchant"].Balance)
+			}
+			if events[len(events)-1] != tt.wantEvent {
+				t.Errorf("Expected last event %s, got %v", tt.wantEvent, events)
+			}
+		})
+	}
+}
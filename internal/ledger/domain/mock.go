@@ -2,16 +2,33 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type MockRepository struct {
-	CreateAccountFunc        func(ctx context.Context, acc *Account) error
-	GetAccountFunc           func(ctx context.Context, id string) (*Account, error)
-	BeginTxFunc              func(ctx context.Context) (TransactionContext, error)
-	GetUnprocessedEventsFunc func(ctx context.Context, limit int) ([]OutboxEvent, error)
-	MarkEventProcessedFunc   func(ctx context.Context, id string) error
-	ListTransactionsFunc     func(ctx context.Context, zoneID string, limit int) ([]TransactionWithEntries, error)
-	GetTransactionFunc       func(ctx context.Context, id string) (*TransactionWithEntries, error)
+	CreateAccountFunc             func(ctx context.Context, acc *Account) error
+	GetAccountFunc                func(ctx context.Context, id string) (*Account, error)
+	GetAccountByCodeFunc          func(ctx context.Context, zoneID, mode, code, currency string) (*Account, error)
+	GetUserAccountFunc            func(ctx context.Context, zoneID, mode, userID, currency string) (*Account, error)
+	ListAccountsFunc              func(ctx context.Context, zoneID, mode string) ([]Account, error)
+	UpdateOverdraftLimitFunc      func(ctx context.Context, id string, limit *int64) error
+	BeginTxFunc                   func(ctx context.Context) (TransactionContext, error)
+	ListTransactionsFunc          func(ctx context.Context, zoneID string, limit int) ([]TransactionWithEntries, error)
+	GetTransactionFunc            func(ctx context.Context, id string) (*TransactionWithEntries, error)
+	GetTransactionByReferenceFunc func(ctx context.Context, referenceID string) (*TransactionWithEntries, error)
+	GetReversedAmountsFunc        func(ctx context.Context, transactionID string) (map[string]int64, error)
+	GetBalanceAtFunc              func(ctx context.Context, accountID string, at time.Time) (int64, error)
+	ListAccountEntriesFunc        func(ctx context.Context, accountID string, from, to time.Time) ([]StatementLine, error)
+	StreamAccountEntriesFunc      func(ctx context.Context, accountID string, from, to time.Time, fn func(StatementLine) error) error
+	UpsertFXRateFunc              func(ctx context.Context, rate *FXRate) error
+	GetFXRateFunc                 func(ctx context.Context, zoneID, mode, base, quote string) (*FXRate, error)
+	ListFXRatesFunc               func(ctx context.Context, zoneID, mode string) ([]FXRate, error)
+	CreatePeriodFunc              func(ctx context.Context, period *AccountingPeriod) error
+	ListPeriodsFunc               func(ctx context.Context, zoneID, mode string) ([]AccountingPeriod, error)
+	GetPeriodSnapshotFunc         func(ctx context.Context, periodID string) (*PeriodSnapshot, error)
+	GetTrialBalanceFunc           func(ctx context.Context, zoneID, mode string, from, to time.Time) ([]TrialBalanceLine, error)
+	GetHoldFunc                   func(ctx context.Context, id string) (*Hold, error)
+	ListExpiredHoldsFunc          func(ctx context.Context, now time.Time, limit int) ([]Hold, error)
 }
 
 func (m *MockRepository) CreateAccount(ctx context.Context, acc *Account) error {
@@ -22,16 +39,24 @@ func (m *MockRepository) GetAccount(ctx context.Context, id string) (*Account, e
 	return m.GetAccountFunc(ctx, id)
 }
 
-func (m *MockRepository) BeginTx(ctx context.Context) (TransactionContext, error) {
-	return m.BeginTxFunc(ctx)
+func (m *MockRepository) GetAccountByCode(ctx context.Context, zoneID, mode, code, currency string) (*Account, error) {
+	return m.GetAccountByCodeFunc(ctx, zoneID, mode, code, currency)
+}
+
+func (m *MockRepository) GetUserAccount(ctx context.Context, zoneID, mode, userID, currency string) (*Account, error) {
+	return m.GetUserAccountFunc(ctx, zoneID, mode, userID, currency)
 }
 
-func (m *MockRepository) GetUnprocessedEvents(ctx context.Context, limit int) ([]OutboxEvent, error) {
-	return m.GetUnprocessedEventsFunc(ctx, limit)
+func (m *MockRepository) ListAccounts(ctx context.Context, zoneID, mode string) ([]Account, error) {
+	return m.ListAccountsFunc(ctx, zoneID, mode)
 }
 
-func (m *MockRepository) MarkEventProcessed(ctx context.Context, id string) error {
-	return m.MarkEventProcessedFunc(ctx, id)
+func (m *MockRepository) UpdateOverdraftLimit(ctx context.Context, id string, limit *int64) error {
+	return m.UpdateOverdraftLimitFunc(ctx, id, limit)
+}
+
+func (m *MockRepository) BeginTx(ctx context.Context) (TransactionContext, error) {
+	return m.BeginTxFunc(ctx)
 }
 
 func (m *MockRepository) ListTransactions(ctx context.Context, zoneID string, limit int) ([]TransactionWithEntries, error) {
@@ -42,13 +67,82 @@ func (m *MockRepository) GetTransaction(ctx context.Context, id string) (*Transa
 	return m.GetTransactionFunc(ctx, id)
 }
 
+func (m *MockRepository) GetTransactionByReference(ctx context.Context, referenceID string) (*TransactionWithEntries, error) {
+	return m.GetTransactionByReferenceFunc(ctx, referenceID)
+}
+
+func (m *MockRepository) GetReversedAmounts(ctx context.Context, transactionID string) (map[string]int64, error) {
+	return m.GetReversedAmountsFunc(ctx, transactionID)
+}
+
+func (m *MockRepository) GetBalanceAt(ctx context.Context, accountID string, at time.Time) (int64, error) {
+	return m.GetBalanceAtFunc(ctx, accountID, at)
+}
+
+func (m *MockRepository) ListAccountEntries(ctx context.Context, accountID string, from, to time.Time) ([]StatementLine, error) {
+	return m.ListAccountEntriesFunc(ctx, accountID, from, to)
+}
+
+func (m *MockRepository) StreamAccountEntries(ctx context.Context, accountID string, from, to time.Time, fn func(StatementLine) error) error {
+	return m.StreamAccountEntriesFunc(ctx, accountID, from, to, fn)
+}
+
+func (m *MockRepository) UpsertFXRate(ctx context.Context, rate *FXRate) error {
+	return m.UpsertFXRateFunc(ctx, rate)
+}
+
+func (m *MockRepository) GetFXRate(ctx context.Context, zoneID, mode, base, quote string) (*FXRate, error) {
+	return m.GetFXRateFunc(ctx, zoneID, mode, base, quote)
+}
+
+func (m *MockRepository) ListFXRates(ctx context.Context, zoneID, mode string) ([]FXRate, error) {
+	return m.ListFXRatesFunc(ctx, zoneID, mode)
+}
+
+func (m *MockRepository) CreatePeriod(ctx context.Context, period *AccountingPeriod) error {
+	return m.CreatePeriodFunc(ctx, period)
+}
+
+func (m *MockRepository) ListPeriods(ctx context.Context, zoneID, mode string) ([]AccountingPeriod, error) {
+	return m.ListPeriodsFunc(ctx, zoneID, mode)
+}
+
+func (m *MockRepository) GetPeriodSnapshot(ctx context.Context, periodID string) (*PeriodSnapshot, error) {
+	return m.GetPeriodSnapshotFunc(ctx, periodID)
+}
+
+func (m *MockRepository) GetTrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) ([]TrialBalanceLine, error) {
+	return m.GetTrialBalanceFunc(ctx, zoneID, mode, from, to)
+}
+
+func (m *MockRepository) GetHold(ctx context.Context, id string) (*Hold, error) {
+	return m.GetHoldFunc(ctx, id)
+}
+
+func (m *MockRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]Hold, error) {
+	return m.ListExpiredHoldsFunc(ctx, now, limit)
+}
+
 type MockTransactionContext struct {
-	CreateTransactionFunc func(ctx context.Context, tx *Transaction) (string, error)
-	CreateEntryFunc       func(ctx context.Context, entry *Entry) error
-	CheckIdempotencyFunc  func(ctx context.Context, referenceID string) (string, error)
-	CreateOutboxEventFunc func(ctx context.Context, eventType string, payload []byte) error
-	CommitFunc            func() error
-	RollbackFunc          func() error
+	CreateTransactionFunc    func(ctx context.Context, tx *Transaction) (string, error)
+	CreateEntryFunc          func(ctx context.Context, entry *Entry) error
+	GetAccountBalanceFunc    func(ctx context.Context, id string) (*Account, error)
+	UpdateAccountBalanceFunc func(ctx context.Context, id string, balance, held, expectedVersion int64) error
+	CreateFXConversionFunc   func(ctx context.Context, transactionID string, conv *FXConversion) error
+	CheckIdempotencyFunc     func(ctx context.Context, referenceID string) (string, error)
+	LockReversalsFunc        func(ctx context.Context, transactionID string) (map[string]int64, error)
+	CreateOutboxEventFunc    func(ctx context.Context, eventType, key string, payload []byte) error
+	GetPeriodForDateFunc     func(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error)
+	LockPeriodFunc           func(ctx context.Context, id string) (*AccountingPeriod, error)
+	UpdatePeriodFunc         func(ctx context.Context, period *AccountingPeriod) error
+	GetTrialBalanceFunc      func(ctx context.Context, zoneID, mode string, from, to time.Time) ([]TrialBalanceLine, error)
+	CreatePeriodSnapshotFunc func(ctx context.Context, snapshot *PeriodSnapshot) error
+	CreateHoldFunc           func(ctx context.Context, hold *Hold) error
+	GetHoldByReferenceFunc   func(ctx context.Context, referenceID string) (*Hold, error)
+	LockHoldFunc             func(ctx context.Context, id string) (*Hold, error)
+	UpdateHoldFunc           func(ctx context.Context, hold *Hold) error
+	CommitFunc               func() error
+	RollbackFunc             func() error
 }
 
 func (m *MockTransactionContext) CreateTransaction(ctx context.Context, tx *Transaction) (string, error) {
@@ -59,12 +153,64 @@ func (m *MockTransactionContext) CreateEntry(ctx context.Context, entry *Entry)
 	return m.CreateEntryFunc(ctx, entry)
 }
 
+func (m *MockTransactionContext) GetAccountBalance(ctx context.Context, id string) (*Account, error) {
+	return m.GetAccountBalanceFunc(ctx, id)
+}
+
+func (m *MockTransactionContext) UpdateAccountBalance(ctx context.Context, id string, balance, held, expectedVersion int64) error {
+	return m.UpdateAccountBalanceFunc(ctx, id, balance, held, expectedVersion)
+}
+
+func (m *MockTransactionContext) CreateFXConversion(ctx context.Context, transactionID string, conv *FXConversion) error {
+	return m.CreateFXConversionFunc(ctx, transactionID, conv)
+}
+
 func (m *MockTransactionContext) CheckIdempotency(ctx context.Context, referenceID string) (string, error) {
 	return m.CheckIdempotencyFunc(ctx, referenceID)
 }
 
-func (m *MockTransactionContext) CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) error {
-	return m.CreateOutboxEventFunc(ctx, eventType, payload)
+func (m *MockTransactionContext) LockReversals(ctx context.Context, transactionID string) (map[string]int64, error) {
+	return m.LockReversalsFunc(ctx, transactionID)
+}
+
+func (m *MockTransactionContext) CreateOutboxEvent(ctx context.Context, eventType, key string, payload []byte) error {
+	return m.CreateOutboxEventFunc(ctx, eventType, key, payload)
+}
+
+func (m *MockTransactionContext) GetPeriodForDate(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error) {
+	return m.GetPeriodForDateFunc(ctx, zoneID, mode, at)
+}
+
+func (m *MockTransactionContext) LockPeriod(ctx context.Context, id string) (*AccountingPeriod, error) {
+	return m.LockPeriodFunc(ctx, id)
+}
+
+func (m *MockTransactionContext) UpdatePeriod(ctx context.Context, period *AccountingPeriod) error {
+	return m.UpdatePeriodFunc(ctx, period)
+}
+
+func (m *MockTransactionContext) GetTrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) ([]TrialBalanceLine, error) {
+	return m.GetTrialBalanceFunc(ctx, zoneID, mode, from, to)
+}
+
+func (m *MockTransactionContext) CreatePeriodSnapshot(ctx context.Context, snapshot *PeriodSnapshot) error {
+	return m.CreatePeriodSnapshotFunc(ctx, snapshot)
+}
+
+func (m *MockTransactionContext) CreateHold(ctx context.Context, hold *Hold) error {
+	return m.CreateHoldFunc(ctx, hold)
+}
+
+func (m *MockTransactionContext) GetHoldByReference(ctx context.Context, referenceID string) (*Hold, error) {
+	return m.GetHoldByReferenceFunc(ctx, referenceID)
+}
+
+func (m *MockTransactionContext) LockHold(ctx context.Context, id string) (*Hold, error) {
+	return m.LockHoldFunc(ctx, id)
+}
+
+func (m *MockTransactionContext) UpdateHold(ctx context.Context, hold *Hold) error {
+	return m.UpdateHoldFunc(ctx, hold)
 }
 
 func (m *MockTransactionContext) Commit() error {
@@ -21,29 +21,40 @@ const (
 )
 
 type Account struct {
-	ID        string      `json:"id"`
-	ZoneID    string      `json:"zone_id"`
-	Mode      string      `json:"mode"`
-	Name      string      `json:"name"`
-	Type      AccountType `json:"type"`
-	Currency  string      `json:"currency"`
-	Balance   int64       `json:"balance"`
-	UserID    *string     `json:"user_id,omitempty"`
-	CreatedAt time.Time   `json:"created_at"`
+	ID               string      `json:"id"`
+	ZoneID           string      `json:"zone_id"`
+	Mode             string      `json:"mode"`
+	Name             string      `json:"name"`
+	Type             AccountType `json:"type"`
+	Currency         string      `json:"currency"`
+	Balance          int64       `json:"balance"`
+	HeldAmount       int64       `json:"held_amount"` // Sum of active holds
+	PostedBalance    int64       `json:"posted_balance"`
+	AvailableBalance int64       `json:"available_balance"` // Posted balance less active holds
+	Version          int64       `json:"version"`
+	OverdraftLimit   *int64      `json:"overdraft_limit,omitempty"` // nil means the balance is unrestricted
+	UserID           *string     `json:"user_id,omitempty"`         // Owner: a user or a connected account
+	Code             string      `json:"code,omitempty"`            // Chart of accounts code, e.g. "1000"
+	ParentID         *string     `json:"parent_id,omitempty"`       // Parent in the chart of accounts
+	CreatedAt        time.Time   `json:"created_at"`
 }
 
 type Transaction struct {
-	ID          string    `json:"id"`
-	ZoneID      string    `json:"zone_id"`
-	Mode        string    `json:"mode"`
-	ReferenceID string    `json:"reference_id"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID             string    `json:"id"`
+	ZoneID         string    `json:"zone_id"`
+	Mode           string    `json:"mode"`
+	ReferenceID    string    `json:"reference_id"`
+	Description    string    `json:"description"`
+	EffectiveAt    time.Time `json:"effective_at"`                      // Accounting date; drives periods and statements
+	ReversesID     *string   `json:"reverses_transaction_id,omitempty"` // Set on reversals, pointing at the original
+	ReversalReason string    `json:"reversal_reason,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type TransactionWithEntries struct {
 	Transaction
-	Entries []Entry `json:"entries"`
+	Entries     []Entry        `json:"entries"`
+	Conversions []FXConversion `json:"fx_conversions,omitempty"`
 }
 
 type Entry struct {
@@ -55,10 +66,26 @@ type Entry struct {
 	CreatedAt     time.Time       `json:"created_at"`
 }
 
+// AccountRequest describes a new account. Code and ParentID place it in the
+// zone's chart of accounts; a child must share its parent's type and currency.
+type AccountRequest struct {
+	Name     string      `json:"name"`
+	Type     AccountType `json:"type"`
+	Currency string      `json:"currency"`
+	UserID   *string     `json:"user_id"`
+	Code     string      `json:"code"`
+	ParentID *string     `json:"parent_id"`
+}
+
 type TransactionRequest struct {
 	ReferenceID string         `json:"reference_id"`
 	Description string         `json:"description"`
 	Entries     []EntryRequest `json:"entries"`
+	Conversions []FXConversion `json:"fx_conversions,omitempty"`
+	EffectiveAt *time.Time     `json:"effective_at,omitempty"` // Defaults to now
+
+	reversal *reversalLink // Set only by ReverseTransaction
+	capture  *holdCapture  // Set only by CaptureHold
 }
 
 type EntryRequest struct {
@@ -67,9 +94,60 @@ type EntryRequest struct {
 	Direction string `json:"direction"` // Optional, helpful for validation
 }
 
+// TrialBalanceLine holds an account's activity over a range and its balance
+// at the end of the range.
+type TrialBalanceLine struct {
+	AccountID   string      `json:"account_id"`
+	AccountName string      `json:"account_name"`
+	AccountType AccountType `json:"account_type"`
+	Currency    string      `json:"currency"`
+	Debits      int64       `json:"debits"`  // Sum of negative entries in the range, as a positive number
+	Credits     int64       `json:"credits"` // Sum of positive entries in the range
+	Balance     int64       `json:"balance"` // Cumulative balance at the end of the range
+}
+
+// FXConversion records a currency conversion performed inside a transaction.
+// The legs in each currency balance independently through FX clearing
+// accounts; the conversion links them and keeps the rate that was applied.
+type FXConversion struct {
+	FromCurrency  string    `json:"from_currency"`
+	ToCurrency    string    `json:"to_currency"`
+	FromAmount    int64     `json:"from_amount"`
+	ToAmount      int64     `json:"to_amount"`
+	Rate          float64   `json:"rate"` // Units of ToCurrency per unit of FromCurrency
+	RateSource    string    `json:"rate_source"`
+	RateTimestamp time.Time `json:"rate_timestamp"`
+}
+
+// FXRate is an entry in the ledger's FX rate table. Rates without a zone
+// are platform-wide and apply to every zone that has not set its own rate
+// for the pair.
+type FXRate struct {
+	ZoneID        string    `json:"zone_id,omitempty"`
+	Mode          string    `json:"mode,omitempty"`
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          float64   `json:"rate"`
+	Source        string    `json:"source"`
+	AsOf          time.Time `json:"as_of"`
+}
+
 type OutboxEvent struct {
 	ID        string    `json:"id"`
 	Type      string    `json:"type"`
+	Key       string    `json:"key"` // Partition key; events with the same key keep their order
 	Payload   []byte    `json:"payload"`
+	Attempts  int       `json:"attempts"` // Failed publish attempts so far
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// outboxKey is the partition key of an event. A zone's events share a key so
+// consumers see them in the order they were recorded; events recorded
+// without a zone are ordered per account. An event that cannot be published
+// holds back its zone until the relay dead-letters it.
+func outboxKey(zoneID, accountID string) string {
+	if zoneID != "" {
+		return "zone:" + zoneID
+	}
+	return "account:" + accountID
+}
@@ -0,0 +1,318 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/audit"
+)
+
+// ErrPeriodNotFound is returned for a period that does not exist or belongs
+// to another zone or mode than the caller's.
+var ErrPeriodNotFound = errors.New("accounting period not found")
+
+// ErrPeriodOverlap is returned by the repository when a new period overlaps
+// an existing one of the same zone and mode.
+var ErrPeriodOverlap = errors.New("accounting period overlaps an existing period")
+
+type PeriodStatus string
+
+const (
+	PeriodOpen   PeriodStatus = "open"
+	PeriodClosed PeriodStatus = "closed"
+)
+
+// AccountingPeriod is a date range [StartsAt, EndsAt) of a zone's books.
+// Once closed, no transaction effective inside it can be recorded.
+type AccountingPeriod struct {
+	ID           string       `json:"id"`
+	ZoneID       string       `json:"zone_id"`
+	Mode         string       `json:"mode"`
+	Name         string       `json:"name"`
+	StartsAt     time.Time    `json:"starts_at"`
+	EndsAt       time.Time    `json:"ends_at"`
+	Status       PeriodStatus `json:"status"`
+	ClosedAt     *time.Time   `json:"closed_at,omitempty"`
+	ClosedBy     string       `json:"closed_by,omitempty"`
+	ReopenedAt   *time.Time   `json:"reopened_at,omitempty"`
+	ReopenedBy   string       `json:"reopened_by,omitempty"`
+	ReopenReason string       `json:"reopen_reason,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// Contains reports whether t falls inside the period.
+func (p *AccountingPeriod) Contains(t time.Time) bool {
+	return !t.Before(p.StartsAt) && t.Before(p.EndsAt)
+}
+
+// PeriodSnapshot is the trial balance captured when a period was closed,
+// signed so later tampering with the stored figures can be detected.
+type PeriodSnapshot struct {
+	ID           string             `json:"id"`
+	PeriodID     string             `json:"period_id"`
+	ZoneID       string             `json:"zone_id"`
+	Mode         string             `json:"mode"`
+	StartsAt     time.Time          `json:"starts_at"`
+	EndsAt       time.Time          `json:"ends_at"`
+	TrialBalance []TrialBalanceLine `json:"trial_balance"`
+	ClosedBy     string             `json:"closed_by"`
+	ClosedAt     time.Time          `json:"closed_at"`
+	Signature    string             `json:"signature"`
+}
+
+// SetSigningKey configures the HMAC key used to sign period close snapshots.
+// Periods cannot be closed until a key is set.
+func (s *LedgerService) SetSigningKey(key []byte) {
+	s.signingKey = key
+}
+
+// CreatePeriod opens a new accounting period for a zone. Periods of the same
+// zone and mode may not overlap.
+func (s *LedgerService) CreatePeriod(ctx context.Context, zoneID, mode, name string, startsAt, endsAt time.Time) (*AccountingPeriod, error) {
+	if name == "" {
+		return nil, invalidf("period name is required")
+	}
+	if !startsAt.Before(endsAt) {
+		return nil, invalidf("period %s is empty: starts_at must be before ends_at", name)
+	}
+
+	existing, err := s.repo.ListPeriods(ctx, zoneID, mode)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range existing {
+		if startsAt.Before(p.EndsAt) && p.StartsAt.Before(endsAt) {
+			return nil, invalidf("period %s overlaps existing period %s", name, p.Name)
+		}
+	}
+
+	period := &AccountingPeriod{
+		ZoneID:   zoneID,
+		Mode:     mode,
+		Name:     name,
+		StartsAt: startsAt.UTC(),
+		EndsAt:   endsAt.UTC(),
+		Status:   PeriodOpen,
+	}
+	if err := s.repo.CreatePeriod(ctx, period); err != nil {
+		if errors.Is(err, ErrPeriodOverlap) {
+			return nil, invalidf("period %s overlaps an existing period", name)
+		}
+		return nil, err
+	}
+	return period, nil
+}
+
+func (s *LedgerService) ListPeriods(ctx context.Context, zoneID, mode string) ([]AccountingPeriod, error) {
+	return s.repo.ListPeriods(ctx, zoneID, mode)
+}
+
+// GetPeriodSnapshot returns the latest close snapshot of a period of the
+// zone, or nil if it has none.
+func (s *LedgerService) GetPeriodSnapshot(ctx context.Context, zoneID, mode, periodID string) (*PeriodSnapshot, error) {
+	snapshot, err := s.repo.GetPeriodSnapshot(ctx, periodID)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+	if snapshot.ZoneID != zoneID || snapshot.Mode != mode {
+		return nil, nil
+	}
+	return snapshot, nil
+}
+
+// lockZonePeriod locks a period of the zone for update. Periods of other
+// zones are reported as not found, like missing ones.
+func lockZonePeriod(ctx context.Context, txCtx TransactionContext, zoneID, mode, periodID string) (*AccountingPeriod, error) {
+	period, err := txCtx.LockPeriod(ctx, periodID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock period: %w", err)
+	}
+	if period == nil || period.ZoneID != zoneID || period.Mode != mode {
+		return nil, ErrPeriodNotFound
+	}
+	return period, nil
+}
+
+// ClosePeriod locks a period against new postings and stores a signed trial
+// balance of the zone as of the period end. The period row is locked for the
+// duration, so postings already in flight finish before the snapshot is taken.
+func (s *LedgerService) ClosePeriod(ctx context.Context, zoneID, mode, periodID, actorID string) (*PeriodSnapshot, error) {
+	if len(s.signingKey) == 0 {
+		return nil, errors.New("period close requires a signing key")
+	}
+
+	txCtx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = txCtx.Rollback() }()
+
+	period, err := lockZonePeriod(ctx, txCtx, zoneID, mode, periodID)
+	if err != nil {
+		return nil, err
+	}
+	if period.Status == PeriodClosed {
+		return nil, invalidf("period %s is already closed", period.Name)
+	}
+
+	lines, err := txCtx.GetTrialBalance(ctx, period.ZoneID, period.Mode, period.StartsAt, period.EndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute trial balance: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	snapshot := &PeriodSnapshot{
+		PeriodID:     period.ID,
+		ZoneID:       period.ZoneID,
+		Mode:         period.Mode,
+		StartsAt:     period.StartsAt,
+		EndsAt:       period.EndsAt,
+		TrialBalance: lines,
+		ClosedBy:     actorID,
+		ClosedAt:     now,
+	}
+	if snapshot.Signature, err = s.signSnapshot(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to sign snapshot: %w", err)
+	}
+
+	period.Status = PeriodClosed
+	period.ClosedAt = &now
+	period.ClosedBy = actorID
+	if err := txCtx.UpdatePeriod(ctx, period); err != nil {
+		return nil, fmt.Errorf("failed to update period: %w", err)
+	}
+	if err := txCtx.CreatePeriodSnapshot(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to store snapshot: %w", err)
+	}
+
+	eventData, _ := json.Marshal(map[string]interface{}{
+		"period_id": period.ID,
+		"name":      period.Name,
+		"starts_at": period.StartsAt,
+		"ends_at":   period.EndsAt,
+		"closed_by": actorID,
+		"zone_id":   period.ZoneID,
+		"mode":      period.Mode,
+	})
+	if err := txCtx.CreateOutboxEvent(ctx, "period.closed", outboxKey(period.ZoneID, ""), eventData); err != nil {
+		return nil, fmt.Errorf("failed to create outbox event: %w", err)
+	}
+
+	if err := txCtx.Commit(); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, audit.AuditLog{
+		ActorID:      actorID,
+		Action:       "ledger.period_closed",
+		ResourceType: "accounting_period",
+		ResourceID:   period.ID,
+		Metadata: map[string]interface{}{
+			"zone_id":   period.ZoneID,
+			"mode":      period.Mode,
+			"signature": snapshot.Signature,
+		},
+	})
+
+	return snapshot, nil
+}
+
+// ReopenPeriod makes a closed period writable again. It is a privileged
+// action: callers must authorize the actor, and a reason is mandatory and
+// recorded in the audit log. The close snapshot is kept for reference.
+func (s *LedgerService) ReopenPeriod(ctx context.Context, zoneID, mode, periodID, actorID, reason string) (*AccountingPeriod, error) {
+	if actorID == "" {
+		return nil, invalidf("reopening a period requires an identified actor")
+	}
+	if reason == "" {
+		return nil, invalidf("reopening a period requires a reason")
+	}
+
+	txCtx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = txCtx.Rollback() }()
+
+	period, err := lockZonePeriod(ctx, txCtx, zoneID, mode, periodID)
+	if err != nil {
+		return nil, err
+	}
+	if period.Status != PeriodClosed {
+		return nil, invalidf("period %s is not closed", period.Name)
+	}
+
+	now := time.Now().UTC()
+	period.Status = PeriodOpen
+	period.ReopenedAt = &now
+	period.ReopenedBy = actorID
+	period.ReopenReason = reason
+	if err := txCtx.UpdatePeriod(ctx, period); err != nil {
+		return nil, fmt.Errorf("failed to update period: %w", err)
+	}
+	if err := txCtx.Commit(); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, audit.AuditLog{
+		ActorID:      actorID,
+		Action:       "ledger.period_reopened",
+		ResourceType: "accounting_period",
+		ResourceID:   period.ID,
+		Metadata: map[string]interface{}{
+			"zone_id": period.ZoneID,
+			"mode":    period.Mode,
+			"reason":  reason,
+		},
+	})
+
+	return period, nil
+}
+
+// VerifyPeriodSnapshot checks a stored snapshot against its signature.
+func (s *LedgerService) VerifyPeriodSnapshot(snapshot *PeriodSnapshot) (bool, error) {
+	expected, err := s.signSnapshot(snapshot)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal([]byte(snapshot.Signature), []byte(expected)), nil
+}
+
+func (s *LedgerService) signSnapshot(snapshot *PeriodSnapshot) (string, error) {
+	// Timestamps are normalized to what survives a round trip through the
+	// database, so a reloaded snapshot verifies against the same bytes.
+	canonical := *snapshot
+	canonical.ID = ""
+	canonical.Signature = ""
+	canonical.StartsAt = snapshot.StartsAt.UTC().Truncate(time.Microsecond)
+	canonical.EndsAt = snapshot.EndsAt.UTC().Truncate(time.Microsecond)
+	canonical.ClosedAt = snapshot.ClosedAt.UTC().Truncate(time.Microsecond)
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// checkPeriodOpen rejects postings dated inside a closed period. It runs in
+// the posting transaction and takes a shared lock on the period row, so a
+// concurrent close waits for the posting to finish.
+func checkPeriodOpen(ctx context.Context, txCtx TransactionContext, zoneID, mode string, at time.Time) error {
+	period, err := txCtx.GetPeriodForDate(ctx, zoneID, mode, at)
+	if err != nil {
+		return fmt.Errorf("failed to check accounting period: %w", err)
+	}
+	if period != nil && period.Status == PeriodClosed {
+		return invalidf("accounting period %s is closed: post an adjusting entry dated in an open period", period.Name)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package domain
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// accountTypeOrder is the order in which account types appear on reports.
+var accountTypeOrder = map[AccountType]int{
+	Asset:     0,
+	Liability: 1,
+	Equity:    2,
+	Revenue:   3,
+	Expense:   4,
+}
+
+// isDebitNormal reports whether an account type normally carries a debit
+// balance. Entries are stored credit-positive, so these balances are negated
+// when presented.
+func isDebitNormal(t AccountType) bool {
+	return t == Asset || t == Expense
+}
+
+// TrialBalanceGroup holds the trial balance lines of one account type and
+// currency.
+type TrialBalanceGroup struct {
+	AccountType AccountType        `json:"account_type"`
+	Currency    string             `json:"currency"`
+	Lines       []TrialBalanceLine `json:"lines"`
+	Debits      int64              `json:"debits"`
+	Credits     int64              `json:"credits"`
+	Balance     int64              `json:"balance"`
+}
+
+// TrialBalanceTotal checks that debits equal credits within one currency.
+type TrialBalanceTotal struct {
+	Currency string `json:"currency"`
+	Debits   int64  `json:"debits"`
+	Credits  int64  `json:"credits"`
+	Balanced bool   `json:"balanced"`
+}
+
+// TrialBalance lists every account's activity over [From, To) and its
+// balance at To.
+type TrialBalance struct {
+	ZoneID string              `json:"zone_id"`
+	Mode   string              `json:"mode"`
+	From   time.Time           `json:"from"`
+	To     time.Time           `json:"to"`
+	Groups []TrialBalanceGroup `json:"groups"`
+	Totals []TrialBalanceTotal `json:"totals"`
+}
+
+// ReportLine is an account's amount on a balance sheet or income statement,
+// signed so that the account type's normal balance is positive.
+type ReportLine struct {
+	AccountID   string `json:"account_id"`
+	AccountName string `json:"account_name"`
+	Amount      int64  `json:"amount"`
+}
+
+// ReportSection groups report lines of one account type and currency.
+type ReportSection struct {
+	AccountType AccountType  `json:"account_type"`
+	Currency    string       `json:"currency"`
+	Lines       []ReportLine `json:"lines"`
+	Total       int64        `json:"total"`
+}
+
+// BalanceSheetTotal summarises a balance sheet in one currency. Retained
+// earnings is the net income of all revenue and expense accounts up to the
+// report date, which is what keeps assets equal to liabilities plus equity
+// before the books are closed into an equity account.
+type BalanceSheetTotal struct {
+	Currency         string `json:"currency"`
+	Assets           int64  `json:"assets"`
+	Liabilities      int64  `json:"liabilities"`
+	Equity           int64  `json:"equity"`
+	RetainedEarnings int64  `json:"retained_earnings"`
+	Balanced         bool   `json:"balanced"`
+}
+
+// BalanceSheet reports asset, liability and equity balances at AsOf.
+type BalanceSheet struct {
+	ZoneID   string              `json:"zone_id"`
+	Mode     string              `json:"mode"`
+	AsOf     time.Time           `json:"as_of"`
+	Sections []ReportSection     `json:"sections"`
+	Totals   []BalanceSheetTotal `json:"totals"`
+}
+
+// IncomeStatementTotal summarises an income statement in one currency.
+type IncomeStatementTotal struct {
+	Currency  string `json:"currency"`
+	Revenue   int64  `json:"revenue"`
+	Expenses  int64  `json:"expenses"`
+	NetIncome int64  `json:"net_income"`
+}
+
+// IncomeStatement reports revenue and expense activity over [From, To).
+type IncomeStatement struct {
+	ZoneID   string                 `json:"zone_id"`
+	Mode     string                 `json:"mode"`
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"`
+	Sections []ReportSection        `json:"sections"`
+	Totals   []IncomeStatementTotal `json:"totals"`
+}
+
+// ReportingService builds financial statements from the ledger.
+type ReportingService struct {
+	repo Repository
+}
+
+func NewReportingService(repo Repository) *ReportingService {
+	return &ReportingService{repo: repo}
+}
+
+// TrialBalance builds the trial balance of a zone and mode for [from, to).
+func (s *ReportingService) TrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) (*TrialBalance, error) {
+	if !from.Before(to) {
+		return nil, invalidf("report range is empty: from must be before to")
+	}
+
+	lines, err := s.repo.GetTrialBalance(ctx, zoneID, mode, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sortReportLines(lines)
+
+	tb := &TrialBalance{ZoneID: zoneID, Mode: mode, From: from, To: to, Groups: []TrialBalanceGroup{}, Totals: []TrialBalanceTotal{}}
+	totals := make(map[string]*TrialBalanceTotal)
+	var currencies []string
+	for _, l := range lines {
+		n := len(tb.Groups)
+		if n == 0 || tb.Groups[n-1].AccountType != l.AccountType || tb.Groups[n-1].Currency != l.Currency {
+			tb.Groups = append(tb.Groups, TrialBalanceGroup{AccountType: l.AccountType, Currency: l.Currency})
+			n++
+		}
+		g := &tb.Groups[n-1]
+		g.Lines = append(g.Lines, l)
+		g.Debits += l.Debits
+		g.Credits += l.Credits
+		g.Balance += l.Balance
+
+		t, ok := totals[l.Currency]
+		if !ok {
+			t = &TrialBalanceTotal{Currency: l.Currency}
+			totals[l.Currency] = t
+			currencies = append(currencies, l.Currency)
+		}
+		t.Debits += l.Debits
+		t.Credits += l.Credits
+	}
+
+	sort.Strings(currencies)
+	for _, cur := range currencies {
+		t := totals[cur]
+		t.Balanced = t.Debits == t.Credits
+		tb.Totals = append(tb.Totals, *t)
+	}
+	return tb, nil
+}
+
+// BalanceSheet builds the balance sheet of a zone and mode as of asOf,
+// including every entry effective strictly before it.
+func (s *ReportingService) BalanceSheet(ctx context.Context, zoneID, mode string, asOf time.Time) (*BalanceSheet, error) {
+	lines, err := s.repo.GetTrialBalance(ctx, zoneID, mode, asOf, asOf)
+	if err != nil {
+		return nil, err
+	}
+	sortReportLines(lines)
+
+	bs := &BalanceSheet{ZoneID: zoneID, Mode: mode, AsOf: asOf, Sections: []ReportSection{}, Totals: []BalanceSheetTotal{}}
+	totals := make(map[string]*BalanceSheetTotal)
+	var currencies []string
+	for _, l := range lines {
+		t, ok := totals[l.Currency]
+		if !ok {
+			t = &BalanceSheetTotal{Currency: l.Currency}
+			totals[l.Currency] = t
+			currencies = append(currencies, l.Currency)
+		}
+
+		amount := normalBalance(l.AccountType, l.Balance)
+		switch l.AccountType {
+		case Asset:
+			t.Assets += amount
+		case Liability:
+			t.Liabilities += amount
+		case Equity:
+			t.Equity += amount
+		default:
+			// Revenue is credit-normal and expense debit-normal, so the
+			// stored credit-positive balances sum to net income directly.
+			t.RetainedEarnings += l.Balance
+			continue
+		}
+		bs.Sections = appendReportLine(bs.Sections, l, amount)
+	}
+
+	sort.Strings(currencies)
+	for _, cur := range currencies {
+		t := totals[cur]
+		t.Balanced = t.Assets == t.Liabilities+t.Equity+t.RetainedEarnings
+		bs.Totals = append(bs.Totals, *t)
+	}
+	return bs, nil
+}
+
+// IncomeStatement builds the income statement of a zone and mode for
+// [from, to) from the activity on revenue and expense accounts.
+func (s *ReportingService) IncomeStatement(ctx context.Context, zoneID, mode string, from, to time.Time) (*IncomeStatement, error) {
+	if !from.Before(to) {
+		return nil, invalidf("report range is empty: from must be before to")
+	}
+
+	lines, err := s.repo.GetTrialBalance(ctx, zoneID, mode, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sortReportLines(lines)
+
+	is := &IncomeStatement{ZoneID: zoneID, Mode: mode, From: from, To: to, Sections: []ReportSection{}, Totals: []IncomeStatementTotal{}}
+	totals := make(map[string]*IncomeStatementTotal)
+	var currencies []string
+	for _, l := range lines {
+		if l.AccountType != Revenue && l.AccountType != Expense {
+			continue
+		}
+		t, ok := totals[l.Currency]
+		if !ok {
+			t = &IncomeStatementTotal{Currency: l.Currency}
+			totals[l.Currency] = t
+			currencies = append(currencies, l.Currency)
+		}
+
+		amount := normalBalance(l.AccountType, l.Credits-l.Debits)
+		if l.AccountType == Revenue {
+			t.Revenue += amount
+		} else {
+			t.Expenses += amount
+		}
+		is.Sections = appendReportLine(is.Sections, l, amount)
+	}
+
+	sort.Strings(currencies)
+	for _, cur := range currencies {
+		t := totals[cur]
+		t.NetIncome = t.Revenue - t.Expenses
+		is.Totals = append(is.Totals, *t)
+	}
+	return is, nil
+}
+
+// normalBalance converts a credit-positive amount to the account type's
+// normal sign.
+func normalBalance(t AccountType, amount int64) int64 {
+	if isDebitNormal(t) {
+		return -amount
+	}
+	return amount
+}
+
+// sortReportLines orders lines by account type, currency and account name so
+// that lines of the same group are adjacent.
+func sortReportLines(lines []TrialBalanceLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if accountTypeOrder[a.AccountType] != accountTypeOrder[b.AccountType] {
+			return accountTypeOrder[a.AccountType] < accountTypeOrder[b.AccountType]
+		}
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		return a.AccountName < b.AccountName
+	})
+}
+
+func appendReportLine(sections []ReportSection, l TrialBalanceLine, amount int64) []ReportSection {
+	n := len(sections)
+	if n == 0 || sections[n-1].AccountType != l.AccountType || sections[n-1].Currency != l.Currency {
+		sections = append(sections, ReportSection{AccountType: l.AccountType, Currency: l.Currency})
+		n++
+	}
+	sec := &sections[n-1]
+	sec.Lines = append(sec.Lines, ReportLine{AccountID: l.AccountID, AccountName: l.AccountName, Amount: amount})
+	sec.Total += amount
+	return sections
+}
+
+// WriteTrialBalanceCSV renders a trial balance as CSV with one row per
+// account, a subtotal row per group and a total row per currency.
+func WriteTrialBalanceCSV(w io.Writer, tb *TrialBalance) error {
+	rows := [][]string{{"account_type", "currency", "account_id", "account_name", "debits", "credits", "balance"}}
+	for _, g := range tb.Groups {
+		for _, l := range g.Lines {
+			rows = append(rows, []string{string(g.AccountType), g.Currency, l.AccountID, l.AccountName, itoa(l.Debits), itoa(l.Credits), itoa(l.Balance)})
+		}
+		rows = append(rows, []string{string(g.AccountType), g.Currency, "", "Subtotal", itoa(g.Debits), itoa(g.Credits), itoa(g.Balance)})
+	}
+	for _, t := range tb.Totals {
+		rows = append(rows, []string{"", t.Currency, "", "Total", itoa(t.Debits), itoa(t.Credits), ""})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteBalanceSheetCSV renders a balance sheet as CSV with one row per
+// account, a subtotal row per section and the per-currency totals.
+func WriteBalanceSheetCSV(w io.Writer, bs *BalanceSheet) error {
+	rows := reportSectionRows(bs.Sections)
+	for _, t := range bs.Totals {
+		rows = append(rows,
+			[]string{"", t.Currency, "", "Total assets", itoa(t.Assets)},
+			[]string{"", t.Currency, "", "Total liabilities", itoa(t.Liabilities)},
+			[]string{"", t.Currency, "", "Total equity", itoa(t.Equity)},
+			[]string{"", t.Currency, "", "Retained earnings", itoa(t.RetainedEarnings)},
+		)
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteIncomeStatementCSV renders an income statement as CSV with one row per
+// account, a subtotal row per section and net income per currency.
+func WriteIncomeStatementCSV(w io.Writer, is *IncomeStatement) error {
+	rows := reportSectionRows(is.Sections)
+	for _, t := range is.Totals {
+		rows = append(rows,
+			[]string{"", t.Currency, "", "Total revenue", itoa(t.Revenue)},
+			[]string{"", t.Currency, "", "Total expenses", itoa(t.Expenses)},
+			[]string{"", t.Currency, "", "Net income", itoa(t.NetIncome)},
+		)
+	}
+	return writeCSV(w, rows)
+}
+
+func reportSectionRows(sections []ReportSection) [][]string {
+	rows := [][]string{{"account_type", "currency", "account_id", "account_name", "amount"}}
+	for _, sec := range sections {
+		for _, l := range sec.Lines {
+			rows = append(rows, []string{string(sec.AccountType), sec.Currency, l.AccountID, l.AccountName, itoa(l.Amount)})
+		}
+		rows = append(rows, []string{string(sec.AccountType), sec.Currency, "", "Subtotal", itoa(sec.Total)})
+	}
+	return rows
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// GetPeriod finds an accounting period of a zone and mode, so reports can be
+// requested for a period instead of an explicit date range.
+func (s *ReportingService) GetPeriod(ctx context.Context, zoneID, mode, id string) (*AccountingPeriod, error) {
+	periods, err := s.repo.ListPeriods(ctx, zoneID, mode)
+	if err != nil {
+		return nil, err
+	}
+	for i := range periods {
+		if periods[i].ID == id {
+			return &periods[i], nil
+		}
+	}
+	return nil, invalidf("accounting period %s not found", id)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportingService_FinancialStatements(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Stored credit-positive: 1000 of customer funds held in cash, 300 of fee
+	// revenue of which 100 was spent on processing costs.
+	lines := []TrialBalanceLine{
+		{AccountID: "rev", AccountName: "Fees", AccountType: Revenue, Currency: "USD", Credits: 300, Balance: 300},
+		{AccountID: "cash", AccountName: "Cash", AccountType: Asset, Currency: "USD", Debits: 1300, Credits: 100, Balance: -1200},
+		{AccountID: "wallets", AccountName: "Wallets", AccountType: Liability, Currency: "USD", Credits: 1000, Balance: 1000},
+		{AccountID: "proc", AccountName: "Processing", AccountType: Expense, Currency: "USD", Debits: 100, Balance: -100},
+		{AccountID: "eur_cash", AccountName: "Cash", AccountType: Asset, Currency: "EUR", Balance: 0},
+	}
+	mockRepo := &MockRepository{
+		GetTrialBalanceFunc: func(ctx context.Context, zoneID, mode string, f, t time.Time) ([]TrialBalanceLine, error) {
+			out := make([]TrialBalanceLine, len(lines))
+			copy(out, lines)
+			return out, nil
+		},
+	}
+	service := NewReportingService(mockRepo)
+	ctx := context.Background()
+
+	tb, err := service.TrialBalance(ctx, "zone_123", "live", from, to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tb.Groups) != 5 || tb.Groups[0].AccountType != Asset || tb.Groups[0].Currency != "EUR" {
+		t.Fatalf("Expected 5 groups starting with EUR assets, got %+v", tb.Groups)
+	}
+	if len(tb.Totals) != 2 || tb.Totals[1].Currency != "USD" || !tb.Totals[1].Balanced || tb.Totals[1].Debits != 1400 {
+		t.Errorf("Expected balanced USD totals of 1400, got %+v", tb.Totals)
+	}
+
+	bs, err := service.BalanceSheet(ctx, "zone_123", "live", to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	usd := bs.Totals[1]
+	if usd.Assets != 1200 || usd.Liabilities != 1000 || usd.RetainedEarnings != 200 || !usd.Balanced {
+		t.Errorf("Unexpected balance sheet totals: %+v", usd)
+	}
+	for _, sec := range bs.Sections {
+		if sec.AccountType == Revenue || sec.AccountType == Expense {
+			t.Errorf("Balance sheet should not list %s accounts", sec.AccountType)
+		}
+	}
+
+	is, err := service.IncomeStatement(ctx, "zone_123", "live", from, to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(is.Totals) != 1 || is.Totals[0].Revenue != 300 || is.Totals[0].Expenses != 100 || is.Totals[0].NetIncome != 200 {
+		t.Errorf("Unexpected income statement totals: %+v", is.Totals)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteIncomeStatementCSV(&buf, is); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), ",USD,,Net income,200\n") {
+		t.Errorf("Expected net income row in CSV, got:\n%s", buf.String())
+	}
+
+	if _, err := service.TrialBalance(ctx, "zone_123", "live", to, from); !IsValidationError(err) {
+		t.Errorf("Expected validation error for empty range, got %v", err)
+	}
+}
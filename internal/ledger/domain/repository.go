@@ -2,23 +2,58 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
 	CreateAccount(ctx context.Context, acc *Account) error
 	GetAccount(ctx context.Context, id string) (*Account, error)
+	GetAccountByCode(ctx context.Context, zoneID, mode, code, currency string) (*Account, error)
+	GetUserAccount(ctx context.Context, zoneID, mode, userID, currency string) (*Account, error)
+	ListAccounts(ctx context.Context, zoneID, mode string) ([]Account, error)
+	UpdateOverdraftLimit(ctx context.Context, id string, limit *int64) error
 	BeginTx(ctx context.Context) (TransactionContext, error)
-	GetUnprocessedEvents(ctx context.Context, limit int) ([]OutboxEvent, error)
-	MarkEventProcessed(ctx context.Context, id string) error
 	ListTransactions(ctx context.Context, zoneID string, limit int) ([]TransactionWithEntries, error)
 	GetTransaction(ctx context.Context, id string) (*TransactionWithEntries, error)
+	GetTransactionByReference(ctx context.Context, referenceID string) (*TransactionWithEntries, error)
+	GetReversedAmounts(ctx context.Context, transactionID string) (map[string]int64, error)
+	GetBalanceAt(ctx context.Context, accountID string, at time.Time) (int64, error)
+	ListAccountEntries(ctx context.Context, accountID string, from, to time.Time) ([]StatementLine, error)
+	StreamAccountEntries(ctx context.Context, accountID string, from, to time.Time, fn func(StatementLine) error) error
+	UpsertFXRate(ctx context.Context, rate *FXRate) error
+	// GetFXRate returns the zone's rate for base/quote, or else the
+	// platform-wide one.
+	GetFXRate(ctx context.Context, zoneID, mode, base, quote string) (*FXRate, error)
+	// ListFXRates returns the zone's rates and the platform-wide ones.
+	ListFXRates(ctx context.Context, zoneID, mode string) ([]FXRate, error)
+	CreatePeriod(ctx context.Context, period *AccountingPeriod) error
+	ListPeriods(ctx context.Context, zoneID, mode string) ([]AccountingPeriod, error)
+	GetPeriodSnapshot(ctx context.Context, periodID string) (*PeriodSnapshot, error)
+	GetTrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) ([]TrialBalanceLine, error)
+	GetHold(ctx context.Context, id string) (*Hold, error)
+	ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]Hold, error)
 }
 
 type TransactionContext interface {
 	CreateTransaction(ctx context.Context, tx *Transaction) (string, error)
 	CreateEntry(ctx context.Context, entry *Entry) error
+	GetAccountBalance(ctx context.Context, id string) (*Account, error)
+	UpdateAccountBalance(ctx context.Context, id string, balance, held, expectedVersion int64) error
+	CreateFXConversion(ctx context.Context, transactionID string, conv *FXConversion) error
 	CheckIdempotency(ctx context.Context, referenceID string) (string, error)
-	CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) error
+	LockReversals(ctx context.Context, transactionID string) (map[string]int64, error)
+	// CreateOutboxEvent queues an event for publishing once the transaction
+	// commits. Events with the same key are published in order.
+	CreateOutboxEvent(ctx context.Context, eventType, key string, payload []byte) error
+	GetPeriodForDate(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error)
+	LockPeriod(ctx context.Context, id string) (*AccountingPeriod, error)
+	UpdatePeriod(ctx context.Context, period *AccountingPeriod) error
+	GetTrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) ([]TrialBalanceLine, error)
+	CreatePeriodSnapshot(ctx context.Context, snapshot *PeriodSnapshot) error
+	CreateHold(ctx context.Context, hold *Hold) error
+	GetHoldByReference(ctx context.Context, referenceID string) (*Hold, error)
+	LockHold(ctx context.Context, id string) (*Hold, error)
+	UpdateHold(ctx context.Context, hold *Hold) error
 	Commit() error
 	Rollback() error
 }
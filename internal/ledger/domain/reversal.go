@@ -0,0 +1,318 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// ErrAlreadyReversed is returned when a transaction has no unreversed amount
+// left.
+var ErrAlreadyReversed = errors.New("transaction has already been fully reversed")
+
+// ErrTransactionNotFound is returned for a transaction that does not exist
+// or belongs to another zone or mode than the caller's.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ReversalRequest describes a partial reversal. Entries are signed as they
+// will be posted, so each one must have the opposite sign of the original
+// entry on the same account and may not exceed what is left unreversed.
+// Reversals of multi-currency transactions also need the conversions that
+// link their legs.
+type ReversalRequest struct {
+	ReferenceID string         `json:"reference_id"`
+	Reason      string         `json:"reason"`
+	Entries     []EntryRequest `json:"entries"`
+	Conversions []FXConversion `json:"fx_conversions,omitempty"`
+
+	entriesFor entriesFunc // Set only by ReverseTransactionAmount
+}
+
+// entriesFunc derives a reversal's entries from the amounts already
+// reversed per account.
+type entriesFunc func(reversed map[string]int64) []EntryRequest
+
+// reversalLink ties a reversal to the transaction it reverses, so the amounts
+// can be re-checked once the original is locked.
+type reversalLink struct {
+	originalID string
+	reason     string
+	original   map[string]int64 // Net amount per account on the original
+	entriesFor entriesFunc      // Re-derives the entries under the lock, if set
+}
+
+// ReverseTransaction posts a mirror of whatever is left unreversed on a
+// transaction of the zone, dated now. The original is never modified; the
+// reversal links back to it, and a transaction can only be fully reversed
+// once.
+func (s *LedgerService) ReverseTransaction(ctx context.Context, zoneID, mode, transactionID, reason string) (string, error) {
+	return s.reverse(ctx, zoneID, mode, transactionID, ReversalRequest{Reason: reason}, true)
+}
+
+// ReverseTransactionPartial reverses part of a transaction. Several partial
+// reversals may be posted as long as together they do not exceed the
+// original amounts. A request whose reference ID was already used for a
+// reversal of the same transaction returns that reversal.
+func (s *LedgerService) ReverseTransactionPartial(ctx context.Context, zoneID, mode, transactionID string, req ReversalRequest) (string, error) {
+	if req.ReferenceID == "" {
+		return "", invalidf("reference_id is required for partial reversals")
+	}
+	if len(req.Entries) == 0 {
+		return "", invalidf("partial reversals require entries")
+	}
+	return s.reverse(ctx, zoneID, mode, transactionID, req, false)
+}
+
+// ReverseTransactionAmount partially reverses amount of a transaction in
+// one currency, such as a payment posting. The entries are derived from the
+// original, so callers need not know its accounts. When a side of the
+// original spans several accounts, as a marketplace payment split between
+// the platform's fee and the connected account does, amount is shared
+// between them in proportion to their original amounts. The shares depend
+// on what earlier reversals took, so they are computed again once the
+// original is locked. Reference IDs make it idempotent as for
+// ReverseTransactionPartial.
+func (s *LedgerService) ReverseTransactionAmount(ctx context.Context, zoneID, mode, transactionID string, req ReversalRequest, amount int64) (string, error) {
+	if req.ReferenceID == "" {
+		return "", invalidf("reference_id is required for partial reversals")
+	}
+	if amount <= 0 {
+		return "", invalidf("reversal amount must be positive")
+	}
+	orig, err := s.repo.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction %s: %w", transactionID, err)
+	}
+	if orig == nil || orig.ZoneID != zoneID || orig.Mode != mode {
+		return "", fmt.Errorf("transaction %s: %w", transactionID, ErrTransactionNotFound)
+	}
+	if len(orig.Conversions) > 0 {
+		return "", invalidf("transaction %s spans currencies: reverse it with entries and fx conversions", transactionID)
+	}
+
+	net := make(map[string]int64)
+	var accounts []string
+	for _, e := range orig.Entries {
+		if _, ok := net[e.AccountID]; !ok {
+			accounts = append(accounts, e.AccountID)
+		}
+		net[e.AccountID] += e.Amount
+	}
+	var credited, debited []string
+	for _, acc := range accounts {
+		if net[acc] > 0 {
+			credited = append(credited, acc)
+		} else if net[acc] < 0 {
+			debited = append(debited, acc)
+		}
+	}
+	if len(credited) == 0 || len(debited) == 0 {
+		return "", invalidf("transaction %s moves no money between accounts: reverse it with entries", transactionID)
+	}
+
+	req.entriesFor = func(reversed map[string]int64) []EntryRequest {
+		var entries []EntryRequest
+		for _, side := range [][]string{credited, debited} {
+			for acc, amt := range share(side, net, reversed, amount) {
+				if amt != 0 {
+					entries = append(entries, EntryRequest{AccountID: acc, Amount: amt, Direction: directionOf(amt)})
+				}
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].AccountID < entries[j].AccountID })
+		return entries
+	}
+	return s.reverse(ctx, zoneID, mode, transactionID, req, false)
+}
+
+// share splits a reversal of amount over the accounts of one side of a
+// transaction in proportion to their original amounts, signed to reverse
+// them. Shares are taken of the total reversed so far, so a series of
+// partial reversals adds up to the original amounts exactly; the largest
+// account takes what the smaller ones round down.
+func share(accounts []string, original, reversed map[string]int64, amount int64) map[string]int64 {
+	var total, done int64
+	largest := accounts[0]
+	for _, acc := range accounts {
+		total += abs(original[acc])
+		done += abs(reversed[acc])
+		if abs(original[acc]) > abs(original[largest]) {
+			largest = acc
+		}
+	}
+	target := done + amount
+
+	shares := make(map[string]int64, len(accounts))
+	rest := target
+	for _, acc := range accounts {
+		if acc == largest {
+			continue
+		}
+		cumulative := mulDiv(abs(original[acc]), target, total)
+		rest -= cumulative
+		shares[acc] = cumulative - abs(reversed[acc])
+	}
+	shares[largest] = rest - abs(reversed[largest])
+
+	for acc, amt := range shares {
+		if original[acc] > 0 {
+			shares[acc] = -amt
+		}
+	}
+	return shares
+}
+
+// mulDiv returns a*b/c rounded down without overflowing on the product.
+// a must not exceed c, which keeps the quotient within b.
+func mulDiv(a, b, c int64) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	q, _ := bits.Div64(hi, lo, uint64(c))
+	return int64(q)
+}
+
+func (s *LedgerService) reverse(ctx context.Context, zoneID, mode, transactionID string, req ReversalRequest, full bool) (string, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return "", invalidf("a reversal reason is required")
+	}
+
+	orig, err := s.repo.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction %s: %w", transactionID, err)
+	}
+	if orig == nil || orig.ZoneID != zoneID || orig.Mode != mode {
+		return "", fmt.Errorf("transaction %s: %w", transactionID, ErrTransactionNotFound)
+	}
+	if orig.ReversesID != nil {
+		return "", invalidf("transaction %s is a reversal and cannot itself be reversed", transactionID)
+	}
+
+	if !full {
+		prev, err := s.repo.GetTransactionByReference(ctx, req.ReferenceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to check reversal reference: %w", err)
+		}
+		if prev != nil {
+			if prev.ReversesID == nil || *prev.ReversesID != transactionID {
+				return "", invalidf("reference %s is already used by transaction %s", req.ReferenceID, prev.ID)
+			}
+			return prev.ID, nil
+		}
+	}
+
+	original := make(map[string]int64)
+	for _, e := range orig.Entries {
+		original[e.AccountID] += e.Amount
+	}
+	reversed, err := s.repo.GetReversedAmounts(ctx, transactionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get reversals of transaction %s: %w", transactionID, err)
+	}
+
+	entries := req.Entries
+	if req.entriesFor != nil {
+		entries = req.entriesFor(reversed)
+	}
+	conversions := req.Conversions
+	if full {
+		seen := make(map[string]bool)
+		for _, e := range orig.Entries {
+			if seen[e.AccountID] {
+				continue
+			}
+			seen[e.AccountID] = true
+			if remaining := original[e.AccountID] + reversed[e.AccountID]; remaining != 0 {
+				entries = append(entries, EntryRequest{AccountID: e.AccountID, Amount: -remaining, Direction: directionOf(-remaining)})
+			}
+		}
+		if len(entries) == 0 {
+			return "", fmt.Errorf("transaction %s: %w", transactionID, ErrAlreadyReversed)
+		}
+
+		if len(orig.Conversions) > 0 {
+			if len(reversed) > 0 {
+				return "", invalidf("transaction %s is partially reversed: reverse the remaining multi-currency amounts with entries and fx conversions", transactionID)
+			}
+			for _, c := range orig.Conversions {
+				conversions = append(conversions, FXConversion{
+					FromCurrency:  c.ToCurrency,
+					ToCurrency:    c.FromCurrency,
+					FromAmount:    c.ToAmount,
+					ToAmount:      c.FromAmount,
+					Rate:          float64(c.FromAmount) / float64(c.ToAmount),
+					RateSource:    "reversal",
+					RateTimestamp: c.RateTimestamp,
+				})
+			}
+		}
+		req.ReferenceID = "reversal_" + transactionID
+	} else if _, err := checkReversal(transactionID, original, reversed, entries); err != nil {
+		return "", err
+	}
+
+	return s.recordTransaction(ctx, TransactionRequest{
+		ReferenceID: req.ReferenceID,
+		Description: fmt.Sprintf("Reversal of %s: %s", transactionID, req.Reason),
+		Entries:     entries,
+		Conversions: conversions,
+		reversal: &reversalLink{
+			originalID: transactionID,
+			reason:     req.Reason,
+			original:   original,
+			entriesFor: req.entriesFor,
+		},
+	}, orig.ZoneID, orig.Mode)
+}
+
+// checkReversal verifies that entries only reverse what is left of the
+// original amounts, given the amounts already reversed per account. It
+// reports whether the original is fully reversed afterwards.
+func checkReversal(transactionID string, original, reversed map[string]int64, entries []EntryRequest) (bool, error) {
+	amounts := make(map[string]int64)
+	for _, e := range entries {
+		if _, ok := original[e.AccountID]; !ok {
+			return false, invalidf("account %s has no entries on transaction %s", e.AccountID, transactionID)
+		}
+		amounts[e.AccountID] += e.Amount
+	}
+
+	// Check accounts in a fixed order so the reported error is stable
+	accounts := make([]string, 0, len(original))
+	for acc := range original {
+		accounts = append(accounts, acc)
+	}
+	sort.Strings(accounts)
+
+	full := true
+	for _, acc := range accounts {
+		remaining := original[acc] + reversed[acc]
+		amt := amounts[acc]
+		if amt != 0 && (remaining == 0 || (amt > 0) == (remaining > 0) || abs(amt) > abs(remaining)) {
+			if remaining == 0 && reversed[acc] != 0 {
+				return false, fmt.Errorf("account %s on transaction %s: %w", acc, transactionID, ErrAlreadyReversed)
+			}
+			return false, invalidf("reversal of %d on account %s exceeds the unreversed amount %d of transaction %s", amt, acc, -remaining, transactionID)
+		}
+		if remaining+amt != 0 {
+			full = false
+		}
+	}
+	return full, nil
+}
+
+func directionOf(amount int64) string {
+	if amount < 0 {
+		return string(Debit)
+	}
+	return string(Credit)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
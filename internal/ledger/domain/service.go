@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type Metrics interface {
@@ -12,8 +14,9 @@ type Metrics interface {
 }
 
 type LedgerService struct {
-	repo    Repository
-	metrics Metrics
+	repo       Repository
+	metrics    Metrics
+	signingKey []byte
 }
 
 func NewLedgerService(repo Repository, metrics Metrics) *LedgerService {
@@ -23,28 +26,78 @@ func NewLedgerService(repo Repository, metrics Metrics) *LedgerService {
 	}
 }
 
-func (s *LedgerService) CreateAccount(ctx context.Context, name string, accType AccountType, currency string, userID *string, zoneID, mode string) (*Account, error) {
+func (s *LedgerService) CreateAccount(ctx context.Context, req AccountRequest, zoneID, mode string) (*Account, error) {
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Code != "" {
+		existing, err := s.repo.GetAccountByCode(ctx, zoneID, mode, req.Code, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, invalidf("account code %s is already used by account %s", req.Code, existing.ID)
+		}
+	}
+	if req.ParentID != nil {
+		parent, err := s.repo.GetAccount(ctx, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkParent(parent, *req.ParentID, req, zoneID, mode); err != nil {
+			return nil, err
+		}
+	}
+
 	acc := &Account{
-		Name:     name,
-		Type:     accType,
-		Currency: currency,
-		UserID:   userID,
-		ZoneID:   zoneID,
-		Mode:     mode,
+		Name:           req.Name,
+		Type:           req.Type,
+		Currency:       req.Currency,
+		OverdraftLimit: defaultOverdraftLimit(req.Type),
+		UserID:         req.UserID,
+		Code:           req.Code,
+		ParentID:       req.ParentID,
+		ZoneID:         zoneID,
+		Mode:           mode,
 	}
 	err := s.repo.CreateAccount(ctx, acc)
 	if err != nil {
 		return nil, err
 	}
 	acc.Balance = 0
+	acc.setBalances()
 	return acc, nil
 }
 
 func (s *LedgerService) GetAccount(ctx context.Context, id string) (*Account, error) {
-	return s.repo.GetAccount(ctx, id)
+	acc, err := s.repo.GetAccount(ctx, id)
+	if err != nil || acc == nil {
+		return acc, err
+	}
+	acc.setBalances()
+	return acc, nil
 }
 
-func (s *LedgerService) RecordTransaction(ctx context.Context, req TransactionRequest, zoneID, mode string) (err error) {
+// zoneAccount returns an account of the zone and mode, or
+// ErrAccountNotFound if it has none with the ID.
+func (s *LedgerService) zoneAccount(ctx context.Context, zoneID, mode, id string) (*Account, error) {
+	acc, err := s.repo.GetAccount(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account %s: %w", id, err)
+	}
+	if acc == nil || acc.ZoneID != zoneID || acc.Mode != mode {
+		return nil, ErrAccountNotFound
+	}
+	return acc, nil
+}
+
+func (s *LedgerService) RecordTransaction(ctx context.Context, req TransactionRequest, zoneID, mode string) error {
+	_, err := s.recordTransaction(ctx, req, zoneID, mode)
+	return err
+}
+
+// recordTransaction validates and posts a transaction, returning its ID. When
+// the reference ID was already recorded, the existing transaction's ID is
+// returned instead.
+func (s *LedgerService) recordTransaction(ctx context.Context, req TransactionRequest, zoneID, mode string) (id string, err error) {
 	defer func() {
 		if s.metrics != nil {
 			if err != nil {
@@ -56,59 +109,125 @@ func (s *LedgerService) RecordTransaction(ctx context.Context, req TransactionRe
 	}()
 
 	// 1. Validate Balance (Sum of amounts must be 0)
+	// Cross-currency transactions balance per currency instead, checked below.
 	var sum int64
 	for _, e := range req.Entries {
 		sum += e.Amount
 	}
-	if sum != 0 {
-		return errors.New("transaction is not balanced (sum != 0)")
+	if sum != 0 && len(req.Conversions) == 0 {
+		return "", invalidf("transaction is not balanced (sum != 0)")
 	}
 
 	// 2. Validate Currency Consistency
-	var commonCurrency string
+	sums := make(map[string]int64)
+	clearing := make(map[string]int64)
+	var currencies []string
 	for _, e := range req.Entries {
 		acc, err := s.repo.GetAccount(ctx, e.AccountID)
 		if err != nil {
-			return fmt.Errorf("failed to get account %s for currency check: %w", e.AccountID, err)
+			return "", fmt.Errorf("failed to get account %s for currency check: %w", e.AccountID, err)
 		}
 		if acc == nil {
-			return fmt.Errorf("account %s not found", e.AccountID)
+			return "", invalidf("account %s not found", e.AccountID)
 		}
 
-		if commonCurrency == "" {
-			commonCurrency = acc.Currency
-		} else if commonCurrency != acc.Currency {
-			return fmt.Errorf("multi-currency transactions not supported: account %s has currency %s, expected %s", e.AccountID, acc.Currency, commonCurrency)
+		if _, ok := sums[acc.Currency]; !ok {
+			currencies = append(currencies, acc.Currency)
+		}
+		sums[acc.Currency] += e.Amount
+		if acc.Code == CodeFXClearing {
+			clearing[acc.Currency] += e.Amount
 		}
 	}
 
+	if len(currencies) > 1 && len(req.Conversions) == 0 {
+		return "", invalidf("multi-currency transactions require fx conversions: found currencies %v", currencies)
+	}
+	for _, cur := range currencies {
+		if sums[cur] != 0 {
+			return "", invalidf("transaction is not balanced in %s (sum = %d)", cur, sums[cur])
+		}
+	}
+	if err := s.resolveConversions(ctx, zoneID, mode, req.Conversions, currencies, clearing); err != nil {
+		return "", err
+	}
+
+	// Balances are updated with optimistic locking, so a concurrent posting to
+	// the same account makes the whole transaction retry from a fresh read.
+	for attempt := 1; ; attempt++ {
+		id, err = s.postTransaction(ctx, req, zoneID, mode)
+		if !errors.Is(err, ErrVersionConflict) || attempt >= maxBalanceRetries {
+			return id, err
+		}
+	}
+}
+
+// postTransaction writes a validated transaction, its entries and the
+// resulting balance changes in a single database transaction.
+func (s *LedgerService) postTransaction(ctx context.Context, req TransactionRequest, zoneID, mode string) (string, error) {
 	txCtx, err := s.repo.BeginTx(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer func() { _ = txCtx.Rollback() }()
 
 	// 3. Check for existing transaction (Idempotency)
 	existingID, err := txCtx.CheckIdempotency(ctx, req.ReferenceID)
 	if err != nil {
-		return fmt.Errorf("failed to check idempotency: %w", err)
+		return "", fmt.Errorf("failed to check idempotency: %w", err)
 	}
 	if existingID != "" {
-		return nil // Already exists
+		return existingID, nil // Already exists
 	}
 
-	// 4. Insert Transaction Record
-	transactionID, err := txCtx.CreateTransaction(ctx, &Transaction{
+	// 4. Reject postings into closed accounting periods
+	effectiveAt := time.Now().UTC()
+	if req.EffectiveAt != nil {
+		effectiveAt = req.EffectiveAt.UTC()
+	}
+	if err := checkPeriodOpen(ctx, txCtx, zoneID, mode, effectiveAt); err != nil {
+		return "", err
+	}
+
+	tx := &Transaction{
 		ReferenceID: req.ReferenceID,
 		Description: req.Description,
 		ZoneID:      zoneID,
 		Mode:        mode,
-	})
+		EffectiveAt: effectiveAt,
+	}
+	eventType := "transaction.recorded"
+	eventData := map[string]interface{}{}
+
+	// 5. Re-check reversals under a lock on the original transaction
+	if rev := req.reversal; rev != nil {
+		reversed, err := txCtx.LockReversals(ctx, rev.originalID)
+		if err != nil {
+			return "", fmt.Errorf("failed to lock transaction %s for reversal: %w", rev.originalID, err)
+		}
+		if rev.entriesFor != nil {
+			// Another reversal may have posted since the entries were derived
+			req.Entries = rev.entriesFor(reversed)
+		}
+		full, err := checkReversal(rev.originalID, rev.original, reversed, req.Entries)
+		if err != nil {
+			return "", err
+		}
+		tx.ReversesID = &rev.originalID
+		tx.ReversalReason = rev.reason
+		eventType = "transaction.reversed"
+		eventData["reverses_transaction_id"] = rev.originalID
+		eventData["reason"] = rev.reason
+		eventData["partial"] = !full
+	}
+
+	// 6. Insert Transaction Record
+	transactionID, err := txCtx.CreateTransaction(ctx, tx)
 	if err != nil {
-		return fmt.Errorf("failed to create transaction: %w", err)
+		return "", fmt.Errorf("failed to create transaction: %w", err)
 	}
 
-	// 5. Insert Entries
+	// 7. Insert Entries
 	for _, e := range req.Entries {
 		err := txCtx.CreateEntry(ctx, &Entry{
 			TransactionID: transactionID,
@@ -117,26 +236,53 @@ func (s *LedgerService) RecordTransaction(ctx context.Context, req TransactionRe
 			Direction:     TransactionType(e.Direction),
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create entry for account %s: %w", e.AccountID, err)
+			return "", fmt.Errorf("failed to create entry for account %s: %w", e.AccountID, err)
 		}
 	}
 
-	// 6. Insert Outbox Event
-	eventData, _ := json.Marshal(map[string]interface{}{
-		"id":           transactionID,
-		"reference_id": req.ReferenceID,
-		"description":  req.Description,
-		"entries":      req.Entries,
-		"zone_id":      zoneID,
-		"mode":         mode,
-	})
-	err = txCtx.CreateOutboxEvent(ctx, "transaction.recorded", eventData)
+	// 8. Apply Balance Changes, releasing a captured hold
+	releases := make(map[string]int64)
+	if req.capture != nil {
+		hold, err := captureHold(ctx, txCtx, req.capture, transactionID)
+		if err != nil {
+			return "", err
+		}
+		releases[hold.AccountID] = hold.Amount
+		eventData["hold_id"] = hold.ID
+	}
+	if err := s.applyBalanceChanges(ctx, txCtx, req.Entries, releases); err != nil {
+		return "", err
+	}
+
+	// 9. Record FX Conversions
+	for i := range req.Conversions {
+		if err := txCtx.CreateFXConversion(ctx, transactionID, &req.Conversions[i]); err != nil {
+			return "", fmt.Errorf("failed to record fx conversion: %w", err)
+		}
+	}
+
+	// 10. Insert Outbox Event
+	eventData["id"] = transactionID
+	eventData["reference_id"] = req.ReferenceID
+	eventData["description"] = req.Description
+	eventData["entries"] = req.Entries
+	eventData["fx_conversions"] = req.Conversions
+	eventData["effective_at"] = effectiveAt
+	eventData["zone_id"] = zoneID
+	eventData["mode"] = mode
+	payload, _ := json.Marshal(eventData)
+	var keyAccount string
+	if len(req.Entries) > 0 {
+		keyAccount = req.Entries[0].AccountID
+	}
+	err = txCtx.CreateOutboxEvent(ctx, eventType, outboxKey(zoneID, keyAccount), payload)
 	if err != nil {
-		return fmt.Errorf("failed to create outbox event: %w", err)
+		return "", fmt.Errorf("failed to create outbox event: %w", err)
 	}
 
-	return txCtx.Commit()
+	return transactionID, txCtx.Commit()
 }
+
 func (s *LedgerService) BulkRecordTransactions(ctx context.Context, requests []TransactionRequest, zoneID, mode string) ([]error, error) {
 	errs := make([]error, len(requests))
 	for i, req := range requests {
@@ -155,3 +301,7 @@ func (s *LedgerService) ListTransactions(ctx context.Context, zoneID string, lim
 func (s *LedgerService) GetTransaction(ctx context.Context, id string) (*TransactionWithEntries, error) {
 	return s.repo.GetTransaction(ctx, id)
 }
+
+func (s *LedgerService) GetTransactionByReference(ctx context.Context, referenceID string) (*TransactionWithEntries, error) {
+	return s.repo.GetTransactionByReference(ctx, referenceID)
+}
@@ -2,8 +2,10 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestRecordTransaction_TableDriven(t *testing.T) {
@@ -96,7 +98,7 @@ func TestCreateAccount_TableDriven(t *testing.T) {
 			tt.mockSetup(mockRepo)
 			service := NewLedgerService(mockRepo, nil)
 
-			acc, err := service.CreateAccount(context.Background(), tt.accName, Asset, "USD", nil, "zone_123", "test")
+			acc, err := service.CreateAccount(context.Background(), AccountRequest{Name: tt.accName, Type: Asset, Currency: "USD"}, "zone_123", "test")
 			if tt.expectedErr {
 				if err == nil {
 					t.Error("Expected error, got nil")
@@ -112,3 +114,761 @@ func TestCreateAccount_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordTransaction_MultiCurrency(t *testing.T) {
+	accounts := map[string]*Account{
+		"cash_eur":     {ID: "cash_eur", Currency: "EUR"},
+		"clearing_eur": {ID: "clearing_eur", Code: CodeFXClearing, Currency: "EUR"},
+		"clearing_usd": {ID: "clearing_usd", Code: CodeFXClearing, Currency: "USD"},
+		"merchant_usd": {ID: "merchant_usd", Currency: "USD"},
+	}
+	entries := []EntryRequest{
+		{AccountID: "cash_eur", Amount: 1000},
+		{AccountID: "clearing_eur", Amount: -1000},
+		{AccountID: "clearing_usd", Amount: 1100},
+		{AccountID: "merchant_usd", Amount: -1100},
+	}
+
+	tests := []struct {
+		name        string
+		conversions []FXConversion
+		rates       map[string]*FXRate
+		expectedErr string
+		expectRate  float64
+	}{
+		{
+			name:        "Missing Conversion",
+			expectedErr: "multi-currency transactions require fx conversions: found currencies [EUR USD]",
+		},
+		{
+			name:        "Rate From Table",
+			conversions: []FXConversion{{FromCurrency: "EUR", ToCurrency: "USD", FromAmount: 1000, ToAmount: 1100}},
+			rates:       map[string]*FXRate{"EUR/USD": {BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.1, Source: "ecb"}},
+			expectRate:  1.1,
+		},
+		{
+			name:        "Inverse Rate From Table",
+			conversions: []FXConversion{{FromCurrency: "EUR", ToCurrency: "USD", FromAmount: 1000, ToAmount: 1100}},
+			rates:       map[string]*FXRate{"USD/EUR": {BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 1 / 1.1, Source: "ecb"}},
+			expectRate:  1.1,
+		},
+		{
+			name:        "Rate Mismatch",
+			conversions: []FXConversion{{FromCurrency: "EUR", ToCurrency: "USD", FromAmount: 1000, ToAmount: 1100, Rate: 1.2}},
+			expectedErr: "fx conversion EUR->USD does not match rate 1.2: expected 1200, got 1100",
+		},
+		{
+			name:        "No Rate Available",
+			conversions: []FXConversion{{FromCurrency: "EUR", ToCurrency: "USD", FromAmount: 1000, ToAmount: 1100}},
+			expectedErr: "no fx rate for EUR/USD",
+		},
+		{
+			name:        "Amounts Differ From Clearing Legs",
+			conversions: []FXConversion{{FromCurrency: "EUR", ToCurrency: "USD", FromAmount: 500, ToAmount: 550, Rate: 1.1}},
+			expectedErr: "fx conversions net -500 EUR on fx clearing, but the transaction posts -1000",
+		},
+		{
+			name: "Currency Not Posted",
+			conversions: []FXConversion{
+				{FromCurrency: "EUR", ToCurrency: "USD", FromAmount: 1000, ToAmount: 1100, Rate: 1.1},
+				{FromCurrency: "USD", ToCurrency: "GBP", FromAmount: 1000, ToAmount: 800, Rate: 0.8},
+				{FromCurrency: "GBP", ToCurrency: "USD", FromAmount: 800, ToAmount: 1000, Rate: 1.25},
+			},
+			expectedErr: "fx conversion currency GBP is not posted by the transaction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded []FXConversion
+			mockRepo := &MockRepository{
+				GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+					return accounts[id], nil
+				},
+				GetFXRateFunc: func(ctx context.Context, zoneID, mode, base, quote string) (*FXRate, error) {
+					return tt.rates[base+"/"+quote], nil
+				},
+				BeginTxFunc: func(ctx context.Context) (TransactionContext, error) {
+					return &MockTransactionContext{
+						CheckIdempotencyFunc: func(ctx context.Context, referenceID string) (string, error) { return "", nil },
+						GetPeriodForDateFunc: func(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error) {
+							return nil, nil
+						},
+						CreateTransactionFunc: func(ctx context.Context, tx *Transaction) (string, error) { return "tx_1", nil },
+						CreateEntryFunc:       func(ctx context.Context, entry *Entry) error { return nil },
+						GetAccountBalanceFunc: func(ctx context.Context, id string) (*Account, error) {
+							return accounts[id], nil
+						},
+						UpdateAccountBalanceFunc: func(ctx context.Context, id string, balance, held, expectedVersion int64) error {
+							return nil
+						},
+						CreateFXConversionFunc: func(ctx context.Context, transactionID string, conv *FXConversion) error {
+							recorded = append(recorded, *conv)
+							return nil
+						},
+						CreateOutboxEventFunc: func(ctx context.Context, eventType, key string, payload []byte) error { return nil },
+						CommitFunc:            func() error { return nil },
+						RollbackFunc:          func() error { return nil },
+					}, nil
+				},
+			}
+			service := NewLedgerService(mockRepo, nil)
+
+			req := TransactionRequest{ReferenceID: "ref_fx", Entries: entries, Conversions: tt.conversions}
+			err := service.RecordTransaction(context.Background(), req, "zone_123", "test")
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("Expected error '%s', got '%v'", tt.expectedErr, err)
+				}
+				if !IsValidationError(err) {
+					t.Errorf("Expected a validation error, got %T", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(recorded) != 1 {
+				t.Fatalf("Expected 1 recorded conversion, got %d", len(recorded))
+			}
+			if diff := recorded[0].Rate - tt.expectRate; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Expected rate %g, got %g", tt.expectRate, recorded[0].Rate)
+			}
+			if recorded[0].RateSource != "ecb" {
+				t.Errorf("Expected rate source ecb, got %s", recorded[0].RateSource)
+			}
+		})
+	}
+}
+
+func TestRecordTransaction_BalanceEnforcement(t *testing.T) {
+	zero := int64(0)
+	overdraft := int64(500)
+
+	tests := []struct {
+		name          string
+		wallet        Account
+		amount        int64
+		conflicts     int
+		expectedErr   string
+		expectBalance int64
+		expectCommits int
+	}{
+		{
+			name:          "Sufficient Funds",
+			wallet:        Account{ID: "wallet", Balance: 1000, OverdraftLimit: &zero},
+			amount:        400,
+			expectBalance: 600,
+			expectCommits: 1,
+		},
+		{
+			name:        "Liability Cannot Go Negative",
+			wallet:      Account{ID: "wallet", Balance: 100, OverdraftLimit: &zero},
+			amount:      400,
+			expectedErr: "insufficient funds: account wallet available balance 100 cannot be debited by 400 (limit 0)",
+		},
+		{
+			name:          "Within Overdraft Limit",
+			wallet:        Account{ID: "wallet", Balance: 100, OverdraftLimit: &overdraft},
+			amount:        400,
+			expectBalance: -300,
+			expectCommits: 1,
+		},
+		{
+			name:          "Unrestricted Account",
+			wallet:        Account{ID: "wallet", Balance: 0},
+			amount:        400,
+			expectBalance: -400,
+			expectCommits: 1,
+		},
+		{
+			name:          "Retries On Version Conflict",
+			wallet:        Account{ID: "wallet", Balance: 1000, OverdraftLimit: &zero},
+			amount:        400,
+			conflicts:     2,
+			expectBalance: 600,
+			expectCommits: 1,
+		},
+		{
+			name:        "Gives Up After Repeated Conflicts",
+			wallet:      Account{ID: "wallet", Balance: 1000, OverdraftLimit: &zero},
+			amount:      400,
+			conflicts:   maxBalanceRetries,
+			expectedErr: ErrVersionConflict.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := map[string]*Account{
+				"wallet": &tt.wallet,
+				"float":  {ID: "float"},
+			}
+			balances := map[string]int64{}
+			conflicts, commits := tt.conflicts, 0
+
+			mockRepo := &MockRepository{
+				GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+					return accounts[id], nil
+				},
+				BeginTxFunc: func(ctx context.Context) (TransactionContext, error) {
+					return &MockTransactionContext{
+						CheckIdempotencyFunc: func(ctx context.Context, referenceID string) (string, error) { return "", nil },
+						GetPeriodForDateFunc: func(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error) {
+							return nil, nil
+						},
+						CreateTransactionFunc: func(ctx context.Context, tx *Transaction) (string, error) { return "tx_1", nil },
+						CreateEntryFunc:       func(ctx context.Context, entry *Entry) error { return nil },
+						GetAccountBalanceFunc: func(ctx context.Context, id string) (*Account, error) {
+							return accounts[id], nil
+						},
+						UpdateAccountBalanceFunc: func(ctx context.Context, id string, balance, held, expectedVersion int64) error {
+							if id == "wallet" && conflicts > 0 {
+								conflicts--
+								return ErrVersionConflict
+							}
+							balances[id] = balance
+							return nil
+						},
+						CreateOutboxEventFunc: func(ctx context.Context, eventType, key string, payload []byte) error { return nil },
+						CommitFunc: func() error {
+							commits++
+							return nil
+						},
+						RollbackFunc: func() error { return nil },
+					}, nil
+				},
+			}
+			service := NewLedgerService(mockRepo, nil)
+
+			req := TransactionRequest{
+				ReferenceID: "ref_debit",
+				Entries: []EntryRequest{
+					{AccountID: "wallet", Amount: -tt.amount},
+					{AccountID: "float", Amount: tt.amount},
+				},
+			}
+			err := service.RecordTransaction(context.Background(), req, "zone_123", "test")
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("Expected error '%s', got '%v'", tt.expectedErr, err)
+				}
+				if commits != 0 {
+					t.Errorf("Expected no commit, got %d", commits)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if commits != tt.expectCommits {
+				t.Errorf("Expected %d commits, got %d", tt.expectCommits, commits)
+			}
+			if balances["wallet"] != tt.expectBalance {
+				t.Errorf("Expected wallet balance %d, got %d", tt.expectBalance, balances["wallet"])
+			}
+		})
+	}
+}
+
+func TestGetStatement_RunningBalance(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo := &MockRepository{
+		GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+			return &Account{ID: id, Name: "Merchant", Currency: "USD"}, nil
+		},
+		GetBalanceAtFunc: func(ctx context.Context, accountID string, at time.Time) (int64, error) {
+			if !at.Equal(from) {
+				t.Errorf("Expected opening balance at %s, got %s", from, at)
+			}
+			return 1000, nil
+		},
+		ListAccountEntriesFunc: func(ctx context.Context, accountID string, f, tt time.Time) ([]StatementLine, error) {
+			return []StatementLine{
+				{EntryID: "e1", Amount: 500},
+				{EntryID: "e2", Amount: -200},
+				{EntryID: "e3", Amount: 50},
+			}, nil
+		},
+	}
+	service := NewLedgerService(mockRepo, nil)
+
+	st, err := service.GetStatement(context.Background(), "acc_1", from, to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if st.OpeningBalance != 1000 || st.ClosingBalance != 1350 {
+		t.Errorf("Expected opening 1000 and closing 1350, got %d and %d", st.OpeningBalance, st.ClosingBalance)
+	}
+	expected := []int64{1500, 1300, 1350}
+	for i, l := range st.Lines {
+		if l.RunningBalance != expected[i] {
+			t.Errorf("Line %d: expected running balance %d, got %d", i, expected[i], l.RunningBalance)
+		}
+	}
+
+	if _, err := service.GetStatement(context.Background(), "acc_1", to, from); err == nil {
+		t.Error("Expected error for inverted range, got nil")
+	}
+}
+
+func TestRecordTransaction_ClosedPeriod(t *testing.T) {
+	closed := &AccountingPeriod{ID: "p_1", Name: "2026-01", Status: PeriodClosed}
+	committed := false
+
+	mockRepo := &MockRepository{
+		GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+			return &Account{ID: id, Currency: "USD"}, nil
+		},
+		BeginTxFunc: func(ctx context.Context) (TransactionContext, error) {
+			return &MockTransactionContext{
+				CheckIdempotencyFunc: func(ctx context.Context, referenceID string) (string, error) { return "", nil },
+				GetPeriodForDateFunc: func(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error) {
+					return closed, nil
+				},
+				CommitFunc: func() error {
+					committed = true
+					return nil
+				},
+				RollbackFunc: func() error { return nil },
+			}, nil
+		},
+	}
+	service := NewLedgerService(mockRepo, nil)
+
+	effectiveAt := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	req := TransactionRequest{
+		ReferenceID: "ref_late",
+		EffectiveAt: &effectiveAt,
+		Entries: []EntryRequest{
+			{AccountID: "acc_1", Amount: 100},
+			{AccountID: "acc_2", Amount: -100},
+		},
+	}
+	err := service.RecordTransaction(context.Background(), req, "zone_123", "test")
+	expected := "accounting period 2026-01 is closed: post an adjusting entry dated in an open period"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error '%s', got '%v'", expected, err)
+	}
+	if committed {
+		t.Error("Expected transaction not to be committed")
+	}
+}
+
+func TestCreatePeriod_Overlap(t *testing.T) {
+	existing := AccountingPeriod{
+		Name:     "2026-01",
+		StartsAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var listed []AccountingPeriod
+	mockRepo := &MockRepository{
+		ListPeriodsFunc: func(ctx context.Context, zoneID, mode string) ([]AccountingPeriod, error) {
+			return listed, nil
+		},
+		// A concurrent create committed between the check and the insert.
+		CreatePeriodFunc: func(ctx context.Context, period *AccountingPeriod) error {
+			return ErrPeriodOverlap
+		},
+	}
+	service := NewLedgerService(mockRepo, nil)
+
+	for _, l := range [][]AccountingPeriod{{existing}, nil} {
+		listed = l
+		_, err := service.CreatePeriod(context.Background(), "zone_123", "live", "Jan",
+			time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC))
+		if !IsValidationError(err) {
+			t.Errorf("Expected overlap validation error with %d listed periods, got %v", len(l), err)
+		}
+	}
+}
+
+func TestClosePeriod_SignedSnapshot(t *testing.T) {
+	period := &AccountingPeriod{
+		ID:       "p_1",
+		Name:     "2026-01",
+		ZoneID:   "zone_123",
+		Mode:     "live",
+		StartsAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Status:   PeriodOpen,
+	}
+	var stored *PeriodSnapshot
+	var events []string
+
+	mockRepo := &MockRepository{
+		BeginTxFunc: func(ctx context.Context) (TransactionContext, error) {
+			return &MockTransactionContext{
+				LockPeriodFunc: func(ctx context.Context, id string) (*AccountingPeriod, error) {
+					p := *period
+					return &p, nil
+				},
+				GetTrialBalanceFunc: func(ctx context.Context, zoneID, mode string, from, to time.Time) ([]TrialBalanceLine, error) {
+					return []TrialBalanceLine{
+						{AccountID: "cash", AccountType: Asset, Currency: "USD", Debits: 0, Credits: 500, Balance: 500},
+						{AccountID: "wallet", AccountType: Liability, Currency: "USD", Debits: 500, Credits: 0, Balance: -500},
+					}, nil
+				},
+				UpdatePeriodFunc: func(ctx context.Context, p *AccountingPeriod) error {
+					period = p
+					return nil
+				},
+				CreatePeriodSnapshotFunc: func(ctx context.Context, snapshot *PeriodSnapshot) error {
+					stored = snapshot
+					return nil
+				},
+				CreateOutboxEventFunc: func(ctx context.Context, eventType, key string, payload []byte) error {
+					events = append(events, eventType)
+					return nil
+				},
+				CommitFunc:   func() error { return nil },
+				RollbackFunc: func() error { return nil },
+			}, nil
+		},
+	}
+	service := NewLedgerService(mockRepo, nil)
+
+	if _, err := service.ClosePeriod(context.Background(), "zone_123", "live", "p_1", "user_1"); err == nil {
+		t.Fatal("Expected close without signing key to fail")
+	}
+
+	service.SetSigningKey([]byte("test-signing-key"))
+	snapshot, err := service.ClosePeriod(context.Background(), "zone_123", "live", "p_1", "user_1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if period.Status != PeriodClosed || period.ClosedBy != "user_1" {
+		t.Errorf("Expected period closed by user_1, got %s by %s", period.Status, period.ClosedBy)
+	}
+	if stored != snapshot || snapshot.Signature == "" {
+		t.Fatal("Expected signed snapshot to be stored")
+	}
+	if len(events) != 1 || events[0] != "period.closed" {
+		t.Errorf("Expected period.closed event, got %v", events)
+	}
+
+	if valid, _ := service.VerifyPeriodSnapshot(snapshot); !valid {
+		t.Error("Expected snapshot signature to verify")
+	}
+	snapshot.TrialBalance[0].Balance = 600
+	if valid, _ := service.VerifyPeriodSnapshot(snapshot); valid {
+		t.Error("Expected tampered snapshot to fail verification")
+	}
+
+	if _, err := service.ReopenPeriod(context.Background(), "zone_456", "live", "p_1", "user_1", "late vendor invoice"); !errors.Is(err, ErrPeriodNotFound) {
+		t.Errorf("Expected reopen from another zone to fail with ErrPeriodNotFound, got %v", err)
+	}
+	if _, err := service.ReopenPeriod(context.Background(), "zone_123", "live", "p_1", "user_1", ""); err == nil {
+		t.Error("Expected reopen without reason to fail")
+	}
+	reopened, err := service.ReopenPeriod(context.Background(), "zone_123", "live", "p_1", "user_1", "late vendor invoice")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reopened.Status != PeriodOpen || reopened.ReopenReason != "late vendor invoice" {
+		t.Errorf("Expected reopened period with reason, got %+v", reopened)
+	}
+}
+
+func TestReverseTransaction(t *testing.T) {
+	original := &TransactionWithEntries{
+		Transaction: Transaction{ID: "tx_1", ReferenceID: "pi_1", ZoneID: "zone_123", Mode: "live"},
+		Entries: []Entry{
+			{AccountID: "user_1", Amount: 1000, Direction: Credit},
+			{AccountID: "system_balancing", Amount: -1000, Direction: Debit},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		zoneID      string // Defaults to the original's zone
+		reversed    map[string]int64
+		partial     *ReversalRequest
+		amount      int64
+		expectedErr string
+		expectedIs  error
+		wantEntries map[string]int64
+		wantPartial bool
+	}{
+		{
+			name:        "Full Reversal",
+			reversed:    map[string]int64{},
+			wantEntries: map[string]int64{"user_1": -1000, "system_balancing": 1000},
+		},
+		{
+			name:        "Full Reversal After Partial",
+			reversed:    map[string]int64{"user_1": -400, "system_balancing": 400},
+			wantEntries: map[string]int64{"user_1": -600, "system_balancing": 600},
+		},
+		{
+			name:       "Other Zone",
+			zoneID:     "zone_456",
+			reversed:   map[string]int64{},
+			expectedIs: ErrTransactionNotFound,
+		},
+		{
+			name:       "Other Zone Amount Reversal",
+			zoneID:     "zone_456",
+			reversed:   map[string]int64{},
+			partial:    &ReversalRequest{ReferenceID: "refund_6", Reason: "refund"},
+			amount:     300,
+			expectedIs: ErrTransactionNotFound,
+		},
+		{
+			name:       "Already Reversed",
+			reversed:   map[string]int64{"user_1": -1000, "system_balancing": 1000},
+			expectedIs: ErrAlreadyReversed,
+		},
+		{
+			name:     "Partial Reversal",
+			reversed: map[string]int64{},
+			partial: &ReversalRequest{ReferenceID: "refund_1", Reason: "refund", Entries: []EntryRequest{
+				{AccountID: "user_1", Amount: -250},
+				{AccountID: "system_balancing", Amount: 250},
+			}},
+			wantEntries: map[string]int64{"user_1": -250, "system_balancing": 250},
+			wantPartial: true,
+		},
+		{
+			name:     "Partial Reversal Exceeds Remainder",
+			reversed: map[string]int64{"user_1": -900, "system_balancing": 900},
+			partial: &ReversalRequest{ReferenceID: "refund_2", Reason: "refund", Entries: []EntryRequest{
+				{AccountID: "user_1", Amount: -250},
+				{AccountID: "system_balancing", Amount: 250},
+			}},
+			expectedErr: "reversal of 250 on account system_balancing exceeds the unreversed amount 100 of transaction tx_1",
+		},
+		{
+			name:     "Partial Reversal Unbalanced",
+			reversed: map[string]int64{},
+			partial: &ReversalRequest{ReferenceID: "refund_3", Reason: "refund", Entries: []EntryRequest{
+				{AccountID: "user_1", Amount: -250},
+				{AccountID: "system_balancing", Amount: 200},
+			}},
+			expectedErr: "transaction is not balanced (sum != 0)",
+		},
+		{
+			name:        "Amount Reversal",
+			reversed:    map[string]int64{"user_1": -250, "system_balancing": 250},
+			partial:     &ReversalRequest{ReferenceID: "refund_4", Reason: "refund"},
+			amount:      300,
+			wantEntries: map[string]int64{"user_1": -300, "system_balancing": 300},
+			wantPartial: true,
+		},
+		{
+			name:        "Amount Reversal Exceeds Remainder",
+			reversed:    map[string]int64{"user_1": -900, "system_balancing": 900},
+			partial:     &ReversalRequest{ReferenceID: "refund_5", Reason: "refund"},
+			amount:      300,
+			expectedErr: "reversal of 300 on account system_balancing exceeds the unreversed amount 100 of transaction tx_1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted *Transaction
+			entries := make(map[string]int64)
+			var eventType string
+			var payload map[string]interface{}
+
+			mockRepo := &MockRepository{
+				GetTransactionFunc: func(ctx context.Context, id string) (*TransactionWithEntries, error) {
+					return original, nil
+				},
+				GetTransactionByReferenceFunc: func(ctx context.Context, referenceID string) (*TransactionWithEntries, error) {
+					return nil, nil
+				},
+				GetReversedAmountsFunc: func(ctx context.Context, transactionID string) (map[string]int64, error) {
+					return tt.reversed, nil
+				},
+				GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+					return &Account{ID: id, Currency: "USD"}, nil
+				},
+				BeginTxFunc: func(ctx context.Context) (TransactionContext, error) {
+					return &MockTransactionContext{
+						CheckIdempotencyFunc: func(ctx context.Context, referenceID string) (string, error) { return "", nil },
+						GetPeriodForDateFunc: func(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error) {
+							return nil, nil
+						},
+						LockReversalsFunc: func(ctx context.Context, transactionID string) (map[string]int64, error) {
+							return tt.reversed, nil
+						},
+						CreateTransactionFunc: func(ctx context.Context, tx *Transaction) (string, error) {
+							posted = tx
+							return "tx_rev", nil
+						},
+						CreateEntryFunc: func(ctx context.Context, entry *Entry) error {
+							entries[entry.AccountID] += entry.Amount
+							return nil
+						},
+						GetAccountBalanceFunc: func(ctx context.Context, id string) (*Account, error) {
+							return &Account{ID: id}, nil
+						},
+						UpdateAccountBalanceFunc: func(ctx context.Context, id string, balance, held, expectedVersion int64) error { return nil },
+						CreateOutboxEventFunc: func(ctx context.Context, et, key string, data []byte) error {
+							eventType = et
+							return json.Unmarshal(data, &payload)
+						},
+						CommitFunc:   func() error { return nil },
+						RollbackFunc: func() error { return nil },
+					}, nil
+				},
+			}
+			service := NewLedgerService(mockRepo, nil)
+
+			zoneID := tt.zoneID
+			if zoneID == "" {
+				zoneID = "zone_123"
+			}
+			var id string
+			var err error
+			if tt.amount != 0 {
+				id, err = service.ReverseTransactionAmount(context.Background(), zoneID, "live", "tx_1", *tt.partial, tt.amount)
+			} else if tt.partial != nil {
+				id, err = service.ReverseTransactionPartial(context.Background(), zoneID, "live", "tx_1", *tt.partial)
+			} else {
+				id, err = service.ReverseTransaction(context.Background(), zoneID, "live", "tx_1", "operator correction")
+			}
+
+			if tt.expectedIs != nil {
+				if !errors.Is(err, tt.expectedIs) {
+					t.Fatalf("Expected %v, got %v", tt.expectedIs, err)
+				}
+				return
+			}
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("Expected error '%s', got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if id != "tx_rev" || posted.ReversesID == nil || *posted.ReversesID != "tx_1" {
+				t.Errorf("Expected reversal tx_rev linked to tx_1, got %s linked to %v", id, posted.ReversesID)
+			}
+			for acc, amt := range tt.wantEntries {
+				if entries[acc] != amt {
+					t.Errorf("Expected %d on %s, got %d", amt, acc, entries[acc])
+				}
+			}
+			if eventType != "transaction.reversed" || payload["partial"] != tt.wantPartial {
+				t.Errorf("Expected transaction.reversed event with partial=%v, got %s %v", tt.wantPartial, eventType, payload["partial"])
+			}
+		})
+	}
+}
+
+func TestReverseTransactionAmount_SplitShares(t *testing.T) {
+	// A marketplace payment of 10000: 1000 fee to the platform, 9000 to the
+	// connected account.
+	original := map[string]int64{"clearing": -10000, "fee_revenue": 1000, "connected": 9000}
+	credited := []string{"fee_revenue", "connected"}
+
+	tests := []struct {
+		name     string
+		reversed map[string]int64
+		amount   int64
+		want     map[string]int64
+	}{
+		{
+			name:     "Quarter Refund",
+			reversed: map[string]int64{},
+			amount:   2500,
+			want:     map[string]int64{"fee_revenue": -250, "connected": -2250},
+		},
+		{
+			name:     "Fee Rounds Down",
+			reversed: map[string]int64{},
+			amount:   15,
+			want:     map[string]int64{"fee_revenue": -1, "connected": -14},
+		},
+		{
+			name:     "Rest After Rounded Refunds",
+			reversed: map[string]int64{"fee_revenue": -1, "connected": -14, "clearing": 15},
+			amount:   9985,
+			want:     map[string]int64{"fee_revenue": -999, "connected": -8986},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := share(credited, original, tt.reversed, tt.amount)
+			for acc, amt := range tt.want {
+				if got[acc] != amt {
+					t.Errorf("Expected %d on %s, got %d", amt, acc, got[acc])
+				}
+			}
+			if debit := share([]string{"clearing"}, original, map[string]int64{}, tt.amount); debit["clearing"] != tt.amount {
+				t.Errorf("Expected %d back on clearing, got %d", tt.amount, debit["clearing"])
+			}
+		})
+	}
+}
+
+func TestReverseTransactionAmount_SharesUnderLock(t *testing.T) {
+	original := &TransactionWithEntries{
+		Transaction: Transaction{ID: "tx_1", ReferenceID: "pi_1", ZoneID: "zone_123", Mode: "live"},
+		Entries: []Entry{
+			{AccountID: "clearing", Amount: -10000, Direction: Debit},
+			{AccountID: "fee_revenue", Amount: 1000, Direction: Credit},
+			{AccountID: "connected", Amount: 9000, Direction: Credit},
+		},
+	}
+	entries := make(map[string]int64)
+
+	mockRepo := &MockRepository{
+		GetTransactionFunc: func(ctx context.Context, id string) (*TransactionWithEntries, error) {
+			return original, nil
+		},
+		GetTransactionByReferenceFunc: func(ctx context.Context, referenceID string) (*TransactionWithEntries, error) {
+			return nil, nil
+		},
+		// A concurrent refund posts between this read and the lock
+		GetReversedAmountsFunc: func(ctx context.Context, transactionID string) (map[string]int64, error) {
+			return map[string]int64{}, nil
+		},
+		GetAccountFunc: func(ctx context.Context, id string) (*Account, error) {
+			return &Account{ID: id, Currency: "USD"}, nil
+		},
+		BeginTxFunc: func(ctx context.Context) (TransactionContext, error) {
+			return &MockTransactionContext{
+				CheckIdempotencyFunc: func(ctx context.Context, referenceID string) (string, error) { return "", nil },
+				GetPeriodForDateFunc: func(ctx context.Context, zoneID, mode string, at time.Time) (*AccountingPeriod, error) {
+					return nil, nil
+				},
+				LockReversalsFunc: func(ctx context.Context, transactionID string) (map[string]int64, error) {
+					return map[string]int64{"fee_revenue": -1, "connected": -14, "clearing": 15}, nil
+				},
+				CreateTransactionFunc: func(ctx context.Context, tx *Transaction) (string, error) { return "tx_rev", nil },
+				CreateEntryFunc: func(ctx context.Context, entry *Entry) error {
+					entries[entry.AccountID] += entry.Amount
+					return nil
+				},
+				GetAccountBalanceFunc: func(ctx context.Context, id string) (*Account, error) {
+					return &Account{ID: id}, nil
+				},
+				UpdateAccountBalanceFunc: func(ctx context.Context, id string, balance, held, expectedVersion int64) error { return nil },
+				CreateOutboxEventFunc:    func(ctx context.Context, et, key string, data []byte) error { return nil },
+				CommitFunc:               func() error { return nil },
+				RollbackFunc:             func() error { return nil },
+			}, nil
+		},
+	}
+	service := NewLedgerService(mockRepo, nil)
+
+	_, err := service.ReverseTransactionAmount(context.Background(), "zone_123", "live", "tx_1", ReversalRequest{ReferenceID: "refund_2", Reason: "refund"}, 9985)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]int64{"fee_revenue": -999, "connected": -8986, "clearing": 9985}
+	for acc, amt := range want {
+		if entries[acc] != amt {
+			t.Errorf("Expected %d on %s, got %d", amt, acc, entries[acc])
+		}
+	}
+}
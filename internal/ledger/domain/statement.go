@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// AccountBalance is an account's posted balance at a point in time.
+type AccountBalance struct {
+	AccountID string    `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+}
+
+// StatementLine is a single entry on an account statement.
+type StatementLine struct {
+	EntryID        string          `json:"entry_id"`
+	TransactionID  string          `json:"transaction_id"`
+	ReferenceID    string          `json:"reference_id"`
+	Description    string          `json:"description"`
+	Amount         int64           `json:"amount"`
+	Direction      TransactionType `json:"direction"`
+	RunningBalance int64           `json:"running_balance"`
+	EffectiveAt    time.Time       `json:"effective_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// Statement lists an account's entries over [From, To) with the balances
+// either side of the range.
+type Statement struct {
+	AccountID      string          `json:"account_id"`
+	AccountName    string          `json:"account_name"`
+	Currency       string          `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// GetBalanceAt returns the balance of an account including every entry
+// effective strictly before at.
+func (s *LedgerService) GetBalanceAt(ctx context.Context, accountID string, at time.Time) (*AccountBalance, error) {
+	acc, err := s.repo.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, invalidf("account %s not found", accountID)
+	}
+
+	balance, err := s.repo.GetBalanceAt(ctx, accountID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountBalance{
+		AccountID: acc.ID,
+		Currency:  acc.Currency,
+		Balance:   balance,
+		AsOf:      at,
+	}, nil
+}
+
+// GetStatement builds the statement of an account for [from, to).
+func (s *LedgerService) GetStatement(ctx context.Context, accountID string, from, to time.Time) (*Statement, error) {
+	if !from.Before(to) {
+		return nil, invalidf("statement range is empty: from must be before to")
+	}
+
+	acc, err := s.repo.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, invalidf("account %s not found", accountID)
+	}
+
+	opening, err := s.repo.GetBalanceAt(ctx, accountID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.ListAccountEntries(ctx, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	running := opening
+	for i := range lines {
+		running += lines[i].Amount
+		lines[i].RunningBalance = running
+	}
+
+	return &Statement{
+		AccountID:      acc.ID,
+		AccountName:    acc.Name,
+		Currency:       acc.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: running,
+		Lines:          lines,
+	}, nil
+}
+
+// WriteStatementCSV renders a statement as CSV with an opening row, one row
+// per entry and a closing row.
+func WriteStatementCSV(w io.Writer, st *Statement) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"date", "transaction_id", "reference_id", "description", "direction", "amount", "running_balance", "currency"},
+		{st.From.Format(time.RFC3339), "", "", "Opening balance", "", "", strconv.FormatInt(st.OpeningBalance, 10), st.Currency},
+	}
+	for _, l := range st.Lines {
+		rows = append(rows, []string{
+			l.EffectiveAt.Format(time.RFC3339),
+			l.TransactionID,
+			l.ReferenceID,
+			l.Description,
+			string(l.Direction),
+			strconv.FormatInt(l.Amount, 10),
+			strconv.FormatInt(l.RunningBalance, 10),
+			st.Currency,
+		})
+	}
+	rows = append(rows, []string{st.To.Format(time.RFC3339), "", "", "Closing balance", "", "", strconv.FormatInt(st.ClosingBalance, 10), st.Currency})
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -61,6 +61,26 @@ func (r *CachedRepository) GetAccount(ctx context.Context, id string) (*domain.A
 	return acc, nil
 }
 
+func (r *CachedRepository) GetAccountByCode(ctx context.Context, zoneID, mode, code, currency string) (*domain.Account, error) {
+	return r.repo.GetAccountByCode(ctx, zoneID, mode, code, currency)
+}
+
+func (r *CachedRepository) GetUserAccount(ctx context.Context, zoneID, mode, userID, currency string) (*domain.Account, error) {
+	return r.repo.GetUserAccount(ctx, zoneID, mode, userID, currency)
+}
+
+func (r *CachedRepository) ListAccounts(ctx context.Context, zoneID, mode string) ([]domain.Account, error) {
+	return r.repo.ListAccounts(ctx, zoneID, mode)
+}
+
+func (r *CachedRepository) UpdateOverdraftLimit(ctx context.Context, id string, limit *int64) error {
+	if err := r.repo.UpdateOverdraftLimit(ctx, id, limit); err != nil {
+		return err
+	}
+	r.redis.Del(ctx, r.accKey(id))
+	return nil
+}
+
 func (r *CachedRepository) BeginTx(ctx context.Context) (domain.TransactionContext, error) {
 	tx, err := r.repo.BeginTx(ctx)
 	if err != nil {
@@ -73,14 +93,6 @@ func (r *CachedRepository) BeginTx(ctx context.Context) (domain.TransactionConte
 	}, nil
 }
 
-func (r *CachedRepository) GetUnprocessedEvents(ctx context.Context, limit int) ([]domain.OutboxEvent, error) {
-	return r.repo.GetUnprocessedEvents(ctx, limit)
-}
-
-func (r *CachedRepository) MarkEventProcessed(ctx context.Context, id string) error {
-	return r.repo.MarkEventProcessed(ctx, id)
-}
-
 func (r *CachedRepository) ListTransactions(ctx context.Context, zoneID string, limit int) ([]domain.TransactionWithEntries, error) {
 	return r.repo.ListTransactions(ctx, zoneID, limit)
 }
@@ -89,6 +101,62 @@ func (r *CachedRepository) GetTransaction(ctx context.Context, id string) (*doma
 	return r.repo.GetTransaction(ctx, id)
 }
 
+func (r *CachedRepository) GetTransactionByReference(ctx context.Context, referenceID string) (*domain.TransactionWithEntries, error) {
+	return r.repo.GetTransactionByReference(ctx, referenceID)
+}
+
+func (r *CachedRepository) GetReversedAmounts(ctx context.Context, transactionID string) (map[string]int64, error) {
+	return r.repo.GetReversedAmounts(ctx, transactionID)
+}
+
+func (r *CachedRepository) GetBalanceAt(ctx context.Context, accountID string, at time.Time) (int64, error) {
+	return r.repo.GetBalanceAt(ctx, accountID, at)
+}
+
+func (r *CachedRepository) ListAccountEntries(ctx context.Context, accountID string, from, to time.Time) ([]domain.StatementLine, error) {
+	return r.repo.ListAccountEntries(ctx, accountID, from, to)
+}
+
+func (r *CachedRepository) StreamAccountEntries(ctx context.Context, accountID string, from, to time.Time, fn func(domain.StatementLine) error) error {
+	return r.repo.StreamAccountEntries(ctx, accountID, from, to, fn)
+}
+
+func (r *CachedRepository) UpsertFXRate(ctx context.Context, rate *domain.FXRate) error {
+	return r.repo.UpsertFXRate(ctx, rate)
+}
+
+func (r *CachedRepository) GetFXRate(ctx context.Context, zoneID, mode, base, quote string) (*domain.FXRate, error) {
+	return r.repo.GetFXRate(ctx, zoneID, mode, base, quote)
+}
+
+func (r *CachedRepository) ListFXRates(ctx context.Context, zoneID, mode string) ([]domain.FXRate, error) {
+	return r.repo.ListFXRates(ctx, zoneID, mode)
+}
+
+func (r *CachedRepository) CreatePeriod(ctx context.Context, period *domain.AccountingPeriod) error {
+	return r.repo.CreatePeriod(ctx, period)
+}
+
+func (r *CachedRepository) ListPeriods(ctx context.Context, zoneID, mode string) ([]domain.AccountingPeriod, error) {
+	return r.repo.ListPeriods(ctx, zoneID, mode)
+}
+
+func (r *CachedRepository) GetPeriodSnapshot(ctx context.Context, periodID string) (*domain.PeriodSnapshot, error) {
+	return r.repo.GetPeriodSnapshot(ctx, periodID)
+}
+
+func (r *CachedRepository) GetTrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) ([]domain.TrialBalanceLine, error) {
+	return r.repo.GetTrialBalance(ctx, zoneID, mode, from, to)
+}
+
+func (r *CachedRepository) GetHold(ctx context.Context, id string) (*domain.Hold, error) {
+	return r.repo.GetHold(ctx, id)
+}
+
+func (r *CachedRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]domain.Hold, error) {
+	return r.repo.ListExpiredHolds(ctx, now, limit)
+}
+
 type cachedTransactionContext struct {
 	domain.TransactionContext
 	redis       *redis.Client
@@ -104,6 +172,16 @@ func (c *cachedTransactionContext) CreateEntry(ctx context.Context, entry *domai
 	return err
 }
 
+// UpdateAccountBalance also covers hold changes, which move the held amount
+// without creating entries.
+func (c *cachedTransactionContext) UpdateAccountBalance(ctx context.Context, id string, balance, held, expectedVersion int64) error {
+	err := c.TransactionContext.UpdateAccountBalance(ctx, id, balance, held, expectedVersion)
+	if err == nil {
+		c.changedAccs = append(c.changedAccs, id)
+	}
+	return err
+}
+
 func (c *cachedTransactionContext) Commit() error {
 	err := c.TransactionContext.Commit()
 	if err == nil {
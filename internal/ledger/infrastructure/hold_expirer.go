@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+)
+
+// HoldExpirer periodically releases holds whose expiry has passed.
+type HoldExpirer struct {
+	service      *domain.LedgerService
+	pollInterval time.Duration
+}
+
+func NewHoldExpirer(service *domain.LedgerService, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{
+		service:      service,
+		pollInterval: interval,
+	}
+}
+
+func (e *HoldExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	log.Printf("Hold Expirer started (polling every %v)", e.pollInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := e.service.ExpireHolds(ctx, time.Now().UTC(), 100)
+			if err != nil {
+				log.Printf("Failed to expire holds: %v", err)
+			}
+			if n > 0 {
+				log.Printf("Expired %d holds", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+)
+
+const holdColumns = `id, account_id, zone_id, mode, amount, captured_amount, reference_id, COALESCE(description, ''),
+	status, transaction_id, expires_at, created_at, updated_at`
+
+func scanHold(row interface{ Scan(...any) error }) (*domain.Hold, error) {
+	h := &domain.Hold{}
+	var transactionID sql.NullString
+	err := row.Scan(&h.ID, &h.AccountID, &h.ZoneID, &h.Mode, &h.Amount, &h.CapturedAmount, &h.ReferenceID, &h.Description,
+		&h.Status, &transactionID, &h.ExpiresAt, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if transactionID.Valid {
+		h.TransactionID = &transactionID.String
+	}
+	return h, nil
+}
+
+func (r *SQLRepository) GetHold(ctx context.Context, id string) (*domain.Hold, error) {
+	return scanHold(r.db.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds WHERE id = $1`, id))
+}
+
+func (r *SQLRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]domain.Hold, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+holdColumns+` FROM holds WHERE status = 'active' AND expires_at <= $1 ORDER BY expires_at LIMIT $2`,
+		now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []domain.Hold
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *h)
+	}
+	return holds, rows.Err()
+}
+
+func (c *sqlTxContext) CreateHold(ctx context.Context, hold *domain.Hold) error {
+	return c.tx.QueryRowContext(ctx,
+		`INSERT INTO holds (account_id, zone_id, mode, amount, reference_id, description, status, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, updated_at`,
+		hold.AccountID, hold.ZoneID, hold.Mode, hold.Amount, hold.ReferenceID, hold.Description, hold.Status, hold.ExpiresAt).
+		Scan(&hold.ID, &hold.CreatedAt, &hold.UpdatedAt)
+}
+
+func (c *sqlTxContext) GetHoldByReference(ctx context.Context, referenceID string) (*domain.Hold, error) {
+	return scanHold(c.tx.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds WHERE reference_id = $1`, referenceID))
+}
+
+func (c *sqlTxContext) LockHold(ctx context.Context, id string) (*domain.Hold, error) {
+	return scanHold(c.tx.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds WHERE id = $1 FOR UPDATE`, id))
+}
+
+func (c *sqlTxContext) UpdateHold(ctx context.Context, hold *domain.Hold) error {
+	return c.tx.QueryRowContext(ctx,
+		`UPDATE holds SET status = $1, captured_amount = $2, transaction_id = $3, updated_at = NOW()
+		 WHERE id = $4 RETURNING updated_at`,
+		hold.Status, hold.CapturedAmount, hold.TransactionID, hold.ID).Scan(&hold.UpdatedAt)
+}
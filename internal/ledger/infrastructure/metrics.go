@@ -16,11 +16,6 @@ var (
 		Help:    "Latency of ledger transaction recording.",
 		Buckets: prometheus.DefBuckets,
 	})
-
-	OutboxLag = promauto.NewGauge(prometheus.GaugeOpts{
-		Name: "ledger_outbox_lag_total",
-		Help: "Current number of unprocessed events in the outbox.",
-	})
 )
 
 type PrometheusMetrics struct{}
@@ -0,0 +1,183 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+)
+
+// queryer is satisfied by both *sql.DB and *sql.Tx so read queries can be
+// shared between the repository and its transaction context.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const periodColumns = `id, zone_id, mode, name, starts_at, ends_at, status, closed_at, COALESCE(closed_by, ''),
+	reopened_at, COALESCE(reopened_by, ''), COALESCE(reopen_reason, ''), created_at`
+
+func scanPeriod(row interface{ Scan(...any) error }) (*domain.AccountingPeriod, error) {
+	p := &domain.AccountingPeriod{}
+	err := row.Scan(&p.ID, &p.ZoneID, &p.Mode, &p.Name, &p.StartsAt, &p.EndsAt, &p.Status, &p.ClosedAt, &p.ClosedBy,
+		&p.ReopenedAt, &p.ReopenedBy, &p.ReopenReason, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *SQLRepository) CreatePeriod(ctx context.Context, period *domain.AccountingPeriod) error {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO accounting_periods (zone_id, mode, name, starts_at, ends_at, status)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		period.ZoneID, period.Mode, period.Name, period.StartsAt, period.EndsAt, period.Status).Scan(&period.ID, &period.CreatedAt)
+	if err != nil {
+		// An exclusion constraint rejects overlapping periods of a zone.
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23P01" {
+			return domain.ErrPeriodOverlap
+		}
+		return fmt.Errorf("failed to create period: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) ListPeriods(ctx context.Context, zoneID, mode string) ([]domain.AccountingPeriod, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+periodColumns+` FROM accounting_periods
+		 WHERE zone_id = $1 AND mode = $2
+		 ORDER BY starts_at ASC`,
+		zoneID, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []domain.AccountingPeriod
+	for rows.Next() {
+		p, err := scanPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, *p)
+	}
+	return periods, rows.Err()
+}
+
+func (r *SQLRepository) GetPeriodSnapshot(ctx context.Context, periodID string) (*domain.PeriodSnapshot, error) {
+	snap := &domain.PeriodSnapshot{}
+	var lines []byte
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, period_id, zone_id, mode, starts_at, ends_at, trial_balance, closed_by, closed_at, signature
+		 FROM period_snapshots
+		 WHERE period_id = $1
+		 ORDER BY closed_at DESC
+		 LIMIT 1`,
+		periodID).Scan(&snap.ID, &snap.PeriodID, &snap.ZoneID, &snap.Mode, &snap.StartsAt, &snap.EndsAt, &lines, &snap.ClosedBy, &snap.ClosedAt, &snap.Signature)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get period snapshot: %w", err)
+	}
+	if err := json.Unmarshal(lines, &snap.TrialBalance); err != nil {
+		return nil, fmt.Errorf("failed to decode trial balance: %w", err)
+	}
+	return snap, nil
+}
+
+func (r *SQLRepository) GetTrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) ([]domain.TrialBalanceLine, error) {
+	return queryTrialBalance(ctx, r.db, zoneID, mode, from, to)
+}
+
+func (c *sqlTxContext) GetPeriodForDate(ctx context.Context, zoneID, mode string, at time.Time) (*domain.AccountingPeriod, error) {
+	p, err := scanPeriod(c.tx.QueryRowContext(ctx,
+		`SELECT `+periodColumns+` FROM accounting_periods
+		 WHERE zone_id = $1 AND mode = $2 AND starts_at <= $3 AND ends_at > $3
+		 FOR SHARE`,
+		zoneID, mode, at))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (c *sqlTxContext) LockPeriod(ctx context.Context, id string) (*domain.AccountingPeriod, error) {
+	p, err := scanPeriod(c.tx.QueryRowContext(ctx,
+		`SELECT `+periodColumns+` FROM accounting_periods WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (c *sqlTxContext) UpdatePeriod(ctx context.Context, period *domain.AccountingPeriod) error {
+	_, err := c.tx.ExecContext(ctx,
+		`UPDATE accounting_periods
+		 SET status = $1, closed_at = $2, closed_by = $3, reopened_at = $4, reopened_by = $5, reopen_reason = $6
+		 WHERE id = $7`,
+		period.Status, period.ClosedAt, period.ClosedBy, period.ReopenedAt, period.ReopenedBy, period.ReopenReason, period.ID)
+	return err
+}
+
+func (c *sqlTxContext) GetTrialBalance(ctx context.Context, zoneID, mode string, from, to time.Time) ([]domain.TrialBalanceLine, error) {
+	return queryTrialBalance(ctx, c.tx, zoneID, mode, from, to)
+}
+
+func (c *sqlTxContext) CreatePeriodSnapshot(ctx context.Context, snapshot *domain.PeriodSnapshot) error {
+	lines, err := json.Marshal(snapshot.TrialBalance)
+	if err != nil {
+		return err
+	}
+	return c.tx.QueryRowContext(ctx,
+		`INSERT INTO period_snapshots (period_id, zone_id, mode, starts_at, ends_at, trial_balance, closed_by, closed_at, signature)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		snapshot.PeriodID, snapshot.ZoneID, snapshot.Mode, snapshot.StartsAt, snapshot.EndsAt, lines, snapshot.ClosedBy, snapshot.ClosedAt, snapshot.Signature).Scan(&snapshot.ID)
+}
+
+// queryTrialBalance returns, for every account of the zone and mode, the
+// debits and credits effective in [from, to) and the balance at to.
+func queryTrialBalance(ctx context.Context, q queryer, zoneID, mode string, from, to time.Time) ([]domain.TrialBalanceLine, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT a.id, a.name, a.type, a.currency,
+		        COALESCE(SUM(CASE WHEN x.amount < 0 AND x.effective_at >= $3 THEN -x.amount END), 0) AS debits,
+		        COALESCE(SUM(CASE WHEN x.amount > 0 AND x.effective_at >= $3 THEN x.amount END), 0) AS credits,
+		        COALESCE(SUM(x.amount), 0) AS balance
+		 FROM accounts a
+		 LEFT JOIN (
+		     SELECT e.account_id, e.amount, t.effective_at
+		     FROM entries e
+		     JOIN transactions t ON t.id = e.transaction_id
+		     WHERE t.effective_at < $4
+		 ) x ON x.account_id = a.id
+		 WHERE a.zone_id = $1 AND a.mode = $2
+		 GROUP BY a.id, a.name, a.type, a.currency
+		 ORDER BY a.type, a.currency, a.name`,
+		zoneID, mode, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []domain.TrialBalanceLine
+	for rows.Next() {
+		var l domain.TrialBalanceLine
+		if err := rows.Scan(&l.AccountID, &l.AccountName, &l.AccountType, &l.Currency, &l.Debits, &l.Credits, &l.Balance); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
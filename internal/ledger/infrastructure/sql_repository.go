@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sapliy/fintech-ecosystem/internal/ledger/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
 )
 
 type SQLRepository struct {
@@ -19,23 +21,48 @@ func NewSQLRepository(db *sql.DB) *SQLRepository {
 
 func (r *SQLRepository) CreateAccount(ctx context.Context, acc *domain.Account) error {
 	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO accounts (name, type, currency, user_id, zone_id, mode) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
-		acc.Name, acc.Type, acc.Currency, acc.UserID, acc.ZoneID, acc.Mode).Scan(&acc.ID, &acc.CreatedAt)
+		`INSERT INTO accounts (name, type, currency, user_id, zone_id, mode, overdraft_limit, code, parent_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9) RETURNING id, created_at`,
+		acc.Name, acc.Type, acc.Currency, acc.UserID, acc.ZoneID, acc.Mode, acc.OverdraftLimit, acc.Code, acc.ParentID).Scan(&acc.ID, &acc.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create account: %w", err)
 	}
 	return nil
 }
 
-func (r *SQLRepository) GetAccount(ctx context.Context, id string) (*domain.Account, error) {
+const accountColumns = `id, name, type, currency, balance, held_amount, version, overdraft_limit, user_id, created_at, zone_id, mode, COALESCE(code, ''), parent_id`
+
+func scanAccount(row interface{ Scan(...any) error }) (*domain.Account, error) {
 	acc := &domain.Account{}
-	err := r.db.QueryRowContext(ctx,
-		`SELECT a.id, a.name, a.type, a.currency, COALESCE(SUM(e.amount), 0) as balance, a.user_id, a.created_at, a.zone_id, a.mode 
-		 FROM accounts a 
-		 LEFT JOIN entries e ON a.id = e.account_id 
-		 WHERE a.id = $1 
-		 GROUP BY a.id, a.zone_id, a.mode`,
-		id).Scan(&acc.ID, &acc.Name, &acc.Type, &acc.Currency, &acc.Balance, &acc.UserID, &acc.CreatedAt, &acc.ZoneID, &acc.Mode)
+	var overdraftLimit sql.NullInt64
+	var parentID sql.NullString
+	if err := row.Scan(&acc.ID, &acc.Name, &acc.Type, &acc.Currency, &acc.Balance, &acc.HeldAmount, &acc.Version, &overdraftLimit, &acc.UserID, &acc.CreatedAt, &acc.ZoneID, &acc.Mode, &acc.Code, &parentID); err != nil {
+		return nil, err
+	}
+	if overdraftLimit.Valid {
+		acc.OverdraftLimit = &overdraftLimit.Int64
+	}
+	if parentID.Valid {
+		acc.ParentID = &parentID.String
+	}
+	return acc, nil
+}
+
+func (r *SQLRepository) GetAccount(ctx context.Context, id string) (*domain.Account, error) {
+	return r.getAccountWhere(ctx, `id = $1`, id)
+}
+
+func (r *SQLRepository) GetAccountByCode(ctx context.Context, zoneID, mode, code, currency string) (*domain.Account, error) {
+	return r.getAccountWhere(ctx, `zone_id = $1 AND mode = $2 AND code = $3 AND currency = $4`, zoneID, mode, code, currency)
+}
+
+// GetUserAccount returns the user's oldest account in the currency.
+func (r *SQLRepository) GetUserAccount(ctx context.Context, zoneID, mode, userID, currency string) (*domain.Account, error) {
+	return r.getAccountWhere(ctx, `zone_id = $1 AND mode = $2 AND user_id = $3 AND currency = $4`, zoneID, mode, userID, currency)
+}
+
+func (r *SQLRepository) getAccountWhere(ctx context.Context, cond string, args ...any) (*domain.Account, error) {
+	acc, err := scanAccount(r.db.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts WHERE `+cond, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -45,39 +72,45 @@ func (r *SQLRepository) GetAccount(ctx context.Context, id string) (*domain.Acco
 	return acc, nil
 }
 
-func (r *SQLRepository) BeginTx(ctx context.Context) (domain.TransactionContext, error) {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, err
-	}
-	return &sqlTxContext{tx: tx}, nil
-}
-
-func (r *SQLRepository) GetUnprocessedEvents(ctx context.Context, limit int) ([]domain.OutboxEvent, error) {
+func (r *SQLRepository) ListAccounts(ctx context.Context, zoneID, mode string) ([]domain.Account, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, event_type, payload, created_at FROM outbox WHERE processed_at IS NULL ORDER BY created_at ASC LIMIT $1`,
-		limit)
+		`SELECT `+accountColumns+` FROM accounts WHERE zone_id = $1 AND mode = $2 ORDER BY code NULLS LAST, created_at`,
+		zoneID, mode)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
+	defer rows.Close()
 
-	var events []domain.OutboxEvent
+	var accounts []domain.Account
 	for rows.Next() {
-		var e domain.OutboxEvent
-		if err := rows.Scan(&e.ID, &e.Type, &e.Payload, &e.CreatedAt); err != nil {
+		acc, err := scanAccount(rows)
+		if err != nil {
 			return nil, err
 		}
-		events = append(events, e)
+		accounts = append(accounts, *acc)
 	}
-	return events, nil
+	return accounts, rows.Err()
 }
 
-func (r *SQLRepository) MarkEventProcessed(ctx context.Context, id string) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE outbox SET processed_at = NOW() WHERE id = $1`, id)
-	return err
+func (r *SQLRepository) UpdateOverdraftLimit(ctx context.Context, id string, limit *int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE accounts SET overdraft_limit = $1 WHERE id = $2`, limit, id)
+	if err != nil {
+		return fmt.Errorf("failed to update overdraft limit: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) BeginTx(ctx context.Context) (domain.TransactionContext, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTxContext{tx: tx}, nil
 }
 
+// EventsTopic is the Kafka topic ledger events are published to.
+const EventsTopic = "ledger-events"
+
 type sqlTxContext struct {
 	tx *sql.Tx
 }
@@ -85,8 +118,9 @@ type sqlTxContext struct {
 func (c *sqlTxContext) CreateTransaction(ctx context.Context, tx *domain.Transaction) (string, error) {
 	var id string
 	err := c.tx.QueryRowContext(ctx,
-		`INSERT INTO transactions (reference_id, description, zone_id, mode) VALUES ($1, $2, $3, $4) RETURNING id`,
-		tx.ReferenceID, tx.Description, tx.ZoneID, tx.Mode).Scan(&id)
+		`INSERT INTO transactions (reference_id, description, zone_id, mode, effective_at, reverses_transaction_id, reversal_reason)
+		 VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')) RETURNING id`,
+		tx.ReferenceID, tx.Description, tx.ZoneID, tx.Mode, tx.EffectiveAt, tx.ReversesID, tx.ReversalReason).Scan(&id)
 	return id, err
 }
 
@@ -97,6 +131,49 @@ func (c *sqlTxContext) CreateEntry(ctx context.Context, entry *domain.Entry) err
 	return err
 }
 
+func (c *sqlTxContext) GetAccountBalance(ctx context.Context, id string) (*domain.Account, error) {
+	acc := &domain.Account{ID: id}
+	var overdraftLimit sql.NullInt64
+	err := c.tx.QueryRowContext(ctx,
+		`SELECT balance, held_amount, version, overdraft_limit FROM accounts WHERE id = $1`,
+		id).Scan(&acc.Balance, &acc.HeldAmount, &acc.Version, &overdraftLimit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if overdraftLimit.Valid {
+		acc.OverdraftLimit = &overdraftLimit.Int64
+	}
+	return acc, nil
+}
+
+func (c *sqlTxContext) UpdateAccountBalance(ctx context.Context, id string, balance, held, expectedVersion int64) error {
+	res, err := c.tx.ExecContext(ctx,
+		`UPDATE accounts SET balance = $1, held_amount = $2, version = version + 1 WHERE id = $3 AND version = $4`,
+		balance, held, id, expectedVersion)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+func (c *sqlTxContext) CreateFXConversion(ctx context.Context, transactionID string, conv *domain.FXConversion) error {
+	_, err := c.tx.ExecContext(ctx,
+		`INSERT INTO fx_conversions (transaction_id, from_currency, to_currency, from_amount, to_amount, rate, rate_source, rate_timestamp)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		transactionID, conv.FromCurrency, conv.ToCurrency, conv.FromAmount, conv.ToAmount, conv.Rate, conv.RateSource, conv.RateTimestamp)
+	return err
+}
+
 func (c *sqlTxContext) CheckIdempotency(ctx context.Context, referenceID string) (string, error) {
 	var id string
 	err := c.tx.QueryRowContext(ctx, `SELECT id FROM transactions WHERE reference_id = $1`, referenceID).Scan(&id)
@@ -109,11 +186,18 @@ func (c *sqlTxContext) CheckIdempotency(ctx context.Context, referenceID string)
 	return id, nil
 }
 
-func (c *sqlTxContext) CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) error {
-	_, err := c.tx.ExecContext(ctx,
-		`INSERT INTO outbox (event_type, payload) VALUES ($1, $2)`,
-		eventType, payload)
-	return err
+// LockReversals locks the original transaction so concurrent reversals of it
+// are serialized, then returns the amounts already reversed per account.
+func (c *sqlTxContext) LockReversals(ctx context.Context, transactionID string) (map[string]int64, error) {
+	var id string
+	if err := c.tx.QueryRowContext(ctx, `SELECT id FROM transactions WHERE id = $1 FOR UPDATE`, transactionID).Scan(&id); err != nil {
+		return nil, err
+	}
+	return queryReversedAmounts(ctx, c.tx, transactionID)
+}
+
+func (c *sqlTxContext) CreateOutboxEvent(ctx context.Context, eventType, key string, payload []byte) error {
+	return outbox.Write(ctx, c.tx, outbox.Event{Topic: EventsTopic, Type: eventType, Key: key, Payload: payload})
 }
 
 func (c *sqlTxContext) Commit() error {
@@ -125,7 +209,7 @@ func (c *sqlTxContext) Rollback() error {
 }
 
 func (r *SQLRepository) ListTransactions(ctx context.Context, zoneID string, limit int) ([]domain.TransactionWithEntries, error) {
-	query := `SELECT id, reference_id, description, zone_id, mode, created_at 
+	query := `SELECT ` + transactionColumns + `
 			  FROM transactions 
 			  WHERE ($1 = '' OR zone_id = $1) 
 			  ORDER BY created_at DESC 
@@ -139,8 +223,8 @@ func (r *SQLRepository) ListTransactions(ctx context.Context, zoneID string, lim
 
 	var txs []domain.TransactionWithEntries
 	for rows.Next() {
-		var tx domain.TransactionWithEntries
-		if err := rows.Scan(&tx.ID, &tx.ReferenceID, &tx.Description, &tx.ZoneID, &tx.Mode, &tx.CreatedAt); err != nil {
+		tx, err := scanTransaction(rows)
+		if err != nil {
 			return nil, err
 		}
 
@@ -150,16 +234,42 @@ func (r *SQLRepository) ListTransactions(ctx context.Context, zoneID string, lim
 			return nil, err
 		}
 		tx.Entries = entries
-		txs = append(txs, tx)
+
+		conversions, err := r.getTransactionConversions(ctx, tx.ID)
+		if err != nil {
+			return nil, err
+		}
+		tx.Conversions = conversions
+		txs = append(txs, *tx)
 	}
 	return txs, nil
 }
 
-func (r *SQLRepository) GetTransaction(ctx context.Context, id string) (*domain.TransactionWithEntries, error) {
+const transactionColumns = `id, reference_id, description, zone_id, mode, effective_at, reverses_transaction_id, COALESCE(reversal_reason, ''), created_at`
+
+func scanTransaction(row interface{ Scan(...any) error }) (*domain.TransactionWithEntries, error) {
 	tx := &domain.TransactionWithEntries{}
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, reference_id, description, zone_id, mode, created_at FROM transactions WHERE id = $1`,
-		id).Scan(&tx.ID, &tx.ReferenceID, &tx.Description, &tx.ZoneID, &tx.Mode, &tx.CreatedAt)
+	var reverses sql.NullString
+	if err := row.Scan(&tx.ID, &tx.ReferenceID, &tx.Description, &tx.ZoneID, &tx.Mode, &tx.EffectiveAt, &reverses, &tx.ReversalReason, &tx.CreatedAt); err != nil {
+		return nil, err
+	}
+	if reverses.Valid {
+		tx.ReversesID = &reverses.String
+	}
+	return tx, nil
+}
+
+func (r *SQLRepository) GetTransaction(ctx context.Context, id string) (*domain.TransactionWithEntries, error) {
+	return r.getTransactionWhere(ctx, `id = $1`, id)
+}
+
+func (r *SQLRepository) GetTransactionByReference(ctx context.Context, referenceID string) (*domain.TransactionWithEntries, error) {
+	return r.getTransactionWhere(ctx, `reference_id = $1`, referenceID)
+}
+
+func (r *SQLRepository) getTransactionWhere(ctx context.Context, cond string, arg string) (*domain.TransactionWithEntries, error) {
+	tx, err := scanTransaction(r.db.QueryRowContext(ctx,
+		`SELECT `+transactionColumns+` FROM transactions WHERE `+cond, arg))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -167,14 +277,51 @@ func (r *SQLRepository) GetTransaction(ctx context.Context, id string) (*domain.
 		return nil, err
 	}
 
-	entries, err := r.getTransactionEntries(ctx, id)
+	entries, err := r.getTransactionEntries(ctx, tx.ID)
 	if err != nil {
 		return nil, err
 	}
 	tx.Entries = entries
+
+	conversions, err := r.getTransactionConversions(ctx, tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	tx.Conversions = conversions
 	return tx, nil
 }
 
+func (r *SQLRepository) GetReversedAmounts(ctx context.Context, transactionID string) (map[string]int64, error) {
+	return queryReversedAmounts(ctx, r.db, transactionID)
+}
+
+// queryReversedAmounts sums, per account, the entries of every reversal of a
+// transaction.
+func queryReversedAmounts(ctx context.Context, q queryer, transactionID string) (map[string]int64, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT e.account_id, SUM(e.amount)
+		 FROM entries e
+		 JOIN transactions t ON t.id = e.transaction_id
+		 WHERE t.reverses_transaction_id = $1
+		 GROUP BY e.account_id`,
+		transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	amounts := make(map[string]int64)
+	for rows.Next() {
+		var accountID string
+		var amount int64
+		if err := rows.Scan(&accountID, &amount); err != nil {
+			return nil, err
+		}
+		amounts[accountID] = amount
+	}
+	return amounts, rows.Err()
+}
+
 func (r *SQLRepository) getTransactionEntries(ctx context.Context, txID string) ([]domain.Entry, error) {
 	rows, err := r.db.QueryContext(ctx,
 		`SELECT id, transaction_id, account_id, amount, direction, created_at FROM entries WHERE transaction_id = $1`,
@@ -194,3 +341,127 @@ func (r *SQLRepository) getTransactionEntries(ctx context.Context, txID string)
 	}
 	return entries, nil
 }
+
+func (r *SQLRepository) GetBalanceAt(ctx context.Context, accountID string, at time.Time) (int64, error) {
+	var balance int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(e.amount), 0)
+		 FROM entries e
+		 JOIN transactions t ON t.id = e.transaction_id
+		 WHERE e.account_id = $1 AND t.effective_at < $2`,
+		accountID, at).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance at %s: %w", at.Format(time.RFC3339), err)
+	}
+	return balance, nil
+}
+
+func (r *SQLRepository) ListAccountEntries(ctx context.Context, accountID string, from, to time.Time) ([]domain.StatementLine, error) {
+	var lines []domain.StatementLine
+	err := r.StreamAccountEntries(ctx, accountID, from, to, func(l domain.StatementLine) error {
+		lines = append(lines, l)
+		return nil
+	})
+	return lines, err
+}
+
+// StreamAccountEntries calls fn for each entry in statement order while
+// reading rows, so large ranges are never held in memory.
+func (r *SQLRepository) StreamAccountEntries(ctx context.Context, accountID string, from, to time.Time, fn func(domain.StatementLine) error) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT e.id, e.transaction_id, t.reference_id, COALESCE(t.description, ''), e.amount, e.direction, t.effective_at, e.created_at
+		 FROM entries e
+		 JOIN transactions t ON t.id = e.transaction_id
+		 WHERE e.account_id = $1 AND t.effective_at >= $2 AND t.effective_at < $3
+		 ORDER BY t.effective_at ASC, e.created_at ASC, e.id ASC`,
+		accountID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l domain.StatementLine
+		if err := rows.Scan(&l.EntryID, &l.TransactionID, &l.ReferenceID, &l.Description, &l.Amount, &l.Direction, &l.EffectiveAt, &l.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *SQLRepository) getTransactionConversions(ctx context.Context, txID string) ([]domain.FXConversion, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT from_currency, to_currency, from_amount, to_amount, rate, rate_source, rate_timestamp
+		 FROM fx_conversions WHERE transaction_id = $1`,
+		txID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversions []domain.FXConversion
+	for rows.Next() {
+		var c domain.FXConversion
+		if err := rows.Scan(&c.FromCurrency, &c.ToCurrency, &c.FromAmount, &c.ToAmount, &c.Rate, &c.RateSource, &c.RateTimestamp); err != nil {
+			return nil, err
+		}
+		conversions = append(conversions, c)
+	}
+	return conversions, nil
+}
+
+func (r *SQLRepository) UpsertFXRate(ctx context.Context, rate *domain.FXRate) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO fx_rates (zone_id, mode, base_currency, quote_currency, rate, source, as_of)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (zone_id, mode, base_currency, quote_currency)
+		 DO UPDATE SET rate = EXCLUDED.rate, source = EXCLUDED.source, as_of = EXCLUDED.as_of`,
+		rate.ZoneID, rate.Mode, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.Source, rate.AsOf)
+	if err != nil {
+		return fmt.Errorf("failed to upsert fx rate: %w", err)
+	}
+	return nil
+}
+
+// Platform-wide rates have an empty zone, so a zone's own rate sorts first.
+const fxRateColumns = `zone_id, mode, base_currency, quote_currency, rate, source, as_of`
+
+func (r *SQLRepository) GetFXRate(ctx context.Context, zoneID, mode, base, quote string) (*domain.FXRate, error) {
+	rate := &domain.FXRate{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT `+fxRateColumns+` FROM fx_rates
+		 WHERE base_currency = $1 AND quote_currency = $2 AND ((zone_id = $3 AND mode = $4) OR zone_id = '')
+		 ORDER BY zone_id DESC LIMIT 1`,
+		base, quote, zoneID, mode).Scan(&rate.ZoneID, &rate.Mode, &rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.Source, &rate.AsOf)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get fx rate: %w", err)
+	}
+	return rate, nil
+}
+
+func (r *SQLRepository) ListFXRates(ctx context.Context, zoneID, mode string) ([]domain.FXRate, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+fxRateColumns+` FROM fx_rates
+		 WHERE (zone_id = $1 AND mode = $2) OR zone_id = ''
+		 ORDER BY base_currency, quote_currency, zone_id DESC`, zoneID, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []domain.FXRate
+	for rows.Next() {
+		var rate domain.FXRate
+		if err := rows.Scan(&rate.ZoneID, &rate.Mode, &rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.Source, &rate.AsOf); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
@@ -28,6 +28,11 @@ CREATE TABLE IF NOT EXISTS outbox (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     event_type VARCHAR(255) NOT NULL,
     payload JSONB NOT NULL,
+    partition_key TEXT NOT NULL DEFAULT '',
+    seq BIGSERIAL,
+    attempts INT NOT NULL DEFAULT 0,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     processed_at TIMESTAMP WITH TIME ZONE -- NULL means pending
 );
@@ -43,14 +48,16 @@ $$ LANGUAGE plpgsql;
 CREATE OR REPLACE FUNCTION prevent_outbox_mutation()
 RETURNS TRIGGER AS $$
 BEGIN
-    -- Allow updating processed_at from NULL to a timestamp
-    IF (OLD.processed_at IS NULL AND NEW.processed_at IS NOT NULL) THEN
-        -- Ensure other columns haven't changed
-        IF (OLD.id = NEW.id AND OLD.event_type = NEW.event_type AND OLD.payload = NEW.payload AND OLD.created_at = NEW.created_at) THEN
+    -- Allow the relay's bookkeeping on pending events: setting processed_at
+    -- once, or recording a failed attempt
+    IF (TG_OP = 'UPDATE' AND OLD.processed_at IS NULL) THEN
+        -- Ensure the event itself hasn't changed
+        IF (OLD.id = NEW.id AND OLD.event_type = NEW.event_type AND OLD.payload = NEW.payload AND OLD.created_at = NEW.created_at
+            AND OLD.partition_key = NEW.partition_key AND OLD.seq = NEW.seq) THEN
             RETURN NEW;
         END IF;
     END IF;
-    RAISE EXCEPTION 'Immutable outbox violation. Only processed_at can be updated exactly once.';
+    RAISE EXCEPTION 'Immutable outbox violation. Only delivery bookkeeping can be updated before processing.';
 END;
 $$ LANGUAGE plpgsql;
 
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/internal/payment/infrastructure"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+// Merchants create checkout sessions and payment links with their API key
+// under /checkout_sessions and /payment_links. Customers reach the hosted
+// pages under /checkout without credentials: the session ID in the URL is
+// what lets them pay.
+
+type checkoutRequest struct {
+	LineItems  []domain.LineItem `json:"line_items"`
+	Currency   string            `json:"currency"`
+	SuccessURL string            `json:"success_url"`
+	CancelURL  string            `json:"cancel_url"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+}
+
+func (h *PaymentHandler) CreateCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+
+	var req checkoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	session := &domain.CheckoutSession{
+		LineItems:  req.LineItems,
+		Currency:   req.Currency,
+		SuccessURL: req.SuccessURL,
+		CancelURL:  req.CancelURL,
+		ExpiresAt:  req.ExpiresAt,
+		UserID:     userID,
+		ZoneID:     r.Header.Get("X-Zone-ID"),
+		Mode:       r.Header.Get("X-Zone-Mode"),
+	}
+	if err := h.checkout.CreateSession(r.Context(), session, time.Now()); err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("checkout_create", "error").Inc()
+		writeError(w, err, "Failed to create checkout session")
+		return
+	}
+
+	infrastructure.PaymentRequests.WithLabelValues("checkout_create", "success").Inc()
+	jsonutil.WriteJSON(w, http.StatusCreated, session)
+}
+
+func (h *PaymentHandler) GetCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+	id := jsonutil.GetIDAfter(r, "checkout_sessions")
+	if id == "" {
+		apierror.BadRequest("Missing Checkout Session ID").Write(w)
+		return
+	}
+
+	session, err := h.checkout.GetSession(r.Context(), id)
+	if err == nil && session.UserID != userID {
+		err = domain.ErrCheckoutSessionNotFound
+	}
+	if err != nil {
+		writeError(w, err, "Failed to get checkout session")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, session)
+}
+
+func (h *PaymentHandler) CreatePaymentLink(w http.ResponseWriter, r *http.Request) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+
+	var req checkoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	link := &domain.PaymentLink{
+		LineItems:  req.LineItems,
+		Currency:   req.Currency,
+		SuccessURL: req.SuccessURL,
+		CancelURL:  req.CancelURL,
+		UserID:     userID,
+		ZoneID:     r.Header.Get("X-Zone-ID"),
+		Mode:       r.Header.Get("X-Zone-Mode"),
+	}
+	if err := h.checkout.CreatePaymentLink(r.Context(), link); err != nil {
+		writeError(w, err, "Failed to create payment link")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, link)
+}
+
+func (h *PaymentHandler) GetPaymentLink(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.ownPaymentLink(w, r)
+	if !ok {
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, link)
+}
+
+func (h *PaymentHandler) DeactivatePaymentLink(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.ownPaymentLink(w, r)
+	if !ok {
+		return
+	}
+
+	link, err := h.checkout.DeactivatePaymentLink(r.Context(), link.ID)
+	if err != nil {
+		writeError(w, err, "Failed to deactivate payment link")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, link)
+}
+
+// ownPaymentLink loads the payment link named in the path if it belongs to
+// the caller, and writes the error response otherwise.
+func (h *PaymentHandler) ownPaymentLink(w http.ResponseWriter, r *http.Request) (*domain.PaymentLink, bool) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return nil, false
+	}
+	id := jsonutil.GetIDAfter(r, "payment_links")
+	if id == "" {
+		apierror.BadRequest("Missing Payment Link ID").Write(w)
+		return nil, false
+	}
+
+	link, err := h.checkout.GetPaymentLink(r.Context(), id)
+	if err == nil && link.UserID != userID {
+		err = domain.ErrPaymentLinkNotFound
+	}
+	if err != nil {
+		writeError(w, err, "Failed to get payment link")
+		return nil, false
+	}
+	return link, true
+}
+
+// hostedSession is what the hosted page shows a customer of a session,
+// without the merchant's details.
+type hostedSession struct {
+	ID          string                       `json:"id"`
+	Status      domain.CheckoutSessionStatus `json:"status"`
+	LineItems   []domain.LineItem            `json:"line_items"`
+	AmountTotal int64                        `json:"amount_total"`
+	Currency    string                       `json:"currency"`
+	CancelURL   string                       `json:"cancel_url,omitempty"`
+	ExpiresAt   time.Time                    `json:"expires_at"`
+}
+
+func newHostedSession(s *domain.CheckoutSession) hostedSession {
+	return hostedSession{
+		ID:          s.ID,
+		Status:      s.Status,
+		LineItems:   s.LineItems,
+		AmountTotal: s.AmountTotal,
+		Currency:    s.Currency,
+		CancelURL:   s.CancelURL,
+		ExpiresAt:   s.ExpiresAt,
+	}
+}
+
+// OpenPaymentLink opens a new session for a customer following a payment
+// link and redirects them to its hosted page.
+func (h *PaymentHandler) OpenPaymentLink(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "links")
+	if id == "" {
+		apierror.BadRequest("Missing Payment Link ID").Write(w)
+		return
+	}
+
+	session, err := h.checkout.OpenPaymentLink(r.Context(), id, time.Now())
+	if err != nil {
+		writeError(w, err, "Failed to open payment link")
+		return
+	}
+
+	http.Redirect(w, r, session.URL, http.StatusSeeOther)
+}
+
+func (h *PaymentHandler) GetHostedCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "sessions")
+	if id == "" {
+		apierror.BadRequest("Missing Checkout Session ID").Write(w)
+		return
+	}
+
+	session, err := h.checkout.GetSession(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "Failed to get checkout session")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, newHostedSession(session))
+}
+
+// checkoutPayment is the outcome of a customer's payment attempt. The
+// hosted page sends the customer to RedirectURL when it is set, and to the
+// next action's URL to authenticate; after a decline the customer can try
+// again with another payment method.
+type checkoutPayment struct {
+	Session       hostedSession      `json:"session"`
+	PaymentStatus domain.Status      `json:"payment_status"`
+	NextAction    *domain.NextAction `json:"next_action,omitempty"`
+	RedirectURL   string             `json:"redirect_url,omitempty"`
+}
+
+// ConfirmCheckoutSession pays for a session with the customer's payment
+// method. The session's payment intent is created on the first attempt and
+// confirmed like through the intents API; a challenge sends the customer
+// back to the session's return page.
+func (h *PaymentHandler) ConfirmCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "sessions")
+	if id == "" {
+		apierror.BadRequest("Missing Checkout Session ID").Write(w)
+		return
+	}
+
+	var req struct {
+		PaymentMethodID string `json:"payment_method_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PaymentMethodID == "" {
+		apierror.BadRequest("payment_method_id is required").Write(w)
+		return
+	}
+
+	session, intent, err := h.checkout.StartPayment(r.Context(), id, time.Now())
+	if err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("checkout_confirm", "error").Inc()
+		writeError(w, err, "Failed to start checkout payment")
+		return
+	}
+	intent, outcome, err := h.confirm(r, intent.ID, req.PaymentMethodID, session.URL+"/return", false)
+	if err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("checkout_confirm", "error").Inc()
+		writeError(w, err, "Failed to confirm checkout payment")
+		return
+	}
+	if session, err = h.checkout.GetSession(r.Context(), id); err != nil {
+		writeError(w, err, "Failed to get checkout session")
+		return
+	}
+
+	resp := checkoutPayment{Session: newHostedSession(session), PaymentStatus: intent.Status, NextAction: intent.NextAction}
+	if session.Status == domain.CheckoutSessionComplete {
+		resp.RedirectURL = session.SuccessURL
+	}
+	infrastructure.PaymentRequests.WithLabelValues("checkout_confirm", outcome).Inc()
+	jsonutil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ReturnToCheckoutSession is where the processor's challenge page sends the
+// customer back. The challenge is completed on the customer's behalf, and
+// the customer is redirected to the success URL once the session is paid,
+// to the cancel URL if it expired, or back to the hosted page otherwise.
+func (h *PaymentHandler) ReturnToCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "sessions")
+	if id == "" {
+		apierror.BadRequest("Missing Checkout Session ID").Write(w)
+		return
+	}
+
+	session, err := h.checkout.GetSession(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "Failed to get checkout session")
+		return
+	}
+	if session.Status == domain.CheckoutSessionOpen && session.PaymentIntentID != "" {
+		intent, err := h.service.GetPaymentIntent(r.Context(), session.PaymentIntentID)
+		if err != nil {
+			apierror.Internal("Failed to get payment intent").Write(w)
+			return
+		}
+		if intent != nil && intent.Status == domain.StatusRequiresAction {
+			_, outcome, err := h.completeAction(r, intent, intent.ClientSecret)
+			if err != nil {
+				// The hosted page shows the session still open, and the
+				// customer can pay again once the intent is back in
+				// requires_payment_method.
+				log.Printf("Failed to complete challenge of checkout session %s: %v", id, err)
+				outcome = "error"
+			}
+			infrastructure.PaymentRequests.WithLabelValues("checkout_return", outcome).Inc()
+		}
+		if session, err = h.checkout.GetSession(r.Context(), id); err != nil {
+			writeError(w, err, "Failed to get checkout session")
+			return
+		}
+	}
+
+	target := session.URL
+	switch {
+	case session.Status == domain.CheckoutSessionComplete:
+		target = session.SuccessURL
+	case session.Status == domain.CheckoutSessionExpired && session.CancelURL != "":
+		target = session.CancelURL
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
+)
+
+// checkoutRepo keeps payment links, checkout sessions and intents in memory
+// and records completed sessions.
+func checkoutRepo(completed *[]string) *domain.MockRepository {
+	links := map[string]domain.PaymentLink{}
+	sessions := map[string]domain.CheckoutSession{}
+	intents := map[string]domain.PaymentIntent{}
+
+	return &domain.MockRepository{
+		CreatePaymentLinkFunc: func(ctx context.Context, link *domain.PaymentLink) error {
+			links[link.ID] = *link
+			return nil
+		},
+		GetPaymentLinkFunc: func(ctx context.Context, id string) (*domain.PaymentLink, error) {
+			link, ok := links[id]
+			if !ok {
+				return nil, nil
+			}
+			return &link, nil
+		},
+		CreateCheckoutSessionFunc: func(ctx context.Context, session *domain.CheckoutSession) error {
+			sessions[session.ID] = *session
+			return nil
+		},
+		GetCheckoutSessionFunc: func(ctx context.Context, id string) (*domain.CheckoutSession, error) {
+			session, ok := sessions[id]
+			if !ok {
+				return nil, nil
+			}
+			return &session, nil
+		},
+		GetCheckoutSessionByPaymentIntentFunc: func(ctx context.Context, intentID string) (*domain.CheckoutSession, error) {
+			for _, session := range sessions {
+				if session.PaymentIntentID == intentID {
+					return &session, nil
+				}
+			}
+			return nil, nil
+		},
+		CreateCheckoutPaymentIntentFunc: func(ctx context.Context, session *domain.CheckoutSession, intent *domain.PaymentIntent) error {
+			intent.ID = "pi_" + session.ID
+			intents[intent.ID] = *intent
+			session.PaymentIntentID = intent.ID
+			sessions[session.ID] = *session
+			return nil
+		},
+		UpdateCheckoutSessionFunc: func(ctx context.Context, session *domain.CheckoutSession, from domain.CheckoutSessionStatus) error {
+			if sessions[session.ID].Status != from {
+				return domain.ErrStatusConflict
+			}
+			sessions[session.ID] = *session
+			if session.Status == domain.CheckoutSessionComplete {
+				*completed = append(*completed, session.ID)
+			}
+			return nil
+		},
+		GetPaymentIntentFunc: func(ctx context.Context, id string) (*domain.PaymentIntent, error) {
+			intent, ok := intents[id]
+			if !ok {
+				return nil, nil
+			}
+			return &intent, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, intent *domain.PaymentIntent, tr domain.Transition) error {
+			if intents[intent.ID].Status != tr.From {
+				return domain.ErrStatusConflict
+			}
+			stored := *intent
+			stored.Status = tr.To
+			intents[intent.ID] = stored
+			return nil
+		},
+	}
+}
+
+func TestPaymentHandler_PaymentLinkCheckout(t *testing.T) {
+	tests := []struct {
+		name          string
+		charge        *bank.TransactionResult
+		completion    *bank.TransactionResult
+		wantStatus    domain.Status
+		wantCompleted bool
+	}{
+		{
+			name:          "Charge succeeds",
+			charge:        &bank.TransactionResult{TransactionID: "txn_1", Status: bank.StatusSuccess},
+			wantStatus:    domain.StatusSucceeded,
+			wantCompleted: true,
+		},
+		{
+			name:       "Charge declined",
+			charge:     &bank.TransactionResult{Status: bank.StatusFailed, ErrorCode: "card_declined"},
+			wantStatus: domain.StatusRequiresPaymentMethod,
+		},
+		{
+			name:          "Challenge passed on return",
+			charge:        &bank.TransactionResult{Status: bank.StatusRequiresAction, ChallengeID: "3ds_1", RedirectURL: "https://sim.test/3ds/3ds_1"},
+			completion:    &bank.TransactionResult{TransactionID: "txn_1", Status: bank.StatusSuccess},
+			wantStatus:    domain.StatusRequiresAction,
+			wantCompleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var completed []string
+			repo := checkoutRepo(&completed)
+			service := domain.NewPaymentService(repo)
+			h := &PaymentHandler{
+				service:    service,
+				checkout:   domain.NewCheckoutService(repo, service, "https://pay.test/v1/checkout"),
+				methods:    domain.NewPaymentMethodService(repo, nil),
+				processors: stubProcessors(&stubBank{result: tt.charge, completion: tt.completion}),
+			}
+
+			// The merchant creates a link...
+			req := httptest.NewRequest("POST", "/payment_links", strings.NewReader(
+				`{"line_items":[{"name":"Mug","quantity":2,"unit_amount":1200}],"currency":"USD","success_url":"https://shop.test/thanks"}`))
+			req.Header.Set("X-User-ID", "user_1")
+			req.Header.Set("X-Zone-ID", "zone_1")
+			w := httptest.NewRecorder()
+			h.CreatePaymentLink(w, req)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("create link answered %d: %s", w.Code, w.Body.String())
+			}
+			var link domain.PaymentLink
+			_ = json.Unmarshal(w.Body.Bytes(), &link)
+
+			// ...a customer follows it to a new session...
+			w = httptest.NewRecorder()
+			h.OpenPaymentLink(w, httptest.NewRequest("GET", "/checkout/links/"+link.ID, nil))
+			sessionURL := w.Header().Get("Location")
+			if w.Code != http.StatusSeeOther || !strings.HasPrefix(sessionURL, "https://pay.test/v1/checkout/sessions/") {
+				t.Fatalf("open link answered %d, Location %q", w.Code, sessionURL)
+			}
+			sessionPath := strings.TrimPrefix(sessionURL, "https://pay.test/v1")
+
+			// ...and pays.
+			w = httptest.NewRecorder()
+			h.ConfirmCheckoutSession(w, httptest.NewRequest("POST", sessionPath+"/confirm", strings.NewReader(`{"payment_method_id":"tok_visa"}`)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("confirm answered %d: %s", w.Code, w.Body.String())
+			}
+			var payment checkoutPayment
+			_ = json.Unmarshal(w.Body.Bytes(), &payment)
+			if payment.PaymentStatus != tt.wantStatus || payment.Session.AmountTotal != 2400 {
+				t.Fatalf("payment = %+v, want status %s", payment, tt.wantStatus)
+			}
+
+			if tt.completion != nil {
+				if payment.NextAction == nil || !strings.HasSuffix(payment.NextAction.RedirectURL, "%2Freturn") {
+					t.Fatalf("next action = %+v, want a return to the session", payment.NextAction)
+				}
+				w = httptest.NewRecorder()
+				h.ReturnToCheckoutSession(w, httptest.NewRequest("GET", sessionPath+"/return", nil))
+				if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "https://shop.test/thanks" {
+					t.Fatalf("return answered %d, Location %q", w.Code, w.Header().Get("Location"))
+				}
+			} else if tt.wantCompleted && payment.RedirectURL != "https://shop.test/thanks" {
+				t.Errorf("redirect_url = %q, want the success URL", payment.RedirectURL)
+			}
+
+			if got := len(completed) == 1; got != tt.wantCompleted {
+				t.Errorf("completed sessions = %v, want completed: %v", completed, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestPaymentHandler_PaymentLinkOwnership(t *testing.T) {
+	var completed []string
+	repo := checkoutRepo(&completed)
+	var deactivated []string
+	repo.DeactivatePaymentLinkFunc = func(ctx context.Context, id string) (*domain.PaymentLink, error) {
+		deactivated = append(deactivated, id)
+		return repo.GetPaymentLinkFunc(ctx, id)
+	}
+	service := domain.NewPaymentService(repo)
+	h := &PaymentHandler{service: service, checkout: domain.NewCheckoutService(repo, service, "https://pay.test/v1/checkout")}
+
+	req := httptest.NewRequest("POST", "/payment_links", strings.NewReader(
+		`{"line_items":[{"name":"Mug","quantity":1,"unit_amount":1200}],"currency":"USD","success_url":"https://shop.test/thanks"}`))
+	req.Header.Set("X-User-ID", "user_1")
+	req.Header.Set("X-Zone-ID", "zone_1")
+	w := httptest.NewRecorder()
+	h.CreatePaymentLink(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create link answered %d: %s", w.Code, w.Body.String())
+	}
+	var link domain.PaymentLink
+	_ = json.Unmarshal(w.Body.Bytes(), &link)
+
+	tests := []struct {
+		name           string
+		userID         string
+		handle         func(w http.ResponseWriter, r *http.Request)
+		method         string
+		expectedStatus int
+	}{
+		{"Unauthenticated Get", "", h.GetPaymentLink, "GET", http.StatusUnauthorized},
+		{"Other Merchant Get", "user_2", h.GetPaymentLink, "GET", http.StatusNotFound},
+		{"Other Merchant Deactivate", "user_2", h.DeactivatePaymentLink, "POST", http.StatusNotFound},
+		{"Owner Get", "user_1", h.GetPaymentLink, "GET", http.StatusOK},
+		{"Owner Deactivate", "user_1", h.DeactivatePaymentLink, "POST", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deactivated = nil
+			req := httptest.NewRequest(tt.method, "/payment_links/"+link.ID, nil)
+			req.Header.Set("X-User-ID", tt.userID)
+			w := httptest.NewRecorder()
+
+			tt.handle(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.method == "POST" && (len(deactivated) == 1) != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Expected deactivation only for the owner, deactivated %v", deactivated)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/internal/payment/infrastructure"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+// Disputes are opened and closed by processor webhooks; merchants read them
+// and answer them with evidence.
+
+func (h *PaymentHandler) ListDisputes(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "intents")
+	if id == "" {
+		apierror.BadRequest("Missing Intent ID").Write(w)
+		return
+	}
+
+	disputes, err := h.disputes.ListDisputes(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "Failed to list disputes")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, disputes)
+}
+
+func (h *PaymentHandler) GetDispute(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "disputes")
+	if id == "" {
+		apierror.BadRequest("Missing Dispute ID").Write(w)
+		return
+	}
+
+	dispute, err := h.disputes.GetDispute(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "Failed to get dispute")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, dispute)
+}
+
+// SubmitDisputeEvidence answers a dispute needing a response, which puts it
+// under review.
+func (h *PaymentHandler) SubmitDisputeEvidence(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "disputes")
+	if id == "" {
+		apierror.BadRequest("Missing Dispute ID").Write(w)
+		return
+	}
+
+	var evidence domain.DisputeEvidence
+	if err := json.NewDecoder(r.Body).Decode(&evidence); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	dispute, err := h.disputes.SubmitEvidence(r.Context(), id, evidence, time.Now())
+	if err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("dispute_evidence", "error").Inc()
+		writeError(w, err, "Failed to submit dispute evidence")
+		return
+	}
+
+	infrastructure.PaymentRequests.WithLabelValues("dispute_evidence", "success").Inc()
+	jsonutil.WriteJSON(w, http.StatusOK, dispute)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
+)
+
+func TestPaymentHandler_DisputeLifecycle(t *testing.T) {
+	intent := &domain.PaymentIntent{ID: "pi_123", Amount: 1000, Currency: "USD", Status: domain.StatusSucceeded, UserID: "user_1", ZoneID: "zone_1", Mode: "test"}
+	var stored *domain.Dispute
+	var events []domain.EventType
+	repo := &domain.MockRepository{
+		GetPaymentIntentByProcessorTransactionFunc: func(ctx context.Context, processor, transactionID string) (*domain.PaymentIntent, error) {
+			if processor != "stub" || transactionID != "txn_1" {
+				return nil, nil
+			}
+			return intent, nil
+		},
+		CreateDisputeFunc: func(ctx context.Context, dispute *domain.Dispute) error {
+			if stored != nil {
+				*dispute = *stored
+				return nil
+			}
+			d := *dispute
+			stored = &d
+			events = append(events, domain.EventDisputeCreated)
+			return nil
+		},
+		GetDisputeFunc: func(ctx context.Context, id string) (*domain.Dispute, error) {
+			if stored == nil || stored.ID != id {
+				return nil, nil
+			}
+			d := *stored
+			return &d, nil
+		},
+		GetDisputeByProcessorIDFunc: func(ctx context.Context, processor, processorDisputeID string) (*domain.Dispute, error) {
+			d := *stored
+			return &d, nil
+		},
+		UpdateDisputeFunc: func(ctx context.Context, dispute *domain.Dispute, from domain.DisputeStatus) error {
+			if stored.Status != from {
+				return domain.ErrStatusConflict
+			}
+			d := *dispute
+			stored = &d
+			events = append(events, dispute.Status.Event())
+			return nil
+		},
+	}
+	h := &PaymentHandler{disputes: domain.NewDisputeService(repo), processors: stubProcessors(&stubBank{})}
+
+	webhook := func(body string) int {
+		req := httptest.NewRequest("POST", "/processors/stub/webhooks", strings.NewReader(body))
+		req.Header.Set(bank.SignatureHeader, bank.SignWebhook("whsec_test", []byte(body)))
+		w := httptest.NewRecorder()
+		h.ProcessorWebhook(w, req)
+		return w.Code
+	}
+	submit := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/disputes/"+stored.ID+"/evidence", strings.NewReader(`{"product_description":"Annual plan"}`))
+		w := httptest.NewRecorder()
+		h.SubmitDisputeEvidence(w, req)
+		return w
+	}
+
+	opened := `{"id":"evt_1","type":"charge.dispute.created","transaction_id":"txn_1","amount":1000,"currency":"USD","dispute_id":"dp_sim_1","reason":"fraudulent"}`
+	for i := 0; i < 2; i++ {
+		if code := webhook(opened); code != http.StatusNoContent {
+			t.Fatalf("dispute.created webhook answered %d", code)
+		}
+	}
+	if stored == nil || stored.Status != domain.DisputeNeedsResponse || stored.UserID != "user_1" || stored.EvidenceDueBy.IsZero() {
+		t.Fatalf("stored dispute = %+v", stored)
+	}
+
+	if w := submit(); w.Code != http.StatusOK {
+		t.Fatalf("evidence answered %d: %s", w.Code, w.Body.String())
+	}
+	if stored.Status != domain.DisputeUnderReview || stored.Evidence == nil {
+		t.Errorf("dispute after evidence = %+v", stored)
+	}
+
+	closed := `{"id":"evt_2","type":"charge.dispute.closed","transaction_id":"txn_1","dispute_id":"dp_sim_1","dispute_status":"won"}`
+	if code := webhook(closed); code != http.StatusNoContent {
+		t.Fatalf("dispute.closed webhook answered %d", code)
+	}
+	if w := submit(); w.Code != http.StatusConflict {
+		t.Errorf("evidence for a closed dispute answered %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	want := []domain.EventType{domain.EventDisputeCreated, domain.EventDisputeUnderReview, domain.EventDisputeWon}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("events = %v, want %v", events, want)
+		}
+	}
+}
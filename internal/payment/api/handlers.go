@@ -2,8 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
@@ -14,75 +19,37 @@ import (
 	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
 	"github.com/sapliy/fintech-ecosystem/pkg/bank"
 	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
-	"github.com/sapliy/fintech-ecosystem/pkg/messaging"
-	pb "github.com/sapliy/fintech-ecosystem/proto/ledger"
 )
 
+// PaymentHandler serves the payment intent API. Events are not published
+// here: the repository writes them to the outbox with each change.
 type PaymentHandler struct {
-	service       *domain.PaymentService
-	bankClient    bank.Client
-	rdb           *redis.Client
-	ledgerClient  pb.LedgerServiceClient
-	kafkaProducer *messaging.KafkaProducer
-	rabbitClient  *messaging.RabbitMQClient
+	service    *domain.PaymentService
+	refunds    *domain.RefundService
+	disputes   *domain.DisputeService
+	checkout   *domain.CheckoutService
+	methods    *domain.PaymentMethodService
+	processors *bank.Registry
+	rdb        *redis.Client
 }
 
 func NewPaymentHandler(
 	service *domain.PaymentService,
-	bankClient bank.Client,
+	refunds *domain.RefundService,
+	disputes *domain.DisputeService,
+	checkout *domain.CheckoutService,
+	methods *domain.PaymentMethodService,
+	processors *bank.Registry,
 	rdb *redis.Client,
-	ledgerClient pb.LedgerServiceClient,
-	kafkaProducer *messaging.KafkaProducer,
-	rabbitClient *messaging.RabbitMQClient,
 ) *PaymentHandler {
 	return &PaymentHandler{
-		service:       service,
-		bankClient:    bankClient,
-		rdb:           rdb,
-		ledgerClient:  ledgerClient,
-		kafkaProducer: kafkaProducer,
-		rabbitClient:  rabbitClient,
-	}
-}
-
-// IdempotencyMiddleware wraps a handler to ensure idempotency.
-func (h *PaymentHandler) IdempotencyMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		key := r.Header.Get("Idempotency-Key")
-		if key == "" {
-			next(w, r)
-			return
-		}
-
-		userID, err := authutil.ExtractUserID(r)
-		if err != nil || userID == "" {
-			apierror.Unauthorized("Authentication required for idempotent requests").Write(w)
-			return
-		}
-
-		record, err := h.service.GetIdempotencyKey(r.Context(), userID, key)
-		if err != nil {
-			apierror.Internal("Internal Server Error").Write(w)
-			return
-		}
-		if record != nil {
-			w.Header().Set("X-Idempotency-Hit", "true")
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(record.StatusCode)
-			w.Write([]byte(record.ResponseBody))
-			return
-		}
-
-		recorder := &jsonutil.ResponseRecorder{
-			ResponseWriter: w,
-			StatusCode:     http.StatusOK,
-		}
-
-		next(recorder, r)
-
-		if recorder.StatusCode < 500 {
-			_ = h.service.SaveIdempotencyKey(r.Context(), userID, key, recorder.StatusCode, recorder.Body.String())
-		}
+		service:    service,
+		refunds:    refunds,
+		disputes:   disputes,
+		checkout:   checkout,
+		methods:    methods,
+		processors: processors,
+		rdb:        rdb,
 	}
 }
 
@@ -92,6 +59,12 @@ type CreateIntentRequest struct {
 	Description          string `json:"description"`
 	ApplicationFeeAmount int64  `json:"application_fee_amount"`
 	OnBehalfOf           string `json:"on_behalf_of"`
+	// CustomerID lets the intent be paid with the customer's saved
+	// payment methods.
+	CustomerID string `json:"customer_id"`
+	// CaptureMethod is "automatic" (default) or "manual". A manual intent
+	// is only authorized on confirm and settled by a capture call.
+	CaptureMethod string `json:"capture_method"`
 }
 
 func (h *PaymentHandler) CreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
@@ -119,12 +92,13 @@ func (h *PaymentHandler) CreatePaymentIntent(w http.ResponseWriter, r *http.Requ
 		Mode:                 r.Header.Get("X-Zone-Mode"),
 		ApplicationFeeAmount: req.ApplicationFeeAmount,
 		OnBehalfOf:           req.OnBehalfOf,
-		Status:               "requires_payment_method",
+		CustomerID:           req.CustomerID,
+		CaptureMethod:        domain.CaptureMethod(req.CaptureMethod),
 	}
 
 	if err := h.service.CreatePaymentIntent(r.Context(), intent); err != nil {
 		infrastructure.PaymentRequests.WithLabelValues("create", "error").Inc()
-		apierror.Internal("Failed to create payment intent").Write(w)
+		writeError(w, err, "Failed to create payment intent")
 		return
 	}
 
@@ -155,34 +129,256 @@ func (h *PaymentHandler) ConfirmPaymentIntent(w http.ResponseWriter, r *http.Req
 	}
 
 	var req struct {
+		// PaymentMethodID is a saved payment method or a processor's card
+		// token. It defaults to the default payment method of the intent's
+		// customer.
 		PaymentMethodID string `json:"payment_method_id"`
+		ReturnURL       string `json:"return_url"`
+		// OffSession is set when the customer is not there to
+		// authenticate, as for subscription renewals.
+		OffSession bool `json:"off_session"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		apierror.BadRequest("Invalid request body").Write(w)
 		return
 	}
 
+	intent, outcome, err := h.confirm(r, id, req.PaymentMethodID, req.ReturnURL, req.OffSession)
+	if err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("confirm", "error").Inc()
+		writeError(w, err, "Failed to confirm payment intent")
+		return
+	}
+
+	infrastructure.PaymentRequests.WithLabelValues("confirm", outcome).Inc()
+	jsonutil.WriteJSON(w, http.StatusOK, intent)
+}
+
+// confirm charges or authorizes an intent with the payment method through
+// its zone's processor and returns the intent with the outcome for
+// metrics. Automatic intents are charged at once; manual ones are
+// authorized and wait for a capture. An off-session payment that asks for
+// authentication fails like a decline, since nobody is there to
+// authenticate.
+func (h *PaymentHandler) confirm(r *http.Request, id, paymentMethodID, returnURL string, offSession bool) (*domain.PaymentIntent, string, error) {
+	intent, err := h.service.GetPaymentIntent(r.Context(), id)
+	if err != nil {
+		return nil, "", err
+	}
+	if intent == nil {
+		return nil, "", domain.ErrPaymentIntentNotFound
+	}
+	cardToken, err := h.methods.CardToken(r.Context(), intent, paymentMethodID)
+	if err != nil {
+		return nil, "", err
+	}
+	if intent, err = h.service.UpdateStatus(r.Context(), id, domain.StatusProcessing, ""); err != nil {
+		return nil, "", err
+	}
+
+	var result *bank.TransactionResult
+	processor, client, err := h.processors.ForZone(intent.ZoneID)
+	if err == nil {
+		intent.Processor = processor
+		charge := client.Charge
+		if intent.CaptureMethod == domain.CaptureMethodManual {
+			charge = client.Authorize
+		}
+		result, err = charge(r.Context(), intent.Amount, intent.Currency, cardToken)
+	}
+
+	if err == nil && result.Status == bank.StatusRequiresAction && offSession {
+		result = &bank.TransactionResult{Status: bank.StatusFailed, ErrorCode: "authentication_required"}
+	}
+	if err == nil && result.Status == bank.StatusRequiresAction {
+		// The customer authenticates at the processor and comes back to
+		// return_url; the intent waits in requires_action until then.
+		redirectURL := withReturnURL(result.RedirectURL, returnURL)
+		err = h.service.MarkRequiresAction(r.Context(), intent, result.ChallengeID, redirectURL, time.Now().Add(domain.ActionTTL))
+		return intent, "requires_action", err
+	}
+	return h.finishAttempt(r, intent, result, err)
+}
+
+// CompletePaymentIntentAction completes the authentication challenge of an
+// intent in requires_action once the customer is back from the processor.
+// The client secret stands in for the merchant's credentials.
+func (h *PaymentHandler) CompletePaymentIntentAction(w http.ResponseWriter, r *http.Request) {
+	timer := prometheus.NewTimer(infrastructure.PaymentLatency.WithLabelValues("complete_action"))
+	defer timer.ObserveDuration()
+
+	id := jsonutil.GetIDAfter(r, "intents")
+	if id == "" {
+		apierror.BadRequest("Missing Intent ID").Write(w)
+		return
+	}
+
+	var req struct {
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ClientSecret == "" {
+		apierror.BadRequest("client_secret is required").Write(w)
+		return
+	}
+
 	intent, err := h.service.GetPaymentIntent(r.Context(), id)
-	if err != nil || intent == nil {
+	if err != nil {
+		apierror.Internal("Failed to get payment intent").Write(w)
+		return
+	}
+	if intent == nil {
 		apierror.NotFound("Payment intent not found").Write(w)
 		return
 	}
 
-	if _, err := h.bankClient.Charge(r.Context(), intent.Amount, intent.Currency, req.PaymentMethodID); err != nil {
-		_ = h.service.UpdateStatus(r.Context(), id, "FAILED")
-		jsonutil.WriteJSON(w, http.StatusOK, map[string]string{"status": "failed", "reason": "Bank declined"})
+	intent, outcome, err := h.completeAction(r, intent, req.ClientSecret)
+	if err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("complete_action", "error").Inc()
+		writeError(w, err, "Failed to complete action")
 		return
 	}
 
-	if err := h.service.UpdateStatus(r.Context(), id, "SUCCEEDED"); err != nil {
-		apierror.Internal("Failed to update status").Write(w)
+	infrastructure.PaymentRequests.WithLabelValues("complete_action", outcome).Inc()
+	jsonutil.WriteJSON(w, http.StatusOK, intent)
+}
+
+// completeAction asks the intent's processor for the outcome of its
+// challenge and returns the intent with the outcome for metrics.
+func (h *PaymentHandler) completeAction(r *http.Request, intent *domain.PaymentIntent, clientSecret string) (*domain.PaymentIntent, string, error) {
+	client, err := h.processors.Get(intent.Processor)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := h.service.ResumeAction(r.Context(), intent, clientSecret, time.Now()); err != nil {
+		return nil, "", err
+	}
+
+	action := *intent.NextAction
+	result, err := client.CompleteChallenge(r.Context(), intent.ChallengeID)
+	if err == nil && result.Status == bank.StatusRequiresAction {
+		// The customer has not finished the challenge yet; it stays open
+		// until its original deadline.
+		err = h.service.MarkRequiresAction(r.Context(), intent, intent.ChallengeID, action.RedirectURL, action.ExpiresAt)
+		return intent, "requires_action", err
+	}
+	return h.finishAttempt(r, intent, result, err)
+}
+
+// finishAttempt applies the processor's final answer to a processing intent
+// and returns the intent with the outcome for metrics. A declined attempt
+// returns the intent to requires_payment_method so it can be confirmed again
+// with another payment method.
+func (h *PaymentHandler) finishAttempt(r *http.Request, intent *domain.PaymentIntent, result *bank.TransactionResult, err error) (*domain.PaymentIntent, string, error) {
+	switch {
+	case err != nil || result.Status != bank.StatusSuccess:
+		reason := "Bank declined"
+		if err != nil {
+			reason = err.Error()
+		} else if result.ErrorCode != "" {
+			reason += ": " + result.ErrorCode
+		}
+		intent, err = h.service.UpdateStatus(r.Context(), intent.ID, domain.StatusRequiresPaymentMethod, reason)
+		return intent, "declined", err
+	case intent.CaptureMethod == domain.CaptureMethodManual:
+		return intent, "authorized", h.service.MarkAuthorized(r.Context(), intent, result.TransactionID, time.Now())
+	default:
+		intent.ProcessorTransactionID = result.TransactionID
+		if err := h.service.MarkCaptured(r.Context(), intent, intent.Amount); err != nil {
+			return intent, "success", err
+		}
+		// The checkout session paid for by the intent, if any, completes
+		// with it. A session missed here is completed by the expiry
+		// worker instead.
+		if _, err := h.checkout.CompleteSession(r.Context(), intent, time.Now()); err != nil {
+			log.Printf("Failed to complete checkout session of payment intent %s: %v", intent.ID, err)
+		}
+		return intent, "success", nil
+	}
+}
+
+// withReturnURL passes the merchant's return URL on to the challenge page,
+// which sends the customer back there when done.
+func withReturnURL(redirectURL, returnURL string) string {
+	if returnURL == "" {
+		return redirectURL
+	}
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return redirectURL
+	}
+	q := u.Query()
+	q.Set("return_url", returnURL)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// CapturePaymentIntent settles an authorized manual-capture intent. The
+// amount defaults to everything authorized; a smaller amount releases the
+// rest.
+func (h *PaymentHandler) CapturePaymentIntent(w http.ResponseWriter, r *http.Request) {
+	timer := prometheus.NewTimer(infrastructure.PaymentLatency.WithLabelValues("capture"))
+	defer timer.ObserveDuration()
+
+	id := jsonutil.GetIDAfter(r, "intents")
+	if id == "" {
+		apierror.BadRequest("Missing Intent ID").Write(w)
+		return
+	}
+
+	var req struct {
+		AmountToCapture int64 `json:"amount_to_capture"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.BadRequest("Invalid request body").Write(w)
+			return
+		}
+	}
+
+	intent, err := h.service.GetPaymentIntent(r.Context(), id)
+	if err != nil {
+		apierror.Internal("Failed to get payment intent").Write(w)
+		return
+	}
+	if intent == nil {
+		apierror.NotFound("Payment intent not found").Write(w)
+		return
+	}
+	amount, err := domain.CaptureAmount(intent, req.AmountToCapture, time.Now())
+	if err != nil {
+		writeError(w, err, "Failed to capture payment intent")
 		return
 	}
 
-	infrastructure.PaymentRequests.WithLabelValues("confirm", "success").Inc()
+	client, err := h.processors.Get(intent.Processor)
+	if err != nil {
+		apierror.Internal("Payment processor unavailable").Write(w)
+		return
+	}
+	result, err := client.Capture(r.Context(), intent.AuthorizationID, amount)
+	if err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("capture", "error").Inc()
+		if errors.Is(err, bank.ErrAuthorizationClosed) || errors.Is(err, bank.ErrAuthorizationNotFound) {
+			apierror.Conflict("Authorization can no longer be captured").Write(w)
+			return
+		}
+		apierror.Internal("Bank capture failed").Write(w)
+		return
+	}
+	intent.ProcessorTransactionID = result.TransactionID
+	if err := h.service.MarkCaptured(r.Context(), intent, amount); err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("capture", "error").Inc()
+		writeError(w, err, "Failed to update status")
+		return
+	}
+
+	infrastructure.PaymentRequests.WithLabelValues("capture", "success").Inc()
 	jsonutil.WriteJSON(w, http.StatusOK, intent)
 }
 
+// RefundPaymentIntent refunds part of a payment, or everything left when no
+// amount is given. It is served at both /intents/{id}/refunds and the older
+// /intents/{id}/refund.
 func (h *PaymentHandler) RefundPaymentIntent(w http.ResponseWriter, r *http.Request) {
 	id := jsonutil.GetIDAfter(r, "intents")
 	if id == "" {
@@ -190,13 +386,163 @@ func (h *PaymentHandler) RefundPaymentIntent(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// For simplified logic, mapping refund to CANCELLED or a new REFUNDED if added to domain
-	if err := h.service.UpdateStatus(r.Context(), id, "CANCELLED"); err != nil {
-		apierror.Internal("Failed to update refund status").Write(w)
+	var req struct {
+		Amount int64  `json:"amount"`
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.BadRequest("Invalid request body").Write(w)
+			return
+		}
+	}
+
+	refund, err := h.refunds.CreateRefund(r.Context(), id, req.Amount, req.Reason)
+	if errors.Is(err, domain.ErrRefundPending) {
+		// The refund is retried in the background; its status shows when
+		// it completes.
+		infrastructure.PaymentRequests.WithLabelValues("refund", "pending").Inc()
+		jsonutil.WriteJSON(w, http.StatusAccepted, refund)
+		return
+	}
+	if err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("refund", "error").Inc()
+		writeError(w, err, "Failed to refund payment intent")
 		return
 	}
 
-	jsonutil.WriteJSON(w, http.StatusOK, map[string]string{"status": "refunded"})
+	infrastructure.PaymentRequests.WithLabelValues("refund", "success").Inc()
+	jsonutil.WriteJSON(w, http.StatusCreated, refund)
+}
+
+func (h *PaymentHandler) ListRefunds(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "intents")
+	if id == "" {
+		apierror.BadRequest("Missing Intent ID").Write(w)
+		return
+	}
+
+	refunds, err := h.refunds.ListRefunds(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "Failed to list refunds")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, refunds)
+}
+
+func (h *PaymentHandler) CancelPaymentIntent(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "intents")
+	if id == "" {
+		apierror.BadRequest("Missing Intent ID").Write(w)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"cancellation_reason"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.BadRequest("Invalid request body").Write(w)
+			return
+		}
+	}
+
+	intent, err := h.service.GetPaymentIntent(r.Context(), id)
+	if err != nil {
+		apierror.Internal("Failed to get payment intent").Write(w)
+		return
+	}
+	if intent == nil {
+		apierror.NotFound("Payment intent not found").Write(w)
+		return
+	}
+
+	// An authorized intent releases its hold at the bank first.
+	if intent.Status == domain.StatusRequiresCapture && intent.AuthorizationID != "" {
+		client, err := h.processors.Get(intent.Processor)
+		if err != nil {
+			apierror.Internal("Payment processor unavailable").Write(w)
+			return
+		}
+		if _, err := client.Void(r.Context(), intent.AuthorizationID); err != nil {
+			if errors.Is(err, bank.ErrAuthorizationClosed) || errors.Is(err, bank.ErrAuthorizationNotFound) {
+				apierror.Conflict("Authorization can no longer be voided").Write(w)
+				return
+			}
+			apierror.Internal("Bank void failed").Write(w)
+			return
+		}
+		err = h.service.MarkVoided(r.Context(), intent, req.Reason)
+	} else {
+		intent, err = h.service.UpdateStatus(r.Context(), id, domain.StatusCanceled, req.Reason)
+	}
+	if err != nil {
+		writeError(w, err, "Failed to cancel payment intent")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, intent)
+}
+
+func (h *PaymentHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id := jsonutil.GetIDAfter(r, "intents")
+	if id == "" {
+		apierror.BadRequest("Missing Intent ID").Write(w)
+		return
+	}
+
+	history, err := h.service.GetStatusHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, err, "Failed to get status history")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, history)
+}
+
+// ProcessorWebhook receives the webhooks a processor sends to
+// /processors/{name}/webhooks. Settlements are recorded on their intent; an
+// intent not found yet is answered with 404 so the processor retries.
+func (h *PaymentHandler) ProcessorWebhook(w http.ResponseWriter, r *http.Request) {
+	name := jsonutil.GetIDAfter(r, "processors")
+	secret, err := h.processors.WebhookSecret(name)
+	if err != nil {
+		apierror.NotFound("Unknown processor").Write(w)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+	event, err := bank.ParseWebhook(secret, body, r.Header.Get(bank.SignatureHeader))
+	if errors.Is(err, bank.ErrInvalidSignature) {
+		apierror.Unauthorized("Invalid webhook signature").Write(w)
+		return
+	}
+	if err != nil {
+		apierror.BadRequest(err.Error()).Write(w)
+		return
+	}
+
+	switch event.Type {
+	case bank.EventChargeSettled:
+		err = h.service.MarkSettled(r.Context(), name, event.TransactionID, event.CreatedAt)
+	case bank.EventDisputeCreated:
+		var dueBy time.Time
+		if event.EvidenceDueBy != nil {
+			dueBy = *event.EvidenceDueBy
+		}
+		_, err = h.disputes.OpenDispute(r.Context(), name, event.TransactionID, event.DisputeID, event.Amount, event.Reason, dueBy)
+	case bank.EventDisputeClosed:
+		_, err = h.disputes.CloseDispute(r.Context(), name, event.DisputeID, domain.DisputeStatus(event.DisputeStatus))
+	}
+	if err != nil {
+		writeError(w, err, "Failed to process "+string(event.Type)+" webhook")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *PaymentHandler) ListPaymentIntents(w http.ResponseWriter, r *http.Request) {
@@ -215,3 +561,36 @@ func (h *PaymentHandler) ListPaymentIntents(w http.ResponseWriter, r *http.Reque
 
 	jsonutil.WriteJSON(w, http.StatusOK, intents)
 }
+
+func writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case domain.IsValidationError(err):
+		apierror.BadRequest(err.Error()).Write(w)
+	case errors.Is(err, domain.ErrPaymentIntentNotFound):
+		apierror.NotFound("Payment intent not found").Write(w)
+	case errors.Is(err, domain.ErrDisputeNotFound):
+		apierror.NotFound("Dispute not found").Write(w)
+	case errors.Is(err, domain.ErrCheckoutSessionNotFound):
+		apierror.NotFound("Checkout session not found").Write(w)
+	case errors.Is(err, domain.ErrPaymentLinkNotFound):
+		apierror.NotFound("Payment link not found").Write(w)
+	case errors.Is(err, domain.ErrCustomerNotFound):
+		apierror.NotFound("Customer not found").Write(w)
+	case errors.Is(err, domain.ErrPaymentMethodNotFound):
+		apierror.NotFound("Payment method not found").Write(w)
+	case errors.Is(err, domain.ErrConnectedAccountNotFound):
+		apierror.NotFound("Connected account not found").Write(w)
+	case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrStatusConflict),
+		errors.Is(err, domain.ErrAuthorizationExpired), errors.Is(err, domain.ErrRefundExceedsAmount),
+		errors.Is(err, domain.ErrActionExpired), errors.Is(err, domain.ErrPaymentDisputed),
+		errors.Is(err, domain.ErrEvidenceDeadlinePassed), errors.Is(err, domain.ErrCheckoutSessionClosed),
+		errors.Is(err, domain.ErrPaymentLinkInactive), errors.Is(err, domain.ErrPaymentMethodUnavailable):
+		apierror.Conflict(err.Error()).Write(w)
+	case errors.Is(err, domain.ErrInvalidClientSecret):
+		apierror.Forbidden(err.Error()).Write(w)
+	case errors.Is(err, domain.ErrRefundFailed):
+		apierror.ServiceUnavailable("Refund was declined by the processor").Write(w)
+	default:
+		apierror.Internal(msg).Write(w)
+	}
+}
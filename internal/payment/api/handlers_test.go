@@ -2,12 +2,15 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
 )
 
 func TestPaymentHandler_CreatePaymentIntent(t *testing.T) {
@@ -67,56 +70,405 @@ func TestPaymentHandler_CreatePaymentIntent(t *testing.T) {
 	}
 }
 
-func TestPaymentHandler_IdempotencyMiddleware(t *testing.T) {
+// stubBank answers every bank call with result, completes challenges with
+// completion and records charges and captures.
+type stubBank struct {
+	result     *bank.TransactionResult
+	completion *bank.TransactionResult
+	charged    string
+	captured   int64
+}
+
+func (b *stubBank) Charge(ctx context.Context, amount int64, currency, cardToken string) (*bank.TransactionResult, error) {
+	b.charged = cardToken
+	return b.result, nil
+}
+
+func (b *stubBank) Authorize(ctx context.Context, amount int64, currency, cardToken string) (*bank.TransactionResult, error) {
+	return b.result, nil
+}
+
+func (b *stubBank) Capture(ctx context.Context, authorizationID string, amount int64) (*bank.TransactionResult, error) {
+	b.captured = amount
+	return b.result, nil
+}
+
+func (b *stubBank) Void(ctx context.Context, authorizationID string) (*bank.TransactionResult, error) {
+	return b.result, nil
+}
+
+func (b *stubBank) CompleteChallenge(ctx context.Context, challengeID string) (*bank.TransactionResult, error) {
+	return b.completion, nil
+}
+
+func (b *stubBank) Refund(ctx context.Context, transactionID, refundID string, amount int64) (*bank.TransactionResult, error) {
+	return b.result, nil
+}
+
+// stubProcessors routes every zone to client.
+func stubProcessors(client bank.Client) *bank.Registry {
+	processors := bank.NewRegistry("stub")
+	processors.Register("stub", client, "whsec_test")
+	return processors
+}
+
+// intentRepo keeps one intent in memory and records its transitions. No
+// checkout session pays for it.
+func intentRepo(current *domain.PaymentIntent, path *[]domain.Status) *domain.MockRepository {
+	return &domain.MockRepository{
+		GetCheckoutSessionByPaymentIntentFunc: func(ctx context.Context, intentID string) (*domain.CheckoutSession, error) {
+			return nil, nil
+		},
+		GetPaymentIntentFunc: func(ctx context.Context, id string) (*domain.PaymentIntent, error) {
+			intent := *current
+			return &intent, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, intent *domain.PaymentIntent, tr domain.Transition) error {
+			*path = append(*path, tr.To)
+			*current = *intent
+			current.Status = tr.To
+			return nil
+		},
+	}
+}
+
+func TestPaymentHandler_ConfirmPaymentIntent(t *testing.T) {
 	tests := []struct {
 		name           string
-		headers        map[string]string
-		mockSetup      func(*domain.MockRepository)
+		status         domain.Status
+		captureMethod  domain.CaptureMethod
+		charge         *bank.TransactionResult
+		offSession     bool
 		expectedStatus int
-		expectedHit    bool
+		expectedPath   []domain.Status
 	}{
 		{
-			name:    "New Request - Saves Key",
-			headers: map[string]string{"Idempotency-Key": "key_1", "X-User-ID": "user_1"},
-			mockSetup: func(m *domain.MockRepository) {
-				m.GetIdempotencyKeyFunc = func(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
-					return nil, nil // Not found
+			name:           "Charge succeeds",
+			status:         domain.StatusRequiresPaymentMethod,
+			captureMethod:  domain.CaptureMethodAutomatic,
+			charge:         &bank.TransactionResult{TransactionID: "txn_1", Status: bank.StatusSuccess},
+			expectedStatus: http.StatusOK,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusSucceeded},
+		},
+		{
+			name:           "Charge declined",
+			status:         domain.StatusRequiresPaymentMethod,
+			captureMethod:  domain.CaptureMethodAutomatic,
+			charge:         &bank.TransactionResult{Status: bank.StatusFailed, ErrorCode: "card_declined"},
+			expectedStatus: http.StatusOK,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusRequiresPaymentMethod},
+		},
+		{
+			name:           "Manual capture authorizes",
+			status:         domain.StatusRequiresPaymentMethod,
+			captureMethod:  domain.CaptureMethodManual,
+			charge:         &bank.TransactionResult{TransactionID: "auth_1", Status: bank.StatusSuccess},
+			expectedStatus: http.StatusOK,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusRequiresCapture},
+		},
+		{
+			name:           "Authentication required",
+			status:         domain.StatusRequiresPaymentMethod,
+			captureMethod:  domain.CaptureMethodAutomatic,
+			charge:         &bank.TransactionResult{Status: bank.StatusRequiresAction, ChallengeID: "3ds_1", RedirectURL: "https://sim.test/3ds/3ds_1"},
+			expectedStatus: http.StatusOK,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusRequiresAction},
+		},
+		{
+			name:           "Authentication required off-session",
+			status:         domain.StatusRequiresPaymentMethod,
+			captureMethod:  domain.CaptureMethodAutomatic,
+			charge:         &bank.TransactionResult{Status: bank.StatusRequiresAction, ChallengeID: "3ds_1", RedirectURL: "https://sim.test/3ds/3ds_1"},
+			offSession:     true,
+			expectedStatus: http.StatusOK,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusRequiresPaymentMethod},
+		},
+		{
+			name:           "Already succeeded",
+			status:         domain.StatusSucceeded,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &domain.PaymentIntent{ID: "pi_123", Amount: 1000, Currency: "USD", Status: tt.status, CaptureMethod: tt.captureMethod}
+			var path []domain.Status
+			repo := intentRepo(current, &path)
+			service := domain.NewPaymentService(repo)
+			h := &PaymentHandler{
+				service:    service,
+				checkout:   domain.NewCheckoutService(repo, service, ""),
+				methods:    domain.NewPaymentMethodService(repo, nil),
+				processors: stubProcessors(&stubBank{result: tt.charge}),
+			}
+
+			body := fmt.Sprintf(`{"payment_method_id":"tok_visa","return_url":"https://shop.test/done","off_session":%t}`, tt.offSession)
+			req := httptest.NewRequest("POST", "/intents/pi_123/confirm", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			h.ConfirmPaymentIntent(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if len(path) != len(tt.expectedPath) {
+				t.Fatalf("Expected transitions %v, got %v", tt.expectedPath, path)
+			}
+			for i := range path {
+				if path[i] != tt.expectedPath[i] {
+					t.Errorf("Expected transitions %v, got %v", tt.expectedPath, path)
 				}
-				m.SaveIdempotencyKeyFunc = func(ctx context.Context, userID, key string, statusCode int, body string) error {
-					return nil
+			}
+			if tt.expectedStatus == http.StatusOK && tt.charge.Status == bank.StatusSuccess && current.Processor != "stub" {
+				t.Errorf("Expected the intent to record processor stub, got %q", current.Processor)
+			}
+			if tt.status == domain.StatusRequiresPaymentMethod && tt.charge.Status == bank.StatusRequiresAction && !tt.offSession {
+				want := "https://sim.test/3ds/3ds_1?return_url=https%3A%2F%2Fshop.test%2Fdone"
+				if current.NextAction == nil || current.NextAction.RedirectURL != want || current.ClientSecret == "" || current.ChallengeID != "3ds_1" {
+					t.Errorf("Expected a redirect to %s with a client secret, got %+v", want, current)
 				}
-			},
+			}
+		})
+	}
+}
+
+func TestPaymentHandler_CompletePaymentIntentAction(t *testing.T) {
+	tests := []struct {
+		name           string
+		secret         string
+		expiresIn      time.Duration
+		completion     *bank.TransactionResult
+		expectedStatus int
+		expectedPath   []domain.Status
+	}{
+		{
+			name:           "Challenge passed",
+			secret:         "pi_123_secret_abc",
+			expiresIn:      time.Minute,
+			completion:     &bank.TransactionResult{TransactionID: "txn_1", Status: bank.StatusSuccess},
 			expectedStatus: http.StatusOK,
-			expectedHit:    false,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusSucceeded},
+		},
+		{
+			name:           "Challenge failed",
+			secret:         "pi_123_secret_abc",
+			expiresIn:      time.Minute,
+			completion:     &bank.TransactionResult{Status: bank.StatusFailed, ErrorCode: "authentication_failed"},
+			expectedStatus: http.StatusOK,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusRequiresPaymentMethod},
+		},
+		{
+			name:           "Challenge still pending",
+			secret:         "pi_123_secret_abc",
+			expiresIn:      time.Minute,
+			completion:     &bank.TransactionResult{Status: bank.StatusRequiresAction, ChallengeID: "3ds_1"},
+			expectedStatus: http.StatusOK,
+			expectedPath:   []domain.Status{domain.StatusProcessing, domain.StatusRequiresAction},
+		},
+		{
+			name:           "Wrong client secret",
+			secret:         "pi_123_secret_xyz",
+			expiresIn:      time.Minute,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Challenge expired",
+			secret:         "pi_123_secret_abc",
+			expiresIn:      -time.Minute,
+			expectedStatus: http.StatusConflict,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mRepo := &domain.MockRepository{}
-			tt.mockSetup(mRepo)
-			service := domain.NewPaymentService(mRepo)
-			h := &PaymentHandler{service: service}
+			expiresAt := time.Now().Add(tt.expiresIn)
+			current := &domain.PaymentIntent{
+				ID: "pi_123", Amount: 1000, Currency: "USD", Status: domain.StatusRequiresAction,
+				CaptureMethod: domain.CaptureMethodAutomatic, Processor: "stub",
+				ClientSecret: "pi_123_secret_abc", ChallengeID: "3ds_1",
+				NextAction: &domain.NextAction{Type: domain.NextActionRedirectToURL, RedirectURL: "https://sim.test/3ds/3ds_1", ExpiresAt: expiresAt},
+			}
+			var path []domain.Status
+			repo := intentRepo(current, &path)
+			service := domain.NewPaymentService(repo)
+			h := &PaymentHandler{service: service, checkout: domain.NewCheckoutService(repo, service, ""), processors: stubProcessors(&stubBank{completion: tt.completion})}
 
-			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusOK)
-				_, _ = w.Write([]byte("ok"))
-			})
+			req := httptest.NewRequest("POST", "/intents/pi_123/complete_action", strings.NewReader(`{"client_secret":"`+tt.secret+`"}`))
+			w := httptest.NewRecorder()
 
-			req := httptest.NewRequest("POST", "/test", nil)
-			for k, v := range tt.headers {
-				req.Header.Set(k, v)
+			h.CompletePaymentIntentAction(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if fmt.Sprint(path) != fmt.Sprint(tt.expectedPath) {
+				t.Fatalf("Expected transitions %v, got %v", tt.expectedPath, path)
+			}
+			switch current.Status {
+			case domain.StatusRequiresAction:
+				if current.NextAction == nil || !current.NextAction.ExpiresAt.Equal(expiresAt) {
+					t.Errorf("Expected the challenge to keep its deadline, got %+v", current.NextAction)
+				}
+			default:
+				if current.NextAction != nil || current.ChallengeID != "" {
+					t.Errorf("Expected the challenge to be cleared, got %+v", current.NextAction)
+				}
+			}
+		})
+	}
+}
+
+func TestPaymentHandler_CapturePaymentIntent(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		status           domain.Status
+		captureBefore    *time.Time
+		reqBody          string
+		expectedStatus   int
+		expectedCaptured int64
+	}{
+		{"Full capture", domain.StatusRequiresCapture, &future, ``, http.StatusOK, 1000},
+		{"Partial capture", domain.StatusRequiresCapture, &future, `{"amount_to_capture":400}`, http.StatusOK, 400},
+		{"Over capture", domain.StatusRequiresCapture, &future, `{"amount_to_capture":1001}`, http.StatusBadRequest, 0},
+		{"Expired authorization", domain.StatusRequiresCapture, &past, ``, http.StatusConflict, 0},
+		{"Not authorized", domain.StatusRequiresPaymentMethod, nil, ``, http.StatusConflict, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &domain.PaymentIntent{
+				ID: "pi_123", Amount: 1000, AmountCapturable: 1000, Currency: "USD", Status: tt.status,
+				CaptureMethod: domain.CaptureMethodManual, AuthorizationID: "auth_1", CaptureBefore: tt.captureBefore,
 			}
+			var path []domain.Status
+			bankClient := &stubBank{result: &bank.TransactionResult{TransactionID: "txn_1", Status: bank.StatusSuccess}}
+			h := &PaymentHandler{service: domain.NewPaymentService(intentRepo(current, &path)), processors: stubProcessors(bankClient)}
+
+			req := httptest.NewRequest("POST", "/intents/pi_123/capture", strings.NewReader(tt.reqBody))
 			w := httptest.NewRecorder()
 
-			h.IdempotencyMiddleware(next)(w, req)
+			h.CapturePaymentIntent(w, req)
 
 			if w.Code != tt.expectedStatus {
-				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if bankClient.captured != tt.expectedCaptured {
+				t.Errorf("Expected bank capture of %d, got %d", tt.expectedCaptured, bankClient.captured)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				if current.Status != domain.StatusSucceeded || current.AmountReceived != tt.expectedCaptured || current.AmountCapturable != 0 {
+					t.Errorf("Expected succeeded intent with %d received, got %+v", tt.expectedCaptured, current)
+				}
+			}
+		})
+	}
+}
+
+func TestPaymentHandler_RefundPaymentIntent(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         domain.Status
+		reqBody        string
+		expectedStatus int
+		expectedIntent domain.Status
+	}{
+		{"Full refund", domain.StatusSucceeded, ``, http.StatusCreated, domain.StatusRefunded},
+		{"Partial refund", domain.StatusSucceeded, `{"amount":250,"reason":"requested_by_customer"}`, http.StatusCreated, domain.StatusPartiallyRefunded},
+		{"Exceeds received", domain.StatusSucceeded, `{"amount":1001}`, http.StatusConflict, domain.StatusSucceeded},
+		{"Unknown reason", domain.StatusSucceeded, `{"reason":"changed_mind"}`, http.StatusBadRequest, domain.StatusSucceeded},
+		{"Not paid", domain.StatusRequiresPaymentMethod, ``, http.StatusConflict, domain.StatusRequiresPaymentMethod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &domain.PaymentIntent{ID: "pi_123", Amount: 1000, AmountReceived: 1000, Currency: "USD", Status: tt.status}
+			repo := &domain.MockRepository{
+				CreateRefundFunc: func(ctx context.Context, refund *domain.Refund) error {
+					amount, err := domain.RefundAmount(current, 0, refund.Amount)
+					refund.Amount = amount
+					return err
+				},
+				GetPaymentIntentFunc: func(ctx context.Context, id string) (*domain.PaymentIntent, error) {
+					return current, nil
+				},
+				CompleteRefundFunc: func(ctx context.Context, refund *domain.Refund) error {
+					tr, err := domain.RefundTransition(current, refund)
+					current.Status = tr.To
+					return err
+				},
+			}
+			ledger := &domain.MockLedger{
+				ReversePaymentFunc: func(ctx context.Context, payment *domain.PaymentIntent, reference string, amount int64, reason string) (string, error) {
+					return "txn_1", nil
+				},
+			}
+			processors := &domain.MockProcessors{
+				RefundPaymentFunc: func(ctx context.Context, payment *domain.PaymentIntent, refundID string, amount int64) (string, error) {
+					return "re_" + refundID, nil
+				},
+			}
+			h := &PaymentHandler{refunds: domain.NewRefundService(repo, ledger, processors)}
+
+			req := httptest.NewRequest("POST", "/intents/pi_123/refunds", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+
+			h.RefundPaymentIntent(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if current.Status != tt.expectedIntent {
+				t.Errorf("Expected intent status %s, got %s", tt.expectedIntent, current.Status)
+			}
+		})
+	}
+}
+
+func TestPaymentHandler_ProcessorWebhook(t *testing.T) {
+	event := `{"id":"evt_1","type":"charge.settled","transaction_id":"txn_1","amount":1000,"currency":"USD"}`
+
+	tests := []struct {
+		name           string
+		path           string
+		signature      string
+		known          bool
+		expectedStatus int
+	}{
+		{"Settlement recorded", "/processors/stub/webhooks", bank.SignWebhook("whsec_test", []byte(event)), true, http.StatusNoContent},
+		{"Intent not found yet", "/processors/stub/webhooks", bank.SignWebhook("whsec_test", []byte(event)), false, http.StatusNotFound},
+		{"Bad signature", "/processors/stub/webhooks", bank.SignWebhook("whsec_other", []byte(event)), true, http.StatusUnauthorized},
+		{"Unknown processor", "/processors/acme/webhooks", bank.SignWebhook("whsec_test", []byte(event)), true, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settled string
+			repo := &domain.MockRepository{
+				MarkSettledFunc: func(ctx context.Context, processor, transactionID string, at time.Time) error {
+					if !tt.known {
+						return domain.ErrPaymentIntentNotFound
+					}
+					settled = processor + "/" + transactionID
+					return nil
+				},
+			}
+			h := &PaymentHandler{service: domain.NewPaymentService(repo), processors: stubProcessors(&stubBank{})}
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(event))
+			req.Header.Set(bank.SignatureHeader, tt.signature)
+			w := httptest.NewRecorder()
+
+			h.ProcessorWebhook(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
 			}
-			hit := w.Header().Get("X-Idempotency-Hit") == "true"
-			if hit != tt.expectedHit {
-				t.Errorf("Expected X-Idempotency-Hit %v, got %v", tt.expectedHit, hit)
+			if tt.expectedStatus == http.StatusNoContent && settled != "stub/txn_1" {
+				t.Errorf("Expected stub/txn_1 to be settled, got %q", settled)
 			}
 		})
 	}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/internal/payment/infrastructure"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+// Merchants keep their customers under /customers and tokenize cards into
+// payment methods under /payment_methods. Customers and payment methods of
+// other merchants are reported as not found.
+
+func (h *PaymentHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	customer := &domain.Customer{
+		Email:  req.Email,
+		Name:   req.Name,
+		UserID: userID,
+		ZoneID: r.Header.Get("X-Zone-ID"),
+		Mode:   r.Header.Get("X-Zone-Mode"),
+	}
+	if err := h.methods.CreateCustomer(r.Context(), customer); err != nil {
+		writeError(w, err, "Failed to create customer")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, customer)
+}
+
+func (h *PaymentHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.ownCustomer(w, r)
+	if !ok {
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, customer)
+}
+
+// ListCustomerPaymentMethods lists the payment methods attached to a
+// customer.
+func (h *PaymentHandler) ListCustomerPaymentMethods(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.ownCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	methods, err := h.methods.ListPaymentMethods(r.Context(), customer.ID)
+	if err != nil {
+		writeError(w, err, "Failed to list payment methods")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, methods)
+}
+
+// ownCustomer loads the customer named in the path if it belongs to the
+// caller, and writes the error response otherwise.
+func (h *PaymentHandler) ownCustomer(w http.ResponseWriter, r *http.Request) (*domain.Customer, bool) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return nil, false
+	}
+	id := jsonutil.GetIDAfter(r, "customers")
+	if id == "" {
+		apierror.BadRequest("Missing Customer ID").Write(w)
+		return nil, false
+	}
+
+	customer, err := h.methods.GetCustomer(r.Context(), id)
+	if err == nil && customer.UserID != userID {
+		err = domain.ErrCustomerNotFound
+	}
+	if err != nil {
+		writeError(w, err, "Failed to get customer")
+		return nil, false
+	}
+	return customer, true
+}
+
+// CreatePaymentMethod tokenizes a card. Only its brand, last four digits
+// and expiry are returned; the payment method's ID stands for the card
+// from then on. A customer_id attaches it to that customer right away.
+func (h *PaymentHandler) CreatePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return
+	}
+
+	var req struct {
+		Type       string             `json:"type"`
+		Card       domain.CardDetails `json:"card"`
+		CustomerID string             `json:"customer_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+	if req.Type != "" && req.Type != domain.PaymentMethodTypeCard {
+		apierror.BadRequest("type must be card").Write(w)
+		return
+	}
+
+	pm := &domain.PaymentMethod{
+		UserID: userID,
+		ZoneID: r.Header.Get("X-Zone-ID"),
+		Mode:   r.Header.Get("X-Zone-Mode"),
+	}
+	if err := h.methods.CreatePaymentMethod(r.Context(), pm, req.Card, time.Now()); err != nil {
+		infrastructure.PaymentRequests.WithLabelValues("payment_method_create", "error").Inc()
+		writeError(w, err, "Failed to create payment method")
+		return
+	}
+	if req.CustomerID != "" {
+		if pm, err = h.methods.AttachPaymentMethod(r.Context(), pm.ID, req.CustomerID, false); err != nil {
+			infrastructure.PaymentRequests.WithLabelValues("payment_method_create", "error").Inc()
+			writeError(w, err, "Failed to attach payment method")
+			return
+		}
+	}
+
+	infrastructure.PaymentRequests.WithLabelValues("payment_method_create", "success").Inc()
+	jsonutil.WriteJSON(w, http.StatusCreated, pm)
+}
+
+func (h *PaymentHandler) GetPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	pm, ok := h.ownPaymentMethod(w, r)
+	if !ok {
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, pm)
+}
+
+// AttachPaymentMethod attaches a payment method to a customer, as the
+// customer's default if asked for or if the customer has none.
+func (h *PaymentHandler) AttachPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	pm, ok := h.ownPaymentMethod(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CustomerID string `json:"customer_id"`
+		Default    bool   `json:"default"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CustomerID == "" {
+		apierror.BadRequest("customer_id is required").Write(w)
+		return
+	}
+
+	pm, err := h.methods.AttachPaymentMethod(r.Context(), pm.ID, req.CustomerID, req.Default)
+	if err != nil {
+		writeError(w, err, "Failed to attach payment method")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, pm)
+}
+
+// DetachPaymentMethod detaches a payment method for good.
+func (h *PaymentHandler) DetachPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	pm, ok := h.ownPaymentMethod(w, r)
+	if !ok {
+		return
+	}
+
+	pm, err := h.methods.DetachPaymentMethod(r.Context(), pm.ID, time.Now())
+	if err != nil {
+		writeError(w, err, "Failed to detach payment method")
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, pm)
+}
+
+// ownPaymentMethod loads the payment method named in the path if it
+// belongs to the caller, and writes the error response otherwise.
+func (h *PaymentHandler) ownPaymentMethod(w http.ResponseWriter, r *http.Request) (*domain.PaymentMethod, bool) {
+	userID, err := authutil.ExtractUserID(r)
+	if err != nil || userID == "" {
+		apierror.Unauthorized("Authentication required").Write(w)
+		return nil, false
+	}
+	id := jsonutil.GetIDAfter(r, "payment_methods")
+	if id == "" {
+		apierror.BadRequest("Missing Payment Method ID").Write(w)
+		return nil, false
+	}
+
+	pm, err := h.methods.GetPaymentMethod(r.Context(), id)
+	if err == nil && pm.UserID != userID {
+		err = domain.ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		writeError(w, err, "Failed to get payment method")
+		return nil, false
+	}
+	return pm, true
+}
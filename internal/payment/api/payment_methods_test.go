@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
+	"github.com/sapliy/fintech-ecosystem/pkg/tenant"
+)
+
+// vaultRepo keeps customers, payment methods and intents in memory. No
+// checkout session pays for the intents.
+func vaultRepo() *domain.MockRepository {
+	customers := map[string]domain.Customer{}
+	methods := map[string]domain.PaymentMethod{}
+	intents := map[string]domain.PaymentIntent{}
+
+	return &domain.MockRepository{
+		CreateCustomerFunc: func(ctx context.Context, customer *domain.Customer) error {
+			customers[customer.ID] = *customer
+			return nil
+		},
+		GetCustomerFunc: func(ctx context.Context, id string) (*domain.Customer, error) {
+			customer, ok := customers[id]
+			if !ok {
+				return nil, nil
+			}
+			return &customer, nil
+		},
+		CreatePaymentMethodFunc: func(ctx context.Context, pm *domain.PaymentMethod) error {
+			methods[pm.ID] = *pm
+			return nil
+		},
+		GetPaymentMethodFunc: func(ctx context.Context, id string) (*domain.PaymentMethod, error) {
+			pm, ok := methods[id]
+			if !ok {
+				return nil, nil
+			}
+			return &pm, nil
+		},
+		AttachPaymentMethodFunc: func(ctx context.Context, id, customerID string, makeDefault bool) error {
+			pm := methods[id]
+			pm.CustomerID = customerID
+			methods[id] = pm
+			customer := customers[customerID]
+			if makeDefault || customer.DefaultPaymentMethodID == "" {
+				customer.DefaultPaymentMethodID = id
+			}
+			customers[customerID] = customer
+			return nil
+		},
+		CreatePaymentIntentFunc: func(ctx context.Context, intent *domain.PaymentIntent) error {
+			intent.ID = "pi_1"
+			intents[intent.ID] = *intent
+			return nil
+		},
+		GetPaymentIntentFunc: func(ctx context.Context, id string) (*domain.PaymentIntent, error) {
+			intent, ok := intents[id]
+			if !ok {
+				return nil, nil
+			}
+			return &intent, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, intent *domain.PaymentIntent, tr domain.Transition) error {
+			stored := *intent
+			stored.Status = tr.To
+			intents[intent.ID] = stored
+			return nil
+		},
+		GetCheckoutSessionByPaymentIntentFunc: func(ctx context.Context, intentID string) (*domain.CheckoutSession, error) {
+			return nil, nil
+		},
+	}
+}
+
+func TestPaymentHandler_SavedCardOffSession(t *testing.T) {
+	keys, err := tenant.NewKeyManager(make([]byte, 32), tenant.NewInMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	repo := vaultRepo()
+	service := domain.NewPaymentService(repo)
+	bankClient := &stubBank{result: &bank.TransactionResult{TransactionID: "txn_1", Status: bank.StatusSuccess}}
+	h := &PaymentHandler{
+		service:    service,
+		checkout:   domain.NewCheckoutService(repo, service, ""),
+		methods:    domain.NewPaymentMethodService(repo, keys),
+		processors: stubProcessors(bankClient),
+	}
+	merchant := func(req *http.Request, zoned bool) *http.Request {
+		req.Header.Set("X-User-ID", "user_1")
+		if zoned {
+			req.Header.Set("X-Zone-ID", "zone_1")
+		}
+		return req
+	}
+
+	// The merchant saves a customer's card...
+	w := httptest.NewRecorder()
+	h.CreateCustomer(w, merchant(httptest.NewRequest("POST", "/customers", strings.NewReader(`{"email":"ada@shop.test"}`)), true))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create customer answered %d: %s", w.Code, w.Body.String())
+	}
+	var customer domain.Customer
+	_ = json.Unmarshal(w.Body.Bytes(), &customer)
+
+	w = httptest.NewRecorder()
+	h.CreatePaymentMethod(w, merchant(httptest.NewRequest("POST", "/payment_methods", strings.NewReader(
+		`{"type":"card","card":{"number":"4242424242424242","exp_month":12,"exp_year":2099,"cvc":"123"},"customer_id":"`+customer.ID+`"}`)), true))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create payment method answered %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); strings.Contains(body, "4242424242424242") || strings.Contains(body, "123\"") {
+		t.Fatalf("payment method response shows the card: %s", body)
+	}
+	var pm domain.PaymentMethod
+	_ = json.Unmarshal(w.Body.Bytes(), &pm)
+	if pm.Card.Brand != "visa" || pm.Card.Last4 != "4242" || pm.CustomerID != customer.ID {
+		t.Fatalf("payment method = %+v, want a visa ending in 4242 attached to %s", pm, customer.ID)
+	}
+
+	// ...which other merchants cannot see...
+	req := httptest.NewRequest("GET", "/customers/"+customer.ID+"/payment_methods", nil)
+	req.Header.Set("X-User-ID", "user_2")
+	w = httptest.NewRecorder()
+	h.ListCustomerPaymentMethods(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("another merchant listing the cards got %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// ...and later charges it without the customer, as billing does.
+	w = httptest.NewRecorder()
+	h.CreatePaymentIntent(w, merchant(httptest.NewRequest("POST", "/intents", strings.NewReader(
+		`{"amount":1500,"currency":"USD","customer_id":"`+customer.ID+`"}`)), false))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create intent answered %d: %s", w.Code, w.Body.String())
+	}
+	var intent domain.PaymentIntent
+	_ = json.Unmarshal(w.Body.Bytes(), &intent)
+	if intent.ZoneID != "zone_1" {
+		t.Errorf("intent zone = %q, want the customer's zone_1", intent.ZoneID)
+	}
+
+	w = httptest.NewRecorder()
+	h.ConfirmPaymentIntent(w, merchant(httptest.NewRequest("POST", "/intents/"+intent.ID+"/confirm", strings.NewReader(`{"off_session":true}`)), false))
+	if w.Code != http.StatusOK {
+		t.Fatalf("confirm answered %d: %s", w.Code, w.Body.String())
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &intent)
+	if intent.Status != domain.StatusSucceeded || bankClient.charged != "4242424242424242" {
+		t.Errorf("intent status %s charged %q, want succeeded with the customer's default card", intent.Status, bankClient.charged)
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActionTTL is how long a customer has to go through an authentication
+// challenge. An intent still in requires_action after that returns to
+// requires_payment_method.
+const ActionTTL = 30 * time.Minute
+
+// NextActionRedirectToURL asks the client to send the customer to
+// RedirectURL to authenticate.
+const NextActionRedirectToURL = "redirect_to_url"
+
+// NextAction tells the client what the customer must do before an intent in
+// requires_action can be completed.
+type NextAction struct {
+	Type        string    `json:"type"`
+	RedirectURL string    `json:"redirect_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// MarkRequiresAction records the processor's authentication challenge and
+// moves a processing intent to requires_action. The intent gets a client
+// secret, which completing the action requires, the first time.
+func (s *PaymentService) MarkRequiresAction(ctx context.Context, intent *PaymentIntent, challengeID, redirectURL string, expiresAt time.Time) error {
+	if challengeID == "" || redirectURL == "" {
+		return &ValidationError{msg: "challenge_id and redirect_url are required"}
+	}
+	if intent.ClientSecret == "" {
+		intent.ClientSecret = fmt.Sprintf("%s_secret_%s", intent.ID, strings.ReplaceAll(uuid.New().String(), "-", ""))
+	}
+	intent.ChallengeID = challengeID
+	intent.NextAction = &NextAction{Type: NextActionRedirectToURL, RedirectURL: redirectURL, ExpiresAt: expiresAt}
+	return s.transition(ctx, intent, StatusRequiresAction, "authentication required")
+}
+
+// ResumeAction moves an intent in requires_action back to processing so its
+// challenge can be completed with the processor. The challenge stays on the
+// intent until the attempt ends.
+func (s *PaymentService) ResumeAction(ctx context.Context, intent *PaymentIntent, clientSecret string, now time.Time) error {
+	if intent.Status != StatusRequiresAction || intent.NextAction == nil {
+		return fmt.Errorf("%w: intent in status %s has no action to complete", ErrInvalidTransition, intent.Status)
+	}
+	if subtle.ConstantTimeCompare([]byte(clientSecret), []byte(intent.ClientSecret)) != 1 {
+		return ErrInvalidClientSecret
+	}
+	if !now.Before(intent.NextAction.ExpiresAt) {
+		return ErrActionExpired
+	}
+	return s.transition(ctx, intent, StatusProcessing, "authentication completed")
+}
+
+// ExpireAction returns an intent whose challenge was abandoned to
+// requires_payment_method.
+func (s *PaymentService) ExpireAction(ctx context.Context, intent *PaymentIntent) error {
+	intent.AuthorizationID, intent.AmountCapturable, intent.CaptureBefore = "", 0, nil
+	return s.transition(ctx, intent, StatusRequiresPaymentMethod, "authentication timed out")
+}
+
+// ListExpiredActions returns up to limit intents whose challenge expired
+// before now.
+func (s *PaymentService) ListExpiredActions(ctx context.Context, now time.Time, limit int) ([]PaymentIntent, error) {
+	return s.repo.ListExpiredActions(ctx, now, limit)
+}
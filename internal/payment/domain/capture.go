@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/validation"
+)
+
+// AuthorizationTTL is how long a manual-capture authorization is held. An
+// authorization not captured by then is voided and its intent canceled.
+const AuthorizationTTL = 7 * 24 * time.Hour
+
+// MarkAuthorized records an authorization held by the bank and moves a
+// processing manual-capture intent to requires_capture. The authorization
+// must be captured within AuthorizationTTL of now.
+func (s *PaymentService) MarkAuthorized(ctx context.Context, intent *PaymentIntent, authorizationID string, now time.Time) error {
+	if authorizationID == "" {
+		return &ValidationError{msg: "authorization_id is required"}
+	}
+	captureBefore := now.Add(AuthorizationTTL)
+	intent.AuthorizationID = authorizationID
+	intent.AmountCapturable = intent.Amount
+	intent.CaptureBefore = &captureBefore
+	return s.transition(ctx, intent, StatusRequiresCapture, "")
+}
+
+// CaptureAmount checks that intent can be captured at now and returns the
+// amount to capture. An amount of zero captures everything authorized.
+func CaptureAmount(intent *PaymentIntent, amount int64, now time.Time) (int64, error) {
+	if intent.Status != StatusRequiresCapture {
+		return 0, fmt.Errorf("%w: cannot capture an intent in status %s", ErrInvalidTransition, intent.Status)
+	}
+	if intent.CaptureBefore != nil && !now.Before(*intent.CaptureBefore) {
+		return 0, ErrAuthorizationExpired
+	}
+	if amount == 0 {
+		return intent.AmountCapturable, nil
+	}
+	if amount < 0 || amount > intent.AmountCapturable {
+		return 0, &ValidationError{msg: fmt.Sprintf("amount_to_capture must be between 1 and %d", intent.AmountCapturable)}
+	}
+	return amount, nil
+}
+
+// MarkCaptured moves an intent to succeeded after the bank settled amount.
+// For a manual capture, an amount below the authorization releases the
+// rest: an intent is captured once.
+func (s *PaymentService) MarkCaptured(ctx context.Context, intent *PaymentIntent, amount int64) error {
+	intent.AmountReceived = amount
+	intent.AmountCapturable = 0
+	intent.CaptureBefore = nil
+	reason := ""
+	if amount < intent.Amount {
+		reason = fmt.Sprintf("captured %d of %d", amount, intent.Amount)
+	}
+	return s.transition(ctx, intent, StatusSucceeded, reason)
+}
+
+// MarkVoided cancels an intent whose authorization the bank released.
+func (s *PaymentService) MarkVoided(ctx context.Context, intent *PaymentIntent, reason string) error {
+	intent.AmountCapturable = 0
+	intent.CaptureBefore = nil
+	return s.transition(ctx, intent, StatusCanceled, reason)
+}
+
+// ListExpiredAuthorizations returns up to limit intents whose authorization
+// expired before now.
+func (s *PaymentService) ListExpiredAuthorizations(ctx context.Context, now time.Time, limit int) ([]PaymentIntent, error) {
+	return s.repo.ListExpiredAuthorizations(ctx, now, limit)
+}
+
+// MarkSettled records a processor's settlement of the charge or capture it
+// knows by transactionID.
+func (s *PaymentService) MarkSettled(ctx context.Context, processor, transactionID string, at time.Time) error {
+	if err := validation.Validate(
+		validation.NotEmpty(processor, "processor"),
+		validation.NotEmpty(transactionID, "transaction_id"),
+	); err != nil {
+		return invalid(err)
+	}
+	return s.repo.MarkSettled(ctx, processor, transactionID, at)
+}
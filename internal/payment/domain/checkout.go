@@ -0,0 +1,363 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapliy/fintech-ecosystem/pkg/validation"
+)
+
+// CheckoutSessionStatus is the state of a checkout session. A session is
+// open until its payment succeeds, which completes it, or its expiry
+// passes.
+type CheckoutSessionStatus string
+
+const (
+	CheckoutSessionOpen     CheckoutSessionStatus = "open"
+	CheckoutSessionComplete CheckoutSessionStatus = "complete"
+	CheckoutSessionExpired  CheckoutSessionStatus = "expired"
+)
+
+// EventCheckoutSessionCompleted is published through the outbox when a
+// session's payment succeeds.
+const EventCheckoutSessionCompleted EventType = "checkout.session.completed"
+
+const (
+	// CheckoutSessionTTL is how long a session stays open when it is
+	// created without an expiry.
+	CheckoutSessionTTL = 24 * time.Hour
+	// MinCheckoutSessionTTL is the shortest expiry a session accepts.
+	MinCheckoutSessionTTL = 30 * time.Minute
+)
+
+// LineItem is one product line of a checkout session or payment link.
+type LineItem struct {
+	Name       string `json:"name"`
+	Quantity   int64  `json:"quantity"`
+	UnitAmount int64  `json:"unit_amount"` // In cents
+}
+
+// CheckoutSession is a hosted page where a customer pays for line items
+// without the merchant integrating the intents API. Its payment intent is
+// created when the customer first pays, and the customer is sent to
+// SuccessURL once it succeeds.
+type CheckoutSession struct {
+	ID string `json:"id"`
+	// PaymentLinkID is set on sessions opened from a payment link.
+	PaymentLinkID   string                `json:"payment_link_id,omitempty"`
+	PaymentIntentID string                `json:"payment_intent_id,omitempty"`
+	Status          CheckoutSessionStatus `json:"status"`
+	LineItems       []LineItem            `json:"line_items"`
+	AmountTotal     int64                 `json:"amount_total"`
+	Currency        string                `json:"currency"`
+	SuccessURL      string                `json:"success_url"`
+	CancelURL       string                `json:"cancel_url,omitempty"`
+	// URL is the hosted page to send the customer to.
+	URL         string     `json:"url"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	UserID      string     `json:"user_id"`
+	ZoneID      string     `json:"zone_id"`
+	Mode        string     `json:"mode"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PaymentLink is a shareable URL that opens a new checkout session for the
+// same line items on every visit, until it is deactivated.
+type PaymentLink struct {
+	ID          string     `json:"id"`
+	LineItems   []LineItem `json:"line_items"`
+	AmountTotal int64      `json:"amount_total"`
+	Currency    string     `json:"currency"`
+	SuccessURL  string     `json:"success_url"`
+	CancelURL   string     `json:"cancel_url,omitempty"`
+	URL         string     `json:"url"`
+	Active      bool       `json:"active"`
+	UserID      string     `json:"user_id"`
+	ZoneID      string     `json:"zone_id"`
+	Mode        string     `json:"mode"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CheckoutService creates checkout sessions and payment links and ties
+// sessions to the payment intents paying for them. Hosted pages are served
+// under baseURL: sessions at {baseURL}/sessions/{id} and links at
+// {baseURL}/links/{id}.
+type CheckoutService struct {
+	repo     Repository
+	payments *PaymentService
+	baseURL  string
+}
+
+func NewCheckoutService(repo Repository, payments *PaymentService, baseURL string) *CheckoutService {
+	return &CheckoutService{repo: repo, payments: payments, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// CreateSession opens a session for its line items, which sets its total.
+// A zero ExpiresAt gives CheckoutSessionTTL from now.
+func (s *CheckoutService) CreateSession(ctx context.Context, session *CheckoutSession, now time.Time) error {
+	total, err := validateCheckout(session.LineItems, session.Currency, session.SuccessURL, session.CancelURL)
+	if err != nil {
+		return err
+	}
+	if err := validation.NotEmpty(session.ZoneID, "zone_id")(); err != nil {
+		return invalid(err)
+	}
+	if session.ExpiresAt.IsZero() {
+		session.ExpiresAt = now.Add(CheckoutSessionTTL)
+	}
+	if ttl := session.ExpiresAt.Sub(now); ttl < MinCheckoutSessionTTL || ttl > CheckoutSessionTTL {
+		return &ValidationError{msg: fmt.Sprintf("expires_at must be between %s and %s from now", MinCheckoutSessionTTL, CheckoutSessionTTL)}
+	}
+
+	session.ID = uuid.New().String()
+	session.Status = CheckoutSessionOpen
+	session.AmountTotal = total
+	session.PaymentIntentID, session.CompletedAt = "", nil
+	if err := s.repo.CreateCheckoutSession(ctx, session); err != nil {
+		return err
+	}
+	s.setSessionURL(session)
+	return nil
+}
+
+func (s *CheckoutService) GetSession(ctx context.Context, id string) (*CheckoutSession, error) {
+	session, err := s.repo.GetCheckoutSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrCheckoutSessionNotFound
+	}
+	s.setSessionURL(session)
+	return session, nil
+}
+
+// CreatePaymentLink stores an active link for its line items.
+func (s *CheckoutService) CreatePaymentLink(ctx context.Context, link *PaymentLink) error {
+	total, err := validateCheckout(link.LineItems, link.Currency, link.SuccessURL, link.CancelURL)
+	if err != nil {
+		return err
+	}
+	if err := validation.NotEmpty(link.ZoneID, "zone_id")(); err != nil {
+		return invalid(err)
+	}
+
+	link.ID = uuid.New().String()
+	link.AmountTotal = total
+	link.Active = true
+	if err := s.repo.CreatePaymentLink(ctx, link); err != nil {
+		return err
+	}
+	s.setLinkURL(link)
+	return nil
+}
+
+func (s *CheckoutService) GetPaymentLink(ctx context.Context, id string) (*PaymentLink, error) {
+	link, err := s.repo.GetPaymentLink(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrPaymentLinkNotFound
+	}
+	s.setLinkURL(link)
+	return link, nil
+}
+
+// DeactivatePaymentLink stops a link from opening new sessions. Sessions
+// already opened from it can still be paid.
+func (s *CheckoutService) DeactivatePaymentLink(ctx context.Context, id string) (*PaymentLink, error) {
+	link, err := s.repo.DeactivatePaymentLink(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.setLinkURL(link)
+	return link, nil
+}
+
+// OpenPaymentLink opens a new session for a customer visiting an active
+// link.
+func (s *CheckoutService) OpenPaymentLink(ctx context.Context, linkID string, now time.Time) (*CheckoutSession, error) {
+	link, err := s.GetPaymentLink(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if !link.Active {
+		return nil, ErrPaymentLinkInactive
+	}
+	session := &CheckoutSession{
+		PaymentLinkID: link.ID,
+		LineItems:     link.LineItems,
+		Currency:      link.Currency,
+		SuccessURL:    link.SuccessURL,
+		CancelURL:     link.CancelURL,
+		UserID:        link.UserID,
+		ZoneID:        link.ZoneID,
+		Mode:          link.Mode,
+	}
+	if err := s.CreateSession(ctx, session, now); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// StartPayment returns the payment intent paying for an open session,
+// creating it on the session's first payment attempt.
+func (s *CheckoutService) StartPayment(ctx context.Context, id string, now time.Time) (*CheckoutSession, *PaymentIntent, error) {
+	session, err := s.GetSession(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := session.payable(now); err != nil {
+		return nil, nil, err
+	}
+
+	if session.PaymentIntentID != "" {
+		intent, err := s.payments.GetPaymentIntent(ctx, session.PaymentIntentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if intent == nil {
+			return nil, nil, ErrPaymentIntentNotFound
+		}
+		return session, intent, nil
+	}
+
+	intent := &PaymentIntent{
+		Amount:      session.AmountTotal,
+		Currency:    session.Currency,
+		Description: "Checkout session " + session.ID,
+		UserID:      session.UserID,
+		ZoneID:      session.ZoneID,
+		Mode:        session.Mode,
+	}
+	if err := prepareIntent(intent); err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.CreateCheckoutPaymentIntent(ctx, session, intent); err != nil {
+		return nil, nil, err
+	}
+	return session, intent, nil
+}
+
+// payable reports why a session cannot be paid at now, if it cannot.
+func (c *CheckoutSession) payable(now time.Time) error {
+	if c.Status != CheckoutSessionOpen {
+		return fmt.Errorf("%w: session is %s", ErrCheckoutSessionClosed, c.Status)
+	}
+	if !now.Before(c.ExpiresAt) {
+		return fmt.Errorf("%w: session expired", ErrCheckoutSessionClosed)
+	}
+	return nil
+}
+
+// CompleteSession completes the open session paid for by intent once the
+// intent succeeded, and returns it. It returns nil for an intent no
+// session created. Completing a session again is a no-op.
+func (s *CheckoutService) CompleteSession(ctx context.Context, intent *PaymentIntent, now time.Time) (*CheckoutSession, error) {
+	session, err := s.repo.GetCheckoutSessionByPaymentIntent(ctx, intent.ID)
+	if err != nil || session == nil {
+		return nil, err
+	}
+	s.setSessionURL(session)
+	if session.Status != CheckoutSessionOpen || !paid(intent) {
+		return session, nil
+	}
+	session.Status = CheckoutSessionComplete
+	session.CompletedAt = &now
+	if err := s.repo.UpdateCheckoutSession(ctx, session, CheckoutSessionOpen); err != nil {
+		return nil, err
+	}
+	s.setSessionURL(session)
+	return session, nil
+}
+
+// ExpireSession closes a session left open past its expiry. A session
+// whose payment succeeded meanwhile is completed instead, and an unpaid
+// intent is canceled so it cannot be confirmed later. A session whose
+// payment attempt is still in progress is left open; it returns false.
+func (s *CheckoutService) ExpireSession(ctx context.Context, session *CheckoutSession, now time.Time) (bool, error) {
+	if session.PaymentIntentID != "" {
+		intent, err := s.payments.GetPaymentIntent(ctx, session.PaymentIntentID)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case intent == nil:
+		case paid(intent):
+			_, err := s.CompleteSession(ctx, intent, now)
+			return err == nil, err
+		case intent.Status == StatusRequiresPaymentMethod:
+			if _, err := s.payments.UpdateStatus(ctx, intent.ID, StatusCanceled, "checkout session expired"); err != nil {
+				return false, err
+			}
+		case intent.Status != StatusCanceled:
+			return false, nil
+		}
+	}
+	session.Status = CheckoutSessionExpired
+	return true, s.repo.UpdateCheckoutSession(ctx, session, CheckoutSessionOpen)
+}
+
+// paid reports whether intent's payment went through, even if it was
+// refunded since.
+func paid(intent *PaymentIntent) bool {
+	switch intent.Status {
+	case StatusSucceeded, StatusPartiallyRefunded, StatusRefunded:
+		return true
+	}
+	return false
+}
+
+// ListExpiredSessions returns up to limit open sessions whose expiry
+// passed before now.
+func (s *CheckoutService) ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]CheckoutSession, error) {
+	return s.repo.ListExpiredCheckoutSessions(ctx, now, limit)
+}
+
+func (s *CheckoutService) setSessionURL(session *CheckoutSession) {
+	session.URL = s.baseURL + "/sessions/" + session.ID
+}
+
+func (s *CheckoutService) setLinkURL(link *PaymentLink) {
+	link.URL = s.baseURL + "/links/" + link.ID
+}
+
+// validateCheckout checks the fields sessions and links share and returns
+// the total of the line items.
+func validateCheckout(items []LineItem, currency, successURL, cancelURL string) (int64, error) {
+	if err := validation.Validate(
+		validation.NotEmpty(currency, "currency"),
+		validation.NotEmpty(successURL, "success_url"),
+	); err != nil {
+		return 0, invalid(err)
+	}
+	if len(items) == 0 {
+		return 0, &ValidationError{msg: "line_items must not be empty"}
+	}
+	var total int64
+	for i, item := range items {
+		if item.Name == "" || item.Quantity <= 0 || item.UnitAmount <= 0 {
+			return 0, &ValidationError{msg: fmt.Sprintf("line_items[%d] needs a name, a positive quantity and a positive unit_amount", i)}
+		}
+		if item.UnitAmount > (math.MaxInt64-total)/item.Quantity {
+			return 0, &ValidationError{msg: "line_items total is too large"}
+		}
+		total += item.Quantity * item.UnitAmount
+	}
+	for field, raw := range map[string]string{"success_url": successURL, "cancel_url": cancelURL} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return 0, &ValidationError{msg: field + " must be an absolute http(s) URL"}
+		}
+	}
+	return total, nil
+}
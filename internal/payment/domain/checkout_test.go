@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckoutService_CreateSession(t *testing.T) {
+	now := time.Now()
+	items := []LineItem{{Name: "T-shirt", Quantity: 2, UnitAmount: 1500}, {Name: "Sticker", Quantity: 1, UnitAmount: 300}}
+
+	tests := []struct {
+		name      string
+		session   CheckoutSession
+		wantTotal int64
+		wantErr   bool
+	}{
+		{name: "valid", session: CheckoutSession{LineItems: items, Currency: "USD", SuccessURL: "https://shop.test/thanks", ZoneID: "zone_1"}, wantTotal: 3300},
+		{name: "no line items", session: CheckoutSession{Currency: "USD", SuccessURL: "https://shop.test/thanks", ZoneID: "zone_1"}, wantErr: true},
+		{name: "zero quantity", session: CheckoutSession{LineItems: []LineItem{{Name: "T-shirt", UnitAmount: 1500}}, Currency: "USD", SuccessURL: "https://shop.test/thanks", ZoneID: "zone_1"}, wantErr: true},
+		{name: "relative success url", session: CheckoutSession{LineItems: items, Currency: "USD", SuccessURL: "/thanks", ZoneID: "zone_1"}, wantErr: true},
+		{name: "bad cancel url", session: CheckoutSession{LineItems: items, Currency: "USD", SuccessURL: "https://shop.test/thanks", CancelURL: "javascript:alert(1)", ZoneID: "zone_1"}, wantErr: true},
+		{name: "expiry too soon", session: CheckoutSession{LineItems: items, Currency: "USD", SuccessURL: "https://shop.test/thanks", ZoneID: "zone_1", ExpiresAt: now.Add(time.Minute)}, wantErr: true},
+		{name: "expiry too late", session: CheckoutSession{LineItems: items, Currency: "USD", SuccessURL: "https://shop.test/thanks", ZoneID: "zone_1", ExpiresAt: now.Add(48 * time.Hour)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{
+				CreateCheckoutSessionFunc: func(ctx context.Context, session *CheckoutSession) error {
+					return nil
+				},
+			}
+			s := NewCheckoutService(repo, NewPaymentService(repo), "https://pay.test/v1/checkout/")
+			session := tt.session
+			err := s.CreateSession(context.Background(), &session, now)
+			if tt.wantErr {
+				if !IsValidationError(err) {
+					t.Fatalf("CreateSession() error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateSession() error = %v", err)
+			}
+			if session.AmountTotal != tt.wantTotal || session.Status != CheckoutSessionOpen || !session.ExpiresAt.Equal(now.Add(CheckoutSessionTTL)) {
+				t.Errorf("session = %+v", session)
+			}
+			if want := "https://pay.test/v1/checkout/sessions/" + session.ID; session.URL != want {
+				t.Errorf("URL = %q, want %q", session.URL, want)
+			}
+		})
+	}
+}
+
+func TestCheckoutService_StartPayment(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		session    CheckoutSession
+		wantCreate bool
+		wantErr    error
+	}{
+		{name: "first attempt creates the intent", session: CheckoutSession{ID: "cs_1", Status: CheckoutSessionOpen, AmountTotal: 3300, Currency: "USD", ZoneID: "zone_1", ExpiresAt: now.Add(time.Hour)}, wantCreate: true},
+		{name: "retry reuses the intent", session: CheckoutSession{ID: "cs_1", PaymentIntentID: "pi_1", Status: CheckoutSessionOpen, AmountTotal: 3300, Currency: "USD", ZoneID: "zone_1", ExpiresAt: now.Add(time.Hour)}},
+		{name: "complete", session: CheckoutSession{ID: "cs_1", PaymentIntentID: "pi_1", Status: CheckoutSessionComplete, ExpiresAt: now.Add(time.Hour)}, wantErr: ErrCheckoutSessionClosed},
+		{name: "past expiry", session: CheckoutSession{ID: "cs_1", Status: CheckoutSessionOpen, ExpiresAt: now.Add(-time.Second)}, wantErr: ErrCheckoutSessionClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			repo := &MockRepository{
+				GetCheckoutSessionFunc: func(ctx context.Context, id string) (*CheckoutSession, error) {
+					s := tt.session
+					return &s, nil
+				},
+				GetPaymentIntentFunc: func(ctx context.Context, id string) (*PaymentIntent, error) {
+					return &PaymentIntent{ID: id, Status: StatusRequiresPaymentMethod}, nil
+				},
+				CreateCheckoutPaymentIntentFunc: func(ctx context.Context, session *CheckoutSession, intent *PaymentIntent) error {
+					if intent.Amount != session.AmountTotal || intent.Status != StatusRequiresPaymentMethod || intent.CaptureMethod != CaptureMethodAutomatic {
+						t.Errorf("intent = %+v", intent)
+					}
+					created = true
+					intent.ID, session.PaymentIntentID = "pi_2", "pi_2"
+					return nil
+				},
+			}
+			s := NewCheckoutService(repo, NewPaymentService(repo), "https://pay.test")
+			session, intent, err := s.StartPayment(context.Background(), "cs_1", now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StartPayment() error = %v, want %v", err, tt.wantErr)
+			}
+			if created != tt.wantCreate {
+				t.Errorf("created intent = %v, want %v", created, tt.wantCreate)
+			}
+			if err == nil && session.PaymentIntentID != intent.ID {
+				t.Errorf("session pays with %q, intent is %q", session.PaymentIntentID, intent.ID)
+			}
+		})
+	}
+}
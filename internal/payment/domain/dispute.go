@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapliy/fintech-ecosystem/pkg/validation"
+)
+
+// DisputeStatus is the state of a dispute. A dispute needs a response until
+// the merchant submits evidence, is under review until the processor
+// decides, and ends won or lost.
+type DisputeStatus string
+
+const (
+	DisputeNeedsResponse DisputeStatus = "needs_response"
+	DisputeUnderReview   DisputeStatus = "under_review"
+	DisputeWon           DisputeStatus = "won"
+	DisputeLost          DisputeStatus = "lost"
+)
+
+// Dispute events, published through the outbox with the dispute. The ledger
+// moves the disputed amount from the merchant's balance to the disputes
+// reserve on EventDisputeCreated and back on EventDisputeWon.
+const (
+	EventDisputeCreated     EventType = "dispute.created"
+	EventDisputeUnderReview EventType = "dispute.under_review"
+	EventDisputeWon         EventType = "dispute.won"
+	EventDisputeLost        EventType = "dispute.lost"
+)
+
+// DisputeResponseWindow is how long the merchant has to submit evidence
+// when the processor does not set a deadline.
+const DisputeResponseWindow = 7 * 24 * time.Hour
+
+var disputeTransitions = map[DisputeStatus][]DisputeStatus{
+	DisputeNeedsResponse: {DisputeUnderReview, DisputeWon, DisputeLost},
+	DisputeUnderReview:   {DisputeWon, DisputeLost},
+	DisputeWon:           {},
+	DisputeLost:          {},
+}
+
+// CanTransition reports whether a dispute in status s may move to to.
+func (s DisputeStatus) CanTransition(to DisputeStatus) bool {
+	for _, next := range disputeTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Event returns the event announcing that a dispute entered s.
+func (s DisputeStatus) Event() EventType {
+	switch s {
+	case DisputeUnderReview:
+		return EventDisputeUnderReview
+	case DisputeWon:
+		return EventDisputeWon
+	case DisputeLost:
+		return EventDisputeLost
+	default:
+		return EventDisputeCreated
+	}
+}
+
+// DisputeEvidence is the merchant's response to a dispute.
+type DisputeEvidence struct {
+	ProductDescription     string `json:"product_description,omitempty"`
+	CustomerCommunication  string `json:"customer_communication,omitempty"`
+	RefundPolicy           string `json:"refund_policy,omitempty"`
+	ShippingTrackingNumber string `json:"shipping_tracking_number,omitempty"`
+	UncategorizedText      string `json:"uncategorized_text,omitempty"`
+}
+
+func (e DisputeEvidence) empty() bool {
+	return e == DisputeEvidence{}
+}
+
+// Dispute is a cardholder's chargeback of a payment, reported by the
+// processor the payment went through. The intent's user, zone and mode are
+// copied so the ledger can book the dispute from its events alone.
+type Dispute struct {
+	ID              string        `json:"id"`
+	PaymentIntentID string        `json:"payment_intent_id"`
+	Amount          int64         `json:"amount"`
+	Currency        string        `json:"currency"`
+	Reason          string        `json:"reason,omitempty"`
+	Status          DisputeStatus `json:"status"`
+	UserID          string        `json:"user_id"`
+	ZoneID          string        `json:"zone_id"`
+	Mode            string        `json:"mode"`
+	// Processor and ProcessorDisputeID identify the dispute in the
+	// processor's webhooks.
+	Processor           string           `json:"processor"`
+	ProcessorDisputeID  string           `json:"-"`
+	Evidence            *DisputeEvidence `json:"evidence,omitempty"`
+	EvidenceDueBy       time.Time        `json:"evidence_due_by"`
+	EvidenceSubmittedAt *time.Time       `json:"evidence_submitted_at,omitempty"`
+	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at"`
+	// OnBehalfOf is the connected account of a disputed marketplace
+	// payment, whose balance the disputed amount is withheld from.
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+}
+
+// DisputeService records disputes reported by processors and the
+// merchant's responses to them.
+type DisputeService struct {
+	repo Repository
+}
+
+func NewDisputeService(repo Repository) *DisputeService {
+	return &DisputeService{repo: repo}
+}
+
+// OpenDispute records a dispute of the charge or capture the processor
+// knows by transactionID. The processor reporting the same dispute again
+// returns the stored one. A zero dueBy gives DisputeResponseWindow.
+func (s *DisputeService) OpenDispute(ctx context.Context, processor, transactionID, processorDisputeID string, amount int64, reason string, dueBy time.Time) (*Dispute, error) {
+	if err := validation.Validate(
+		validation.NotEmpty(processor, "processor"),
+		validation.NotEmpty(transactionID, "transaction_id"),
+		validation.NotEmpty(processorDisputeID, "dispute_id"),
+	); err != nil {
+		return nil, invalid(err)
+	}
+	if amount <= 0 {
+		return nil, &ValidationError{msg: "amount must be positive"}
+	}
+
+	intent, err := s.repo.GetPaymentIntentByProcessorTransaction(ctx, processor, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPaymentIntentNotFound
+	}
+	if dueBy.IsZero() {
+		dueBy = time.Now().Add(DisputeResponseWindow)
+	}
+
+	dispute := &Dispute{
+		ID:                 uuid.New().String(),
+		PaymentIntentID:    intent.ID,
+		Amount:             amount,
+		Currency:           intent.Currency,
+		Reason:             reason,
+		Status:             DisputeNeedsResponse,
+		UserID:             intent.UserID,
+		ZoneID:             intent.ZoneID,
+		Mode:               intent.Mode,
+		Processor:          processor,
+		ProcessorDisputeID: processorDisputeID,
+		EvidenceDueBy:      dueBy,
+		OnBehalfOf:         intent.OnBehalfOf,
+	}
+	if err := s.repo.CreateDispute(ctx, dispute); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// SubmitEvidence stores the merchant's evidence and puts the dispute under
+// review. Evidence is accepted once, before the dispute's deadline.
+func (s *DisputeService) SubmitEvidence(ctx context.Context, id string, evidence DisputeEvidence, now time.Time) (*Dispute, error) {
+	if evidence.empty() {
+		return nil, &ValidationError{msg: "evidence must not be empty"}
+	}
+	dispute, err := s.GetDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != DisputeNeedsResponse {
+		return nil, fmt.Errorf("%w: cannot submit evidence for a dispute in status %s", ErrInvalidTransition, dispute.Status)
+	}
+	if now.After(dispute.EvidenceDueBy) {
+		return nil, ErrEvidenceDeadlinePassed
+	}
+
+	dispute.Evidence = &evidence
+	dispute.EvidenceSubmittedAt = &now
+	return dispute, s.move(ctx, dispute, DisputeUnderReview)
+}
+
+// CloseDispute applies the processor's decision on a dispute, won or lost.
+// The processor reporting the same decision again is a no-op.
+func (s *DisputeService) CloseDispute(ctx context.Context, processor, processorDisputeID string, outcome DisputeStatus) (*Dispute, error) {
+	if outcome != DisputeWon && outcome != DisputeLost {
+		return nil, &ValidationError{msg: fmt.Sprintf("invalid dispute outcome %q", outcome)}
+	}
+	dispute, err := s.repo.GetDisputeByProcessorID(ctx, processor, processorDisputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute == nil {
+		return nil, ErrDisputeNotFound
+	}
+	if dispute.Status == outcome {
+		return dispute, nil
+	}
+	return dispute, s.move(ctx, dispute, outcome)
+}
+
+// ExpireDispute loses a dispute the merchant did not answer before its
+// deadline.
+func (s *DisputeService) ExpireDispute(ctx context.Context, dispute *Dispute) error {
+	return s.move(ctx, dispute, DisputeLost)
+}
+
+// ListOverdueDisputes returns up to limit disputes still needing a response
+// after their deadline.
+func (s *DisputeService) ListOverdueDisputes(ctx context.Context, now time.Time, limit int) ([]Dispute, error) {
+	return s.repo.ListOverdueDisputes(ctx, now, limit)
+}
+
+func (s *DisputeService) GetDispute(ctx context.Context, id string) (*Dispute, error) {
+	dispute, err := s.repo.GetDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute == nil {
+		return nil, ErrDisputeNotFound
+	}
+	return dispute, nil
+}
+
+// ListDisputes returns an intent's disputes, oldest first.
+func (s *DisputeService) ListDisputes(ctx context.Context, intentID string) ([]Dispute, error) {
+	intent, err := s.repo.GetPaymentIntent(ctx, intentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPaymentIntentNotFound
+	}
+	return s.repo.ListDisputes(ctx, intentID)
+}
+
+// move validates a dispute's status change and stores it with its event.
+func (s *DisputeService) move(ctx context.Context, dispute *Dispute, to DisputeStatus) error {
+	from := dispute.Status
+	if !from.CanTransition(to) {
+		return fmt.Errorf("%w: dispute %s -> %s", ErrInvalidTransition, from, to)
+	}
+	dispute.Status = to
+	return s.repo.UpdateDispute(ctx, dispute, from)
+}
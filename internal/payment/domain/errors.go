@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+)
+
+var (
+	ErrPaymentIntentNotFound = errors.New("payment intent not found")
+	// ErrInvalidTransition is returned for a status change the state
+	// machine does not allow.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrStatusConflict is returned when an intent's status changed between
+	// reading it and writing the transition.
+	ErrStatusConflict = errors.New("payment intent status changed concurrently")
+	// ErrAuthorizationExpired is returned when capturing an intent whose
+	// authorization is past its capture deadline.
+	ErrAuthorizationExpired = errors.New("authorization expired")
+	// ErrActionExpired is returned when completing an authentication
+	// challenge after its deadline.
+	ErrActionExpired = errors.New("authentication challenge expired")
+	// ErrInvalidClientSecret is returned when completing an action with a
+	// client secret that is not the intent's.
+	ErrInvalidClientSecret = errors.New("invalid client secret")
+	ErrRefundExceedsAmount = errors.New("refund exceeds the refundable amount")
+	// ErrRefundFailed is returned when the processor declines a refund.
+	// The refund is stored as failed.
+	ErrRefundFailed = errors.New("refund failed")
+	// ErrRefundPending is returned when a refund could not be confirmed,
+	// because the processor or the ledger did not answer, the ledger
+	// rejected the reversal of funds already returned, or the refund could
+	// not be completed afterwards. The refund stays pending and is retried.
+	ErrRefundPending = errors.New("refund pending")
+	// ErrLedgerRejected is returned by a Ledger that definitely did not
+	// post a request, as opposed to one that may have.
+	ErrLedgerRejected = errors.New("ledger rejected the request")
+	// ErrProcessorDeclined is returned by Processors when the processor
+	// definitely did not return the funds, as opposed to one that may have.
+	ErrProcessorDeclined = errors.New("processor declined the request")
+	// ErrPaymentDisputed is returned when refunding an intent with an open
+	// or lost dispute, whose amount the cardholder already gets back.
+	ErrPaymentDisputed = errors.New("payment is disputed")
+	ErrDisputeNotFound = errors.New("dispute not found")
+	// ErrEvidenceDeadlinePassed is returned when submitting evidence for a
+	// dispute after its deadline.
+	ErrEvidenceDeadlinePassed  = errors.New("dispute evidence deadline passed")
+	ErrCheckoutSessionNotFound = errors.New("checkout session not found")
+	// ErrCheckoutSessionClosed is returned when paying for a checkout
+	// session that is complete or expired.
+	ErrCheckoutSessionClosed = errors.New("checkout session is not open")
+	ErrPaymentLinkNotFound   = errors.New("payment link not found")
+	// ErrPaymentLinkInactive is returned when opening a session from a
+	// deactivated payment link.
+	ErrPaymentLinkInactive   = errors.New("payment link is no longer active")
+	ErrCustomerNotFound      = errors.New("customer not found")
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	// ErrPaymentMethodUnavailable is returned when using a payment method
+	// that was detached or is attached to another customer.
+	ErrPaymentMethodUnavailable = errors.New("payment method is detached or attached to another customer")
+	// ErrConnectedAccountNotFound is returned when a payment is made on
+	// behalf of a connected account the connect service does not know.
+	ErrConnectedAccountNotFound = errors.New("connected account not found")
+)
+
+// ValidationError is returned when a request is rejected before reaching
+// storage. API layers map it to a client error.
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+// invalid wraps an error from pkg/validation as a ValidationError.
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValidationError{msg: err.Error()}
+}
+
+// IsValidationError reports whether err, or any error it wraps, is a
+// ValidationError.
+func IsValidationError(err error) bool {
+	var verr *ValidationError
+	return errors.As(err, &verr)
+}
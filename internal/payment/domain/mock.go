@@ -2,15 +2,46 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type MockRepository struct {
-	CreatePaymentIntentFunc func(ctx context.Context, intent *PaymentIntent) error
-	GetPaymentIntentFunc    func(ctx context.Context, id string) (*PaymentIntent, error)
-	UpdateStatusFunc        func(ctx context.Context, id, status string) error
-	GetIdempotencyKeyFunc   func(ctx context.Context, userID, key string) (*IdempotencyRecord, error)
-	SaveIdempotencyKeyFunc  func(ctx context.Context, userID, key string, statusCode int, body string) error
-	ListPaymentIntentsFunc  func(ctx context.Context, zoneID string, limit int) ([]PaymentIntent, error)
+	CreatePaymentIntentFunc                    func(ctx context.Context, intent *PaymentIntent) error
+	GetPaymentIntentFunc                       func(ctx context.Context, id string) (*PaymentIntent, error)
+	GetPaymentIntentByProcessorTransactionFunc func(ctx context.Context, processor, transactionID string) (*PaymentIntent, error)
+	UpdateStatusFunc                           func(ctx context.Context, intent *PaymentIntent, t Transition) error
+	ListStatusHistoryFunc                      func(ctx context.Context, id string) ([]StatusChange, error)
+	ListExpiredAuthorizationsFunc              func(ctx context.Context, before time.Time, limit int) ([]PaymentIntent, error)
+	ListExpiredActionsFunc                     func(ctx context.Context, before time.Time, limit int) ([]PaymentIntent, error)
+	MarkSettledFunc                            func(ctx context.Context, processor, transactionID string, at time.Time) error
+	CreateRefundFunc                           func(ctx context.Context, refund *Refund) error
+	CompleteRefundFunc                         func(ctx context.Context, refund *Refund) error
+	FailRefundFunc                             func(ctx context.Context, refund *Refund) error
+	ListRefundsFunc                            func(ctx context.Context, intentID string) ([]Refund, error)
+	ListPendingRefundsFunc                     func(ctx context.Context, before time.Time, limit int) ([]Refund, error)
+	CreateDisputeFunc                          func(ctx context.Context, dispute *Dispute) error
+	GetDisputeFunc                             func(ctx context.Context, id string) (*Dispute, error)
+	GetDisputeByProcessorIDFunc                func(ctx context.Context, processor, processorDisputeID string) (*Dispute, error)
+	ListDisputesFunc                           func(ctx context.Context, intentID string) ([]Dispute, error)
+	UpdateDisputeFunc                          func(ctx context.Context, dispute *Dispute, from DisputeStatus) error
+	ListOverdueDisputesFunc                    func(ctx context.Context, before time.Time, limit int) ([]Dispute, error)
+	ListPaymentIntentsFunc                     func(ctx context.Context, zoneID string, limit int) ([]PaymentIntent, error)
+	CreateCheckoutSessionFunc                  func(ctx context.Context, session *CheckoutSession) error
+	GetCheckoutSessionFunc                     func(ctx context.Context, id string) (*CheckoutSession, error)
+	GetCheckoutSessionByPaymentIntentFunc      func(ctx context.Context, intentID string) (*CheckoutSession, error)
+	CreateCheckoutPaymentIntentFunc            func(ctx context.Context, session *CheckoutSession, intent *PaymentIntent) error
+	UpdateCheckoutSessionFunc                  func(ctx context.Context, session *CheckoutSession, from CheckoutSessionStatus) error
+	ListExpiredCheckoutSessionsFunc            func(ctx context.Context, before time.Time, limit int) ([]CheckoutSession, error)
+	CreatePaymentLinkFunc                      func(ctx context.Context, link *PaymentLink) error
+	GetPaymentLinkFunc                         func(ctx context.Context, id string) (*PaymentLink, error)
+	DeactivatePaymentLinkFunc                  func(ctx context.Context, id string) (*PaymentLink, error)
+	CreateCustomerFunc                         func(ctx context.Context, customer *Customer) error
+	GetCustomerFunc                            func(ctx context.Context, id string) (*Customer, error)
+	CreatePaymentMethodFunc                    func(ctx context.Context, pm *PaymentMethod) error
+	GetPaymentMethodFunc                       func(ctx context.Context, id string) (*PaymentMethod, error)
+	ListPaymentMethodsFunc                     func(ctx context.Context, customerID string) ([]PaymentMethod, error)
+	AttachPaymentMethodFunc                    func(ctx context.Context, id, customerID string, makeDefault bool) error
+	DetachPaymentMethodFunc                    func(ctx context.Context, pm *PaymentMethod, at time.Time) error
 }
 
 func (m *MockRepository) ListPaymentIntents(ctx context.Context, zoneID string, limit int) ([]PaymentIntent, error) {
@@ -25,14 +56,152 @@ func (m *MockRepository) GetPaymentIntent(ctx context.Context, id string) (*Paym
 	return m.GetPaymentIntentFunc(ctx, id)
 }
 
-func (m *MockRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	return m.UpdateStatusFunc(ctx, id, status)
+func (m *MockRepository) GetPaymentIntentByProcessorTransaction(ctx context.Context, processor, transactionID string) (*PaymentIntent, error) {
+	return m.GetPaymentIntentByProcessorTransactionFunc(ctx, processor, transactionID)
 }
 
-func (m *MockRepository) GetIdempotencyKey(ctx context.Context, userID, key string) (*IdempotencyRecord, error) {
-	return m.GetIdempotencyKeyFunc(ctx, userID, key)
+func (m *MockRepository) UpdateStatus(ctx context.Context, intent *PaymentIntent, t Transition) error {
+	return m.UpdateStatusFunc(ctx, intent, t)
 }
 
-func (m *MockRepository) SaveIdempotencyKey(ctx context.Context, userID, key string, statusCode int, body string) error {
-	return m.SaveIdempotencyKeyFunc(ctx, userID, key, statusCode, body)
+func (m *MockRepository) ListExpiredAuthorizations(ctx context.Context, before time.Time, limit int) ([]PaymentIntent, error) {
+	return m.ListExpiredAuthorizationsFunc(ctx, before, limit)
+}
+
+func (m *MockRepository) ListExpiredActions(ctx context.Context, before time.Time, limit int) ([]PaymentIntent, error) {
+	return m.ListExpiredActionsFunc(ctx, before, limit)
+}
+
+func (m *MockRepository) MarkSettled(ctx context.Context, processor, transactionID string, at time.Time) error {
+	return m.MarkSettledFunc(ctx, processor, transactionID, at)
+}
+
+func (m *MockRepository) ListStatusHistory(ctx context.Context, id string) ([]StatusChange, error) {
+	return m.ListStatusHistoryFunc(ctx, id)
+}
+
+func (m *MockRepository) CreateRefund(ctx context.Context, refund *Refund) error {
+	return m.CreateRefundFunc(ctx, refund)
+}
+
+func (m *MockRepository) CompleteRefund(ctx context.Context, refund *Refund) error {
+	return m.CompleteRefundFunc(ctx, refund)
+}
+
+func (m *MockRepository) FailRefund(ctx context.Context, refund *Refund) error {
+	return m.FailRefundFunc(ctx, refund)
+}
+
+func (m *MockRepository) ListRefunds(ctx context.Context, intentID string) ([]Refund, error) {
+	return m.ListRefundsFunc(ctx, intentID)
+}
+
+func (m *MockRepository) ListPendingRefunds(ctx context.Context, before time.Time, limit int) ([]Refund, error) {
+	return m.ListPendingRefundsFunc(ctx, before, limit)
+}
+
+// MockLedger is a hand-written Ledger for tests.
+type MockLedger struct {
+	ReversePaymentFunc func(ctx context.Context, payment *PaymentIntent, reference string, amount int64, reason string) (string, error)
+}
+
+func (m *MockLedger) ReversePayment(ctx context.Context, payment *PaymentIntent, reference string, amount int64, reason string) (string, error) {
+	return m.ReversePaymentFunc(ctx, payment, reference, amount, reason)
+}
+
+// MockProcessors is a hand-written Processors for tests.
+type MockProcessors struct {
+	RefundPaymentFunc func(ctx context.Context, payment *PaymentIntent, refundID string, amount int64) (string, error)
+}
+
+func (m *MockProcessors) RefundPayment(ctx context.Context, payment *PaymentIntent, refundID string, amount int64) (string, error) {
+	return m.RefundPaymentFunc(ctx, payment, refundID, amount)
+}
+
+func (m *MockRepository) CreateDispute(ctx context.Context, dispute *Dispute) error {
+	return m.CreateDisputeFunc(ctx, dispute)
+}
+
+func (m *MockRepository) GetDispute(ctx context.Context, id string) (*Dispute, error) {
+	return m.GetDisputeFunc(ctx, id)
+}
+
+func (m *MockRepository) GetDisputeByProcessorID(ctx context.Context, processor, processorDisputeID string) (*Dispute, error) {
+	return m.GetDisputeByProcessorIDFunc(ctx, processor, processorDisputeID)
+}
+
+func (m *MockRepository) ListDisputes(ctx context.Context, intentID string) ([]Dispute, error) {
+	return m.ListDisputesFunc(ctx, intentID)
+}
+
+func (m *MockRepository) UpdateDispute(ctx context.Context, dispute *Dispute, from DisputeStatus) error {
+	return m.UpdateDisputeFunc(ctx, dispute, from)
+}
+
+func (m *MockRepository) ListOverdueDisputes(ctx context.Context, before time.Time, limit int) ([]Dispute, error) {
+	return m.ListOverdueDisputesFunc(ctx, before, limit)
+}
+
+func (m *MockRepository) CreateCheckoutSession(ctx context.Context, session *CheckoutSession) error {
+	return m.CreateCheckoutSessionFunc(ctx, session)
+}
+
+func (m *MockRepository) GetCheckoutSession(ctx context.Context, id string) (*CheckoutSession, error) {
+	return m.GetCheckoutSessionFunc(ctx, id)
+}
+
+func (m *MockRepository) GetCheckoutSessionByPaymentIntent(ctx context.Context, intentID string) (*CheckoutSession, error) {
+	return m.GetCheckoutSessionByPaymentIntentFunc(ctx, intentID)
+}
+
+func (m *MockRepository) CreateCheckoutPaymentIntent(ctx context.Context, session *CheckoutSession, intent *PaymentIntent) error {
+	return m.CreateCheckoutPaymentIntentFunc(ctx, session, intent)
+}
+
+func (m *MockRepository) UpdateCheckoutSession(ctx context.Context, session *CheckoutSession, from CheckoutSessionStatus) error {
+	return m.UpdateCheckoutSessionFunc(ctx, session, from)
+}
+
+func (m *MockRepository) ListExpiredCheckoutSessions(ctx context.Context, before time.Time, limit int) ([]CheckoutSession, error) {
+	return m.ListExpiredCheckoutSessionsFunc(ctx, before, limit)
+}
+
+func (m *MockRepository) CreatePaymentLink(ctx context.Context, link *PaymentLink) error {
+	return m.CreatePaymentLinkFunc(ctx, link)
+}
+
+func (m *MockRepository) GetPaymentLink(ctx context.Context, id string) (*PaymentLink, error) {
+	return m.GetPaymentLinkFunc(ctx, id)
+}
+
+func (m *MockRepository) DeactivatePaymentLink(ctx context.Context, id string) (*PaymentLink, error) {
+	return m.DeactivatePaymentLinkFunc(ctx, id)
+}
+
+func (m *MockRepository) CreateCustomer(ctx context.Context, customer *Customer) error {
+	return m.CreateCustomerFunc(ctx, customer)
+}
+
+func (m *MockRepository) GetCustomer(ctx context.Context, id string) (*Customer, error) {
+	return m.GetCustomerFunc(ctx, id)
+}
+
+func (m *MockRepository) CreatePaymentMethod(ctx context.Context, pm *PaymentMethod) error {
+	return m.CreatePaymentMethodFunc(ctx, pm)
+}
+
+func (m *MockRepository) GetPaymentMethod(ctx context.Context, id string) (*PaymentMethod, error) {
+	return m.GetPaymentMethodFunc(ctx, id)
+}
+
+func (m *MockRepository) ListPaymentMethods(ctx context.Context, customerID string) ([]PaymentMethod, error) {
+	return m.ListPaymentMethodsFunc(ctx, customerID)
+}
+
+func (m *MockRepository) AttachPaymentMethod(ctx context.Context, id, customerID string, makeDefault bool) error {
+	return m.AttachPaymentMethodFunc(ctx, id, customerID, makeDefault)
+}
+
+func (m *MockRepository) DetachPaymentMethod(ctx context.Context, pm *PaymentMethod, at time.Time) error {
+	return m.DetachPaymentMethodFunc(ctx, pm, at)
 }
@@ -6,23 +6,50 @@ import (
 
 // PaymentIntent represents a payment transaction intent.
 type PaymentIntent struct {
-	ID                   string    `json:"id"`
-	ZoneID               string    `json:"zone_id"`
-	Mode                 string    `json:"mode"`
-	Amount               int64     `json:"amount"` // In cents
-	Currency             string    `json:"currency"`
-	Status               string    `json:"status"` // requires_payment_method, succeeded, failed
-	Description          string    `json:"description,omitempty"`
-	UserID               string    `json:"user_id"`
-	ApplicationFeeAmount int64     `json:"application_fee_amount,omitempty"`
-	OnBehalfOf           string    `json:"on_behalf_of,omitempty"`
-	CreatedAt            time.Time `json:"created_at"`
+	ID                   string        `json:"id"`
+	ZoneID               string        `json:"zone_id"`
+	Mode                 string        `json:"mode"`
+	Amount               int64         `json:"amount"` // In cents
+	Currency             string        `json:"currency"`
+	Status               Status        `json:"status"`
+	CaptureMethod        CaptureMethod `json:"capture_method"`
+	AmountCapturable     int64         `json:"amount_capturable"`
+	AmountReceived       int64         `json:"amount_received"`
+	AmountRefunded       int64         `json:"amount_refunded"`
+	Description          string        `json:"description,omitempty"`
+	UserID               string        `json:"user_id"`
+	ApplicationFeeAmount int64         `json:"application_fee_amount,omitempty"`
+	OnBehalfOf           string        `json:"on_behalf_of,omitempty"`
+	// CustomerID is set on intents paid by a customer's saved payment
+	// methods.
+	CustomerID string `json:"customer_id,omitempty"`
+	// Processor is the processor adapter the intent was confirmed with;
+	// captures, voids and settlements go through the same one.
+	Processor string `json:"processor,omitempty"`
+	// ClientSecret lets the customer's client complete an action on the
+	// intent without the merchant's credentials.
+	ClientSecret string `json:"client_secret,omitempty"`
+	// NextAction is set while the intent requires action.
+	NextAction *NextAction `json:"next_action,omitempty"`
+	// ChallengeID is the processor's reference for the authentication
+	// challenge of NextAction.
+	ChallengeID string `json:"-"`
+	// AuthorizationID is the bank's reference for a held authorization.
+	AuthorizationID string `json:"-"`
+	// ProcessorTransactionID is the processor's reference for the charge or
+	// capture, which its settlement webhook refers to.
+	ProcessorTransactionID string     `json:"-"`
+	CaptureBefore          *time.Time `json:"capture_before,omitempty"`
+	// SettledAt is when the processor reported the funds settled.
+	SettledAt *time.Time `json:"settled_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
-// IdempotencyRecord keys response.
-type IdempotencyRecord struct {
-	UserID       string
-	Key          string
-	ResponseBody string
-	StatusCode   int
-}
+// CaptureMethod decides whether confirming an intent settles it at once or
+// only authorizes it for a later capture.
+type CaptureMethod string
+
+const (
+	CaptureMethodAutomatic CaptureMethod = "automatic"
+	CaptureMethodManual    CaptureMethod = "manual"
+)
@@ -0,0 +1,309 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapliy/fintech-ecosystem/pkg/validation"
+)
+
+// PaymentMethodTypeCard is the type of payment methods saved from card
+// details, the only type so far.
+const PaymentMethodTypeCard = "card"
+
+// Customer is a merchant's customer. Payment methods attached to a customer
+// can be charged again without the customer present, as subscriptions do.
+type Customer struct {
+	ID    string `json:"id"`
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+	// DefaultPaymentMethodID is charged when an intent for the customer is
+	// confirmed without a payment method.
+	DefaultPaymentMethodID string    `json:"default_payment_method_id,omitempty"`
+	UserID                 string    `json:"user_id"`
+	ZoneID                 string    `json:"zone_id"`
+	Mode                   string    `json:"mode"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+// Card is what is shown of a saved card.
+type Card struct {
+	Brand    string `json:"brand"`
+	Last4    string `json:"last4"`
+	ExpMonth int    `json:"exp_month"`
+	ExpYear  int    `json:"exp_year"`
+}
+
+// CardDetails are the details of a card as entered by its holder. They are
+// tokenized into a PaymentMethod and never stored as they are: the number
+// is encrypted and the security code is dropped once checked.
+type CardDetails struct {
+	Number   string `json:"number"`
+	ExpMonth int    `json:"exp_month"`
+	ExpYear  int    `json:"exp_year"`
+	CVC      string `json:"cvc"`
+}
+
+// PaymentMethod is a tokenized card. Its ID is used in place of the card
+// to confirm intents.
+type PaymentMethod struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Card Card   `json:"card"`
+	// CustomerID is set while the method is attached to a customer; it then
+	// only pays for intents of that customer.
+	CustomerID string `json:"customer_id,omitempty"`
+	// DetachedAt is set once the method was detached. It cannot be used
+	// again.
+	DetachedAt *time.Time `json:"detached_at,omitempty"`
+	UserID     string     `json:"user_id"`
+	ZoneID     string     `json:"zone_id"`
+	Mode       string     `json:"mode"`
+	// EncryptedCard is the card number encrypted with the zone's key. It
+	// never leaves the service.
+	EncryptedCard string    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Cipher encrypts card numbers with a key per zone, like
+// tenant.KeyManager.
+type Cipher interface {
+	// EnsureTenantKey creates the zone's key if it has none yet.
+	EnsureTenantKey(tenantID string) error
+	Encrypt(tenantID string, plaintext []byte) (string, error)
+	Decrypt(tenantID string, ciphertext string) ([]byte, error)
+}
+
+// PaymentMethodService keeps customers and their saved payment methods,
+// and turns a payment method back into the card a processor charges.
+type PaymentMethodService struct {
+	repo   Repository
+	cipher Cipher
+}
+
+func NewPaymentMethodService(repo Repository, cipher Cipher) *PaymentMethodService {
+	return &PaymentMethodService{repo: repo, cipher: cipher}
+}
+
+func (s *PaymentMethodService) CreateCustomer(ctx context.Context, customer *Customer) error {
+	validators := []validation.Validator{validation.NotEmpty(customer.ZoneID, "zone_id")}
+	if customer.Email != "" {
+		validators = append(validators, validation.Email(customer.Email))
+	}
+	if err := validation.Validate(validators...); err != nil {
+		return invalid(err)
+	}
+
+	customer.ID = uuid.New().String()
+	customer.DefaultPaymentMethodID = ""
+	return s.repo.CreateCustomer(ctx, customer)
+}
+
+func (s *PaymentMethodService) GetCustomer(ctx context.Context, id string) (*Customer, error) {
+	customer, err := s.repo.GetCustomer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
+}
+
+// CreatePaymentMethod tokenizes a card into pm, encrypting its number with
+// the key of pm's zone.
+func (s *PaymentMethodService) CreatePaymentMethod(ctx context.Context, pm *PaymentMethod, card CardDetails, now time.Time) error {
+	number, err := validateCard(card, now)
+	if err != nil {
+		return err
+	}
+	if err := validation.NotEmpty(pm.ZoneID, "zone_id")(); err != nil {
+		return invalid(err)
+	}
+
+	if err := s.cipher.EnsureTenantKey(pm.ZoneID); err != nil {
+		return fmt.Errorf("failed to get the zone's encryption key: %w", err)
+	}
+	encrypted, err := s.cipher.Encrypt(pm.ZoneID, []byte(number))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt card: %w", err)
+	}
+
+	pm.ID = uuid.New().String()
+	pm.Type = PaymentMethodTypeCard
+	pm.Card = Card{
+		Brand:    cardBrand(number),
+		Last4:    number[len(number)-4:],
+		ExpMonth: card.ExpMonth,
+		ExpYear:  card.ExpYear,
+	}
+	pm.EncryptedCard = encrypted
+	pm.CustomerID, pm.DetachedAt = "", nil
+	return s.repo.CreatePaymentMethod(ctx, pm)
+}
+
+func (s *PaymentMethodService) GetPaymentMethod(ctx context.Context, id string) (*PaymentMethod, error) {
+	pm, err := s.repo.GetPaymentMethod(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pm == nil {
+		return nil, ErrPaymentMethodNotFound
+	}
+	return pm, nil
+}
+
+// ListPaymentMethods returns the payment methods attached to a customer.
+func (s *PaymentMethodService) ListPaymentMethods(ctx context.Context, customerID string) ([]PaymentMethod, error) {
+	if _, err := s.GetCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPaymentMethods(ctx, customerID)
+}
+
+// AttachPaymentMethod attaches a payment method to a customer of the same
+// merchant and zone. It becomes the customer's default when makeDefault is
+// set or the customer has no default yet.
+func (s *PaymentMethodService) AttachPaymentMethod(ctx context.Context, id, customerID string, makeDefault bool) (*PaymentMethod, error) {
+	pm, err := s.GetPaymentMethod(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := s.GetCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.UserID != pm.UserID || customer.ZoneID != pm.ZoneID {
+		return nil, ErrCustomerNotFound
+	}
+	if pm.DetachedAt != nil || (pm.CustomerID != "" && pm.CustomerID != customer.ID) {
+		return nil, ErrPaymentMethodUnavailable
+	}
+
+	if err := s.repo.AttachPaymentMethod(ctx, pm.ID, customer.ID, makeDefault); err != nil {
+		return nil, err
+	}
+	pm.CustomerID = customer.ID
+	return pm, nil
+}
+
+// DetachPaymentMethod detaches a payment method from its customer for
+// good, dropping it as the customer's default.
+func (s *PaymentMethodService) DetachPaymentMethod(ctx context.Context, id string, now time.Time) (*PaymentMethod, error) {
+	pm, err := s.GetPaymentMethod(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pm.DetachedAt != nil {
+		return pm, nil
+	}
+	if err := s.repo.DetachPaymentMethod(ctx, pm, now); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// CardToken returns what the processor charges for intent confirmed with
+// paymentMethodID. A saved payment method is decrypted into its card
+// number; any other value is a token issued by the processor and passed on
+// as is. An intent for a customer confirmed without a payment method is
+// paid with the customer's default one.
+func (s *PaymentMethodService) CardToken(ctx context.Context, intent *PaymentIntent, paymentMethodID string) (string, error) {
+	if paymentMethodID == "" && intent.CustomerID != "" {
+		customer, err := s.GetCustomer(ctx, intent.CustomerID)
+		if err != nil {
+			return "", err
+		}
+		if customer.DefaultPaymentMethodID == "" {
+			return "", &ValidationError{msg: "payment_method_id is required: the customer has no default payment method"}
+		}
+		paymentMethodID = customer.DefaultPaymentMethodID
+	}
+	if _, err := uuid.Parse(paymentMethodID); err != nil {
+		return paymentMethodID, nil
+	}
+
+	pm, err := s.repo.GetPaymentMethod(ctx, paymentMethodID)
+	if err != nil {
+		return "", err
+	}
+	if pm == nil || pm.UserID != intent.UserID || pm.ZoneID != intent.ZoneID {
+		return "", ErrPaymentMethodNotFound
+	}
+	if pm.DetachedAt != nil || (pm.CustomerID != "" && pm.CustomerID != intent.CustomerID) {
+		return "", ErrPaymentMethodUnavailable
+	}
+	number, err := s.cipher.Decrypt(pm.ZoneID, pm.EncryptedCard)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt payment method %s: %w", pm.ID, err)
+	}
+	return string(number), nil
+}
+
+// validateCard checks a card and returns its number without separators.
+// Expired cards are rejected.
+func validateCard(card CardDetails, now time.Time) (string, error) {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(card.Number)
+	if len(number) < 12 || len(number) > 19 || !digits(number) || !luhn(number) {
+		return "", &ValidationError{msg: "card number is invalid"}
+	}
+	if card.ExpMonth < 1 || card.ExpMonth > 12 {
+		return "", &ValidationError{msg: "exp_month must be between 1 and 12"}
+	}
+	// A card is valid through the last day of its expiry month.
+	if !now.Before(time.Date(card.ExpYear, time.Month(card.ExpMonth)+1, 1, 0, 0, 0, 0, time.UTC)) {
+		return "", &ValidationError{msg: "card is expired"}
+	}
+	if (len(card.CVC) != 3 && len(card.CVC) != 4) || !digits(card.CVC) {
+		return "", &ValidationError{msg: "cvc must be 3 or 4 digits"}
+	}
+	return number, nil
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// luhn reports whether number passes the Luhn checksum of card numbers.
+func luhn(number string) bool {
+	sum := 0
+	for i := range number {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// cardBrand tells the card network from the number's prefix.
+func cardBrand(number string) string {
+	prefix := func(n int) int {
+		p, _ := strconv.Atoi(number[:n])
+		return p
+	}
+	switch {
+	case number[0] == '4':
+		return "visa"
+	case prefix(2) >= 51 && prefix(2) <= 55, prefix(4) >= 2221 && prefix(4) <= 2720:
+		return "mastercard"
+	case prefix(2) == 34, prefix(2) == 37:
+		return "amex"
+	case prefix(4) == 6011, prefix(2) == 65, prefix(3) >= 644 && prefix(3) <= 649:
+		return "discover"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/tenant"
+)
+
+// vaultRepo keeps customers and payment methods in memory.
+func vaultRepo(customers map[string]*Customer, methods map[string]*PaymentMethod) *MockRepository {
+	return &MockRepository{
+		GetCustomerFunc: func(ctx context.Context, id string) (*Customer, error) {
+			return customers[id], nil
+		},
+		CreatePaymentMethodFunc: func(ctx context.Context, pm *PaymentMethod) error {
+			methods[pm.ID] = pm
+			return nil
+		},
+		GetPaymentMethodFunc: func(ctx context.Context, id string) (*PaymentMethod, error) {
+			return methods[id], nil
+		},
+	}
+}
+
+func newTestKeyManager(t *testing.T) *tenant.KeyManager {
+	t.Helper()
+	keys, err := tenant.NewKeyManager(make([]byte, 32), tenant.NewInMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	return keys
+}
+
+func TestPaymentMethodService_CreatePaymentMethod(t *testing.T) {
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	visa := CardDetails{Number: "4242 4242 4242 4242", ExpMonth: 12, ExpYear: 2028, CVC: "123"}
+
+	tests := []struct {
+		name      string
+		card      func(c *CardDetails)
+		wantBrand string
+		wantErr   bool
+	}{
+		{name: "visa", card: func(c *CardDetails) {}, wantBrand: "visa"},
+		{name: "mastercard", card: func(c *CardDetails) { c.Number = "5555555555554444" }, wantBrand: "mastercard"},
+		{name: "expires this month", card: func(c *CardDetails) { c.ExpMonth, c.ExpYear = 3, 2026 }, wantBrand: "visa"},
+		{name: "bad checksum", card: func(c *CardDetails) { c.Number = "4242424242424241" }, wantErr: true},
+		{name: "not a number", card: func(c *CardDetails) { c.Number = "4242abcd42424242" }, wantErr: true},
+		{name: "expired", card: func(c *CardDetails) { c.ExpMonth, c.ExpYear = 2, 2026 }, wantErr: true},
+		{name: "bad month", card: func(c *CardDetails) { c.ExpMonth = 13 }, wantErr: true},
+		{name: "missing cvc", card: func(c *CardDetails) { c.CVC = "" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods := map[string]*PaymentMethod{}
+			keys := newTestKeyManager(t)
+			s := NewPaymentMethodService(vaultRepo(nil, methods), keys)
+			card := visa
+			tt.card(&card)
+
+			pm := &PaymentMethod{UserID: "user_1", ZoneID: "zone_1"}
+			err := s.CreatePaymentMethod(context.Background(), pm, card, now)
+			if tt.wantErr {
+				if !IsValidationError(err) || len(methods) != 0 {
+					t.Fatalf("CreatePaymentMethod() error = %v, stored %d, want a validation error", err, len(methods))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePaymentMethod() error = %v", err)
+			}
+
+			number := strings.ReplaceAll(card.Number, " ", "")
+			if pm.Card.Brand != tt.wantBrand || pm.Card.Last4 != number[len(number)-4:] || pm.Card.ExpMonth != card.ExpMonth {
+				t.Errorf("Card = %+v, want brand %s and last4 of %s", pm.Card, tt.wantBrand, number)
+			}
+			if pm.EncryptedCard == "" || strings.Contains(pm.EncryptedCard, number) {
+				t.Fatalf("EncryptedCard = %q, want the number encrypted", pm.EncryptedCard)
+			}
+			plain, err := keys.Decrypt("zone_1", pm.EncryptedCard)
+			if err != nil || string(plain) != number {
+				t.Errorf("Decrypt() = %q, %v, want %s", plain, err, number)
+			}
+		})
+	}
+}
+
+func TestPaymentMethodService_CardToken(t *testing.T) {
+	const (
+		savedID    = "0b8c5b8e-8f3e-4a56-9d36-5b7f1c0a1a01"
+		attachedID = "0b8c5b8e-8f3e-4a56-9d36-5b7f1c0a1a02"
+		detachedID = "0b8c5b8e-8f3e-4a56-9d36-5b7f1c0a1a03"
+	)
+	keys := newTestKeyManager(t)
+	if err := keys.EnsureTenantKey("zone_1"); err != nil {
+		t.Fatalf("EnsureTenantKey: %v", err)
+	}
+	sealed := func(number string) string {
+		ciphertext, err := keys.Encrypt("zone_1", []byte(number))
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		return ciphertext
+	}
+	detachedAt := time.Now()
+	methods := map[string]*PaymentMethod{
+		savedID:    {ID: savedID, UserID: "user_1", ZoneID: "zone_1", EncryptedCard: sealed("4242424242424242")},
+		attachedID: {ID: attachedID, CustomerID: "cus_1", UserID: "user_1", ZoneID: "zone_1", EncryptedCard: sealed("5555555555554444")},
+		detachedID: {ID: detachedID, DetachedAt: &detachedAt, UserID: "user_1", ZoneID: "zone_1", EncryptedCard: sealed("4000000000000002")},
+	}
+	customers := map[string]*Customer{
+		"cus_1": {ID: "cus_1", DefaultPaymentMethodID: attachedID, UserID: "user_1", ZoneID: "zone_1"},
+		"cus_2": {ID: "cus_2", UserID: "user_1", ZoneID: "zone_1"},
+	}
+	s := NewPaymentMethodService(vaultRepo(customers, methods), keys)
+
+	tests := []struct {
+		name            string
+		intent          PaymentIntent
+		paymentMethodID string
+		want            string
+		wantErr         error
+		wantInvalid     bool
+	}{
+		{name: "processor token", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_1"}, paymentMethodID: "tok_visa", want: "tok_visa"},
+		{name: "saved card", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_1"}, paymentMethodID: savedID, want: "4242424242424242"},
+		{name: "customer's card", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_1", CustomerID: "cus_1"}, paymentMethodID: attachedID, want: "5555555555554444"},
+		{name: "customer's default", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_1", CustomerID: "cus_1"}, want: "5555555555554444"},
+		{name: "customer without default", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_1", CustomerID: "cus_2"}, wantInvalid: true},
+		{name: "another customer's card", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_1", CustomerID: "cus_2"}, paymentMethodID: attachedID, wantErr: ErrPaymentMethodUnavailable},
+		{name: "detached card", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_1"}, paymentMethodID: detachedID, wantErr: ErrPaymentMethodUnavailable},
+		{name: "another merchant's card", intent: PaymentIntent{UserID: "user_2", ZoneID: "zone_1"}, paymentMethodID: savedID, wantErr: ErrPaymentMethodNotFound},
+		{name: "another zone's card", intent: PaymentIntent{UserID: "user_1", ZoneID: "zone_2"}, paymentMethodID: savedID, wantErr: ErrPaymentMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.CardToken(context.Background(), &tt.intent, tt.paymentMethodID)
+			if tt.wantInvalid {
+				if !IsValidationError(err) {
+					t.Fatalf("CardToken() error = %v, want a validation error", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CardToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CardToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapliy/fintech-ecosystem/pkg/validation"
+)
+
+// RefundStatus is the state of a refund. A refund is pending while the
+// processor returns its funds and its ledger reversal is posted; a pending
+// refund holds its amount so concurrent refunds cannot exceed what was
+// received.
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"
+	RefundSucceeded RefundStatus = "succeeded"
+	RefundFailed    RefundStatus = "failed"
+)
+
+// Refund returns part or all of a payment's received amount. An intent may
+// have several refunds up to its amount_received.
+type Refund struct {
+	ID              string       `json:"id"`
+	PaymentIntentID string       `json:"payment_intent_id"`
+	Amount          int64        `json:"amount"`
+	Currency        string       `json:"currency"`
+	Reason          string       `json:"reason,omitempty"`
+	Status          RefundStatus `json:"status"`
+	FailureReason   string       `json:"failure_reason,omitempty"`
+	// LedgerReference is the reference the refund's ledger reversal is
+	// posted under.
+	LedgerReference     string    `json:"-"`
+	LedgerTransactionID string    `json:"ledger_transaction_id,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// Refund reasons accepted from clients.
+var refundReasons = []string{"", "duplicate", "fraudulent", "requested_by_customer"}
+
+// RefundLedgerReference is the ledger reference of a refund's reversal. The
+// reconciler relies on it starting with the payment ID.
+func RefundLedgerReference(paymentID, refundID string) string {
+	return "refund_" + paymentID + "_" + refundID
+}
+
+// RefundAmount checks that amount can be refunded from intent while pending
+// is held by other pending refunds, and returns the amount to refund. An
+// amount of zero refunds everything left.
+func RefundAmount(intent *PaymentIntent, pending, amount int64) (int64, error) {
+	if intent.Status != StatusSucceeded && intent.Status != StatusPartiallyRefunded {
+		return 0, fmt.Errorf("%w: cannot refund an intent in status %s", ErrInvalidTransition, intent.Status)
+	}
+	refundable := intent.AmountReceived - intent.AmountRefunded - pending
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount <= 0 || amount > refundable {
+		return 0, fmt.Errorf("%w: %d requested, %d refundable", ErrRefundExceedsAmount, amount, refundable)
+	}
+	return amount, nil
+}
+
+// RefundTransition applies a completed refund to intent and returns the
+// status change it makes: refunded once everything received is returned,
+// partially_refunded before that.
+func RefundTransition(intent *PaymentIntent, refund *Refund) (Transition, error) {
+	intent.AmountRefunded += refund.Amount
+	to := StatusPartiallyRefunded
+	if intent.AmountRefunded >= intent.AmountReceived {
+		to = StatusRefunded
+	}
+	return NewTransition(intent.Status, to, refund.Reason)
+}
+
+// RefundService creates refunds, has the processor return their funds and
+// posts their reversals to the ledger.
+type RefundService struct {
+	repo       Repository
+	ledger     Ledger
+	processors Processors
+}
+
+func NewRefundService(repo Repository, ledger Ledger, processors Processors) *RefundService {
+	return &RefundService{repo: repo, ledger: ledger, processors: processors}
+}
+
+// CreateRefund refunds amount of a succeeded intent, or everything left
+// when amount is zero. The refund is stored as pending, returned by the
+// processor, reversed in the ledger and then completed, which moves the
+// intent to partially_refunded or refunded and emits EventPaymentRefunded.
+// If the processor declines the refund it is returned as failed along with
+// ErrRefundFailed. If the outcome is unknown it is returned as pending
+// along with ErrRefundPending, and RetryRefund finishes it later.
+func (s *RefundService) CreateRefund(ctx context.Context, intentID string, amount int64, reason string) (*Refund, error) {
+	if err := validation.Validate(
+		validation.NotEmpty(intentID, "payment_intent_id"),
+		validation.InList(reason, refundReasons, "reason"),
+	); err != nil {
+		return nil, invalid(err)
+	}
+	if amount < 0 {
+		return nil, &ValidationError{msg: "amount must not be negative"}
+	}
+
+	refund := &Refund{
+		ID:              uuid.New().String(),
+		PaymentIntentID: intentID,
+		Amount:          amount,
+		Reason:          reason,
+		Status:          RefundPending,
+	}
+	refund.LedgerReference = RefundLedgerReference(intentID, refund.ID)
+	if err := s.repo.CreateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+
+	return refund, s.post(ctx, refund)
+}
+
+// RetryRefund refunds a pending refund at the processor and posts its
+// reversal again, and completes the refund. Both are made under the
+// refund's own references, so a refund or reversal that was already made
+// is returned rather than made twice.
+func (s *RefundService) RetryRefund(ctx context.Context, refund *Refund) error {
+	return s.post(ctx, refund)
+}
+
+// ListPendingRefunds returns refunds created before the given time that are
+// still pending.
+func (s *RefundService) ListPendingRefunds(ctx context.Context, before time.Time, limit int) ([]Refund, error) {
+	return s.repo.ListPendingRefunds(ctx, before, limit)
+}
+
+// post has the processor return a pending refund's funds, reverses the
+// refund in the ledger and completes it. Only a processor decline fails the
+// refund and releases its amount. After any other error the funds may have
+// been returned, so the refund is left pending.
+func (s *RefundService) post(ctx context.Context, refund *Refund) error {
+	intent, err := s.repo.GetPaymentIntent(ctx, refund.PaymentIntentID)
+	if err != nil || intent == nil {
+		return fmt.Errorf("%w: payment intent %s not loaded: %v", ErrRefundPending, refund.PaymentIntentID, err)
+	}
+	if _, err := s.processors.RefundPayment(ctx, intent, refund.ID, refund.Amount); err != nil {
+		if errors.Is(err, ErrProcessorDeclined) {
+			refund.Status = RefundFailed
+			refund.FailureReason = err.Error()
+			if ferr := s.repo.FailRefund(ctx, refund); ferr != nil {
+				log.Printf("Refunds: failed to mark refund %s failed: %v", refund.ID, ferr)
+			}
+			return fmt.Errorf("%w: %v", ErrRefundFailed, err)
+		}
+		return fmt.Errorf("%w: processor refund not confirmed: %v", ErrRefundPending, err)
+	}
+
+	ledgerReason := "Refund " + refund.ID
+	if refund.Reason != "" {
+		ledgerReason += ": " + refund.Reason
+	}
+	txID, err := s.ledger.ReversePayment(ctx, intent, refund.LedgerReference, refund.Amount, ledgerReason)
+	if errors.Is(err, ErrLedgerRejected) {
+		// The cardholder has the funds back, so the refund cannot fail.
+		log.Printf("Refunds: ledger rejected the reversal of refund %s, whose funds were returned: %v", refund.ID, err)
+		return fmt.Errorf("%w: funds returned but reversal rejected: %v", ErrRefundPending, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: reversal not confirmed: %v", ErrRefundPending, err)
+	}
+
+	refund.LedgerTransactionID = txID
+	if err := s.repo.CompleteRefund(ctx, refund); err != nil {
+		return fmt.Errorf("%w: reversal %s posted but not applied: %v", ErrRefundPending, txID, err)
+	}
+	refund.Status = RefundSucceeded
+	return nil
+}
+
+// ListRefunds returns an intent's refunds, oldest first.
+func (s *RefundService) ListRefunds(ctx context.Context, intentID string) ([]Refund, error) {
+	intent, err := s.repo.GetPaymentIntent(ctx, intentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPaymentIntentNotFound
+	}
+	return s.repo.ListRefunds(ctx, intentID)
+}
@@ -2,13 +2,118 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
+	// CreatePaymentIntent stores the intent, records its initial status in
+	// the history and queues EventPaymentCreated in the same transaction.
 	CreatePaymentIntent(ctx context.Context, intent *PaymentIntent) error
 	GetPaymentIntent(ctx context.Context, id string) (*PaymentIntent, error)
-	UpdateStatus(ctx context.Context, id, status string) error
-	GetIdempotencyKey(ctx context.Context, userID, key string) (*IdempotencyRecord, error)
-	SaveIdempotencyKey(ctx context.Context, userID, key string, statusCode int, body string) error
+	// GetPaymentIntentByProcessorTransaction returns the intent whose charge
+	// or capture the processor knows by transactionID, or nil.
+	GetPaymentIntentByProcessorTransaction(ctx context.Context, processor, transactionID string) (*PaymentIntent, error)
+	// UpdateStatus applies t if the intent is still in t.From, returning
+	// ErrStatusConflict otherwise. The intent's capture and processor fields
+	// are stored with the status, and the history entry and t.Event are written in the
+	// same transaction.
+	UpdateStatus(ctx context.Context, intent *PaymentIntent, t Transition) error
+	ListStatusHistory(ctx context.Context, id string) ([]StatusChange, error)
+	// ListExpiredAuthorizations returns intents in StatusRequiresCapture
+	// whose authorization expired before the given time, oldest first.
+	ListExpiredAuthorizations(ctx context.Context, before time.Time, limit int) ([]PaymentIntent, error)
+	// ListExpiredActions returns intents in StatusRequiresAction whose
+	// challenge expired before the given time, oldest first.
+	ListExpiredActions(ctx context.Context, before time.Time, limit int) ([]PaymentIntent, error)
+	// MarkSettled records the settlement of the intent the processor knows
+	// by transactionID, keeping the first settlement time when repeated. It
+	// returns ErrPaymentIntentNotFound when no intent matches.
+	MarkSettled(ctx context.Context, processor, transactionID string, at time.Time) error
+	// CreateRefund stores a pending refund after checking it with
+	// RefundAmount against the locked intent, filling in its amount when
+	// zero and its currency. It returns ErrPaymentDisputed while the intent
+	// has a dispute that is not won.
+	CreateRefund(ctx context.Context, refund *Refund) error
+	// CompleteRefund marks a pending refund succeeded and applies it to the
+	// locked intent with RefundTransition, writing the history entry and
+	// EventPaymentRefunded in the same transaction.
+	CompleteRefund(ctx context.Context, refund *Refund) error
+	FailRefund(ctx context.Context, refund *Refund) error
+	ListRefunds(ctx context.Context, intentID string) ([]Refund, error)
+	// ListPendingRefunds returns up to limit refunds created before the
+	// given time that are still pending, oldest first.
+	ListPendingRefunds(ctx context.Context, before time.Time, limit int) ([]Refund, error)
+	// CreateDispute stores a dispute and queues EventDisputeCreated in the
+	// same transaction. A dispute the processor already reported is loaded
+	// into dispute instead, without an event.
+	CreateDispute(ctx context.Context, dispute *Dispute) error
+	GetDispute(ctx context.Context, id string) (*Dispute, error)
+	GetDisputeByProcessorID(ctx context.Context, processor, processorDisputeID string) (*Dispute, error)
+	ListDisputes(ctx context.Context, intentID string) ([]Dispute, error)
+	// UpdateDispute stores the dispute's status and evidence if it is still
+	// in from, returning ErrStatusConflict otherwise, and queues the event
+	// of its new status in the same transaction.
+	UpdateDispute(ctx context.Context, dispute *Dispute, from DisputeStatus) error
+	// ListOverdueDisputes returns disputes needing a response whose
+	// deadline passed before the given time, oldest first.
+	ListOverdueDisputes(ctx context.Context, before time.Time, limit int) ([]Dispute, error)
 	ListPaymentIntents(ctx context.Context, zoneID string, limit int) ([]PaymentIntent, error)
+	CreateCheckoutSession(ctx context.Context, session *CheckoutSession) error
+	GetCheckoutSession(ctx context.Context, id string) (*CheckoutSession, error)
+	GetCheckoutSessionByPaymentIntent(ctx context.Context, intentID string) (*CheckoutSession, error)
+	// CreateCheckoutPaymentIntent stores intent like CreatePaymentIntent
+	// and sets it as the payment of the open session in the same
+	// transaction. If the session got a payment meanwhile, that intent is
+	// loaded into intent instead; if it closed, ErrCheckoutSessionClosed is
+	// returned.
+	CreateCheckoutPaymentIntent(ctx context.Context, session *CheckoutSession, intent *PaymentIntent) error
+	// UpdateCheckoutSession stores the session's status and completion time
+	// if it is still in from, returning ErrStatusConflict otherwise, and
+	// queues EventCheckoutSessionCompleted in the same transaction when it
+	// completes.
+	UpdateCheckoutSession(ctx context.Context, session *CheckoutSession, from CheckoutSessionStatus) error
+	// ListExpiredCheckoutSessions returns open sessions whose expiry passed
+	// before the given time, oldest first.
+	ListExpiredCheckoutSessions(ctx context.Context, before time.Time, limit int) ([]CheckoutSession, error)
+	CreatePaymentLink(ctx context.Context, link *PaymentLink) error
+	GetPaymentLink(ctx context.Context, id string) (*PaymentLink, error)
+	// DeactivatePaymentLink returns ErrPaymentLinkNotFound when no link
+	// matches.
+	DeactivatePaymentLink(ctx context.Context, id string) (*PaymentLink, error)
+	CreateCustomer(ctx context.Context, customer *Customer) error
+	GetCustomer(ctx context.Context, id string) (*Customer, error)
+	CreatePaymentMethod(ctx context.Context, pm *PaymentMethod) error
+	GetPaymentMethod(ctx context.Context, id string) (*PaymentMethod, error)
+	// ListPaymentMethods returns the payment methods attached to a
+	// customer, newest first.
+	ListPaymentMethods(ctx context.Context, customerID string) ([]PaymentMethod, error)
+	// AttachPaymentMethod attaches a payment method that is not detached
+	// or attached elsewhere to the customer, returning
+	// ErrPaymentMethodUnavailable otherwise. In the same transaction it
+	// becomes the customer's default if makeDefault is set or the customer
+	// has none.
+	AttachPaymentMethod(ctx context.Context, id, customerID string, makeDefault bool) error
+	// DetachPaymentMethod marks pm detached at the given time and clears
+	// it as its customer's default in the same transaction.
+	DetachPaymentMethod(ctx context.Context, pm *PaymentMethod, at time.Time) error
+}
+
+// Ledger posts refunds to the ledger.
+type Ledger interface {
+	// ReversePayment reverses amount of the payment's ledger posting under
+	// reference, in the payment's zone, and returns the reversal's
+	// transaction ID. Posting the same reference again returns the existing
+	// reversal. Errors wrap ErrLedgerRejected when the reversal was
+	// definitely not posted.
+	ReversePayment(ctx context.Context, payment *PaymentIntent, reference string, amount int64, reason string) (string, error)
+}
+
+// Processors returns refunded funds through the processor that took the
+// payment.
+type Processors interface {
+	// RefundPayment asks the payment's processor to return amount of its
+	// charge to the cardholder and returns the processor's refund ID.
+	// Refunding under the same refundID again returns the first refund.
+	// Errors wrap ErrProcessorDeclined when no funds were returned.
+	RefundPayment(ctx context.Context, payment *PaymentIntent, refundID string, amount int64) (string, error)
 }
@@ -2,49 +2,161 @@ package domain
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/sapliy/fintech-ecosystem/pkg/validation"
 )
 
+// ConnectedAccounts looks up the connected accounts of the connect service
+// that marketplace payments are made on behalf of.
+type ConnectedAccounts interface {
+	ConnectedAccountExists(ctx context.Context, id string) (bool, error)
+}
+
 type PaymentService struct {
-	repo Repository
+	repo     Repository
+	accounts ConnectedAccounts
 }
 
 func NewPaymentService(repo Repository) *PaymentService {
 	return &PaymentService{repo: repo}
 }
 
+// SetConnectedAccounts makes CreatePaymentIntent check that intents made on
+// behalf of a connected account name one that exists.
+func (s *PaymentService) SetConnectedAccounts(accounts ConnectedAccounts) {
+	s.accounts = accounts
+}
+
+// CreatePaymentIntent stores a new intent in StatusRequiresPaymentMethod.
+// An intent for a customer belongs to the customer's zone, which it takes
+// when created without one. An intent on behalf of a connected account is a
+// marketplace payment: once it succeeds, the ledger credits the connected
+// account with the amount less ApplicationFeeAmount, which the platform
+// keeps.
 func (s *PaymentService) CreatePaymentIntent(ctx context.Context, intent *PaymentIntent) error {
+	if intent.CustomerID != "" {
+		customer, err := s.repo.GetCustomer(ctx, intent.CustomerID)
+		if err != nil {
+			return err
+		}
+		if customer == nil || customer.UserID != intent.UserID || (intent.ZoneID != "" && intent.ZoneID != customer.ZoneID) {
+			return ErrCustomerNotFound
+		}
+		if intent.ZoneID == "" {
+			intent.ZoneID, intent.Mode = customer.ZoneID, customer.Mode
+		}
+	}
+	if err := prepareIntent(intent); err != nil {
+		return err
+	}
+	if intent.OnBehalfOf != "" && s.accounts != nil {
+		ok, err := s.accounts.ConnectedAccountExists(ctx, intent.OnBehalfOf)
+		if err != nil {
+			return fmt.Errorf("failed to look up connected account: %w", err)
+		}
+		if !ok {
+			return ErrConnectedAccountNotFound
+		}
+	}
+	return s.repo.CreatePaymentIntent(ctx, intent)
+}
+
+// prepareIntent validates a new intent and sets its defaults and initial
+// status.
+func prepareIntent(intent *PaymentIntent) error {
 	if err := validation.Validate(
 		validation.PositiveAmount(intent.Amount, "amount"),
 		validation.NotEmpty(intent.Currency, "currency"),
 		validation.NotEmpty(intent.ZoneID, "zone_id"),
+		validation.InList(string(intent.CaptureMethod), []string{"", string(CaptureMethodAutomatic), string(CaptureMethodManual)}, "capture_method"),
 	); err != nil {
-		return err
+		return invalid(err)
 	}
-	return s.repo.CreatePaymentIntent(ctx, intent)
+	if intent.ApplicationFeeAmount < 0 || intent.ApplicationFeeAmount > intent.Amount {
+		return &ValidationError{msg: "application_fee_amount must be between 0 and amount"}
+	}
+	if intent.ApplicationFeeAmount > 0 && intent.OnBehalfOf == "" {
+		return &ValidationError{msg: "application_fee_amount requires on_behalf_of"}
+	}
+	if _, err := uuid.Parse(intent.OnBehalfOf); intent.OnBehalfOf != "" && err != nil {
+		return &ValidationError{msg: "on_behalf_of must be a connected account ID"}
+	}
+	if intent.CaptureMethod == "" {
+		intent.CaptureMethod = CaptureMethodAutomatic
+	}
+	intent.Status = StatusRequiresPaymentMethod
+	return nil
 }
 
 func (s *PaymentService) GetPaymentIntent(ctx context.Context, id string) (*PaymentIntent, error) {
 	return s.repo.GetPaymentIntent(ctx, id)
 }
 
-func (s *PaymentService) UpdateStatus(ctx context.Context, id, status string) error {
+// UpdateStatus moves an intent to status to. The change is rejected with
+// ErrInvalidTransition unless the state machine allows it from the intent's
+// current status. It returns the updated intent.
+func (s *PaymentService) UpdateStatus(ctx context.Context, id string, to Status, reason string) (*PaymentIntent, error) {
 	if err := validation.Validate(
 		validation.NotEmpty(id, "id"),
-		validation.InList(status, []string{"CREATED", "PROCESSING", "SUCCEEDED", "FAILED", "CANCELLED"}, "status"),
+		validation.NotEmpty(string(to), "status"),
 	); err != nil {
-		return err
+		return nil, invalid(err)
+	}
+	if !to.Valid() {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidTransition, to)
+	}
+	if to == StatusPartiallyRefunded || to == StatusRefunded {
+		// Refunded amounts must match the ledger, so only refunds move here.
+		return nil, fmt.Errorf("%w: %s is reached by creating a refund", ErrInvalidTransition, to)
+	}
+
+	intent, err := s.repo.GetPaymentIntent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPaymentIntentNotFound
+	}
+	if to == StatusRequiresPaymentMethod {
+		// A failed attempt drops any authorization it was holding.
+		intent.AuthorizationID, intent.AmountCapturable, intent.CaptureBefore = "", 0, nil
 	}
-	return s.repo.UpdateStatus(ctx, id, status)
+	if err := s.transition(ctx, intent, to, reason); err != nil {
+		return nil, err
+	}
+	return intent, nil
 }
 
-func (s *PaymentService) GetIdempotencyKey(ctx context.Context, userID, key string) (*IdempotencyRecord, error) {
-	return s.repo.GetIdempotencyKey(ctx, userID, key)
+// transition moves intent to status to, storing any other fields the caller
+// changed on it in the same write.
+func (s *PaymentService) transition(ctx context.Context, intent *PaymentIntent, to Status, reason string) error {
+	t, err := NewTransition(intent.Status, to, reason)
+	if err != nil {
+		return err
+	}
+	if to != StatusRequiresAction && to != StatusProcessing {
+		// The challenge is over once the attempt ends.
+		intent.ChallengeID, intent.NextAction = "", nil
+	}
+	if err := s.repo.UpdateStatus(ctx, intent, t); err != nil {
+		return err
+	}
+	intent.Status = to
+	return nil
 }
 
-func (s *PaymentService) SaveIdempotencyKey(ctx context.Context, userID, key string, statusCode int, body string) error {
-	return s.repo.SaveIdempotencyKey(ctx, userID, key, statusCode, body)
+// GetStatusHistory returns an intent's status changes, oldest first.
+func (s *PaymentService) GetStatusHistory(ctx context.Context, id string) ([]StatusChange, error) {
+	intent, err := s.repo.GetPaymentIntent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPaymentIntentNotFound
+	}
+	return s.repo.ListStatusHistory(ctx, id)
 }
 
 func (s *PaymentService) ListPaymentIntents(ctx context.Context, zoneID string, limit int) ([]PaymentIntent, error) {
@@ -2,7 +2,10 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestPaymentService_ListPaymentIntents(t *testing.T) {
@@ -55,19 +58,6 @@ func TestPaymentService_CreatePaymentIntent(t *testing.T) {
 	}
 }
 
-func TestPaymentService_UpdateStatus(t *testing.T) {
-	ctx := context.Background()
-	repo := &MockRepository{
-		UpdateStatusFunc: func(ctx context.Context, id, status string) error {
-			return nil
-		},
-	}
-	service := NewPaymentService(repo)
-	err := service.UpdateStatus(ctx, "id", "SUCCEEDED")
-	if err != nil {
-		t.Fatalf("UpdateStatus failed: %v", err)
-	}
-}
 func TestPaymentService_CreatePaymentIntent_Validation(t *testing.T) {
 	ctx := context.Background()
 
@@ -80,6 +70,11 @@ func TestPaymentService_CreatePaymentIntent_Validation(t *testing.T) {
 		{"Zero amount", &PaymentIntent{Amount: 0, Currency: "USD", ZoneID: "z1"}, true},
 		{"Empty currency", &PaymentIntent{Amount: 100, Currency: "", ZoneID: "z1"}, true},
 		{"Empty zone", &PaymentIntent{Amount: 100, Currency: "USD", ZoneID: ""}, true},
+		{"Marketplace payment", &PaymentIntent{Amount: 100, Currency: "USD", ZoneID: "z1", ApplicationFeeAmount: 10, OnBehalfOf: connectedID}, false},
+		{"Fee above amount", &PaymentIntent{Amount: 100, Currency: "USD", ZoneID: "z1", ApplicationFeeAmount: 101, OnBehalfOf: connectedID}, true},
+		{"Negative fee", &PaymentIntent{Amount: 100, Currency: "USD", ZoneID: "z1", ApplicationFeeAmount: -1, OnBehalfOf: connectedID}, true},
+		{"Fee without connected account", &PaymentIntent{Amount: 100, Currency: "USD", ZoneID: "z1", ApplicationFeeAmount: 10}, true},
+		{"Connected account not an ID", &PaymentIntent{Amount: 100, Currency: "USD", ZoneID: "z1", OnBehalfOf: "acct_1"}, true},
 	}
 
 	for _, tt := range tests {
@@ -98,30 +93,332 @@ func TestPaymentService_CreatePaymentIntent_Validation(t *testing.T) {
 	}
 }
 
-func TestPaymentService_UpdateStatus_Validation(t *testing.T) {
+const connectedID = "7d7e0c1a-3c1f-4a8e-9a55-2f1b6f0d9c11"
+
+type stubConnectedAccounts map[string]bool
+
+func (s stubConnectedAccounts) ConnectedAccountExists(ctx context.Context, id string) (bool, error) {
+	return s[id], nil
+}
+
+func TestPaymentService_CreatePaymentIntent_ConnectedAccount(t *testing.T) {
+	tests := []struct {
+		name       string
+		onBehalfOf string
+		wantErr    error
+	}{
+		{name: "Known account", onBehalfOf: connectedID},
+		{name: "Unknown account", onBehalfOf: "0f4c1de2-52d8-4f0e-8a86-3f8f4b1d2e7a", wantErr: ErrConnectedAccountNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			repo := &MockRepository{
+				CreatePaymentIntentFunc: func(ctx context.Context, intent *PaymentIntent) error {
+					created = true
+					return nil
+				},
+			}
+			service := NewPaymentService(repo)
+			service.SetConnectedAccounts(stubConnectedAccounts{connectedID: true})
+
+			err := service.CreatePaymentIntent(context.Background(), &PaymentIntent{
+				Amount: 1000, Currency: "USD", ZoneID: "z1", ApplicationFeeAmount: 100, OnBehalfOf: tt.onBehalfOf,
+			})
+			if !errors.Is(err, tt.wantErr) || created != (tt.wantErr == nil) {
+				t.Errorf("CreatePaymentIntent() error = %v, created %v, want %v", err, created, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentService_UpdateStatus(t *testing.T) {
 	ctx := context.Background()
 
 	tests := []struct {
-		name    string
-		status  string
-		wantErr bool
+		name      string
+		from      Status
+		to        Status
+		wantEvent EventType
+		wantErr   error
 	}{
-		{"Valid status", "SUCCEEDED", false},
-		{"Invalid status", "INVALID", true},
-		{"Empty status", "", true},
+		{"confirm", StatusRequiresPaymentMethod, StatusProcessing, EventPaymentProcessing, nil},
+		{"charge succeeds", StatusProcessing, StatusSucceeded, EventPaymentSucceeded, nil},
+		{"charge declined", StatusProcessing, StatusRequiresPaymentMethod, EventPaymentFailed, nil},
+		{"authorized", StatusProcessing, StatusRequiresCapture, EventPaymentRequiresCapture, nil},
+		{"cancel", StatusRequiresCapture, StatusCanceled, EventPaymentCanceled, nil},
+		{"refund without a refund", StatusSucceeded, StatusRefunded, "", ErrInvalidTransition},
+		{"partially refund without a refund", StatusSucceeded, StatusPartiallyRefunded, "", ErrInvalidTransition},
+		{"cancel succeeded intent", StatusSucceeded, StatusCanceled, "", ErrInvalidTransition},
+		{"leave refunded", StatusRefunded, StatusSucceeded, "", ErrInvalidTransition},
+		{"leave canceled", StatusCanceled, StatusProcessing, "", ErrInvalidTransition},
+		{"unknown status", StatusProcessing, Status("SUCCEEDED"), "", ErrInvalidTransition},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			var got Transition
 			repo := &MockRepository{
-				UpdateStatusFunc: func(ctx context.Context, id, status string) error {
+				GetPaymentIntentFunc: func(ctx context.Context, id string) (*PaymentIntent, error) {
+					return &PaymentIntent{ID: id, Status: tt.from}, nil
+				},
+				UpdateStatusFunc: func(ctx context.Context, intent *PaymentIntent, t Transition) error {
+					got = t
 					return nil
 				},
 			}
 			service := NewPaymentService(repo)
-			err := service.UpdateStatus(ctx, "id", tt.status)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("UpdateStatus() error = %v, wantErr %v", err, tt.wantErr)
+			intent, err := service.UpdateStatus(ctx, "pi_1", tt.to, "")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateStatus() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateStatus() error = %v", err)
+			}
+			if got.From != tt.from || got.To != tt.to || got.Event != tt.wantEvent {
+				t.Errorf("transition = %+v, want %s -> %s with %s", got, tt.from, tt.to, tt.wantEvent)
+			}
+			if intent.Status != tt.to {
+				t.Errorf("status = %s, want %s", intent.Status, tt.to)
+			}
+		})
+	}
+}
+
+func TestPaymentService_UpdateStatus_NotFound(t *testing.T) {
+	repo := &MockRepository{
+		GetPaymentIntentFunc: func(ctx context.Context, id string) (*PaymentIntent, error) {
+			return nil, nil
+		},
+	}
+	service := NewPaymentService(repo)
+	if _, err := service.UpdateStatus(context.Background(), "pi_1", StatusCanceled, ""); !errors.Is(err, ErrPaymentIntentNotFound) {
+		t.Errorf("UpdateStatus() error = %v, want %v", err, ErrPaymentIntentNotFound)
+	}
+}
+
+func TestRefundAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   Status
+		refunded int64
+		pending  int64
+		amount   int64
+		want     int64
+		wantErr  error
+	}{
+		{name: "full refund", status: StatusSucceeded, want: 1000},
+		{name: "partial refund", status: StatusSucceeded, amount: 300, want: 300},
+		{name: "rest after partial refund", status: StatusPartiallyRefunded, refunded: 300, want: 700},
+		{name: "pending refund is held", status: StatusSucceeded, pending: 600, amount: 400, want: 400},
+		{name: "exceeds received", status: StatusSucceeded, amount: 1001, wantErr: ErrRefundExceedsAmount},
+		{name: "exceeds with pending", status: StatusPartiallyRefunded, refunded: 300, pending: 600, amount: 200, wantErr: ErrRefundExceedsAmount},
+		{name: "nothing left", status: StatusRefunded, refunded: 1000, wantErr: ErrInvalidTransition},
+		{name: "not paid", status: StatusRequiresCapture, wantErr: ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intent := &PaymentIntent{Status: tt.status, AmountReceived: 1000, AmountRefunded: tt.refunded}
+			got, err := RefundAmount(intent, tt.pending, tt.amount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("RefundAmount() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RefundAmount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RefundAmount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundService_CreateRefund(t *testing.T) {
+	tests := []struct {
+		name         string
+		refunded     int64
+		amount       int64
+		processorErr error
+		ledgerErr    error
+		wantStatus   RefundStatus
+		wantIntent   Status
+		wantErr      error
+		completeErr  error
+	}{
+		{"partial refund", 0, 400, nil, nil, RefundSucceeded, StatusPartiallyRefunded, nil, nil},
+		{"refund the rest", 400, 0, nil, nil, RefundSucceeded, StatusRefunded, nil, nil},
+		{"processor declines", 0, 400, fmt.Errorf("%w: amount_too_large", ErrProcessorDeclined), nil, RefundFailed, StatusSucceeded, ErrRefundFailed, nil},
+		{"processor unreachable", 0, 400, errors.New("processor unreachable"), nil, RefundPending, StatusSucceeded, ErrRefundPending, nil},
+		{"ledger rejects reversal", 0, 400, nil, fmt.Errorf("%w: transaction has already been fully reversed", ErrLedgerRejected), RefundPending, StatusSucceeded, ErrRefundPending, nil},
+		{"ledger unavailable", 0, 400, nil, errors.New("ledger unavailable"), RefundPending, StatusSucceeded, ErrRefundPending, nil},
+		{"completion fails after reversal", 0, 400, nil, nil, RefundPending, StatusSucceeded, ErrRefundPending, errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := StatusSucceeded
+			if tt.refunded > 0 {
+				status = StatusPartiallyRefunded
+			}
+			intent := &PaymentIntent{ID: "pi_1", Currency: "USD", Status: status, AmountReceived: 1000, AmountRefunded: tt.refunded}
+			var failed bool
+			repo := &MockRepository{
+				CreateRefundFunc: func(ctx context.Context, refund *Refund) error {
+					amount, err := RefundAmount(intent, 0, refund.Amount)
+					refund.Amount, refund.Currency = amount, intent.Currency
+					return err
+				},
+				GetPaymentIntentFunc: func(ctx context.Context, id string) (*PaymentIntent, error) {
+					return intent, nil
+				},
+				CompleteRefundFunc: func(ctx context.Context, refund *Refund) error {
+					if tt.completeErr != nil {
+						return tt.completeErr
+					}
+					tr, err := RefundTransition(intent, refund)
+					intent.Status = tr.To
+					return err
+				},
+				FailRefundFunc: func(ctx context.Context, refund *Refund) error {
+					failed = true
+					return nil
+				},
+			}
+			var processorRefund, reference string
+			processors := &MockProcessors{
+				RefundPaymentFunc: func(ctx context.Context, payment *PaymentIntent, refundID string, amount int64) (string, error) {
+					processorRefund = refundID
+					return "re_1", tt.processorErr
+				},
+			}
+			ledger := &MockLedger{
+				ReversePaymentFunc: func(ctx context.Context, payment *PaymentIntent, ref string, amount int64, reason string) (string, error) {
+					reference = ref
+					return "txn_1", tt.ledgerErr
+				},
+			}
+
+			refund, err := NewRefundService(repo, ledger, processors).CreateRefund(context.Background(), "pi_1", tt.amount, "requested_by_customer")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateRefund() error = %v, want %v", err, tt.wantErr)
+			}
+			if refund.Status != tt.wantStatus || failed != (tt.wantStatus == RefundFailed) {
+				t.Errorf("refund status = %s (marked failed %v), want %s", refund.Status, failed, tt.wantStatus)
+			}
+			if refund.Amount != 1000-tt.refunded && tt.amount == 0 {
+				t.Errorf("refund amount = %d, want %d", refund.Amount, 1000-tt.refunded)
+			}
+			if processorRefund != refund.ID {
+				t.Errorf("processor refund ID = %q, want %q", processorRefund, refund.ID)
+			}
+			if posted := reference != ""; posted != (tt.processorErr == nil) {
+				t.Errorf("ledger reversal posted: %v, want it only after the processor refunded", posted)
+			}
+			if reference != "" && reference != RefundLedgerReference("pi_1", refund.ID) {
+				t.Errorf("ledger reference = %q, want %q", reference, RefundLedgerReference("pi_1", refund.ID))
+			}
+			if intent.Status != tt.wantIntent {
+				t.Errorf("intent status = %s, want %s", intent.Status, tt.wantIntent)
+			}
+		})
+	}
+}
+
+func TestDisputeService_SubmitEvidence(t *testing.T) {
+	now := time.Now()
+	evidence := DisputeEvidence{ProductDescription: "Annual plan", ShippingTrackingNumber: "1Z999"}
+
+	tests := []struct {
+		name    string
+		status  DisputeStatus
+		dueBy   time.Time
+		wantErr error
+	}{
+		{"submitted in time", DisputeNeedsResponse, now.Add(time.Hour), nil},
+		{"after the deadline", DisputeNeedsResponse, now.Add(-time.Hour), ErrEvidenceDeadlinePassed},
+		{"already under review", DisputeUnderReview, now.Add(time.Hour), ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &Dispute{ID: "dp_1", Status: tt.status, EvidenceDueBy: tt.dueBy}
+			var from DisputeStatus
+			repo := &MockRepository{
+				GetDisputeFunc: func(ctx context.Context, id string) (*Dispute, error) {
+					d := *stored
+					return &d, nil
+				},
+				UpdateDisputeFunc: func(ctx context.Context, dispute *Dispute, f DisputeStatus) error {
+					from, *stored = f, *dispute
+					return nil
+				},
+			}
+
+			service := NewDisputeService(repo)
+			if _, err := service.SubmitEvidence(context.Background(), "dp_1", DisputeEvidence{}, now); !IsValidationError(err) {
+				t.Fatalf("SubmitEvidence() without evidence: error = %v, want a validation error", err)
+			}
+			_, err := service.SubmitEvidence(context.Background(), "dp_1", evidence, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SubmitEvidence() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if from != DisputeNeedsResponse || stored.Status != DisputeUnderReview || stored.Evidence == nil || stored.EvidenceSubmittedAt == nil {
+				t.Errorf("stored dispute = %+v from %s, want it under review with evidence", stored, from)
+			}
+		})
+	}
+}
+
+func TestDisputeService_CloseDispute(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     DisputeStatus
+		outcome    DisputeStatus
+		wantUpdate bool
+		wantErr    error
+	}{
+		{"won after review", DisputeUnderReview, DisputeWon, true, nil},
+		{"lost without a response", DisputeNeedsResponse, DisputeLost, true, nil},
+		{"decision redelivered", DisputeWon, DisputeWon, false, nil},
+		{"decision reversed", DisputeLost, DisputeWon, false, ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated bool
+			repo := &MockRepository{
+				GetDisputeByProcessorIDFunc: func(ctx context.Context, processor, processorDisputeID string) (*Dispute, error) {
+					return &Dispute{ID: "dp_1", Processor: processor, ProcessorDisputeID: processorDisputeID, Status: tt.status}, nil
+				},
+				UpdateDisputeFunc: func(ctx context.Context, dispute *Dispute, from DisputeStatus) error {
+					if from != tt.status || dispute.Status != tt.outcome {
+						t.Errorf("UpdateDispute(%s -> %s), want %s -> %s", from, dispute.Status, tt.status, tt.outcome)
+					}
+					updated = true
+					return nil
+				},
+			}
+
+			_, err := NewDisputeService(repo).CloseDispute(context.Background(), "simulator", "dp_sim_1", tt.outcome)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CloseDispute() error = %v, want %v", err, tt.wantErr)
+			}
+			if updated != tt.wantUpdate {
+				t.Errorf("updated = %v, want %v", updated, tt.wantUpdate)
+			}
+			if got := tt.outcome.Event(); tt.wantUpdate && got != EventType("dispute."+string(tt.outcome)) {
+				t.Errorf("event = %s", got)
 			}
 		})
 	}
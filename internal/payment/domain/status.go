@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status is the state of a payment intent.
+type Status string
+
+const (
+	StatusRequiresPaymentMethod Status = "requires_payment_method"
+	StatusRequiresConfirmation  Status = "requires_confirmation"
+	StatusRequiresAction        Status = "requires_action"
+	StatusProcessing            Status = "processing"
+	StatusRequiresCapture       Status = "requires_capture"
+	StatusSucceeded             Status = "succeeded"
+	StatusCanceled              Status = "canceled"
+	StatusPartiallyRefunded     Status = "partially_refunded"
+	StatusRefunded              Status = "refunded"
+)
+
+// EventType names an event announcing a payment intent change.
+type EventType string
+
+// Payment events, published to other services through the outbox. Every
+// status change emits exactly one of them. EventPaymentRefunded announces
+// each refund, partial or full, with the refunded amount.
+const (
+	EventPaymentCreated              EventType = "payment.created"
+	EventPaymentRequiresConfirmation EventType = "payment.requires_confirmation"
+	EventPaymentRequiresAction       EventType = "payment.requires_action"
+	EventPaymentProcessing           EventType = "payment.processing"
+	EventPaymentRequiresCapture      EventType = "payment.requires_capture"
+	EventPaymentSucceeded            EventType = "payment.succeeded"
+	EventPaymentFailed               EventType = "payment.failed"
+	EventPaymentCanceled             EventType = "payment.canceled"
+	EventPaymentRefunded             EventType = "payment.refunded"
+)
+
+// transitions lists the statuses each status may move to. A failed attempt
+// returns the intent to requires_payment_method so it can be retried with
+// another payment method. canceled and refunded are final.
+var transitions = map[Status][]Status{
+	StatusRequiresPaymentMethod: {StatusRequiresConfirmation, StatusProcessing, StatusCanceled},
+	StatusRequiresConfirmation:  {StatusProcessing, StatusRequiresAction, StatusCanceled},
+	StatusRequiresAction:        {StatusProcessing, StatusRequiresPaymentMethod, StatusCanceled},
+	StatusProcessing:            {StatusSucceeded, StatusRequiresCapture, StatusRequiresAction, StatusRequiresPaymentMethod},
+	StatusRequiresCapture:       {StatusSucceeded, StatusCanceled},
+	StatusSucceeded:             {StatusPartiallyRefunded, StatusRefunded},
+	StatusPartiallyRefunded:     {StatusPartiallyRefunded, StatusRefunded},
+	StatusCanceled:              {},
+	StatusRefunded:              {},
+}
+
+// Valid reports whether s is a known status.
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// Final reports whether no transition leaves s.
+func (s Status) Final() bool {
+	return s.Valid() && len(transitions[s]) == 0
+}
+
+// CanTransition reports whether an intent in status from may move to to.
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition is one validated status change of an intent, with the event
+// that announces it.
+type Transition struct {
+	From   Status
+	To     Status
+	Event  EventType
+	Reason string
+}
+
+// NewTransition validates a status change and picks its event.
+func NewTransition(from, to Status, reason string) (Transition, error) {
+	if !CanTransition(from, to) {
+		return Transition{}, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	return Transition{From: from, To: to, Event: transitionEvent(to), Reason: reason}, nil
+}
+
+// transitionEvent returns the event for entering status to. Returning to
+// requires_payment_method only happens when an attempt fails.
+func transitionEvent(to Status) EventType {
+	switch to {
+	case StatusRequiresPaymentMethod:
+		return EventPaymentFailed
+	case StatusRequiresConfirmation:
+		return EventPaymentRequiresConfirmation
+	case StatusRequiresAction:
+		return EventPaymentRequiresAction
+	case StatusProcessing:
+		return EventPaymentProcessing
+	case StatusRequiresCapture:
+		return EventPaymentRequiresCapture
+	case StatusSucceeded:
+		return EventPaymentSucceeded
+	case StatusCanceled:
+		return EventPaymentCanceled
+	default:
+		return EventPaymentRefunded
+	}
+}
+
+// StatusChange is an entry in an intent's status history. From is empty for
+// the entry recording the intent's creation.
+type StatusChange struct {
+	ID              string    `json:"id"`
+	PaymentIntentID string    `json:"payment_intent_id"`
+	From            Status    `json:"from_status,omitempty"`
+	To              Status    `json:"to_status"`
+	Event           EventType `json:"event_type"`
+	Reason          string    `json:"reason,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
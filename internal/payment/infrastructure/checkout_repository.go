@@ -0,0 +1,239 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+const checkoutSessionColumns = `id, payment_link_id, payment_intent_id, status, line_items, amount_total, currency, success_url,
+	cancel_url, expires_at, completed_at, user_id, zone_id, mode, created_at`
+
+func scanCheckoutSession(row rowScanner) (*domain.CheckoutSession, error) {
+	var s domain.CheckoutSession
+	var linkID, intentID, cancelURL, userID, zoneID, mode sql.NullString
+	var lineItems []byte
+	var completedAt sql.NullTime
+	if err := row.Scan(&s.ID, &linkID, &intentID, &s.Status, &lineItems, &s.AmountTotal, &s.Currency, &s.SuccessURL,
+		&cancelURL, &s.ExpiresAt, &completedAt, &userID, &zoneID, &mode, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(lineItems, &s.LineItems); err != nil {
+		return nil, fmt.Errorf("failed to decode checkout line items: %w", err)
+	}
+	s.PaymentLinkID = linkID.String
+	s.PaymentIntentID = intentID.String
+	s.CancelURL = cancelURL.String
+	s.UserID = userID.String
+	s.ZoneID = zoneID.String
+	s.Mode = mode.String
+	if completedAt.Valid {
+		s.CompletedAt = &completedAt.Time
+	}
+	return &s, nil
+}
+
+func (r *SQLRepository) CreateCheckoutSession(ctx context.Context, session *domain.CheckoutSession) error {
+	lineItems, err := json.Marshal(session.LineItems)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkout line items: %w", err)
+	}
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO checkout_sessions (id, payment_link_id, status, line_items, amount_total, currency, success_url, cancel_url,
+		                                expires_at, user_id, zone_id, mode)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 RETURNING created_at`,
+		session.ID, nullString(session.PaymentLinkID), session.Status, lineItems, session.AmountTotal, session.Currency,
+		session.SuccessURL, nullString(session.CancelURL), session.ExpiresAt, nullString(session.UserID),
+		nullString(session.ZoneID), nullString(session.Mode)).
+		Scan(&session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create checkout session: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) GetCheckoutSession(ctx context.Context, id string) (*domain.CheckoutSession, error) {
+	return r.getCheckoutSessionWhere(ctx, `id = $1`, id)
+}
+
+func (r *SQLRepository) GetCheckoutSessionByPaymentIntent(ctx context.Context, intentID string) (*domain.CheckoutSession, error) {
+	return r.getCheckoutSessionWhere(ctx, `payment_intent_id = $1`, intentID)
+}
+
+func (r *SQLRepository) getCheckoutSessionWhere(ctx context.Context, where string, args ...any) (*domain.CheckoutSession, error) {
+	session, err := scanCheckoutSession(r.db.QueryRowContext(ctx,
+		`SELECT `+checkoutSessionColumns+` FROM checkout_sessions WHERE `+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get checkout session: %w", err)
+	}
+	return session, nil
+}
+
+// CreateCheckoutPaymentIntent locks the session row, so of two customers
+// paying for the same session at once only one creates its intent.
+func (r *SQLRepository) CreateCheckoutPaymentIntent(ctx context.Context, session *domain.CheckoutSession, intent *domain.PaymentIntent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	locked, err := scanCheckoutSession(tx.QueryRowContext(ctx,
+		`SELECT `+checkoutSessionColumns+` FROM checkout_sessions WHERE id = $1 FOR UPDATE`, session.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrCheckoutSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get checkout session: %w", err)
+	}
+	if locked.Status != domain.CheckoutSessionOpen {
+		return fmt.Errorf("%w: session is %s", domain.ErrCheckoutSessionClosed, locked.Status)
+	}
+	if locked.PaymentIntentID != "" {
+		existing, err := scanIntent(tx.QueryRowContext(ctx,
+			`SELECT `+intentColumns+` FROM payment_intents WHERE id = $1`, locked.PaymentIntentID))
+		if err != nil {
+			return fmt.Errorf("failed to get payment intent: %w", err)
+		}
+		*intent = *existing
+		session.PaymentIntentID = intent.ID
+		return nil
+	}
+
+	if err := insertPaymentIntent(ctx, tx, intent); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE checkout_sessions SET payment_intent_id = $1, updated_at = NOW() WHERE id = $2`,
+		intent.ID, session.ID); err != nil {
+		return fmt.Errorf("failed to attach payment intent to checkout session: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	session.PaymentIntentID = intent.ID
+	return nil
+}
+
+func (r *SQLRepository) UpdateCheckoutSession(ctx context.Context, session *domain.CheckoutSession, from domain.CheckoutSessionStatus) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	updated, err := scanCheckoutSession(tx.QueryRowContext(ctx,
+		`UPDATE checkout_sessions SET status = $1, completed_at = $2, updated_at = NOW()
+		 WHERE id = $3 AND status = $4
+		 RETURNING `+checkoutSessionColumns,
+		session.Status, session.CompletedAt, session.ID, from))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrStatusConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update checkout session: %w", err)
+	}
+	*session = *updated
+
+	if session.Status == domain.CheckoutSessionComplete {
+		if err := writeCheckoutEvent(ctx, tx, domain.EventCheckoutSessionCompleted, session); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) ListExpiredCheckoutSessions(ctx context.Context, before time.Time, limit int) ([]domain.CheckoutSession, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+checkoutSessionColumns+` FROM checkout_sessions
+		 WHERE status = $1 AND expires_at < $2
+		 ORDER BY expires_at
+		 LIMIT $3`,
+		domain.CheckoutSessionOpen, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired checkout sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []domain.CheckoutSession{}
+	for rows.Next() {
+		session, err := scanCheckoutSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+const paymentLinkColumns = `id, line_items, amount_total, currency, success_url, cancel_url, active, user_id, zone_id, mode, created_at`
+
+func scanPaymentLink(row rowScanner) (*domain.PaymentLink, error) {
+	var l domain.PaymentLink
+	var cancelURL, userID, zoneID, mode sql.NullString
+	var lineItems []byte
+	if err := row.Scan(&l.ID, &lineItems, &l.AmountTotal, &l.Currency, &l.SuccessURL, &cancelURL, &l.Active,
+		&userID, &zoneID, &mode, &l.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(lineItems, &l.LineItems); err != nil {
+		return nil, fmt.Errorf("failed to decode payment link line items: %w", err)
+	}
+	l.CancelURL = cancelURL.String
+	l.UserID = userID.String
+	l.ZoneID = zoneID.String
+	l.Mode = mode.String
+	return &l, nil
+}
+
+func (r *SQLRepository) CreatePaymentLink(ctx context.Context, link *domain.PaymentLink) error {
+	lineItems, err := json.Marshal(link.LineItems)
+	if err != nil {
+		return fmt.Errorf("failed to encode payment link line items: %w", err)
+	}
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO payment_links (id, line_items, amount_total, currency, success_url, cancel_url, active, user_id, zone_id, mode)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 RETURNING created_at`,
+		link.ID, lineItems, link.AmountTotal, link.Currency, link.SuccessURL, nullString(link.CancelURL), link.Active,
+		nullString(link.UserID), nullString(link.ZoneID), nullString(link.Mode)).
+		Scan(&link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment link: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) GetPaymentLink(ctx context.Context, id string) (*domain.PaymentLink, error) {
+	link, err := scanPaymentLink(r.db.QueryRowContext(ctx,
+		`SELECT `+paymentLinkColumns+` FROM payment_links WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment link: %w", err)
+	}
+	return link, nil
+}
+
+func (r *SQLRepository) DeactivatePaymentLink(ctx context.Context, id string) (*domain.PaymentLink, error) {
+	link, err := scanPaymentLink(r.db.QueryRowContext(ctx,
+		`UPDATE payment_links SET active = FALSE, updated_at = NOW() WHERE id = $1
+		 RETURNING `+paymentLinkColumns, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPaymentLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to deactivate payment link: %w", err)
+	}
+	return link, nil
+}
@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"context"
+
+	pb "github.com/sapliy/fintech-ecosystem/proto/connect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConnectClient looks up connected accounts in the connect service.
+type ConnectClient struct {
+	client pb.ConnectServiceClient
+}
+
+func NewConnectClient(client pb.ConnectServiceClient) *ConnectClient {
+	return &ConnectClient{client: client}
+}
+
+func (c *ConnectClient) ConnectedAccountExists(ctx context.Context, id string) (bool, error) {
+	_, err := c.client.GetAccount(ctx, &pb.GetAccountRequest{Id: id})
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
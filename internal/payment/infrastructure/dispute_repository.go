@@ -0,0 +1,163 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+const disputeColumns = `id, payment_intent_id, amount, currency, reason, status, user_id, zone_id, mode, processor,
+	processor_dispute_id, evidence, evidence_due_by, evidence_submitted_at, created_at, updated_at, on_behalf_of`
+
+func scanDispute(row rowScanner) (*domain.Dispute, error) {
+	var d domain.Dispute
+	var reason, userID, zoneID, mode, onBehalfOf sql.NullString
+	var evidence []byte
+	var submittedAt sql.NullTime
+	if err := row.Scan(&d.ID, &d.PaymentIntentID, &d.Amount, &d.Currency, &reason, &d.Status, &userID, &zoneID, &mode,
+		&d.Processor, &d.ProcessorDisputeID, &evidence, &d.EvidenceDueBy, &submittedAt, &d.CreatedAt, &d.UpdatedAt, &onBehalfOf); err != nil {
+		return nil, err
+	}
+	d.Reason = reason.String
+	d.UserID = userID.String
+	d.ZoneID = zoneID.String
+	d.Mode = mode.String
+	d.OnBehalfOf = onBehalfOf.String
+	if evidence != nil {
+		if err := json.Unmarshal(evidence, &d.Evidence); err != nil {
+			return nil, fmt.Errorf("failed to decode dispute evidence: %w", err)
+		}
+	}
+	if submittedAt.Valid {
+		d.EvidenceSubmittedAt = &submittedAt.Time
+	}
+	return &d, nil
+}
+
+func (r *SQLRepository) CreateDispute(ctx context.Context, dispute *domain.Dispute) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stored, err := scanDispute(tx.QueryRowContext(ctx,
+		`INSERT INTO disputes (id, payment_intent_id, amount, currency, reason, status, user_id, zone_id, mode, processor,
+		                       processor_dispute_id, evidence_due_by, on_behalf_of)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		 ON CONFLICT (processor, processor_dispute_id) DO NOTHING
+		 RETURNING `+disputeColumns,
+		dispute.ID, dispute.PaymentIntentID, dispute.Amount, dispute.Currency, nullString(dispute.Reason), dispute.Status,
+		nullString(dispute.UserID), nullString(dispute.ZoneID), nullString(dispute.Mode), dispute.Processor,
+		dispute.ProcessorDisputeID, dispute.EvidenceDueBy, nullString(dispute.OnBehalfOf)))
+	if errors.Is(err, sql.ErrNoRows) {
+		// The processor redelivered the dispute.
+		existing, err := r.GetDisputeByProcessorID(ctx, dispute.Processor, dispute.ProcessorDisputeID)
+		if err != nil {
+			return err
+		}
+		*dispute = *existing
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create dispute: %w", err)
+	}
+	*dispute = *stored
+
+	if err := writeDisputeEvent(ctx, tx, domain.EventDisputeCreated, dispute, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) GetDispute(ctx context.Context, id string) (*domain.Dispute, error) {
+	return r.getDisputeWhere(ctx, `id = $1`, id)
+}
+
+func (r *SQLRepository) GetDisputeByProcessorID(ctx context.Context, processor, processorDisputeID string) (*domain.Dispute, error) {
+	return r.getDisputeWhere(ctx, `processor = $1 AND processor_dispute_id = $2`, processor, processorDisputeID)
+}
+
+func (r *SQLRepository) getDisputeWhere(ctx context.Context, where string, args ...any) (*domain.Dispute, error) {
+	dispute, err := scanDispute(r.db.QueryRowContext(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE `+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get dispute: %w", err)
+	}
+	return dispute, nil
+}
+
+func (r *SQLRepository) ListDisputes(ctx context.Context, intentID string) ([]domain.Dispute, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+disputeColumns+` FROM disputes WHERE payment_intent_id = $1 ORDER BY created_at, id`, intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disputes: %w", err)
+	}
+	return scanDisputes(rows)
+}
+
+func (r *SQLRepository) UpdateDispute(ctx context.Context, dispute *domain.Dispute, from domain.DisputeStatus) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var evidence []byte
+	if dispute.Evidence != nil {
+		if evidence, err = json.Marshal(dispute.Evidence); err != nil {
+			return fmt.Errorf("failed to encode dispute evidence: %w", err)
+		}
+	}
+	updated, err := scanDispute(tx.QueryRowContext(ctx,
+		`UPDATE disputes SET status = $1, evidence = $2, evidence_submitted_at = $3, updated_at = NOW()
+		 WHERE id = $4 AND status = $5
+		 RETURNING `+disputeColumns,
+		dispute.Status, evidence, dispute.EvidenceSubmittedAt, dispute.ID, from))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrStatusConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update dispute: %w", err)
+	}
+	*dispute = *updated
+
+	if err := writeDisputeEvent(ctx, tx, dispute.Status.Event(), dispute, from); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) ListOverdueDisputes(ctx context.Context, before time.Time, limit int) ([]domain.Dispute, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+disputeColumns+` FROM disputes
+		 WHERE status = $1 AND evidence_due_by < $2
+		 ORDER BY evidence_due_by
+		 LIMIT $3`,
+		domain.DisputeNeedsResponse, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overdue disputes: %w", err)
+	}
+	return scanDisputes(rows)
+}
+
+func scanDisputes(rows *sql.Rows) ([]domain.Dispute, error) {
+	defer rows.Close()
+
+	disputes := []domain.Dispute{}
+	for rows.Next() {
+		dispute, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, *dispute)
+	}
+	return disputes, rows.Err()
+}
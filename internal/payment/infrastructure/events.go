@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/outbox"
+)
+
+// EventsTopic is the Kafka topic payment events are published to. The
+// ledger, fraud and flow-runner services consume it.
+const EventsTopic = "payments"
+
+// writeEvent queues a payment event in tx. Events are keyed by payment so
+// consumers see each payment's events in order. fields are added to the
+// envelope next to the intent, such as the status the intent left.
+func writeEvent(ctx context.Context, tx *sql.Tx, eventType domain.EventType, intent *domain.PaymentIntent, fields map[string]interface{}) error {
+	envelope := map[string]interface{}{
+		"id":         uuid.New().String(),
+		"type":       eventType,
+		"zone_id":    intent.ZoneID,
+		"mode":       intent.Mode,
+		"created_at": time.Now().UTC(),
+		"data":       intent,
+	}
+	for k, v := range fields {
+		envelope[k] = v
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return outbox.Write(ctx, tx, outbox.Event{Topic: EventsTopic, Type: string(eventType), Key: intent.ID, Payload: payload})
+}
+
+// writeDisputeEvent queues a dispute event in tx. It is keyed by the
+// disputed payment so consumers see it in order with the payment's events,
+// and carries the status the dispute left, if any.
+func writeDisputeEvent(ctx context.Context, tx *sql.Tx, eventType domain.EventType, dispute *domain.Dispute, previous domain.DisputeStatus) error {
+	envelope := map[string]interface{}{
+		"id":         uuid.New().String(),
+		"type":       eventType,
+		"zone_id":    dispute.ZoneID,
+		"mode":       dispute.Mode,
+		"created_at": time.Now().UTC(),
+		"data":       dispute,
+	}
+	if previous != "" {
+		envelope["previous_status"] = previous
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return outbox.Write(ctx, tx, outbox.Event{Topic: EventsTopic, Type: string(eventType), Key: dispute.PaymentIntentID, Payload: payload})
+}
+
+// writeCheckoutEvent queues a checkout session event in tx, keyed by the
+// session's payment so it follows the payment's own events.
+func writeCheckoutEvent(ctx context.Context, tx *sql.Tx, eventType domain.EventType, session *domain.CheckoutSession) error {
+	envelope := map[string]interface{}{
+		"id":         uuid.New().String(),
+		"type":       eventType,
+		"zone_id":    session.ZoneID,
+		"mode":       session.Mode,
+		"created_at": time.Now().UTC(),
+		"data":       session,
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return outbox.Write(ctx, tx, outbox.Event{Topic: EventsTopic, Type: string(eventType), Key: session.PaymentIntentID, Payload: payload})
+}
@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	pb "github.com/sapliy/fintech-ecosystem/proto/ledger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LedgerClient posts refund reversals through the ledger service. The
+// ledger derives the reversal's entries from the payment's posting, which it
+// finds by the payment ID; a marketplace payment's fee and connected account
+// share are refunded in proportion.
+type LedgerClient struct {
+	client pb.LedgerServiceClient
+}
+
+func NewLedgerClient(client pb.LedgerServiceClient) *LedgerClient {
+	return &LedgerClient{client: client}
+}
+
+func (c *LedgerClient) ReversePayment(ctx context.Context, payment *domain.PaymentIntent, reference string, amount int64, reason string) (string, error) {
+	resp, err := c.client.ReverseTransaction(ctx, &pb.ReverseTransactionRequest{
+		OriginalReferenceId: payment.ID,
+		ReferenceId:         reference,
+		Amount:              amount,
+		Reason:              reason,
+		ZoneId:              payment.ZoneID,
+		Mode:                payment.Mode,
+	})
+	if err != nil {
+		// The ledger answers these codes only when it validated the request
+		// and posted nothing. Timeouts and unavailability leave the outcome
+		// unknown.
+		switch status.Code(err) {
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			return "", fmt.Errorf("%w: %s", domain.ErrLedgerRejected, status.Convert(err).Message())
+		}
+		return "", err
+	}
+	return resp.TransactionId, nil
+}
@@ -0,0 +1,181 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+const customerColumns = `id, email, name, default_payment_method_id, user_id, zone_id, mode, created_at`
+
+func scanCustomer(row rowScanner) (*domain.Customer, error) {
+	var c domain.Customer
+	var email, name, defaultPM, userID, zoneID, mode sql.NullString
+	if err := row.Scan(&c.ID, &email, &name, &defaultPM, &userID, &zoneID, &mode, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	c.Email = email.String
+	c.Name = name.String
+	c.DefaultPaymentMethodID = defaultPM.String
+	c.UserID = userID.String
+	c.ZoneID = zoneID.String
+	c.Mode = mode.String
+	return &c, nil
+}
+
+func (r *SQLRepository) CreateCustomer(ctx context.Context, customer *domain.Customer) error {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO customers (id, email, name, user_id, zone_id, mode)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING created_at`,
+		customer.ID, nullString(customer.Email), nullString(customer.Name), nullString(customer.UserID),
+		nullString(customer.ZoneID), nullString(customer.Mode)).
+		Scan(&customer.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) GetCustomer(ctx context.Context, id string) (*domain.Customer, error) {
+	customer, err := scanCustomer(r.db.QueryRowContext(ctx,
+		`SELECT `+customerColumns+` FROM customers WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	return customer, nil
+}
+
+const paymentMethodColumns = `id, type, card_brand, card_last4, card_exp_month, card_exp_year, encrypted_card, customer_id,
+	detached_at, user_id, zone_id, mode, created_at`
+
+func scanPaymentMethod(row rowScanner) (*domain.PaymentMethod, error) {
+	var pm domain.PaymentMethod
+	var customerID, userID, zoneID, mode sql.NullString
+	var detachedAt sql.NullTime
+	if err := row.Scan(&pm.ID, &pm.Type, &pm.Card.Brand, &pm.Card.Last4, &pm.Card.ExpMonth, &pm.Card.ExpYear,
+		&pm.EncryptedCard, &customerID, &detachedAt, &userID, &zoneID, &mode, &pm.CreatedAt); err != nil {
+		return nil, err
+	}
+	pm.CustomerID = customerID.String
+	pm.UserID = userID.String
+	pm.ZoneID = zoneID.String
+	pm.Mode = mode.String
+	if detachedAt.Valid {
+		pm.DetachedAt = &detachedAt.Time
+	}
+	return &pm, nil
+}
+
+func (r *SQLRepository) CreatePaymentMethod(ctx context.Context, pm *domain.PaymentMethod) error {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO payment_methods (id, type, card_brand, card_last4, card_exp_month, card_exp_year, encrypted_card,
+		                              user_id, zone_id, mode)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 RETURNING created_at`,
+		pm.ID, pm.Type, pm.Card.Brand, pm.Card.Last4, pm.Card.ExpMonth, pm.Card.ExpYear, pm.EncryptedCard,
+		nullString(pm.UserID), nullString(pm.ZoneID), nullString(pm.Mode)).
+		Scan(&pm.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment method: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) GetPaymentMethod(ctx context.Context, id string) (*domain.PaymentMethod, error) {
+	pm, err := scanPaymentMethod(r.db.QueryRowContext(ctx,
+		`SELECT `+paymentMethodColumns+` FROM payment_methods WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment method: %w", err)
+	}
+	return pm, nil
+}
+
+func (r *SQLRepository) ListPaymentMethods(ctx context.Context, customerID string) ([]domain.PaymentMethod, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+paymentMethodColumns+` FROM payment_methods
+		 WHERE customer_id = $1
+		 ORDER BY created_at DESC`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+	defer rows.Close()
+
+	methods := []domain.PaymentMethod{}
+	for rows.Next() {
+		pm, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, *pm)
+	}
+	return methods, rows.Err()
+}
+
+func (r *SQLRepository) AttachPaymentMethod(ctx context.Context, id, customerID string, makeDefault bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE payment_methods SET customer_id = $1, updated_at = NOW()
+		 WHERE id = $2 AND detached_at IS NULL AND (customer_id IS NULL OR customer_id = $1)`,
+		customerID, id)
+	if err != nil {
+		return fmt.Errorf("failed to attach payment method: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrPaymentMethodUnavailable
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE customers SET default_payment_method_id = $1, updated_at = NOW()
+		 WHERE id = $2 AND ($3 OR default_payment_method_id IS NULL)`,
+		id, customerID, makeDefault); err != nil {
+		return fmt.Errorf("failed to set default payment method: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) DetachPaymentMethod(ctx context.Context, pm *domain.PaymentMethod, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	updated, err := scanPaymentMethod(tx.QueryRowContext(ctx,
+		`UPDATE payment_methods SET customer_id = NULL, detached_at = COALESCE(detached_at, $1), updated_at = NOW()
+		 WHERE id = $2
+		 RETURNING `+paymentMethodColumns,
+		at, pm.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to detach payment method: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE customers SET default_payment_method_id = NULL, updated_at = NOW()
+		 WHERE default_payment_method_id = $1`, pm.ID); err != nil {
+		return fmt.Errorf("failed to clear default payment method: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	*pm = *updated
+	return nil
+}
@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
+)
+
+// ProcessorRefunds returns refunded funds through the processor recorded on
+// each payment.
+type ProcessorRefunds struct {
+	processors *bank.Registry
+}
+
+func NewProcessorRefunds(processors *bank.Registry) *ProcessorRefunds {
+	return &ProcessorRefunds{processors: processors}
+}
+
+func (p *ProcessorRefunds) RefundPayment(ctx context.Context, payment *domain.PaymentIntent, refundID string, amount int64) (string, error) {
+	client, err := p.processors.Get(payment.Processor)
+	if err != nil {
+		return "", err
+	}
+	result, err := client.Refund(ctx, payment.ProcessorTransactionID, refundID, amount)
+	if errors.Is(err, bank.ErrTransactionNotFound) {
+		return "", fmt.Errorf("%w: %v", domain.ErrProcessorDeclined, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if result.Status != bank.StatusSuccess {
+		return "", fmt.Errorf("%w: %s", domain.ErrProcessorDeclined, result.ErrorCode)
+	}
+	return result.TransactionID, nil
+}
@@ -0,0 +1,168 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+const refundColumns = `id, payment_intent_id, amount, currency, reason, status, failure_reason, ledger_reference, ledger_transaction_id, created_at`
+
+// lockIntent reads an intent and locks it for the rest of tx, so refunds of
+// one intent are checked and applied one at a time.
+func lockIntent(ctx context.Context, tx *sql.Tx, id string) (*domain.PaymentIntent, error) {
+	intent, err := scanIntent(tx.QueryRowContext(ctx,
+		`SELECT `+intentColumns+` FROM payment_intents WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPaymentIntentNotFound
+		}
+		return nil, fmt.Errorf("failed to lock payment intent: %w", err)
+	}
+	return intent, nil
+}
+
+func (r *SQLRepository) CreateRefund(ctx context.Context, refund *domain.Refund) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	intent, err := lockIntent(ctx, tx, refund.PaymentIntentID)
+	if err != nil {
+		return err
+	}
+	var disputed bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM disputes WHERE payment_intent_id = $1 AND status <> $2)`,
+		refund.PaymentIntentID, domain.DisputeWon).Scan(&disputed); err != nil {
+		return fmt.Errorf("failed to check disputes: %w", err)
+	}
+	if disputed {
+		return domain.ErrPaymentDisputed
+	}
+	var pending int64
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE payment_intent_id = $1 AND status = $2`,
+		refund.PaymentIntentID, domain.RefundPending).Scan(&pending); err != nil {
+		return fmt.Errorf("failed to sum pending refunds: %w", err)
+	}
+	amount, err := domain.RefundAmount(intent, pending, refund.Amount)
+	if err != nil {
+		return err
+	}
+	refund.Amount = amount
+	refund.Currency = intent.Currency
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO refunds (id, payment_intent_id, amount, currency, reason, status, ledger_reference)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`,
+		refund.ID, refund.PaymentIntentID, refund.Amount, refund.Currency, nullString(refund.Reason), refund.Status, refund.LedgerReference).
+		Scan(&refund.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) CompleteRefund(ctx context.Context, refund *domain.Refund) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE refunds SET status = $1, ledger_transaction_id = $2, updated_at = NOW() WHERE id = $3 AND status = $4`,
+		domain.RefundSucceeded, refund.LedgerTransactionID, refund.ID, domain.RefundPending)
+	if err != nil {
+		return fmt.Errorf("failed to complete refund: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("refund %s is not pending", refund.ID)
+	}
+
+	intent, err := lockIntent(ctx, tx, refund.PaymentIntentID)
+	if err != nil {
+		return err
+	}
+	t, err := domain.RefundTransition(intent, refund)
+	if err != nil {
+		return err
+	}
+	updated, err := scanIntent(tx.QueryRowContext(ctx,
+		`UPDATE payment_intents SET status = $1, amount_refunded = $2, updated_at = NOW() WHERE id = $3
+		 RETURNING `+intentColumns,
+		t.To, intent.AmountRefunded, intent.ID))
+	if err != nil {
+		return fmt.Errorf("failed to apply refund: %w", err)
+	}
+
+	if err := insertStatusChange(ctx, tx, intent.ID, t); err != nil {
+		return err
+	}
+	completed := *refund
+	completed.Status = domain.RefundSucceeded
+	if err := writeEvent(ctx, tx, t.Event, updated, map[string]interface{}{
+		"previous_status": t.From,
+		"refund":          completed,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) FailRefund(ctx context.Context, refund *domain.Refund) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refunds SET status = $1, failure_reason = $2, updated_at = NOW() WHERE id = $3 AND status = $4`,
+		domain.RefundFailed, refund.FailureReason, refund.ID, domain.RefundPending)
+	if err != nil {
+		return fmt.Errorf("failed to mark refund failed: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) ListRefunds(ctx context.Context, intentID string) ([]domain.Refund, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+refundColumns+` FROM refunds WHERE payment_intent_id = $1 ORDER BY created_at, id`, intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+	return scanRefunds(rows)
+}
+
+func (r *SQLRepository) ListPendingRefunds(ctx context.Context, before time.Time, limit int) ([]domain.Refund, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+refundColumns+` FROM refunds WHERE status = $1 AND created_at < $2 ORDER BY created_at, id LIMIT $3`,
+		domain.RefundPending, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending refunds: %w", err)
+	}
+	return scanRefunds(rows)
+}
+
+func scanRefunds(rows *sql.Rows) ([]domain.Refund, error) {
+	defer rows.Close()
+
+	refunds := []domain.Refund{}
+	for rows.Next() {
+		var refund domain.Refund
+		var reason, failureReason, ledgerTxID sql.NullString
+		if err := rows.Scan(&refund.ID, &refund.PaymentIntentID, &refund.Amount, &refund.Currency, &reason, &refund.Status,
+			&failureReason, &refund.LedgerReference, &ledgerTxID, &refund.CreatedAt); err != nil {
+			return nil, err
+		}
+		refund.Reason = reason.String
+		refund.FailureReason = failureReason.String
+		refund.LedgerTransactionID = ledgerTxID.String
+		refunds = append(refunds, refund)
+	}
+	return refunds, rows.Err()
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
 )
@@ -17,7 +18,72 @@ func NewSQLRepository(db *sql.DB) *SQLRepository {
 	return &SQLRepository{db: db}
 }
 
+const intentColumns = `id, amount, currency, status, capture_method, amount_capturable, amount_received, amount_refunded, description, user_id,
+	application_fee_amount, on_behalf_of, zone_id, mode, processor, authorization_id, processor_transaction_id, capture_before, settled_at,
+	client_secret, challenge_id, next_action_url, action_expires_at, customer_id, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// nullString stores an empty string as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func scanIntent(row rowScanner) (*domain.PaymentIntent, error) {
+	var intent domain.PaymentIntent
+	var description, onBehalfOf, zoneID, mode, processor, authorizationID, processorTxID sql.NullString
+	var clientSecret, challengeID, nextActionURL, customerID sql.NullString
+	var captureBefore, settledAt, actionExpiresAt sql.NullTime
+	if err := row.Scan(&intent.ID, &intent.Amount, &intent.Currency, &intent.Status, &intent.CaptureMethod,
+		&intent.AmountCapturable, &intent.AmountReceived, &intent.AmountRefunded, &description, &intent.UserID, &intent.ApplicationFeeAmount,
+		&onBehalfOf, &zoneID, &mode, &processor, &authorizationID, &processorTxID, &captureBefore, &settledAt,
+		&clientSecret, &challengeID, &nextActionURL, &actionExpiresAt, &customerID, &intent.CreatedAt); err != nil {
+		return nil, err
+	}
+	intent.Description = description.String
+	intent.OnBehalfOf = onBehalfOf.String
+	intent.ZoneID = zoneID.String
+	intent.Mode = mode.String
+	intent.Processor = processor.String
+	intent.AuthorizationID = authorizationID.String
+	intent.ProcessorTransactionID = processorTxID.String
+	intent.CustomerID = customerID.String
+	if captureBefore.Valid {
+		intent.CaptureBefore = &captureBefore.Time
+	}
+	if settledAt.Valid {
+		intent.SettledAt = &settledAt.Time
+	}
+	intent.ClientSecret = clientSecret.String
+	intent.ChallengeID = challengeID.String
+	if nextActionURL.Valid {
+		intent.NextAction = &domain.NextAction{
+			Type:        domain.NextActionRedirectToURL,
+			RedirectURL: nextActionURL.String,
+			ExpiresAt:   actionExpiresAt.Time,
+		}
+	}
+	return &intent, nil
+}
+
 func (r *SQLRepository) CreatePaymentIntent(ctx context.Context, intent *domain.PaymentIntent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := insertPaymentIntent(ctx, tx, intent); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertPaymentIntent stores a new intent in tx with its initial history
+// entry and EventPaymentCreated.
+func insertPaymentIntent(ctx context.Context, tx *sql.Tx, intent *domain.PaymentIntent) error {
 	if intent.Currency == "" {
 		intent.Currency = "USD"
 	}
@@ -26,68 +92,141 @@ func (r *SQLRepository) CreatePaymentIntent(ctx context.Context, intent *domain.
 		onBehalfOf = sql.NullString{String: intent.OnBehalfOf, Valid: true}
 	}
 
-	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO payment_intents (amount, currency, status, description, user_id, application_fee_amount, on_behalf_of, zone_id, mode) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at`,
-		intent.Amount, intent.Currency, intent.Status, intent.Description, intent.UserID, intent.ApplicationFeeAmount, onBehalfOf, intent.ZoneID, intent.Mode).
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO payment_intents (amount, currency, status, capture_method, description, user_id, application_fee_amount, on_behalf_of, zone_id, mode, customer_id) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at`,
+		intent.Amount, intent.Currency, intent.Status, intent.CaptureMethod, intent.Description, intent.UserID, intent.ApplicationFeeAmount, onBehalfOf, intent.ZoneID, intent.Mode,
+		nullString(intent.CustomerID)).
 		Scan(&intent.ID, &intent.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create payment intent: %w", err)
 	}
-	return nil
+	if err := insertStatusChange(ctx, tx, intent.ID, domain.Transition{To: intent.Status, Event: domain.EventPaymentCreated}); err != nil {
+		return err
+	}
+	return writeEvent(ctx, tx, domain.EventPaymentCreated, intent, nil)
 }
 
 func (r *SQLRepository) GetPaymentIntent(ctx context.Context, id string) (*domain.PaymentIntent, error) {
-	var intent domain.PaymentIntent
-	var description, onBehalfOf, zoneID, mode sql.NullString
-	err := r.db.QueryRowContext(ctx,
-		"SELECT id, amount, currency, status, description, user_id, application_fee_amount, on_behalf_of, zone_id, mode, created_at FROM payment_intents WHERE id = $1",
-		id).Scan(&intent.ID, &intent.Amount, &intent.Currency, &intent.Status, &description, &intent.UserID, &intent.ApplicationFeeAmount, &onBehalfOf, &zoneID, &mode, &intent.CreatedAt)
-
+	intent, err := scanIntent(r.db.QueryRowContext(ctx,
+		`SELECT `+intentColumns+` FROM payment_intents WHERE id = $1`, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Not found
 		}
 		return nil, fmt.Errorf("failed to get payment intent: %w", err)
 	}
-
-	intent.Description = description.String
-	intent.OnBehalfOf = onBehalfOf.String
-	intent.ZoneID = zoneID.String
-	intent.Mode = mode.String
-
-	return &intent, nil
+	return intent, nil
 }
 
-func (r *SQLRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE payment_intents SET status = $1 WHERE id = $2", status, id)
+func (r *SQLRepository) GetPaymentIntentByProcessorTransaction(ctx context.Context, processor, transactionID string) (*domain.PaymentIntent, error) {
+	intent, err := scanIntent(r.db.QueryRowContext(ctx,
+		`SELECT `+intentColumns+` FROM payment_intents WHERE processor = $1 AND processor_transaction_id = $2`,
+		processor, transactionID))
 	if err != nil {
-		return fmt.Errorf("failed to update payment status: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment intent: %w", err)
 	}
-	return nil
+	return intent, nil
 }
 
-func (r *SQLRepository) GetIdempotencyKey(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
-	var rec domain.IdempotencyRecord
-	err := r.db.QueryRowContext(ctx, "SELECT user_id, key, response_body, status_code FROM idempotency_keys WHERE user_id = $1 AND key = $2", userID, key).
-		Scan(&rec.UserID, &rec.Key, &rec.ResponseBody, &rec.StatusCode)
+func (r *SQLRepository) UpdateStatus(ctx context.Context, intent *domain.PaymentIntent, t domain.Transition) error {
+	var nextActionURL string
+	var actionExpiresAt *time.Time
+	if intent.NextAction != nil {
+		nextActionURL, actionExpiresAt = intent.NextAction.RedirectURL, &intent.NextAction.ExpiresAt
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The status guard makes concurrent transitions from the same status
+	// race on the row lock; the loser matches no row.
+	updated, err := scanIntent(tx.QueryRowContext(ctx,
+		`UPDATE payment_intents
+		 SET status = $1, amount_capturable = $2, amount_received = $3, authorization_id = $4, capture_before = $5,
+		     processor = $6, processor_transaction_id = $7, client_secret = $8, challenge_id = $9,
+		     next_action_url = $10, action_expires_at = $11, updated_at = NOW()
+		 WHERE id = $12 AND status = $13
+		 RETURNING `+intentColumns,
+		t.To, intent.AmountCapturable, intent.AmountReceived, nullString(intent.AuthorizationID), intent.CaptureBefore,
+		nullString(intent.Processor), nullString(intent.ProcessorTransactionID), nullString(intent.ClientSecret),
+		nullString(intent.ChallengeID), nullString(nextActionURL), actionExpiresAt, intent.ID, t.From))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM payment_intents WHERE id = $1)`, intent.ID).Scan(&exists); err != nil {
+				return fmt.Errorf("failed to update payment status: %w", err)
+			}
+			if !exists {
+				return domain.ErrPaymentIntentNotFound
+			}
+			return domain.ErrStatusConflict
 		}
-		return nil, err
+		return fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	if err := insertStatusChange(ctx, tx, intent.ID, t); err != nil {
+		return err
 	}
-	return &rec, nil
+	if err := writeEvent(ctx, tx, t.Event, updated, map[string]interface{}{"previous_status": t.From}); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *SQLRepository) SaveIdempotencyKey(ctx context.Context, userID, key string, statusCode int, body string) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO idempotency_keys (user_id, key, response_body, status_code) VALUES ($1, $2, $3, $4)", userID, key, body, statusCode)
-	return err
+// insertStatusChange records t in the intent's status history.
+func insertStatusChange(ctx context.Context, tx *sql.Tx, id string, t domain.Transition) error {
+	var from, reason sql.NullString
+	if t.From != "" {
+		from = sql.NullString{String: string(t.From), Valid: true}
+	}
+	if t.Reason != "" {
+		reason = sql.NullString{String: t.Reason, Valid: true}
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO payment_status_history (payment_intent_id, from_status, to_status, event_type, reason)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		id, from, t.To, t.Event, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) ListStatusHistory(ctx context.Context, id string) ([]domain.StatusChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, payment_intent_id, from_status, to_status, event_type, reason, created_at
+		 FROM payment_status_history
+		 WHERE payment_intent_id = $1
+		 ORDER BY seq`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []domain.StatusChange{}
+	for rows.Next() {
+		var c domain.StatusChange
+		var from, reason sql.NullString
+		if err := rows.Scan(&c.ID, &c.PaymentIntentID, &from, &c.To, &c.Event, &reason, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.From = domain.Status(from.String)
+		c.Reason = reason.String
+		history = append(history, c)
+	}
+	return history, rows.Err()
 }
 
 func (r *SQLRepository) ListPaymentIntents(ctx context.Context, zoneID string, limit int) ([]domain.PaymentIntent, error) {
-	query := `SELECT id, amount, currency, status, description, user_id, application_fee_amount, on_behalf_of, zone_id, mode, created_at 
+	query := `SELECT ` + intentColumns + ` 
 			  FROM payment_intents 
 			  WHERE ($1 = '' OR zone_id = $1) 
 			  ORDER BY created_at DESC 
@@ -97,20 +236,65 @@ func (r *SQLRepository) ListPaymentIntents(ctx context.Context, zoneID string, l
 	if err != nil {
 		return nil, err
 	}
+	return scanIntents(rows)
+}
+
+func (r *SQLRepository) ListExpiredAuthorizations(ctx context.Context, before time.Time, limit int) ([]domain.PaymentIntent, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+intentColumns+` FROM payment_intents
+		 WHERE status = $1 AND capture_before < $2
+		 ORDER BY capture_before
+		 LIMIT $3`,
+		domain.StatusRequiresCapture, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired authorizations: %w", err)
+	}
+	return scanIntents(rows)
+}
+
+func (r *SQLRepository) ListExpiredActions(ctx context.Context, before time.Time, limit int) ([]domain.PaymentIntent, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+intentColumns+` FROM payment_intents
+		 WHERE status = $1 AND action_expires_at < $2
+		 ORDER BY action_expires_at
+		 LIMIT $3`,
+		domain.StatusRequiresAction, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired actions: %w", err)
+	}
+	return scanIntents(rows)
+}
+
+// MarkSettled does not touch updated_at: settlement changes nothing the
+// ledger or the reconciler compare.
+func (r *SQLRepository) MarkSettled(ctx context.Context, processor, transactionID string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE payment_intents SET settled_at = COALESCE(settled_at, $1)
+		 WHERE processor = $2 AND processor_transaction_id = $3`,
+		at, processor, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to mark payment settled: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrPaymentIntentNotFound
+	}
+	return nil
+}
+
+func scanIntents(rows *sql.Rows) ([]domain.PaymentIntent, error) {
 	defer rows.Close()
 
 	var intents []domain.PaymentIntent
 	for rows.Next() {
-		var intent domain.PaymentIntent
-		var description, onBehalfOf, zoneID, mode sql.NullString
-		if err := rows.Scan(&intent.ID, &intent.Amount, &intent.Currency, &intent.Status, &description, &intent.UserID, &intent.ApplicationFeeAmount, &onBehalfOf, &zoneID, &mode, &intent.CreatedAt); err != nil {
+		intent, err := scanIntent(rows)
+		if err != nil {
 			return nil, err
 		}
-		intent.Description = description.String
-		intent.OnBehalfOf = onBehalfOf.String
-		intent.ZoneID = zoneID.String
-		intent.Mode = mode.String
-		intents = append(intents, intent)
+		intents = append(intents, *intent)
 	}
-	return intents, nil
+	return intents, rows.Err()
 }
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+// ActionTimeoutWorker returns intents whose customer abandoned the
+// authentication challenge to requires_payment_method.
+type ActionTimeoutWorker struct {
+	service  *domain.PaymentService
+	interval time.Duration
+}
+
+func NewActionTimeoutWorker(service *domain.PaymentService, interval time.Duration) *ActionTimeoutWorker {
+	return &ActionTimeoutWorker{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (w *ActionTimeoutWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runProcess(ctx, time.Now())
+		}
+	}
+}
+
+// runProcess times out the challenges expired at now and returns how many
+// intents it moved.
+func (w *ActionTimeoutWorker) runProcess(ctx context.Context, now time.Time) int {
+	intents, err := w.service.ListExpiredActions(ctx, now, expiryBatchSize)
+	if err != nil {
+		log.Printf("Worker: failed to list expired actions: %v", err)
+		return 0
+	}
+
+	expired := 0
+	for i := range intents {
+		// An intent completed meanwhile is no longer in requires_action and
+		// fails the status check, which leaves it alone.
+		if err := w.service.ExpireAction(ctx, &intents[i]); err != nil {
+			log.Printf("Worker: failed to time out action of intent %s: %v", intents[i].ID, err)
+			continue
+		}
+		expired++
+	}
+	return expired
+}
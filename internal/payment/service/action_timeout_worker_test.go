@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+func TestActionTimeoutWorker_ExpiresAbandonedChallenges(t *testing.T) {
+	now := time.Now()
+	intents := []domain.PaymentIntent{
+		{
+			ID: "pi_abandoned", Status: domain.StatusRequiresAction, ChallengeID: "3ds_1",
+			NextAction: &domain.NextAction{Type: domain.NextActionRedirectToURL, RedirectURL: "https://sim.test/3ds/3ds_1", ExpiresAt: now.Add(-time.Minute)},
+		},
+		{ID: "pi_completed", Status: domain.StatusRequiresAction, ChallengeID: "3ds_2"},
+	}
+
+	var expired []string
+	repo := &domain.MockRepository{
+		ListExpiredActionsFunc: func(ctx context.Context, before time.Time, limit int) ([]domain.PaymentIntent, error) {
+			return intents, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, intent *domain.PaymentIntent, tr domain.Transition) error {
+			if intent.ID == "pi_completed" {
+				return domain.ErrStatusConflict
+			}
+			if tr.To != domain.StatusRequiresPaymentMethod || tr.Reason != "authentication timed out" {
+				t.Errorf("unexpected transition %+v", tr)
+			}
+			if intent.NextAction != nil || intent.ChallengeID != "" {
+				t.Errorf("challenge not cleared: %+v", intent)
+			}
+			expired = append(expired, intent.ID)
+			return nil
+		},
+	}
+
+	w := NewActionTimeoutWorker(domain.NewPaymentService(repo), time.Minute)
+	if n := w.runProcess(context.Background(), now); n != 1 {
+		t.Fatalf("runProcess expired %d intents, want 1", n)
+	}
+	if len(expired) != 1 || expired[0] != "pi_abandoned" {
+		t.Errorf("expired = %v, want [pi_abandoned]", expired)
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
+)
+
+const expiryBatchSize = 100
+
+// AuthorizationExpiryWorker voids manual-capture authorizations that were
+// not captured before their deadline and cancels their intents.
+type AuthorizationExpiryWorker struct {
+	service    *domain.PaymentService
+	processors *bank.Registry
+	interval   time.Duration
+}
+
+func NewAuthorizationExpiryWorker(service *domain.PaymentService, processors *bank.Registry, interval time.Duration) *AuthorizationExpiryWorker {
+	return &AuthorizationExpiryWorker{
+		service:    service,
+		processors: processors,
+		interval:   interval,
+	}
+}
+
+func (w *AuthorizationExpiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runProcess(ctx, time.Now())
+		}
+	}
+}
+
+// runProcess voids the authorizations expired at now and returns how many
+// intents it canceled.
+func (w *AuthorizationExpiryWorker) runProcess(ctx context.Context, now time.Time) int {
+	intents, err := w.service.ListExpiredAuthorizations(ctx, now, expiryBatchSize)
+	if err != nil {
+		log.Printf("Worker: failed to list expired authorizations: %v", err)
+		return 0
+	}
+
+	canceled := 0
+	for i := range intents {
+		if err := w.voidIntent(ctx, &intents[i]); err != nil {
+			log.Printf("Worker: failed to void authorization of intent %s: %v", intents[i].ID, err)
+			continue
+		}
+		canceled++
+	}
+	return canceled
+}
+
+func (w *AuthorizationExpiryWorker) voidIntent(ctx context.Context, intent *domain.PaymentIntent) error {
+	client, err := w.processors.Get(intent.Processor)
+	if err != nil {
+		return err
+	}
+	// An authorization the bank no longer knows has lapsed on its side, so
+	// the intent is canceled all the same.
+	if _, err := client.Void(ctx, intent.AuthorizationID); err != nil && !errors.Is(err, bank.ErrAuthorizationNotFound) {
+		return err
+	}
+	log.Printf("Worker: voided expired authorization of intent %s", intent.ID)
+	return w.service.MarkVoided(ctx, intent, "authorization expired")
+}
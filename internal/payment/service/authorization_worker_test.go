@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/bank"
+)
+
+func TestAuthorizationExpiryWorker_VoidsExpired(t *testing.T) {
+	ctx := context.Background()
+	bankClient := bank.NewSimulator(bank.SimulatorConfig{})
+	processors := bank.NewRegistry("simulator")
+	processors.Register("simulator", bankClient, "")
+	held, err := bankClient.Authorize(ctx, 1000, "USD", "tok_visa")
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	intents := []domain.PaymentIntent{
+		{ID: "pi_held", Processor: "simulator", Status: domain.StatusRequiresCapture, AuthorizationID: held.TransactionID, AmountCapturable: 1000, CaptureBefore: &expired},
+		{ID: "pi_lapsed", Status: domain.StatusRequiresCapture, AuthorizationID: "auth_unknown", AmountCapturable: 500, CaptureBefore: &expired},
+	}
+
+	var canceled []string
+	repo := &domain.MockRepository{
+		ListExpiredAuthorizationsFunc: func(ctx context.Context, before time.Time, limit int) ([]domain.PaymentIntent, error) {
+			if !before.Equal(now) {
+				t.Errorf("before = %v, want %v", before, now)
+			}
+			return intents, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, intent *domain.PaymentIntent, tr domain.Transition) error {
+			if tr.From != domain.StatusRequiresCapture || tr.To != domain.StatusCanceled || tr.Event != domain.EventPaymentCanceled {
+				t.Errorf("unexpected transition %+v", tr)
+			}
+			if intent.AmountCapturable != 0 {
+				t.Errorf("amount_capturable = %d, want 0", intent.AmountCapturable)
+			}
+			canceled = append(canceled, intent.ID)
+			return nil
+		},
+	}
+
+	w := NewAuthorizationExpiryWorker(domain.NewPaymentService(repo), processors, time.Minute)
+	if n := w.runProcess(ctx, now); n != 2 {
+		t.Fatalf("runProcess canceled %d intents, want 2", n)
+	}
+	if len(canceled) != 2 {
+		t.Errorf("canceled = %v, want both intents", canceled)
+	}
+	if _, err := bankClient.Capture(ctx, held.TransactionID, 1000); err != bank.ErrAuthorizationClosed {
+		t.Errorf("Capture after void: err = %v, want %v", err, bank.ErrAuthorizationClosed)
+	}
+}
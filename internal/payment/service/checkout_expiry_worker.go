@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+// CheckoutExpiryWorker closes checkout sessions left open past their
+// expiry, and completes those whose payment succeeded without completing
+// them.
+type CheckoutExpiryWorker struct {
+	checkout *domain.CheckoutService
+	interval time.Duration
+}
+
+func NewCheckoutExpiryWorker(checkout *domain.CheckoutService, interval time.Duration) *CheckoutExpiryWorker {
+	return &CheckoutExpiryWorker{
+		checkout: checkout,
+		interval: interval,
+	}
+}
+
+func (w *CheckoutExpiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runProcess(ctx, time.Now())
+		}
+	}
+}
+
+// runProcess closes the sessions expired at now and returns how many it
+// closed. Sessions whose payment attempt is still in progress are left for
+// a later run.
+func (w *CheckoutExpiryWorker) runProcess(ctx context.Context, now time.Time) int {
+	sessions, err := w.checkout.ListExpiredSessions(ctx, now, expiryBatchSize)
+	if err != nil {
+		log.Printf("Worker: failed to list expired checkout sessions: %v", err)
+		return 0
+	}
+
+	closed := 0
+	for i := range sessions {
+		ok, err := w.checkout.ExpireSession(ctx, &sessions[i], now)
+		if err != nil {
+			log.Printf("Worker: failed to expire checkout session %s: %v", sessions[i].ID, err)
+			continue
+		}
+		if ok {
+			closed++
+		}
+	}
+	return closed
+}
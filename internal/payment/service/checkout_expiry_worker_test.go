@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+func TestCheckoutExpiryWorker_ClosesExpiredSessions(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	sessions := []domain.CheckoutSession{
+		{ID: "cs_unpaid", Status: domain.CheckoutSessionOpen, ExpiresAt: expired},
+		{ID: "cs_declined", PaymentIntentID: "pi_declined", Status: domain.CheckoutSessionOpen, ExpiresAt: expired},
+		{ID: "cs_paid", PaymentIntentID: "pi_paid", Status: domain.CheckoutSessionOpen, ExpiresAt: expired},
+		{ID: "cs_authenticating", PaymentIntentID: "pi_authenticating", Status: domain.CheckoutSessionOpen, ExpiresAt: expired},
+	}
+	intents := map[string]domain.Status{
+		"pi_declined":       domain.StatusRequiresPaymentMethod,
+		"pi_paid":           domain.StatusSucceeded,
+		"pi_authenticating": domain.StatusRequiresAction,
+	}
+
+	closed := map[string]domain.CheckoutSessionStatus{}
+	var canceled []string
+	repo := &domain.MockRepository{
+		ListExpiredCheckoutSessionsFunc: func(ctx context.Context, before time.Time, limit int) ([]domain.CheckoutSession, error) {
+			return sessions, nil
+		},
+		GetPaymentIntentFunc: func(ctx context.Context, id string) (*domain.PaymentIntent, error) {
+			return &domain.PaymentIntent{ID: id, Status: intents[id]}, nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, intent *domain.PaymentIntent, tr domain.Transition) error {
+			if tr.To == domain.StatusCanceled {
+				canceled = append(canceled, intent.ID)
+			}
+			return nil
+		},
+		GetCheckoutSessionByPaymentIntentFunc: func(ctx context.Context, intentID string) (*domain.CheckoutSession, error) {
+			for _, s := range sessions {
+				if s.PaymentIntentID == intentID {
+					return &s, nil
+				}
+			}
+			return nil, nil
+		},
+		UpdateCheckoutSessionFunc: func(ctx context.Context, session *domain.CheckoutSession, from domain.CheckoutSessionStatus) error {
+			if from != domain.CheckoutSessionOpen {
+				t.Errorf("unexpected update of %s from %s", session.ID, from)
+			}
+			closed[session.ID] = session.Status
+			return nil
+		},
+	}
+
+	payments := domain.NewPaymentService(repo)
+	w := NewCheckoutExpiryWorker(domain.NewCheckoutService(repo, payments, "https://pay.test"), time.Minute)
+	if n := w.runProcess(context.Background(), now); n != 3 {
+		t.Fatalf("runProcess closed %d sessions, want 3", n)
+	}
+	want := map[string]domain.CheckoutSessionStatus{
+		"cs_unpaid":   domain.CheckoutSessionExpired,
+		"cs_declined": domain.CheckoutSessionExpired,
+		"cs_paid":     domain.CheckoutSessionComplete,
+	}
+	for id, status := range want {
+		if closed[id] != status {
+			t.Errorf("session %s = %q, want %q", id, closed[id], status)
+		}
+	}
+	if _, ok := closed["cs_authenticating"]; ok {
+		t.Error("session with a challenge in progress was closed")
+	}
+	if len(canceled) != 1 || canceled[0] != "pi_declined" {
+		t.Errorf("canceled = %v, want [pi_declined]", canceled)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+// DisputeDeadlineWorker loses disputes the merchant did not answer with
+// evidence before their deadline.
+type DisputeDeadlineWorker struct {
+	disputes *domain.DisputeService
+	interval time.Duration
+}
+
+func NewDisputeDeadlineWorker(disputes *domain.DisputeService, interval time.Duration) *DisputeDeadlineWorker {
+	return &DisputeDeadlineWorker{
+		disputes: disputes,
+		interval: interval,
+	}
+}
+
+func (w *DisputeDeadlineWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runProcess(ctx, time.Now())
+		}
+	}
+}
+
+// runProcess loses the disputes overdue at now and returns how many it
+// closed.
+func (w *DisputeDeadlineWorker) runProcess(ctx context.Context, now time.Time) int {
+	disputes, err := w.disputes.ListOverdueDisputes(ctx, now, expiryBatchSize)
+	if err != nil {
+		log.Printf("Worker: failed to list overdue disputes: %v", err)
+		return 0
+	}
+
+	lost := 0
+	for i := range disputes {
+		if err := w.disputes.ExpireDispute(ctx, &disputes[i]); err != nil {
+			log.Printf("Worker: failed to close overdue dispute %s: %v", disputes[i].ID, err)
+			continue
+		}
+		lost++
+	}
+	return lost
+}
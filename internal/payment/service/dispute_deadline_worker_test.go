@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+func TestDisputeDeadlineWorker_LosesOverdueDisputes(t *testing.T) {
+	now := time.Now()
+	disputes := []domain.Dispute{
+		{ID: "dp_overdue", Status: domain.DisputeNeedsResponse, EvidenceDueBy: now.Add(-time.Hour)},
+		{ID: "dp_answered", Status: domain.DisputeNeedsResponse, EvidenceDueBy: now.Add(-time.Hour)},
+	}
+
+	var lost []string
+	repo := &domain.MockRepository{
+		ListOverdueDisputesFunc: func(ctx context.Context, before time.Time, limit int) ([]domain.Dispute, error) {
+			return disputes, nil
+		},
+		UpdateDisputeFunc: func(ctx context.Context, dispute *domain.Dispute, from domain.DisputeStatus) error {
+			// Evidence arrived after the listing.
+			if dispute.ID == "dp_answered" {
+				return domain.ErrStatusConflict
+			}
+			if from != domain.DisputeNeedsResponse || dispute.Status != domain.DisputeLost {
+				t.Errorf("unexpected update %s -> %s", from, dispute.Status)
+			}
+			lost = append(lost, dispute.ID)
+			return nil
+		},
+	}
+
+	w := NewDisputeDeadlineWorker(domain.NewDisputeService(repo), time.Minute)
+	if n := w.runProcess(context.Background(), now); n != 1 {
+		t.Fatalf("runProcess lost %d disputes, want 1", n)
+	}
+	if len(lost) != 1 || lost[0] != "dp_overdue" {
+		t.Errorf("lost = %v, want [dp_overdue]", lost)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+// refundRetryDelay is how long a refund must have been pending before the
+// worker retries it, so it does not race the request still posting it.
+const refundRetryDelay = time.Minute
+
+// RefundRetryWorker finishes refunds left pending because their ledger
+// reversal could not be confirmed or the refund could not be completed
+// after it.
+type RefundRetryWorker struct {
+	refunds  *domain.RefundService
+	interval time.Duration
+}
+
+func NewRefundRetryWorker(refunds *domain.RefundService, interval time.Duration) *RefundRetryWorker {
+	return &RefundRetryWorker{
+		refunds:  refunds,
+		interval: interval,
+	}
+}
+
+func (w *RefundRetryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runProcess(ctx, time.Now())
+		}
+	}
+}
+
+// runProcess retries the refunds stalled at now and returns how many it
+// completed or failed.
+func (w *RefundRetryWorker) runProcess(ctx context.Context, now time.Time) int {
+	refunds, err := w.refunds.ListPendingRefunds(ctx, now.Add(-refundRetryDelay), expiryBatchSize)
+	if err != nil {
+		log.Printf("Worker: failed to list pending refunds: %v", err)
+		return 0
+	}
+
+	settled := 0
+	for i := range refunds {
+		if err := w.refunds.RetryRefund(ctx, &refunds[i]); err != nil && refunds[i].Status == domain.RefundPending {
+			log.Printf("Worker: refund %s is still pending: %v", refunds[i].ID, err)
+			continue
+		}
+		settled++
+	}
+	return settled
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/payment/domain"
+)
+
+type stubLedger struct {
+	posted map[string]int
+	err    map[string]error
+}
+
+func (l *stubLedger) ReversePayment(ctx context.Context, payment *domain.PaymentIntent, reference string, amount int64, reason string) (string, error) {
+	if err := l.err[reference]; err != nil {
+		return "", err
+	}
+	l.posted[reference]++
+	return "tx_" + reference, nil
+}
+
+type stubProcessors struct {
+	declined map[string]bool
+}
+
+func (p *stubProcessors) RefundPayment(ctx context.Context, payment *domain.PaymentIntent, refundID string, amount int64) (string, error) {
+	if p.declined[refundID] {
+		return "", domain.ErrProcessorDeclined
+	}
+	return "re_" + refundID, nil
+}
+
+func TestRefundRetryWorker_SettlesPendingRefunds(t *testing.T) {
+	now := time.Now()
+	refunds := []domain.Refund{
+		{ID: "re_posted", PaymentIntentID: "pi_1", Amount: 100, Status: domain.RefundPending, LedgerReference: "ref_posted"},
+		{ID: "re_declined", PaymentIntentID: "pi_2", Amount: 100, Status: domain.RefundPending, LedgerReference: "ref_declined"},
+		{ID: "re_down", PaymentIntentID: "pi_3", Amount: 100, Status: domain.RefundPending, LedgerReference: "ref_down"},
+	}
+
+	var completed, failed []string
+	repo := &domain.MockRepository{
+		ListPendingRefundsFunc: func(ctx context.Context, before time.Time, limit int) ([]domain.Refund, error) {
+			if !before.Equal(now.Add(-refundRetryDelay)) {
+				t.Errorf("listed refunds pending before %v, want %v", before, now.Add(-refundRetryDelay))
+			}
+			return refunds, nil
+		},
+		GetPaymentIntentFunc: func(ctx context.Context, id string) (*domain.PaymentIntent, error) {
+			return &domain.PaymentIntent{ID: id, ZoneID: "zone_1", Mode: "live"}, nil
+		},
+		CompleteRefundFunc: func(ctx context.Context, refund *domain.Refund) error {
+			completed = append(completed, refund.ID)
+			return nil
+		},
+		FailRefundFunc: func(ctx context.Context, refund *domain.Refund) error {
+			failed = append(failed, refund.ID)
+			return nil
+		},
+	}
+	ledger := &stubLedger{posted: map[string]int{}, err: map[string]error{
+		"ref_down": errors.New("connection refused"),
+	}}
+	processors := &stubProcessors{declined: map[string]bool{"re_declined": true}}
+
+	w := NewRefundRetryWorker(domain.NewRefundService(repo, ledger, processors), time.Minute)
+	if n := w.runProcess(context.Background(), now); n != 2 {
+		t.Fatalf("runProcess settled %d refunds, want 2", n)
+	}
+	if len(completed) != 1 || completed[0] != "re_posted" || ledger.posted["ref_posted"] != 1 {
+		t.Errorf("completed = %v, want [re_posted] posted under its own reference", completed)
+	}
+	if len(failed) != 1 || failed[0] != "re_declined" || ledger.posted["ref_declined"] != 0 {
+		t.Errorf("failed = %v, want [re_declined] without a reversal", failed)
+	}
+	if refunds[2].Status != domain.RefundPending {
+		t.Errorf("refund with an unconfirmed reversal is %s, want pending", refunds[2].Status)
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+// maxStatementSize bounds uploaded statement files. A month of daily
+// settlement lines is well below this.
+const maxStatementSize = 20 << 20
+
+type ReconciliationHandler struct {
+	service *domain.ReconciliationService
+}
+
+func NewReconciliationHandler(service *domain.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{service: service}
+}
+
+// ImportStatement takes the raw statement file as the request body, with the
+// ledger account and format in the query string.
+func (h *ReconciliationHandler) ImportStatement(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := domain.Format(q.Get("format"))
+	if format == "" {
+		apierror.BadRequest("format is required: csv, mt940 or camt053").Write(w)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxStatementSize))
+	if err != nil {
+		apierror.BadRequest("Statement file is too large or unreadable").Write(w)
+		return
+	}
+	if len(data) == 0 {
+		apierror.BadRequest("Statement file is empty").Write(w)
+		return
+	}
+
+	result, err := h.service.ImportStatement(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), q.Get("account_id"), format, data)
+	if err != nil {
+		writeError(w, err, "Failed to import statement")
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Duplicate {
+		status = http.StatusOK
+	}
+	jsonutil.WriteJSON(w, status, result)
+}
+
+func (h *ReconciliationHandler) ListStatements(w http.ResponseWriter, r *http.Request) {
+	limit, ok := parseLimit(w, r)
+	if !ok {
+		return
+	}
+
+	statements, err := h.service.ListStatements(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), r.URL.Query().Get("account_id"), limit)
+	if err != nil {
+		writeError(w, err, "Error retrieving statements")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": statements})
+}
+
+func (h *ReconciliationHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	st, err := h.service.GetStatement(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), jsonutil.GetIDAfter(r, "statements"))
+	if err != nil {
+		writeError(w, err, "Error retrieving statement")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, st)
+}
+
+// Reconcile runs the matching rules for an account again, for instance after
+// new ledger postings arrived or the rules changed.
+func (h *ReconciliationHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AccountID string `json:"account_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	result, err := h.service.Reconcile(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), req.AccountID)
+	if err != nil {
+		writeError(w, err, "Failed to reconcile account")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, result)
+}
+
+func (h *ReconciliationHandler) ReviewQueue(w http.ResponseWriter, r *http.Request) {
+	queue, err := h.service.ReviewQueue(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), r.URL.Query().Get("account_id"))
+	if err != nil {
+		writeError(w, err, "Error retrieving review queue")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, queue)
+}
+
+func (h *ReconciliationHandler) ManualMatch(w http.ResponseWriter, r *http.Request) {
+	var req domain.ManualMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	match, err := h.service.ManualMatch(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), r.Header.Get("X-User-ID"), req)
+	if err != nil {
+		writeError(w, err, "Failed to match items")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusCreated, match)
+}
+
+func (h *ReconciliationHandler) WriteOffLine(w http.ResponseWriter, r *http.Request) {
+	var req domain.WriteOffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	match, err := h.service.WriteOffLine(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), r.Header.Get("X-User-ID"), jsonutil.GetIDAfter(r, "lines"), req)
+	if err != nil {
+		writeError(w, err, "Failed to write off statement line")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusCreated, match)
+}
+
+func (h *ReconciliationHandler) WriteOffEntry(w http.ResponseWriter, r *http.Request) {
+	var req domain.WriteOffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	match, err := h.service.WriteOffEntry(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), r.Header.Get("X-User-ID"), jsonutil.GetIDAfter(r, "entries"), req)
+	if err != nil {
+		writeError(w, err, "Failed to write off ledger entry")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusCreated, match)
+}
+
+func (h *ReconciliationHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("account_id")
+	rules, err := h.service.GetRules(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), accountID)
+	if err != nil {
+		writeError(w, err, "Error retrieving rules")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, map[string]interface{}{"account_id": accountID, "rules": rules})
+}
+
+func (h *ReconciliationHandler) SetRules(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AccountID string        `json:"account_id"`
+		Rules     []domain.Rule `json:"rules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.BadRequest("Invalid request body").Write(w)
+		return
+	}
+
+	if err := h.service.SetRules(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), req.AccountID, req.Rules); err != nil {
+		writeError(w, err, "Failed to save rules")
+		return
+	}
+	if req.Rules == nil {
+		req.Rules = []domain.Rule{}
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, req)
+}
+
+func writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case domain.IsValidationError(err):
+		apierror.BadRequest(err.Error()).Write(w)
+	case errors.Is(err, domain.ErrAccountNotFound):
+		apierror.NotFound("Account not found").Write(w)
+	case errors.Is(err, domain.ErrStatementNotFound):
+		apierror.NotFound("Statement not found").Write(w)
+	case errors.Is(err, domain.ErrLineNotFound):
+		apierror.NotFound("Statement line not found").Write(w)
+	case errors.Is(err, domain.ErrEntryNotFound):
+		apierror.NotFound("Ledger entry not found").Write(w)
+	case errors.Is(err, domain.ErrRunNotFound):
+		apierror.NotFound("Reconciliation run not found").Write(w)
+	case errors.Is(err, domain.ErrDiscrepancyNotFound):
+		apierror.NotFound("Discrepancy not found").Write(w)
+	case errors.Is(err, domain.ErrAlreadyMatched), errors.Is(err, domain.ErrRunInProgress):
+		apierror.Conflict(err.Error()).Write(w)
+	default:
+		apierror.Internal(msg).Write(w)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+type RunHandler struct {
+	service *domain.RunService
+}
+
+func NewRunHandler(service *domain.RunService) *RunHandler {
+	return &RunHandler{service: service}
+}
+
+// StartRun runs an audit now instead of waiting for the schedule. It
+// returns when the run has finished.
+func (h *RunHandler) StartRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.Run(r.Context(), "manual")
+	if err != nil && run == nil {
+		writeError(w, err, "Failed to start reconciliation run")
+		return
+	}
+	// A run that failed midway is recorded; it is returned with its error.
+	jsonutil.WriteJSON(w, http.StatusCreated, run)
+}
+
+func (h *RunHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit, ok := parseLimit(w, r)
+	if !ok {
+		return
+	}
+	runs, err := h.service.ListRuns(r.Context(), limit)
+	if err != nil {
+		apierror.Internal("Error retrieving reconciliation runs").Write(w)
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": runs})
+}
+
+func (h *RunHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.GetRun(r.Context(), jsonutil.GetIDAfter(r, "runs"))
+	if err != nil {
+		writeError(w, err, "Error retrieving reconciliation run")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, run)
+}
+
+func (h *RunHandler) ListDiscrepancies(w http.ResponseWriter, r *http.Request) {
+	limit, ok := parseLimit(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	list, err := h.service.ListDiscrepancies(r.Context(), domain.DiscrepancyFilter{
+		ZoneID:   r.Header.Get("X-Zone-ID"),
+		Mode:     r.Header.Get("X-Zone-Mode"),
+		RunID:    q.Get("run_id"),
+		Category: domain.DiscrepancyCategory(q.Get("category")),
+		Status:   domain.DiscrepancyStatus(q.Get("status")),
+		Limit:    limit,
+	})
+	if err != nil {
+		writeError(w, err, "Error retrieving discrepancies")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": list})
+}
+
+func (h *RunHandler) GetDiscrepancy(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.GetDiscrepancy(r.Context(), r.Header.Get("X-Zone-ID"), r.Header.Get("X-Zone-Mode"), jsonutil.GetIDAfter(r, "discrepancies"))
+	if err != nil {
+		writeError(w, err, "Error retrieving discrepancy")
+		return
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, d)
+}
+
+func parseLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			apierror.BadRequest("limit must be between 1 and 500").Write(w)
+			return 0, false
+		}
+		limit = n
+	}
+	return limit, true
+}
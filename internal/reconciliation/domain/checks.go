@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// refundReference is the ledger reference of a payment's refund posting
+// made before payments stored refunds. Refunds now carry their own
+// reference, refund_<payment id>_<refund id>.
+func refundReference(paymentID string) string {
+	return "refund_" + paymentID
+}
+
+// paymentIDFromReference returns the payment a ledger reference points to,
+// if it has the shape of a payment or refund posting.
+func paymentIDFromReference(ref string) (string, bool) {
+	id := ref
+	if rest, ok := strings.CutPrefix(ref, "refund_"); ok {
+		id, _, _ = strings.Cut(rest, "_")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// paymentRefunds returns the refunds expected in the ledger for p. A
+// refunded payment without stored refunds was refunded in full under the
+// legacy reference.
+func paymentRefunds(p Payment) []PaymentRefund {
+	if len(p.Refunds) == 0 && strings.EqualFold(p.Status, "refunded") {
+		return []PaymentRefund{{Reference: refundReference(p.ID), Amount: p.Amount}}
+	}
+	return p.Refunds
+}
+
+// checkPayment compares a payment with its ledger postings, given the
+// transactions found for its references. Statuses are compared
+// case-insensitively as services have written both cases.
+func checkPayment(p Payment, txs map[string]LedgerTransaction) []Discrepancy {
+	charge, charged := txs[p.ID]
+	legacyRefund, legacyRefunded := txs[refundReference(p.ID)]
+
+	var out []Discrepancy
+	switch status := strings.ToLower(p.Status); status {
+	case "succeeded", "partially_refunded", "refunded":
+		if !charged {
+			out = append(out, paymentDiscrepancy(p, MissingInLedger, p.ID, nil, status+" payment has no ledger transaction"))
+		} else {
+			out = append(out, checkAmounts(p, p.ID, charge, p.Amount)...)
+		}
+		refunds := paymentRefunds(p)
+		for _, r := range refunds {
+			tx, ok := txs[r.Reference]
+			switch {
+			case ok:
+				out = append(out, checkAmounts(p, r.Reference, tx, r.Amount)...)
+			case !r.Pending:
+				// A pending refund may not have reached the ledger yet.
+				out = append(out, paymentDiscrepancy(p, MissingInLedger, r.Reference, nil, "refund has no ledger reversal"))
+			}
+		}
+		if legacyRefunded && !hasRefund(refunds, refundReference(p.ID)) {
+			out = append(out, paymentDiscrepancy(p, StatusMismatch, refundReference(p.ID), &legacyRefund, "ledger has a refund for a payment that was not refunded"))
+		}
+	default:
+		// Payments that did not complete must not have moved money, unless
+		// the posting was reversed again.
+		if charged && !legacyRefunded {
+			out = append(out, paymentDiscrepancy(p, StatusMismatch, p.ID, &charge,
+				fmt.Sprintf("ledger has a transaction for a payment in status %s", p.Status)))
+		}
+	}
+	return out
+}
+
+func hasRefund(refunds []PaymentRefund, ref string) bool {
+	for _, r := range refunds {
+		if r.Reference == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAmounts verifies that a posting balances and moved amount in the
+// payment currency.
+func checkAmounts(p Payment, ref string, tx LedgerTransaction, amount int64) []Discrepancy {
+	sums := map[string]int64{}
+	var moved int64
+	inCurrency := false
+	for _, leg := range tx.Legs {
+		sums[leg.Currency] += leg.Amount
+		if strings.EqualFold(leg.Currency, p.Currency) {
+			inCurrency = true
+			if leg.Amount > 0 {
+				moved += leg.Amount
+			}
+		}
+	}
+
+	var out []Discrepancy
+	var unbalanced []string
+	for ccy, sum := range sums {
+		if sum != 0 {
+			unbalanced = append(unbalanced, fmt.Sprintf("%s %d", ccy, sum))
+		}
+	}
+	if len(unbalanced) > 0 {
+		sort.Strings(unbalanced)
+		out = append(out, paymentDiscrepancy(p, UnbalancedTransaction, ref, &tx,
+			"entries do not sum to zero: "+strings.Join(unbalanced, ", ")))
+	}
+
+	switch {
+	case !inCurrency:
+		out = append(out, paymentDiscrepancy(p, CurrencyMismatch, ref, &tx,
+			fmt.Sprintf("ledger transaction has no entries in %s", p.Currency)))
+	case moved != amount:
+		d := paymentDiscrepancy(p, AmountMismatch, ref, &tx,
+			fmt.Sprintf("ledger moved %d, expected %d", moved, amount))
+		d.ExpectedAmount, d.ActualAmount = &amount, &moved
+		out = append(out, d)
+	}
+	return out
+}
+
+// unknownPosting reports whether a payment or refund posting under ref has
+// no payment, or is a refund the payment does not know of. It also returns
+// the payment the reference points to.
+func unknownPosting(ref string, payments map[string]Payment) (string, bool) {
+	id, ok := paymentIDFromReference(ref)
+	if !ok {
+		return "", false
+	}
+	p, ok := payments[id]
+	if !ok {
+		return id, true
+	}
+	// Refunds posted under the legacy reference are checked with the
+	// payment's status.
+	if !strings.HasPrefix(ref, "refund_") || ref == refundReference(id) {
+		return id, false
+	}
+	return id, !hasRefund(p.Refunds, ref)
+}
+
+// checkOrphan reports a payment posting whose payment does not exist, or a
+// refund posting whose refund does not.
+func checkOrphan(tx LedgerTransaction, payments map[string]Payment) *Discrepancy {
+	id, unknown := unknownPosting(tx.ReferenceID, payments)
+	if !unknown {
+		return nil
+	}
+
+	detail := fmt.Sprintf("ledger transaction references payment %s, which does not exist", id)
+	if _, ok := payments[id]; ok {
+		detail = fmt.Sprintf("ledger refund of payment %s has no refund in payments", id)
+	}
+	d := &Discrepancy{
+		ZoneID:        tx.ZoneID,
+		Mode:          tx.Mode,
+		Category:      OrphanLedgerTransaction,
+		ReferenceID:   tx.ReferenceID,
+		PaymentID:     id,
+		TransactionID: tx.ID,
+		Detail:        detail,
+	}
+	var moved int64
+	for _, leg := range tx.Legs {
+		if leg.Amount > 0 {
+			moved += leg.Amount
+			d.Currency = leg.Currency
+		}
+	}
+	d.ActualAmount = &moved
+	return d
+}
+
+func paymentDiscrepancy(p Payment, category DiscrepancyCategory, ref string, tx *LedgerTransaction, detail string) Discrepancy {
+	d := Discrepancy{
+		ZoneID:        p.ZoneID,
+		Mode:          p.Mode,
+		Category:      category,
+		ReferenceID:   ref,
+		PaymentID:     p.ID,
+		PaymentStatus: p.Status,
+		Currency:      p.Currency,
+		Detail:        detail,
+	}
+	if tx != nil {
+		d.TransactionID = tx.ID
+	}
+	return d
+}
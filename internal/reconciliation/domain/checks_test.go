@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	payID   = "7f1f4c1e-3a47-4a59-9a5e-1c2f0b6f9d10"
+	otherID = "0b8e2d9a-5f0c-4d7e-8a61-2f3c4d5e6f70"
+)
+
+func posting(id, ref string, amount int64, ccy string) LedgerTransaction {
+	return LedgerTransaction{ID: id, ReferenceID: ref, Legs: []LedgerLeg{
+		{AccountID: "wallet", Amount: amount, Currency: ccy},
+		{AccountID: "clearing", Amount: -amount, Currency: ccy},
+	}}
+}
+
+func TestCheckPayment(t *testing.T) {
+	type found struct {
+		category DiscrepancyCategory
+		ref      string
+	}
+	charge := posting("tx_1", payID, 5000, "USD")
+	refund := posting("tx_2", "refund_"+payID, -5000, "USD")
+	refund.Legs[0], refund.Legs[1] = refund.Legs[1], refund.Legs[0]
+	unbalanced := posting("tx_1", payID, 5000, "USD")
+	unbalanced.Legs[1].Amount = -4000
+	firstRef, secondRef := "refund_"+payID+"_"+otherID, "refund_"+payID+"_second"
+	first := posting("tx_3", firstRef, 2000, "USD")
+	second := posting("tx_4", secondRef, 3000, "USD")
+	partial := []PaymentRefund{{Reference: firstRef, Amount: 2000}}
+	both := []PaymentRefund{{Reference: firstRef, Amount: 2000}, {Reference: secondRef, Amount: 3000}}
+	pending := []PaymentRefund{{Reference: firstRef, Amount: 2000}, {Reference: secondRef, Amount: 3000, Pending: true}}
+
+	tests := []struct {
+		name    string
+		status  string
+		refunds []PaymentRefund
+		txs     []LedgerTransaction
+		want    []found
+	}{
+		{"succeeded and posted", "SUCCEEDED", nil, []LedgerTransaction{charge}, nil},
+		{"succeeded without posting", "succeeded", nil, nil, []found{{MissingInLedger, payID}}},
+		{"amount differs", "succeeded", nil, []LedgerTransaction{posting("tx_1", payID, 4999, "USD")}, []found{{AmountMismatch, payID}}},
+		{"currency differs", "succeeded", nil, []LedgerTransaction{posting("tx_1", payID, 5000, "EUR")}, []found{{CurrencyMismatch, payID}}},
+		{"entries do not balance", "succeeded", nil, []LedgerTransaction{unbalanced}, []found{{UnbalancedTransaction, payID}}},
+		{"refund without refunded status", "succeeded", nil, []LedgerTransaction{charge, refund}, []found{{StatusMismatch, "refund_" + payID}}},
+		{"refunded and reversed", "refunded", nil, []LedgerTransaction{charge, refund}, nil},
+		{"refunded without reversal", "refunded", nil, []LedgerTransaction{charge}, []found{{MissingInLedger, "refund_" + payID}}},
+		{"failed with posting", "FAILED", nil, []LedgerTransaction{charge}, []found{{StatusMismatch, payID}}},
+		{"cancelled and reversed", "CANCELLED", nil, []LedgerTransaction{charge, refund}, nil},
+		{"failed without posting", "failed", nil, nil, nil},
+		{"partially refunded and reversed", "partially_refunded", partial, []LedgerTransaction{charge, first}, nil},
+		{"refunded in two parts", "refunded", both, []LedgerTransaction{charge, first, second}, nil},
+		{"partial refund without reversal", "partially_refunded", partial, []LedgerTransaction{charge}, []found{{MissingInLedger, firstRef}}},
+		{"partial reversal amount differs", "partially_refunded", partial, []LedgerTransaction{charge, posting("tx_3", firstRef, 2500, "USD")}, []found{{AmountMismatch, firstRef}}},
+		{"pending refund not yet reversed", "partially_refunded", pending, []LedgerTransaction{charge, first}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs := map[string]LedgerTransaction{}
+			for _, tx := range tt.txs {
+				txs[tx.ReferenceID] = tx
+			}
+			p := Payment{ID: payID, ZoneID: "zone_1", Mode: "live", Amount: 5000, Currency: "usd", Status: tt.status, Refunds: tt.refunds}
+
+			var got []found
+			for _, d := range checkPayment(p, txs) {
+				got = append(got, found{d.Category, d.ReferenceID})
+				if d.PaymentID != payID || d.ZoneID != "zone_1" || d.Detail == "" {
+					t.Errorf("incomplete discrepancy: %+v", d)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOrphan(t *testing.T) {
+	payments := map[string]Payment{payID: {ID: payID}}
+
+	if d := checkOrphan(posting("tx_1", payID, 100, "USD"), payments); d != nil {
+		t.Errorf("expected no orphan for an existing payment, got %+v", d)
+	}
+	if d := checkOrphan(posting("tx_1", "topup_42", 100, "USD"), payments); d != nil {
+		t.Errorf("expected references other than payments to be ignored, got %+v", d)
+	}
+	d := checkOrphan(posting("tx_2", "refund_"+otherID, 100, "USD"), payments)
+	if d == nil || d.Category != OrphanLedgerTransaction || d.PaymentID != otherID || d.TransactionID != "tx_2" || *d.ActualAmount != 100 {
+		t.Errorf("expected an orphan refund, got %+v", d)
+	}
+
+	refunded := map[string]Payment{payID: {ID: payID, Refunds: []PaymentRefund{{Reference: "refund_" + payID + "_r1", Amount: 100}}}}
+	if d := checkOrphan(posting("tx_3", "refund_"+payID+"_r1", 100, "USD"), refunded); d != nil {
+		t.Errorf("expected no orphan for a known refund, got %+v", d)
+	}
+	d = checkOrphan(posting("tx_4", "refund_"+payID+"_r2", 100, "USD"), refunded)
+	if d == nil || d.Category != OrphanLedgerTransaction || d.PaymentID != payID || d.ReferenceID != "refund_"+payID+"_r2" {
+		t.Errorf("expected an orphan refund of an existing payment, got %+v", d)
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrStatementNotFound = errors.New("bank statement not found")
+	ErrLineNotFound      = errors.New("bank statement line not found")
+	ErrEntryNotFound     = errors.New("ledger entry not found")
+	ErrAccountNotFound   = errors.New("ledger account not found")
+	// ErrAlreadyMatched is returned when a line or entry was matched or
+	// written off by someone else first.
+	ErrAlreadyMatched = errors.New("item is already matched or written off")
+
+	ErrRunNotFound         = errors.New("reconciliation run not found")
+	ErrDiscrepancyNotFound = errors.New("discrepancy not found")
+	// ErrRunInProgress is returned when a run is started while another one
+	// has not finished.
+	ErrRunInProgress = errors.New("a reconciliation run is already in progress")
+)
+
+// ValidationError is returned when a request is rejected by reconciliation
+// rules rather than failing in storage. API layers map it to a client error.
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+func invalidf(format string, args ...any) error {
+	return &ValidationError{msg: fmt.Sprintf(format, args...)}
+}
+
+// IsValidationError reports whether err, or any error it wraps, is a
+// ValidationError.
+func IsValidationError(err error) bool {
+	var verr *ValidationError
+	return errors.As(err, &verr)
+}
@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxGroupCandidates bounds how many items near a date a many_to_one
+	// search considers; maxSubsetSteps bounds the search itself.
+	maxGroupCandidates = 20
+	maxSubsetSteps     = 100000
+	defaultGroupSize   = 10
+)
+
+// matchItems runs the rules in order over unmatched lines and entries and
+// returns the matches it proposes. It is deterministic: items are taken in
+// date order and the earliest or closest candidate wins.
+func matchItems(rules []Rule, lines []Line, entries []LedgerEntry) []Match {
+	m := &matcher{
+		lines:   append([]Line(nil), lines...),
+		entries: append([]LedgerEntry(nil), entries...),
+	}
+	sort.SliceStable(m.lines, func(i, j int) bool {
+		if !m.lines[i].date().Equal(m.lines[j].date()) {
+			return m.lines[i].date().Before(m.lines[j].date())
+		}
+		return m.lines[i].LineNo < m.lines[j].LineNo
+	})
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		if !m.entries[i].EffectiveAt.Equal(m.entries[j].EffectiveAt) {
+			return m.entries[i].EffectiveAt.Before(m.entries[j].EffectiveAt)
+		}
+		return m.entries[i].EntryID < m.entries[j].EntryID
+	})
+	m.usedLines = make([]bool, len(m.lines))
+	m.usedEntries = make([]bool, len(m.entries))
+
+	for _, rule := range rules {
+		switch rule.Type {
+		case RuleReference:
+			m.byReference()
+		case RuleAmountDate:
+			m.byAmountDate(rule.DateWindowDays)
+		case RuleManyToOne:
+			size := rule.MaxGroupSize
+			if size <= 0 {
+				size = defaultGroupSize
+			}
+			m.manyToOne(rule.DateWindowDays, size)
+		}
+	}
+	return m.matches
+}
+
+type matcher struct {
+	lines       []Line
+	entries     []LedgerEntry
+	usedLines   []bool
+	usedEntries []bool
+	matches     []Match
+}
+
+func (m *matcher) add(rule MatchRule, lines, entries []int) {
+	match := Match{Rule: rule}
+	for _, i := range lines {
+		m.usedLines[i] = true
+		match.LineIDs = append(match.LineIDs, m.lines[i].ID)
+	}
+	for _, i := range entries {
+		m.usedEntries[i] = true
+		match.EntryIDs = append(match.EntryIDs, m.entries[i].EntryID)
+	}
+	m.matches = append(m.matches, match)
+}
+
+// byReference pairs a line with the earliest entry of the opposite amount
+// whose reference equals the line's reference or bank reference.
+func (m *matcher) byReference() {
+	refs := make(map[string][]int)
+	for i, e := range m.entries {
+		if ref := normRef(e.ReferenceID); ref != "" && !m.usedEntries[i] {
+			refs[ref] = append(refs[ref], i)
+		}
+	}
+	for li, l := range m.lines {
+		if m.usedLines[li] {
+			continue
+		}
+		for _, ref := range []string{normRef(l.Reference), normRef(l.BankReference)} {
+			if ref == "" {
+				continue
+			}
+			if ei := m.firstEntry(refs[ref], func(e LedgerEntry) bool { return e.Amount == -l.Amount }); ei >= 0 {
+				m.add(RuleReference, []int{li}, []int{ei})
+				break
+			}
+		}
+	}
+}
+
+// byAmountDate pairs a line with the entry of the opposite amount closest to
+// its date, within the window.
+func (m *matcher) byAmountDate(windowDays int) {
+	for li, l := range m.lines {
+		if m.usedLines[li] {
+			continue
+		}
+		best, bestDiff := -1, 0
+		for ei, e := range m.entries {
+			if m.usedEntries[ei] || e.Amount != -l.Amount {
+				continue
+			}
+			diff := dayDiff(l.date(), e.EffectiveAt)
+			if diff <= windowDays && (best < 0 || diff < bestDiff) {
+				best, bestDiff = ei, diff
+			}
+		}
+		if best >= 0 {
+			m.add(RuleAmountDate, []int{li}, []int{best})
+		}
+	}
+}
+
+// manyToOne looks for several entries summing to one line, as when the bank
+// books a processor's batched payout, and then for several lines summing to
+// one entry, as when one ledger posting is paid out in instalments.
+func (m *matcher) manyToOne(windowDays, maxSize int) {
+	for li, l := range m.lines {
+		if m.usedLines[li] || l.Amount == 0 {
+			continue
+		}
+		var candidates []int
+		var amounts []int64
+		for _, ei := range m.nearestEntries(l.date(), windowDays, -l.Amount) {
+			candidates = append(candidates, ei)
+			amounts = append(amounts, abs(m.entries[ei].Amount))
+		}
+		if picked := findSubset(amounts, abs(l.Amount), maxSize); picked != nil {
+			group := make([]int, len(picked))
+			for i, p := range picked {
+				group[i] = candidates[p]
+			}
+			m.add(RuleManyToOne, []int{li}, group)
+		}
+	}
+
+	for ei, e := range m.entries {
+		if m.usedEntries[ei] || e.Amount == 0 {
+			continue
+		}
+		var candidates []int
+		var amounts []int64
+		for _, li := range m.nearestLines(e.EffectiveAt, windowDays, -e.Amount) {
+			candidates = append(candidates, li)
+			amounts = append(amounts, abs(m.lines[li].Amount))
+		}
+		if picked := findSubset(amounts, abs(e.Amount), maxSize); picked != nil {
+			group := make([]int, len(picked))
+			for i, p := range picked {
+				group[i] = candidates[p]
+			}
+			m.add(RuleManyToOne, group, []int{ei})
+		}
+	}
+}
+
+func (m *matcher) firstEntry(idx []int, ok func(LedgerEntry) bool) int {
+	for _, i := range idx {
+		if !m.usedEntries[i] && ok(m.entries[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nearestEntries returns unused entries within the window that move money
+// the same way as target, closest in date first.
+func (m *matcher) nearestEntries(at time.Time, windowDays int, target int64) []int {
+	var idx []int
+	for i, e := range m.entries {
+		if !m.usedEntries[i] && sameSign(e.Amount, target) && dayDiff(at, e.EffectiveAt) <= windowDays {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return dayDiff(at, m.entries[idx[a]].EffectiveAt) < dayDiff(at, m.entries[idx[b]].EffectiveAt)
+	})
+	if len(idx) > maxGroupCandidates {
+		idx = idx[:maxGroupCandidates]
+	}
+	return idx
+}
+
+func (m *matcher) nearestLines(at time.Time, windowDays int, target int64) []int {
+	var idx []int
+	for i, l := range m.lines {
+		if !m.usedLines[i] && sameSign(l.Amount, target) && dayDiff(at, l.date()) <= windowDays {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return dayDiff(at, m.lines[idx[a]].date()) < dayDiff(at, m.lines[idx[b]].date())
+	})
+	if len(idx) > maxGroupCandidates {
+		idx = idx[:maxGroupCandidates]
+	}
+	return idx
+}
+
+// findSubset returns the indexes of at least two and at most maxSize
+// positive amounts that add up to target, or nil.
+func findSubset(amounts []int64, target int64, maxSize int) []int {
+	var picked []int
+	steps := 0
+	var search func(start int, remaining int64) bool
+	search = func(start int, remaining int64) bool {
+		if remaining == 0 {
+			return len(picked) >= 2
+		}
+		if len(picked) == maxSize {
+			return false
+		}
+		for i := start; i < len(amounts); i++ {
+			if steps++; steps > maxSubsetSteps {
+				return false
+			}
+			if amounts[i] <= 0 || amounts[i] > remaining {
+				continue
+			}
+			picked = append(picked, i)
+			if search(i+1, remaining-amounts[i]) {
+				return true
+			}
+			picked = picked[:len(picked)-1]
+		}
+		return false
+	}
+	if search(0, target) {
+		return picked
+	}
+	return nil
+}
+
+func normRef(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// dayDiff is the number of calendar days between a and b in UTC.
+func dayDiff(a, b time.Time) int {
+	d := a.UTC().Truncate(24*time.Hour).Sub(b.UTC().Truncate(24*time.Hour)) / (24 * time.Hour)
+	if d < 0 {
+		d = -d
+	}
+	return int(d)
+}
+
+func sameSign(a, b int64) bool {
+	return (a > 0 && b > 0) || (a < 0 && b < 0)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
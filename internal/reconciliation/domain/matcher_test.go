@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatchItems(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	line := func(id string, d int, amount int64, ref string) Line {
+		return Line{ID: id, BookingDate: day(d), Amount: amount, Reference: ref}
+	}
+	entry := func(id string, d int, amount int64, ref string) LedgerEntry {
+		return LedgerEntry{EntryID: id, EffectiveAt: day(d).Add(time.Hour), Amount: amount, ReferenceID: ref}
+	}
+
+	tests := []struct {
+		name    string
+		rules   []Rule
+		lines   []Line
+		entries []LedgerEntry
+		want    []Match
+	}{
+		{
+			name:    "reference beats an earlier entry with the same amount",
+			rules:   DefaultRules,
+			lines:   []Line{line("l1", 2, 5000, "PI_2")},
+			entries: []LedgerEntry{entry("e1", 2, -5000, "pi_1"), entry("e2", 2, -5000, "pi_2")},
+			want:    []Match{{Rule: RuleReference, LineIDs: []string{"l1"}, EntryIDs: []string{"e2"}}},
+		},
+		{
+			name:    "reference needs the opposite amount",
+			rules:   []Rule{{Type: RuleReference}},
+			lines:   []Line{line("l1", 2, 5000, "pi_1")},
+			entries: []LedgerEntry{entry("e1", 2, -4999, "pi_1")},
+		},
+		{
+			name:    "amount and date picks the closest entry within the window",
+			rules:   []Rule{{Type: RuleAmountDate, DateWindowDays: 3}},
+			lines:   []Line{line("l1", 5, -1200, "")},
+			entries: []LedgerEntry{entry("e1", 1, 1200, ""), entry("e2", 3, 1200, ""), entry("e3", 6, 1200, "")},
+			want:    []Match{{Rule: RuleAmountDate, LineIDs: []string{"l1"}, EntryIDs: []string{"e3"}}},
+		},
+		{
+			name:    "amount and date outside the window",
+			rules:   []Rule{{Type: RuleAmountDate, DateWindowDays: 1}},
+			lines:   []Line{line("l1", 5, -1200, "")},
+			entries: []LedgerEntry{entry("e1", 2, 1200, "")},
+		},
+		{
+			name:  "many entries settled by one bank line",
+			rules: DefaultRules,
+			lines: []Line{line("l1", 4, 10000, "payout_7")},
+			entries: []LedgerEntry{
+				entry("e1", 3, -2500, "pi_1"),
+				entry("e2", 3, -4000, "pi_2"),
+				entry("e3", 3, -9999, "pi_3"),
+				entry("e4", 4, -3500, "pi_4"),
+			},
+			want: []Match{{Rule: RuleManyToOne, LineIDs: []string{"l1"}, EntryIDs: []string{"e4", "e1", "e2"}}},
+		},
+		{
+			name:    "one entry paid in several bank lines",
+			rules:   []Rule{{Type: RuleManyToOne, DateWindowDays: 2, MaxGroupSize: 2}},
+			lines:   []Line{line("l1", 2, -300, ""), line("l2", 3, -700, ""), line("l3", 3, -100, "")},
+			entries: []LedgerEntry{entry("e1", 2, 1000, "")},
+			want:    []Match{{Rule: RuleManyToOne, LineIDs: []string{"l1", "l2"}, EntryIDs: []string{"e1"}}},
+		},
+		{
+			name:    "group size limit",
+			rules:   []Rule{{Type: RuleManyToOne, DateWindowDays: 2, MaxGroupSize: 2}},
+			lines:   []Line{line("l1", 2, 600, "")},
+			entries: []LedgerEntry{entry("e1", 2, -200, ""), entry("e2", 2, -200, ""), entry("e3", 2, -200, "")},
+		},
+		{
+			name:    "each item is matched once",
+			rules:   DefaultRules,
+			lines:   []Line{line("l1", 2, 100, ""), line("l2", 2, 100, "")},
+			entries: []LedgerEntry{entry("e1", 2, -100, "")},
+			want:    []Match{{Rule: RuleAmountDate, LineIDs: []string{"l1"}, EntryIDs: []string{"e1"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchItems(tt.rules, tt.lines, tt.entries)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
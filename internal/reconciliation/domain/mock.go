@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type MockRepository struct {
+	GetAccountFunc             func(ctx context.Context, id string) (*Account, error)
+	CreateStatementFunc        func(ctx context.Context, st *Statement) error
+	GetStatementFunc           func(ctx context.Context, id string) (*Statement, error)
+	GetStatementByChecksumFunc func(ctx context.Context, accountID, checksum string) (*Statement, error)
+	ListStatementsFunc         func(ctx context.Context, accountID string, limit int) ([]Statement, error)
+	GetCoveredPeriodFunc       func(ctx context.Context, accountID string) (from, to time.Time, err error)
+	GetLineFunc                func(ctx context.Context, id string) (*Line, error)
+	ListUnmatchedLinesFunc     func(ctx context.Context, accountID string) ([]Line, error)
+	GetEntryFunc               func(ctx context.Context, entryID string) (*LedgerEntry, error)
+	GetEntryByReferenceFunc    func(ctx context.Context, accountID, referenceID string) (*LedgerEntry, error)
+	ListUnmatchedEntriesFunc   func(ctx context.Context, accountID string, from, to time.Time) ([]LedgerEntry, error)
+	CreateMatchFunc            func(ctx context.Context, match *Match) error
+	GetRulesFunc               func(ctx context.Context, accountID string) ([]Rule, error)
+	SaveRulesFunc              func(ctx context.Context, accountID string, rules []Rule) error
+}
+
+func (m *MockRepository) GetAccount(ctx context.Context, id string) (*Account, error) {
+	return m.GetAccountFunc(ctx, id)
+}
+
+func (m *MockRepository) CreateStatement(ctx context.Context, st *Statement) error {
+	return m.CreateStatementFunc(ctx, st)
+}
+
+func (m *MockRepository) GetStatement(ctx context.Context, id string) (*Statement, error) {
+	return m.GetStatementFunc(ctx, id)
+}
+
+func (m *MockRepository) GetStatementByChecksum(ctx context.Context, accountID, checksum string) (*Statement, error) {
+	return m.GetStatementByChecksumFunc(ctx, accountID, checksum)
+}
+
+func (m *MockRepository) ListStatements(ctx context.Context, accountID string, limit int) ([]Statement, error) {
+	return m.ListStatementsFunc(ctx, accountID, limit)
+}
+
+func (m *MockRepository) GetCoveredPeriod(ctx context.Context, accountID string) (from, to time.Time, err error) {
+	return m.GetCoveredPeriodFunc(ctx, accountID)
+}
+
+func (m *MockRepository) GetLine(ctx context.Context, id string) (*Line, error) {
+	return m.GetLineFunc(ctx, id)
+}
+
+func (m *MockRepository) ListUnmatchedLines(ctx context.Context, accountID string) ([]Line, error) {
+	return m.ListUnmatchedLinesFunc(ctx, accountID)
+}
+
+func (m *MockRepository) GetEntry(ctx context.Context, entryID string) (*LedgerEntry, error) {
+	return m.GetEntryFunc(ctx, entryID)
+}
+
+func (m *MockRepository) GetEntryByReference(ctx context.Context, accountID, referenceID string) (*LedgerEntry, error) {
+	return m.GetEntryByReferenceFunc(ctx, accountID, referenceID)
+}
+
+func (m *MockRepository) ListUnmatchedEntries(ctx context.Context, accountID string, from, to time.Time) ([]LedgerEntry, error) {
+	return m.ListUnmatchedEntriesFunc(ctx, accountID, from, to)
+}
+
+func (m *MockRepository) CreateMatch(ctx context.Context, match *Match) error {
+	return m.CreateMatchFunc(ctx, match)
+}
+
+func (m *MockRepository) GetRules(ctx context.Context, accountID string) ([]Rule, error) {
+	return m.GetRulesFunc(ctx, accountID)
+}
+
+func (m *MockRepository) SaveRules(ctx context.Context, accountID string, rules []Rule) error {
+	return m.SaveRulesFunc(ctx, accountID, rules)
+}
+
+type MockLedgerPoster struct {
+	RecordAdjustmentFunc func(ctx context.Context, adj Adjustment) error
+}
+
+func (m *MockLedgerPoster) RecordAdjustment(ctx context.Context, adj Adjustment) error {
+	return m.RecordAdjustmentFunc(ctx, adj)
+}
+
+type MockRunRepository struct {
+	GetWatermarksFunc      func(ctx context.Context) (Watermarks, error)
+	CreateRunFunc          func(ctx context.Context, run *Run) error
+	CompleteRunFunc        func(ctx context.Context, run *Run) error
+	FailRunFunc            func(ctx context.Context, run *Run) error
+	FailStaleRunsFunc      func(ctx context.Context, before time.Time) (int, error)
+	GetRunFunc             func(ctx context.Context, id string) (*Run, error)
+	ListRunsFunc           func(ctx context.Context, limit int) ([]Run, error)
+	CreateDiscrepancyFunc  func(ctx context.Context, d *Discrepancy) (bool, error)
+	GetDiscrepancyFunc     func(ctx context.Context, id string) (*Discrepancy, error)
+	ListDiscrepanciesFunc  func(ctx context.Context, filter DiscrepancyFilter) ([]Discrepancy, error)
+	ResolveDiscrepancyFunc func(ctx context.Context, id, runID string) error
+}
+
+func (m *MockRunRepository) GetWatermarks(ctx context.Context) (Watermarks, error) {
+	return m.GetWatermarksFunc(ctx)
+}
+
+func (m *MockRunRepository) CreateRun(ctx context.Context, run *Run) error {
+	return m.CreateRunFunc(ctx, run)
+}
+
+func (m *MockRunRepository) CompleteRun(ctx context.Context, run *Run) error {
+	return m.CompleteRunFunc(ctx, run)
+}
+
+func (m *MockRunRepository) FailRun(ctx context.Context, run *Run) error {
+	return m.FailRunFunc(ctx, run)
+}
+
+func (m *MockRunRepository) FailStaleRuns(ctx context.Context, before time.Time) (int, error) {
+	return m.FailStaleRunsFunc(ctx, before)
+}
+
+func (m *MockRunRepository) GetRun(ctx context.Context, id string) (*Run, error) {
+	return m.GetRunFunc(ctx, id)
+}
+
+func (m *MockRunRepository) ListRuns(ctx context.Context, limit int) ([]Run, error) {
+	return m.ListRunsFunc(ctx, limit)
+}
+
+func (m *MockRunRepository) CreateDiscrepancy(ctx context.Context, d *Discrepancy) (bool, error) {
+	return m.CreateDiscrepancyFunc(ctx, d)
+}
+
+func (m *MockRunRepository) GetDiscrepancy(ctx context.Context, id string) (*Discrepancy, error) {
+	return m.GetDiscrepancyFunc(ctx, id)
+}
+
+func (m *MockRunRepository) ListDiscrepancies(ctx context.Context, filter DiscrepancyFilter) ([]Discrepancy, error) {
+	return m.ListDiscrepanciesFunc(ctx, filter)
+}
+
+func (m *MockRunRepository) ResolveDiscrepancy(ctx context.Context, id, runID string) error {
+	return m.ResolveDiscrepancyFunc(ctx, id, runID)
+}
+
+type MockPaymentSource struct {
+	ListPaymentsUpdatedFunc func(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]Payment, error)
+	GetPaymentsFunc         func(ctx context.Context, ids []string) (map[string]Payment, error)
+}
+
+func (m *MockPaymentSource) ListPaymentsUpdated(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]Payment, error) {
+	return m.ListPaymentsUpdatedFunc(ctx, from, until, after, limit)
+}
+
+func (m *MockPaymentSource) GetPayments(ctx context.Context, ids []string) (map[string]Payment, error) {
+	return m.GetPaymentsFunc(ctx, ids)
+}
+
+type MockLedgerSource struct {
+	GetTransactionsByReferenceFunc func(ctx context.Context, refs []string) (map[string]LedgerTransaction, error)
+	ListSettlementTransactionsFunc func(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]LedgerTransaction, error)
+}
+
+func (m *MockLedgerSource) GetTransactionsByReference(ctx context.Context, refs []string) (map[string]LedgerTransaction, error) {
+	return m.GetTransactionsByReferenceFunc(ctx, refs)
+}
+
+func (m *MockLedgerSource) ListSettlementTransactions(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]LedgerTransaction, error) {
+	return m.ListSettlementTransactionsFunc(ctx, from, until, after, limit)
+}
+
+type MockEventPublisher struct {
+	PublishDiscrepancyFunc func(ctx context.Context, d Discrepancy) error
+}
+
+func (m *MockEventPublisher) PublishDiscrepancy(ctx context.Context, d Discrepancy) error {
+	return m.PublishDiscrepancyFunc(ctx, d)
+}
@@ -0,0 +1,178 @@
+package domain
+
+import "time"
+
+// Format is a bank statement file format.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatMT940   Format = "mt940"
+	FormatCAMT053 Format = "camt053"
+)
+
+type LineStatus string
+
+const (
+	LineUnmatched  LineStatus = "unmatched"
+	LineMatched    LineStatus = "matched"
+	LineWrittenOff LineStatus = "written_off"
+)
+
+// MatchRule names how a match was made. The first three are automatic rules
+// that can be configured per account.
+type MatchRule string
+
+const (
+	RuleReference  MatchRule = "reference"   // Same reference and amount
+	RuleAmountDate MatchRule = "amount_date" // Same amount within a date window
+	RuleManyToOne  MatchRule = "many_to_one" // Several items on one side sum to one on the other
+	RuleManual     MatchRule = "manual"
+	RuleWriteOff   MatchRule = "write_off"
+)
+
+// Account is the ledger account a bank account is reconciled against,
+// typically the settlement bank account in the chart of accounts.
+type Account struct {
+	ID       string `json:"id"`
+	ZoneID   string `json:"zone_id"`
+	Mode     string `json:"mode"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+// Statement is an imported bank statement for one ledger account.
+type Statement struct {
+	ID             string    `json:"id"`
+	ZoneID         string    `json:"zone_id"`
+	Mode           string    `json:"mode"`
+	AccountID      string    `json:"account_id"`
+	Format         Format    `json:"format"`
+	Reference      string    `json:"reference"`    // Statement ID given by the bank
+	BankAccount    string    `json:"bank_account"` // Account number or IBAN on the statement
+	Currency       string    `json:"currency"`
+	OpeningBalance *int64    `json:"opening_balance,omitempty"`
+	ClosingBalance *int64    `json:"closing_balance,omitempty"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	LineCount      int       `json:"line_count"`
+	Checksum       string    `json:"checksum"` // SHA-256 of the file; re-imports are ignored
+	ImportedAt     time.Time `json:"imported_at"`
+	Lines          []Line    `json:"lines,omitempty"`
+}
+
+// Line is a single booking on a bank statement. Amounts are signed from the
+// bank's side: credits (money in) are positive. The ledger books money in as
+// a debit on the asset account mirroring the bank, so a line matches ledger
+// entries whose amounts sum to the line's negation.
+type Line struct {
+	ID            string     `json:"id"`
+	StatementID   string     `json:"statement_id"`
+	ZoneID        string     `json:"zone_id"`
+	Mode          string     `json:"mode"`
+	AccountID     string     `json:"account_id"`
+	LineNo        int        `json:"line_no"`
+	BookingDate   time.Time  `json:"booking_date"`
+	ValueDate     time.Time  `json:"value_date"`
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency"`
+	Reference     string     `json:"reference"`      // End-to-end or customer reference
+	BankReference string     `json:"bank_reference"` // Reference assigned by the bank
+	Description   string     `json:"description"`
+	Counterparty  string     `json:"counterparty,omitempty"`
+	Status        LineStatus `json:"status"`
+	MatchID       *string    `json:"match_id,omitempty"`
+}
+
+// date is the day the line is matched on: the value date when the bank gave
+// one, otherwise the booking date.
+func (l Line) date() time.Time {
+	if l.ValueDate.IsZero() {
+		return l.BookingDate
+	}
+	return l.ValueDate
+}
+
+// LedgerEntry is an entry on the reconciled ledger account. Amounts use the
+// ledger's sign: debits are negative.
+type LedgerEntry struct {
+	EntryID       string    `json:"entry_id"`
+	AccountID     string    `json:"account_id"`
+	TransactionID string    `json:"transaction_id"`
+	ReferenceID   string    `json:"reference_id"`
+	Description   string    `json:"description"`
+	Amount        int64     `json:"amount"`
+	EffectiveAt   time.Time `json:"effective_at"`
+	MatchID       *string   `json:"match_id,omitempty"`
+}
+
+// Match pairs bank lines with ledger entries. Write-offs may have lines or
+// entries only.
+type Match struct {
+	ID         string    `json:"id"`
+	ZoneID     string    `json:"zone_id"`
+	Mode       string    `json:"mode"`
+	AccountID  string    `json:"account_id"`
+	Rule       MatchRule `json:"rule"`
+	LineIDs    []string  `json:"line_ids"`
+	EntryIDs   []string  `json:"entry_ids"`
+	Note       string    `json:"note,omitempty"`
+	CreatedBy  string    `json:"created_by,omitempty"` // Empty for automatic matches
+	CreatedAt  time.Time `json:"created_at"`
+	Adjustment string    `json:"adjustment_reference,omitempty"` // Ledger posting made by a write-off
+}
+
+// Rule configures one automatic matching pass. Rules run in order and each
+// only sees the items earlier rules left unmatched.
+type Rule struct {
+	Type           MatchRule `json:"type"`
+	DateWindowDays int       `json:"date_window_days,omitempty"` // amount_date and many_to_one
+	MaxGroupSize   int       `json:"max_group_size,omitempty"`   // many_to_one
+}
+
+// DefaultRules apply to accounts without configured rules.
+var DefaultRules = []Rule{
+	{Type: RuleReference},
+	{Type: RuleAmountDate, DateWindowDays: 3},
+	{Type: RuleManyToOne, DateWindowDays: 3, MaxGroupSize: 10},
+}
+
+// ReconcileResult summarises one matching run over an account.
+type ReconcileResult struct {
+	AccountID        string  `json:"account_id"`
+	Matches          []Match `json:"matches"`
+	MatchedLines     int     `json:"matched_lines"`
+	MatchedEntries   int     `json:"matched_entries"`
+	UnmatchedLines   int     `json:"unmatched_lines"`
+	UnmatchedEntries int     `json:"unmatched_entries"`
+}
+
+// ReviewQueue lists what is left for treasury to resolve by hand: bank lines
+// with no ledger counterpart and ledger entries, within the imported
+// statement periods, that the bank has not reported.
+type ReviewQueue struct {
+	AccountID string        `json:"account_id"`
+	Lines     []Line        `json:"lines"`
+	Entries   []LedgerEntry `json:"entries"`
+}
+
+type ManualMatchRequest struct {
+	AccountID string   `json:"account_id"`
+	LineIDs   []string `json:"line_ids"`
+	EntryIDs  []string `json:"entry_ids"`
+	Note      string   `json:"note"`
+}
+
+// WriteOffRequest resolves an unmatched item without a counterpart. When
+// OffsetAccountID is set on a line write-off, the line is posted to the
+// ledger against that account so the ledger agrees with the bank.
+type WriteOffRequest struct {
+	Reason          string `json:"reason"`
+	OffsetAccountID string `json:"offset_account_id,omitempty"`
+}
+
+type ImportResult struct {
+	Statement      *Statement       `json:"statement"`
+	Duplicate      bool             `json:"duplicate"` // The file was imported before
+	Reconciliation *ReconcileResult `json:"reconciliation,omitempty"`
+}
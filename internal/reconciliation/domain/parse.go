@@ -0,0 +1,557 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/currency"
+)
+
+// ParseStatement reads a bank statement file. currency is used for files
+// that do not state their own, such as most CSV exports. The returned
+// statement has no IDs and its period covers the first to the last line when
+// the file does not give one.
+func ParseStatement(format Format, data []byte, ccy string) (*Statement, error) {
+	var (
+		st  *Statement
+		err error
+	)
+	switch format {
+	case FormatCSV:
+		st, err = parseCSV(data, ccy)
+	case FormatMT940:
+		st, err = parseMT940(data)
+	case FormatCAMT053:
+		st, err = parseCAMT053(data)
+	default:
+		return nil, invalidf("unsupported statement format %q: use csv, mt940 or camt053", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	st.Format = format
+	for i := range st.Lines {
+		l := &st.Lines[i]
+		l.LineNo = i + 1
+		l.Status = LineUnmatched
+		if l.Currency == "" {
+			l.Currency = st.Currency
+		}
+		if st.From.IsZero() || l.BookingDate.Before(st.From) {
+			st.From = l.BookingDate
+		}
+		if l.BookingDate.After(st.To) {
+			st.To = l.BookingDate
+		}
+	}
+	st.LineCount = len(st.Lines)
+	return st, nil
+}
+
+// parseDate accepts the date layouts banks commonly use in CSV and XML
+// exports. Ambiguous day/month orders such as 01/02/2026 are not accepted.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04:05", "02.01.2006", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// csvColumns maps the header names we accept to the field they fill.
+var csvColumns = map[string]string{
+	"record_type":      "record_type",
+	"date":             "booking_date",
+	"booking_date":     "booking_date",
+	"booking date":     "booking_date",
+	"transaction_date": "booking_date",
+	"value_date":       "value_date",
+	"value date":       "value_date",
+	"amount":           "amount",
+	"credit_debit":     "credit_debit",
+	"direction":        "credit_debit",
+	"currency":         "currency",
+	"reference":        "reference",
+	"reference_id":     "reference",
+	"end_to_end_id":    "reference",
+	"bank_reference":   "bank_reference",
+	"entry_id":         "bank_reference",
+	"description":      "description",
+	"details":          "description",
+	"counterparty":     "counterparty",
+	"balance":          "balance",
+}
+
+// parseCSV reads a CSV with a header row. Amounts are signed, or unsigned
+// with a credit_debit column. Files in the ledger's own statement export
+// layout are accepted too; their opening and closing rows become the
+// statement balances.
+func parseCSV(data []byte, ccy string) (*Statement, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, invalidf("invalid CSV statement: missing header row")
+	}
+	cols := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := csvColumns[name]; ok {
+			if _, dup := cols[field]; !dup {
+				cols[field] = i
+			}
+		}
+	}
+	for _, required := range []string{"booking_date", "amount"} {
+		if _, ok := cols[required]; !ok {
+			return nil, invalidf("invalid CSV statement: no %s column", required)
+		}
+	}
+
+	st := &Statement{Currency: strings.ToUpper(ccy)}
+	for row := 2; ; row++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalidf("invalid CSV statement: row %d: %v", row, err)
+		}
+		get := func(field string) string {
+			if i, ok := cols[field]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+
+		lineCcy := strings.ToUpper(get("currency"))
+		if lineCcy == "" {
+			lineCcy = st.Currency
+		}
+		if st.Currency == "" {
+			st.Currency = lineCcy
+		}
+
+		switch get("record_type") {
+		case "opening", "closing":
+			balance, err := currency.ParseMinor(get("balance"), lineCcy)
+			if err != nil {
+				return nil, invalidf("invalid CSV statement: row %d: %v", row, err)
+			}
+			if get("record_type") == "opening" {
+				st.OpeningBalance = &balance
+			} else {
+				st.ClosingBalance = &balance
+			}
+			continue
+		case "", "entry":
+		default:
+			continue
+		}
+
+		booked, err := parseDate(get("booking_date"))
+		if err != nil {
+			return nil, invalidf("invalid CSV statement: row %d: %v", row, err)
+		}
+		var valued time.Time
+		if v := get("value_date"); v != "" {
+			if valued, err = parseDate(v); err != nil {
+				return nil, invalidf("invalid CSV statement: row %d: %v", row, err)
+			}
+		}
+		amount, err := currency.ParseMinor(get("amount"), lineCcy)
+		if err != nil {
+			return nil, invalidf("invalid CSV statement: row %d: %v", row, err)
+		}
+		switch strings.ToUpper(get("credit_debit")) {
+		case "D", "DR", "DBIT", "DEBIT":
+			if amount > 0 {
+				amount = -amount
+			}
+		}
+
+		st.Lines = append(st.Lines, Line{
+			BookingDate:   booked,
+			ValueDate:     valued,
+			Amount:        amount,
+			Currency:      lineCcy,
+			Reference:     get("reference"),
+			BankReference: get("bank_reference"),
+			Description:   get("description"),
+			Counterparty:  get("counterparty"),
+		})
+	}
+	if st.Currency == "" {
+		return nil, invalidf("invalid CSV statement: no currency column and no account currency")
+	}
+	return st, nil
+}
+
+// mt940EndToEnd finds an end-to-end reference in :86: information, which
+// banks write either as SEPA "EREF+" or as a "/EREF/" subfield.
+var mt940EndToEnd = regexp.MustCompile(`(?:EREF\+|/EREF/)([^/?\s]+)`)
+
+// parseMT940 reads a SWIFT MT940 customer statement. Only the first
+// statement of a multi-statement file is allowed, since an import belongs to
+// one account.
+func parseMT940(data []byte) (*Statement, error) {
+	type field struct{ tag, value string }
+	var fields []field
+	for _, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line := strings.TrimRight(raw, "\r ")
+		switch {
+		case line == "", line == "-", line == "-}", strings.HasPrefix(line, "{"):
+			continue
+		case strings.HasPrefix(line, ":"):
+			if end := strings.Index(line[1:], ":"); end > 0 {
+				fields = append(fields, field{tag: line[1 : end+1], value: line[end+2:]})
+				continue
+			}
+		}
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+
+	st := &Statement{}
+	var current *Line
+	statements := 0
+	for _, f := range fields {
+		switch f.tag {
+		case "20":
+			if statements++; statements > 1 {
+				return nil, invalidf("invalid MT940 statement: file holds more than one statement; import one account at a time")
+			}
+			st.Reference = strings.TrimSpace(f.value)
+		case "25":
+			st.BankAccount = strings.TrimSpace(f.value)
+		case "60F", "60M", "62F", "62M":
+			balance, date, ccy, err := parseMT940Balance(f.value)
+			if err != nil {
+				return nil, invalidf("invalid MT940 statement: :%s: %v", f.tag, err)
+			}
+			st.Currency = ccy
+			if f.tag[:2] == "60" {
+				st.OpeningBalance = &balance
+				st.From = date
+			} else {
+				st.ClosingBalance = &balance
+				st.To = date
+			}
+		case "61":
+			if st.Currency == "" {
+				return nil, invalidf("invalid MT940 statement: :61: before opening balance")
+			}
+			l, err := parseMT940Line(f.value, st.Currency)
+			if err != nil {
+				return nil, invalidf("invalid MT940 statement: :61: %v", err)
+			}
+			st.Lines = append(st.Lines, l)
+			current = &st.Lines[len(st.Lines)-1]
+		case "86":
+			if current == nil {
+				continue
+			}
+			info := strings.Join(strings.Fields(strings.ReplaceAll(f.value, "\n", "")), " ")
+			current.Description = info
+			if current.Reference == "" {
+				if m := mt940EndToEnd.FindStringSubmatch(strings.ReplaceAll(f.value, "\n", "")); m != nil {
+					current.Reference = m[1]
+				}
+			}
+			current = nil
+		}
+	}
+	if statements == 0 {
+		return nil, invalidf("invalid MT940 statement: no :20: transaction reference")
+	}
+	return st, nil
+}
+
+// parseMT940Balance reads "C260301EUR1234,56".
+func parseMT940Balance(s string) (int64, time.Time, string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 11 {
+		return 0, time.Time{}, "", fmt.Errorf("malformed balance %q", s)
+	}
+	date, err := time.Parse("060102", s[1:7])
+	if err != nil {
+		return 0, time.Time{}, "", fmt.Errorf("malformed balance date %q", s[1:7])
+	}
+	ccy := s[7:10]
+	amount, err := currency.ParseMinor(s[10:], ccy)
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+	switch s[0] {
+	case 'D':
+		amount = -amount
+	case 'C':
+	default:
+		return 0, time.Time{}, "", fmt.Errorf("malformed balance mark %q", s[0:1])
+	}
+	return amount, date, ccy, nil
+}
+
+// parseMT940Line reads a :61: statement line:
+//
+//	value date YYMMDD, optional entry date MMDD, mark (C, D, RC, RD),
+//	optional funds code, amount, transaction type (4 chars),
+//	customer reference, optional //bank reference, optional details line.
+func parseMT940Line(s, ccy string) (Line, error) {
+	first, details, _ := strings.Cut(s, "\n")
+	if len(first) < 6 {
+		return Line{}, fmt.Errorf("malformed line %q", first)
+	}
+	valued, err := time.Parse("060102", first[:6])
+	if err != nil {
+		return Line{}, fmt.Errorf("malformed value date %q", first[:6])
+	}
+	rest := first[6:]
+	booked := valued
+	if len(rest) >= 4 && isDigits(rest[:4]) {
+		entry, err := time.Parse("0102", rest[:4])
+		if err != nil {
+			return Line{}, fmt.Errorf("malformed entry date %q", rest[:4])
+		}
+		booked = time.Date(valued.Year(), entry.Month(), entry.Day(), 0, 0, 0, 0, time.UTC)
+		// An entry date in December for a January value date belongs to the
+		// previous year, and the other way round.
+		if booked.Sub(valued) > 180*24*time.Hour {
+			booked = booked.AddDate(-1, 0, 0)
+		} else if valued.Sub(booked) > 180*24*time.Hour {
+			booked = booked.AddDate(1, 0, 0)
+		}
+		rest = rest[4:]
+	}
+
+	var sign int64
+	switch {
+	case strings.HasPrefix(rest, "RC"):
+		sign, rest = -1, rest[2:]
+	case strings.HasPrefix(rest, "RD"):
+		sign, rest = 1, rest[2:]
+	case strings.HasPrefix(rest, "C"):
+		sign, rest = 1, rest[1:]
+	case strings.HasPrefix(rest, "D"):
+		sign, rest = -1, rest[1:]
+	default:
+		return Line{}, fmt.Errorf("malformed debit/credit mark in %q", first)
+	}
+	if rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+		rest = rest[1:] // Funds code
+	}
+	n := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != ',' })
+	if n <= 0 {
+		return Line{}, fmt.Errorf("malformed amount in %q", first)
+	}
+	amount, err := currency.ParseMinor(rest[:n], ccy)
+	if err != nil {
+		return Line{}, err
+	}
+	rest = rest[n:]
+	if len(rest) < 4 {
+		return Line{}, fmt.Errorf("missing transaction type in %q", first)
+	}
+	rest = rest[4:]
+	ref, bankRef, _ := strings.Cut(rest, "//")
+	if ref == "NONREF" {
+		ref = ""
+	}
+
+	return Line{
+		BookingDate:   booked,
+		ValueDate:     valued,
+		Amount:        sign * amount,
+		Currency:      ccy,
+		Reference:     strings.TrimSpace(ref),
+		BankReference: strings.TrimSpace(bankRef),
+		Description:   strings.TrimSpace(details),
+	}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// camtDocument holds the parts of a camt.053 document we read. Tags carry no
+// namespace so any camt.053 version is accepted.
+type camtDocument struct {
+	Stmts []struct {
+		ID   string `xml:"Id"`
+		IBAN string `xml:"Acct>Id>IBAN"`
+		Othr string `xml:"Acct>Id>Othr>Id"`
+		Ccy  string `xml:"Acct>Ccy"`
+		Bal  []struct {
+			Code string     `xml:"Tp>CdOrPrtry>Cd"`
+			Amt  camtAmount `xml:"Amt"`
+			Ind  string     `xml:"CdtDbtInd"`
+			Date camtDate   `xml:"Dt"`
+		} `xml:"Bal"`
+		Ntry []struct {
+			NtryRef     string     `xml:"NtryRef"`
+			Amt         camtAmount `xml:"Amt"`
+			Ind         string     `xml:"CdtDbtInd"`
+			Reversal    bool       `xml:"RvslInd"`
+			BookgDt     camtDate   `xml:"BookgDt"`
+			ValDt       camtDate   `xml:"ValDt"`
+			AcctSvcrRef string     `xml:"AcctSvcrRef"`
+			AddtlInf    string     `xml:"AddtlNtryInf"`
+			TxDtls      []struct {
+				EndToEndID  string   `xml:"Refs>EndToEndId"`
+				AcctSvcrRef string   `xml:"Refs>AcctSvcrRef"`
+				Ustrd       []string `xml:"RmtInf>Ustrd"`
+				Debtor      string   `xml:"RltdPties>Dbtr>Nm"`
+				Creditor    string   `xml:"RltdPties>Cdtr>Nm"`
+			} `xml:"NtryDtls>TxDtls"`
+		} `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camtDate struct {
+	Dt   string `xml:"Dt"`
+	DtTm string `xml:"DtTm"`
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	switch {
+	case d.Dt != "":
+		return parseDate(d.Dt)
+	case d.DtTm != "":
+		return parseDate(d.DtTm)
+	}
+	return time.Time{}, nil
+}
+
+// parseCAMT053 reads an ISO 20022 BankToCustomerStatement holding a single
+// account statement. Batched entries become one line for the booked total.
+func parseCAMT053(data []byte) (*Statement, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, invalidf("invalid camt.053 statement: %v", err)
+	}
+	if len(doc.Stmts) != 1 {
+		return nil, invalidf("invalid camt.053 statement: file holds %d statements; import one account at a time", len(doc.Stmts))
+	}
+	s := doc.Stmts[0]
+	st := &Statement{Reference: s.ID, BankAccount: s.IBAN, Currency: s.Ccy}
+	if st.BankAccount == "" {
+		st.BankAccount = s.Othr
+	}
+
+	for _, b := range s.Bal {
+		if st.Currency == "" {
+			st.Currency = b.Amt.Ccy
+		}
+		amount, err := camtSigned(b.Amt, b.Ind, false)
+		if err != nil {
+			return nil, invalidf("invalid camt.053 statement: balance %s: %v", b.Code, err)
+		}
+		date, err := b.Date.parse()
+		if err != nil {
+			return nil, invalidf("invalid camt.053 statement: balance %s: %v", b.Code, err)
+		}
+		switch b.Code {
+		case "OPBD", "PRCD":
+			st.OpeningBalance = &amount
+			st.From = date
+		case "CLBD":
+			st.ClosingBalance = &amount
+			st.To = date
+		}
+	}
+
+	for i, n := range s.Ntry {
+		amount, err := camtSigned(n.Amt, n.Ind, n.Reversal)
+		if err != nil {
+			return nil, invalidf("invalid camt.053 statement: entry %d: %v", i+1, err)
+		}
+		booked, err := n.BookgDt.parse()
+		if err != nil {
+			return nil, invalidf("invalid camt.053 statement: entry %d: %v", i+1, err)
+		}
+		valued, err := n.ValDt.parse()
+		if err != nil {
+			return nil, invalidf("invalid camt.053 statement: entry %d: %v", i+1, err)
+		}
+		if booked.IsZero() {
+			booked = valued
+		}
+		if booked.IsZero() {
+			return nil, invalidf("invalid camt.053 statement: entry %d has no booking date", i+1)
+		}
+
+		l := Line{
+			BookingDate:   booked,
+			ValueDate:     valued,
+			Amount:        amount,
+			Currency:      n.Amt.Ccy,
+			BankReference: n.AcctSvcrRef,
+			Description:   n.AddtlInf,
+		}
+		if l.BankReference == "" {
+			l.BankReference = n.NtryRef
+		}
+		if len(n.TxDtls) == 1 {
+			tx := n.TxDtls[0]
+			if tx.EndToEndID != "NOTPROVIDED" {
+				l.Reference = tx.EndToEndID
+			}
+			if l.BankReference == "" {
+				l.BankReference = tx.AcctSvcrRef
+			}
+			if ustrd := strings.Join(tx.Ustrd, " "); ustrd != "" {
+				l.Description = ustrd
+			}
+			// The other party is the debtor of money in and the creditor of
+			// money out.
+			l.Counterparty = tx.Debtor
+			if amount < 0 {
+				l.Counterparty = tx.Creditor
+			}
+		}
+		st.Lines = append(st.Lines, l)
+	}
+	return st, nil
+}
+
+// camtSigned turns an unsigned camt amount and its CdtDbtInd into a bank-side
+// signed amount. A reversal flips the sign of the indicator.
+func camtSigned(a camtAmount, ind string, reversal bool) (int64, error) {
+	amount, err := currency.ParseMinor(a.Value, a.Ccy)
+	if err != nil {
+		return 0, err
+	}
+	switch ind {
+	case "CRDT":
+	case "DBIT":
+		amount = -amount
+	default:
+		return 0, fmt.Errorf("unknown credit/debit indicator %q", ind)
+	}
+	if reversal {
+		amount = -amount
+	}
+	return amount, nil
+}
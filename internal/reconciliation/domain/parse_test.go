@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+const testMT940 = `{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:
+:20:STMT20260302
+:25:DE89370400440532013000
+:28C:61/1
+:60F:C260301EUR1000,00
+:61:2603020302C250,00NTRFpi_123//BNK001
+:86:Card settlement
+ batch 42
+:61:2603020303D12,50NCHGNONREF//BNK002
+:86:166?00FEES?20EREF+fee_march
+:62F:C260303EUR1237,50
+-}`
+
+const testCAMT053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+ <BkToCstmrStmt>
+  <Stmt>
+   <Id>STMT-1</Id>
+   <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+   <Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2026-03-01</Dt></Dt></Bal>
+   <Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1237.50</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2026-03-03</Dt></Dt></Bal>
+   <Ntry>
+    <Amt Ccy="EUR">250.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+    <BookgDt><Dt>2026-03-02</Dt></BookgDt><ValDt><Dt>2026-03-02</Dt></ValDt>
+    <AcctSvcrRef>BNK001</AcctSvcrRef>
+    <NtryDtls><TxDtls>
+     <Refs><EndToEndId>pi_123</EndToEndId></Refs>
+     <RltdPties><Dbtr><Nm>Acme Processing</Nm></Dbtr></RltdPties>
+     <RmtInf><Ustrd>Card settlement</Ustrd></RmtInf>
+    </TxDtls></NtryDtls>
+   </Ntry>
+   <Ntry>
+    <Amt Ccy="EUR">12.50</Amt><CdtDbtInd>DBIT</CdtDbtInd>
+    <BookgDt><DtTm>2026-03-03T09:30:00Z</DtTm></BookgDt>
+    <AcctSvcrRef>BNK002</AcctSvcrRef>
+    <NtryDtls><TxDtls><Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs></TxDtls></NtryDtls>
+   </Ntry>
+  </Stmt>
+ </BkToCstmrStmt>
+</Document>`
+
+func TestParseStatement(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		format    Format
+		data      string
+		currency  string
+		reference string
+		opening   int64
+		closing   int64
+		lines     []Line
+	}{
+		{
+			name:   "CSV with signed amounts",
+			format: FormatCSV,
+			data: "Date,Value Date,Amount,Reference,Description\n" +
+				"2026-03-02,2026-03-02,250.00,pi_123,Card settlement\n" +
+				"03.03.2026,,\"-12,50\",,Bank fee\n",
+			currency: "EUR",
+			lines: []Line{
+				{BookingDate: day(2), ValueDate: day(2), Amount: 25000, Reference: "pi_123", Description: "Card settlement"},
+				{BookingDate: day(3), Amount: -1250, Description: "Bank fee"},
+			},
+		},
+		{
+			name:   "CSV ledger export layout",
+			format: FormatCSV,
+			data: "record_type,account_id,currency,booking_date,value_date,entry_id,reference_id,description,credit_debit,amount,balance\n" +
+				"opening,acc_1,EUR,2026-03-01T00:00:00Z,2026-03-01T00:00:00Z,,,,CRDT,,1000.00\n" +
+				"entry,acc_1,EUR,2026-03-02T10:00:00Z,2026-03-02T10:00:00Z,e-1,pi_123,Card settlement,CRDT,250.00,1250.00\n" +
+				"entry,acc_1,EUR,2026-03-03T00:00:00Z,2026-03-03T00:00:00Z,e-2,,Bank fee,DBIT,12.50,1237.50\n" +
+				"closing,acc_1,EUR,2026-03-04T00:00:00Z,2026-03-04T00:00:00Z,,,,CRDT,,1237.50\n",
+			opening: 100000,
+			closing: 123750,
+			lines: []Line{
+				{BookingDate: day(2).Add(10 * time.Hour), ValueDate: day(2).Add(10 * time.Hour), Amount: 25000, Reference: "pi_123", BankReference: "e-1", Description: "Card settlement"},
+				{BookingDate: day(3), ValueDate: day(3), Amount: -1250, BankReference: "e-2", Description: "Bank fee"},
+			},
+		},
+		{
+			name:      "MT940",
+			format:    FormatMT940,
+			data:      testMT940,
+			reference: "STMT20260302",
+			opening:   100000,
+			closing:   123750,
+			lines: []Line{
+				{BookingDate: day(2), ValueDate: day(2), Amount: 25000, Reference: "pi_123", BankReference: "BNK001", Description: "Card settlement batch 42"},
+				{BookingDate: day(3), ValueDate: day(2), Amount: -1250, Reference: "fee_march", BankReference: "BNK002", Description: "166?00FEES?20EREF+fee_march"},
+			},
+		},
+		{
+			name:      "camt.053",
+			format:    FormatCAMT053,
+			data:      testCAMT053,
+			reference: "STMT-1",
+			opening:   100000,
+			closing:   123750,
+			lines: []Line{
+				{BookingDate: day(2), ValueDate: day(2), Amount: 25000, Reference: "pi_123", BankReference: "BNK001", Description: "Card settlement", Counterparty: "Acme Processing"},
+				{BookingDate: day(3).Add(9*time.Hour + 30*time.Minute), Amount: -1250, BankReference: "BNK002"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ParseStatement(tt.format, []byte(tt.data), tt.currency)
+			if err != nil {
+				t.Fatalf("ParseStatement: %v", err)
+			}
+			if st.Currency != "EUR" {
+				t.Errorf("expected currency EUR, got %q", st.Currency)
+			}
+			if st.Reference != tt.reference {
+				t.Errorf("expected reference %q, got %q", tt.reference, st.Reference)
+			}
+			if tt.opening != 0 && (st.OpeningBalance == nil || *st.OpeningBalance != tt.opening) {
+				t.Errorf("expected opening balance %d, got %v", tt.opening, st.OpeningBalance)
+			}
+			if tt.closing != 0 && (st.ClosingBalance == nil || *st.ClosingBalance != tt.closing) {
+				t.Errorf("expected closing balance %d, got %v", tt.closing, st.ClosingBalance)
+			}
+			if len(st.Lines) != len(tt.lines) {
+				t.Fatalf("expected %d lines, got %d", len(tt.lines), len(st.Lines))
+			}
+			for i, want := range tt.lines {
+				got := st.Lines[i]
+				if !got.BookingDate.Equal(want.BookingDate) || !got.ValueDate.Equal(want.ValueDate) ||
+					got.Amount != want.Amount || got.Reference != want.Reference || got.BankReference != want.BankReference ||
+					got.Description != want.Description || got.Counterparty != want.Counterparty {
+					t.Errorf("line %d:\n got %+v\nwant %+v", i+1, got, want)
+				}
+				if got.LineNo != i+1 || got.Status != LineUnmatched || got.Currency != "EUR" {
+					t.Errorf("line %d: unexpected line_no/status/currency %d/%s/%s", i+1, got.LineNo, got.Status, got.Currency)
+				}
+			}
+		})
+	}
+}
+
+func TestParseStatement_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+	}{
+		{"unknown format", "bai2", "x"},
+		{"CSV without amount column", FormatCSV, "date,reference\n2026-03-02,pi_1\n"},
+		{"CSV with ambiguous date", FormatCSV, "date,amount\n03/02/2026,1.00\n"},
+		{"CSV with too many decimals", FormatCSV, "date,amount\n2026-03-02,1.005\n"},
+		{"MT940 line before balance", FormatMT940, ":20:X\n:61:2603020302C250,00NTRFpi_1\n"},
+		{"MT940 two statements", FormatMT940, ":20:A\n:60F:C260301EUR0,00\n:20:B\n"},
+		{"camt.053 not XML", FormatCAMT053, "not xml"},
+		{"camt.053 bad indicator", FormatCAMT053, `<Document><BkToCstmrStmt><Stmt><Ntry><Amt Ccy="EUR">1.00</Amt><CdtDbtInd>X</CdtDbtInd></Ntry></Stmt></BkToCstmrStmt></Document>`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseStatement(tt.format, []byte(tt.data), "EUR"); !IsValidationError(err) {
+			t.Errorf("%s: expected a validation error, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Repository stores statements and matches. Reconciliation tables live in
+// the ledger database, so ledger accounts and entries are read directly.
+// Lookups return nil and no error when nothing is found.
+type Repository interface {
+	GetAccount(ctx context.Context, id string) (*Account, error)
+	CreateStatement(ctx context.Context, st *Statement) error
+	GetStatement(ctx context.Context, id string) (*Statement, error)
+	GetStatementByChecksum(ctx context.Context, accountID, checksum string) (*Statement, error)
+	ListStatements(ctx context.Context, accountID string, limit int) ([]Statement, error)
+	// GetCoveredPeriod returns the earliest and latest days covered by the
+	// account's statements, or zero times when none were imported.
+	GetCoveredPeriod(ctx context.Context, accountID string) (from, to time.Time, err error)
+	GetLine(ctx context.Context, id string) (*Line, error)
+	ListUnmatchedLines(ctx context.Context, accountID string) ([]Line, error)
+	GetEntry(ctx context.Context, entryID string) (*LedgerEntry, error)
+	GetEntryByReference(ctx context.Context, accountID, referenceID string) (*LedgerEntry, error)
+	ListUnmatchedEntries(ctx context.Context, accountID string, from, to time.Time) ([]LedgerEntry, error)
+	// CreateMatch stores the match and marks its lines and entries, failing
+	// with ErrAlreadyMatched if any of them was matched meanwhile.
+	CreateMatch(ctx context.Context, m *Match) error
+	// GetRules returns nil when the account has no rules configured.
+	GetRules(ctx context.Context, accountID string) ([]Rule, error)
+	SaveRules(ctx context.Context, accountID string, rules []Rule) error
+}
+
+// Adjustment is a ledger posting made to bring the ledger in line with the
+// bank, such as booking an unexpected bank fee.
+type Adjustment struct {
+	ZoneID          string
+	Mode            string
+	AccountID       string
+	OffsetAccountID string
+	Amount          int64 // On AccountID, in ledger sign; the offset gets the opposite
+	Currency        string
+	ReferenceID     string
+	Description     string
+	EffectiveAt     time.Time
+}
+
+// LedgerPoster records adjustments in the ledger.
+type LedgerPoster interface {
+	RecordAdjustment(ctx context.Context, adj Adjustment) error
+}
+
+// RunRepository stores audit runs, their discrepancies and the watermarks.
+type RunRepository interface {
+	GetWatermarks(ctx context.Context) (Watermarks, error)
+	// CreateRun fails with ErrRunInProgress while another run is running.
+	CreateRun(ctx context.Context, run *Run) error
+	// CompleteRun stores the run's results and moves the watermarks to
+	// run.Until in the same transaction.
+	CompleteRun(ctx context.Context, run *Run) error
+	// FailRun stores the run as failed and leaves the watermarks alone.
+	FailRun(ctx context.Context, run *Run) error
+	// FailStaleRuns fails runs still marked running that started before the
+	// given time, left behind by a reconciler that stopped mid-run.
+	FailStaleRuns(ctx context.Context, before time.Time) (int, error)
+	GetRun(ctx context.Context, id string) (*Run, error)
+	ListRuns(ctx context.Context, limit int) ([]Run, error)
+	// CreateDiscrepancy stores d unless an open discrepancy with the same
+	// reference and category exists, and reports whether it was stored.
+	CreateDiscrepancy(ctx context.Context, d *Discrepancy) (bool, error)
+	GetDiscrepancy(ctx context.Context, id string) (*Discrepancy, error)
+	ListDiscrepancies(ctx context.Context, filter DiscrepancyFilter) ([]Discrepancy, error)
+	ResolveDiscrepancy(ctx context.Context, id, runID string) error
+}
+
+// PaymentSource reads payment intents from the payments database.
+type PaymentSource interface {
+	// ListPaymentsUpdated pages through payments updated in (from, until],
+	// ordered by update time and ID, starting after the cursor.
+	ListPaymentsUpdated(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]Payment, error)
+	// GetPayments returns the payments that exist among ids, by ID.
+	GetPayments(ctx context.Context, ids []string) (map[string]Payment, error)
+}
+
+// LedgerSource reads ledger transactions for the audit.
+type LedgerSource interface {
+	// GetTransactionsByReference returns the transactions found for the
+	// references, by reference.
+	GetTransactionsByReference(ctx context.Context, refs []string) (map[string]LedgerTransaction, error)
+	// ListSettlementTransactions pages through transactions created in
+	// (from, until] with a leg on a settlement clearing account, ordered by
+	// creation time and ID, starting after the cursor.
+	ListSettlementTransactions(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]LedgerTransaction, error)
+}
+
+// EventPublisher announces new discrepancies so flows can react to them.
+type EventPublisher interface {
+	PublishDiscrepancy(ctx context.Context, d Discrepancy) error
+}
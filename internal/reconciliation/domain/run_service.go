@@ -0,0 +1,320 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// recheckLimit bounds how many open discrepancies a run checks again.
+const recheckLimit = 1000
+
+type RunConfig struct {
+	// Grace keeps the newest changes out of a run. The ledger learns of
+	// payments asynchronously, and rows committed late must not fall behind
+	// the watermark.
+	Grace time.Duration
+	// InitialLookback is how far back the first run starts.
+	InitialLookback time.Duration
+	// StaleAfter is how long a run may stay running before the next run
+	// assumes it died and takes over.
+	StaleAfter time.Duration
+	BatchSize  int
+}
+
+var DefaultRunConfig = RunConfig{
+	Grace:           5 * time.Minute,
+	InitialLookback: 24 * time.Hour,
+	StaleAfter:      time.Hour,
+	BatchSize:       500,
+}
+
+// RunService audits payments against the ledger in incremental runs.
+type RunService struct {
+	runs     RunRepository
+	payments PaymentSource
+	ledger   LedgerSource
+	events   EventPublisher
+	cfg      RunConfig
+	now      func() time.Time
+}
+
+// NewRunService creates the audit service. Zero config fields take their
+// defaults, and events may be nil.
+func NewRunService(runs RunRepository, payments PaymentSource, ledger LedgerSource, events EventPublisher, cfg RunConfig) *RunService {
+	if cfg.Grace <= 0 {
+		cfg.Grace = DefaultRunConfig.Grace
+	}
+	if cfg.InitialLookback <= 0 {
+		cfg.InitialLookback = DefaultRunConfig.InitialLookback
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = DefaultRunConfig.StaleAfter
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultRunConfig.BatchSize
+	}
+	return &RunService{runs: runs, payments: payments, ledger: ledger, events: events, cfg: cfg, now: time.Now}
+}
+
+// Run audits everything that changed since the last completed run. It
+// first checks open discrepancies again and resolves those that no longer
+// apply, then checks updated payments and new ledger payment postings.
+func (s *RunService) Run(ctx context.Context, trigger string) (*Run, error) {
+	now := s.now()
+	if _, err := s.runs.FailStaleRuns(ctx, now.Add(-s.cfg.StaleAfter)); err != nil {
+		return nil, err
+	}
+	wm, err := s.runs.GetWatermarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Postgres keeps microseconds; a finer bound would not survive the
+	// round trip as the next run's start.
+	until := now.Add(-s.cfg.Grace).Truncate(time.Microsecond)
+	if wm.Payments.IsZero() {
+		wm.Payments = until.Add(-s.cfg.InitialLookback)
+	}
+	if wm.Ledger.IsZero() {
+		wm.Ledger = until.Add(-s.cfg.InitialLookback)
+	}
+
+	run := &Run{
+		Trigger:      trigger,
+		Status:       RunRunning,
+		PaymentsFrom: wm.Payments,
+		LedgerFrom:   wm.Ledger,
+		Until:        until,
+		Summary:      map[DiscrepancyCategory]int{},
+		StartedAt:    now,
+	}
+	if err := s.runs.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit(ctx, run); err != nil {
+		run.Status = RunFailed
+		run.Error = err.Error()
+		finished := s.now()
+		run.FinishedAt = &finished
+		if ferr := s.runs.FailRun(ctx, run); ferr != nil {
+			return run, errors.Join(err, ferr)
+		}
+		return run, err
+	}
+
+	run.Status = RunCompleted
+	finished := s.now()
+	run.FinishedAt = &finished
+	if err := s.runs.CompleteRun(ctx, run); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+func (s *RunService) audit(ctx context.Context, run *Run) error {
+	if err := s.recheck(ctx, run); err != nil {
+		return err
+	}
+
+	cursor := Cursor{At: run.PaymentsFrom}
+	for {
+		batch, err := s.payments.ListPaymentsUpdated(ctx, run.PaymentsFrom, run.Until, cursor, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		txs, err := s.transactionsFor(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, p := range batch {
+			for _, d := range checkPayment(p, txs) {
+				if err := s.record(ctx, run, d); err != nil {
+					return err
+				}
+			}
+		}
+		run.PaymentsChecked += len(batch)
+		last := batch[len(batch)-1]
+		cursor = Cursor{At: last.UpdatedAt, ID: last.ID}
+		if len(batch) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	cursor = Cursor{At: run.LedgerFrom}
+	for {
+		batch, err := s.ledger.ListSettlementTransactions(ctx, run.LedgerFrom, run.Until, cursor, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		var ids []string
+		for _, tx := range batch {
+			if id, ok := paymentIDFromReference(tx.ReferenceID); ok {
+				ids = append(ids, id)
+			}
+		}
+		payments, err := s.payments.GetPayments(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, tx := range batch {
+			if d := checkOrphan(tx, payments); d != nil {
+				if err := s.record(ctx, run, *d); err != nil {
+					return err
+				}
+			}
+		}
+		run.TransactionsChecked += len(batch)
+		last := batch[len(batch)-1]
+		cursor = Cursor{At: last.CreatedAt, ID: last.ID}
+		if len(batch) < s.cfg.BatchSize {
+			break
+		}
+	}
+	return nil
+}
+
+// recheck resolves open discrepancies that the current state of the payment
+// and the ledger no longer shows, such as a posting that arrived late.
+func (s *RunService) recheck(ctx context.Context, run *Run) error {
+	open, err := s.runs.ListDiscrepancies(ctx, DiscrepancyFilter{Status: DiscrepancyOpen, Limit: recheckLimit})
+	if err != nil || len(open) == 0 {
+		return err
+	}
+
+	var ids []string
+	seen := map[string]bool{}
+	for _, d := range open {
+		if !seen[d.PaymentID] {
+			seen[d.PaymentID] = true
+			ids = append(ids, d.PaymentID)
+		}
+	}
+	payments, err := s.payments.GetPayments(ctx, ids)
+	if err != nil {
+		return err
+	}
+	found := make([]Payment, 0, len(ids))
+	for _, id := range ids {
+		if p, ok := payments[id]; ok {
+			found = append(found, p)
+		} else {
+			found = append(found, Payment{ID: id})
+		}
+	}
+	txs, err := s.transactionsFor(ctx, found)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range open {
+		if stillApplies(d, payments, txs) {
+			continue
+		}
+		if err := s.runs.ResolveDiscrepancy(ctx, d.ID, run.ID); err != nil {
+			return err
+		}
+		run.ResolvedCount++
+	}
+	return nil
+}
+
+func stillApplies(d Discrepancy, payments map[string]Payment, txs map[string]LedgerTransaction) bool {
+	if d.Category == OrphanLedgerTransaction {
+		_, unknown := unknownPosting(d.ReferenceID, payments)
+		return unknown
+	}
+	p, ok := payments[d.PaymentID]
+	if !ok {
+		return true
+	}
+	for _, found := range checkPayment(p, txs) {
+		if found.Category == d.Category && found.ReferenceID == d.ReferenceID {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionsFor loads the payment and refund postings of the payments.
+func (s *RunService) transactionsFor(ctx context.Context, payments []Payment) (map[string]LedgerTransaction, error) {
+	refs := make([]string, 0, 2*len(payments))
+	for _, p := range payments {
+		refs = append(refs, p.ID, refundReference(p.ID))
+		for _, r := range p.Refunds {
+			if r.Reference != refundReference(p.ID) {
+				refs = append(refs, r.Reference)
+			}
+		}
+	}
+	return s.ledger.GetTransactionsByReference(ctx, refs)
+}
+
+// record stores a discrepancy found by the run and announces it, unless the
+// same discrepancy is already open from an earlier run.
+func (s *RunService) record(ctx context.Context, run *Run, d Discrepancy) error {
+	d.RunID = run.ID
+	d.Status = DiscrepancyOpen
+	created, err := s.runs.CreateDiscrepancy(ctx, &d)
+	if err != nil || !created {
+		return err
+	}
+	run.DiscrepancyCount++
+	run.Summary[d.Category]++
+
+	if s.events != nil {
+		if err := s.events.PublishDiscrepancy(ctx, d); err != nil {
+			log.Printf("Failed to publish discrepancy %s: %v", d.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *RunService) ListRuns(ctx context.Context, limit int) ([]Run, error) {
+	return s.runs.ListRuns(ctx, limit)
+}
+
+func (s *RunService) GetRun(ctx context.Context, id string) (*Run, error) {
+	run, err := s.runs.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	return run, nil
+}
+
+// ListDiscrepancies lists the zone's discrepancies, newest first.
+func (s *RunService) ListDiscrepancies(ctx context.Context, filter DiscrepancyFilter) ([]Discrepancy, error) {
+	if filter.ZoneID == "" {
+		return nil, invalidf("zone is required")
+	}
+	if filter.Category != "" && !discrepancyCategories[filter.Category] {
+		return nil, invalidf("unknown discrepancy category %q", filter.Category)
+	}
+	if filter.Status != "" && filter.Status != DiscrepancyOpen && filter.Status != DiscrepancyResolved {
+		return nil, invalidf("status must be open or resolved")
+	}
+	return s.runs.ListDiscrepancies(ctx, filter)
+}
+
+func (s *RunService) GetDiscrepancy(ctx context.Context, zoneID, mode, id string) (*Discrepancy, error) {
+	d, err := s.runs.GetDiscrepancy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.ZoneID != zoneID || d.Mode != mode {
+		return nil, ErrDiscrepancyNotFound
+	}
+	return d, nil
+}
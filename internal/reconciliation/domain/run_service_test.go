@@ -0,0 +1,281 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// auditFixture wires RunService to in-memory payments, ledger and run
+// storage built on the mocks.
+type auditFixture struct {
+	runs          MockRunRepository
+	payments      MockPaymentSource
+	ledger        MockLedgerSource
+	events        MockEventPublisher
+	paymentRows   map[string]Payment
+	txs           []LedgerTransaction
+	stored        []*Run
+	discrepancies []*Discrepancy
+	watermarks    Watermarks
+	published     []Discrepancy
+	failPayments  error
+}
+
+func newAuditFixture() *auditFixture {
+	f := &auditFixture{paymentRows: map[string]Payment{}}
+
+	f.runs = MockRunRepository{
+		FailStaleRunsFunc: func(ctx context.Context, before time.Time) (int, error) { return 0, nil },
+		GetWatermarksFunc: func(ctx context.Context) (Watermarks, error) { return f.watermarks, nil },
+		CreateRunFunc: func(ctx context.Context, run *Run) error {
+			for _, r := range f.stored {
+				if r.Status == RunRunning {
+					return ErrRunInProgress
+				}
+			}
+			run.ID = fmt.Sprintf("run_%d", len(f.stored)+1)
+			f.stored = append(f.stored, run)
+			return nil
+		},
+		CompleteRunFunc: func(ctx context.Context, run *Run) error {
+			f.watermarks = Watermarks{Payments: run.Until, Ledger: run.Until}
+			return nil
+		},
+		FailRunFunc: func(ctx context.Context, run *Run) error { return nil },
+		CreateDiscrepancyFunc: func(ctx context.Context, d *Discrepancy) (bool, error) {
+			for _, existing := range f.discrepancies {
+				if existing.Status == DiscrepancyOpen && existing.ReferenceID == d.ReferenceID && existing.Category == d.Category {
+					return false, nil
+				}
+			}
+			d.ID = fmt.Sprintf("d_%d", len(f.discrepancies)+1)
+			cp := *d
+			f.discrepancies = append(f.discrepancies, &cp)
+			return true, nil
+		},
+		ListDiscrepanciesFunc: func(ctx context.Context, filter DiscrepancyFilter) ([]Discrepancy, error) {
+			var out []Discrepancy
+			for _, d := range f.discrepancies {
+				if filter.Status == "" || d.Status == filter.Status {
+					out = append(out, *d)
+				}
+			}
+			return out, nil
+		},
+		ResolveDiscrepancyFunc: func(ctx context.Context, id, runID string) error {
+			for _, d := range f.discrepancies {
+				if d.ID == id {
+					d.Status = DiscrepancyResolved
+					d.ResolvedRunID = runID
+				}
+			}
+			return nil
+		},
+	}
+
+	f.payments = MockPaymentSource{
+		ListPaymentsUpdatedFunc: func(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]Payment, error) {
+			if f.failPayments != nil {
+				return nil, f.failPayments
+			}
+			var out []Payment
+			for _, p := range f.paymentRows {
+				if p.UpdatedAt.After(from) && !p.UpdatedAt.After(until) && after.before(p.UpdatedAt, p.ID) {
+					out = append(out, p)
+				}
+			}
+			sort.Slice(out, func(i, j int) bool {
+				return Cursor{At: out[i].UpdatedAt, ID: out[i].ID}.before(out[j].UpdatedAt, out[j].ID)
+			})
+			if len(out) > limit {
+				out = out[:limit]
+			}
+			return out, nil
+		},
+		GetPaymentsFunc: func(ctx context.Context, ids []string) (map[string]Payment, error) {
+			out := map[string]Payment{}
+			for _, id := range ids {
+				if p, ok := f.paymentRows[id]; ok {
+					out[id] = p
+				}
+			}
+			return out, nil
+		},
+	}
+
+	f.ledger = MockLedgerSource{
+		GetTransactionsByReferenceFunc: func(ctx context.Context, refs []string) (map[string]LedgerTransaction, error) {
+			out := map[string]LedgerTransaction{}
+			for _, ref := range refs {
+				for _, tx := range f.txs {
+					if tx.ReferenceID == ref {
+						out[ref] = tx
+					}
+				}
+			}
+			return out, nil
+		},
+		ListSettlementTransactionsFunc: func(ctx context.Context, from, until time.Time, after Cursor, limit int) ([]LedgerTransaction, error) {
+			var out []LedgerTransaction
+			for _, tx := range f.txs {
+				if tx.CreatedAt.After(from) && !tx.CreatedAt.After(until) && after.before(tx.CreatedAt, tx.ID) {
+					out = append(out, tx)
+				}
+			}
+			if len(out) > limit {
+				out = out[:limit]
+			}
+			return out, nil
+		},
+	}
+
+	f.events = MockEventPublisher{
+		PublishDiscrepancyFunc: func(ctx context.Context, d Discrepancy) error {
+			f.published = append(f.published, d)
+			return nil
+		},
+	}
+	return f
+}
+
+func (c Cursor) before(at time.Time, id string) bool {
+	return c.At.Before(at) || (c.At.Equal(at) && c.ID < id)
+}
+
+func (f *auditFixture) service(now *time.Time) *RunService {
+	s := NewRunService(&f.runs, &f.payments, &f.ledger, &f.events, RunConfig{Grace: time.Minute, InitialLookback: time.Hour, BatchSize: 2})
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestRunService_Run(t *testing.T) {
+	ctx := context.Background()
+	f := newAuditFixture()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	service := f.service(&now)
+
+	ids := []string{
+		"10000000-0000-4000-8000-000000000001",
+		"10000000-0000-4000-8000-000000000002",
+		"10000000-0000-4000-8000-000000000003",
+		"10000000-0000-4000-8000-000000000004",
+	}
+	orphan := "10000000-0000-4000-8000-000000000099"
+	at := now.Add(-30 * time.Minute)
+	pay := func(id, status string, amount int64, updated time.Time) {
+		f.paymentRows[id] = Payment{ID: id, ZoneID: "zone_1", Mode: "live", Amount: amount, Currency: "USD", Status: status, UpdatedAt: updated}
+	}
+	post := func(ref string, amount int64, created time.Time) {
+		tx := posting(fmt.Sprintf("tx_%d", len(f.txs)+1), ref, amount, "USD")
+		tx.ZoneID, tx.Mode, tx.CreatedAt = "zone_1", "live", created
+		f.txs = append(f.txs, tx)
+	}
+	pay(ids[0], "succeeded", 1000, at)
+	post(ids[0], 1000, at)
+	pay(ids[1], "succeeded", 2000, at) // Not posted yet
+	pay(ids[2], "succeeded", 3000, at)
+	post(ids[2], 2500, at)
+	pay(ids[3], "failed", 4000, at)
+	post(orphan, 700, at)
+	pay(ids[0]+"-late", "succeeded", 1, now.Add(-30*time.Second)) // Inside the grace period
+	post(ids[0]+"-late", 1, now.Add(-30*time.Second))
+
+	run, err := service.Run(ctx, "schedule")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Status != RunCompleted || run.PaymentsChecked != 4 || run.TransactionsChecked != 3 {
+		t.Errorf("unexpected run: %+v", run)
+	}
+	if !run.PaymentsFrom.Equal(now.Add(-time.Minute-time.Hour)) || !run.Until.Equal(now.Add(-time.Minute)) {
+		t.Errorf("unexpected window %s - %s", run.PaymentsFrom, run.Until)
+	}
+	want := map[DiscrepancyCategory]int{MissingInLedger: 1, AmountMismatch: 1, OrphanLedgerTransaction: 1}
+	if fmt.Sprint(run.Summary) != fmt.Sprint(want) || run.DiscrepancyCount != 3 {
+		t.Errorf("expected summary %v, got %v", want, run.Summary)
+	}
+	if len(f.published) != 3 {
+		t.Errorf("expected 3 events, got %d", len(f.published))
+	}
+	if !f.watermarks.Payments.Equal(run.Until) {
+		t.Errorf("expected the watermark to move to %s, got %s", run.Until, f.watermarks.Payments)
+	}
+
+	// The ledger catches up with the missing posting and the failed payment
+	// gets a posting. The next run covers only what changed since.
+	now = now.Add(10 * time.Minute)
+	post(ids[1], 2000, now.Add(-5*time.Minute))
+	post(ids[3], 4000, now.Add(-5*time.Minute))
+	pay(ids[3], "FAILED", 4000, now.Add(-5*time.Minute))
+
+	run2, err := service.Run(ctx, "manual")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !run2.PaymentsFrom.Equal(run.Until) {
+		t.Errorf("expected the second run to start at the watermark, got %s", run2.PaymentsFrom)
+	}
+	if run2.PaymentsChecked != 2 || run2.ResolvedCount != 1 {
+		t.Errorf("expected the late payment and the failed one checked and one discrepancy resolved, got %+v", run2)
+	}
+	if run2.Summary[StatusMismatch] != 1 || run2.DiscrepancyCount != 1 {
+		t.Errorf("expected only the status mismatch to be new, got %v", run2.Summary)
+	}
+	for _, d := range f.discrepancies {
+		if d.PaymentID == ids[1] && (d.Status != DiscrepancyResolved || d.ResolvedRunID != run2.ID) {
+			t.Errorf("expected the missing posting to be resolved, got %+v", d)
+		}
+	}
+}
+
+func TestRunService_FailedRunKeepsWatermark(t *testing.T) {
+	ctx := context.Background()
+	f := newAuditFixture()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	previous := now.Add(-20 * time.Minute)
+	f.watermarks = Watermarks{Payments: previous, Ledger: previous}
+	f.failPayments = errors.New("connection reset")
+
+	var failed *Run
+	f.runs.FailRunFunc = func(ctx context.Context, run *Run) error {
+		failed = run
+		return nil
+	}
+
+	run, err := f.service(&now).Run(ctx, "schedule")
+	if err == nil || run == nil || run.Status != RunFailed || failed != run || run.Error == "" {
+		t.Fatalf("expected a failed run, got %+v, %v", run, err)
+	}
+	if !f.watermarks.Payments.Equal(previous) {
+		t.Errorf("expected the watermark to stay at %s, got %s", previous, f.watermarks.Payments)
+	}
+}
+
+func TestRunService_ListDiscrepancies(t *testing.T) {
+	f := newAuditFixture()
+	now := time.Now()
+	service := f.service(&now)
+	ctx := context.Background()
+
+	bad := []DiscrepancyFilter{
+		{},
+		{ZoneID: "zone_1", Category: "typo"},
+		{ZoneID: "zone_1", Status: "closed"},
+	}
+	for _, filter := range bad {
+		if _, err := service.ListDiscrepancies(ctx, filter); !IsValidationError(err) {
+			t.Errorf("%+v: expected a validation error, got %v", filter, err)
+		}
+	}
+
+	f.runs.GetDiscrepancyFunc = func(ctx context.Context, id string) (*Discrepancy, error) {
+		return &Discrepancy{ID: id, ZoneID: "zone_1", Mode: "live"}, nil
+	}
+	if _, err := service.GetDiscrepancy(ctx, "zone_2", "live", "d_1"); !errors.Is(err, ErrDiscrepancyNotFound) {
+		t.Errorf("expected discrepancies of other zones to be hidden, got %v", err)
+	}
+}
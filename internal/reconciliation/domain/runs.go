@@ -0,0 +1,150 @@
+package domain
+
+import "time"
+
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunCompleted RunStatus = "completed"
+	RunFailed    RunStatus = "failed"
+)
+
+// A Run audits payments against the ledger. Each run covers the changes
+// since the previous completed run up to Until, so every payment update is
+// checked once; a failed run leaves the watermarks where they were and the
+// next run covers its window again.
+type Run struct {
+	ID                  string                      `json:"id"`
+	Trigger             string                      `json:"trigger"` // schedule or manual
+	Status              RunStatus                   `json:"status"`
+	PaymentsFrom        time.Time                   `json:"payments_from"` // Exclusive; payments updated after this
+	LedgerFrom          time.Time                   `json:"ledger_from"`   // Exclusive; ledger transactions created after this
+	Until               time.Time                   `json:"until"`         // Inclusive upper bound of both windows
+	PaymentsChecked     int                         `json:"payments_checked"`
+	TransactionsChecked int                         `json:"transactions_checked"`
+	DiscrepancyCount    int                         `json:"discrepancy_count"`
+	ResolvedCount       int                         `json:"resolved_count"`
+	Summary             map[DiscrepancyCategory]int `json:"summary"` // New discrepancies by category
+	Error               string                      `json:"error,omitempty"`
+	StartedAt           time.Time                   `json:"started_at"`
+	FinishedAt          *time.Time                  `json:"finished_at,omitempty"`
+}
+
+// Watermarks are the positions up to which payments and ledger transactions
+// have been audited. Zero times mean no run has completed yet.
+type Watermarks struct {
+	Payments time.Time
+	Ledger   time.Time
+}
+
+// Cursor is a keyset position within a run's window.
+type Cursor struct {
+	At time.Time
+	ID string
+}
+
+type DiscrepancyCategory string
+
+const (
+	// A payment that moved money has no ledger transaction.
+	MissingInLedger DiscrepancyCategory = "missing_in_ledger"
+	// The ledger transaction moved a different amount than the payment.
+	AmountMismatch DiscrepancyCategory = "amount_mismatch"
+	// The ledger transaction was booked in a different currency.
+	CurrencyMismatch DiscrepancyCategory = "currency_mismatch"
+	// The ledger transaction's entries do not sum to zero.
+	UnbalancedTransaction DiscrepancyCategory = "unbalanced_transaction"
+	// The ledger disagrees with the payment's status, such as a posting for
+	// a failed payment or a refund for one that was not refunded.
+	StatusMismatch DiscrepancyCategory = "status_mismatch"
+	// A payment posting in the ledger has no payment, or a refund posting
+	// has no refund.
+	OrphanLedgerTransaction DiscrepancyCategory = "orphan_ledger_transaction"
+)
+
+var discrepancyCategories = map[DiscrepancyCategory]bool{
+	MissingInLedger:         true,
+	AmountMismatch:          true,
+	CurrencyMismatch:        true,
+	UnbalancedTransaction:   true,
+	StatusMismatch:          true,
+	OrphanLedgerTransaction: true,
+}
+
+type DiscrepancyStatus string
+
+const (
+	DiscrepancyOpen     DiscrepancyStatus = "open"
+	DiscrepancyResolved DiscrepancyStatus = "resolved"
+)
+
+// Discrepancy is a difference between a payment and the ledger. It stays
+// open until a later run finds the two in agreement. There is at most one
+// open discrepancy per reference and category.
+type Discrepancy struct {
+	ID             string              `json:"id"`
+	RunID          string              `json:"run_id"`
+	ZoneID         string              `json:"zone_id"`
+	Mode           string              `json:"mode"`
+	Category       DiscrepancyCategory `json:"category"`
+	Status         DiscrepancyStatus   `json:"status"`
+	ReferenceID    string              `json:"reference_id"` // Ledger reference the check was about
+	PaymentID      string              `json:"payment_id,omitempty"`
+	PaymentStatus  string              `json:"payment_status,omitempty"`
+	TransactionID  string              `json:"transaction_id,omitempty"`
+	Currency       string              `json:"currency,omitempty"`
+	ExpectedAmount *int64              `json:"expected_amount,omitempty"`
+	ActualAmount   *int64              `json:"actual_amount,omitempty"`
+	Detail         string              `json:"detail"`
+	CreatedAt      time.Time           `json:"created_at"`
+	ResolvedAt     *time.Time          `json:"resolved_at,omitempty"`
+	ResolvedRunID  string              `json:"resolved_run_id,omitempty"`
+}
+
+// DiscrepancyFilter selects discrepancies; empty fields match everything.
+type DiscrepancyFilter struct {
+	ZoneID   string
+	Mode     string
+	RunID    string
+	Category DiscrepancyCategory
+	Status   DiscrepancyStatus
+	Limit    int
+}
+
+// Payment is the part of a payment intent the audit compares with the ledger.
+type Payment struct {
+	ID        string
+	ZoneID    string
+	Mode      string
+	Amount    int64
+	Currency  string
+	Status    string
+	UpdatedAt time.Time
+	// Refunds are the payment's pending and succeeded refunds.
+	Refunds []PaymentRefund
+}
+
+// PaymentRefund is a refund the ledger should have a reversal for, posted
+// under Reference. A pending refund's reversal may still be in flight.
+type PaymentRefund struct {
+	Reference string
+	Amount    int64
+	Pending   bool
+}
+
+// LedgerTransaction is a ledger transaction with its legs.
+type LedgerTransaction struct {
+	ID          string
+	ReferenceID string
+	ZoneID      string
+	Mode        string
+	CreatedAt   time.Time
+	Legs        []LedgerLeg
+}
+
+type LedgerLeg struct {
+	AccountID string
+	Amount    int64 // Positive is a credit
+	Currency  string
+}
@@ -0,0 +1,482 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	maxDateWindowDays = 31
+	maxGroupSize      = 50
+)
+
+type ReconciliationService struct {
+	repo   Repository
+	poster LedgerPoster
+}
+
+// NewReconciliationService creates the service. poster may be nil, in which
+// case write-offs cannot post ledger adjustments.
+func NewReconciliationService(repo Repository, poster LedgerPoster) *ReconciliationService {
+	return &ReconciliationService{repo: repo, poster: poster}
+}
+
+// ImportStatement parses a bank statement for a ledger account, stores it and
+// runs the account's matching rules. Importing the same file twice returns
+// the first import.
+func (s *ReconciliationService) ImportStatement(ctx context.Context, zoneID, mode, accountID string, format Format, data []byte) (*ImportResult, error) {
+	acc, err := s.account(ctx, zoneID, mode, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	existing, err := s.repo.GetStatementByChecksum(ctx, acc.ID, checksum)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return &ImportResult{Statement: existing, Duplicate: true}, nil
+	}
+
+	st, err := ParseStatement(format, data, acc.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(st.Currency, acc.Currency) {
+		return nil, invalidf("statement currency %s does not match account currency %s", st.Currency, acc.Currency)
+	}
+	for _, l := range st.Lines {
+		if !strings.EqualFold(l.Currency, acc.Currency) {
+			return nil, invalidf("statement line %d is in %s, account currency is %s", l.LineNo, l.Currency, acc.Currency)
+		}
+	}
+
+	st.ZoneID, st.Mode, st.AccountID = zoneID, mode, acc.ID
+	st.Currency = acc.Currency
+	st.Checksum = checksum
+	for i := range st.Lines {
+		l := &st.Lines[i]
+		l.ZoneID, l.Mode, l.AccountID = zoneID, mode, acc.ID
+		l.Currency = acc.Currency
+	}
+	if err := s.repo.CreateStatement(ctx, st); err != nil {
+		return nil, err
+	}
+
+	result, err := s.Reconcile(ctx, zoneID, mode, acc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("statement %s imported but matching failed: %w", st.ID, err)
+	}
+	return &ImportResult{Statement: st, Reconciliation: result}, nil
+}
+
+// Reconcile matches the account's unmatched statement lines against its
+// unmatched ledger entries using the configured rules.
+func (s *ReconciliationService) Reconcile(ctx context.Context, zoneID, mode, accountID string) (*ReconcileResult, error) {
+	acc, err := s.account(ctx, zoneID, mode, accountID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.rules(ctx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{AccountID: acc.ID, Matches: []Match{}}
+	lines, err := s.repo.ListUnmatchedLines(ctx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return result, nil
+	}
+
+	// Only entries that could fall within a rule's date window of some line
+	// are candidates.
+	window := 0
+	for _, r := range rules {
+		if r.DateWindowDays > window {
+			window = r.DateWindowDays
+		}
+	}
+	from, to := lines[0].date(), lines[0].date()
+	for _, l := range lines[1:] {
+		if l.date().Before(from) {
+			from = l.date()
+		}
+		if l.date().After(to) {
+			to = l.date()
+		}
+	}
+	from = startOfDay(from).AddDate(0, 0, -window)
+	to = startOfDay(to).AddDate(0, 0, window+1)
+	entries, err := s.repo.ListUnmatchedEntries(ctx, acc.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range matchItems(rules, lines, entries) {
+		m.ZoneID, m.Mode, m.AccountID = zoneID, mode, acc.ID
+		if err := s.repo.CreateMatch(ctx, &m); err != nil {
+			if errors.Is(err, ErrAlreadyMatched) {
+				// Someone resolved one of the items while we were matching.
+				continue
+			}
+			return nil, err
+		}
+		result.Matches = append(result.Matches, m)
+		result.MatchedLines += len(m.LineIDs)
+		result.MatchedEntries += len(m.EntryIDs)
+	}
+	result.UnmatchedLines = len(lines) - result.MatchedLines
+	result.UnmatchedEntries = len(entries) - result.MatchedEntries
+	return result, nil
+}
+
+// ReviewQueue returns the unmatched statement lines of the account and the
+// unmatched ledger entries within the period its statements cover.
+func (s *ReconciliationService) ReviewQueue(ctx context.Context, zoneID, mode, accountID string) (*ReviewQueue, error) {
+	acc, err := s.account(ctx, zoneID, mode, accountID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.ListUnmatchedLines(ctx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	queue := &ReviewQueue{AccountID: acc.ID, Lines: lines, Entries: []LedgerEntry{}}
+	if queue.Lines == nil {
+		queue.Lines = []Line{}
+	}
+
+	from, to, err := s.repo.GetCoveredPeriod(ctx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() {
+		return queue, nil
+	}
+	entries, err := s.repo.ListUnmatchedEntries(ctx, acc.ID, startOfDay(from), startOfDay(to).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	if entries != nil {
+		queue.Entries = entries
+	}
+	return queue, nil
+}
+
+// ManualMatch matches statement lines with ledger entries chosen by a
+// reviewer. Both sides must net to zero.
+func (s *ReconciliationService) ManualMatch(ctx context.Context, zoneID, mode, userID string, req ManualMatchRequest) (*Match, error) {
+	acc, err := s.account(ctx, zoneID, mode, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.LineIDs) == 0 || len(req.EntryIDs) == 0 {
+		return nil, invalidf("a manual match needs at least one statement line and one ledger entry")
+	}
+
+	var lineTotal, entryTotal int64
+	seen := make(map[string]bool)
+	for _, id := range req.LineIDs {
+		if seen[id] {
+			return nil, invalidf("statement line %s is listed twice", id)
+		}
+		seen[id] = true
+		l, err := s.unmatchedLine(ctx, acc, id)
+		if err != nil {
+			return nil, err
+		}
+		lineTotal += l.Amount
+	}
+	for _, id := range req.EntryIDs {
+		if seen[id] {
+			return nil, invalidf("ledger entry %s is listed twice", id)
+		}
+		seen[id] = true
+		e, err := s.unmatchedEntry(ctx, acc, id)
+		if err != nil {
+			return nil, err
+		}
+		entryTotal += e.Amount
+	}
+	if lineTotal+entryTotal != 0 {
+		return nil, invalidf("statement lines total %d but ledger entries total %d; they must offset exactly", lineTotal, entryTotal)
+	}
+
+	m := &Match{
+		ZoneID:    zoneID,
+		Mode:      mode,
+		AccountID: acc.ID,
+		Rule:      RuleManual,
+		LineIDs:   req.LineIDs,
+		EntryIDs:  req.EntryIDs,
+		Note:      req.Note,
+		CreatedBy: userID,
+	}
+	if err := s.repo.CreateMatch(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteOffLine resolves a statement line the ledger has no entry for. With
+// an offset account the line is booked in the ledger first, so balances
+// agree; without one it is only marked as written off.
+func (s *ReconciliationService) WriteOffLine(ctx context.Context, zoneID, mode, userID, lineID string, req WriteOffRequest) (*Match, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, invalidf("a write-off needs a reason")
+	}
+	l, err := s.repo.GetLine(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || l.ZoneID != zoneID || l.Mode != mode {
+		return nil, ErrLineNotFound
+	}
+	acc, err := s.account(ctx, zoneID, mode, l.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if l.Status != LineUnmatched {
+		return nil, ErrAlreadyMatched
+	}
+
+	m := &Match{
+		ZoneID:    zoneID,
+		Mode:      mode,
+		AccountID: acc.ID,
+		Rule:      RuleWriteOff,
+		LineIDs:   []string{l.ID},
+		Note:      req.Reason,
+		CreatedBy: userID,
+	}
+	if req.OffsetAccountID != "" {
+		entry, err := s.postAdjustment(ctx, acc, l, req)
+		if err != nil {
+			return nil, err
+		}
+		m.EntryIDs = []string{entry.EntryID}
+		m.Adjustment = entry.ReferenceID
+	}
+	if err := s.repo.CreateMatch(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// postAdjustment books the line in the ledger against the offset account and
+// returns the entry made on the reconciled account. The posting reference is
+// derived from the line, so a retried write-off reuses an earlier posting.
+func (s *ReconciliationService) postAdjustment(ctx context.Context, acc *Account, l *Line, req WriteOffRequest) (*LedgerEntry, error) {
+	if s.poster == nil {
+		return nil, invalidf("ledger adjustments are not available; write off without an offset account")
+	}
+	offset, err := s.account(ctx, acc.ZoneID, acc.Mode, req.OffsetAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if offset.ID == acc.ID {
+		return nil, invalidf("offset account must differ from the reconciled account")
+	}
+	if offset.Currency != acc.Currency {
+		return nil, invalidf("offset account currency %s does not match %s", offset.Currency, acc.Currency)
+	}
+
+	ref := "recon_writeoff_" + l.ID
+	entry, err := s.repo.GetEntryByReference(ctx, acc.ID, ref)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		return entry, nil
+	}
+	err = s.poster.RecordAdjustment(ctx, Adjustment{
+		ZoneID:          acc.ZoneID,
+		Mode:            acc.Mode,
+		AccountID:       acc.ID,
+		OffsetAccountID: offset.ID,
+		Amount:          -l.Amount,
+		Currency:        acc.Currency,
+		ReferenceID:     ref,
+		Description:     "Bank reconciliation write-off: " + req.Reason,
+		EffectiveAt:     l.BookingDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to post write-off adjustment: %w", err)
+	}
+	entry, err = s.repo.GetEntryByReference(ctx, acc.ID, ref)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("write-off adjustment %s was posted but its entry was not found", ref)
+	}
+	return entry, nil
+}
+
+// WriteOffEntry resolves a ledger entry the bank will never report, such as
+// an internal transfer booked against the wrong account.
+func (s *ReconciliationService) WriteOffEntry(ctx context.Context, zoneID, mode, userID, entryID string, req WriteOffRequest) (*Match, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, invalidf("a write-off needs a reason")
+	}
+	if req.OffsetAccountID != "" {
+		return nil, invalidf("offset_account_id only applies to statement line write-offs")
+	}
+	e, err := s.repo.GetEntry(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, ErrEntryNotFound
+	}
+	acc, err := s.account(ctx, zoneID, mode, e.AccountID)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	if e.MatchID != nil {
+		return nil, ErrAlreadyMatched
+	}
+
+	m := &Match{
+		ZoneID:    zoneID,
+		Mode:      mode,
+		AccountID: acc.ID,
+		Rule:      RuleWriteOff,
+		EntryIDs:  []string{e.EntryID},
+		Note:      req.Reason,
+		CreatedBy: userID,
+	}
+	if err := s.repo.CreateMatch(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetRules returns the account's matching rules, or DefaultRules.
+func (s *ReconciliationService) GetRules(ctx context.Context, zoneID, mode, accountID string) ([]Rule, error) {
+	acc, err := s.account(ctx, zoneID, mode, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return s.rules(ctx, acc.ID)
+}
+
+// SetRules replaces the account's matching rules. An empty list turns
+// automatic matching off.
+func (s *ReconciliationService) SetRules(ctx context.Context, zoneID, mode, accountID string, rules []Rule) error {
+	acc, err := s.account(ctx, zoneID, mode, accountID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[MatchRule]bool)
+	for _, r := range rules {
+		switch r.Type {
+		case RuleReference, RuleAmountDate, RuleManyToOne:
+		default:
+			return invalidf("unknown rule type %q: use reference, amount_date or many_to_one", r.Type)
+		}
+		if seen[r.Type] {
+			return invalidf("rule %s is listed twice", r.Type)
+		}
+		seen[r.Type] = true
+		if r.DateWindowDays < 0 || r.DateWindowDays > maxDateWindowDays {
+			return invalidf("date_window_days must be between 0 and %d", maxDateWindowDays)
+		}
+		if r.MaxGroupSize != 0 && (r.Type != RuleManyToOne || r.MaxGroupSize < 2 || r.MaxGroupSize > maxGroupSize) {
+			return invalidf("max_group_size applies to many_to_one and must be between 2 and %d", maxGroupSize)
+		}
+	}
+	if rules == nil {
+		rules = []Rule{}
+	}
+	return s.repo.SaveRules(ctx, acc.ID, rules)
+}
+
+func (s *ReconciliationService) ListStatements(ctx context.Context, zoneID, mode, accountID string, limit int) ([]Statement, error) {
+	acc, err := s.account(ctx, zoneID, mode, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListStatements(ctx, acc.ID, limit)
+}
+
+func (s *ReconciliationService) GetStatement(ctx context.Context, zoneID, mode, id string) (*Statement, error) {
+	st, err := s.repo.GetStatement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || st.ZoneID != zoneID || st.Mode != mode {
+		return nil, ErrStatementNotFound
+	}
+	return st, nil
+}
+
+func (s *ReconciliationService) account(ctx context.Context, zoneID, mode, id string) (*Account, error) {
+	if id == "" {
+		return nil, invalidf("account_id is required")
+	}
+	acc, err := s.repo.GetAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil || acc.ZoneID != zoneID || acc.Mode != mode {
+		return nil, ErrAccountNotFound
+	}
+	return acc, nil
+}
+
+func (s *ReconciliationService) rules(ctx context.Context, accountID string) ([]Rule, error) {
+	rules, err := s.repo.GetRules(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		return DefaultRules, nil
+	}
+	return rules, nil
+}
+
+func (s *ReconciliationService) unmatchedLine(ctx context.Context, acc *Account, id string) (*Line, error) {
+	l, err := s.repo.GetLine(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || l.AccountID != acc.ID {
+		return nil, invalidf("statement line %s not found on account %s", id, acc.ID)
+	}
+	if l.Status != LineUnmatched {
+		return nil, invalidf("statement line %s is already %s", id, l.Status)
+	}
+	return l, nil
+}
+
+func (s *ReconciliationService) unmatchedEntry(ctx context.Context, acc *Account, id string) (*LedgerEntry, error) {
+	e, err := s.repo.GetEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil || e.AccountID != acc.ID {
+		return nil, invalidf("ledger entry %s not found on account %s", id, acc.ID)
+	}
+	if e.MatchID != nil {
+		return nil, invalidf("ledger entry %s is already matched", id)
+	}
+	return e, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,368 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memRepo is an in-memory Repository built on MockRepository.
+type memRepo struct {
+	MockRepository
+	accounts   map[string]*Account
+	statements []*Statement
+	lines      map[string]*Line
+	entries    map[string]*LedgerEntry
+	matches    []*Match
+	rules      map[string][]Rule
+}
+
+func newMemRepo() *memRepo {
+	r := &memRepo{
+		accounts: map[string]*Account{
+			"acc_bank":  {ID: "acc_bank", ZoneID: "zone_1", Mode: "live", Name: "Operating Bank", Currency: "EUR"},
+			"acc_fees":  {ID: "acc_fees", ZoneID: "zone_1", Mode: "live", Name: "Processing Costs", Currency: "EUR"},
+			"acc_other": {ID: "acc_other", ZoneID: "zone_2", Mode: "live", Name: "Other", Currency: "EUR"},
+		},
+		lines:   map[string]*Line{},
+		entries: map[string]*LedgerEntry{},
+		rules:   map[string][]Rule{},
+	}
+	r.GetAccountFunc = func(ctx context.Context, id string) (*Account, error) {
+		return r.accounts[id], nil
+	}
+	r.CreateStatementFunc = func(ctx context.Context, st *Statement) error {
+		st.ID = fmt.Sprintf("st_%d", len(r.statements)+1)
+		for i := range st.Lines {
+			st.Lines[i].ID = fmt.Sprintf("%s_l%d", st.ID, i+1)
+			st.Lines[i].StatementID = st.ID
+			l := st.Lines[i]
+			r.lines[l.ID] = &l
+		}
+		r.statements = append(r.statements, st)
+		return nil
+	}
+	r.GetStatementByChecksumFunc = func(ctx context.Context, accountID, checksum string) (*Statement, error) {
+		for _, st := range r.statements {
+			if st.AccountID == accountID && st.Checksum == checksum {
+				return st, nil
+			}
+		}
+		return nil, nil
+	}
+	r.GetCoveredPeriodFunc = func(ctx context.Context, accountID string) (time.Time, time.Time, error) {
+		var from, to time.Time
+		for _, st := range r.statements {
+			if from.IsZero() || st.From.Before(from) {
+				from = st.From
+			}
+			if st.To.After(to) {
+				to = st.To
+			}
+		}
+		return from, to, nil
+	}
+	r.GetLineFunc = func(ctx context.Context, id string) (*Line, error) {
+		if l, ok := r.lines[id]; ok {
+			cp := *l
+			return &cp, nil
+		}
+		return nil, nil
+	}
+	r.ListUnmatchedLinesFunc = func(ctx context.Context, accountID string) ([]Line, error) {
+		var out []Line
+		for _, st := range r.statements {
+			for _, sl := range st.Lines {
+				if l := r.lines[sl.ID]; l.AccountID == accountID && l.Status == LineUnmatched {
+					out = append(out, *l)
+				}
+			}
+		}
+		return out, nil
+	}
+	r.GetEntryFunc = func(ctx context.Context, id string) (*LedgerEntry, error) {
+		if e, ok := r.entries[id]; ok {
+			cp := *e
+			return &cp, nil
+		}
+		return nil, nil
+	}
+	r.GetEntryByReferenceFunc = func(ctx context.Context, accountID, ref string) (*LedgerEntry, error) {
+		for _, e := range r.entries {
+			if e.AccountID == accountID && e.ReferenceID == ref {
+				cp := *e
+				return &cp, nil
+			}
+		}
+		return nil, nil
+	}
+	r.ListUnmatchedEntriesFunc = func(ctx context.Context, accountID string, from, to time.Time) ([]LedgerEntry, error) {
+		var out []LedgerEntry
+		for _, e := range r.entries {
+			if e.AccountID == accountID && e.MatchID == nil && !e.EffectiveAt.Before(from) && e.EffectiveAt.Before(to) {
+				out = append(out, *e)
+			}
+		}
+		return out, nil
+	}
+	r.CreateMatchFunc = func(ctx context.Context, m *Match) error {
+		m.ID = fmt.Sprintf("m_%d", len(r.matches)+1)
+		for _, id := range m.LineIDs {
+			if r.lines[id].Status != LineUnmatched {
+				return ErrAlreadyMatched
+			}
+		}
+		for _, id := range m.EntryIDs {
+			if r.entries[id].MatchID != nil {
+				return ErrAlreadyMatched
+			}
+		}
+		status := LineMatched
+		if m.Rule == RuleWriteOff {
+			status = LineWrittenOff
+		}
+		for _, id := range m.LineIDs {
+			r.lines[id].Status = status
+			r.lines[id].MatchID = &m.ID
+		}
+		for _, id := range m.EntryIDs {
+			r.entries[id].MatchID = &m.ID
+		}
+		r.matches = append(r.matches, m)
+		return nil
+	}
+	r.GetRulesFunc = func(ctx context.Context, accountID string) ([]Rule, error) {
+		return r.rules[accountID], nil
+	}
+	r.SaveRulesFunc = func(ctx context.Context, accountID string, rules []Rule) error {
+		r.rules[accountID] = rules
+		return nil
+	}
+	return r
+}
+
+func (r *memRepo) addEntry(id string, at time.Time, amount int64, ref string) {
+	r.entries[id] = &LedgerEntry{EntryID: id, AccountID: "acc_bank", TransactionID: "tx_" + id, ReferenceID: ref, Amount: amount, EffectiveAt: at}
+}
+
+func TestImportStatement(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	repo.addEntry("e_pi1", day(1), -25000, "pi_1")
+	repo.addEntry("e_pi2", day(2), -4000, "pi_2")
+	repo.addEntry("e_pi3", day(2), -6000, "pi_3")
+	repo.addEntry("e_payout", day(2), 9900, "po_1")
+	repo.addEntry("e_late", day(20), -100, "pi_9")
+	service := NewReconciliationService(repo, nil)
+
+	csv := "date,amount,reference,description\n" +
+		"2026-03-02,250.00,pi_1,Card settlement\n" + // Reference, a day late
+		"2026-03-03,100.00,batch_7,Batch settlement\n" + // pi_2 + pi_3
+		"2026-03-03,-99.00,,Payout\n" + // Amount and date
+		"2026-03-03,-3.20,,Account fee\n" // Unknown to the ledger
+
+	res, err := service.ImportStatement(ctx, "zone_1", "live", "acc_bank", FormatCSV, []byte(csv))
+	if err != nil {
+		t.Fatalf("ImportStatement: %v", err)
+	}
+	if res.Duplicate || res.Statement.LineCount != 4 || res.Statement.Currency != "EUR" {
+		t.Fatalf("unexpected statement: %+v", res.Statement)
+	}
+	rec := res.Reconciliation
+	if rec.MatchedLines != 3 || rec.MatchedEntries != 4 || rec.UnmatchedLines != 1 {
+		t.Fatalf("unexpected reconciliation: %+v", rec)
+	}
+	rules := map[MatchRule]int{}
+	for _, m := range rec.Matches {
+		rules[m.Rule]++
+		if m.AccountID != "acc_bank" || m.ZoneID != "zone_1" {
+			t.Errorf("match not scoped to the account: %+v", m)
+		}
+	}
+	if rules[RuleReference] != 1 || rules[RuleAmountDate] != 1 || rules[RuleManyToOne] != 1 {
+		t.Errorf("unexpected rules used: %v", rules)
+	}
+
+	again, err := service.ImportStatement(ctx, "zone_1", "live", "acc_bank", FormatCSV, []byte(csv))
+	if err != nil || !again.Duplicate || again.Statement.ID != res.Statement.ID {
+		t.Errorf("expected re-import to return the first import, got %+v, %v", again, err)
+	}
+
+	queue, err := service.ReviewQueue(ctx, "zone_1", "live", "acc_bank")
+	if err != nil {
+		t.Fatalf("ReviewQueue: %v", err)
+	}
+	if len(queue.Lines) != 1 || queue.Lines[0].Description != "Account fee" {
+		t.Errorf("expected the account fee in the review queue, got %+v", queue.Lines)
+	}
+	if len(queue.Entries) != 0 {
+		t.Errorf("entries outside the statement period should not be queued, got %+v", queue.Entries)
+	}
+
+	t.Run("Rejected imports", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			accountID string
+			format    Format
+			data      string
+			want      error
+		}{
+			{"other zone", "acc_other", FormatCSV, csv, ErrAccountNotFound},
+			{"wrong currency", "acc_bank", FormatCSV, "date,amount,currency\n2026-03-02,1.00,USD\n", nil},
+			{"unparseable", "acc_bank", FormatMT940, "garbage", nil},
+		}
+		for _, tt := range tests {
+			_, err := service.ImportStatement(ctx, "zone_1", "live", tt.accountID, tt.format, []byte(tt.data))
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+			}
+			if tt.want == nil && !IsValidationError(err) {
+				t.Errorf("%s: expected a validation error, got %v", tt.name, err)
+			}
+		}
+	})
+}
+
+func TestReviewActions(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	setup := func(poster LedgerPoster) (*memRepo, *ReconciliationService) {
+		repo := newMemRepo()
+		repo.rules["acc_bank"] = []Rule{}
+		repo.addEntry("e_1", day, -1000, "pi_1")
+		repo.addEntry("e_2", day, -500, "pi_2")
+		service := NewReconciliationService(repo, poster)
+		csv := "date,amount,description\n2026-03-02,15.00,Two payments\n2026-03-02,-2.50,Bank fee\n"
+		if _, err := service.ImportStatement(ctx, "zone_1", "live", "acc_bank", FormatCSV, []byte(csv)); err != nil {
+			t.Fatalf("ImportStatement: %v", err)
+		}
+		return repo, service
+	}
+
+	t.Run("Manual match", func(t *testing.T) {
+		repo, service := setup(nil)
+		_, err := service.ManualMatch(ctx, "zone_1", "live", "user_1", ManualMatchRequest{
+			AccountID: "acc_bank", LineIDs: []string{"st_1_l1"}, EntryIDs: []string{"e_1"},
+		})
+		if !IsValidationError(err) || !strings.Contains(err.Error(), "offset exactly") {
+			t.Errorf("expected an amount mismatch error, got %v", err)
+		}
+
+		m, err := service.ManualMatch(ctx, "zone_1", "live", "user_1", ManualMatchRequest{
+			AccountID: "acc_bank", LineIDs: []string{"st_1_l1"}, EntryIDs: []string{"e_1", "e_2"}, Note: "Split settlement",
+		})
+		if err != nil {
+			t.Fatalf("ManualMatch: %v", err)
+		}
+		if m.Rule != RuleManual || m.CreatedBy != "user_1" || repo.lines["st_1_l1"].Status != LineMatched || repo.entries["e_2"].MatchID == nil {
+			t.Errorf("unexpected match state: %+v", m)
+		}
+
+		_, err = service.ManualMatch(ctx, "zone_1", "live", "user_1", ManualMatchRequest{
+			AccountID: "acc_bank", LineIDs: []string{"st_1_l1"}, EntryIDs: []string{"e_1", "e_2"},
+		})
+		if !IsValidationError(err) {
+			t.Errorf("expected matching a matched line to fail, got %v", err)
+		}
+	})
+
+	t.Run("Write off line without adjustment", func(t *testing.T) {
+		repo, service := setup(nil)
+		if _, err := service.WriteOffLine(ctx, "zone_1", "live", "user_1", "st_1_l2", WriteOffRequest{}); !IsValidationError(err) {
+			t.Errorf("expected a reason to be required, got %v", err)
+		}
+		if _, err := service.WriteOffLine(ctx, "zone_1", "live", "user_1", "st_1_l2", WriteOffRequest{Reason: "Fee", OffsetAccountID: "acc_fees"}); !IsValidationError(err) {
+			t.Errorf("expected adjustments to need a ledger poster, got %v", err)
+		}
+		if _, err := service.WriteOffLine(ctx, "zone_2", "live", "user_1", "st_1_l2", WriteOffRequest{Reason: "Fee"}); !errors.Is(err, ErrLineNotFound) {
+			t.Errorf("expected lines of other zones to be hidden, got %v", err)
+		}
+		m, err := service.WriteOffLine(ctx, "zone_1", "live", "user_1", "st_1_l2", WriteOffRequest{Reason: "Fee"})
+		if err != nil {
+			t.Fatalf("WriteOffLine: %v", err)
+		}
+		if m.Rule != RuleWriteOff || len(m.EntryIDs) != 0 || repo.lines["st_1_l2"].Status != LineWrittenOff {
+			t.Errorf("unexpected write-off: %+v", m)
+		}
+		if _, err := service.WriteOffLine(ctx, "zone_1", "live", "user_1", "st_1_l2", WriteOffRequest{Reason: "Fee"}); !errors.Is(err, ErrAlreadyMatched) {
+			t.Errorf("expected a second write-off to conflict, got %v", err)
+		}
+	})
+
+	t.Run("Write off line with adjustment", func(t *testing.T) {
+		var repo *memRepo
+		var posted []Adjustment
+		poster := &MockLedgerPoster{
+			RecordAdjustmentFunc: func(ctx context.Context, adj Adjustment) error {
+				posted = append(posted, adj)
+				repo.addEntry("e_adj", adj.EffectiveAt, adj.Amount, adj.ReferenceID)
+				return nil
+			},
+		}
+		repo, service := setup(poster)
+
+		m, err := service.WriteOffLine(ctx, "zone_1", "live", "user_1", "st_1_l2", WriteOffRequest{Reason: "Monthly fee", OffsetAccountID: "acc_fees"})
+		if err != nil {
+			t.Fatalf("WriteOffLine: %v", err)
+		}
+		if len(posted) != 1 {
+			t.Fatalf("expected one adjustment, got %d", len(posted))
+		}
+		adj := posted[0]
+		// Money left the bank: the asset account is credited (positive).
+		if adj.AccountID != "acc_bank" || adj.OffsetAccountID != "acc_fees" || adj.Amount != 250 || adj.ReferenceID != "recon_writeoff_st_1_l2" {
+			t.Errorf("unexpected adjustment: %+v", adj)
+		}
+		if len(m.EntryIDs) != 1 || m.EntryIDs[0] != "e_adj" || m.Adjustment != "recon_writeoff_st_1_l2" {
+			t.Errorf("expected the adjustment entry in the match, got %+v", m)
+		}
+	})
+
+	t.Run("Write off entry", func(t *testing.T) {
+		repo, service := setup(nil)
+		if _, err := service.WriteOffEntry(ctx, "zone_2", "live", "user_1", "e_1", WriteOffRequest{Reason: "Internal"}); !errors.Is(err, ErrEntryNotFound) {
+			t.Errorf("expected entries of other zones to be hidden, got %v", err)
+		}
+		if _, err := service.WriteOffEntry(ctx, "zone_1", "live", "user_1", "e_1", WriteOffRequest{Reason: "Booked twice"}); err != nil {
+			t.Fatalf("WriteOffEntry: %v", err)
+		}
+		if repo.entries["e_1"].MatchID == nil {
+			t.Error("expected the entry to be resolved")
+		}
+	})
+}
+
+func TestSetRules(t *testing.T) {
+	service := NewReconciliationService(newMemRepo(), nil)
+	ctx := context.Background()
+
+	invalid := [][]Rule{
+		{{Type: "fuzzy"}},
+		{{Type: RuleReference}, {Type: RuleReference}},
+		{{Type: RuleAmountDate, DateWindowDays: 90}},
+		{{Type: RuleAmountDate, MaxGroupSize: 3}},
+		{{Type: RuleManyToOne, MaxGroupSize: 500}},
+	}
+	for _, rules := range invalid {
+		if err := service.SetRules(ctx, "zone_1", "live", "acc_bank", rules); !IsValidationError(err) {
+			t.Errorf("%+v: expected a validation error, got %v", rules, err)
+		}
+	}
+
+	got, err := service.GetRules(ctx, "zone_1", "live", "acc_bank")
+	if err != nil || len(got) != len(DefaultRules) {
+		t.Errorf("expected default rules, got %+v, %v", got, err)
+	}
+	want := []Rule{{Type: RuleAmountDate, DateWindowDays: 5}}
+	if err := service.SetRules(ctx, "zone_1", "live", "acc_bank", want); err != nil {
+		t.Fatalf("SetRules: %v", err)
+	}
+	if got, _ := service.GetRules(ctx, "zone_1", "live", "acc_bank"); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("expected saved rules, got %+v", got)
+	}
+}
@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+)
+
+// DiscrepancyEventType is the event flows subscribe to for discrepancies.
+const DiscrepancyEventType = "reconciliation.discrepancy"
+
+// RedisEventPublisher emits discrepancies to the zone's event stream in the
+// envelope the gateway uses, so flow triggers pick them up like any other
+// zone event.
+type RedisEventPublisher struct {
+	client *redis.Client
+}
+
+func NewRedisEventPublisher(client *redis.Client) *RedisEventPublisher {
+	return &RedisEventPublisher{client: client}
+}
+
+func (p *RedisEventPublisher) PublishDiscrepancy(ctx context.Context, d domain.Discrepancy) error {
+	if d.ZoneID == "" {
+		return nil // Payments from before zones have no stream to go to
+	}
+	payload := map[string]interface{}{
+		"id":             d.ID,
+		"run_id":         d.RunID,
+		"mode":           d.Mode,
+		"category":       d.Category,
+		"reference_id":   d.ReferenceID,
+		"payment_id":     d.PaymentID,
+		"payment_status": d.PaymentStatus,
+		"transaction_id": d.TransactionID,
+		"currency":       d.Currency,
+		"detail":         d.Detail,
+	}
+	if d.ExpectedAmount != nil {
+		payload["expected_amount"] = *d.ExpectedAmount
+	}
+	if d.ActualAmount != nil {
+		payload["actual_amount"] = *d.ActualAmount
+	}
+
+	envelope, err := json.Marshal(map[string]interface{}{
+		"id":              "evt_" + d.ID,
+		"type":            DiscrepancyEventType,
+		"zone_id":         d.ZoneID,
+		"timestamp":       d.CreatedAt.UTC().Format(time.RFC3339),
+		"idempotency_key": d.ID,
+		"payload":         payload,
+		"meta":            map[string]string{"source": "reconciler", "env": d.Mode},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: fmt.Sprintf("zone.%s.event.%s", d.ZoneID, DiscrepancyEventType),
+		Values: map[string]interface{}{
+			"envelope": envelope,
+			"data":     envelope,
+			"ts":       time.Now().Unix(),
+		},
+	}).Err()
+}
@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+	pb "github.com/sapliy/fintech-ecosystem/proto/ledger"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// LedgerClient posts reconciliation adjustments through the ledger service,
+// so they go through the same balance and period checks as any posting.
+type LedgerClient struct {
+	client pb.LedgerServiceClient
+}
+
+func NewLedgerClient(client pb.LedgerServiceClient) *LedgerClient {
+	return &LedgerClient{client: client}
+}
+
+func (c *LedgerClient) RecordAdjustment(ctx context.Context, adj domain.Adjustment) error {
+	req := &pb.RecordTransactionRequest{
+		Currency:    adj.Currency,
+		Description: adj.Description,
+		ReferenceId: adj.ReferenceID,
+		ZoneId:      adj.ZoneID,
+		Mode:        adj.Mode,
+		Entries: []*pb.LedgerEntry{
+			{AccountId: adj.AccountID, Amount: adj.Amount, Direction: direction(adj.Amount)},
+			{AccountId: adj.OffsetAccountID, Amount: -adj.Amount, Direction: direction(-adj.Amount)},
+		},
+	}
+	if !adj.EffectiveAt.IsZero() {
+		req.EffectiveAt = timestamppb.New(adj.EffectiveAt)
+	}
+	_, err := c.client.RecordTransaction(ctx, req)
+	return err
+}
+
+func direction(amount int64) string {
+	if amount < 0 {
+		return "debit"
+	}
+	return "credit"
+}
@@ -0,0 +1,299 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+)
+
+// Watermark names in reconciliation_watermarks.
+const (
+	watermarkPayments = "payments"
+	watermarkLedger   = "ledger"
+)
+
+// SQLRunRepository stores audit runs in the ledger database, next to the
+// statement reconciliation tables.
+type SQLRunRepository struct {
+	db *sql.DB
+}
+
+func NewSQLRunRepository(db *sql.DB) *SQLRunRepository {
+	return &SQLRunRepository{db: db}
+}
+
+func (r *SQLRunRepository) GetWatermarks(ctx context.Context) (domain.Watermarks, error) {
+	var wm domain.Watermarks
+	rows, err := r.db.QueryContext(ctx, `SELECT name, position FROM reconciliation_watermarks`)
+	if err != nil {
+		return wm, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var name string
+		var position time.Time
+		if err := rows.Scan(&name, &position); err != nil {
+			return wm, err
+		}
+		switch name {
+		case watermarkPayments:
+			wm.Payments = position
+		case watermarkLedger:
+			wm.Ledger = position
+		}
+	}
+	return wm, rows.Err()
+}
+
+func (r *SQLRunRepository) CreateRun(ctx context.Context, run *domain.Run) error {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO reconciliation_runs (trigger, status, payments_from, ledger_from, until, started_at)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		run.Trigger, run.Status, run.PaymentsFrom, run.LedgerFrom, run.Until, run.StartedAt).Scan(&run.ID)
+	if err != nil {
+		// A partial unique index allows a single running run.
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return domain.ErrRunInProgress
+		}
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRunRepository) CompleteRun(ctx context.Context, run *domain.Run) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := finishRun(ctx, tx, run); err != nil {
+		return err
+	}
+	for _, name := range []string{watermarkPayments, watermarkLedger} {
+		// Watermarks only move forward, even if the grace period was raised.
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO reconciliation_watermarks (name, position, run_id) VALUES ($1, $2, $3)
+			 ON CONFLICT (name) DO UPDATE
+			 SET position = GREATEST(reconciliation_watermarks.position, EXCLUDED.position),
+			     run_id = EXCLUDED.run_id, updated_at = NOW()`,
+			name, run.Until, run.ID)
+		if err != nil {
+			return fmt.Errorf("failed to move %s watermark: %w", name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRunRepository) FailRun(ctx context.Context, run *domain.Run) error {
+	return finishRun(ctx, r.db, run)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func finishRun(ctx context.Context, db execer, run *domain.Run) error {
+	summary, err := json.Marshal(run.Summary)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		`UPDATE reconciliation_runs
+		 SET status = $2, payments_checked = $3, transactions_checked = $4, discrepancy_count = $5,
+		     resolved_count = $6, summary = $7, error = NULLIF($8, ''), finished_at = $9
+		 WHERE id = $1`,
+		run.ID, run.Status, run.PaymentsChecked, run.TransactionsChecked, run.DiscrepancyCount,
+		run.ResolvedCount, summary, run.Error, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish run: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRunRepository) FailStaleRuns(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE reconciliation_runs SET status = 'failed', error = 'run did not finish', finished_at = NOW()
+		 WHERE status = 'running' AND started_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale runs: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+const runColumns = `id, trigger, status, payments_from, ledger_from, until, payments_checked, transactions_checked,
+	discrepancy_count, resolved_count, summary, COALESCE(error, ''), started_at, finished_at`
+
+func scanRun(row interface{ Scan(...any) error }) (*domain.Run, error) {
+	var run domain.Run
+	var summary []byte
+	var finished sql.NullTime
+	err := row.Scan(&run.ID, &run.Trigger, &run.Status, &run.PaymentsFrom, &run.LedgerFrom, &run.Until,
+		&run.PaymentsChecked, &run.TransactionsChecked, &run.DiscrepancyCount, &run.ResolvedCount,
+		&summary, &run.Error, &run.StartedAt, &finished)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(summary, &run.Summary); err != nil {
+		return nil, fmt.Errorf("failed to decode run summary: %w", err)
+	}
+	if finished.Valid {
+		run.FinishedAt = &finished.Time
+	}
+	return &run, nil
+}
+
+func (r *SQLRunRepository) GetRun(ctx context.Context, id string) (*domain.Run, error) {
+	run, err := scanRun(r.db.QueryRowContext(ctx, `SELECT `+runColumns+` FROM reconciliation_runs WHERE id::text = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	return run, nil
+}
+
+func (r *SQLRunRepository) ListRuns(ctx context.Context, limit int) ([]domain.Run, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+runColumns+` FROM reconciliation_runs ORDER BY started_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	runs := []domain.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+func (r *SQLRunRepository) CreateDiscrepancy(ctx context.Context, d *domain.Discrepancy) (bool, error) {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO reconciliation_discrepancies (run_id, zone_id, mode, category, status, reference_id, payment_id,
+		     payment_status, transaction_id, currency, expected_amount, actual_amount, detail)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid, $10, $11, $12, $13)
+		 ON CONFLICT (reference_id, category) WHERE status = 'open' DO NOTHING
+		 RETURNING id, created_at`,
+		d.RunID, d.ZoneID, d.Mode, d.Category, d.Status, d.ReferenceID, d.PaymentID,
+		d.PaymentStatus, d.TransactionID, d.Currency, d.ExpectedAmount, d.ActualAmount, d.Detail).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create discrepancy: %w", err)
+	}
+	return true, nil
+}
+
+const discrepancyColumns = `id, run_id, zone_id, mode, category, status, reference_id, payment_id, payment_status,
+	COALESCE(transaction_id::text, ''), currency, expected_amount, actual_amount, detail, created_at, resolved_at,
+	COALESCE(resolved_run_id::text, '')`
+
+func scanDiscrepancy(row interface{ Scan(...any) error }) (*domain.Discrepancy, error) {
+	var d domain.Discrepancy
+	var expected, actual sql.NullInt64
+	var resolved sql.NullTime
+	err := row.Scan(&d.ID, &d.RunID, &d.ZoneID, &d.Mode, &d.Category, &d.Status, &d.ReferenceID, &d.PaymentID,
+		&d.PaymentStatus, &d.TransactionID, &d.Currency, &expected, &actual, &d.Detail, &d.CreatedAt, &resolved,
+		&d.ResolvedRunID)
+	if err != nil {
+		return nil, err
+	}
+	if expected.Valid {
+		d.ExpectedAmount = &expected.Int64
+	}
+	if actual.Valid {
+		d.ActualAmount = &actual.Int64
+	}
+	if resolved.Valid {
+		d.ResolvedAt = &resolved.Time
+	}
+	return &d, nil
+}
+
+func (r *SQLRunRepository) GetDiscrepancy(ctx context.Context, id string) (*domain.Discrepancy, error) {
+	d, err := scanDiscrepancy(r.db.QueryRowContext(ctx,
+		`SELECT `+discrepancyColumns+` FROM reconciliation_discrepancies WHERE id::text = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get discrepancy: %w", err)
+	}
+	return d, nil
+}
+
+func (r *SQLRunRepository) ListDiscrepancies(ctx context.Context, filter domain.DiscrepancyFilter) ([]domain.Discrepancy, error) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.ZoneID != "" {
+		add("zone_id = $%d", filter.ZoneID)
+	}
+	if filter.Mode != "" {
+		add("mode = $%d", filter.Mode)
+	}
+	if filter.RunID != "" {
+		add("run_id::text = $%d", filter.RunID)
+	}
+	if filter.Category != "" {
+		add("category = $%d", filter.Category)
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+	query := `SELECT ` + discrepancyColumns + ` FROM reconciliation_discrepancies`
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d`, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list discrepancies: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	list := []domain.Discrepancy{}
+	for rows.Next() {
+		d, err := scanDiscrepancy(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *d)
+	}
+	return list, rows.Err()
+}
+
+func (r *SQLRunRepository) ResolveDiscrepancy(ctx context.Context, id, runID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE reconciliation_discrepancies SET status = 'resolved', resolved_at = NOW(), resolved_run_id = $2
+		 WHERE id = $1 AND status = 'open'`, id, runID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve discrepancy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+)
+
+// SQLPaymentSource reads payment intents from the payments database.
+type SQLPaymentSource struct {
+	db *sql.DB
+}
+
+func NewSQLPaymentSource(db *sql.DB) *SQLPaymentSource {
+	return &SQLPaymentSource{db: db}
+}
+
+// paymentColumns reads the captured amount, which is below the intent
+// amount after a partial capture.
+const paymentColumns = `id, COALESCE(zone_id, ''), COALESCE(mode, ''), COALESCE(NULLIF(amount_received, 0), amount), currency, status, updated_at`
+
+func (s *SQLPaymentSource) scan(rows *sql.Rows) ([]domain.Payment, error) {
+	defer func() { _ = rows.Close() }()
+	var out []domain.Payment
+	for rows.Next() {
+		var p domain.Payment
+		if err := rows.Scan(&p.ID, &p.ZoneID, &p.Mode, &p.Amount, &p.Currency, &p.Status, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// withRefunds loads the pending and succeeded refunds of payments.
+func (s *SQLPaymentSource) withRefunds(ctx context.Context, payments []domain.Payment) ([]domain.Payment, error) {
+	if len(payments) == 0 {
+		return payments, nil
+	}
+	ids := make([]string, len(payments))
+	index := map[string]int{}
+	for i, p := range payments {
+		ids[i] = p.ID
+		index[p.ID] = i
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT payment_intent_id::text, ledger_reference, amount, status FROM refunds
+		 WHERE payment_intent_id::text = ANY($1) AND status IN ('pending', 'succeeded')
+		 ORDER BY created_at, id`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunds: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var paymentID, status string
+		var r domain.PaymentRefund
+		if err := rows.Scan(&paymentID, &r.Reference, &r.Amount, &status); err != nil {
+			return nil, err
+		}
+		r.Pending = status == "pending"
+		if i, ok := index[paymentID]; ok {
+			payments[i].Refunds = append(payments[i].Refunds, r)
+		}
+	}
+	return payments, rows.Err()
+}
+
+func (s *SQLPaymentSource) ListPaymentsUpdated(ctx context.Context, from, until time.Time, after domain.Cursor, limit int) ([]domain.Payment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+paymentColumns+` FROM payment_intents
+		 WHERE updated_at > $1 AND updated_at <= $2 AND (updated_at, id::text) > ($3, $4)
+		 ORDER BY updated_at, id::text LIMIT $5`,
+		from, until, after.At, after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+	payments, err := s.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	return s.withRefunds(ctx, payments)
+}
+
+func (s *SQLPaymentSource) GetPayments(ctx context.Context, ids []string) (map[string]domain.Payment, error) {
+	out := map[string]domain.Payment{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+paymentColumns+` FROM payment_intents WHERE id::text = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	payments, err := s.scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	if payments, err = s.withRefunds(ctx, payments); err != nil {
+		return nil, err
+	}
+	for _, p := range payments {
+		out[p.ID] = p
+	}
+	return out, nil
+}
+
+// SQLLedgerSource reads ledger transactions from the ledger database.
+type SQLLedgerSource struct {
+	db *sql.DB
+}
+
+func NewSQLLedgerSource(db *sql.DB) *SQLLedgerSource {
+	return &SQLLedgerSource{db: db}
+}
+
+func (s *SQLLedgerSource) GetTransactionsByReference(ctx context.Context, refs []string) (map[string]domain.LedgerTransaction, error) {
+	out := map[string]domain.LedgerTransaction{}
+	if len(refs) == 0 {
+		return out, nil
+	}
+	txs, err := s.query(ctx,
+		`SELECT id, reference_id, COALESCE(zone_id, ''), COALESCE(mode, ''), created_at
+		 FROM transactions WHERE reference_id = ANY($1)`, pq.Array(refs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger transactions: %w", err)
+	}
+	for _, tx := range txs {
+		out[tx.ReferenceID] = tx
+	}
+	return out, nil
+}
+
+func (s *SQLLedgerSource) ListSettlementTransactions(ctx context.Context, from, until time.Time, after domain.Cursor, limit int) ([]domain.LedgerTransaction, error) {
+	txs, err := s.query(ctx,
+		`SELECT t.id, COALESCE(t.reference_id, ''), COALESCE(t.zone_id, ''), COALESCE(t.mode, ''), t.created_at
+		 FROM transactions t
+		 WHERE t.created_at > $1 AND t.created_at <= $2 AND (t.created_at, t.id::text) > ($3, $4)
+		   AND EXISTS (SELECT 1 FROM entries e JOIN accounts a ON a.id = e.account_id
+		               WHERE e.transaction_id = t.id AND a.code = '1100')
+		 ORDER BY t.created_at, t.id::text LIMIT $5`,
+		from, until, after.At, after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement transactions: %w", err)
+	}
+	return txs, nil
+}
+
+// query loads transactions and then their legs in one more round trip.
+func (s *SQLLedgerSource) query(ctx context.Context, query string, args ...any) ([]domain.LedgerTransaction, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var txs []domain.LedgerTransaction
+	index := map[string]int{}
+	for rows.Next() {
+		var tx domain.LedgerTransaction
+		if err := rows.Scan(&tx.ID, &tx.ReferenceID, &tx.ZoneID, &tx.Mode, &tx.CreatedAt); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		index[tx.ID] = len(txs)
+		txs = append(txs, tx)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return txs, nil
+	}
+
+	ids := make([]string, len(txs))
+	for i, tx := range txs {
+		ids[i] = tx.ID
+	}
+	legs, err := s.db.QueryContext(ctx,
+		`SELECT e.transaction_id, e.account_id, e.amount, a.currency
+		 FROM entries e JOIN accounts a ON a.id = e.account_id
+		 WHERE e.transaction_id::text = ANY($1) ORDER BY e.created_at, e.id`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = legs.Close() }()
+	for legs.Next() {
+		var txID string
+		var leg domain.LedgerLeg
+		if err := legs.Scan(&txID, &leg.AccountID, &leg.Amount, &leg.Currency); err != nil {
+			return nil, err
+		}
+		if i, ok := index[txID]; ok {
+			txs[i].Legs = append(txs[i].Legs, leg)
+		}
+	}
+	return txs, legs.Err()
+}
@@ -0,0 +1,343 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sapliy/fintech-ecosystem/internal/reconciliation/domain"
+)
+
+// SQLRepository works on the ledger database, next to the accounts and
+// entries it reconciles.
+type SQLRepository struct {
+	db *sql.DB
+}
+
+func NewSQLRepository(db *sql.DB) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+func (r *SQLRepository) GetAccount(ctx context.Context, id string) (*domain.Account, error) {
+	var acc domain.Account
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, COALESCE(zone_id, ''), COALESCE(mode, ''), COALESCE(name, ''), currency FROM accounts WHERE id::text = $1`,
+		id).Scan(&acc.ID, &acc.ZoneID, &acc.Mode, &acc.Name, &acc.Currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return &acc, nil
+}
+
+func (r *SQLRepository) CreateStatement(ctx context.Context, st *domain.Statement) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO bank_statements (zone_id, mode, account_id, format, reference, bank_account, currency,
+		     opening_balance, closing_balance, period_from, period_to, line_count, checksum)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, imported_at`,
+		st.ZoneID, st.Mode, st.AccountID, st.Format, st.Reference, st.BankAccount, st.Currency,
+		st.OpeningBalance, st.ClosingBalance, st.From, st.To, st.LineCount, st.Checksum).Scan(&st.ID, &st.ImportedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create statement: %w", err)
+	}
+
+	for i := range st.Lines {
+		l := &st.Lines[i]
+		l.StatementID = st.ID
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO bank_statement_lines (statement_id, zone_id, mode, account_id, line_no, booking_date, value_date,
+			     amount, currency, reference, bank_reference, description, counterparty, status)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`,
+			l.StatementID, l.ZoneID, l.Mode, l.AccountID, l.LineNo, l.BookingDate, nullTime(l.ValueDate),
+			l.Amount, l.Currency, l.Reference, l.BankReference, l.Description, l.Counterparty, l.Status).Scan(&l.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create statement line %d: %w", l.LineNo, err)
+		}
+	}
+	return tx.Commit()
+}
+
+const statementColumns = `id, zone_id, mode, account_id, format, reference, bank_account, currency,
+	opening_balance, closing_balance, period_from, period_to, line_count, checksum, imported_at`
+
+func scanStatement(row interface{ Scan(...any) error }) (*domain.Statement, error) {
+	var st domain.Statement
+	var opening, closing sql.NullInt64
+	err := row.Scan(&st.ID, &st.ZoneID, &st.Mode, &st.AccountID, &st.Format, &st.Reference, &st.BankAccount, &st.Currency,
+		&opening, &closing, &st.From, &st.To, &st.LineCount, &st.Checksum, &st.ImportedAt)
+	if err != nil {
+		return nil, err
+	}
+	if opening.Valid {
+		st.OpeningBalance = &opening.Int64
+	}
+	if closing.Valid {
+		st.ClosingBalance = &closing.Int64
+	}
+	return &st, nil
+}
+
+func (r *SQLRepository) getStatementWhere(ctx context.Context, cond string, args ...any) (*domain.Statement, error) {
+	st, err := scanStatement(r.db.QueryRowContext(ctx, `SELECT `+statementColumns+` FROM bank_statements WHERE `+cond, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get statement: %w", err)
+	}
+	return st, nil
+}
+
+func (r *SQLRepository) GetStatement(ctx context.Context, id string) (*domain.Statement, error) {
+	st, err := r.getStatementWhere(ctx, `id::text = $1`, id)
+	if err != nil || st == nil {
+		return st, err
+	}
+	st.Lines, err = r.listLinesWhere(ctx, `statement_id = $1 ORDER BY line_no`, st.ID)
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (r *SQLRepository) GetStatementByChecksum(ctx context.Context, accountID, checksum string) (*domain.Statement, error) {
+	return r.getStatementWhere(ctx, `account_id = $1 AND checksum = $2`, accountID, checksum)
+}
+
+func (r *SQLRepository) ListStatements(ctx context.Context, accountID string, limit int) ([]domain.Statement, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+statementColumns+` FROM bank_statements WHERE account_id = $1 ORDER BY period_to DESC, imported_at DESC LIMIT $2`,
+		accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statements := []domain.Statement{}
+	for rows.Next() {
+		st, err := scanStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *st)
+	}
+	return statements, rows.Err()
+}
+
+func (r *SQLRepository) GetCoveredPeriod(ctx context.Context, accountID string) (time.Time, time.Time, error) {
+	var from, to sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT MIN(period_from), MAX(period_to) FROM bank_statements WHERE account_id = $1`,
+		accountID).Scan(&from, &to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from.Time, to.Time, nil
+}
+
+const lineColumns = `id, statement_id, zone_id, mode, account_id, line_no, booking_date, value_date, amount, currency,
+	reference, bank_reference, description, counterparty, status, match_id`
+
+func scanLine(row interface{ Scan(...any) error }) (*domain.Line, error) {
+	var l domain.Line
+	var valueDate sql.NullTime
+	var matchID sql.NullString
+	err := row.Scan(&l.ID, &l.StatementID, &l.ZoneID, &l.Mode, &l.AccountID, &l.LineNo, &l.BookingDate, &valueDate, &l.Amount, &l.Currency,
+		&l.Reference, &l.BankReference, &l.Description, &l.Counterparty, &l.Status, &matchID)
+	if err != nil {
+		return nil, err
+	}
+	l.ValueDate = valueDate.Time
+	if matchID.Valid {
+		l.MatchID = &matchID.String
+	}
+	return &l, nil
+}
+
+func (r *SQLRepository) listLinesWhere(ctx context.Context, cond string, args ...any) ([]domain.Line, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+lineColumns+` FROM bank_statement_lines WHERE `+cond, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []domain.Line{}
+	for rows.Next() {
+		l, err := scanLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, *l)
+	}
+	return lines, rows.Err()
+}
+
+func (r *SQLRepository) GetLine(ctx context.Context, id string) (*domain.Line, error) {
+	l, err := scanLine(r.db.QueryRowContext(ctx, `SELECT `+lineColumns+` FROM bank_statement_lines WHERE id::text = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get statement line: %w", err)
+	}
+	return l, nil
+}
+
+func (r *SQLRepository) ListUnmatchedLines(ctx context.Context, accountID string) ([]domain.Line, error) {
+	return r.listLinesWhere(ctx, `account_id = $1 AND status = 'unmatched' ORDER BY booking_date, statement_id, line_no`, accountID)
+}
+
+const entrySelect = `SELECT e.id, e.account_id, e.transaction_id, COALESCE(t.reference_id, ''), COALESCE(t.description, ''),
+	e.amount, t.effective_at, m.match_id
+	FROM entries e
+	JOIN transactions t ON t.id = e.transaction_id
+	LEFT JOIN reconciliation_match_entries m ON m.entry_id = e.id`
+
+func scanEntry(row interface{ Scan(...any) error }) (*domain.LedgerEntry, error) {
+	var e domain.LedgerEntry
+	var matchID sql.NullString
+	if err := row.Scan(&e.EntryID, &e.AccountID, &e.TransactionID, &e.ReferenceID, &e.Description, &e.Amount, &e.EffectiveAt, &matchID); err != nil {
+		return nil, err
+	}
+	if matchID.Valid {
+		e.MatchID = &matchID.String
+	}
+	return &e, nil
+}
+
+func (r *SQLRepository) getEntryWhere(ctx context.Context, cond string, args ...any) (*domain.LedgerEntry, error) {
+	e, err := scanEntry(r.db.QueryRowContext(ctx, entrySelect+` WHERE `+cond, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ledger entry: %w", err)
+	}
+	return e, nil
+}
+
+func (r *SQLRepository) GetEntry(ctx context.Context, entryID string) (*domain.LedgerEntry, error) {
+	return r.getEntryWhere(ctx, `e.id::text = $1`, entryID)
+}
+
+func (r *SQLRepository) GetEntryByReference(ctx context.Context, accountID, referenceID string) (*domain.LedgerEntry, error) {
+	return r.getEntryWhere(ctx, `e.account_id = $1 AND t.reference_id = $2 LIMIT 1`, accountID, referenceID)
+}
+
+func (r *SQLRepository) ListUnmatchedEntries(ctx context.Context, accountID string, from, to time.Time) ([]domain.LedgerEntry, error) {
+	rows, err := r.db.QueryContext(ctx,
+		entrySelect+` WHERE e.account_id = $1 AND t.effective_at >= $2 AND t.effective_at < $3 AND m.match_id IS NULL
+		 ORDER BY t.effective_at, e.id`,
+		accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.LedgerEntry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// CreateMatch claims the lines with a conditional update and the entries
+// through the unique entry_id constraint, so two reviewers resolving the
+// same item cannot both succeed.
+func (r *SQLRepository) CreateMatch(ctx context.Context, m *domain.Match) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO reconciliation_matches (zone_id, mode, account_id, rule, note, created_by, adjustment_reference)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		m.ZoneID, m.Mode, m.AccountID, m.Rule, m.Note, m.CreatedBy, m.Adjustment).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create match: %w", err)
+	}
+
+	status := domain.LineMatched
+	if m.Rule == domain.RuleWriteOff {
+		status = domain.LineWrittenOff
+	}
+	for _, id := range m.LineIDs {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE bank_statement_lines SET status = $1, match_id = $2 WHERE id::text = $3 AND status = 'unmatched'`,
+			status, m.ID, id)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return domain.ErrAlreadyMatched
+		}
+	}
+	for _, id := range m.EntryIDs {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO reconciliation_match_entries (match_id, entry_id) VALUES ($1, $2::uuid) ON CONFLICT (entry_id) DO NOTHING`,
+			m.ID, id)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return domain.ErrAlreadyMatched
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) GetRules(ctx context.Context, accountID string) ([]domain.Rule, error) {
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, `SELECT rules FROM reconciliation_rules WHERE account_id = $1`, accountID).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rules: %w", err)
+	}
+	rules := []domain.Rule{}
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *SQLRepository) SaveRules(ctx context.Context, accountID string, rules []domain.Rule) error {
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO reconciliation_rules (account_id, rules, updated_at) VALUES ($1, $2, NOW())
+		 ON CONFLICT (account_id) DO UPDATE SET rules = EXCLUDED.rules, updated_at = NOW()`,
+		accountID, raw)
+	if err != nil {
+		return fmt.Errorf("failed to save rules: %w", err)
+	}
+	return nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
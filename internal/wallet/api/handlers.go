@@ -52,7 +52,12 @@ func (h *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
 	// Optional: Check if userID matches targetUserID or user has admin scope
 	// For now, we trust the gateway's scoping logic.
 
-	wallet, err := h.service.GetWallet(r.Context(), targetUserID)
+	wallet, err := h.service.GetWallet(r.Context(), &pb.GetWalletRequest{
+		UserId:   targetUserID,
+		ZoneId:   r.Header.Get("X-Zone-ID"),
+		Mode:     r.Header.Get("X-Zone-Mode"),
+		Currency: r.URL.Query().Get("currency"),
+	})
 	if err != nil {
 		apierror.Internal("Failed to retrieve wallet").Write(w)
 		return
@@ -83,6 +88,8 @@ func (h *WalletHandler) TopUp(w http.ResponseWriter, r *http.Request) {
 		Amount:      req.Amount,
 		Currency:    req.Currency,
 		ReferenceId: req.ReferenceId,
+		ZoneId:      r.Header.Get("X-Zone-ID"),
+		Mode:        r.Header.Get("X-Zone-Mode"),
 	})
 	if err != nil {
 		apierror.Internal(err.Error()).Write(w)
@@ -111,6 +118,8 @@ func (h *WalletHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 		Amount:      req.Amount,
 		Currency:    req.Currency,
 		ReferenceId: req.ReferenceId,
+		ZoneId:      r.Header.Get("X-Zone-ID"),
+		Mode:        r.Header.Get("X-Zone-Mode"),
 	})
 	if err != nil {
 		apierror.Internal(err.Error()).Write(w)
@@ -132,7 +141,7 @@ func NewWalletGRPCServer(service *domain.WalletService) *WalletGRPCServer {
 }
 
 func (s *WalletGRPCServer) GetWallet(ctx context.Context, req *pb.GetWalletRequest) (*pb.Wallet, error) {
-	return s.service.GetWallet(ctx, req.UserId)
+	return s.service.GetWallet(ctx, req)
 }
 
 func (s *WalletGRPCServer) TopUp(ctx context.Context, req *pb.TopUpRequest) (*pb.TransactionResponse, error) {
@@ -7,12 +7,12 @@ import (
 )
 
 type MockLedgerClient struct {
-	GetAccountFunc        func(ctx context.Context, accountID string) (*pb.GetAccountResponse, error)
+	GetAccountFunc        func(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error)
 	RecordTransactionFunc func(ctx context.Context, req *pb.RecordTransactionRequest) (*pb.RecordTransactionResponse, error)
 }
 
-func (m *MockLedgerClient) GetAccount(ctx context.Context, accountID string) (*pb.GetAccountResponse, error) {
-	return m.GetAccountFunc(ctx, accountID)
+func (m *MockLedgerClient) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	return m.GetAccountFunc(ctx, req)
 }
 
 func (m *MockLedgerClient) RecordTransaction(ctx context.Context, req *pb.RecordTransactionRequest) (*pb.RecordTransactionResponse, error) {
@@ -10,7 +10,7 @@ import (
 )
 
 type LedgerClient interface {
-	GetAccount(ctx context.Context, accountID string) (*pb.GetAccountResponse, error)
+	GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error)
 	RecordTransaction(ctx context.Context, req *pb.RecordTransactionRequest) (*pb.RecordTransactionResponse, error)
 }
 
@@ -22,17 +22,29 @@ func NewWalletService(ledger LedgerClient) *WalletService {
 	return &WalletService{ledgerClient: ledger}
 }
 
-func (s *WalletService) GetWallet(ctx context.Context, userID string) (*walletpb.Wallet, error) {
-	// For now, we assume user ID is the account ID in the ledger
-	// In a real system, there would be a mapping or a specific wallet account naming convention
-	res, err := s.ledgerClient.GetAccount(ctx, userID)
+// GetWallet returns the user's wallet account of the zone in the ledger, the
+// same account payments to the user are credited to.
+func (s *WalletService) GetWallet(ctx context.Context, req *walletpb.GetWalletRequest) (*walletpb.Wallet, error) {
+	if err := validation.Validate(
+		validation.NotEmpty(req.UserId, "user_id"),
+		validation.NotEmpty(req.ZoneId, "zone_id"),
+	); err != nil {
+		return nil, err
+	}
+
+	res, err := s.ledgerClient.GetAccount(ctx, &pb.GetAccountRequest{
+		UserId:   req.UserId,
+		ZoneId:   req.ZoneId,
+		Mode:     req.Mode,
+		Currency: req.Currency,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ledger error: %w", err)
 	}
 
 	return &walletpb.Wallet{
 		Id:       res.AccountId,
-		UserId:   userID,
+		UserId:   req.UserId,
 		Balance:  res.Balance,
 		Currency: res.Currency,
 	}, nil
@@ -41,6 +53,7 @@ func (s *WalletService) GetWallet(ctx context.Context, userID string) (*walletpb
 func (s *WalletService) TopUp(ctx context.Context, req *walletpb.TopUpRequest) (*walletpb.TransactionResponse, error) {
 	if err := validation.Validate(
 		validation.NotEmpty(req.UserId, "user_id"),
+		validation.NotEmpty(req.ZoneId, "zone_id"),
 		validation.PositiveAmount(req.Amount, "amount"),
 		validation.NotEmpty(req.Currency, "currency"),
 	); err != nil {
@@ -48,13 +61,15 @@ func (s *WalletService) TopUp(ctx context.Context, req *walletpb.TopUpRequest) (
 	}
 
 	// Top up involves recording a transaction in the ledger
-	// From an internal "float" or "system" account to the user's liability account
+	// From the zone's settlement clearing account to the user's wallet account
 	res, err := s.ledgerClient.RecordTransaction(ctx, &pb.RecordTransactionRequest{
-		AccountId:   req.UserId,
+		UserId:      req.UserId,
 		Amount:      req.Amount,
 		Currency:    req.Currency,
 		Description: "Wallet Top-Up",
 		ReferenceId: req.ReferenceId,
+		ZoneId:      req.ZoneId,
+		Mode:        req.Mode,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("ledger error: %w", err)
@@ -70,39 +85,28 @@ func (s *WalletService) Transfer(ctx context.Context, req *walletpb.TransferRequ
 	if err := validation.Validate(
 		validation.NotEmpty(req.FromUserId, "from_user_id"),
 		validation.NotEmpty(req.ToUserId, "to_user_id"),
+		validation.NotEmpty(req.ZoneId, "zone_id"),
 		validation.PositiveAmount(req.Amount, "amount"),
 		validation.NotEmpty(req.Currency, "currency"),
 	); err != nil {
 		return nil, err
 	}
 
-	// Transfer between two wallets
-	// FIXME: This implementation is NOT atomic. If the debit succeeds but the credit fails,
-	// money will be lost. This should use a multi-leg transaction supported by the ledger.
-	// For now, we perform two sequential updates.
-
-	// Debit from_user (amount is negative)
-	_, err := s.ledgerClient.RecordTransaction(ctx, &pb.RecordTransactionRequest{
-		AccountId:   req.FromUserId,
-		Amount:      -req.Amount,
-		Currency:    req.Currency,
-		Description: fmt.Sprintf("Transfer to %s", req.ToUserId),
-		ReferenceId: req.ReferenceId + "_debit",
-	})
-	if err != nil {
-		return nil, fmt.Errorf("debit failed: %w", err)
-	}
-
-	// Credit to_user (amount is positive)
+	// Transfer between two wallets as a single balanced ledger transaction,
+	// so the debit and credit legs either both post or neither does.
 	res, err := s.ledgerClient.RecordTransaction(ctx, &pb.RecordTransactionRequest{
-		AccountId:   req.ToUserId,
-		Amount:      req.Amount,
 		Currency:    req.Currency,
-		Description: fmt.Sprintf("Transfer from %s", req.FromUserId),
-		ReferenceId: req.ReferenceId + "_credit",
+		Description: fmt.Sprintf("Transfer from %s to %s", req.FromUserId, req.ToUserId),
+		ReferenceId: req.ReferenceId,
+		ZoneId:      req.ZoneId,
+		Mode:        req.Mode,
+		Entries: []*pb.LedgerEntry{
+			{UserId: req.FromUserId, Amount: -req.Amount, Direction: "debit"},
+			{UserId: req.ToUserId, Amount: req.Amount, Direction: "credit"},
+		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("credit failed: %w", err)
+		return nil, fmt.Errorf("transfer failed: %w", err)
 	}
 
 	return &walletpb.TransactionResponse{
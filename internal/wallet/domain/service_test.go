@@ -14,9 +14,12 @@ func TestWalletService_GetWallet(t *testing.T) {
 	userID := "user-123"
 
 	mockLedger := &MockLedgerClient{
-		GetAccountFunc: func(ctx context.Context, id string) (*pb.GetAccountResponse, error) {
+		GetAccountFunc: func(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+			if req.UserId != userID || req.ZoneId != "zone-1" || req.Mode != "live" {
+				return nil, errors.New("unexpected account lookup")
+			}
 			return &pb.GetAccountResponse{
-				AccountId: id,
+				AccountId: "acc-1",
 				Balance:   1000,
 				Currency:  "USD",
 			}, nil
@@ -24,7 +27,7 @@ func TestWalletService_GetWallet(t *testing.T) {
 	}
 
 	service := NewWalletService(mockLedger)
-	wallet, err := service.GetWallet(ctx, userID)
+	wallet, err := service.GetWallet(ctx, &walletpb.GetWalletRequest{UserId: userID, ZoneId: "zone-1", Mode: "live"})
 
 	if err != nil {
 		t.Fatalf("GetWallet failed: %v", err)
@@ -44,11 +47,14 @@ func TestWalletService_TopUp(t *testing.T) {
 		Amount:      500,
 		Currency:    "USD",
 		ReferenceId: "ref-123",
+		ZoneId:      "zone-1",
+		Mode:        "live",
 	}
 
 	mockLedger := &MockLedgerClient{
 		RecordTransactionFunc: func(ctx context.Context, ledgerReq *pb.RecordTransactionRequest) (*pb.RecordTransactionResponse, error) {
-			if ledgerReq.AccountId != req.UserId || ledgerReq.Amount != req.Amount {
+			if ledgerReq.UserId != req.UserId || ledgerReq.AccountId != "" || ledgerReq.Amount != req.Amount ||
+				ledgerReq.ZoneId != req.ZoneId || ledgerReq.Mode != req.Mode {
 				return nil, errors.New("unexpected ledger request")
 			}
 			return &pb.RecordTransactionResponse{
@@ -77,22 +83,23 @@ func TestWalletService_Transfer(t *testing.T) {
 		Amount:      200,
 		Currency:    "USD",
 		ReferenceId: "ref-456",
+		ZoneId:      "zone-1",
+		Mode:        "live",
 	}
 
 	callCount := 0
 	mockLedger := &MockLedgerClient{
 		RecordTransactionFunc: func(ctx context.Context, ledgerReq *pb.RecordTransactionRequest) (*pb.RecordTransactionResponse, error) {
 			callCount++
-			if callCount == 1 {
-				// Debit
-				if ledgerReq.AccountId != req.FromUserId || ledgerReq.Amount != -req.Amount {
-					return nil, errors.New("unexpected debit request")
-				}
-			} else if callCount == 2 {
-				// Credit
-				if ledgerReq.AccountId != req.ToUserId || ledgerReq.Amount != req.Amount {
-					return nil, errors.New("unexpected credit request")
-				}
+			if ledgerReq.ReferenceId != req.ReferenceId || ledgerReq.ZoneId != req.ZoneId || len(ledgerReq.Entries) != 2 {
+				return nil, errors.New("unexpected transfer request")
+			}
+			debit, credit := ledgerReq.Entries[0], ledgerReq.Entries[1]
+			if debit.UserId != req.FromUserId || debit.Amount != -req.Amount {
+				return nil, errors.New("unexpected debit leg")
+			}
+			if credit.UserId != req.ToUserId || credit.Amount != req.Amount {
+				return nil, errors.New("unexpected credit leg")
 			}
 			return &pb.RecordTransactionResponse{
 				TransactionId: "tx-multi",
@@ -107,8 +114,8 @@ func TestWalletService_Transfer(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Transfer failed: %v", err)
 	}
-	if callCount != 2 {
-		t.Errorf("expected 2 ledger calls, got %d", callCount)
+	if callCount != 1 {
+		t.Errorf("expected 1 ledger call, got %d", callCount)
 	}
 	if res.Status != "COMPLETED" {
 		t.Errorf("expected status COMPLETED, got %s", res.Status)
@@ -14,8 +14,8 @@ func NewLedgerClient(client pb.LedgerServiceClient) *LedgerClient {
 	return &LedgerClient{client: client}
 }
 
-func (c *LedgerClient) GetAccount(ctx context.Context, accountID string) (*pb.GetAccountResponse, error) {
-	return c.client.GetAccount(ctx, &pb.GetAccountRequest{AccountId: accountID})
+func (c *LedgerClient) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	return c.client.GetAccount(ctx, req)
 }
 
 func (c *LedgerClient) RecordTransaction(ctx context.Context, req *pb.RecordTransactionRequest) (*pb.RecordTransactionResponse, error) {
@@ -16,6 +16,9 @@ type Template struct {
 type TemplateProviders struct {
 	CreateLedgerAccount func(ctx context.Context, name, accType, currency string, zoneID, mode string) error
 	CreateFlow          func(ctx context.Context, zoneID string, name string, nodes interface{}, edges interface{}) error
+	// SeedChartOfAccounts creates the ledger accounts of a named chart
+	// template (e.g. "fintech-basic") in the zone.
+	SeedChartOfAccounts func(ctx context.Context, zoneID, mode, chart string) error
 }
 
 var registry = make(map[string]Template)
@@ -60,6 +63,14 @@ func init() {
 		},
 	})
 
+	RegisterTemplate(Template{
+		Name:        "fintech-basic",
+		Description: "Payment processing with a default chart of accounts (cash, settlement clearing, customer wallets, fees).",
+		Apply: func(ctx context.Context, z *Zone, p TemplateProviders) error {
+			return p.SeedChartOfAccounts(ctx, z.ID, string(z.Mode), "fintech-basic")
+		},
+	})
+
 	RegisterTemplate(Template{
 		Name:        "marketplace",
 		Description: "Setup for marketplaces with Escrow and Fee accounts.",
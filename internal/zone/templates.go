@@ -26,6 +26,8 @@ type Template struct {
 	Webhooks    []WebhookConfig  `json:"webhooks"`
 	EventTypes  []string         `json:"eventTypes"`
 	Policies    []PolicyTemplate `json:"policies,omitempty"`
+	// ChartOfAccounts names the ledger chart template seeded into the zone.
+	ChartOfAccounts string `json:"chartOfAccounts,omitempty"`
 }
 
 // FlowTemplate represents a pre-configured flow
@@ -84,6 +86,7 @@ type TemplateApplyResult struct {
 	FlowsCreated    int       `json:"flowsCreated"`
 	WebhooksCreated int       `json:"webhooksCreated"`
 	PoliciesCreated int       `json:"policiesCreated"`
+	ChartOfAccounts string    `json:"chartOfAccounts,omitempty"` // Seeded ledger chart, if any
 	AppliedAt       time.Time `json:"appliedAt"`
 }
 
@@ -217,9 +220,10 @@ var TemplateRegistry = map[TemplateType]Template{
 		},
 	},
 	TemplateFintechBasic: {
-		Type:        TemplateFintechBasic,
-		Name:        "Fintech Basic",
-		Description: "Basic payment processing with fraud checks",
+		Type:            TemplateFintechBasic,
+		Name:            "Fintech Basic",
+		Description:     "Basic payment processing with fraud checks",
+		ChartOfAccounts: "fintech-basic",
 		Flows: []FlowTemplate{
 			{
 				Name:    "Fraud Check on Payment",
@@ -334,5 +338,17 @@ func (s *TemplateService) Apply(ctx context.Context, zoneID string, templateType
 		result.PoliciesCreated++
 	}
 
+	// Seed the ledger chart of accounts
+	if template.ChartOfAccounts != "" && s.zoneService.providers.SeedChartOfAccounts != nil {
+		zone, err := s.zoneService.GetZone(ctx, zoneID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.zoneService.providers.SeedChartOfAccounts(ctx, zone.ID, string(zone.Mode), template.ChartOfAccounts); err != nil {
+			return nil, fmt.Errorf("failed to seed chart of accounts: %w", err)
+		}
+		result.ChartOfAccounts = template.ChartOfAccounts
+	}
+
 	return result, nil
 }
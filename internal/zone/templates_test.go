@@ -165,6 +165,40 @@ func TestTemplateService_Apply(t *testing.T) {
 	})
 }
 
+func TestTemplateFintechBasic_SeedsChartOfAccounts(t *testing.T) {
+	var seeded []string
+	providers := domain.TemplateProviders{
+		SeedChartOfAccounts: func(ctx context.Context, zoneID, mode, chart string) error {
+			seeded = append(seeded, zoneID+"/"+mode+"/"+chart)
+			return nil
+		},
+	}
+	service := NewService(NewMockRepo(), providers, &MockEventPublisher{})
+	templateService := NewTemplateService(service)
+
+	z, err := service.CreateZone(context.Background(), domain.CreateZoneParams{
+		OrgID:        "org_1",
+		Name:         "Payments",
+		Mode:         domain.ModeLive,
+		TemplateName: string(TemplateFintechBasic),
+	})
+	if err != nil {
+		t.Fatalf("CreateZone: %v", err)
+	}
+	expected := z.ID + "/live/fintech-basic"
+	if len(seeded) != 1 || seeded[0] != expected {
+		t.Fatalf("Expected chart seeded as %s on zone creation, got %v", expected, seeded)
+	}
+
+	result, err := templateService.Apply(context.Background(), z.ID, TemplateFintechBasic)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if result.ChartOfAccounts != "fintech-basic" || len(seeded) != 2 {
+		t.Errorf("Expected Apply to seed the fintech-basic chart, got %q after %d seeds", result.ChartOfAccounts, len(seeded))
+	}
+}
+
 func TestTemplateEcommerce_Flows(t *testing.T) {
 	template := TemplateRegistry[TemplateEcommerce]
 
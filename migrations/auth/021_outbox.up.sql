@@ -0,0 +1,17 @@
+-- The auth and flow services share this database and its outbox.
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seq BIGSERIAL,
+    topic VARCHAR(255) NOT NULL,
+    event_type VARCHAR(255) NOT NULL DEFAULT '',
+    partition_key TEXT NOT NULL DEFAULT '',
+    payload BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(seq) WHERE processed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending_key ON outbox(partition_key, seq) WHERE processed_at IS NULL;
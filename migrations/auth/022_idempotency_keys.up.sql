@@ -0,0 +1,19 @@
+-- Idempotency keys (pkg/idempotency). A key is claimed before its request
+-- runs: the row has no response until the request finishes, and
+-- locked_until bounds how long it blocks duplicates. Keys are forgotten
+-- after expires_at.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope TEXT NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_method VARCHAR(10),
+    request_path TEXT,
+    request_hash VARCHAR(64),
+    response_body BYTEA,
+    status_code INT,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS payment_method_id;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS customer_id;
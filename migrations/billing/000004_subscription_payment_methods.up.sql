@@ -0,0 +1,4 @@
+-- Renewals are charged off-session to a customer's saved payment method in
+-- the payments service: payment_method_id, or else the customer's default.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS customer_id UUID;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS payment_method_id UUID;
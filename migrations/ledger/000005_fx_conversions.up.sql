@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate NUMERIC(24, 12) NOT NULL CHECK (rate > 0),
+    source VARCHAR(100) NOT NULL,
+    as_of TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+CREATE TABLE IF NOT EXISTS fx_conversions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    from_amount BIGINT NOT NULL,
+    to_amount BIGINT NOT NULL,
+    rate NUMERIC(24, 12) NOT NULL,
+    rate_source VARCHAR(100) NOT NULL,
+    rate_timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_conversions_transaction_id ON fx_conversions(transaction_id);
@@ -0,0 +1,11 @@
+-- Balances are maintained on the account row inside the posting transaction
+-- instead of being summed from entries on every read.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS balance BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+-- NULL means unrestricted; otherwise the balance may not drop below -overdraft_limit.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_limit BIGINT CHECK (overdraft_limit >= 0);
+
+UPDATE accounts a
+SET balance = COALESCE((SELECT SUM(e.amount) FROM entries e WHERE e.account_id = a.id), 0);
+
+UPDATE accounts SET overdraft_limit = 0 WHERE type = 'liability' AND overdraft_limit IS NULL;
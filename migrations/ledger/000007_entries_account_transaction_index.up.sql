@@ -0,0 +1,3 @@
+-- Point-in-time balances and account statements read an account's entries
+-- and join each to its transaction, whose effective_at they filter on.
+CREATE INDEX IF NOT EXISTS idx_entries_account_id_transaction_id ON entries(account_id, transaction_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS period_snapshots;
+DROP TABLE IF EXISTS accounting_periods;
+DROP INDEX IF EXISTS idx_transactions_effective_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS effective_at;
@@ -0,0 +1,41 @@
+-- Accounting date of a transaction, used for period locks and statements.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS effective_at TIMESTAMP WITH TIME ZONE;
+UPDATE transactions SET effective_at = created_at WHERE effective_at IS NULL;
+ALTER TABLE transactions ALTER COLUMN effective_at SET DEFAULT NOW();
+ALTER TABLE transactions ALTER COLUMN effective_at SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_effective_at ON transactions(effective_at);
+
+CREATE TABLE IF NOT EXISTS accounting_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    zone_id TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    closed_at TIMESTAMP WITH TIME ZONE,
+    closed_by TEXT,
+    reopened_at TIMESTAMP WITH TIME ZONE,
+    reopened_by TEXT,
+    reopen_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounting_periods_zone_range ON accounting_periods(zone_id, mode, starts_at, ends_at);
+
+-- Signed trial balance captured at each close. Rows are never updated.
+CREATE TABLE IF NOT EXISTS period_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    period_id UUID NOT NULL REFERENCES accounting_periods(id),
+    zone_id TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    trial_balance JSONB NOT NULL,
+    closed_by TEXT NOT NULL,
+    closed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    signature VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_period_snapshots_period_id ON period_snapshots(period_id, closed_at DESC);
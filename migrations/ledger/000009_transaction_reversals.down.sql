@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_reverses;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversal_reason;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reverses_transaction_id;
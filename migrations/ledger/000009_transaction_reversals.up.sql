@@ -0,0 +1,4 @@
+-- Reversals are ordinary transactions linked to the transaction they reverse.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reverses_transaction_id UUID REFERENCES transactions(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_reason TEXT;
+CREATE INDEX IF NOT EXISTS idx_transactions_reverses ON transactions(reverses_transaction_id) WHERE reverses_transaction_id IS NOT NULL;
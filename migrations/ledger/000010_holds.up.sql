@@ -0,0 +1,21 @@
+-- Funds reserved by active holds; available balance = balance - held_amount.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS held_amount BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id TEXT NOT NULL,
+    zone_id TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    reference_id VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    transaction_id UUID REFERENCES transactions(id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_holds_account ON holds(account_id);
+CREATE INDEX IF NOT EXISTS idx_holds_active_expiry ON holds(expires_at) WHERE status = 'active';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_accounts_zone_user;
+DROP INDEX IF EXISTS idx_accounts_parent;
+DROP INDEX IF EXISTS idx_accounts_zone_code;
+ALTER TABLE accounts DROP COLUMN IF EXISTS parent_id;
+ALTER TABLE accounts DROP COLUMN IF EXISTS code;
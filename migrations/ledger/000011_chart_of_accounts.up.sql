@@ -0,0 +1,8 @@
+-- Chart of accounts: accounts get an optional code (e.g. 1000 for Cash) that
+-- is unique per zone, mode and currency, and may sit under a parent account.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS code VARCHAR(50);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES accounts(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_zone_code ON accounts(zone_id, mode, currency, code) WHERE code IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_accounts_parent ON accounts(parent_id);
+CREATE INDEX IF NOT EXISTS idx_accounts_zone_user ON accounts(zone_id, mode, user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reconciliation_rules;
+DROP TABLE IF EXISTS reconciliation_match_entries;
+DROP TABLE IF EXISTS bank_statement_lines;
+DROP TABLE IF EXISTS reconciliation_matches;
+DROP TABLE IF EXISTS bank_statements;
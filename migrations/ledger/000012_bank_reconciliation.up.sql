@@ -0,0 +1,67 @@
+-- Bank statements imported for reconciling ledger accounts against the bank.
+CREATE TABLE IF NOT EXISTS bank_statements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    zone_id TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    format VARCHAR(20) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    bank_account VARCHAR(255) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL,
+    opening_balance BIGINT,
+    closing_balance BIGINT,
+    period_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_to TIMESTAMP WITH TIME ZONE NOT NULL,
+    line_count INT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64) NOT NULL,
+    imported_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (account_id, checksum)
+);
+
+CREATE TABLE IF NOT EXISTS reconciliation_matches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    zone_id TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    rule VARCHAR(20) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    adjustment_reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS bank_statement_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    statement_id UUID NOT NULL REFERENCES bank_statements(id),
+    zone_id TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    line_no INT NOT NULL,
+    booking_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    value_date TIMESTAMP WITH TIME ZONE,
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    bank_reference VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    counterparty TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'unmatched',
+    match_id UUID REFERENCES reconciliation_matches(id),
+    UNIQUE (statement_id, line_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_unmatched ON bank_statement_lines(account_id, booking_date) WHERE status = 'unmatched';
+
+-- Ledger entries are immutable, so their match is recorded alongside. An
+-- entry can be part of one match only.
+CREATE TABLE IF NOT EXISTS reconciliation_match_entries (
+    match_id UUID NOT NULL REFERENCES reconciliation_matches(id),
+    entry_id UUID NOT NULL UNIQUE REFERENCES entries(id),
+    PRIMARY KEY (match_id, entry_id)
+);
+
+CREATE TABLE IF NOT EXISTS reconciliation_rules (
+    account_id UUID PRIMARY KEY REFERENCES accounts(id),
+    rules JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,54 @@
+-- Audit runs comparing payments with their ledger postings. Each run covers
+-- the changes since the watermarks left by the previous completed run.
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trigger VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    payments_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    ledger_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    until TIMESTAMP WITH TIME ZONE NOT NULL,
+    payments_checked INT NOT NULL DEFAULT 0,
+    transactions_checked INT NOT NULL DEFAULT 0,
+    discrepancy_count INT NOT NULL DEFAULT 0,
+    resolved_count INT NOT NULL DEFAULT 0,
+    summary JSONB NOT NULL DEFAULT '{}',
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Only one run at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliation_runs_running ON reconciliation_runs(status) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_reconciliation_runs_started_at ON reconciliation_runs(started_at DESC);
+
+CREATE TABLE IF NOT EXISTS reconciliation_watermarks (
+    name VARCHAR(50) PRIMARY KEY,
+    position TIMESTAMP WITH TIME ZONE NOT NULL,
+    run_id UUID REFERENCES reconciliation_runs(id),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS reconciliation_discrepancies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(id),
+    zone_id TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    reference_id VARCHAR(255) NOT NULL,
+    payment_id VARCHAR(255) NOT NULL DEFAULT '',
+    payment_status VARCHAR(50) NOT NULL DEFAULT '',
+    transaction_id UUID,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    expected_amount BIGINT,
+    actual_amount BIGINT,
+    detail TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_run_id UUID REFERENCES reconciliation_runs(id)
+);
+
+-- A discrepancy found again by later runs is not reported twice while open.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliation_discrepancies_open ON reconciliation_discrepancies(reference_id, category) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_reconciliation_discrepancies_zone ON reconciliation_discrepancies(zone_id, mode, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_discrepancies_run ON reconciliation_discrepancies(run_id);
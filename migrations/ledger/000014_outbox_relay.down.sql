@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_outbox_pending_key;
+DROP INDEX IF EXISTS idx_outbox_pending;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS last_error;
+ALTER TABLE outbox DROP COLUMN IF EXISTS available_at;
+ALTER TABLE outbox DROP COLUMN IF EXISTS attempts;
+ALTER TABLE outbox DROP COLUMN IF EXISTS partition_key;
+ALTER TABLE outbox DROP COLUMN IF EXISTS seq;
+ALTER TABLE outbox ALTER COLUMN topic DROP DEFAULT;
@@ -0,0 +1,12 @@
+-- Relay bookkeeping for the outbox. Events are published in seq order per
+-- partition_key; a failed event waits until available_at and holds back the
+-- later events of its key.
+ALTER TABLE outbox ALTER COLUMN topic SET DEFAULT 'ledger-events';
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS seq BIGSERIAL;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS partition_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(seq) WHERE processed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending_key ON outbox(partition_key, seq) WHERE processed_at IS NULL;
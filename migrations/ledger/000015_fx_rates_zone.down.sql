@@ -0,0 +1,5 @@
+DELETE FROM fx_rates WHERE zone_id <> '';
+ALTER TABLE fx_rates DROP CONSTRAINT IF EXISTS fx_rates_pkey;
+ALTER TABLE fx_rates ADD PRIMARY KEY (base_currency, quote_currency);
+ALTER TABLE fx_rates DROP COLUMN IF EXISTS mode;
+ALTER TABLE fx_rates DROP COLUMN IF EXISTS zone_id;
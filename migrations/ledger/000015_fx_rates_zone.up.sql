@@ -0,0 +1,6 @@
+-- Rates set through the API belong to the caller's zone. Rows with an empty
+-- zone are platform-wide and apply wherever a zone has no rate of its own.
+ALTER TABLE fx_rates ADD COLUMN IF NOT EXISTS zone_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE fx_rates ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT '';
+ALTER TABLE fx_rates DROP CONSTRAINT IF EXISTS fx_rates_pkey;
+ALTER TABLE fx_rates ADD PRIMARY KEY (zone_id, mode, base_currency, quote_currency);
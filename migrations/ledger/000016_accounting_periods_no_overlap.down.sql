@@ -0,0 +1 @@
+ALTER TABLE accounting_periods DROP CONSTRAINT IF EXISTS accounting_periods_no_overlap;
@@ -0,0 +1,6 @@
+-- Periods of a zone and mode may not overlap. The service checks this before
+-- inserting; the constraint catches concurrent creates that both pass it.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE accounting_periods ADD CONSTRAINT accounting_periods_no_overlap
+    EXCLUDE USING gist (zone_id WITH =, mode WITH =, tstzrange(starts_at, ends_at) WITH &&);
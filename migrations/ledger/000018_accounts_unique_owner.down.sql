@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_accounts_zone_user;
+CREATE INDEX IF NOT EXISTS idx_accounts_zone_user ON accounts(zone_id, mode, user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,6 @@
+-- An owner has a single account per zone, mode and currency, so concurrent
+-- first postings cannot open two. user_id holds either a user ID or a
+-- connected account ID; the two never collide. Zones that already have
+-- duplicates must merge them by hand before this applies.
+DROP INDEX IF EXISTS idx_accounts_zone_user;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_zone_user ON accounts(zone_id, mode, user_id, currency) WHERE user_id IS NOT NULL;
@@ -0,0 +1,2 @@
+-- The reconciler reads payments incrementally by update time.
+CREATE INDEX IF NOT EXISTS idx_payment_intents_updated_at ON payment_intents(updated_at, id);
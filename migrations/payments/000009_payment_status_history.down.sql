@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_status_history;
+ALTER TABLE payment_intents DROP CONSTRAINT IF EXISTS payment_intents_status_check;
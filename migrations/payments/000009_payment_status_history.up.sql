@@ -0,0 +1,32 @@
+-- Payment intents follow a fixed state machine with lowercase statuses.
+-- Earlier code wrote uppercase statuses: FAILED meant the attempt failed and
+-- the intent can take another payment method, and CANCELLED was also used
+-- for refunds, which cannot be told apart now, so it becomes canceled.
+UPDATE payment_intents SET status = CASE UPPER(status)
+        WHEN 'CREATED' THEN 'requires_payment_method'
+        WHEN 'FAILED' THEN 'requires_payment_method'
+        WHEN 'PROCESSING' THEN 'processing'
+        WHEN 'SUCCEEDED' THEN 'succeeded'
+        WHEN 'CANCELLED' THEN 'canceled'
+        ELSE LOWER(status)
+    END
+WHERE status <> LOWER(status) OR UPPER(status) IN ('FAILED', 'CREATED', 'CANCELLED');
+
+ALTER TABLE payment_intents ADD CONSTRAINT payment_intents_status_check CHECK (status IN (
+    'requires_payment_method', 'requires_confirmation', 'requires_action', 'processing',
+    'requires_capture', 'succeeded', 'canceled', 'partially_refunded', 'refunded'));
+
+-- One row per status change, written in the same transaction as the change
+-- and its outbox event. from_status is NULL for the row recording creation.
+CREATE TABLE IF NOT EXISTS payment_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seq BIGSERIAL,
+    payment_intent_id UUID NOT NULL REFERENCES payment_intents(id),
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_status_history_intent ON payment_status_history(payment_intent_id, seq);
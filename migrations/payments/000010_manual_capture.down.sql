@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_payment_intents_capture_before;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS capture_before;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS authorization_id;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS amount_received;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS amount_capturable;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS capture_method;
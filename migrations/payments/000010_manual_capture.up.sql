@@ -0,0 +1,16 @@
+-- Manual capture: confirming a manual intent only authorizes it, and a
+-- later capture settles up to amount_capturable. authorization_id is the
+-- bank's reference for the hold, which is voided after capture_before.
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS capture_method VARCHAR(20) NOT NULL DEFAULT 'automatic'
+    CHECK (capture_method IN ('automatic', 'manual'));
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS amount_capturable BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS amount_received BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS authorization_id VARCHAR(255);
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS capture_before TIMESTAMP WITH TIME ZONE;
+
+-- Intents settled before this migration received their full amount.
+UPDATE payment_intents SET amount_received = amount
+WHERE status IN ('succeeded', 'partially_refunded', 'refunded');
+
+CREATE INDEX IF NOT EXISTS idx_payment_intents_capture_before ON payment_intents(capture_before)
+    WHERE status = 'requires_capture';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS refunds;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS amount_refunded;
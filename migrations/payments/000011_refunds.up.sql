@@ -0,0 +1,30 @@
+-- Refunds return part or all of a payment's amount_received. A pending
+-- refund holds its amount while its ledger reversal, posted under
+-- ledger_reference, is in flight; amount_refunded sums the succeeded ones.
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS amount_refunded BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_intent_id UUID NOT NULL REFERENCES payment_intents(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    reason VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    failure_reason TEXT,
+    ledger_reference VARCHAR(255) NOT NULL UNIQUE,
+    ledger_transaction_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_payment_intent ON refunds(payment_intent_id, created_at);
+
+-- Full refunds made before refunds were stored were reversed by the
+-- ledger's event consumer under refund_<payment id>.
+INSERT INTO refunds (payment_intent_id, amount, currency, status, ledger_reference, created_at, updated_at)
+SELECT id, amount_received, currency, 'succeeded', 'refund_' || id, updated_at, updated_at
+FROM payment_intents
+WHERE status = 'refunded' AND amount_received > 0
+ON CONFLICT (ledger_reference) DO NOTHING;
+
+UPDATE payment_intents SET amount_refunded = amount_received WHERE status = 'refunded';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payment_intents_processor_txn;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS settled_at;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS processor_transaction_id;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS processor;
@@ -0,0 +1,9 @@
+-- The processor an intent was confirmed with, its reference for the charge
+-- or capture, and when the processor reported the funds settled.
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS processor VARCHAR(50);
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS processor_transaction_id VARCHAR(255);
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS settled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_payment_intents_processor_txn
+    ON payment_intents(processor, processor_transaction_id)
+    WHERE processor_transaction_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payment_intents_action_expiry;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS action_expires_at;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS next_action_url;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS challenge_id;
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS client_secret;
@@ -0,0 +1,11 @@
+-- Authentication challenges: the customer is redirected to next_action_url
+-- and the intent's client secret completes the action before
+-- action_expires_at.
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS client_secret VARCHAR(255);
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS challenge_id VARCHAR(255);
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS next_action_url TEXT;
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS action_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_payment_intents_action_expiry
+    ON payment_intents(action_expires_at)
+    WHERE status = 'requires_action';
@@ -0,0 +1,29 @@
+-- Disputes are chargebacks reported by the processor a payment went
+-- through. The intent's user, zone and mode are copied so dispute events
+-- can be booked by the ledger on their own.
+CREATE TABLE IF NOT EXISTS disputes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_intent_id UUID NOT NULL REFERENCES payment_intents(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    reason VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'needs_response'
+        CHECK (status IN ('needs_response', 'under_review', 'won', 'lost')),
+    user_id VARCHAR(255),
+    zone_id VARCHAR(255),
+    mode VARCHAR(10),
+    processor VARCHAR(50) NOT NULL,
+    processor_dispute_id VARCHAR(255) NOT NULL,
+    evidence JSONB,
+    evidence_due_by TIMESTAMP WITH TIME ZONE NOT NULL,
+    evidence_submitted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (processor, processor_dispute_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_disputes_payment_intent ON disputes(payment_intent_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_disputes_evidence_due
+    ON disputes(evidence_due_by)
+    WHERE status = 'needs_response';
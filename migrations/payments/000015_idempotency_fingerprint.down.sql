@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DELETE FROM idempotency_keys WHERE status_code IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN status_code SET NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN response_body SET NOT NULL;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS request_hash;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS request_path;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS request_method;
@@ -0,0 +1,17 @@
+-- Idempotency keys remember the request they were first used for, so a key
+-- reused with other parameters is rejected. A key is claimed before its
+-- request runs: the row has no response until the request finishes, and
+-- locked_until bounds how long it blocks duplicates. Keys are forgotten
+-- after expires_at.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS request_method VARCHAR(10);
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS request_path TEXT;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS request_hash VARCHAR(64);
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE idempotency_keys ALTER COLUMN response_body DROP NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN status_code DROP NOT NULL;
+
+UPDATE idempotency_keys SET expires_at = COALESCE(created_at, NOW()) + INTERVAL '24 hours' WHERE expires_at IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,4 @@
+ALTER TABLE idempotency_keys ALTER COLUMN response_body TYPE TEXT USING convert_from(response_body, 'UTF8');
+DELETE FROM idempotency_keys WHERE scope !~ '^[0-9a-fA-F-]{36}$';
+ALTER TABLE idempotency_keys ALTER COLUMN scope TYPE UUID USING scope::uuid;
+ALTER TABLE idempotency_keys RENAME COLUMN scope TO user_id;
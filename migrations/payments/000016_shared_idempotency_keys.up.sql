@@ -0,0 +1,6 @@
+-- Idempotency keys move to pkg/idempotency, whose table scopes keys by an
+-- opaque scope (the user ID for HTTP requests, the method for gRPC calls)
+-- and stores responses as bytes.
+ALTER TABLE idempotency_keys RENAME COLUMN user_id TO scope;
+ALTER TABLE idempotency_keys ALTER COLUMN scope TYPE TEXT;
+ALTER TABLE idempotency_keys ALTER COLUMN response_body TYPE BYTEA USING convert_to(response_body, 'UTF8');
@@ -0,0 +1,42 @@
+-- Payment links are reusable: every visit opens a new checkout session
+-- with the link's line items.
+CREATE TABLE IF NOT EXISTS payment_links (
+    id UUID PRIMARY KEY,
+    line_items JSONB NOT NULL,
+    amount_total BIGINT NOT NULL CHECK (amount_total > 0),
+    currency VARCHAR(3) NOT NULL,
+    success_url TEXT NOT NULL,
+    cancel_url TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    user_id VARCHAR(255),
+    zone_id VARCHAR(255),
+    mode VARCHAR(10),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- A checkout session gets its payment intent on the customer's first
+-- payment attempt; an intent pays for at most one session.
+CREATE TABLE IF NOT EXISTS checkout_sessions (
+    id UUID PRIMARY KEY,
+    payment_link_id UUID REFERENCES payment_links(id),
+    payment_intent_id UUID UNIQUE REFERENCES payment_intents(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'complete', 'expired')),
+    line_items JSONB NOT NULL,
+    amount_total BIGINT NOT NULL CHECK (amount_total > 0),
+    currency VARCHAR(3) NOT NULL,
+    success_url TEXT NOT NULL,
+    cancel_url TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    user_id VARCHAR(255),
+    zone_id VARCHAR(255),
+    mode VARCHAR(10),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkout_sessions_expires
+    ON checkout_sessions(expires_at)
+    WHERE status = 'open';
@@ -0,0 +1,5 @@
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS customer_id;
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_default_payment_method_fkey;
+DROP TABLE IF EXISTS payment_methods;
+DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS tenant_keys;
@@ -0,0 +1,49 @@
+-- Tenant keys encrypt saved card numbers, one key per zone. The keys are
+-- stored encrypted with the service's master key.
+CREATE TABLE IF NOT EXISTS tenant_keys (
+    tenant_id VARCHAR(255) PRIMARY KEY,
+    encrypted_key BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS customers (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255),
+    name VARCHAR(255),
+    default_payment_method_id UUID,
+    user_id VARCHAR(255),
+    zone_id VARCHAR(255),
+    mode VARCHAR(10),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Only the card's brand, last four digits and expiry are stored in the
+-- clear; the number is encrypted with the zone's key and the security code
+-- is never stored.
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id UUID PRIMARY KEY,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('card')),
+    card_brand VARCHAR(20) NOT NULL,
+    card_last4 VARCHAR(4) NOT NULL,
+    card_exp_month SMALLINT NOT NULL CHECK (card_exp_month BETWEEN 1 AND 12),
+    card_exp_year SMALLINT NOT NULL,
+    encrypted_card TEXT NOT NULL,
+    customer_id UUID REFERENCES customers(id),
+    detached_at TIMESTAMP WITH TIME ZONE,
+    user_id VARCHAR(255),
+    zone_id VARCHAR(255),
+    mode VARCHAR(10),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE customers
+    ADD CONSTRAINT customers_default_payment_method_fkey
+    FOREIGN KEY (default_payment_method_id) REFERENCES payment_methods(id);
+
+CREATE INDEX IF NOT EXISTS idx_payment_methods_customer
+    ON payment_methods(customer_id)
+    WHERE customer_id IS NOT NULL;
+
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES customers(id);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_refunds_pending ON refunds(created_at) WHERE status = 'pending';
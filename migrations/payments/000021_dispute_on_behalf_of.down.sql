@@ -0,0 +1 @@
+ALTER TABLE disputes DROP COLUMN IF EXISTS on_behalf_of;
@@ -0,0 +1,7 @@
+-- Connected account a disputed marketplace payment was made for. The ledger
+-- withholds the disputed amount from that account's balance.
+ALTER TABLE disputes ADD COLUMN IF NOT EXISTS on_behalf_of UUID;
+
+UPDATE disputes d SET on_behalf_of = p.on_behalf_of
+FROM payment_intents p
+WHERE p.id = d.payment_intent_id AND d.on_behalf_of IS NULL;
@@ -14,7 +14,9 @@ const (
 	CodeUnauthorized        Code = "UNAUTHORIZED"
 	CodeForbidden           Code = "FORBIDDEN"
 	CodeNotFound            Code = "NOT_FOUND"
+	CodeMethodNotAllowed    Code = "METHOD_NOT_ALLOWED"
 	CodeConflict            Code = "CONFLICT"
+	CodePayloadTooLarge     Code = "PAYLOAD_TOO_LARGE"
 	CodeRateLimitExceeded   Code = "RATE_LIMIT_EXCEEDED"
 	CodeValidationFailed    Code = "VALIDATION_FAILED"
 	CodeInternalError       Code = "INTERNAL_ERROR"
@@ -79,11 +81,28 @@ func NotFound(message string) *APIError {
 	return &APIError{Code: CodeNotFound, Message: message, HTTPStatus: http.StatusNotFound}
 }
 
+// MethodNotAllowed creates a 405 Method Not Allowed error.
+func MethodNotAllowed(message string) *APIError {
+	return &APIError{Code: CodeMethodNotAllowed, Message: message, HTTPStatus: http.StatusMethodNotAllowed}
+}
+
 // Conflict creates a 409 Conflict error.
 func Conflict(message string) *APIError {
 	return &APIError{Code: CodeConflict, Message: message, HTTPStatus: http.StatusConflict}
 }
 
+// PayloadTooLarge creates a 413 Request Entity Too Large error.
+func PayloadTooLarge(message string) *APIError {
+	return &APIError{Code: CodePayloadTooLarge, Message: message, HTTPStatus: http.StatusRequestEntityTooLarge}
+}
+
+// IdempotencyConflict creates an error for a request whose idempotency key
+// cannot be used: 409 Conflict while the key's first request is in flight,
+// 422 Unprocessable Entity when the key was used for a different request.
+func IdempotencyConflict(message string, status int) *APIError {
+	return &APIError{Code: CodeIdempotencyConflict, Message: message, HTTPStatus: status}
+}
+
 // RateLimited creates a 429 Too Many Requests error.
 func RateLimited(retryAfter string) *APIError {
 	return &APIError{
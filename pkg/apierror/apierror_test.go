@@ -95,6 +95,16 @@ func TestNotFound(t *testing.T) {
 	}
 }
 
+func TestMethodNotAllowed(t *testing.T) {
+	e := apierror.MethodNotAllowed("use POST")
+	if e.HTTPStatus != http.StatusMethodNotAllowed {
+		t.Errorf("HTTPStatus: got %d, want %d", e.HTTPStatus, http.StatusMethodNotAllowed)
+	}
+	if e.Code != apierror.CodeMethodNotAllowed {
+		t.Errorf("Code: got %q, want %q", e.Code, apierror.CodeMethodNotAllowed)
+	}
+}
+
 func TestConflict(t *testing.T) {
 	e := apierror.Conflict("already exists")
 	if e.HTTPStatus != http.StatusConflict {
@@ -105,6 +115,16 @@ func TestConflict(t *testing.T) {
 	}
 }
 
+func TestIdempotencyConflict(t *testing.T) {
+	e := apierror.IdempotencyConflict("key reused", http.StatusUnprocessableEntity)
+	if e.HTTPStatus != http.StatusUnprocessableEntity {
+		t.Errorf("HTTPStatus: got %d, want %d", e.HTTPStatus, http.StatusUnprocessableEntity)
+	}
+	if e.Code != apierror.CodeIdempotencyConflict {
+		t.Errorf("Code: got %q, want %q", e.Code, apierror.CodeIdempotencyConflict)
+	}
+}
+
 func TestRateLimited(t *testing.T) {
 	e := apierror.RateLimited("30")
 	if e.HTTPStatus != http.StatusTooManyRequests {
@@ -133,6 +153,16 @@ func TestValidationFailed(t *testing.T) {
 	}
 }
 
+func TestPayloadTooLarge(t *testing.T) {
+	e := apierror.PayloadTooLarge("body too large")
+	if e.HTTPStatus != http.StatusRequestEntityTooLarge {
+		t.Errorf("HTTPStatus: got %d, want %d", e.HTTPStatus, http.StatusRequestEntityTooLarge)
+	}
+	if e.Code != apierror.CodePayloadTooLarge {
+		t.Errorf("Code: got %q, want %q", e.Code, apierror.CodePayloadTooLarge)
+	}
+}
+
 func TestInternal(t *testing.T) {
 	e := apierror.Internal("something went wrong")
 	if e.HTTPStatus != http.StatusInternalServerError {
@@ -212,6 +242,7 @@ func TestWrite_SetsStatusCode(t *testing.T) {
 		{"Unauthorized", apierror.Unauthorized("x"), http.StatusUnauthorized},
 		{"Forbidden", apierror.Forbidden("x"), http.StatusForbidden},
 		{"NotFound", apierror.NotFound("x"), http.StatusNotFound},
+		{"MethodNotAllowed", apierror.MethodNotAllowed("x"), http.StatusMethodNotAllowed},
 		{"Conflict", apierror.Conflict("x"), http.StatusConflict},
 		{"RateLimited", apierror.RateLimited("60"), http.StatusTooManyRequests},
 		{"ValidationFailed", apierror.ValidationFailed("x", nil), http.StatusUnprocessableEntity},
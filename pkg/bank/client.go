@@ -3,7 +3,11 @@ package bank
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Status string
@@ -11,6 +15,9 @@ type Status string
 const (
 	StatusSuccess Status = "succeeded"
 	StatusFailed  Status = "failed"
+	// StatusRequiresAction means the issuer asks the cardholder to
+	// authenticate before the operation goes ahead.
+	StatusRequiresAction Status = "requires_action"
 )
 
 // TransactionResult represents the outcome of a bank transaction.
@@ -18,19 +25,76 @@ type TransactionResult struct {
 	TransactionID string
 	Status        Status
 	ErrorCode     string
+	// ChallengeID and RedirectURL identify the authentication challenge of
+	// a result that requires action.
+	ChallengeID string
+	RedirectURL string
 }
 
 // Client defines the interface for communicating with a bank.
 type Client interface {
+	// Charge authorizes and settles amount in one step.
 	Charge(ctx context.Context, amount int64, currency, cardToken string) (*TransactionResult, error)
+	// Authorize holds amount on the card without settling it. The result's
+	// TransactionID identifies the authorization for Capture and Void.
+	Authorize(ctx context.Context, amount int64, currency, cardToken string) (*TransactionResult, error)
+	// Capture settles up to the authorized amount and releases the rest.
+	// An authorization is captured at most once.
+	Capture(ctx context.Context, authorizationID string, amount int64) (*TransactionResult, error)
+	// Void releases an authorization that has not been captured.
+	Void(ctx context.Context, authorizationID string) (*TransactionResult, error)
+	// CompleteChallenge resumes the charge or authorization a challenge
+	// held back, once the cardholder went through it. The result still
+	// requires action while the cardholder has not finished.
+	CompleteChallenge(ctx context.Context, challengeID string) (*TransactionResult, error)
+	// Refund returns amount of a charge or capture to the cardholder, up
+	// to what was charged. refundID is the caller's reference for the
+	// refund; refunding under the same refundID again returns the first
+	// result instead of refunding twice.
+	Refund(ctx context.Context, transactionID, refundID string, amount int64) (*TransactionResult, error)
+}
+
+var (
+	ErrAuthorizationNotFound = errors.New("authorization not found")
+	ErrAuthorizationClosed   = errors.New("authorization already captured or voided")
+	// ErrChallengeNotFound is returned for a challenge the processor did
+	// not issue or already completed.
+	ErrChallengeNotFound = errors.New("challenge not found")
+	// ErrTransactionNotFound is returned when refunding a charge or
+	// capture the processor did not make.
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrNetwork is returned when the processor could not be reached. The
+	// request did not take effect and may be retried.
+	ErrNetwork = errors.New("processor unreachable")
+)
+
+type authorizationState string
+
+const (
+	authorizationOpen     authorizationState = "open"
+	authorizationCaptured authorizationState = "captured"
+	authorizationVoided   authorizationState = "voided"
+)
+
+type authorization struct {
+	amount   int64
+	currency string
+	state    authorizationState
 }
 
-// MockClient is a mock implementation of the Bank Client.
-type MockClient struct{}
+// MockClient is a mock implementation of the Bank Client. Authorizations
+// are kept in memory.
+type MockClient struct {
+	// Latency is added to every call to simulate the network.
+	Latency time.Duration
+
+	mu             sync.Mutex
+	authorizations map[string]*authorization
+}
 
 // NewMockClient creates a new instance of MockClient.
 func NewMockClient() *MockClient {
-	return &MockClient{}
+	return &MockClient{Latency: 500 * time.Millisecond}
 }
 
 // Charge simulates a credit card charge.
@@ -40,9 +104,89 @@ func NewMockClient() *MockClient {
 // - cardToken "tok_mastercard" -> success
 // - other tokens -> failure
 func (m *MockClient) Charge(ctx context.Context, amount int64, currency, cardToken string) (*TransactionResult, error) {
-	// Simulate network latency
-	time.Sleep(500 * time.Millisecond)
+	time.Sleep(m.Latency)
+	return m.decide(amount, cardToken, "txn_")
+}
+
+// Authorize simulates an authorization hold, with the same card tokens as
+// Charge.
+func (m *MockClient) Authorize(ctx context.Context, amount int64, currency, cardToken string) (*TransactionResult, error) {
+	time.Sleep(m.Latency)
+	result, err := m.decide(amount, cardToken, "auth_")
+	if err != nil || result.Status != StatusSuccess {
+		return result, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.authorizations == nil {
+		m.authorizations = map[string]*authorization{}
+	}
+	m.authorizations[result.TransactionID] = &authorization{amount: amount, currency: currency, state: authorizationOpen}
+	return result, nil
+}
+
+func (m *MockClient) Capture(ctx context.Context, authorizationID string, amount int64) (*TransactionResult, error) {
+	time.Sleep(m.Latency)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	auth, ok := m.authorizations[authorizationID]
+	if !ok {
+		return nil, ErrAuthorizationNotFound
+	}
+	if auth.state != authorizationOpen {
+		return nil, ErrAuthorizationClosed
+	}
+	if amount <= 0 || amount > auth.amount {
+		return nil, errors.New("invalid capture amount")
+	}
+	auth.state = authorizationCaptured
+	return &TransactionResult{
+		TransactionID: "txn_" + GenerateRandomID(),
+		Status:        StatusSuccess,
+	}, nil
+}
+
+func (m *MockClient) Void(ctx context.Context, authorizationID string) (*TransactionResult, error) {
+	time.Sleep(m.Latency)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	auth, ok := m.authorizations[authorizationID]
+	if !ok {
+		return nil, ErrAuthorizationNotFound
+	}
+	if auth.state != authorizationOpen {
+		return nil, ErrAuthorizationClosed
+	}
+	auth.state = authorizationVoided
+	return &TransactionResult{
+		TransactionID: authorizationID,
+		Status:        StatusSuccess,
+	}, nil
+}
+
+// CompleteChallenge fails: MockClient never asks for authentication.
+func (m *MockClient) CompleteChallenge(ctx context.Context, challengeID string) (*TransactionResult, error) {
+	return nil, ErrChallengeNotFound
+}
+
+// Refund always succeeds; the refund's ID is derived from refundID, so
+// replays return the same refund.
+func (m *MockClient) Refund(ctx context.Context, transactionID, refundID string, amount int64) (*TransactionResult, error) {
+	time.Sleep(m.Latency)
+	if amount <= 0 {
+		return nil, errors.New("invalid refund amount")
+	}
+	return &TransactionResult{
+		TransactionID: "re_" + refundID,
+		Status:        StatusSuccess,
+	}, nil
+}
 
+// decide applies the card token rules shared by Charge and Authorize.
+func (m *MockClient) decide(amount int64, cardToken, idPrefix string) (*TransactionResult, error) {
 	// Validation
 	if amount <= 0 {
 		return nil, errors.New("invalid amount")
@@ -52,25 +196,23 @@ func (m *MockClient) Charge(ctx context.Context, amount int64, currency, cardTok
 	switch cardToken {
 	case "tok_visa", "tok_mastercard":
 		return &TransactionResult{
-			TransactionID: "txn_" + GenerateRandomID(),
-			Status:        "succeeded",
+			TransactionID: idPrefix + GenerateRandomID(),
+			Status:        StatusSuccess,
 		}, nil
 	case "tok_declined":
 		return &TransactionResult{
-			Status:    "failed",
+			Status:    StatusFailed,
 			ErrorCode: "card_declined",
 		}, nil
 	default:
-		// Default to success for development ease, or error?
-		// Let's default to error to be safe and force correct token usage.
+		// Default to error to be safe and force correct token usage.
 		return &TransactionResult{
-			Status:    "failed",
+			Status:    StatusFailed,
 			ErrorCode: "invalid_card_token",
 		}, nil
 	}
 }
 
 func GenerateRandomID() string {
-	// In real app use crypto/rand or uuid
-	return time.Now().Format("20060102150405")
+	return strings.ReplaceAll(uuid.New().String(), "-", "")
 }
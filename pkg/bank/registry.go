@@ -0,0 +1,99 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownProcessor is returned for a processor that was not registered.
+var ErrUnknownProcessor = errors.New("unknown payment processor")
+
+type processor struct {
+	client        Client
+	webhookSecret string
+}
+
+// Registry holds the payment processor adapters and the processor each
+// zone uses. Zones without an assignment use the fallback processor. It is
+// set up at startup and read-only afterwards.
+type Registry struct {
+	processors map[string]processor
+	zones      map[string]string
+	fallback   string
+}
+
+// NewRegistry creates a registry whose unassigned zones use fallback.
+func NewRegistry(fallback string) *Registry {
+	return &Registry{
+		processors: map[string]processor{},
+		zones:      map[string]string{},
+		fallback:   fallback,
+	}
+}
+
+// Register adds a processor adapter. webhookSecret signs the webhooks the
+// processor sends; it may be empty for processors that send none.
+func (r *Registry) Register(name string, client Client, webhookSecret string) {
+	r.processors[name] = processor{client: client, webhookSecret: webhookSecret}
+}
+
+// AssignZone routes a zone's payments to a registered processor.
+func (r *Registry) AssignZone(zoneID, name string) error {
+	if _, ok := r.processors[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownProcessor, name)
+	}
+	r.zones[zoneID] = name
+	return nil
+}
+
+// ForZone returns the processor new payments of a zone go to.
+func (r *Registry) ForZone(zoneID string) (string, Client, error) {
+	name, ok := r.zones[zoneID]
+	if !ok {
+		name = r.fallback
+	}
+	client, err := r.Get(name)
+	return name, client, err
+}
+
+// Get returns a processor by name. Payments made before processors were
+// recorded have no name and use the fallback.
+func (r *Registry) Get(name string) (Client, error) {
+	if name == "" {
+		name = r.fallback
+	}
+	p, ok := r.processors[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProcessor, name)
+	}
+	return p.client, nil
+}
+
+// WebhookSecret returns the secret a processor signs its webhooks with.
+func (r *Registry) WebhookSecret(name string) (string, error) {
+	p, ok := r.processors[name]
+	if !ok || p.webhookSecret == "" {
+		return "", fmt.Errorf("%w: %s", ErrUnknownProcessor, name)
+	}
+	return p.webhookSecret, nil
+}
+
+// ParseZoneAssignments parses zone assignments written as
+// "zone_a=simulator,zone_b=acme".
+func ParseZoneAssignments(s string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		zoneID, name, ok := strings.Cut(pair, "=")
+		zoneID, name = strings.TrimSpace(zoneID), strings.TrimSpace(name)
+		if !ok || zoneID == "" || name == "" {
+			return nil, fmt.Errorf("invalid zone assignment %q, want zone=processor", pair)
+		}
+		out[zoneID] = name
+	}
+	return out, nil
+}
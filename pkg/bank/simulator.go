@@ -0,0 +1,511 @@
+package bank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+// Outcome is how the simulator answers a charge or authorization.
+type Outcome string
+
+const (
+	OutcomeApprove      Outcome = "approve"
+	OutcomeDecline      Outcome = "decline"
+	OutcomeNetworkError Outcome = "network_error"
+	// OutcomeChallenge asks for cardholder authentication first; the
+	// operation goes ahead once the challenge is passed.
+	OutcomeChallenge Outcome = "challenge"
+)
+
+// Scenario is the deterministic answer to a card token.
+type Scenario struct {
+	Outcome     Outcome
+	DeclineCode string
+}
+
+// TestTokens are the card tokens the simulator always answers the same way.
+// Test card numbers, which saved payment methods are charged with, are
+// answered like the tokens.
+var TestTokens = map[string]Scenario{
+	"tok_visa":               {Outcome: OutcomeApprove},
+	"tok_mastercard":         {Outcome: OutcomeApprove},
+	"tok_declined":           {Outcome: OutcomeDecline, DeclineCode: "card_declined"},
+	"tok_insufficient_funds": {Outcome: OutcomeDecline, DeclineCode: "insufficient_funds"},
+	"tok_expired_card":       {Outcome: OutcomeDecline, DeclineCode: "expired_card"},
+	"tok_fraudulent":         {Outcome: OutcomeDecline, DeclineCode: "fraudulent"},
+	"tok_network_error":      {Outcome: OutcomeNetworkError},
+	"tok_threeds_required":   {Outcome: OutcomeChallenge},
+	"4242424242424242":       {Outcome: OutcomeApprove},
+	"5555555555554444":       {Outcome: OutcomeApprove},
+	"4000000000000002":       {Outcome: OutcomeDecline, DeclineCode: "card_declined"},
+	"4000000000009995":       {Outcome: OutcomeDecline, DeclineCode: "insufficient_funds"},
+	"4000000000003220":       {Outcome: OutcomeChallenge},
+}
+
+// SimulatorConfig configures a Simulator. The zero value answers test
+// tokens instantly, approves every other token and never settles on its
+// own.
+type SimulatorConfig struct {
+	// Latency is added to every call, unless the context ends first.
+	Latency time.Duration
+	// DeclineRate and NetworkErrorRate are the chances that a token
+	// without a scenario is declined or fails to reach the processor.
+	DeclineRate      float64
+	NetworkErrorRate float64
+	// Scenarios add to or override TestTokens.
+	Scenarios map[string]Scenario
+	// Seed makes the random outcomes repeatable; zero seeds from the clock.
+	Seed int64
+	// SettlementDelay is how long after a charge or capture its settlement
+	// webhook is sent. Zero leaves settlement to Settle.
+	SettlementDelay time.Duration
+	// WebhookURL receives settlement and dispute webhooks signed with
+	// WebhookSecret.
+	WebhookURL    string
+	WebhookSecret string
+	HTTPClient    *http.Client
+	// ChallengeURL is where challenge redirects point; the challenge ID is
+	// appended.
+	ChallengeURL string
+	// DisputeWindow is how long merchants have to answer a dispute;
+	// it defaults to seven days.
+	DisputeWindow time.Duration
+}
+
+type settlement struct {
+	amount   int64
+	currency string
+	settled  bool
+	refunded int64 // Refunded so far
+}
+
+type challengeState string
+
+const (
+	challengePending challengeState = "pending"
+	challengePassed  challengeState = "passed"
+	challengeFailed  challengeState = "failed"
+)
+
+// dispute is a cardholder dispute of a settled-to-be transaction.
+type dispute struct {
+	transactionID string
+	amount        int64
+	currency      string
+	closed        bool
+}
+
+// challenge is an operation waiting for cardholder authentication.
+type challenge struct {
+	authorize   bool
+	amount      int64
+	currency    string
+	redirectURL string
+	state       challengeState
+}
+
+// Simulator is an in-process processor that keeps authorizations,
+// challenges, pending settlements and refunds in memory.
+type Simulator struct {
+	cfg  SimulatorConfig
+	http *http.Client
+
+	mu             sync.Mutex
+	rand           *rand.Rand
+	authorizations map[string]*authorization
+	settlements    map[string]*settlement
+	challenges     map[string]*challenge
+	disputes       map[string]*dispute
+	refunds        map[string]TransactionResult // By the caller's refund ID
+}
+
+func NewSimulator(cfg SimulatorConfig) *Simulator {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Simulator{
+		cfg:            cfg,
+		http:           httpClient,
+		rand:           rand.New(rand.NewSource(seed)),
+		authorizations: map[string]*authorization{},
+		settlements:    map[string]*settlement{},
+		challenges:     map[string]*challenge{},
+		disputes:       map[string]*dispute{},
+		refunds:        map[string]TransactionResult{},
+	}
+}
+
+func (s *Simulator) Charge(ctx context.Context, amount int64, currency, cardToken string) (*TransactionResult, error) {
+	return s.open(ctx, false, amount, currency, cardToken)
+}
+
+func (s *Simulator) Authorize(ctx context.Context, amount int64, currency, cardToken string) (*TransactionResult, error) {
+	return s.open(ctx, true, amount, currency, cardToken)
+}
+
+func (s *Simulator) Capture(ctx context.Context, authorizationID string, amount int64) (*TransactionResult, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth, ok := s.authorizations[authorizationID]
+	if !ok {
+		return nil, ErrAuthorizationNotFound
+	}
+	if auth.state != authorizationOpen {
+		return nil, ErrAuthorizationClosed
+	}
+	if amount <= 0 || amount > auth.amount {
+		return nil, errors.New("invalid capture amount")
+	}
+	auth.state = authorizationCaptured
+	return s.settleLater("txn_"+GenerateRandomID(), amount, auth.currency), nil
+}
+
+func (s *Simulator) Void(ctx context.Context, authorizationID string) (*TransactionResult, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth, ok := s.authorizations[authorizationID]
+	if !ok {
+		return nil, ErrAuthorizationNotFound
+	}
+	if auth.state != authorizationOpen {
+		return nil, ErrAuthorizationClosed
+	}
+	auth.state = authorizationVoided
+	return &TransactionResult{TransactionID: authorizationID, Status: StatusSuccess}, nil
+}
+
+// Refund returns amount of a charge or capture. Refunds beyond what is
+// left of the charge are declined with amount_too_large.
+func (s *Simulator) Refund(ctx context.Context, transactionID, refundID string, amount int64) (*TransactionResult, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if result, ok := s.refunds[refundID]; ok {
+		return &result, nil
+	}
+	st, ok := s.settlements[transactionID]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	if amount <= 0 {
+		return nil, errors.New("invalid refund amount")
+	}
+
+	result := TransactionResult{TransactionID: "re_" + refundID, Status: StatusSuccess}
+	if amount > st.amount-st.refunded {
+		result = TransactionResult{Status: StatusFailed, ErrorCode: "amount_too_large"}
+	} else {
+		st.refunded += amount
+	}
+	s.refunds[refundID] = result
+	return &result, nil
+}
+
+// Authenticate records the cardholder's answer to a challenge, as the
+// issuer's challenge page would.
+func (s *Simulator) Authenticate(challengeID string, passed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.challenges[challengeID]
+	if !ok {
+		return ErrChallengeNotFound
+	}
+	c.state = challengeFailed
+	if passed {
+		c.state = challengePassed
+	}
+	return nil
+}
+
+// CompleteChallenge finishes the charge or authorization a challenge held
+// back. A failed challenge declines it; one the cardholder has not
+// answered yet still requires action.
+func (s *Simulator) CompleteChallenge(ctx context.Context, challengeID string) (*TransactionResult, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.challenges[challengeID]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	switch c.state {
+	case challengePending:
+		return &TransactionResult{Status: StatusRequiresAction, ChallengeID: challengeID, RedirectURL: c.redirectURL}, nil
+	case challengeFailed:
+		delete(s.challenges, challengeID)
+		return &TransactionResult{Status: StatusFailed, ErrorCode: "authentication_failed"}, nil
+	default:
+		delete(s.challenges, challengeID)
+		return s.approve(c.authorize, c.amount, c.currency), nil
+	}
+}
+
+// ChallengeHandler serves the simulated challenge page under ChallengeURL.
+// The cardholder passes with ?result=pass and fails with ?result=fail, and
+// is sent on to return_url when one is given.
+func (s *Simulator) ChallengeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := path.Base(r.URL.Path)
+		result := r.URL.Query().Get("result")
+		if result != "pass" && result != "fail" {
+			http.Error(w, "result must be pass or fail", http.StatusBadRequest)
+			return
+		}
+		if err := s.Authenticate(id, result == "pass"); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if returnURL := r.URL.Query().Get("return_url"); returnURL != "" {
+			http.Redirect(w, r, returnURL, http.StatusSeeOther)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "Authentication %sed. You can return to the merchant.\n", result)
+	})
+}
+
+// Settle sends the settlement webhook of a charge or capture now. It is
+// what SettlementDelay schedules, and lets tests settle deterministically.
+func (s *Simulator) Settle(ctx context.Context, transactionID string) error {
+	s.mu.Lock()
+	st, ok := s.settlements[transactionID]
+	if ok && st.settled {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no settlement for transaction %s", transactionID)
+	}
+
+	event := Event{
+		ID:            "evt_" + GenerateRandomID(),
+		Type:          EventChargeSettled,
+		TransactionID: transactionID,
+		Amount:        st.amount,
+		Currency:      st.currency,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := s.deliver(ctx, event); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	st.settled = true
+	s.mu.Unlock()
+	return nil
+}
+
+// OpenDispute disputes the whole amount of a charge or capture on the
+// cardholder's behalf and sends its webhook. It returns the dispute's ID.
+func (s *Simulator) OpenDispute(ctx context.Context, transactionID, reason string) (string, error) {
+	s.mu.Lock()
+	st, ok := s.settlements[transactionID]
+	if !ok {
+		s.mu.Unlock()
+		return "", fmt.Errorf("no charge with transaction %s", transactionID)
+	}
+	id := "dp_" + GenerateRandomID()
+	d := &dispute{transactionID: transactionID, amount: st.amount, currency: st.currency}
+	s.disputes[id] = d
+	s.mu.Unlock()
+
+	window := s.cfg.DisputeWindow
+	if window <= 0 {
+		window = 7 * 24 * time.Hour
+	}
+	now := time.Now().UTC()
+	dueBy := now.Add(window)
+	return id, s.deliver(ctx, Event{
+		ID:            "evt_" + GenerateRandomID(),
+		Type:          EventDisputeCreated,
+		TransactionID: transactionID,
+		Amount:        d.amount,
+		Currency:      d.currency,
+		DisputeID:     id,
+		Reason:        reason,
+		EvidenceDueBy: &dueBy,
+		CreatedAt:     now,
+	})
+}
+
+// CloseDispute decides a dispute for or against the merchant and sends its
+// webhook.
+func (s *Simulator) CloseDispute(ctx context.Context, disputeID string, won bool) error {
+	s.mu.Lock()
+	d, ok := s.disputes[disputeID]
+	if !ok || d.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("no open dispute %s", disputeID)
+	}
+	d.closed = true
+	s.mu.Unlock()
+
+	status := DisputeLost
+	if won {
+		status = DisputeWon
+	}
+	return s.deliver(ctx, Event{
+		ID:            "evt_" + GenerateRandomID(),
+		Type:          EventDisputeClosed,
+		TransactionID: d.transactionID,
+		Amount:        d.amount,
+		Currency:      d.currency,
+		DisputeID:     disputeID,
+		DisputeStatus: status,
+		CreatedAt:     time.Now().UTC(),
+	})
+}
+
+// open decides a charge or authorization from the token's scenario, or at
+// random for tokens without one.
+func (s *Simulator) open(ctx context.Context, authorize bool, amount int64, currency, cardToken string) (*TransactionResult, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch sc := s.scenario(cardToken); sc.Outcome {
+	case OutcomeDecline:
+		return &TransactionResult{Status: StatusFailed, ErrorCode: sc.DeclineCode}, nil
+	case OutcomeNetworkError:
+		return nil, fmt.Errorf("%w: connection reset", ErrNetwork)
+	case OutcomeChallenge:
+		id := "chl_" + GenerateRandomID()
+		c := &challenge{authorize: authorize, amount: amount, currency: currency, redirectURL: s.cfg.ChallengeURL + id, state: challengePending}
+		s.challenges[id] = c
+		return &TransactionResult{Status: StatusRequiresAction, ChallengeID: id, RedirectURL: c.redirectURL}, nil
+	default:
+		return s.approve(authorize, amount, currency), nil
+	}
+}
+
+// scenario returns the answer to a token. s.mu must be held.
+func (s *Simulator) scenario(cardToken string) Scenario {
+	if sc, ok := s.cfg.Scenarios[cardToken]; ok {
+		return sc
+	}
+	if sc, ok := TestTokens[cardToken]; ok {
+		return sc
+	}
+	switch r := s.rand.Float64(); {
+	case r < s.cfg.NetworkErrorRate:
+		return Scenario{Outcome: OutcomeNetworkError}
+	case r < s.cfg.NetworkErrorRate+s.cfg.DeclineRate:
+		return Scenario{Outcome: OutcomeDecline, DeclineCode: "card_declined"}
+	default:
+		return Scenario{Outcome: OutcomeApprove}
+	}
+}
+
+// approve records an approved charge or authorization. s.mu must be held.
+func (s *Simulator) approve(authorize bool, amount int64, currency string) *TransactionResult {
+	if authorize {
+		id := "auth_" + GenerateRandomID()
+		s.authorizations[id] = &authorization{amount: amount, currency: currency, state: authorizationOpen}
+		return &TransactionResult{TransactionID: id, Status: StatusSuccess}
+	}
+	return s.settleLater("txn_"+GenerateRandomID(), amount, currency)
+}
+
+// settleLater records a settled-to-be transaction and schedules its
+// webhook. s.mu must be held.
+func (s *Simulator) settleLater(id string, amount int64, currency string) *TransactionResult {
+	s.settlements[id] = &settlement{amount: amount, currency: currency}
+	if s.cfg.SettlementDelay > 0 {
+		time.AfterFunc(s.cfg.SettlementDelay, func() {
+			if err := s.Settle(context.Background(), id); err != nil {
+				log.Printf("Simulator: failed to settle %s: %v", id, err)
+			}
+		})
+	}
+	return &TransactionResult{TransactionID: id, Status: StatusSuccess}
+}
+
+// deliver posts a signed webhook, retrying failed attempts with backoff.
+func (s *Simulator) deliver(ctx context.Context, event Event) error {
+	if s.cfg.WebhookURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	const attempts = 3
+	backoff := 100 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err = s.post(ctx, body)
+		if err == nil || attempt == attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (s *Simulator) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, SignWebhook(s.cfg.WebhookSecret, body))
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// wait adds the configured latency.
+func (s *Simulator) wait(ctx context.Context) error {
+	if s.cfg.Latency <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrNetwork, ctx.Err())
+	case <-time.After(s.cfg.Latency):
+		return nil
+	}
+}
@@ -0,0 +1,247 @@
+package bank
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSimulator_TestTokens(t *testing.T) {
+	sim := NewSimulator(SimulatorConfig{Scenarios: map[string]Scenario{
+		"tok_custom": {Outcome: OutcomeDecline, DeclineCode: "do_not_honor"},
+	}})
+
+	tests := []struct {
+		token      string
+		wantStatus Status
+		wantCode   string
+		wantErr    error
+	}{
+		{"tok_visa", StatusSuccess, "", nil},
+		{"tok_declined", StatusFailed, "card_declined", nil},
+		{"tok_insufficient_funds", StatusFailed, "insufficient_funds", nil},
+		{"tok_custom", StatusFailed, "do_not_honor", nil},
+		{"tok_threeds_required", StatusRequiresAction, "", nil},
+		{"tok_network_error", "", "", ErrNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			result, err := sim.Charge(context.Background(), 1000, "USD", tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Charge() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result.Status != tt.wantStatus || result.ErrorCode != tt.wantCode {
+				t.Errorf("Charge() = %+v, want status %s code %q", result, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantStatus == StatusRequiresAction && result.ChallengeID == "" {
+				t.Error("expected a challenge ID")
+			}
+		})
+	}
+}
+
+func TestSimulator_SeededOutcomesRepeat(t *testing.T) {
+	cfg := SimulatorConfig{DeclineRate: 0.3, NetworkErrorRate: 0.2, Seed: 42}
+	outcomes := func() []string {
+		sim := NewSimulator(cfg)
+		var out []string
+		for i := 0; i < 20; i++ {
+			result, err := sim.Charge(context.Background(), 1000, "USD", "tok_random")
+			if err != nil {
+				out = append(out, "error")
+				continue
+			}
+			out = append(out, string(result.Status))
+		}
+		return out
+	}
+
+	first, second := outcomes(), outcomes()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("seeded runs differ at %d: %v vs %v", i, first, second)
+		}
+	}
+}
+
+func TestSimulator_Challenge(t *testing.T) {
+	ctx := context.Background()
+	sim := NewSimulator(SimulatorConfig{ChallengeURL: "https://sim.test/3ds/"})
+
+	held, err := sim.Authorize(ctx, 1000, "USD", "tok_threeds_required")
+	if err != nil || held.Status != StatusRequiresAction {
+		t.Fatalf("Authorize() = %+v, %v, want a challenge", held, err)
+	}
+	if held.RedirectURL != "https://sim.test/3ds/"+held.ChallengeID {
+		t.Errorf("RedirectURL = %q", held.RedirectURL)
+	}
+
+	pending, err := sim.CompleteChallenge(ctx, held.ChallengeID)
+	if err != nil || pending.Status != StatusRequiresAction {
+		t.Fatalf("CompleteChallenge() before authentication = %+v, %v, want requires_action", pending, err)
+	}
+
+	page := httptest.NewRecorder()
+	sim.ChallengeHandler().ServeHTTP(page, httptest.NewRequest("GET", "/3ds/"+held.ChallengeID+"?result=pass&return_url=https://shop.test/done", nil))
+	if page.Code != http.StatusSeeOther || page.Header().Get("Location") != "https://shop.test/done" {
+		t.Fatalf("challenge page answered %d to %q", page.Code, page.Header().Get("Location"))
+	}
+
+	result, err := sim.CompleteChallenge(ctx, held.ChallengeID)
+	if err != nil || result.Status != StatusSuccess {
+		t.Fatalf("CompleteChallenge() = %+v, %v, want success", result, err)
+	}
+	if _, err := sim.Capture(ctx, result.TransactionID, 1000); err != nil {
+		t.Errorf("Capture after challenge: %v", err)
+	}
+	if _, err := sim.CompleteChallenge(ctx, held.ChallengeID); !errors.Is(err, ErrChallengeNotFound) {
+		t.Errorf("second CompleteChallenge() error = %v, want %v", err, ErrChallengeNotFound)
+	}
+
+	failed, _ := sim.Charge(ctx, 1000, "USD", "tok_threeds_required")
+	if err := sim.Authenticate(failed.ChallengeID, false); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if result, err := sim.CompleteChallenge(ctx, failed.ChallengeID); err != nil || result.ErrorCode != "authentication_failed" {
+		t.Errorf("CompleteChallenge() after a failed challenge = %+v, %v", result, err)
+	}
+}
+
+func TestSimulator_SettlementWebhook(t *testing.T) {
+	var got *Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		event, err := ParseWebhook("whsec_test", body, r.Header.Get(SignatureHeader))
+		if err != nil {
+			t.Errorf("ParseWebhook: %v", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		got = event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	sim := NewSimulator(SimulatorConfig{WebhookURL: srv.URL, WebhookSecret: "whsec_test"})
+	charge, err := sim.Charge(ctx, 1500, "EUR", "tok_visa")
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if err := sim.Settle(ctx, charge.TransactionID); err != nil {
+		t.Fatalf("Settle: %v", err)
+	}
+	if got == nil || got.Type != EventChargeSettled || got.TransactionID != charge.TransactionID || got.Amount != 1500 || got.Currency != "EUR" {
+		t.Errorf("webhook event = %+v", got)
+	}
+}
+
+func TestSimulator_Refunds(t *testing.T) {
+	ctx := context.Background()
+	sim := NewSimulator(SimulatorConfig{})
+	charge, err := sim.Charge(ctx, 1000, "USD", "tok_visa")
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+
+	first, err := sim.Refund(ctx, charge.TransactionID, "refund_1", 600)
+	if err != nil || first.Status != StatusSuccess {
+		t.Fatalf("Refund() = %+v, %v", first, err)
+	}
+	if replay, err := sim.Refund(ctx, charge.TransactionID, "refund_1", 600); err != nil || replay.TransactionID != first.TransactionID {
+		t.Errorf("Refund() replay = %+v, %v, want %s", replay, err, first.TransactionID)
+	}
+	if over, err := sim.Refund(ctx, charge.TransactionID, "refund_2", 500); err != nil || over.Status != StatusFailed || over.ErrorCode != "amount_too_large" {
+		t.Errorf("Refund() beyond the charge = %+v, %v, want amount_too_large", over, err)
+	}
+	if rest, err := sim.Refund(ctx, charge.TransactionID, "refund_3", 400); err != nil || rest.Status != StatusSuccess {
+		t.Errorf("Refund() of the rest = %+v, %v", rest, err)
+	}
+	if _, err := sim.Refund(ctx, "txn_unknown", "refund_4", 100); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("Refund() of an unknown charge error = %v, want ErrTransactionNotFound", err)
+	}
+}
+
+func TestSimulator_Disputes(t *testing.T) {
+	var got []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		event, err := ParseWebhook("whsec_test", body, r.Header.Get(SignatureHeader))
+		if err != nil {
+			t.Errorf("ParseWebhook: %v", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		got = append(got, *event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	sim := NewSimulator(SimulatorConfig{WebhookURL: srv.URL, WebhookSecret: "whsec_test", DisputeWindow: time.Hour})
+	charge, _ := sim.Charge(ctx, 2500, "USD", "tok_visa")
+	disputeID, err := sim.OpenDispute(ctx, charge.TransactionID, "fraudulent")
+	if err != nil {
+		t.Fatalf("OpenDispute: %v", err)
+	}
+	if err := sim.CloseDispute(ctx, disputeID, true); err != nil {
+		t.Fatalf("CloseDispute: %v", err)
+	}
+	if err := sim.CloseDispute(ctx, disputeID, false); err == nil {
+		t.Error("expected an error closing a dispute twice")
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d webhooks, want 2", len(got))
+	}
+	opened, closed := got[0], got[1]
+	if opened.Type != EventDisputeCreated || opened.DisputeID != disputeID || opened.Amount != 2500 || opened.Reason != "fraudulent" || opened.EvidenceDueBy == nil {
+		t.Errorf("dispute created event = %+v", opened)
+	}
+	if closed.Type != EventDisputeClosed || closed.TransactionID != charge.TransactionID || closed.DisputeStatus != DisputeWon {
+		t.Errorf("dispute closed event = %+v", closed)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	sim := NewSimulator(SimulatorConfig{})
+	other := NewSimulator(SimulatorConfig{})
+	r := NewRegistry("simulator")
+	r.Register("simulator", sim, "")
+	r.Register("acme", other, "whsec_acme")
+
+	if err := r.AssignZone("zone_eu", "acme"); err != nil {
+		t.Fatalf("AssignZone: %v", err)
+	}
+	if err := r.AssignZone("zone_us", "missing"); !errors.Is(err, ErrUnknownProcessor) {
+		t.Errorf("AssignZone(missing) error = %v, want %v", err, ErrUnknownProcessor)
+	}
+	if name, client, _ := r.ForZone("zone_eu"); name != "acme" || client != other {
+		t.Errorf("ForZone(zone_eu) = %s", name)
+	}
+	if name, client, _ := r.ForZone("zone_other"); name != "simulator" || client != sim {
+		t.Errorf("ForZone(zone_other) = %s, want the fallback", name)
+	}
+	if client, _ := r.Get(""); client != sim {
+		t.Error("Get(\"\") should return the fallback")
+	}
+	if _, err := r.WebhookSecret("simulator"); !errors.Is(err, ErrUnknownProcessor) {
+		t.Errorf("WebhookSecret of a processor without webhooks: err = %v", err)
+	}
+
+	zones, err := ParseZoneAssignments(" zone_eu=acme, zone_us=simulator ")
+	if err != nil || zones["zone_eu"] != "acme" || zones["zone_us"] != "simulator" {
+		t.Errorf("ParseZoneAssignments() = %v, %v", zones, err)
+	}
+	if _, err := ParseZoneAssignments("zone_eu"); err == nil {
+		t.Error("expected an error for an assignment without a processor")
+	}
+}
@@ -0,0 +1,74 @@
+package bank
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SignatureHeader carries the signature of a processor webhook.
+const SignatureHeader = "Processor-Signature"
+
+// ErrInvalidSignature is returned for a webhook whose signature does not
+// match its body.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+type EventType string
+
+// EventChargeSettled announces that the funds of a charge or capture
+// reached the merchant. Settlement follows the charge asynchronously.
+const EventChargeSettled EventType = "charge.settled"
+
+// EventDisputeCreated announces that the cardholder disputed a charge. The
+// merchant has until EvidenceDueBy to respond.
+const EventDisputeCreated EventType = "charge.dispute.created"
+
+// EventDisputeClosed announces the processor's decision on a dispute in
+// DisputeStatus.
+const EventDisputeClosed EventType = "charge.dispute.closed"
+
+// Dispute outcomes reported by EventDisputeClosed.
+const (
+	DisputeWon  = "won"
+	DisputeLost = "lost"
+)
+
+// Event is a notification a processor sends by webhook. The dispute fields
+// are only set on dispute events.
+type Event struct {
+	ID            string     `json:"id"`
+	Type          EventType  `json:"type"`
+	TransactionID string     `json:"transaction_id"`
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency"`
+	DisputeID     string     `json:"dispute_id,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	DisputeStatus string     `json:"dispute_status,omitempty"`
+	EvidenceDueBy *time.Time `json:"evidence_due_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// SignWebhook returns the signature of a webhook body: the hex HMAC-SHA256
+// of body under secret.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseWebhook verifies a webhook's signature and decodes its event.
+func ParseWebhook(secret string, body []byte, signature string) (*Event, error) {
+	expected := SignWebhook(secret, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidSignature
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook body: %w", err)
+	}
+	return &event, nil
+}
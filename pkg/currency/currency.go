@@ -27,3 +27,74 @@ func Validate(code string) error {
 	}
 	return nil
 }
+
+// minorUnits lists ISO 4217 currencies whose minor unit is not 2 digits.
+var minorUnits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// Exponent returns the number of decimal digits in the currency's minor
+// unit, e.g. 2 for USD (cents) and 0 for JPY.
+func Exponent(code string) int {
+	if n, ok := minorUnits[strings.ToUpper(code)]; ok {
+		return n
+	}
+	return 2
+}
+
+// FormatMinor renders an amount in minor units as a decimal string in major
+// units, e.g. 12345 USD as "123.45".
+func FormatMinor(amount int64, code string) string {
+	exp := Exponent(code)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if exp == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+	div := int64(1)
+	for i := 0; i < exp; i++ {
+		div *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/div, exp, amount%div)
+}
+
+// ParseMinor parses a decimal amount in major units (e.g. "123.45", "-7",
+// "1234,50") into minor units of the currency. Thousands separators are not
+// accepted; a comma is read as the decimal mark.
+func ParseMinor(s, code string) (int64, error) {
+	s = strings.TrimSpace(strings.Replace(s, ",", ".", 1))
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	exp := Exponent(code)
+	if whole == "" && frac == "" || len(frac) > exp {
+		return 0, fmt.Errorf("invalid %s amount %q", code, s)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	var n int64
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid %s amount %q", code, s)
+		}
+		n = n*10 + int64(c-'0')
+	}
+	if neg {
+		n = -n
+	}
+	return n, nil
+}
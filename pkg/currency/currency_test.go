@@ -0,0 +1,54 @@
+package currency
+
+import "testing"
+
+func TestFormatMinor(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		code     string
+		expected string
+	}{
+		{12345, "USD", "123.45"},
+		{5, "EUR", "0.05"},
+		{-250, "usd", "-2.50"},
+		{1500, "JPY", "1500"},
+		{1234, "KWD", "1.234"},
+		{0, "GBP", "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatMinor(tt.amount, tt.code); got != tt.expected {
+			t.Errorf("FormatMinor(%d, %s) = %s, want %s", tt.amount, tt.code, got, tt.expected)
+		}
+	}
+}
+
+func TestParseMinor(t *testing.T) {
+	tests := []struct {
+		input    string
+		code     string
+		expected int64
+		wantErr  bool
+	}{
+		{"123.45", "USD", 12345, false},
+		{"1234,5", "EUR", 123450, false},
+		{"-7", "USD", -700, false},
+		{"+0.01", "GBP", 1, false},
+		{"1500", "JPY", 1500, false},
+		{"1.5", "JPY", 0, true},
+		{"1.234", "USD", 0, true},
+		{"1,234.00", "USD", 0, true},
+		{"", "USD", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMinor(tt.input, tt.code)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMinor(%q, %s) error = %v, wantErr %v", tt.input, tt.code, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseMinor(%q, %s) = %d, want %d", tt.input, tt.code, got, tt.expected)
+		}
+	}
+}
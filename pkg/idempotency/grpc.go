@@ -0,0 +1,116 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// grpcMethod stands in for the HTTP method in the records of gRPC calls,
+// whose path is the full method name.
+const grpcMethod = "GRPC"
+
+// metadataUser carries the caller's identity, as X-User-ID does over HTTP.
+const metadataUser = "x-user-id"
+
+var metadataKey = strings.ToLower(HeaderKey)
+
+// NewOutgoingContext returns ctx carrying key for an idempotent gRPC call
+// made on behalf of userID.
+func NewOutgoingContext(ctx context.Context, userID, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, metadataUser, userID, metadataKey, key)
+}
+
+// UnaryServerInterceptor makes the given methods, or every method when
+// none is given, idempotent for calls with idempotency-key metadata. Keys
+// are scoped to the method and to the caller named by x-user-id metadata,
+// which such calls must carry. A retry gets the stored response, with
+// x-idempotency-hit header metadata; a different request with the key
+// fails with InvalidArgument and a duplicate still waiting when the first
+// call outlasts the Guard's wait with Aborted. Only successful responses
+// are stored: a failed call releases its key.
+func (g *Guard) UnaryServerInterceptor(methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		guarded[m] = true
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if len(guarded) > 0 && !guarded[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		keys := md.Get(metadataKey)
+		msg, ok := req.(proto.Message)
+		if len(keys) == 0 || keys[0] == "" || !ok {
+			return handler(ctx, req)
+		}
+		users := md.Get(metadataUser)
+		if len(users) == 0 || users[0] == "" {
+			return nil, status.Error(codes.Unauthenticated, "x-user-id metadata is required for idempotent calls")
+		}
+		scope, key := info.FullMethod+":"+users[0], keys[0]
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid request")
+		}
+		record, err := g.Claim(ctx, scope, key, grpcMethod, info.FullMethod, Fingerprint(grpcMethod, info.FullMethod, body))
+		switch {
+		case errors.Is(err, ErrKeyReused):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, ErrKeyInUse):
+			return nil, status.Error(codes.Aborted, err.Error())
+		case err != nil:
+			return nil, status.FromContextError(err).Err()
+		}
+		if record != nil {
+			return replay(ctx, record)
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			_ = g.Release(ctx, scope, key)
+			return nil, err
+		}
+		stored, err := marshalResponse(resp)
+		if err != nil {
+			_ = g.Release(ctx, scope, key)
+			return resp, nil
+		}
+		_ = g.Complete(ctx, scope, key, http.StatusOK, stored)
+		return resp, nil
+	}
+}
+
+func marshalResponse(resp interface{}) ([]byte, error) {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil, errors.New("idempotency: response is not a protobuf message")
+	}
+	wrapped, err := anypb.New(msg)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(wrapped)
+}
+
+func replay(ctx context.Context, record *Record) (interface{}, error) {
+	var wrapped anypb.Any
+	if err := proto.Unmarshal(record.Response, &wrapped); err != nil {
+		return nil, status.Error(codes.Internal, "failed to replay stored response")
+	}
+	resp, err := wrapped.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to replay stored response")
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(HeaderHit), "true"))
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sapliy/fintech-ecosystem/pkg/apierror"
+	"github.com/sapliy/fintech-ecosystem/pkg/authutil"
+	"github.com/sapliy/fintech-ecosystem/pkg/jsonutil"
+)
+
+// maxBodySize bounds the request body read for the fingerprint. Larger
+// requests are refused rather than fingerprinted by a prefix.
+const maxBodySize = 1 << 20
+
+// Middleware makes next idempotent for requests with an Idempotency-Key
+// header. Keys are scoped to the authenticated user. A retry gets the
+// stored response with X-Idempotency-Hit, a different request with the key
+// gets 422, and a duplicate still waiting when the first request outlasts
+// the Guard's wait gets 409. Bodies over maxBodySize get 413. Server errors
+// are not stored: the key is released so the client can retry with it.
+func (g *Guard) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		userID, err := authutil.ExtractUserID(r)
+		if err != nil || userID == "" {
+			apierror.Unauthorized("Authentication required for idempotent requests").Write(w)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+		if err != nil {
+			apierror.BadRequest("Invalid request body").Write(w)
+			return
+		}
+		if len(body) > maxBodySize {
+			apierror.PayloadTooLarge(fmt.Sprintf("Idempotent requests are limited to %d bytes", maxBodySize)).Write(w)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := g.Claim(r.Context(), userID, key, r.Method, r.URL.Path, Fingerprint(r.Method, r.URL.Path, body))
+		switch {
+		case errors.Is(err, ErrKeyReused):
+			apierror.IdempotencyConflict(err.Error(), http.StatusUnprocessableEntity).Write(w)
+			return
+		case errors.Is(err, ErrKeyInUse):
+			apierror.IdempotencyConflict(err.Error(), http.StatusConflict).Write(w)
+			return
+		case r.Context().Err() != nil:
+			return
+		case err != nil:
+			apierror.Internal("Internal Server Error").Write(w)
+			return
+		}
+		if record != nil {
+			w.Header().Set(HeaderHit, "true")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(record.StatusCode)
+			_, _ = w.Write(record.Response)
+			return
+		}
+
+		recorder := &jsonutil.ResponseRecorder{
+			ResponseWriter: w,
+			StatusCode:     http.StatusOK,
+		}
+
+		next(recorder, r)
+
+		if recorder.StatusCode < 500 {
+			_ = g.Complete(r.Context(), userID, key, recorder.StatusCode, recorder.Body.Bytes())
+		} else {
+			_ = g.Release(r.Context(), userID, key)
+		}
+	}
+}
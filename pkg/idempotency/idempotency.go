@@ -0,0 +1,209 @@
+// Package idempotency makes retried requests safe to run again.
+//
+// A client sends an Idempotency-Key with a request, as an HTTP header or
+// gRPC metadata. The first request with a key claims it with a fingerprint
+// of the request, runs, and stores its response; a retry with the same key
+// and request gets the stored response instead of running again. Reusing a
+// key for a different request fails with ErrKeyReused. A duplicate arriving
+// while the first request still runs waits for it, up to the Guard's wait,
+// then fails with ErrKeyInUse. Keys are forgotten after their TTL.
+//
+// Claims are stored in a Store: SQLStore keeps them in the service's
+// Postgres database (see the idempotency_keys migrations under
+// migrations/), RedisStore in Redis.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// HeaderKey is the HTTP header, and lowercased the gRPC metadata key,
+	// carrying the idempotency key.
+	HeaderKey = "Idempotency-Key"
+	// HeaderHit is set on replayed HTTP responses.
+	HeaderHit = "X-Idempotency-Hit"
+
+	// DefaultTTL is how long a key is remembered.
+	DefaultTTL = 24 * time.Hour
+	// DefaultLockTTL bounds how long a claimed key blocks duplicates of a
+	// request still running. A claim left by a crashed request can be
+	// taken over after it.
+	DefaultLockTTL = time.Minute
+	// DefaultWait is how long a duplicate waits for the first request.
+	DefaultWait = 5 * time.Second
+
+	pollInterval = 100 * time.Millisecond
+)
+
+var (
+	ErrKeyReused = errors.New("idempotency key was already used for a different request")
+	ErrKeyInUse  = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Record is an idempotency key with the request it was first used for and,
+// once that request finished, its response.
+type Record struct {
+	// Scope separates the keys of different clients, and of different
+	// gRPC methods.
+	Scope  string
+	Key    string
+	Method string
+	Path   string
+	// Fingerprint identifies the first request. Keys stored without one
+	// match any request.
+	Fingerprint string
+	// StatusCode is zero while the first request is still running.
+	StatusCode  int
+	Response    []byte
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the record holds a response to replay.
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Store keeps idempotency records.
+type Store interface {
+	// Claim stores claim unless the store has a live record for its scope
+	// and key, which it returns instead. An expired record, or an
+	// unfinished one with the same fingerprint whose lock lapsed before
+	// now, is replaced by the claim.
+	Claim(ctx context.Context, claim *Record, now time.Time) (*Record, error)
+	// Complete stores the response of the request that claimed a key.
+	Complete(ctx context.Context, scope, key string, statusCode int, response []byte) error
+	// Release forgets a claimed key that has no response, so its request
+	// can be retried.
+	Release(ctx context.Context, scope, key string) error
+}
+
+// Fingerprint identifies a request by its method, path and body.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Guard claims keys in a store and decides what a request with a key may
+// do. Its HTTP middleware and gRPC interceptor share these semantics.
+type Guard struct {
+	store   Store
+	ttl     time.Duration
+	lockTTL time.Duration
+	wait    time.Duration
+}
+
+// Option configures a Guard.
+type Option func(*Guard)
+
+// WithTTL sets how long keys are remembered.
+func WithTTL(ttl time.Duration) Option {
+	return func(g *Guard) {
+		g.ttl = ttl
+	}
+}
+
+// WithLockTTL sets how long a claim blocks duplicates.
+func WithLockTTL(ttl time.Duration) Option {
+	return func(g *Guard) {
+		g.lockTTL = ttl
+	}
+}
+
+// WithWait sets how long a duplicate waits for the first request before
+// failing with ErrKeyInUse. Zero fails it at once.
+func WithWait(wait time.Duration) Option {
+	return func(g *Guard) {
+		g.wait = wait
+	}
+}
+
+// OptionsFromEnv returns the options set in the environment:
+// IDEMPOTENCY_KEY_RETENTION, a duration, overrides DefaultTTL.
+func OptionsFromEnv() ([]Option, error) {
+	var opts []Option
+	if v := os.Getenv("IDEMPOTENCY_KEY_RETENTION"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("idempotency: invalid IDEMPOTENCY_KEY_RETENTION %q", v)
+		}
+		opts = append(opts, WithTTL(ttl))
+	}
+	return opts, nil
+}
+
+// New creates a Guard storing keys in store.
+func New(store Store, opts ...Option) *Guard {
+	g := &Guard{
+		store:   store,
+		ttl:     DefaultTTL,
+		lockTTL: DefaultLockTTL,
+		wait:    DefaultWait,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Claim claims key for a request about to run. It returns nil when the
+// request may run, and the stored record when a previous request with the
+// key completed and its response should be replayed. A key used with
+// another request returns ErrKeyReused, and a key whose first request is
+// still running after the Guard's wait ErrKeyInUse.
+func (g *Guard) Claim(ctx context.Context, scope, key, method, path, fingerprint string) (*Record, error) {
+	deadline := time.Now().Add(g.wait)
+	for {
+		record, err := g.claim(ctx, scope, key, method, path, fingerprint, time.Now())
+		if !errors.Is(err, ErrKeyInUse) || !time.Now().Before(deadline) {
+			return record, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (g *Guard) claim(ctx context.Context, scope, key, method, path, fingerprint string, now time.Time) (*Record, error) {
+	existing, err := g.store.Claim(ctx, &Record{
+		Scope:       scope,
+		Key:         key,
+		Method:      method,
+		Path:        path,
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(g.lockTTL),
+		ExpiresAt:   now.Add(g.ttl),
+	}, now)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if existing.Fingerprint != "" && existing.Fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if !existing.Completed() {
+		return nil, ErrKeyInUse
+	}
+	return existing, nil
+}
+
+// Complete stores the response of the request that claimed key. The
+// response is stored even if ctx was canceled meanwhile.
+func (g *Guard) Complete(ctx context.Context, scope, key string, statusCode int, response []byte) error {
+	return g.store.Complete(context.WithoutCancel(ctx), scope, key, statusCode, response)
+}
+
+// Release forgets key so its request can be retried.
+func (g *Guard) Release(ctx context.Context, scope, key string) error {
+	return g.store.Release(context.WithoutCancel(ctx), scope, key)
+}
@@ -0,0 +1,334 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// memStore is a Store in memory with the semantics of SQLStore.
+type memStore struct {
+	mu       sync.Mutex
+	records  map[string]*Record
+	released bool
+}
+
+func newMemStore(records ...*Record) *memStore {
+	s := &memStore{records: map[string]*Record{}}
+	for _, r := range records {
+		s.records[r.Scope+":"+r.Key] = r
+	}
+	return s
+}
+
+func (s *memStore) Claim(_ context.Context, claim *Record, now time.Time) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.records[claim.Scope+":"+claim.Key]
+	if ok && !current.ExpiresAt.Before(now) && (current.Completed() || current.Fingerprint != claim.Fingerprint || !current.LockedUntil.Before(now)) {
+		rec := *current
+		return &rec, nil
+	}
+	rec := *claim
+	s.records[claim.Scope+":"+claim.Key] = &rec
+	return nil, nil
+}
+
+func (s *memStore) Complete(_ context.Context, scope, key string, statusCode int, response []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[scope+":"+key]; ok {
+		rec.StatusCode, rec.Response = statusCode, response
+	}
+	return nil
+}
+
+func (s *memStore) Release(_ context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[scope+":"+key]; ok && !rec.Completed() {
+		delete(s.records, scope+":"+key)
+		s.released = true
+	}
+	return nil
+}
+
+func TestGuard_Claim(t *testing.T) {
+	now := time.Now()
+	fp := Fingerprint("POST", "/charges", []byte(`{"amount":1000}`))
+	done := &Record{Scope: "user_1", Key: "key_1", Fingerprint: fp, StatusCode: http.StatusCreated, Response: []byte(`{}`), ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name        string
+		existing    *Record
+		fingerprint string
+		wantRecord  bool
+		wantErr     error
+	}{
+		{name: "new key", fingerprint: fp},
+		{name: "completed with the same request", existing: done, fingerprint: fp, wantRecord: true},
+		{name: "completed with another request", existing: done, fingerprint: "other", wantErr: ErrKeyReused},
+		{
+			name:        "stored without fingerprint",
+			existing:    &Record{Scope: "user_1", Key: "key_1", StatusCode: http.StatusOK, ExpiresAt: now.Add(time.Hour)},
+			fingerprint: fp, wantRecord: true,
+		},
+		{
+			name:        "running",
+			existing:    &Record{Scope: "user_1", Key: "key_1", Fingerprint: fp, LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)},
+			fingerprint: fp, wantErr: ErrKeyInUse,
+		},
+		{
+			name:        "abandoned",
+			existing:    &Record{Scope: "user_1", Key: "key_1", Fingerprint: fp, LockedUntil: now.Add(-time.Second), ExpiresAt: now.Add(time.Hour)},
+			fingerprint: fp,
+		},
+		{
+			name:        "expired",
+			existing:    &Record{Scope: "user_1", Key: "key_1", Fingerprint: "other", StatusCode: http.StatusOK, ExpiresAt: now.Add(-time.Second)},
+			fingerprint: fp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			if tt.existing != nil {
+				store = newMemStore(tt.existing)
+			}
+			g := New(store, WithWait(0))
+			record, err := g.Claim(context.Background(), "user_1", "key_1", "POST", "/charges", tt.fingerprint)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Claim() error = %v, want %v", err, tt.wantErr)
+			}
+			if (record != nil) != tt.wantRecord {
+				t.Errorf("Claim() record = %+v, want a record: %v", record, tt.wantRecord)
+			}
+		})
+	}
+}
+
+func TestGuard_ClaimWaitsForRunningRequest(t *testing.T) {
+	store := newMemStore()
+	g := New(store, WithWait(time.Second))
+	ctx := context.Background()
+	if _, err := g.Claim(ctx, "user_1", "key_1", "POST", "/charges", "fp"); err != nil {
+		t.Fatalf("first Claim: %v", err)
+	}
+	go func() {
+		time.Sleep(2 * pollInterval)
+		_ = g.Complete(ctx, "user_1", "key_1", http.StatusCreated, []byte(`{"id":"ch_1"}`))
+	}()
+
+	record, err := g.Claim(ctx, "user_1", "key_1", "POST", "/charges", "fp")
+	if err != nil || record == nil || string(record.Response) != `{"id":"ch_1"}` {
+		t.Fatalf("duplicate Claim() = %+v, %v, want the first response", record, err)
+	}
+}
+
+func TestGuard_Middleware(t *testing.T) {
+	stored := func(body string, status int) *Record {
+		return &Record{
+			Scope:       "user_1",
+			Key:         "key_1",
+			Fingerprint: Fingerprint("POST", "/test", []byte(body)),
+			StatusCode:  status,
+			Response:    []byte(`{"id":"pi_1"}`),
+			LockedUntil: time.Now().Add(time.Minute),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		existing       *Record
+		handlerStatus  int
+		expectedStatus int
+		expectedHit    bool
+		expectedRan    bool
+		expectReleased bool
+	}{
+		{
+			name:           "New Request - Runs Handler",
+			body:           `{"amount":1000}`,
+			handlerStatus:  http.StatusOK,
+			expectedStatus: http.StatusOK,
+			expectedRan:    true,
+		},
+		{
+			name:           "Retry - Replays Response",
+			body:           `{"amount":1000}`,
+			existing:       stored(`{"amount":1000}`, http.StatusCreated),
+			expectedStatus: http.StatusCreated,
+			expectedHit:    true,
+		},
+		{
+			name:           "Different Body - Rejected",
+			body:           `{"amount":2000}`,
+			existing:       stored(`{"amount":1000}`, http.StatusCreated),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "First Request In Flight - Conflict",
+			body:           `{"amount":1000}`,
+			existing:       stored(`{"amount":1000}`, 0),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Server Error - Releases Key",
+			body:           `{"amount":1000}`,
+			handlerStatus:  http.StatusServiceUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedRan:    true,
+			expectReleased: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			if tt.existing != nil {
+				store = newMemStore(tt.existing)
+			}
+			g := New(store, WithWait(0))
+
+			var gotBody string
+			ran := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				gotBody, ran = string(b), true
+				w.WriteHeader(tt.handlerStatus)
+				_, _ = w.Write([]byte(`{"id":"pi_2"}`))
+			})
+
+			req := httptest.NewRequest("POST", "/test", strings.NewReader(tt.body))
+			req.Header.Set(HeaderKey, "key_1")
+			req.Header.Set("X-User-ID", "user_1")
+			w := httptest.NewRecorder()
+
+			g.Middleware(next)(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if hit := w.Header().Get(HeaderHit) == "true"; hit != tt.expectedHit {
+				t.Errorf("Expected %s %v, got %v", HeaderHit, tt.expectedHit, hit)
+			}
+			if ran != tt.expectedRan || store.released != tt.expectReleased {
+				t.Errorf("ran = %v, released = %v, want %v, %v", ran, store.released, tt.expectedRan, tt.expectReleased)
+			}
+			if ran && gotBody != tt.body {
+				t.Errorf("handler read body %q, want %q", gotBody, tt.body)
+			}
+			if tt.handlerStatus == http.StatusOK {
+				if rec := store.records["user_1:key_1"]; rec == nil || rec.StatusCode != http.StatusOK || string(rec.Response) != `{"id":"pi_2"}` {
+					t.Errorf("stored record = %+v", rec)
+				}
+			}
+		})
+	}
+}
+
+func TestGuard_MiddlewareRequiresUser(t *testing.T) {
+	g := New(newMemStore(), WithWait(0))
+	req := httptest.NewRequest("POST", "/test", strings.NewReader(`{}`))
+	req.Header.Set(HeaderKey, "key_1")
+	w := httptest.NewRecorder()
+
+	g.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler ran without a user")
+	})(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestGuard_MiddlewareRejectsLargeBodies(t *testing.T) {
+	g := New(newMemStore(), WithWait(0))
+	req := httptest.NewRequest("POST", "/test", strings.NewReader(strings.Repeat("x", maxBodySize+1)))
+	req.Header.Set(HeaderKey, "key_1")
+	req.Header.Set("X-User-ID", "user_1")
+	w := httptest.NewRecorder()
+
+	g.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler ran with a truncated body")
+	})(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
+
+func TestGuard_UnaryServerInterceptor(t *testing.T) {
+	const method = "/wallet.WalletService/TopUp"
+	store := newMemStore()
+	interceptor := New(store, WithWait(0)).UnaryServerInterceptor(method)
+
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		if req.(*wrapperspb.Int64Value).Value < 0 {
+			return nil, status.Error(codes.InvalidArgument, "negative amount")
+		}
+		return wrapperspb.String("tx_1"), nil
+	}
+	callAs := func(userID, fullMethod, key string, amount int64) (interface{}, error) {
+		ctx := context.Background()
+		if key != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(metadataKey, key, metadataUser, userID))
+		}
+		return interceptor(ctx, wrapperspb.Int64(amount), &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+	}
+	call := func(fullMethod, key string, amount int64) (interface{}, error) {
+		return callAs("user_1", fullMethod, key, amount)
+	}
+
+	first, err := call(method, "key_1", 1000)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	replayed, err := call(method, "key_1", 1000)
+	if err != nil || !proto.Equal(replayed.(proto.Message), first.(proto.Message)) {
+		t.Fatalf("retry = %v, %v, want %v", replayed, err, first)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+
+	if _, err := call(method, "key_1", 2000); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("reused key error = %v, want InvalidArgument", err)
+	}
+	if _, err := call(method, "key_2", -1); status.Code(err) != codes.InvalidArgument || !store.released {
+		t.Errorf("failed call: err = %v, released = %v", err, store.released)
+	}
+	if _, err := call(method, "", 1000); err != nil {
+		t.Errorf("call without key: %v", err)
+	}
+	if _, err := call("/wallet.WalletService/GetWallet", "key_1", 1000); err != nil {
+		t.Errorf("unguarded method: %v", err)
+	}
+	if _, err := callAs("user_2", method, "key_1", 2000); err != nil {
+		t.Errorf("another caller's key: %v", err)
+	}
+	if _, err := callAs("", method, "key_3", 1000); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("call without a caller error = %v, want Unauthenticated", err)
+	}
+	if calls != 5 {
+		t.Errorf("handler ran %d times, want 5", calls)
+	}
+}
@@ -0,0 +1,130 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps idempotency records in Redis, one JSON value per key,
+// which Redis deletes when the record expires.
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisStore creates a store whose Redis keys start with keyPrefix.
+func NewRedisStore(client *redis.Client, keyPrefix string) *RedisStore {
+	return &RedisStore{client: client, keyPrefix: keyPrefix}
+}
+
+// redisRecord is a Record as stored in Redis.
+type redisRecord struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code"`
+	Response    []byte `json:"response,omitempty"`
+	// LockedUntil is in Unix milliseconds, for the claim script.
+	LockedUntil int64 `json:"locked_until"`
+	ExpiresAt   int64 `json:"expires_at"`
+}
+
+// claimScript sets KEYS[1] to the claim ARGV[1] for ARGV[2] milliseconds
+// unless it holds a record that is completed, has another fingerprint than
+// ARGV[3] or is locked at ARGV[4]; that record is returned instead.
+var claimScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	local rec = cjson.decode(current)
+	if rec.status_code ~= 0 or rec.fingerprint ~= ARGV[3] or rec.locked_until >= tonumber(ARGV[4]) then
+		return current
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return false
+`)
+
+// releaseScript deletes KEYS[1] if it holds no response.
+var releaseScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and cjson.decode(current).status_code == 0 then
+	redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (s *RedisStore) key(scope, key string) string {
+	return s.keyPrefix + scope + ":" + key
+}
+
+func (s *RedisStore) Claim(ctx context.Context, claim *Record, now time.Time) (*Record, error) {
+	ttl := claim.ExpiresAt.Sub(now)
+	if ttl <= 0 {
+		return nil, errors.New("idempotency: claim expires before now")
+	}
+	value, err := json.Marshal(redisRecord{
+		Method:      claim.Method,
+		Path:        claim.Path,
+		Fingerprint: claim.Fingerprint,
+		LockedUntil: claim.LockedUntil.UnixMilli(),
+		ExpiresAt:   claim.ExpiresAt.UnixMilli(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := claimScript.Run(ctx, s.client, []string{s.key(claim.Scope, claim.Key)},
+		value, ttl.Milliseconds(), claim.Fingerprint, now.UnixMilli()).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: failed to claim key: %w", err)
+	}
+
+	var rec redisRecord
+	if err := json.Unmarshal([]byte(current), &rec); err != nil {
+		return nil, fmt.Errorf("idempotency: invalid record for key %s: %w", claim.Key, err)
+	}
+	return &Record{
+		Scope:       claim.Scope,
+		Key:         claim.Key,
+		Method:      rec.Method,
+		Path:        rec.Path,
+		Fingerprint: rec.Fingerprint,
+		StatusCode:  rec.StatusCode,
+		Response:    rec.Response,
+		LockedUntil: time.UnixMilli(rec.LockedUntil),
+		ExpiresAt:   time.UnixMilli(rec.ExpiresAt),
+	}, nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, scope, key string, statusCode int, response []byte) error {
+	redisKey := s.key(scope, key)
+	current, err := s.client.Get(ctx, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var rec redisRecord
+	if err := json.Unmarshal(current, &rec); err != nil {
+		return err
+	}
+	rec.StatusCode, rec.Response, rec.LockedUntil = statusCode, response, 0
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.client.SetArgs(ctx, redisKey, value, redis.SetArgs{KeepTTL: true}).Err()
+}
+
+func (s *RedisStore) Release(ctx context.Context, scope, key string) error {
+	return releaseScript.Run(ctx, s.client, []string{s.key(scope, key)}).Err()
+}
@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "test:idem:")
+	ctx := context.Background()
+	now := time.Now()
+	claim := func(fingerprint string, at time.Time) (*Record, error) {
+		return store.Claim(ctx, &Record{
+			Scope: "user_1", Key: "key_1", Method: "POST", Path: "/top-up", Fingerprint: fingerprint,
+			LockedUntil: at.Add(time.Minute), ExpiresAt: at.Add(time.Hour),
+		}, at)
+	}
+
+	if rec, err := claim("fp", now); err != nil || rec != nil {
+		t.Fatalf("first Claim() = %+v, %v, want a claim", rec, err)
+	}
+	if rec, err := claim("fp", now); err != nil || rec == nil || rec.Completed() {
+		t.Fatalf("Claim() while running = %+v, %v, want the running record", rec, err)
+	}
+	if rec, err := claim("fp", now.Add(2*time.Minute)); err != nil || rec != nil {
+		t.Fatalf("Claim() after the lock lapsed = %+v, %v, want a takeover", rec, err)
+	}
+
+	if err := store.Complete(ctx, "user_1", "key_1", http.StatusCreated, []byte(`{"id":"tx_1"}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	rec, err := claim("fp", now)
+	if err != nil || rec == nil || rec.StatusCode != http.StatusCreated || string(rec.Response) != `{"id":"tx_1"}` || rec.Path != "/top-up" {
+		t.Fatalf("Claim() after Complete = %+v, %v", rec, err)
+	}
+	if err := store.Release(ctx, "user_1", "key_1"); err != nil || !mr.Exists("test:idem:user_1:key_1") {
+		t.Errorf("Release removed a completed key: %v", err)
+	}
+
+	mr.FastForward(time.Hour + time.Second)
+	if rec, err := claim("other", now.Add(time.Hour+time.Second)); err != nil || rec != nil {
+		t.Fatalf("Claim() after expiry = %+v, %v, want a claim", rec, err)
+	}
+	if err := store.Release(ctx, "user_1", "key_1"); err != nil || mr.Exists("test:idem:user_1:key_1") {
+		t.Errorf("Release kept a running key: %v", err)
+	}
+}
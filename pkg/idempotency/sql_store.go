@@ -0,0 +1,139 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SQLStore keeps idempotency records in the idempotency_keys table of a
+// Postgres database.
+type SQLStore struct {
+	db *sql.DB
+}
+
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Claim inserts the claim, or takes over an expired or abandoned row in
+// the same statement, so of two concurrent claims exactly one wins.
+func (s *SQLStore) Claim(ctx context.Context, claim *Record, now time.Time) (*Record, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO idempotency_keys (scope, key, request_method, request_path, request_hash, locked_until, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (scope, key) DO UPDATE SET
+		     request_method = EXCLUDED.request_method, request_path = EXCLUDED.request_path,
+		     request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL,
+		     locked_until = EXCLUDED.locked_until, expires_at = EXCLUDED.expires_at, created_at = NOW()
+		 WHERE idempotency_keys.expires_at < $8
+		    OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until < $8
+		        AND idempotency_keys.request_hash = EXCLUDED.request_hash)`,
+		claim.Scope, claim.Key, claim.Method, claim.Path, claim.Fingerprint, claim.LockedUntil, claim.ExpiresAt, now)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: failed to claim key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n > 0 {
+		return nil, nil
+	}
+
+	var rec Record
+	var method, path, hash sql.NullString
+	var statusCode sql.NullInt64
+	var lockedUntil sql.NullTime
+	err = s.db.QueryRowContext(ctx,
+		`SELECT scope, key, request_method, request_path, request_hash, response_body, status_code, locked_until, expires_at
+		 FROM idempotency_keys WHERE scope = $1 AND key = $2`, claim.Scope, claim.Key).
+		Scan(&rec.Scope, &rec.Key, &method, &path, &hash, &rec.Response, &statusCode, &lockedUntil, &rec.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released or purged in between; the client can retry.
+		return nil, ErrKeyInUse
+	}
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: failed to get key: %w", err)
+	}
+	rec.Method, rec.Path, rec.Fingerprint = method.String, path.String, hash.String
+	rec.StatusCode, rec.LockedUntil = int(statusCode.Int64), lockedUntil.Time
+	return &rec, nil
+}
+
+func (s *SQLStore) Complete(ctx context.Context, scope, key string, statusCode int, response []byte) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE idempotency_keys SET status_code = $1, response_body = $2, locked_until = NULL
+		 WHERE scope = $3 AND key = $4`,
+		statusCode, response, scope, key)
+	return err
+}
+
+func (s *SQLStore) Release(ctx context.Context, scope, key string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status_code IS NULL`, scope, key)
+	return err
+}
+
+// Purge deletes up to limit keys expired before before and returns how many
+// it deleted.
+func (s *SQLStore) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE ctid IN (
+		     SELECT ctid FROM idempotency_keys WHERE expires_at < $1 LIMIT $2)`,
+		before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("idempotency: failed to delete expired keys: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// purgeBatchSize bounds how many expired keys one delete removes, so a
+// backlog is cleared in short statements.
+const purgeBatchSize = 1000
+
+// Purger periodically deletes expired keys from a SQLStore. RedisStore
+// needs none: Redis expires its keys.
+type Purger struct {
+	store interface {
+		Purge(ctx context.Context, before time.Time, limit int) (int64, error)
+	}
+	interval time.Duration
+}
+
+func NewPurger(store *SQLStore, interval time.Duration) *Purger {
+	return &Purger{store: store, interval: interval}
+}
+
+func (p *Purger) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.purge(ctx, time.Now())
+		}
+	}
+}
+
+// purge deletes the keys expired at now, batch by batch, and returns how
+// many it deleted.
+func (p *Purger) purge(ctx context.Context, now time.Time) int64 {
+	var purged int64
+	for ctx.Err() == nil {
+		n, err := p.store.Purge(ctx, now, purgeBatchSize)
+		if err != nil {
+			log.Printf("idempotency: failed to purge expired keys: %v", err)
+			break
+		}
+		purged += n
+		if n < purgeBatchSize {
+			break
+		}
+	}
+	return purged
+}
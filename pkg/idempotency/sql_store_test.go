@@ -0,0 +1,31 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakePurgeStore struct {
+	remaining int64
+	calls     int
+}
+
+func (s *fakePurgeStore) Purge(_ context.Context, _ time.Time, limit int) (int64, error) {
+	s.calls++
+	n := min(s.remaining, int64(limit))
+	s.remaining -= n
+	return n, nil
+}
+
+func TestPurger_PurgesInBatches(t *testing.T) {
+	store := &fakePurgeStore{remaining: 2*purgeBatchSize + 5}
+	p := &Purger{store: store, interval: time.Hour}
+
+	if n := p.purge(context.Background(), time.Now()); n != 2*purgeBatchSize+5 {
+		t.Fatalf("purge deleted %d keys, want %d", n, 2*purgeBatchSize+5)
+	}
+	if store.calls != 3 {
+		t.Errorf("made %d deletes, want 3", store.calls)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -12,21 +13,29 @@ type KafkaProducer struct {
 	writer *kafka.Writer
 }
 
+// NewKafkaProducer creates a producer for topic. Messages with the same key
+// go to the same partition, so consumers see them in publish order, and a
+// publish only succeeds once all in-sync replicas have the message.
 func NewKafkaProducer(brokers []string, topic string) *KafkaProducer {
 	return &KafkaProducer{
 		writer: &kafka.Writer{
-			Addr:     kafka.TCP(brokers...),
-			Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
 		},
 	}
 }
 
 func (p *KafkaProducer) Publish(ctx context.Context, key string, value []byte) error {
-	err := p.writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(key),
-		Value: value,
-	})
+	msg := kafka.Message{Value: value}
+	if key != "" {
+		// Unkeyed messages are spread over partitions rather than all
+		// hashing to the same one.
+		msg.Key = []byte(key)
+	}
+	err := p.writer.WriteMessages(ctx, msg)
 	if err != nil {
 		return fmt.Errorf("failed to write message to kafka: %w", err)
 	}
@@ -37,10 +46,22 @@ func (p *KafkaProducer) Close() error {
 	return p.writer.Close()
 }
 
+// Consumer handler failures are retried maxHandleAttempts times, backing
+// off from handleRetryDelay, before the message is dead-lettered.
+const (
+	maxHandleAttempts = 5
+	handleRetryDelay  = time.Second
+)
+
 type KafkaConsumer struct {
 	reader *kafka.Reader
+	dlq    *kafka.Writer
+	group  string
 }
 
+// NewKafkaConsumer creates a consumer of topic in the consumer group.
+// Messages the handler keeps failing on are published to topic + ".dlq",
+// like the RabbitMQ dead-letter queues, rather than dropped.
 func NewKafkaConsumer(brokers []string, topic, groupID string) *KafkaConsumer {
 	return &KafkaConsumer{
 		reader: kafka.NewReader(kafka.ReaderConfig{
@@ -50,12 +71,23 @@ func NewKafkaConsumer(brokers []string, topic, groupID string) *KafkaConsumer {
 			MinBytes: 10e3, // 10KB
 			MaxBytes: 10e6, // 10MB
 		}),
+		dlq: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic + ".dlq",
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+		group: groupID,
 	}
 }
 
+// Consume hands each message to handler and commits its offset only once
+// it is handled or dead-lettered, so a message is never acknowledged while
+// its handler is failing. It returns when ctx is done.
 func (c *KafkaConsumer) Consume(ctx context.Context, handler func(key string, value []byte) error) {
 	for {
-		m, err := c.reader.ReadMessage(ctx)
+		m, err := c.reader.FetchMessage(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
@@ -64,13 +96,66 @@ func (c *KafkaConsumer) Consume(ctx context.Context, handler func(key string, va
 			continue
 		}
 
-		if err := handler(string(m.Key), m.Value); err != nil {
-			log.Printf("error handling message: %v", err)
-			// In a real system, you might want more sophisticated error handling (retry, DLQ)
+		if err := c.handle(ctx, m, handler); err != nil {
+			// Only a done context gets here; the uncommitted message is
+			// redelivered to the group.
+			return
+		}
+		if err := c.reader.CommitMessages(ctx, m); err != nil {
+			log.Printf("error committing kafka offset %d of partition %d: %v", m.Offset, m.Partition, err)
+		}
+	}
+}
+
+// handle runs handler on m until it succeeds or runs out of attempts, then
+// dead-letters m. It fails only if ctx is done first.
+func (c *KafkaConsumer) handle(ctx context.Context, m kafka.Message, handler func(key string, value []byte) error) error {
+	delay := handleRetryDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = handler(string(m.Key), m.Value); err == nil {
+			return nil
+		}
+		log.Printf("error handling message at offset %d (attempt %d/%d): %v", m.Offset, attempt, maxHandleAttempts, err)
+		if attempt == maxHandleAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	dead := kafka.Message{
+		Key:   m.Key,
+		Value: m.Value,
+		Headers: append(m.Headers,
+			kafka.Header{Key: "dlq-error", Value: []byte(err.Error())},
+			kafka.Header{Key: "dlq-group", Value: []byte(c.group)},
+			kafka.Header{Key: "dlq-source", Value: []byte(fmt.Sprintf("%s/%d/%d", m.Topic, m.Partition, m.Offset))},
+		),
+	}
+	for {
+		werr := c.dlq.WriteMessages(ctx, dead)
+		if werr == nil {
+			log.Printf("dead-lettered message at offset %d to %s: %v", m.Offset, c.dlq.Topic, err)
+			return nil
+		}
+		log.Printf("error dead-lettering message at offset %d: %v", m.Offset, werr)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(handleRetryDelay):
 		}
 	}
 }
 
 func (c *KafkaConsumer) Close() error {
+	if err := c.dlq.Close(); err != nil {
+		_ = c.reader.Close()
+		return err
+	}
 	return c.reader.Close()
 }
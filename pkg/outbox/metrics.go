@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// PendingEvents is the number of unpublished events in the outbox.
+	PendingEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_pending_events",
+		Help: "Current number of unpublished events in the outbox.",
+	})
+
+	// OldestPendingAge is the age of the oldest unpublished event.
+	OldestPendingAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_oldest_pending_seconds",
+		Help: "Age of the oldest unpublished event in the outbox.",
+	})
+
+	// Published counts events delivered to the broker.
+	Published = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_published_total",
+		Help: "Total number of outbox events published.",
+	}, []string{"topic", "event_type"})
+
+	// PublishFailures counts failed publish attempts.
+	PublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_publish_failures_total",
+		Help: "Total number of failed outbox publish attempts.",
+	}, []string{"topic", "event_type"})
+
+	// DeadLettered counts events given up on after maxAttempts failed
+	// publishes. Any increase needs an operator: the event was skipped and
+	// its consumers never saw it.
+	DeadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_dead_lettered_total",
+		Help: "Total number of outbox events dead-lettered after repeated publish failures.",
+	}, []string{"topic", "event_type"})
+
+	// DeadEvents is the number of dead-lettered events in the outbox.
+	DeadEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_dead_events",
+		Help: "Current number of dead-lettered events in the outbox.",
+	})
+
+	// DeliveryLatency tracks the time from an event being written to it
+	// being published.
+	DeliveryLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "outbox_delivery_seconds",
+		Help:    "Time from an event being written to it being published.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+	})
+)
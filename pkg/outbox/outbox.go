@@ -0,0 +1,58 @@
+// Package outbox implements the transactional outbox pattern.
+//
+// Services write events with Write in the same database transaction as the
+// state change they describe, so the two commit or roll back together. A
+// Relay then publishes the committed events to a broker, retrying with
+// backoff. Delivery is at least once; consumers must tolerate duplicates.
+// An event that keeps failing is dead-lettered: it stays in the table with
+// dead_lettered_at set, is counted by outbox_dead_lettered_total, and no
+// longer holds back the events after it.
+//
+// Each service database needs the outbox table; see the outbox migrations
+// under migrations/. The relay publishes a key's events in seq order; a
+// failed event waits until its available_at and holds back the later events
+// of its partition_key.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Event is a message in the outbox.
+type Event struct {
+	ID string
+	// Topic is where the event goes: a Kafka topic, RabbitMQ queue or
+	// Redis stream, depending on the relay's publisher.
+	Topic string
+	Type  string
+	// Key orders events: events with the same key are published in the
+	// order they were written. On Kafka it is also the message key.
+	Key       string
+	Payload   []byte
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// Publisher delivers events to a broker.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Write queues e in tx. It is published after tx commits, and never if tx
+// rolls back.
+func Write(ctx context.Context, tx *sql.Tx, e Event) error {
+	if e.Topic == "" {
+		return errors.New("outbox: event has no topic")
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO outbox (topic, event_type, partition_key, payload) VALUES ($1, $2, $3, $4)`,
+		e.Topic, e.Type, e.Key, e.Payload)
+	if err != nil {
+		return fmt.Errorf("outbox: failed to write %s event: %w", e.Type, err)
+	}
+	return nil
+}
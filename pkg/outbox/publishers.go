@@ -0,0 +1,118 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sapliy/fintech-ecosystem/pkg/messaging"
+)
+
+// KafkaPublisher publishes each event to the Kafka topic named by its Topic,
+// keyed by its Key so a key's events land on one partition in order.
+type KafkaPublisher struct {
+	brokers   []string
+	mu        sync.Mutex
+	producers map[string]*messaging.KafkaProducer
+}
+
+func NewKafkaPublisher(brokers []string) *KafkaPublisher {
+	return &KafkaPublisher{
+		brokers:   brokers,
+		producers: map[string]*messaging.KafkaProducer{},
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, e Event) error {
+	return p.producer(e.Topic).Publish(ctx, e.Key, e.Payload)
+}
+
+func (p *KafkaPublisher) producer(topic string) *messaging.KafkaProducer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	producer, ok := p.producers[topic]
+	if !ok {
+		producer = messaging.NewKafkaProducer(p.brokers, topic)
+		p.producers[topic] = producer
+	}
+	return producer
+}
+
+func (p *KafkaPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var firstErr error
+	for _, producer := range p.producers {
+		if err := producer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// RabbitMQPublisher publishes each event to the queue named by its Topic.
+type RabbitMQPublisher struct {
+	client *messaging.RabbitMQClient
+}
+
+func NewRabbitMQPublisher(client *messaging.RabbitMQClient) *RabbitMQPublisher {
+	return &RabbitMQPublisher{client: client}
+}
+
+func (p *RabbitMQPublisher) Publish(ctx context.Context, e Event) error {
+	return p.client.Publish(ctx, e.Topic, e.Payload)
+}
+
+// RedisStreamPublisher appends each event to the Redis stream named by its
+// Topic.
+type RedisStreamPublisher struct {
+	client *redis.Client
+}
+
+func NewRedisStreamPublisher(client *redis.Client) *RedisStreamPublisher {
+	return &RedisStreamPublisher{client: client}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, e Event) error {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: e.Topic,
+		Values: map[string]interface{}{
+			"id":   e.ID,
+			"type": e.Type,
+			"key":  e.Key,
+			"data": e.Payload,
+		},
+	}).Err()
+}
+
+// MemoryPublisher keeps published events in memory, for tests and local
+// runs without a broker. Fail, when set, decides whether a publish fails.
+type MemoryPublisher struct {
+	Fail func(e Event) error
+
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, e Event) error {
+	if p.Fail != nil {
+		if err := p.Fail(e); err != nil {
+			return err
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+// Events returns the events published so far, in publish order.
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Event(nil), p.events...)
+}
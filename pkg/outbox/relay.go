@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	batchSize  = 100
+	minBackoff = time.Second
+	maxBackoff = 5 * time.Minute
+	// maxAttempts is the number of failed publishes after which an event is
+	// dead-lettered, about an hour and a half of retries.
+	maxAttempts = 25
+)
+
+// Store hands out outbox events for publishing.
+type Store interface {
+	// Claim locks a batch of events that are due, oldest first. Events whose
+	// key is being published by another relay, or has an earlier event
+	// waiting for a retry, are left out.
+	Claim(ctx context.Context, limit int) (Claim, error)
+	// Stats returns the number of unpublished events and the creation time
+	// of the oldest, or a zero time when there are none, along with the
+	// number of dead-lettered events.
+	Stats(ctx context.Context) (pending int, oldest time.Time, dead int, err error)
+}
+
+// Claim is a batch of claimed events. The claim holds its locks until it is
+// committed or rolled back.
+type Claim interface {
+	Events() []Event
+	MarkPublished(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, retryAt time.Time, reason string) error
+	// MarkDead takes the event out of publishing for good. It no longer
+	// holds back the later events of its key.
+	MarkDead(ctx context.Context, id string, reason string) error
+	Commit() error
+	Rollback() error
+}
+
+// Relay publishes outbox events. Several replicas of a service can run it
+// at once: a claim locks the event rows and their keys, so each event is
+// published by one relay and each key's events in order. An event is
+// published again if its relay dies between publishing and committing.
+//
+// Every relay on a database must be able to publish every topic written to
+// it, since any of them may claim any event.
+type Relay struct {
+	store        Store
+	publisher    Publisher
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+func NewRelay(store Store, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{
+		store:        store,
+		publisher:    publisher,
+		pollInterval: interval,
+		now:          time.Now,
+	}
+}
+
+func (r *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	log.Printf("Outbox relay started (polling every %v)", r.pollInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Drain(ctx)
+		}
+	}
+}
+
+// Drain publishes due events until a claim comes back short, then updates
+// the lag metrics.
+func (r *Relay) Drain(ctx context.Context) {
+	for {
+		n, err := r.relayBatch(ctx)
+		if err != nil {
+			log.Printf("Outbox relay: %v", err)
+			break
+		}
+		if n < batchSize {
+			break
+		}
+	}
+
+	pending, oldest, dead, err := r.store.Stats(ctx)
+	if err != nil {
+		log.Printf("Outbox relay: failed to read outbox stats: %v", err)
+		return
+	}
+	PendingEvents.Set(float64(pending))
+	DeadEvents.Set(float64(dead))
+	if oldest.IsZero() {
+		OldestPendingAge.Set(0)
+	} else {
+		OldestPendingAge.Set(r.now().Sub(oldest).Seconds())
+	}
+}
+
+// relayBatch publishes one claimed batch and returns its size. After a
+// failure, the rest of that key's events in the batch are left for the
+// retry so they are not published ahead of it. An event that has failed
+// maxAttempts times is dead-lettered instead, so it stops holding back its
+// key.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	claim, err := r.store.Claim(ctx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = claim.Rollback() }()
+
+	events := claim.Events()
+	blocked := map[string]bool{}
+	for _, e := range events {
+		if blocked[e.Key] {
+			continue
+		}
+		if err := r.publisher.Publish(ctx, e); err != nil {
+			PublishFailures.WithLabelValues(e.Topic, e.Type).Inc()
+			if e.Attempts+1 >= maxAttempts {
+				log.Printf("Outbox relay: dead-lettering event %s (%s) to %s after %d attempts: %v",
+					e.ID, e.Type, e.Topic, e.Attempts+1, err)
+				if err := claim.MarkDead(ctx, e.ID, err.Error()); err != nil {
+					return 0, err
+				}
+				DeadLettered.WithLabelValues(e.Topic, e.Type).Inc()
+				continue
+			}
+			blocked[e.Key] = true
+			retryAt := r.now().Add(backoff(e.Attempts + 1))
+			log.Printf("Outbox relay: failed to publish event %s (%s) to %s, attempt %d, retrying at %s: %v",
+				e.ID, e.Type, e.Topic, e.Attempts+1, retryAt.Format(time.RFC3339), err)
+			if err := claim.MarkFailed(ctx, e.ID, retryAt, err.Error()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := claim.MarkPublished(ctx, e.ID); err != nil {
+			return 0, err
+		}
+		Published.WithLabelValues(e.Topic, e.Type).Inc()
+		DeliveryLatency.Observe(r.now().Sub(e.CreatedAt).Seconds())
+	}
+	return len(events), claim.Commit()
+}
+
+// backoff doubles the wait after each failed attempt, up to a cap. Later
+// events of the key wait behind the failed one until it is published or
+// dead-lettered.
+func backoff(attempts int) time.Duration {
+	d := minBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClaim struct {
+	events    []Event
+	published []string
+	failed    map[string]time.Time
+	dead      []string
+	committed bool
+}
+
+func (c *fakeClaim) Events() []Event { return c.events }
+
+func (c *fakeClaim) MarkPublished(_ context.Context, id string) error {
+	c.published = append(c.published, id)
+	return nil
+}
+
+func (c *fakeClaim) MarkFailed(_ context.Context, id string, retryAt time.Time, _ string) error {
+	c.failed[id] = retryAt
+	return nil
+}
+
+func (c *fakeClaim) MarkDead(_ context.Context, id string, _ string) error {
+	c.dead = append(c.dead, id)
+	return nil
+}
+
+func (c *fakeClaim) Commit() error   { c.committed = true; return nil }
+func (c *fakeClaim) Rollback() error { return nil }
+
+type fakeStore struct {
+	claim *fakeClaim
+}
+
+func (s *fakeStore) Claim(context.Context, int) (Claim, error) { return s.claim, nil }
+
+func (s *fakeStore) Stats(context.Context) (int, time.Time, int, error) {
+	return len(s.claim.events) - len(s.claim.published) - len(s.claim.dead), time.Time{}, len(s.claim.dead), nil
+}
+
+func TestRelay_Drain(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{ID: "1", Topic: "payments", Key: "a", Payload: []byte("a1"), CreatedAt: now},
+		{ID: "2", Topic: "payments", Key: "b", Payload: []byte("b1"), Attempts: 3, CreatedAt: now},
+		{ID: "3", Topic: "payments", Key: "a", Payload: []byte("a2"), CreatedAt: now},
+		{ID: "4", Topic: "payments", Key: "b", Payload: []byte("b2"), CreatedAt: now},
+	}
+	claim := &fakeClaim{events: events, failed: map[string]time.Time{}}
+	publisher := NewMemoryPublisher()
+	publisher.Fail = func(e Event) error {
+		if e.Key == "b" {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	}
+	relay := NewRelay(&fakeStore{claim: claim}, publisher, time.Second)
+	relay.now = func() time.Time { return now }
+
+	relay.Drain(context.Background())
+
+	var sent []string
+	for _, e := range publisher.Events() {
+		sent = append(sent, string(e.Payload))
+	}
+	if len(sent) != 2 || sent[0] != "a1" || sent[1] != "a2" {
+		t.Errorf("sent = %v, want [a1 a2]", sent)
+	}
+	if len(claim.published) != 2 || claim.published[0] != "1" || claim.published[1] != "3" {
+		t.Errorf("published = %v, want [1 3]", claim.published)
+	}
+	// Event 4 must wait behind the failed event 2 and is not attempted.
+	if len(claim.failed) != 1 {
+		t.Fatalf("failed = %v, want only event 2", claim.failed)
+	}
+	if got, want := claim.failed["2"], now.Add(8*time.Second); !got.Equal(want) {
+		t.Errorf("retry at = %v, want %v", got, want)
+	}
+	if !claim.committed {
+		t.Error("claim was not committed")
+	}
+}
+
+func TestRelay_DeadLetter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	claim := &fakeClaim{events: []Event{
+		{ID: "1", Topic: "payments", Key: "a", Payload: []byte("poison"), Attempts: maxAttempts - 1, CreatedAt: now},
+		{ID: "2", Topic: "payments", Key: "a", Payload: []byte("a2"), CreatedAt: now},
+	}, failed: map[string]time.Time{}}
+	publisher := NewMemoryPublisher()
+	publisher.Fail = func(e Event) error {
+		if string(e.Payload) == "poison" {
+			return errors.New("message too large")
+		}
+		return nil
+	}
+	relay := NewRelay(&fakeStore{claim: claim}, publisher, time.Second)
+	relay.now = func() time.Time { return now }
+
+	relay.Drain(context.Background())
+
+	if len(claim.dead) != 1 || claim.dead[0] != "1" || len(claim.failed) != 0 {
+		t.Errorf("dead = %v, failed = %v, want event 1 dead-lettered", claim.dead, claim.failed)
+	}
+	// The key is no longer held back by the dead-lettered event.
+	if len(claim.published) != 1 || claim.published[0] != "2" {
+		t.Errorf("published = %v, want [2]", claim.published)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{9, 256 * time.Second},
+		{10, 5 * time.Minute},
+		{50, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
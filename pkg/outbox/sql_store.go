@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SQLStore claims events from the outbox table of a Postgres database.
+type SQLStore struct {
+	db *sql.DB
+}
+
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Claim takes row locks with SKIP LOCKED so concurrent relays get disjoint
+// batches, and a transaction-scoped advisory lock per key so no two relays
+// publish events of the same key at once. An event is only due when no
+// earlier event of its key is waiting for a retry.
+func (s *SQLStore) Claim(ctx context.Context, limit int) (Claim, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT o.id, o.topic, COALESCE(o.event_type, ''), o.partition_key, o.payload, o.attempts, o.created_at
+		 FROM outbox o
+		 WHERE o.processed_at IS NULL AND o.dead_lettered_at IS NULL AND o.available_at <= NOW()
+		   AND NOT EXISTS (
+		       SELECT 1 FROM outbox p
+		       WHERE p.partition_key = o.partition_key AND p.processed_at IS NULL AND p.dead_lettered_at IS NULL
+		         AND p.seq < o.seq AND p.available_at > NOW())
+		   AND pg_try_advisory_xact_lock(hashtext('outbox:' || o.partition_key))
+		 ORDER BY o.seq
+		 LIMIT $1
+		 FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	claim := &sqlClaim{tx: tx}
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Topic, &e.Type, &e.Key, &e.Payload, &e.Attempts, &e.CreatedAt); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		claim.events = append(claim.events, e)
+	}
+	if err := rows.Err(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return claim, nil
+}
+
+func (s *SQLStore) Stats(ctx context.Context) (int, time.Time, int, error) {
+	var pending, dead int
+	var oldest sql.NullTime
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FILTER (WHERE dead_lettered_at IS NULL),
+		        MIN(created_at) FILTER (WHERE dead_lettered_at IS NULL),
+		        COUNT(*) FILTER (WHERE dead_lettered_at IS NOT NULL)
+		 FROM outbox WHERE processed_at IS NULL`).Scan(&pending, &oldest, &dead)
+	if err != nil {
+		return 0, time.Time{}, 0, fmt.Errorf("failed to count outbox events: %w", err)
+	}
+	return pending, oldest.Time, dead, nil
+}
+
+type sqlClaim struct {
+	tx     *sql.Tx
+	events []Event
+}
+
+func (c *sqlClaim) Events() []Event {
+	return c.events
+}
+
+func (c *sqlClaim) MarkPublished(ctx context.Context, id string) error {
+	_, err := c.tx.ExecContext(ctx, `UPDATE outbox SET processed_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (c *sqlClaim) MarkFailed(ctx context.Context, id string, retryAt time.Time, reason string) error {
+	_, err := c.tx.ExecContext(ctx,
+		`UPDATE outbox SET attempts = attempts + 1, available_at = $2, last_error = $3 WHERE id = $1`,
+		id, retryAt, reason)
+	return err
+}
+
+func (c *sqlClaim) MarkDead(ctx context.Context, id string, reason string) error {
+	_, err := c.tx.ExecContext(ctx,
+		`UPDATE outbox SET attempts = attempts + 1, dead_lettered_at = NOW(), last_error = $2 WHERE id = $1`,
+		id, reason)
+	return err
+}
+
+func (c *sqlClaim) Commit() error {
+	return c.tx.Commit()
+}
+
+func (c *sqlClaim) Rollback() error {
+	return c.tx.Rollback()
+}
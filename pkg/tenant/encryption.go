@@ -5,10 +5,19 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 )
 
+var (
+	// ErrKeyNotFound is returned by key stores for a tenant without a key.
+	ErrKeyNotFound = errors.New("tenant key not found")
+	// ErrKeyExists is returned by key stores that never replace a tenant's
+	// key when setting one for a tenant that has it already.
+	ErrKeyExists = errors.New("tenant key already exists")
+)
+
 // KeyManager manages tenant-specific encryption keys for CMK (Customer Managed Keys).
 type KeyManager struct {
 	masterKey []byte
@@ -62,6 +71,20 @@ func (km *KeyManager) GenerateTenantKey(tenantID string) ([]byte, error) {
 	return key, nil
 }
 
+// EnsureTenantKey generates a key for a tenant that has none yet. When
+// another caller stored one first, in a store returning ErrKeyExists, that
+// key is kept.
+func (km *KeyManager) EnsureTenantKey(tenantID string) error {
+	_, err := km.keyStore.GetKey(tenantID)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	if _, err := km.GenerateTenantKey(tenantID); err != nil && !errors.Is(err, ErrKeyExists) {
+		return err
+	}
+	return nil
+}
+
 // GetTenantKey retrieves and decrypts the key for a tenant.
 func (km *KeyManager) GetTenantKey(tenantID string) ([]byte, error) {
 	encryptedKey, err := km.keyStore.GetKey(tenantID)
@@ -187,7 +210,7 @@ func NewInMemoryKeyStore() *InMemoryKeyStore {
 func (s *InMemoryKeyStore) GetKey(tenantID string) ([]byte, error) {
 	key, ok := s.keys[tenantID]
 	if !ok {
-		return nil, fmt.Errorf("key not found for tenant %s", tenantID)
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, tenantID)
 	}
 	return key, nil
 }
@@ -0,0 +1,69 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SQLKeyStore keeps tenant keys, encrypted with the master key by
+// KeyManager, in the tenant_keys table of a service's Postgres database.
+//
+// A tenant's key is never replaced: KeyManager keeps a single key per
+// tenant, so data encrypted under a replaced key could not be read again.
+// SetKey fails with ErrKeyExists for a tenant that has a key, and RotateKey
+// is not supported.
+type SQLKeyStore struct {
+	db *sql.DB
+}
+
+// NewSQLKeyStore creates a key store on db.
+func NewSQLKeyStore(db *sql.DB) *SQLKeyStore {
+	return &SQLKeyStore{db: db}
+}
+
+// GetKey returns the stored key of a tenant.
+func (s *SQLKeyStore) GetKey(tenantID string) ([]byte, error) {
+	var key []byte
+	err := s.db.QueryRowContext(context.Background(),
+		`SELECT encrypted_key FROM tenant_keys WHERE tenant_id = $1`, tenantID).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, tenantID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant key: %w", err)
+	}
+	return key, nil
+}
+
+// SetKey stores the first key of a tenant.
+func (s *SQLKeyStore) SetKey(tenantID string, key []byte) error {
+	res, err := s.db.ExecContext(context.Background(),
+		`INSERT INTO tenant_keys (tenant_id, encrypted_key) VALUES ($1, $2)
+		 ON CONFLICT (tenant_id) DO NOTHING`, tenantID, key)
+	if err != nil {
+		return fmt.Errorf("failed to store tenant key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %s", ErrKeyExists, tenantID)
+	}
+	return nil
+}
+
+// RotateKey fails: keys in an SQLKeyStore are never replaced.
+func (s *SQLKeyStore) RotateKey(tenantID string) ([]byte, error) {
+	return nil, fmt.Errorf("%w: %s: rotation is not supported", ErrKeyExists, tenantID)
+}
+
+// DeleteKey removes the key of a tenant, which leaves the data encrypted
+// under it unreadable.
+func (s *SQLKeyStore) DeleteKey(tenantID string) error {
+	if _, err := s.db.ExecContext(context.Background(),
+		`DELETE FROM tenant_keys WHERE tenant_id = $1`, tenantID); err != nil {
+		return fmt.Errorf("failed to delete tenant key: %w", err)
+	}
+	return nil
+}
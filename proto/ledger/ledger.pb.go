@@ -30,6 +30,9 @@ type CreateAccountRequest struct {
 	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
 	ZoneId        string                 `protobuf:"bytes,4,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
 	Mode          string                 `protobuf:"bytes,5,opt,name=mode,proto3" json:"mode,omitempty"`
+	Code          string                 `protobuf:"bytes,6,opt,name=code,proto3" json:"code,omitempty"`                         // Chart of accounts code, e.g. "1000"
+	ParentId      string                 `protobuf:"bytes,7,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Parent account; must share type and currency
+	UserId        string                 `protobuf:"bytes,8,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -99,6 +102,27 @@ func (x *CreateAccountRequest) GetMode() string {
 	return ""
 }
 
+func (x *CreateAccountRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
 type CreateAccountResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
@@ -152,14 +176,26 @@ func (x *CreateAccountResponse) GetStatus() string {
 }
 
 type RecordTransactionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"` // In cents
-	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
-	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	ReferenceId   string                 `protobuf:"bytes,5,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"` // e.g. PaymentIntent ID
-	ZoneId        string                 `protobuf:"bytes,6,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
-	Mode          string                 `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AccountId   string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount      int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"` // In cents
+	Currency    string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Description string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	ReferenceId string                 `protobuf:"bytes,5,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"` // e.g. PaymentIntent ID
+	ZoneId      string                 `protobuf:"bytes,6,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	Mode        string                 `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	// When set, the transaction is posted with exactly these balanced legs and
+	// account_id/amount are ignored.
+	Entries []*LedgerEntry `protobuf:"bytes,8,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Required when entries span several currencies; each currency must balance
+	// on its own through FX clearing accounts.
+	FxConversions []*FXConversion `protobuf:"bytes,9,rep,name=fx_conversions,json=fxConversions,proto3" json:"fx_conversions,omitempty"`
+	// Accounting date of the transaction; defaults to now. Rejected when it
+	// falls in a closed accounting period.
+	EffectiveAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=effective_at,json=effectiveAt,proto3" json:"effective_at,omitempty"`
+	// Single-account form only: credits the user's wallet account in the zone,
+	// opened if missing, instead of account_id.
+	UserId        string `protobuf:"bytes,11,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -243,28 +279,61 @@ func (x *RecordTransactionRequest) GetMode() string {
 	return ""
 }
 
-type RecordTransactionResponse struct {
+func (x *RecordTransactionRequest) GetEntries() []*LedgerEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *RecordTransactionRequest) GetFxConversions() []*FXConversion {
+	if x != nil {
+		return x.FxConversions
+	}
+	return nil
+}
+
+func (x *RecordTransactionRequest) GetEffectiveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveAt
+	}
+	return nil
+}
+
+func (x *RecordTransactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type FXConversion struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	FromCurrency  string                 `protobuf:"bytes,1,opt,name=from_currency,json=fromCurrency,proto3" json:"from_currency,omitempty"`
+	ToCurrency    string                 `protobuf:"bytes,2,opt,name=to_currency,json=toCurrency,proto3" json:"to_currency,omitempty"`
+	FromAmount    int64                  `protobuf:"varint,3,opt,name=from_amount,json=fromAmount,proto3" json:"from_amount,omitempty"`
+	ToAmount      int64                  `protobuf:"varint,4,opt,name=to_amount,json=toAmount,proto3" json:"to_amount,omitempty"`
+	Rate          float64                `protobuf:"fixed64,5,opt,name=rate,proto3" json:"rate,omitempty"` // Optional; looked up in the rate table when zero
+	RateSource    string                 `protobuf:"bytes,6,opt,name=rate_source,json=rateSource,proto3" json:"rate_source,omitempty"`
+	RateTimestamp *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=rate_timestamp,json=rateTimestamp,proto3" json:"rate_timestamp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RecordTransactionResponse) Reset() {
-	*x = RecordTransactionResponse{}
+func (x *FXConversion) Reset() {
+	*x = FXConversion{}
 	mi := &file_proto_ledger_ledger_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RecordTransactionResponse) String() string {
+func (x *FXConversion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecordTransactionResponse) ProtoMessage() {}
+func (*FXConversion) ProtoMessage() {}
 
-func (x *RecordTransactionResponse) ProtoReflect() protoreflect.Message {
+func (x *FXConversion) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_ledger_ledger_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -276,46 +345,87 @@ func (x *RecordTransactionResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecordTransactionResponse.ProtoReflect.Descriptor instead.
-func (*RecordTransactionResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use FXConversion.ProtoReflect.Descriptor instead.
+func (*FXConversion) Descriptor() ([]byte, []int) {
 	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *RecordTransactionResponse) GetTransactionId() string {
+func (x *FXConversion) GetFromCurrency() string {
 	if x != nil {
-		return x.TransactionId
+		return x.FromCurrency
 	}
 	return ""
 }
 
-func (x *RecordTransactionResponse) GetStatus() string {
+func (x *FXConversion) GetToCurrency() string {
 	if x != nil {
-		return x.Status
+		return x.ToCurrency
 	}
 	return ""
 }
 
-type BulkRecordRequest struct {
-	state         protoimpl.MessageState      `protogen:"open.v1"`
-	Transactions  []*RecordTransactionRequest `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+func (x *FXConversion) GetFromAmount() int64 {
+	if x != nil {
+		return x.FromAmount
+	}
+	return 0
+}
+
+func (x *FXConversion) GetToAmount() int64 {
+	if x != nil {
+		return x.ToAmount
+	}
+	return 0
+}
+
+func (x *FXConversion) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *FXConversion) GetRateSource() string {
+	if x != nil {
+		return x.RateSource
+	}
+	return ""
+}
+
+func (x *FXConversion) GetRateTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RateTimestamp
+	}
+	return nil
+}
+
+type FXRate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseCurrency  string                 `protobuf:"bytes,1,opt,name=base_currency,json=baseCurrency,proto3" json:"base_currency,omitempty"`
+	QuoteCurrency string                 `protobuf:"bytes,2,opt,name=quote_currency,json=quoteCurrency,proto3" json:"quote_currency,omitempty"`
+	Rate          float64                `protobuf:"fixed64,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	ZoneId        string                 `protobuf:"bytes,6,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"` // Required by SetFXRate; platform-wide rates are only loaded from file
+	Mode          string                 `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BulkRecordRequest) Reset() {
-	*x = BulkRecordRequest{}
+func (x *FXRate) Reset() {
+	*x = FXRate{}
 	mi := &file_proto_ledger_ledger_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BulkRecordRequest) String() string {
+func (x *FXRate) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BulkRecordRequest) ProtoMessage() {}
+func (*FXRate) ProtoMessage() {}
 
-func (x *BulkRecordRequest) ProtoReflect() protoreflect.Message {
+func (x *FXRate) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_ledger_ledger_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -327,39 +437,86 @@ func (x *BulkRecordRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BulkRecordRequest.ProtoReflect.Descriptor instead.
-func (*BulkRecordRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use FXRate.ProtoReflect.Descriptor instead.
+func (*FXRate) Descriptor() ([]byte, []int) {
 	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *BulkRecordRequest) GetTransactions() []*RecordTransactionRequest {
+func (x *FXRate) GetBaseCurrency() string {
 	if x != nil {
-		return x.Transactions
+		return x.BaseCurrency
+	}
+	return ""
+}
+
+func (x *FXRate) GetQuoteCurrency() string {
+	if x != nil {
+		return x.QuoteCurrency
+	}
+	return ""
+}
+
+func (x *FXRate) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *FXRate) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *FXRate) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
 	}
 	return nil
 }
 
-type BulkRecordResponse struct {
-	state         protoimpl.MessageState       `protogen:"open.v1"`
-	Responses     []*RecordTransactionResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
+func (x *FXRate) GetZoneId() string {
+	if x != nil {
+		return x.ZoneId
+	}
+	return ""
+}
+
+func (x *FXRate) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type LedgerEntry struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount    int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`      // Signed amount in cents
+	Direction string                 `protobuf:"bytes,3,opt,name=direction,proto3" json:"direction,omitempty"` // "debit" or "credit"
+	// Posts to the user's wallet account in the transaction's zone and
+	// currency when account_id is empty.
+	UserId        string `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BulkRecordResponse) Reset() {
-	*x = BulkRecordResponse{}
+func (x *LedgerEntry) Reset() {
+	*x = LedgerEntry{}
 	mi := &file_proto_ledger_ledger_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BulkRecordResponse) String() string {
+func (x *LedgerEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BulkRecordResponse) ProtoMessage() {}
+func (*LedgerEntry) ProtoMessage() {}
 
-func (x *BulkRecordResponse) ProtoReflect() protoreflect.Message {
+func (x *LedgerEntry) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_ledger_ledger_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -371,39 +528,61 @@ func (x *BulkRecordResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BulkRecordResponse.ProtoReflect.Descriptor instead.
-func (*BulkRecordResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LedgerEntry.ProtoReflect.Descriptor instead.
+func (*LedgerEntry) Descriptor() ([]byte, []int) {
 	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *BulkRecordResponse) GetResponses() []*RecordTransactionResponse {
+func (x *LedgerEntry) GetAccountId() string {
 	if x != nil {
-		return x.Responses
+		return x.AccountId
 	}
-	return nil
+	return ""
 }
 
-type GetAccountRequest struct {
+func (x *LedgerEntry) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *LedgerEntry) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *LedgerEntry) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type RecordTransactionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAccountRequest) Reset() {
-	*x = GetAccountRequest{}
+func (x *RecordTransactionResponse) Reset() {
+	*x = RecordTransactionResponse{}
 	mi := &file_proto_ledger_ledger_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAccountRequest) String() string {
+func (x *RecordTransactionResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAccountRequest) ProtoMessage() {}
+func (*RecordTransactionResponse) ProtoMessage() {}
 
-func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
+func (x *RecordTransactionResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_ledger_ledger_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -415,42 +594,63 @@ func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAccountRequest.ProtoReflect.Descriptor instead.
-func (*GetAccountRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RecordTransactionResponse.ProtoReflect.Descriptor instead.
+func (*RecordTransactionResponse) Descriptor() ([]byte, []int) {
 	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *GetAccountRequest) GetAccountId() string {
+func (x *RecordTransactionResponse) GetTransactionId() string {
 	if x != nil {
-		return x.AccountId
+		return x.TransactionId
 	}
 	return ""
 }
 
-type GetAccountResponse struct {
+func (x *RecordTransactionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ReverseTransactionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	Balance       int64                  `protobuf:"varint,2,opt,name=balance,proto3" json:"balance,omitempty"`
-	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	// Alternative to transaction_id: the reference the original was recorded
+	// under, e.g. a PaymentIntent ID.
+	OriginalReferenceId string `protobuf:"bytes,2,opt,name=original_reference_id,json=originalReferenceId,proto3" json:"original_reference_id,omitempty"`
+	Reason              string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Set reference_id and entries for a partial reversal; otherwise whatever
+	// is left unreversed is reversed.
+	ReferenceId   string          `protobuf:"bytes,4,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"`
+	Entries       []*LedgerEntry  `protobuf:"bytes,5,rep,name=entries,proto3" json:"entries,omitempty"`
+	FxConversions []*FXConversion `protobuf:"bytes,6,rep,name=fx_conversions,json=fxConversions,proto3" json:"fx_conversions,omitempty"`
+	// Alternative to entries for a partial reversal of a single-currency
+	// transaction such as a payment: reverses this amount and derives the
+	// entries from the original, sharing it in proportion between the
+	// accounts of a split payment. Requires reference_id.
+	Amount int64 `protobuf:"varint,7,opt,name=amount,proto3" json:"amount,omitempty"`
+	// The zone and mode of the caller, which the original must belong to.
+	ZoneId        string `protobuf:"bytes,8,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	Mode          string `protobuf:"bytes,9,opt,name=mode,proto3" json:"mode,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAccountResponse) Reset() {
-	*x = GetAccountResponse{}
+func (x *ReverseTransactionRequest) Reset() {
+	*x = ReverseTransactionRequest{}
 	mi := &file_proto_ledger_ledger_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAccountResponse) String() string {
+func (x *ReverseTransactionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAccountResponse) ProtoMessage() {}
+func (*ReverseTransactionRequest) ProtoMessage() {}
 
-func (x *GetAccountResponse) ProtoReflect() protoreflect.Message {
+func (x *ReverseTransactionRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_ledger_ledger_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -462,86 +662,1524 @@ func (x *GetAccountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAccountResponse.ProtoReflect.Descriptor instead.
-func (*GetAccountResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReverseTransactionRequest.ProtoReflect.Descriptor instead.
+func (*ReverseTransactionRequest) Descriptor() ([]byte, []int) {
 	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *GetAccountResponse) GetAccountId() string {
+func (x *ReverseTransactionRequest) GetTransactionId() string {
 	if x != nil {
-		return x.AccountId
+		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *GetAccountResponse) GetBalance() int64 {
+func (x *ReverseTransactionRequest) GetOriginalReferenceId() string {
 	if x != nil {
-		return x.Balance
+		return x.OriginalReferenceId
 	}
-	return 0
+	return ""
 }
 
-func (x *GetAccountResponse) GetCurrency() string {
+func (x *ReverseTransactionRequest) GetReason() string {
 	if x != nil {
-		return x.Currency
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *GetAccountResponse) GetCreatedAt() *timestamppb.Timestamp {
+func (x *ReverseTransactionRequest) GetReferenceId() string {
 	if x != nil {
-		return x.CreatedAt
+		return x.ReferenceId
+	}
+	return ""
+}
+
+func (x *ReverseTransactionRequest) GetEntries() []*LedgerEntry {
+	if x != nil {
+		return x.Entries
 	}
 	return nil
 }
 
-var File_proto_ledger_ledger_proto protoreflect.FileDescriptor
+func (x *ReverseTransactionRequest) GetFxConversions() []*FXConversion {
+	if x != nil {
+		return x.FxConversions
+	}
+	return nil
+}
 
-const file_proto_ledger_ledger_proto_rawDesc = "" +
-	"\n" +
-	"\x19proto/ledger/ledger.proto\x12\x06ledger\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/api/annotations.proto\"\x87\x01\n" +
-	"\x14CreateAccountRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
-	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
-	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x17\n" +
-	"\azone_id\x18\x04 \x01(\tR\x06zoneId\x12\x12\n" +
-	"\x04mode\x18\x05 \x01(\tR\x04mode\"N\n" +
-	"\x15CreateAccountResponse\x12\x1d\n" +
-	"\n" +
-	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\"\xdf\x01\n" +
-	"\x18RecordTransactionRequest\x12\x1d\n" +
-	"\n" +
-	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1a\n" +
-	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\x12!\n" +
-	"\freference_id\x18\x05 \x01(\tR\vreferenceId\x12\x17\n" +
-	"\azone_id\x18\x06 \x01(\tR\x06zoneId\x12\x12\n" +
-	"\x04mode\x18\a \x01(\tR\x04mode\"Z\n" +
-	"\x19RecordTransactionResponse\x12%\n" +
-	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\"Y\n" +
-	"\x11BulkRecordRequest\x12D\n" +
-	"\ftransactions\x18\x01 \x03(\v2 .ledger.RecordTransactionRequestR\ftransactions\"U\n" +
-	"\x12BulkRecordResponse\x12?\n" +
-	"\tresponses\x18\x01 \x03(\v2!.ledger.RecordTransactionResponseR\tresponses\"2\n" +
-	"\x11GetAccountRequest\x12\x1d\n" +
-	"\n" +
-	"account_id\x18\x01 \x01(\tR\taccountId\"\xa4\x01\n" +
-	"\x12GetAccountResponse\x12\x1d\n" +
-	"\n" +
-	"account_id\x18\x01 \x01(\tR\taccountId\x12\x18\n" +
-	"\abalance\x18\x02 \x01(\x03R\abalance\x12\x1a\n" +
-	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x129\n" +
-	"\n" +
-	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt2\xe4\x03\n" +
-	"\rLedgerService\x12x\n" +
-	"\x16BulkRecordTransactions\x12\x19.ledger.BulkRecordRequest\x1a\x1a.ledger.BulkRecordResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/ledger/bulk-transactions\x12|\n" +
-	"\x11RecordTransaction\x12 .ledger.RecordTransactionRequest\x1a!.ledger.RecordTransactionResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/ledger/transactions\x12l\n" +
-	"\rCreateAccount\x12\x1c.ledger.CreateAccountRequest\x1a\x1d.ledger.CreateAccountResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/ledger/accounts\x12m\n" +
+func (x *ReverseTransactionRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ReverseTransactionRequest) GetZoneId() string {
+	if x != nil {
+		return x.ZoneId
+	}
+	return ""
+}
+
+func (x *ReverseTransactionRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type ReverseTransactionResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId         string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	ReversesTransactionId string                 `protobuf:"bytes,2,opt,name=reverses_transaction_id,json=reversesTransactionId,proto3" json:"reverses_transaction_id,omitempty"`
+	Status                string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ReverseTransactionResponse) Reset() {
+	*x = ReverseTransactionResponse{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseTransactionResponse) ProtoMessage() {}
+
+func (x *ReverseTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseTransactionResponse.ProtoReflect.Descriptor instead.
+func (*ReverseTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReverseTransactionResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ReverseTransactionResponse) GetReversesTransactionId() string {
+	if x != nil {
+		return x.ReversesTransactionId
+	}
+	return ""
+}
+
+func (x *ReverseTransactionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type BulkRecordRequest struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Transactions  []*RecordTransactionRequest `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkRecordRequest) Reset() {
+	*x = BulkRecordRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkRecordRequest) ProtoMessage() {}
+
+func (x *BulkRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkRecordRequest.ProtoReflect.Descriptor instead.
+func (*BulkRecordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BulkRecordRequest) GetTransactions() []*RecordTransactionRequest {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type BulkRecordResponse struct {
+	state         protoimpl.MessageState       `protogen:"open.v1"`
+	Responses     []*RecordTransactionResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkRecordResponse) Reset() {
+	*x = BulkRecordResponse{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkRecordResponse) ProtoMessage() {}
+
+func (x *BulkRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkRecordResponse.ProtoReflect.Descriptor instead.
+func (*BulkRecordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BulkRecordResponse) GetResponses() []*RecordTransactionResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+type GetAccountRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// When set instead of account_id, returns the user's wallet account in the
+	// zone and currency, opened if missing.
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ZoneId        string `protobuf:"bytes,3,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	Mode          string `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	Currency      string `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountRequest) Reset() {
+	*x = GetAccountRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountRequest) ProtoMessage() {}
+
+func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetAccountRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetAccountRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetAccountRequest) GetZoneId() string {
+	if x != nil {
+		return x.ZoneId
+	}
+	return ""
+}
+
+func (x *GetAccountRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *GetAccountRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type SeedChartOfAccountsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ZoneId        string                 `protobuf:"bytes,1,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	Template      string                 `protobuf:"bytes,3,opt,name=template,proto3" json:"template,omitempty"` // Defaults to "fintech-basic"
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"` // Defaults to "USD"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SeedChartOfAccountsRequest) Reset() {
+	*x = SeedChartOfAccountsRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeedChartOfAccountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeedChartOfAccountsRequest) ProtoMessage() {}
+
+func (x *SeedChartOfAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeedChartOfAccountsRequest.ProtoReflect.Descriptor instead.
+func (*SeedChartOfAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SeedChartOfAccountsRequest) GetZoneId() string {
+	if x != nil {
+		return x.ZoneId
+	}
+	return ""
+}
+
+func (x *SeedChartOfAccountsRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *SeedChartOfAccountsRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *SeedChartOfAccountsRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type ChartAccount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	ParentId      string                 `protobuf:"bytes,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChartAccount) Reset() {
+	*x = ChartAccount{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChartAccount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChartAccount) ProtoMessage() {}
+
+func (x *ChartAccount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChartAccount.ProtoReflect.Descriptor instead.
+func (*ChartAccount) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ChartAccount) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ChartAccount) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ChartAccount) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChartAccount) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ChartAccount) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+type SeedChartOfAccountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*ChartAccount        `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SeedChartOfAccountsResponse) Reset() {
+	*x = SeedChartOfAccountsResponse{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeedChartOfAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeedChartOfAccountsResponse) ProtoMessage() {}
+
+func (x *SeedChartOfAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeedChartOfAccountsResponse.ProtoReflect.Descriptor instead.
+func (*SeedChartOfAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SeedChartOfAccountsResponse) GetAccounts() []*ChartAccount {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+type GetAccountResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AccountId        string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Balance          int64                  `protobuf:"varint,2,opt,name=balance,proto3" json:"balance,omitempty"` // Posted balance
+	Currency         string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	PostedBalance    int64                  `protobuf:"varint,5,opt,name=posted_balance,json=postedBalance,proto3" json:"posted_balance,omitempty"`
+	AvailableBalance int64                  `protobuf:"varint,6,opt,name=available_balance,json=availableBalance,proto3" json:"available_balance,omitempty"` // Posted balance less active holds
+	Code             string                 `protobuf:"bytes,7,opt,name=code,proto3" json:"code,omitempty"`
+	ParentId         string                 `protobuf:"bytes,8,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetAccountResponse) Reset() {
+	*x = GetAccountResponse{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountResponse) ProtoMessage() {}
+
+func (x *GetAccountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetAccountResponse) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetAccountResponse) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *GetAccountResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetAccountResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *GetAccountResponse) GetPostedBalance() int64 {
+	if x != nil {
+		return x.PostedBalance
+	}
+	return 0
+}
+
+func (x *GetAccountResponse) GetAvailableBalance() int64 {
+	if x != nil {
+		return x.AvailableBalance
+	}
+	return 0
+}
+
+func (x *GetAccountResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *GetAccountResponse) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+type CreateHoldRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	ReferenceId   string                 `protobuf:"bytes,3,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Defaults to 7 days from now
+	ZoneId        string                 `protobuf:"bytes,6,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	Mode          string                 `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateHoldRequest) Reset() {
+	*x = CreateHoldRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateHoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateHoldRequest) ProtoMessage() {}
+
+func (x *CreateHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateHoldRequest.ProtoReflect.Descriptor instead.
+func (*CreateHoldRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateHoldRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *CreateHoldRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CreateHoldRequest) GetReferenceId() string {
+	if x != nil {
+		return x.ReferenceId
+	}
+	return ""
+}
+
+func (x *CreateHoldRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateHoldRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *CreateHoldRequest) GetZoneId() string {
+	if x != nil {
+		return x.ZoneId
+	}
+	return ""
+}
+
+func (x *CreateHoldRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type CaptureHoldRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HoldId        string                 `protobuf:"bytes,1,opt,name=hold_id,json=holdId,proto3" json:"hold_id,omitempty"`
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"` // Defaults to the full hold; the remainder is released
+	ToAccountId   string                 `protobuf:"bytes,3,opt,name=to_account_id,json=toAccountId,proto3" json:"to_account_id,omitempty"`
+	ReferenceId   string                 `protobuf:"bytes,4,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"`
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	ZoneId        string                 `protobuf:"bytes,6,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	Mode          string                 `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureHoldRequest) Reset() {
+	*x = CaptureHoldRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureHoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureHoldRequest) ProtoMessage() {}
+
+func (x *CaptureHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureHoldRequest.ProtoReflect.Descriptor instead.
+func (*CaptureHoldRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CaptureHoldRequest) GetHoldId() string {
+	if x != nil {
+		return x.HoldId
+	}
+	return ""
+}
+
+func (x *CaptureHoldRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CaptureHoldRequest) GetToAccountId() string {
+	if x != nil {
+		return x.ToAccountId
+	}
+	return ""
+}
+
+func (x *CaptureHoldRequest) GetReferenceId() string {
+	if x != nil {
+		return x.ReferenceId
+	}
+	return ""
+}
+
+func (x *CaptureHoldRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CaptureHoldRequest) GetZoneId() string {
+	if x != nil {
+		return x.ZoneId
+	}
+	return ""
+}
+
+func (x *CaptureHoldRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type VoidHoldRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HoldId        string                 `protobuf:"bytes,1,opt,name=hold_id,json=holdId,proto3" json:"hold_id,omitempty"`
+	ZoneId        string                 `protobuf:"bytes,2,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	Mode          string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VoidHoldRequest) Reset() {
+	*x = VoidHoldRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VoidHoldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoidHoldRequest) ProtoMessage() {}
+
+func (x *VoidHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoidHoldRequest.ProtoReflect.Descriptor instead.
+func (*VoidHoldRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *VoidHoldRequest) GetHoldId() string {
+	if x != nil {
+		return x.HoldId
+	}
+	return ""
+}
+
+func (x *VoidHoldRequest) GetZoneId() string {
+	if x != nil {
+		return x.ZoneId
+	}
+	return ""
+}
+
+func (x *VoidHoldRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type Hold struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId      string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount         int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	CapturedAmount int64                  `protobuf:"varint,4,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"`
+	ReferenceId    string                 `protobuf:"bytes,5,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"`
+	Status         string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"` // active, captured, voided or expired
+	TransactionId  string                 `protobuf:"bytes,7,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Hold) Reset() {
+	*x = Hold{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hold) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hold) ProtoMessage() {}
+
+func (x *Hold) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hold.ProtoReflect.Descriptor instead.
+func (*Hold) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Hold) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Hold) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Hold) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Hold) GetCapturedAmount() int64 {
+	if x != nil {
+		return x.CapturedAmount
+	}
+	return 0
+}
+
+func (x *Hold) GetReferenceId() string {
+	if x != nil {
+		return x.ReferenceId
+	}
+	return ""
+}
+
+func (x *Hold) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Hold) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Hold) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type GetBalanceAtRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"` // Defaults to now
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceAtRequest) Reset() {
+	*x = GetBalanceAtRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceAtRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceAtRequest) ProtoMessage() {}
+
+func (x *GetBalanceAtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceAtRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceAtRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetBalanceAtRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetBalanceAtRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type GetBalanceAtResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Balance       int64                  `protobuf:"varint,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceAtResponse) Reset() {
+	*x = GetBalanceAtResponse{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceAtResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceAtResponse) ProtoMessage() {}
+
+func (x *GetBalanceAtResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceAtResponse.ProtoReflect.Descriptor instead.
+func (*GetBalanceAtResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetBalanceAtResponse) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetBalanceAtResponse) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *GetBalanceAtResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetBalanceAtResponse) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type GetStatementRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"` // "json" (default) or "csv"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatementRequest) Reset() {
+	*x = GetStatementRequest{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatementRequest) ProtoMessage() {}
+
+func (x *GetStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatementRequest.ProtoReflect.Descriptor instead.
+func (*GetStatementRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetStatementRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetStatementRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetStatementRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetStatementRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type StatementLine struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	EntryId        string                 `protobuf:"bytes,1,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	TransactionId  string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	ReferenceId    string                 `protobuf:"bytes,3,opt,name=reference_id,json=referenceId,proto3" json:"reference_id,omitempty"`
+	Description    string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Amount         int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Direction      string                 `protobuf:"bytes,6,opt,name=direction,proto3" json:"direction,omitempty"`
+	RunningBalance int64                  `protobuf:"varint,7,opt,name=running_balance,json=runningBalance,proto3" json:"running_balance,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	EffectiveAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=effective_at,json=effectiveAt,proto3" json:"effective_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StatementLine) Reset() {
+	*x = StatementLine{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatementLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatementLine) ProtoMessage() {}
+
+func (x *StatementLine) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatementLine.ProtoReflect.Descriptor instead.
+func (*StatementLine) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StatementLine) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+func (x *StatementLine) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *StatementLine) GetReferenceId() string {
+	if x != nil {
+		return x.ReferenceId
+	}
+	return ""
+}
+
+func (x *StatementLine) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *StatementLine) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *StatementLine) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *StatementLine) GetRunningBalance() int64 {
+	if x != nil {
+		return x.RunningBalance
+	}
+	return 0
+}
+
+func (x *StatementLine) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *StatementLine) GetEffectiveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveAt
+	}
+	return nil
+}
+
+type GetStatementResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AccountId      string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Currency       string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	From           *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To             *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	OpeningBalance int64                  `protobuf:"varint,5,opt,name=opening_balance,json=openingBalance,proto3" json:"opening_balance,omitempty"`
+	ClosingBalance int64                  `protobuf:"varint,6,opt,name=closing_balance,json=closingBalance,proto3" json:"closing_balance,omitempty"`
+	Lines          []*StatementLine       `protobuf:"bytes,7,rep,name=lines,proto3" json:"lines,omitempty"`
+	Csv            []byte                 `protobuf:"bytes,8,opt,name=csv,proto3" json:"csv,omitempty"` // Set when format is "csv"
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetStatementResponse) Reset() {
+	*x = GetStatementResponse{}
+	mi := &file_proto_ledger_ledger_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatementResponse) ProtoMessage() {}
+
+func (x *GetStatementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ledger_ledger_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatementResponse.ProtoReflect.Descriptor instead.
+func (*GetStatementResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ledger_ledger_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetStatementResponse) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetStatementResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetStatementResponse) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetStatementResponse) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetStatementResponse) GetOpeningBalance() int64 {
+	if x != nil {
+		return x.OpeningBalance
+	}
+	return 0
+}
+
+func (x *GetStatementResponse) GetClosingBalance() int64 {
+	if x != nil {
+		return x.ClosingBalance
+	}
+	return 0
+}
+
+func (x *GetStatementResponse) GetLines() []*StatementLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *GetStatementResponse) GetCsv() []byte {
+	if x != nil {
+		return x.Csv
+	}
+	return nil
+}
+
+var File_proto_ledger_ledger_proto protoreflect.FileDescriptor
+
+const file_proto_ledger_ledger_proto_rawDesc = "" +
+	"\n" +
+	"\x19proto/ledger/ledger.proto\x12\x06ledger\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cgoogle/api/annotations.proto\"\xd1\x01\n" +
+	"\x14CreateAccountRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x17\n" +
+	"\azone_id\x18\x04 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\x05 \x01(\tR\x04mode\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\tR\x04code\x12\x1b\n" +
+	"\tparent_id\x18\a \x01(\tR\bparentId\x12\x17\n" +
+	"\auser_id\x18\b \x01(\tR\x06userId\"N\n" +
+	"\x15CreateAccountResponse\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"\xa3\x03\n" +
+	"\x18RecordTransactionRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12!\n" +
+	"\freference_id\x18\x05 \x01(\tR\vreferenceId\x12\x17\n" +
+	"\azone_id\x18\x06 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\a \x01(\tR\x04mode\x12-\n" +
+	"\aentries\x18\b \x03(\v2\x13.ledger.LedgerEntryR\aentries\x12;\n" +
+	"\x0efx_conversions\x18\t \x03(\v2\x14.ledger.FXConversionR\rfxConversions\x12=\n" +
+	"\feffective_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\veffectiveAt\x12\x17\n" +
+	"\auser_id\x18\v \x01(\tR\x06userId\"\x8a\x02\n" +
+	"\fFXConversion\x12#\n" +
+	"\rfrom_currency\x18\x01 \x01(\tR\ffromCurrency\x12\x1f\n" +
+	"\vto_currency\x18\x02 \x01(\tR\n" +
+	"toCurrency\x12\x1f\n" +
+	"\vfrom_amount\x18\x03 \x01(\x03R\n" +
+	"fromAmount\x12\x1b\n" +
+	"\tto_amount\x18\x04 \x01(\x03R\btoAmount\x12\x12\n" +
+	"\x04rate\x18\x05 \x01(\x01R\x04rate\x12\x1f\n" +
+	"\vrate_source\x18\x06 \x01(\tR\n" +
+	"rateSource\x12A\n" +
+	"\x0erate_timestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\rrateTimestamp\"\xde\x01\n" +
+	"\x06FXRate\x12#\n" +
+	"\rbase_currency\x18\x01 \x01(\tR\fbaseCurrency\x12%\n" +
+	"\x0equote_currency\x18\x02 \x01(\tR\rquoteCurrency\x12\x12\n" +
+	"\x04rate\x18\x03 \x01(\x01R\x04rate\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12/\n" +
+	"\x05as_of\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\x12\x17\n" +
+	"\azone_id\x18\x06 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\a \x01(\tR\x04mode\"{\n" +
+	"\vLedgerEntry\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1c\n" +
+	"\tdirection\x18\x03 \x01(\tR\tdirection\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\"Z\n" +
+	"\x19RecordTransactionResponse\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"\xe2\x02\n" +
+	"\x19ReverseTransactionRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x122\n" +
+	"\x15original_reference_id\x18\x02 \x01(\tR\x13originalReferenceId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12!\n" +
+	"\freference_id\x18\x04 \x01(\tR\vreferenceId\x12-\n" +
+	"\aentries\x18\x05 \x03(\v2\x13.ledger.LedgerEntryR\aentries\x12;\n" +
+	"\x0efx_conversions\x18\x06 \x03(\v2\x14.ledger.FXConversionR\rfxConversions\x12\x16\n" +
+	"\x06amount\x18\a \x01(\x03R\x06amount\x12\x17\n" +
+	"\azone_id\x18\b \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\t \x01(\tR\x04mode\"\x93\x01\n" +
+	"\x1aReverseTransactionResponse\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x126\n" +
+	"\x17reverses_transaction_id\x18\x02 \x01(\tR\x15reversesTransactionId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"Y\n" +
+	"\x11BulkRecordRequest\x12D\n" +
+	"\ftransactions\x18\x01 \x03(\v2 .ledger.RecordTransactionRequestR\ftransactions\"U\n" +
+	"\x12BulkRecordResponse\x12?\n" +
+	"\tresponses\x18\x01 \x03(\v2!.ledger.RecordTransactionResponseR\tresponses\"\x94\x01\n" +
+	"\x11GetAccountRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x17\n" +
+	"\azone_id\x18\x03 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\tR\x04mode\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\"\x81\x01\n" +
+	"\x1aSeedChartOfAccountsRequest\x12\x17\n" +
+	"\azone_id\x18\x01 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12\x1a\n" +
+	"\btemplate\x18\x03 \x01(\tR\btemplate\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\"\x86\x01\n" +
+	"\fChartAccount\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x1b\n" +
+	"\tparent_id\x18\x05 \x01(\tR\bparentId\"O\n" +
+	"\x1bSeedChartOfAccountsResponse\x120\n" +
+	"\baccounts\x18\x01 \x03(\v2\x14.ledger.ChartAccountR\baccounts\"\xa9\x02\n" +
+	"\x12GetAccountResponse\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x03R\abalance\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12%\n" +
+	"\x0eposted_balance\x18\x05 \x01(\x03R\rpostedBalance\x12+\n" +
+	"\x11available_balance\x18\x06 \x01(\x03R\x10availableBalance\x12\x12\n" +
+	"\x04code\x18\a \x01(\tR\x04code\x12\x1b\n" +
+	"\tparent_id\x18\b \x01(\tR\bparentId\"\xf7\x01\n" +
+	"\x11CreateHoldRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12!\n" +
+	"\freference_id\x18\x03 \x01(\tR\vreferenceId\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x17\n" +
+	"\azone_id\x18\x06 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\a \x01(\tR\x04mode\"\xdb\x01\n" +
+	"\x12CaptureHoldRequest\x12\x17\n" +
+	"\ahold_id\x18\x01 \x01(\tR\x06holdId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\"\n" +
+	"\rto_account_id\x18\x03 \x01(\tR\vtoAccountId\x12!\n" +
+	"\freference_id\x18\x04 \x01(\tR\vreferenceId\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x17\n" +
+	"\azone_id\x18\x06 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\a \x01(\tR\x04mode\"W\n" +
+	"\x0fVoidHoldRequest\x12\x17\n" +
+	"\ahold_id\x18\x01 \x01(\tR\x06holdId\x12\x17\n" +
+	"\azone_id\x18\x02 \x01(\tR\x06zoneId\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\"\x93\x02\n" +
+	"\x04Hold\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\x12'\n" +
+	"\x0fcaptured_amount\x18\x04 \x01(\x03R\x0ecapturedAmount\x12!\n" +
+	"\freference_id\x18\x05 \x01(\tR\vreferenceId\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12%\n" +
+	"\x0etransaction_id\x18\a \x01(\tR\rtransactionId\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"e\n" +
+	"\x13GetBalanceAtRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12/\n" +
+	"\x05as_of\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"\x9c\x01\n" +
+	"\x14GetBalanceAtResponse\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x03R\abalance\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12/\n" +
+	"\x05as_of\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"\xa8\x01\n" +
+	"\x13GetStatementRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\"\xef\x02\n" +
+	"\rStatementLine\x12\x19\n" +
+	"\bentry_id\x18\x01 \x01(\tR\aentryId\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12!\n" +
+	"\freference_id\x18\x03 \x01(\tR\vreferenceId\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x1c\n" +
+	"\tdirection\x18\x06 \x01(\tR\tdirection\x12'\n" +
+	"\x0frunning_balance\x18\a \x01(\x03R\x0erunningBalance\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\feffective_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\veffectiveAt\"\xbe\x02\n" +
+	"\x14GetStatementResponse\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12'\n" +
+	"\x0fopening_balance\x18\x05 \x01(\x03R\x0eopeningBalance\x12'\n" +
+	"\x0fclosing_balance\x18\x06 \x01(\x03R\x0eclosingBalance\x12+\n" +
+	"\x05lines\x18\a \x03(\v2\x15.ledger.StatementLineR\x05lines\x12\x10\n" +
+	"\x03csv\x18\b \x01(\fR\x03csv2\xe6\n" +
+	"\n" +
+	"\rLedgerService\x12x\n" +
+	"\x16BulkRecordTransactions\x12\x19.ledger.BulkRecordRequest\x1a\x1a.ledger.BulkRecordResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/ledger/bulk-transactions\x12|\n" +
+	"\x11RecordTransaction\x12 .ledger.RecordTransactionRequest\x1a!.ledger.RecordTransactionResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/ledger/transactions\x12\x98\x01\n" +
+	"\x12ReverseTransaction\x12!.ledger.ReverseTransactionRequest\x1a\".ledger.ReverseTransactionResponse\";\x82\xd3\xe4\x93\x025:\x01*\"0/v1/ledger/transactions/{transaction_id}/reverse\x12l\n" +
+	"\rCreateAccount\x12\x1c.ledger.CreateAccountRequest\x1a\x1d.ledger.CreateAccountResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/ledger/accounts\x12\x80\x01\n" +
+	"\x13SeedChartOfAccounts\x12\".ledger.SeedChartOfAccountsRequest\x1a#.ledger.SeedChartOfAccountsResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/ledger/chart/seed\x12m\n" +
+	"\n" +
+	"GetAccount\x12\x19.ledger.GetAccountRequest\x1a\x1a.ledger.GetAccountResponse\"(\x82\xd3\xe4\x93\x02\"\x12 /v1/ledger/accounts/{account_id}\x12{\n" +
+	"\fGetBalanceAt\x12\x1b.ledger.GetBalanceAtRequest\x1a\x1c.ledger.GetBalanceAtResponse\"0\x82\xd3\xe4\x93\x02*\x12(/v1/ledger/accounts/{account_id}/balance\x12}\n" +
+	"\fGetStatement\x12\x1b.ledger.GetStatementRequest\x1a\x1c.ledger.GetStatementResponse\"2\x82\xd3\xe4\x93\x02,\x12*/v1/ledger/accounts/{account_id}/statement\x12R\n" +
 	"\n" +
-	"GetAccount\x12\x19.ledger.GetAccountRequest\x1a\x1a.ledger.GetAccountResponse\"(\x82\xd3\xe4\x93\x02\"\x12 /v1/ledger/accounts/{account_id}B2Z0github.com/sapliy/fintech-ecosystem/proto/ledgerb\x06proto3"
+	"CreateHold\x12\x19.ledger.CreateHoldRequest\x1a\f.ledger.Hold\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/ledger/holds\x12f\n" +
+	"\vCaptureHold\x12\x1a.ledger.CaptureHoldRequest\x1a\f.ledger.Hold\"-\x82\xd3\xe4\x93\x02':\x01*\"\"/v1/ledger/holds/{hold_id}/capture\x12]\n" +
+	"\bVoidHold\x12\x17.ledger.VoidHoldRequest\x1a\f.ledger.Hold\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/v1/ledger/holds/{hold_id}/void\x12K\n" +
+	"\tSetFXRate\x12\x0e.ledger.FXRate\x1a\x0e.ledger.FXRate\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/ledger/fx-ratesB2Z0github.com/sapliy/fintech-ecosystem/proto/ledgerb\x06proto3"
 
 var (
 	file_proto_ledger_ledger_proto_rawDescOnce sync.Once
@@ -555,35 +2193,87 @@ func file_proto_ledger_ledger_proto_rawDescGZIP() []byte {
 	return file_proto_ledger_ledger_proto_rawDescData
 }
 
-var file_proto_ledger_ledger_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_proto_ledger_ledger_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_proto_ledger_ledger_proto_goTypes = []any{
-	(*CreateAccountRequest)(nil),      // 0: ledger.CreateAccountRequest
-	(*CreateAccountResponse)(nil),     // 1: ledger.CreateAccountResponse
-	(*RecordTransactionRequest)(nil),  // 2: ledger.RecordTransactionRequest
-	(*RecordTransactionResponse)(nil), // 3: ledger.RecordTransactionResponse
-	(*BulkRecordRequest)(nil),         // 4: ledger.BulkRecordRequest
-	(*BulkRecordResponse)(nil),        // 5: ledger.BulkRecordResponse
-	(*GetAccountRequest)(nil),         // 6: ledger.GetAccountRequest
-	(*GetAccountResponse)(nil),        // 7: ledger.GetAccountResponse
-	(*timestamppb.Timestamp)(nil),     // 8: google.protobuf.Timestamp
+	(*CreateAccountRequest)(nil),        // 0: ledger.CreateAccountRequest
+	(*CreateAccountResponse)(nil),       // 1: ledger.CreateAccountResponse
+	(*RecordTransactionRequest)(nil),    // 2: ledger.RecordTransactionRequest
+	(*FXConversion)(nil),                // 3: ledger.FXConversion
+	(*FXRate)(nil),                      // 4: ledger.FXRate
+	(*LedgerEntry)(nil),                 // 5: ledger.LedgerEntry
+	(*RecordTransactionResponse)(nil),   // 6: ledger.RecordTransactionResponse
+	(*ReverseTransactionRequest)(nil),   // 7: ledger.ReverseTransactionRequest
+	(*ReverseTransactionResponse)(nil),  // 8: ledger.ReverseTransactionResponse
+	(*BulkRecordRequest)(nil),           // 9: ledger.BulkRecordRequest
+	(*BulkRecordResponse)(nil),          // 10: ledger.BulkRecordResponse
+	(*GetAccountRequest)(nil),           // 11: ledger.GetAccountRequest
+	(*SeedChartOfAccountsRequest)(nil),  // 12: ledger.SeedChartOfAccountsRequest
+	(*ChartAccount)(nil),                // 13: ledger.ChartAccount
+	(*SeedChartOfAccountsResponse)(nil), // 14: ledger.SeedChartOfAccountsResponse
+	(*GetAccountResponse)(nil),          // 15: ledger.GetAccountResponse
+	(*CreateHoldRequest)(nil),           // 16: ledger.CreateHoldRequest
+	(*CaptureHoldRequest)(nil),          // 17: ledger.CaptureHoldRequest
+	(*VoidHoldRequest)(nil),             // 18: ledger.VoidHoldRequest
+	(*Hold)(nil),                        // 19: ledger.Hold
+	(*GetBalanceAtRequest)(nil),         // 20: ledger.GetBalanceAtRequest
+	(*GetBalanceAtResponse)(nil),        // 21: ledger.GetBalanceAtResponse
+	(*GetStatementRequest)(nil),         // 22: ledger.GetStatementRequest
+	(*StatementLine)(nil),               // 23: ledger.StatementLine
+	(*GetStatementResponse)(nil),        // 24: ledger.GetStatementResponse
+	(*timestamppb.Timestamp)(nil),       // 25: google.protobuf.Timestamp
 }
 var file_proto_ledger_ledger_proto_depIdxs = []int32{
-	2, // 0: ledger.BulkRecordRequest.transactions:type_name -> ledger.RecordTransactionRequest
-	3, // 1: ledger.BulkRecordResponse.responses:type_name -> ledger.RecordTransactionResponse
-	8, // 2: ledger.GetAccountResponse.created_at:type_name -> google.protobuf.Timestamp
-	4, // 3: ledger.LedgerService.BulkRecordTransactions:input_type -> ledger.BulkRecordRequest
-	2, // 4: ledger.LedgerService.RecordTransaction:input_type -> ledger.RecordTransactionRequest
-	0, // 5: ledger.LedgerService.CreateAccount:input_type -> ledger.CreateAccountRequest
-	6, // 6: ledger.LedgerService.GetAccount:input_type -> ledger.GetAccountRequest
-	5, // 7: ledger.LedgerService.BulkRecordTransactions:output_type -> ledger.BulkRecordResponse
-	3, // 8: ledger.LedgerService.RecordTransaction:output_type -> ledger.RecordTransactionResponse
-	1, // 9: ledger.LedgerService.CreateAccount:output_type -> ledger.CreateAccountResponse
-	7, // 10: ledger.LedgerService.GetAccount:output_type -> ledger.GetAccountResponse
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5,  // 0: ledger.RecordTransactionRequest.entries:type_name -> ledger.LedgerEntry
+	3,  // 1: ledger.RecordTransactionRequest.fx_conversions:type_name -> ledger.FXConversion
+	25, // 2: ledger.RecordTransactionRequest.effective_at:type_name -> google.protobuf.Timestamp
+	25, // 3: ledger.FXConversion.rate_timestamp:type_name -> google.protobuf.Timestamp
+	25, // 4: ledger.FXRate.as_of:type_name -> google.protobuf.Timestamp
+	5,  // 5: ledger.ReverseTransactionRequest.entries:type_name -> ledger.LedgerEntry
+	3,  // 6: ledger.ReverseTransactionRequest.fx_conversions:type_name -> ledger.FXConversion
+	2,  // 7: ledger.BulkRecordRequest.transactions:type_name -> ledger.RecordTransactionRequest
+	6,  // 8: ledger.BulkRecordResponse.responses:type_name -> ledger.RecordTransactionResponse
+	13, // 9: ledger.SeedChartOfAccountsResponse.accounts:type_name -> ledger.ChartAccount
+	25, // 10: ledger.GetAccountResponse.created_at:type_name -> google.protobuf.Timestamp
+	25, // 11: ledger.CreateHoldRequest.expires_at:type_name -> google.protobuf.Timestamp
+	25, // 12: ledger.Hold.expires_at:type_name -> google.protobuf.Timestamp
+	25, // 13: ledger.GetBalanceAtRequest.as_of:type_name -> google.protobuf.Timestamp
+	25, // 14: ledger.GetBalanceAtResponse.as_of:type_name -> google.protobuf.Timestamp
+	25, // 15: ledger.GetStatementRequest.from:type_name -> google.protobuf.Timestamp
+	25, // 16: ledger.GetStatementRequest.to:type_name -> google.protobuf.Timestamp
+	25, // 17: ledger.StatementLine.created_at:type_name -> google.protobuf.Timestamp
+	25, // 18: ledger.StatementLine.effective_at:type_name -> google.protobuf.Timestamp
+	25, // 19: ledger.GetStatementResponse.from:type_name -> google.protobuf.Timestamp
+	25, // 20: ledger.GetStatementResponse.to:type_name -> google.protobuf.Timestamp
+	23, // 21: ledger.GetStatementResponse.lines:type_name -> ledger.StatementLine
+	9,  // 22: ledger.LedgerService.BulkRecordTransactions:input_type -> ledger.BulkRecordRequest
+	2,  // 23: ledger.LedgerService.RecordTransaction:input_type -> ledger.RecordTransactionRequest
+	7,  // 24: ledger.LedgerService.ReverseTransaction:input_type -> ledger.ReverseTransactionRequest
+	0,  // 25: ledger.LedgerService.CreateAccount:input_type -> ledger.CreateAccountRequest
+	12, // 26: ledger.LedgerService.SeedChartOfAccounts:input_type -> ledger.SeedChartOfAccountsRequest
+	11, // 27: ledger.LedgerService.GetAccount:input_type -> ledger.GetAccountRequest
+	20, // 28: ledger.LedgerService.GetBalanceAt:input_type -> ledger.GetBalanceAtRequest
+	22, // 29: ledger.LedgerService.GetStatement:input_type -> ledger.GetStatementRequest
+	16, // 30: ledger.LedgerService.CreateHold:input_type -> ledger.CreateHoldRequest
+	17, // 31: ledger.LedgerService.CaptureHold:input_type -> ledger.CaptureHoldRequest
+	18, // 32: ledger.LedgerService.VoidHold:input_type -> ledger.VoidHoldRequest
+	4,  // 33: ledger.LedgerService.SetFXRate:input_type -> ledger.FXRate
+	10, // 34: ledger.LedgerService.BulkRecordTransactions:output_type -> ledger.BulkRecordResponse
+	6,  // 35: ledger.LedgerService.RecordTransaction:output_type -> ledger.RecordTransactionResponse
+	8,  // 36: ledger.LedgerService.ReverseTransaction:output_type -> ledger.ReverseTransactionResponse
+	1,  // 37: ledger.LedgerService.CreateAccount:output_type -> ledger.CreateAccountResponse
+	14, // 38: ledger.LedgerService.SeedChartOfAccounts:output_type -> ledger.SeedChartOfAccountsResponse
+	15, // 39: ledger.LedgerService.GetAccount:output_type -> ledger.GetAccountResponse
+	21, // 40: ledger.LedgerService.GetBalanceAt:output_type -> ledger.GetBalanceAtResponse
+	24, // 41: ledger.LedgerService.GetStatement:output_type -> ledger.GetStatementResponse
+	19, // 42: ledger.LedgerService.CreateHold:output_type -> ledger.Hold
+	19, // 43: ledger.LedgerService.CaptureHold:output_type -> ledger.Hold
+	19, // 44: ledger.LedgerService.VoidHold:output_type -> ledger.Hold
+	4,  // 45: ledger.LedgerService.SetFXRate:output_type -> ledger.FXRate
+	34, // [34:46] is the sub-list for method output_type
+	22, // [22:34] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_proto_ledger_ledger_proto_init() }
@@ -597,7 +2287,7 @@ func file_proto_ledger_ledger_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_ledger_ledger_proto_rawDesc), len(file_proto_ledger_ledger_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
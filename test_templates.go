@@ -26,6 +26,10 @@ func main() {
 			fmt.Printf("✅ Created flow: %s for zone %s\n", name, zoneID)
 			return nil
 		},
+		SeedChartOfAccounts: func(ctx context.Context, zoneID, mode, chart string) error {
+			fmt.Printf("✅ Seeded chart of accounts: %s for zone %s\n", chart, zoneID)
+			return nil
+		},
 	}
 
 	// Create zone service